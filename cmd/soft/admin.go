@@ -2,31 +2,101 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/db/migrate"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/spf13/cobra"
 )
 
 var (
+	migrateDryRun      bool
+	migrateReposFrom   string
+	migrateReposDryRun bool
+
+	importDirRecursive bool
+	importDirOwner     string
+	importDirPrivate   bool
+	importDirHidden    bool
+	importDirMove      bool
+	importDirDryRun    bool
+
 	adminCmd = &cobra.Command{
 		Use:   "admin",
 		Short: "Administrate the server",
 	}
 
 	migrateCmd = &cobra.Command{
-		Use:                "migrate",
-		Short:              "Migrate the database to the latest version",
+		Use:   "migrate",
+		Short: "Migrate the database to the latest version",
+		Long: `Migrate the database to the latest version. Use the status, up, and down
+subcommands to inspect the migrations, and --dry-run to print their SQL
+without running it.`,
 		PersistentPreRunE:  initBackendContext,
 		PersistentPostRunE: closeDBContext,
+		RunE:               migrateUp,
+	}
+
+	migrateStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the applied and pending migrations",
+		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
-			db := db.FromContext(ctx)
-			if err := migrate.Migrate(ctx, db); err != nil {
-				return fmt.Errorf("migration: %w", err)
+			status, err := migrate.Status(ctx, db.FromContext(ctx))
+			if err != nil {
+				return err
+			}
+
+			var pending int
+			for _, s := range status {
+				state := "applied"
+				if !s.Applied {
+					state = "pending"
+					pending++
+				}
+				cmd.Printf("%4d  %-30s  %s\n", s.Version, s.Name, state)
+			}
+			cmd.Printf("%d pending migrations\n", pending)
+
+			return nil
+		},
+	}
+
+	migrateUpCmd = &cobra.Command{
+		Use:   "up",
+		Short: "Apply the pending migrations",
+		Args:  cobra.NoArgs,
+		RunE:  migrateUp,
+	}
+
+	migrateDownCmd = &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the last migration",
+		Long: `Roll back the last applied migration. The migration creating the tables
+can't be rolled back.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			dbx := db.FromContext(ctx)
+			m, err := migrate.Last(ctx, dbx)
+			if err != nil {
+				return fmt.Errorf("rollback: %w", err)
+			}
+
+			if migrateDryRun {
+				return printMigration(cmd, dbx, m, true)
+			}
+
+			if err := migrate.Rollback(ctx, dbx); err != nil {
+				return fmt.Errorf("rollback: %w", err)
 			}
+			cmd.Printf("Rolled back migration %d. %s\n", m.Version, m.Name)
 
 			return nil
 		},
@@ -35,6 +105,7 @@ var (
 	rollbackCmd = &cobra.Command{
 		Use:                "rollback",
 		Short:              "Rollback the database to the previous version",
+		Deprecated:         `use "migrate down" instead`,
 		PersistentPreRunE:  initBackendContext,
 		PersistentPostRunE: closeDBContext,
 		RunE: func(cmd *cobra.Command, _ []string) error {
@@ -55,21 +126,212 @@ var (
 		PersistentPostRunE: closeDBContext,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
-			cfg := config.FromContext(ctx)
 			be := backend.FromContext(ctx)
-			if err := initializeHooks(ctx, cfg, be); err != nil {
+			if err := initializeHooks(ctx, be); err != nil {
 				return fmt.Errorf("initialize hooks: %w", err)
 			}
 
 			return nil
 		},
 	}
+
+	migrateReposCmd = &cobra.Command{
+		Use:   "migrate-repos",
+		Short: "Move the repositories to the paths of the repository path template",
+		Long: `Move the repositories from the paths of the --from template to the paths of the
+"repo_path_template" setting. Stop the server first.`,
+		PersistentPreRunE:  initBackendContext,
+		PersistentPostRunE: closeDBContext,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			be := backend.FromContext(ctx)
+			repos, err := be.Repositories(ctx)
+			if err != nil {
+				return err
+			}
+
+			var moved, missing int
+			for _, r := range repos {
+				rel, err := config.RepoPathWithTemplate(migrateReposFrom, r.Name())
+				if err != nil {
+					return fmt.Errorf("from template: %w", err)
+				}
+				op := filepath.Join(cfg.ReposPath(), rel)
+				np := cfg.RepoPath(r.Name())
+				if op == np {
+					continue
+				}
+				if _, err := os.Stat(np); err == nil {
+					// Already moved.
+					continue
+				}
+				if _, err := os.Stat(op); err != nil {
+					cmd.PrintErrf("%s: not found at %s\n", r.Name(), op)
+					missing++
+					continue
+				}
+
+				cmd.Printf("%s: %s -> %s\n", r.Name(), op, np)
+				moved++
+				if migrateReposDryRun {
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(np), os.ModePerm); err != nil {
+					return err
+				}
+				if err := os.Rename(op, np); err != nil {
+					return fmt.Errorf("move %s: %w", r.Name(), err)
+				}
+				cfg.RemoveEmptyRepoDirs(op)
+			}
+
+			if migrateReposDryRun {
+				cmd.Printf("%d repositories to move\n", moved)
+			} else {
+				cmd.Printf("%d repositories moved\n", moved)
+			}
+			if missing > 0 {
+				return fmt.Errorf("%d repositories not found", missing)
+			}
+
+			return nil
+		},
+	}
+
+	importDirCmd = &cobra.Command{
+		Use:   "import-dir DIRECTORY",
+		Short: "Import the bare repositories of a directory",
+		Long: `Import the existing bare git repositories of a directory, to migrate from plain
+git hosting. The repositories are copied to the data path, or moved there with
+--move, and named after their directory. With --recursive, the repositories of
+the subdirectories are imported too, in the namespace of their subdirectory.
+Repositories that already exist are skipped.`,
+		Example:            "  soft admin import-dir /srv/git --recursive --owner beatrice",
+		Args:               cobra.ExactArgs(1),
+		PersistentPreRunE:  initBackendContext,
+		PersistentPostRunE: closeDBContext,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			dir, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			owner, err := be.User(ctx, importDirOwner)
+			if err != nil {
+				return fmt.Errorf("owner %s: %w", importDirOwner, err)
+			}
+
+			repos, err := backend.FindBareRepositories(dir, importDirRecursive)
+			if err != nil {
+				return err
+			}
+
+			var imported, failed int
+			for _, r := range repos {
+				if _, err := be.Repository(ctx, r.Name); err == nil {
+					cmd.Printf("%s: already exists, skipped\n", r.Name)
+					continue
+				}
+
+				if importDirDryRun {
+					cmd.Printf("%s: %s\n", r.Name, r.Path)
+					imported++
+					continue
+				}
+
+				opts := proto.RepositoryOptions{Private: importDirPrivate, Hidden: importDirHidden}
+				if _, err := be.AdoptRepository(ctx, r.Name, r.Path, owner, opts, importDirMove); err != nil {
+					cmd.PrintErrf("%s: %v\n", r.Name, err)
+					failed++
+					continue
+				}
+
+				cmd.Printf("%s: imported\n", r.Name)
+				imported++
+			}
+
+			if importDirDryRun {
+				cmd.Printf("%d repositories to import\n", imported)
+			} else {
+				cmd.Printf("%d repositories imported\n", imported)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d repositories failed to import", failed)
+			}
+
+			return nil
+		},
+	}
 )
 
 func init() {
+	migrateCmd.PersistentFlags().BoolVar(&migrateDryRun, "dry-run", false, "print the SQL of the migrations without running it")
+	migrateCmd.AddCommand(
+		migrateStatusCmd,
+		migrateUpCmd,
+		migrateDownCmd,
+	)
+
+	migrateReposCmd.Flags().StringVar(&migrateReposFrom, "from", config.FlatRepoPathTemplate, "the repository path template the repositories are stored with")
+	migrateReposCmd.Flags().BoolVar(&migrateReposDryRun, "dry-run", false, "print the moves without moving the repositories")
+
+	importDirCmd.Flags().BoolVarP(&importDirRecursive, "recursive", "r", false, "import the repositories of the subdirectories too")
+	importDirCmd.Flags().StringVar(&importDirOwner, "owner", "admin", "the user owning the imported repositories")
+	importDirCmd.Flags().BoolVar(&importDirPrivate, "private", false, "make the imported repositories private")
+	importDirCmd.Flags().BoolVar(&importDirHidden, "hidden", false, "hide the imported repositories")
+	importDirCmd.Flags().BoolVar(&importDirMove, "move", false, "move the repositories instead of copying them")
+	importDirCmd.Flags().BoolVar(&importDirDryRun, "dry-run", false, "print the repositories without importing them")
+
 	adminCmd.AddCommand(
 		syncHooksCmd,
 		migrateCmd,
 		rollbackCmd,
+		migrateReposCmd,
+		importDirCmd,
 	)
 }
+
+// migrateUp applies the pending migrations, or prints them with --dry-run.
+func migrateUp(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	dbx := db.FromContext(ctx)
+	pending, err := migrate.Pending(ctx, dbx)
+	if err != nil {
+		return fmt.Errorf("migration: %w", err)
+	}
+
+	if len(pending) == 0 {
+		cmd.Println("The database is up to date")
+		return nil
+	}
+
+	if migrateDryRun {
+		for _, m := range pending {
+			if err := printMigration(cmd, dbx, m, false); err != nil {
+				return err
+			}
+		}
+		cmd.Printf("%d pending migrations\n", len(pending))
+		return nil
+	}
+
+	if err := migrate.Migrate(ctx, dbx); err != nil {
+		return fmt.Errorf("migration: %w", err)
+	}
+	cmd.Printf("%d migrations applied\n", len(pending))
+
+	return nil
+}
+
+func printMigration(cmd *cobra.Command, dbx *db.DB, m migrate.Migration, down bool) error {
+	sqlstr, err := m.SQL(dbx.DriverName(), down)
+	if err != nil {
+		return fmt.Errorf("migration %d: %w", m.Version, err)
+	}
+
+	cmd.Printf("-- %d. %s\n%s\n", m.Version, m.Name, strings.TrimSpace(sqlstr))
+	return nil
+}
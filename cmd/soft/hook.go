@@ -13,9 +13,11 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/spf13/cobra"
 )
 
@@ -63,6 +65,31 @@ var (
 		// This is set in the server before invoking git-receive-pack/git-upload-pack
 		repoName := os.Getenv("SOFT_SERVE_REPO_NAME")
 
+		// Git runs the hooks in the repository, the checks of the hooks
+		// must never apply to another one.
+		if err := checkHookRepo(hks.RepoPath(repoName)); err != nil {
+			return err
+		}
+
+		// The user pushing to the repository, if any.
+		if username := os.Getenv("SOFT_SERVE_USERNAME"); username != "" {
+			user, err := hks.User(ctx, username)
+			if err != nil {
+				return err
+			}
+			ctx = proto.WithUserContext(ctx, user)
+
+			// The user has the access level they pushed with, which might be
+			// capped by an access token scope or a public key restriction.
+			if level := access.ParseAccessLevel(os.Getenv("SOFT_SERVE_ACCESS_LEVEL")); level >= 0 {
+				ctx = access.WithContext(ctx, level)
+			}
+		}
+
+		if addr := os.Getenv("SOFT_SERVE_REMOTE_ADDR"); addr != "" {
+			ctx = proto.WithRemoteAddrContext(ctx, addr)
+		}
+
 		stdin := cmd.InOrStdin()
 		stdout := cmd.OutOrStdout()
 		stderr := cmd.ErrOrStderr()
@@ -70,6 +97,13 @@ var (
 		cmdName := cmd.Name()
 		customHookPath := filepath.Join(cfg.DataPath, "hooks", cmdName)
 
+		// In HA mode, the hooks of a repository run on one server at a time.
+		unlock, err := hks.LockHooks(ctx, repoName)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
 		var buf bytes.Buffer
 		opts := make([]hooks.HookArg, 0)
 
@@ -78,6 +112,7 @@ var (
 			scanner := bufio.NewScanner(stdin)
 			for scanner.Scan() {
 				buf.Write(scanner.Bytes())
+				buf.WriteByte('\n')
 				fields := strings.Fields(scanner.Text())
 				if len(fields) != 3 {
 					return fmt.Errorf("invalid hook input: %s", scanner.Text())
@@ -91,7 +126,9 @@ var (
 
 			switch cmdName {
 			case hooks.PreReceiveHook:
-				hks.PreReceive(ctx, stdout, stderr, repoName, opts)
+				if err := hks.PreReceive(ctx, stdout, stderr, repoName, opts); err != nil {
+					return err
+				}
 			case hooks.PostReceiveHook:
 				hks.PostReceive(ctx, stdout, stderr, repoName, opts)
 			}
@@ -100,24 +137,28 @@ var (
 				return fmt.Errorf("invalid update hook input: %s", args)
 			}
 
-			hks.Update(ctx, stdout, stderr, repoName, hooks.HookArg{
+			if err := hks.Update(ctx, stdout, stderr, repoName, hooks.HookArg{
 				RefName: args[0],
 				OldSha:  args[1],
 				NewSha:  args[2],
-			})
+			}); err != nil {
+				return err
+			}
 		case hooks.PostUpdateHook:
 			hks.PostUpdate(ctx, stdout, stderr, repoName, args...)
 		}
 
 		// Custom hooks
+		input := buf.Bytes()
 		if stat, err := os.Stat(customHookPath); err == nil && !stat.IsDir() && stat.Mode()&0o111 != 0 {
 			// If the custom hook is executable, run it
-			if err := runCommand(ctx, &buf, stdout, stderr, customHookPath, args...); err != nil {
+			if err := runCommand(ctx, bytes.NewReader(input), stdout, stderr, customHookPath, args...); err != nil {
 				return fmt.Errorf("failed to run custom hook: %w", err)
 			}
 		}
 
-		return nil
+		// The hook script of the repository, see repo hooks set.
+		return hks.RunRepoHook(ctx, repoName, cmdName, bytes.NewReader(input), stderr, args...)
 	}
 
 	preReceiveCmd = &cobra.Command{
@@ -164,6 +205,29 @@ func runCommand(ctx context.Context, in io.Reader, out io.Writer, err io.Writer,
 	return cmd.Run()
 }
 
+// checkHookRepo returns an error if the hook doesn't run in the repository at
+// path.
+func checkHookRepo(path string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	rs, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("invalid repository: %w", err)
+	}
+	ws, err := os.Stat(wd)
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(rs, ws) {
+		return fmt.Errorf("hook runs outside of repository %s", path)
+	}
+
+	return nil
+}
+
 const updateHookExample = `#!/bin/sh
 #
 # An example hook script to echo information about the push
@@ -148,7 +148,7 @@ var migrateConfig = &cobra.Command{
 		// Copy repos
 		if reposPath != "" {
 			logger.Info("Copying repos...")
-			if err := os.MkdirAll(filepath.Join(cfg.DataPath, "repos"), os.ModePerm); err != nil {
+			if err := os.MkdirAll(cfg.ReposPath(), os.ModePerm); err != nil {
 				return fmt.Errorf("failed to create repos directory: %w", err)
 			}
 
@@ -168,7 +168,7 @@ var migrateConfig = &cobra.Command{
 
 				logger.Infof("  Copying repo %s", dir.Name())
 				src := filepath.Join(reposPath, utils.SanitizeRepo(dir.Name()))
-				dst := filepath.Join(cfg.DataPath, "repos", utils.SanitizeRepo(dir.Name())) + ".git"
+				dst := sb.RepoPath(dir.Name())
 				if err := os.MkdirAll(dst, os.ModePerm); err != nil {
 					return fmt.Errorf("failed to create repo directory: %w", err)
 				}
@@ -188,7 +188,7 @@ var migrateConfig = &cobra.Command{
 				// Switch to main branch
 				bcmd := git.NewCommand("branch", "-M", "main")
 
-				rp := filepath.Join(cfg.DataPath, "repos", ".soft-serve.git")
+				rp := sb.RepoPath(".soft-serve")
 				nr, err := git.Init(rp, true)
 				if err != nil {
 					return fmt.Errorf("failed to init repo: %w", err)
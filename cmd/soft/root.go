@@ -15,6 +15,7 @@ import (
 	logr "github.com/charmbracelet/soft-serve/server/log"
 	"github.com/charmbracelet/soft-serve/server/store"
 	"github.com/charmbracelet/soft-serve/server/store/database"
+	"github.com/charmbracelet/soft-serve/server/version"
 	"github.com/spf13/cobra"
 	"go.uber.org/automaxprocs/maxprocs"
 )
@@ -58,6 +59,8 @@ func init() {
 		}
 	}
 	rootCmd.Version = Version
+	version.Version = Version
+	version.CommitSHA = CommitSHA
 }
 
 func main() {
@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/server"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
@@ -63,8 +64,27 @@ var (
 			}
 
 			db := db.FromContext(ctx)
-			if err := migrate.Migrate(ctx, db); err != nil {
-				return fmt.Errorf("migration error: %w", err)
+			if cfg.Replica.Enabled {
+				// The primary migrates the database of a replica.
+				pending, err := migrate.Pending(ctx, db)
+				if err != nil {
+					return fmt.Errorf("migration error: %w", err)
+				}
+				if len(pending) > 0 {
+					return fmt.Errorf("the database has %d pending migrations, upgrade the primary first", len(pending))
+				}
+			} else if cfg.DB.AutoMigrate {
+				if err := migrate.Migrate(ctx, db); err != nil {
+					return fmt.Errorf("migration error: %w", err)
+				}
+			} else {
+				pending, err := migrate.Pending(ctx, db)
+				if err != nil {
+					return fmt.Errorf("migration error: %w", err)
+				}
+				if len(pending) > 0 {
+					return fmt.Errorf("the database has %d pending migrations, apply them with \"soft admin migrate up\"", len(pending))
+				}
 			}
 
 			s, err := server.NewServer(ctx)
@@ -74,7 +94,7 @@ var (
 
 			if syncHooks {
 				be := backend.FromContext(ctx)
-				if err := initializeHooks(ctx, cfg, be); err != nil {
+				if err := initializeHooks(ctx, be); err != nil {
 					return fmt.Errorf("initialize hooks: %w", err)
 				}
 			}
@@ -87,10 +107,26 @@ var (
 				lch <- s.Start()
 			}()
 
+			// SIGHUP reloads the configuration.
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+			defer signal.Stop(hup)
+			go func() {
+				be := backend.FromContext(ctx)
+				for range hup {
+					if err := be.ReloadConfig(ctx); err != nil {
+						log.FromContext(ctx).Error("failed to reload configuration", "err", err)
+					}
+				}
+			}()
+
 			signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 			<-done
 
-			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			// Give in-flight transfers and sessions the grace period to
+			// finish.
+			log.FromContext(ctx).Info("Shutting down, draining connections", "timeout", cfg.ShutdownTimeout)
+			ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.ShutdownTimeout)*time.Second)
 			defer cancel()
 			if err := s.Shutdown(ctx); err != nil {
 				return err
@@ -106,14 +142,14 @@ func init() {
 	serveCmd.Flags().BoolVarP(&syncHooks, "sync-hooks", "", false, "synchronize hooks for all repositories before running the server")
 }
 
-func initializeHooks(ctx context.Context, cfg *config.Config, be *backend.Backend) error {
+func initializeHooks(ctx context.Context, be *backend.Backend) error {
 	repos, err := be.Repositories(ctx)
 	if err != nil {
 		return err
 	}
 
 	for _, repo := range repos {
-		if err := hooks.GenerateHooks(ctx, cfg, repo.Name()); err != nil {
+		if err := hooks.GenerateHooks(ctx, be.RepoPath(repo.Name())); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is a line of a file with the commit that last changed it.
+type BlameLine struct {
+	// Number is the number of the line in the file, from 1.
+	Number      int
+	Commit      string
+	Author      string
+	AuthorEmail string
+	AuthorTime  time.Time
+	// Summary is the first line of the message of the commit.
+	Summary string
+	Content string
+}
+
+// Blame returns the lines start to end of the file path at the revision rev,
+// with the commits that last changed them. Zero start and end blame the
+// whole file.
+func (r *Repository) Blame(rev string, path string, start int, end int) ([]BlameLine, error) {
+	args := []string{"blame", "--line-porcelain"}
+	if start > 0 || end > 0 {
+		var rng string
+		if start > 0 {
+			rng = strconv.Itoa(start)
+		}
+		rng += ","
+		if end > 0 {
+			rng += strconv.Itoa(end)
+		}
+		args = append(args, "-L", rng)
+	}
+
+	var out bytes.Buffer
+	if err := r.run(&out, append(args, rev, "--", path)...); err != nil {
+		return nil, err
+	}
+
+	return parseBlame(&out)
+}
+
+// parseBlame parses the output of git blame --line-porcelain, where each
+// line is preceded by all the information of its commit.
+func parseBlame(out io.Reader) ([]BlameLine, error) {
+	var lines []BlameLine
+	var line BlameLine
+	header := true
+	rd := bufio.NewReader(out)
+	for {
+		text, err := rd.ReadString('\n')
+		if errors.Is(err, io.EOF) && text == "" {
+			return lines, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		text = strings.TrimSuffix(text, "\n")
+		if header {
+			// <commit> <original line> <final line> [<lines in group>]
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("invalid blame header %q", text)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid blame header %q", text)
+			}
+			line = BlameLine{Commit: fields[0], Number: n}
+			header = false
+			continue
+		}
+
+		if content, ok := strings.CutPrefix(text, "\t"); ok {
+			line.Content = content
+			lines = append(lines, line)
+			header = true
+			continue
+		}
+
+		key, value, _ := strings.Cut(text, " ")
+		switch key {
+		case "author":
+			line.Author = value
+		case "author-mail":
+			line.AuthorEmail = strings.Trim(value, "<>")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid blame author time %q", value)
+			}
+			line.AuthorTime = time.Unix(sec, 0).UTC()
+		case "summary":
+			line.Summary = value
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GrepOptions are the options of Grep.
+type GrepOptions struct {
+	IgnoreCase bool
+	// Extensions limits the search to the files with one of the extensions,
+	// without the dot.
+	Extensions []string
+	// Limit is the maximum number of matches, 0 for all.
+	Limit int
+}
+
+// GrepMatch is a line of a file matching a pattern.
+type GrepMatch struct {
+	Path    string
+	Line    int
+	Content string
+}
+
+// Grep returns the lines of the files at the revision rev matching the
+// extended regular expression pattern. Binary files are skipped. It returns
+// true if there are more matches than the limit.
+func (r *Repository) Grep(ctx context.Context, rev string, pattern string, opts GrepOptions) ([]GrepMatch, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	args := []string{"grep", "--null", "--line-number", "-I", "-E"}
+	if opts.IgnoreCase {
+		args = append(args, "--ignore-case")
+	}
+	args = append(args, "-e", pattern, rev, "--")
+	for _, ext := range opts.Extensions {
+		args = append(args, "*."+strings.TrimPrefix(ext, "."))
+	}
+
+	pr, pw := io.Pipe()
+	var stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		err := NewCommand(args...).WithContext(ctx).
+			RunInDirWithOptions(r.Path, RunInDirOptions{
+				Stdout: pw,
+				Stderr: &stderr,
+			})
+		pw.Close() // nolint: errcheck
+		done <- err
+	}()
+
+	// <rev>:<path>\0<line>\0<content>
+	var matches []GrepMatch
+	more := false
+	rd := bufio.NewReader(pr)
+	for {
+		text, err := rd.ReadString('\n')
+		if text == "" && err != nil {
+			break
+		}
+		if opts.Limit > 0 && len(matches) == opts.Limit {
+			more = true
+			break
+		}
+
+		fields := strings.SplitN(strings.TrimSuffix(text, "\n"), "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		matches = append(matches, GrepMatch{
+			Path:    strings.TrimPrefix(fields[0], rev+":"),
+			Line:    n,
+			Content: fields[2],
+		})
+	}
+
+	if more {
+		// Stop git, the rest of the matches are discarded.
+		cancel()
+		pr.Close() // nolint: errcheck
+		<-done
+		return matches, true, nil
+	}
+
+	// git grep exits with 1 if nothing matches.
+	if err := <-done; err != nil && (len(matches) > 0 || stderr.Len() > 0) {
+		return nil, false, fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return matches, false, nil
+}
@@ -0,0 +1,416 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogs/git-module"
+)
+
+var (
+	// ErrMergeConflict is returned when a merge cannot be performed because of
+	// conflicting changes.
+	ErrMergeConflict = errors.New("merge conflict")
+	// ErrNotFastForward is returned when a fast-forward merge is requested but
+	// the head is not a descendant of the base.
+	ErrNotFastForward = errors.New("not a fast-forward")
+	// ErrNothingToMerge is returned when the head is already merged into the
+	// base.
+	ErrNothingToMerge = errors.New("nothing to merge")
+	// ErrInvalidMergeStrategy is returned when an unknown merge strategy is
+	// used.
+	ErrInvalidMergeStrategy = errors.New("invalid merge strategy")
+)
+
+// Signature represents the author or committer of a commit.
+type Signature = git.Signature
+
+// MergeStrategy is the strategy used to merge a head into a base branch.
+type MergeStrategy string
+
+const (
+	// MergeFastForward only moves the base branch forward to the head. It
+	// fails if the branches have diverged.
+	MergeFastForward MergeStrategy = "fast-forward"
+	// MergeCommit creates a merge commit with both the base and the head as
+	// parents.
+	MergeCommit MergeStrategy = "merge"
+	// MergeSquash creates a single commit on top of the base containing all
+	// the changes of the head.
+	MergeSquash MergeStrategy = "squash"
+	// MergeRebase replays the commits of the head on top of the base and
+	// fast-forwards the base to the result.
+	MergeRebase MergeStrategy = "rebase"
+)
+
+// MergeStrategies is a list of all the supported merge strategies.
+var MergeStrategies = []MergeStrategy{
+	MergeFastForward,
+	MergeCommit,
+	MergeSquash,
+	MergeRebase,
+}
+
+// ParseMergeStrategy parses a merge strategy from a string.
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	for _, ms := range MergeStrategies {
+		if string(ms) == s {
+			return ms, nil
+		}
+	}
+	return "", ErrInvalidMergeStrategy
+}
+
+// String returns the string representation of the merge strategy.
+func (s MergeStrategy) String() string {
+	return string(s)
+}
+
+// MergeOptions are options for merging a head into a base branch.
+type MergeOptions struct {
+	// Strategy is the merge strategy. Defaults to MergeCommit.
+	Strategy MergeStrategy
+	// Message is the commit message used for merge and squash commits.
+	Message string
+	// Author is the author of merge and squash commits. Defaults to the
+	// committer.
+	Author *Signature
+	// Committer is the committer of the new commits.
+	Committer *Signature
+	// Timeout is the timeout of each git command.
+	Timeout time.Duration
+}
+
+// MergeResult is the result of a merge.
+type MergeResult struct {
+	// Hash is the new hash of the base branch.
+	Hash Hash
+	// OldHash is the hash of the base branch before the merge.
+	OldHash Hash
+	// FastForward is true if the base branch was fast-forwarded.
+	FastForward bool
+}
+
+// Merge merges the head revision into the base branch using the given
+// strategy. The base must be a branch name, i.e. "main" or "refs/heads/main",
+// and head can be any revision. The base branch is updated atomically and
+// the merge fails if it was modified concurrently. Merges are performed
+// without a working tree so they work on bare repositories.
+func (r *Repository) Merge(base, head string, opts MergeOptions) (*MergeResult, error) {
+	if opts.Strategy == "" {
+		opts.Strategy = MergeCommit
+	}
+	if !strings.HasPrefix(base, RefsHeads) {
+		base = RefsHeads + base
+	}
+
+	baseHash, err := r.revParse(base, opts)
+	if err != nil {
+		return nil, err
+	}
+	headHash, err := r.revParse(head, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &MergeResult{OldHash: Hash(baseHash)}
+	if baseHash == headHash || r.isAncestor(headHash, baseHash, opts) {
+		return nil, ErrNothingToMerge
+	}
+
+	canFF := r.isAncestor(baseHash, headHash, opts)
+	var newHash string
+	switch opts.Strategy {
+	case MergeFastForward:
+		if !canFF {
+			return nil, ErrNotFastForward
+		}
+		newHash = headHash
+		res.FastForward = true
+	case MergeCommit, MergeSquash:
+		tree, err := r.mergeTree(baseHash, headHash, opts)
+		if err != nil {
+			return nil, err
+		}
+		msg := opts.Message
+		if msg == "" {
+			msg = fmt.Sprintf("Merge %s into %s", head, ReferenceName(base).Short())
+		}
+		parents := []string{baseHash}
+		if opts.Strategy == MergeCommit {
+			parents = append(parents, headHash)
+		}
+		newHash, err = r.commitTree(tree, msg, parents, opts)
+		if err != nil {
+			return nil, err
+		}
+	case MergeRebase:
+		if canFF {
+			newHash = headHash
+			res.FastForward = true
+			break
+		}
+		newHash, err = r.rebase(baseHash, headHash, opts)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidMergeStrategy
+	}
+
+	if _, err := r.command(opts, "update-ref", base, newHash, baseHash).RunInDir(r.Path); err != nil {
+		return nil, err
+	}
+
+	res.Hash = Hash(newHash)
+	return res, nil
+}
+
+func (r *Repository) command(opts MergeOptions, args ...string) *git.Command {
+	cmd := NewCommand(args...)
+	if opts.Timeout > 0 {
+		cmd = cmd.WithTimeout(opts.Timeout)
+	}
+	if c := opts.Committer; c != nil {
+		cmd.AddCommitter(c)
+		if !c.When.IsZero() {
+			cmd.AddEnvs("GIT_COMMITTER_DATE=" + c.When.Format(time.RFC3339))
+		}
+	}
+	return cmd
+}
+
+func (r *Repository) revParse(rev string, opts MergeOptions) (string, error) {
+	out, err := r.command(opts, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}").RunInDir(r.Path)
+	if err != nil {
+		return "", ErrRevisionNotExist
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// isAncestor returns true if a is an ancestor of b.
+func (r *Repository) isAncestor(a, b string, opts MergeOptions) bool {
+	_, err := r.command(opts, "merge-base", "--is-ancestor", a, b).RunInDir(r.Path)
+	return err == nil
+}
+
+// mergeTree performs a three-way merge of base and head and returns the
+// resulting tree.
+func (r *Repository) mergeTree(base, head string, opts MergeOptions) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := r.command(opts, "merge-tree", "--write-tree", "--no-messages", base, head).
+		RunInDirPipeline(&stdout, &stderr, r.Path)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", ErrMergeConflict
+		}
+		return "", fmt.Errorf("%w - %s", err, stderr.String())
+	}
+	tree, _, _ := strings.Cut(stdout.String(), "\n")
+	return strings.TrimSpace(tree), nil
+}
+
+// commitTree creates a new commit object with the given tree and parents.
+func (r *Repository) commitTree(tree, msg string, parents []string, opts MergeOptions) (string, error) {
+	args := []string{"commit-tree", tree, "-m", msg}
+	for _, p := range parents {
+		args = append(args, "-p", p)
+	}
+	cmd := r.command(opts, args...)
+	author := opts.Author
+	if author == nil {
+		author = opts.Committer
+	}
+	if author != nil {
+		cmd.AddEnvs("GIT_AUTHOR_NAME="+author.Name, "GIT_AUTHOR_EMAIL="+author.Email)
+		if !author.When.IsZero() {
+			cmd.AddEnvs("GIT_AUTHOR_DATE=" + author.When.Format(time.RFC3339))
+		}
+	}
+	out, err := cmd.RunInDir(r.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// rebase replays the commits of head that are not in base on top of base and
+// returns the new tip. Each commit keeps its original author and message.
+// Merge commits are dropped.
+func (r *Repository) rebase(base, head string, opts MergeOptions) (string, error) {
+	out, err := r.command(opts, "rev-list", "--reverse", "--no-merges", "--cherry-pick", "--right-only",
+		base+"..."+head).RunInDir(r.Path)
+	if err != nil {
+		return "", err
+	}
+
+	tip := base
+	for _, c := range strings.Fields(string(out)) {
+		tip, err = r.cherryPick(tip, c, opts)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return tip, nil
+}
+
+// cherryPick applies commit c on top of onto and returns the new commit.
+func (r *Repository) cherryPick(onto, c string, opts MergeOptions) (string, error) {
+	rnd := rand.NewSource(time.Now().UnixNano())
+	fn := "soft-serve-index-" + strconv.Itoa(rand.New(rnd).Int()) // nolint: gosec
+	tmpindex := filepath.Join(os.TempDir(), fn)
+
+	defer os.Remove(tmpindex) // nolint: errcheck
+
+	env := "GIT_INDEX_FILE=" + tmpindex
+	if _, err := r.command(opts, "read-tree", "-i", "-m", "--aggressive", c+"^", onto, c).
+		AddEnvs(env).RunInDir(r.Path); err != nil {
+		return "", err
+	}
+
+	// Resolve remaining conflicts using a content-level three-way merge on
+	// each unmerged file.
+	if err := r.mergeUnmerged(env, opts); err != nil {
+		return "", err
+	}
+
+	out, err := r.command(opts, "write-tree").AddEnvs(env).RunInDir(r.Path)
+	if err != nil {
+		return "", ErrMergeConflict
+	}
+	tree := strings.TrimSpace(string(out))
+
+	out, err = r.command(opts, "show", "-s", "--format=%an%x00%ae%x00%aI%x00%B", c).RunInDir(r.Path)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.SplitN(string(out), "\x00", 4)
+	if len(parts) != 4 {
+		return "", fmt.Errorf("invalid commit %s", c)
+	}
+	when, _ := time.Parse(time.RFC3339, parts[2])
+	opts.Author = &Signature{Name: parts[0], Email: parts[1], When: when}
+
+	return r.commitTree(tree, strings.TrimRight(parts[3], "\n"), []string{onto}, opts)
+}
+
+// mergeUnmerged resolves the unmerged entries of the index using a
+// three-way file merge. It returns ErrMergeConflict if any of the files
+// cannot be merged cleanly.
+func (r *Repository) mergeUnmerged(env string, opts MergeOptions) error {
+	out, err := r.command(opts, "ls-files", "-u", "-z").AddEnvs(env).RunInDir(r.Path)
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		mode   string
+		stages [3]string
+	}
+	files := make(map[string]*entry)
+	paths := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\x00") {
+		// <mode> SP <object> SP <stage> TAB <path>
+		info, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) != 3 {
+			continue
+		}
+		e, ok := files[path]
+		if !ok {
+			e = &entry{}
+			files[path] = e
+			paths = append(paths, path)
+		}
+		switch fields[2] {
+		case "1":
+			e.stages[0] = fields[1]
+		case "2":
+			e.mode = fields[0]
+			e.stages[1] = fields[1]
+		case "3":
+			e.stages[2] = fields[1]
+		}
+	}
+
+	for _, path := range paths {
+		e := files[path]
+		for _, s := range e.stages {
+			if s == "" {
+				// Added/deleted on one side and modified on the other.
+				return ErrMergeConflict
+			}
+		}
+
+		merged, err := r.mergeFile(e.stages, opts)
+		if err != nil {
+			return err
+		}
+
+		var stdout bytes.Buffer
+		if err := r.command(opts, "hash-object", "-w", "--stdin").
+			RunInDirWithOptions(r.Path, RunInDirOptions{
+				Stdin:  bytes.NewReader(merged),
+				Stdout: &stdout,
+			}); err != nil {
+			return err
+		}
+
+		if _, err := r.command(opts, "update-index", "--cacheinfo",
+			e.mode+","+strings.TrimSpace(stdout.String())+","+path).
+			AddEnvs(env).RunInDir(r.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeFile performs a three-way merge of the given base, ours, and theirs
+// blobs and returns the merged content.
+func (r *Repository) mergeFile(blobs [3]string, opts MergeOptions) ([]byte, error) {
+	files := make([]string, 0, len(blobs))
+	defer func() {
+		for _, f := range files {
+			os.Remove(f) // nolint: errcheck
+		}
+	}()
+	for _, b := range blobs {
+		content, err := r.command(opts, "cat-file", "blob", b).RunInDir(r.Path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.CreateTemp("", "soft-serve-merge-")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f.Name())
+		_, err = f.Write(content)
+		f.Close() // nolint: errcheck
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// git merge-file expects <current> <base> <other>.
+	merged, err := r.command(opts, "merge-file", "-p", files[1], files[0], files[2]).
+		RunInDir(r.Path)
+	if err != nil {
+		return nil, ErrMergeConflict
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,153 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+var testCommitter = &Signature{Name: "Soft Serve", Email: "soft-serve@example.com"}
+
+// setupMergeRepo creates a bare repository with a main branch and a feature
+// branch that diverged from it.
+func setupMergeRepo(t *testing.T, conflict bool) *Repository {
+	t.Helper()
+	is := is.New(t)
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+	bare := filepath.Join(dir, "repo.git")
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = work
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(name, content string) {
+		is.NoErr(os.WriteFile(filepath.Join(work, name), []byte(content), 0o644))
+	}
+
+	is.NoErr(os.MkdirAll(work, 0o755))
+	run("init", "-b", "main")
+	write("a.txt", "one\ntwo\nthree\nfour\nfive\n")
+	run("add", ".")
+	run("commit", "-m", "initial")
+	run("checkout", "-b", "feature")
+	write("a.txt", "ONE\ntwo\nthree\nfour\nfive\n")
+	write("b.txt", "feature\n")
+	run("add", ".")
+	run("commit", "-m", "feature 1")
+	write("c.txt", "feature\n")
+	run("add", ".")
+	run("commit", "-m", "feature 2")
+	run("checkout", "main")
+	if conflict {
+		write("a.txt", "uno\ntwo\nthree\nfour\nfive\n")
+	} else {
+		write("a.txt", "one\ntwo\nthree\nfour\nFIVE\n")
+	}
+	run("commit", "-am", "main 1")
+	run("clone", "--bare", work, bare)
+
+	r, err := Open(bare)
+	is.NoErr(err)
+	return r
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		strategy MergeStrategy
+		conflict bool
+		err      error
+		parents  int
+		ff       bool
+	}{
+		{strategy: MergeFastForward, err: ErrNotFastForward},
+		{strategy: MergeCommit, parents: 2},
+		{strategy: MergeSquash, parents: 1},
+		{strategy: MergeRebase, parents: 1},
+		{strategy: MergeCommit, conflict: true, err: ErrMergeConflict},
+		{strategy: MergeSquash, conflict: true, err: ErrMergeConflict},
+		{strategy: MergeRebase, conflict: true, err: ErrMergeConflict},
+	}
+	for _, c := range cases {
+		name := c.strategy.String()
+		if c.conflict {
+			name += "-conflict"
+		}
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			r := setupMergeRepo(t, c.conflict)
+			res, err := r.Merge("main", "feature", MergeOptions{
+				Strategy:  c.strategy,
+				Committer: testCommitter,
+			})
+			if c.err != nil {
+				is.Equal(err, c.err)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(res.FastForward, c.ff)
+
+			ref, err := r.ShowRefVerify(RefsHeads + "main")
+			is.NoErr(err)
+			is.Equal(ref, res.Hash.String())
+
+			commit, err := r.CatFileCommit(ref)
+			is.NoErr(err)
+			is.Equal(commit.ParentsCount(), c.parents)
+
+			// All changes from both branches are present.
+			out, err := NewCommand("show", ref+":a.txt").RunInDir(r.Path)
+			is.NoErr(err)
+			is.Equal(string(out), "ONE\ntwo\nthree\nfour\nFIVE\n")
+			for _, f := range []string{"b.txt", "c.txt"} {
+				_, err := NewCommand("cat-file", "-e", ref+":"+f).RunInDir(r.Path)
+				is.NoErr(err)
+			}
+
+			// Merging again is a no-op.
+			_, err = r.Merge("main", "feature", MergeOptions{Strategy: c.strategy})
+			if c.strategy != MergeSquash && c.strategy != MergeRebase {
+				is.Equal(err, ErrNothingToMerge)
+			}
+		})
+	}
+}
+
+func TestMergeFastForward(t *testing.T) {
+	is := is.New(t)
+	r := setupMergeRepo(t, false)
+	for _, s := range []MergeStrategy{MergeFastForward, MergeRebase} {
+		// Move main back so feature is a descendant of it.
+		_, err := NewCommand("update-ref", RefsHeads+"main", "feature~2").RunInDir(r.Path)
+		is.NoErr(err)
+		res, err := r.Merge("main", "feature", MergeOptions{Strategy: s})
+		is.NoErr(err)
+		is.True(res.FastForward)
+		head, err := r.ShowRefVerify(RefsHeads + "feature")
+		is.NoErr(err)
+		is.Equal(res.Hash.String(), head)
+	}
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	is := is.New(t)
+	for _, s := range MergeStrategies {
+		ms, err := ParseMergeStrategy(s.String())
+		is.NoErr(err)
+		is.Equal(ms, s)
+	}
+	_, err := ParseMergeStrategy("octopus")
+	is.Equal(err, ErrInvalidMergeStrategy)
+}
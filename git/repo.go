@@ -1,7 +1,11 @@
 package git
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gogs/git-module"
@@ -200,6 +204,84 @@ func (r *Repository) CommitsByPage(ref *Reference, page, size int) (Commits, err
 	return commits, nil
 }
 
+// CommitsFrom returns up to size commits reachable from the commit hashes in
+// cursor, in the order of git log, and the cursor of the next commits. Unlike
+// CommitsByPage, getting a page doesn't walk the commits of the previous
+// pages. The next cursor is empty after the last page.
+//
+// The cursor is the frontier of the walk, the commits git log would show next.
+// Their order matters, it breaks ties between commits with the same date.
+func (r *Repository) CommitsFrom(cursor []string, size int) (Commits, []string, error) {
+	if len(cursor) == 0 || size <= 0 {
+		return nil, nil, nil
+	}
+
+	cmd := NewCommand("log", "--pretty=format:%H", "--max-count="+strconv.Itoa(size)).
+		AddArgs(cursor...).
+		AddArgs("--")
+	out, err := cmd.RunInDir(r.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := strings.Fields(string(out))
+	commits := make(Commits, 0, len(hashes))
+	shown := make(map[string]bool, len(hashes))
+	next := append([]string(nil), cursor...)
+	for _, h := range hashes {
+		c, err := r.CatFileCommit(h)
+		if err != nil {
+			return nil, nil, err
+		}
+		commits = append(commits, &Commit{
+			Commit: c,
+			Hash:   Hash(h),
+		})
+
+		// Parents are walked after their children.
+		shown[h] = true
+		for i := 0; i < c.ParentsCount(); i++ {
+			p, err := c.ParentID(i)
+			if err != nil {
+				return nil, nil, err
+			}
+			next = append(next, p.String())
+		}
+	}
+
+	if len(commits) < size {
+		return commits, nil, nil
+	}
+
+	frontier := make([]string, 0, len(next))
+	seen := make(map[string]bool, len(next))
+	for _, h := range next {
+		if !shown[h] && !seen[h] {
+			seen[h] = true
+			frontier = append(frontier, h)
+		}
+	}
+
+	return commits, frontier, nil
+}
+
+// LatestTags returns the names of up to n tags, the most recently created
+// first. Annotated tags are dated by their tagger, others by their commit.
+// Tags of the same date are sorted by version, the highest first.
+func (r *Repository) LatestTags(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	out, err := NewCommand("for-each-ref", "--sort=-version:refname", "--sort=-creatordate", "--count="+strconv.Itoa(n),
+		"--format=%(refname:strip=2)", RefsTags).RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
 // SymbolicRef returns or updates the symbolic reference for the given name.
 // Both name and ref can be empty.
 func (r *Repository) SymbolicRef(name string, ref string, opts ...git.SymbolicRefOptions) (string, error) {
@@ -212,3 +294,212 @@ func (r *Repository) SymbolicRef(name string, ref string, opts ...git.SymbolicRe
 	opt.Ref = ref
 	return r.Repository.SymbolicRef(opt)
 }
+
+// IsAncestor returns true if the commit a is an ancestor of the commit b.
+func (r *Repository) IsAncestor(a, b string) bool {
+	_, err := NewCommand("merge-base", "--is-ancestor", a, b).RunInDir(r.Path)
+	return err == nil
+}
+
+// UnverifiedCommits returns the hashes of the commits in the given revision
+// range whose signature can't be verified, including the commits that aren't
+// signed at all. The range is passed to git rev-list as is, i.e. "old..new"
+// or "new --not --all".
+//
+// SSH signatures are verified against allowedSigners, the path of a file in
+// the format of ssh-keygen(1) "ALLOWED SIGNERS". OpenPGP signatures are
+// verified against the GnuPG keyring of the server.
+func (r *Repository) UnverifiedCommits(allowedSigners string, revs ...string) ([]Hash, error) {
+	out, err := NewCommand("rev-list").AddArgs(revs...).RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	unverified := make([]Hash, 0)
+	for _, h := range strings.Fields(string(out)) {
+		if _, err := NewCommand("-c", "gpg.ssh.allowedSignersFile="+allowedSigners,
+			"verify-commit", h).RunInDir(r.Path); err != nil {
+			unverified = append(unverified, Hash(h))
+		}
+	}
+
+	return unverified, nil
+}
+
+// Object describes an object reachable from a revision range.
+type Object struct {
+	// Hash is the object hash.
+	Hash Hash
+	// Type is the object type, i.e. "commit", "tree", "blob", or "tag".
+	Type string
+	// Size is the size of the object in bytes.
+	Size int64
+	// Path is the path of the object in the tree. It's only set for trees
+	// and blobs.
+	Path string
+}
+
+// Objects returns the objects reachable from the given revision range. The
+// range is passed to git rev-list as is, i.e. "old..new" or
+// "new --not --all".
+func (r *Repository) Objects(revs ...string) ([]Object, error) {
+	list, err := NewCommand("rev-list", "--objects").AddArgs(revs...).RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := NewCommand("cat-file", "--batch-check=%(objectname) %(objecttype) %(objectsize) %(rest)").
+		RunInDirWithOptions(r.Path, RunInDirOptions{
+			Stdin:  bytes.NewReader(list),
+			Stdout: &stdout,
+			Stderr: &stderr,
+		}); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	objs := make([]Object, 0)
+	for _, l := range strings.Split(stdout.String(), "\n") {
+		if l == "" {
+			continue
+		}
+		fields := strings.SplitN(l, " ", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		obj := Object{
+			Hash: Hash(fields[0]),
+			Type: fields[1],
+			Size: size,
+		}
+		if len(fields) == 4 {
+			obj.Path = fields[3]
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// Files returns the blobs of the tree of rev, recursively. Their hashes,
+// sizes, and paths are set.
+func (r *Repository) Files(rev string) ([]Object, error) {
+	out, err := NewCommand("ls-tree", "-r", "-l", "-z", rev).RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]Object, 0)
+	for _, l := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		meta, path, ok := strings.Cut(l, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, Object{
+			Hash: Hash(fields[2]),
+			Type: fields[1],
+			Size: size,
+			Path: path,
+		})
+	}
+
+	return objs, nil
+}
+
+// Archive writes an archive of the tree of rev to w, format is any format of
+// git archive, like "tar.gz" or "zip". The paths in the archive start with
+// prefix.
+func (r *Repository) Archive(w io.Writer, format string, rev string, prefix string) error {
+	var stderr bytes.Buffer
+	if err := NewCommand("archive", "--format="+format, "--prefix="+prefix, rev).
+		RunInDirWithOptions(r.Path, RunInDirOptions{
+			Stdout: w,
+			Stderr: &stderr,
+		}); err != nil {
+		return fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// FormatPatch writes the commits from base to head to w as patches in the
+// mbox format of git format-patch, ready to be applied with git am. An empty
+// base writes the patch of the head commit only.
+func (r *Repository) FormatPatch(w io.Writer, base string, head string) error {
+	args := []string{"format-patch", "--stdout", "--no-signature", "--no-color"}
+	if base == "" {
+		args = append(args, "-1", head)
+	} else {
+		args = append(args, base+".."+head)
+	}
+
+	return r.run(w, append(args, "--")...)
+}
+
+// DiffPatch writes the diff of head against the merge base of base and head
+// to w. An empty base writes the diff of the head commit against its first
+// parent.
+func (r *Repository) DiffPatch(w io.Writer, base string, head string) error {
+	args := []string{"diff", "--no-color", base + "..." + head}
+	if base == "" {
+		args = []string{"diff-tree", "--patch", "--root", "--no-commit-id", "--first-parent", "--no-color", head}
+	}
+
+	return r.run(w, append(args, "--")...)
+}
+
+// DiffRangeOptions are the options of DiffRange.
+type DiffRangeOptions struct {
+	// MergeBase compares head to the merge base of base and head, like
+	// base...head.
+	MergeBase bool
+	// Stat writes the diffstat instead of the patch.
+	Stat bool
+	// NameOnly writes the names of the changed files instead of the patch.
+	NameOnly bool
+}
+
+// DiffRange writes the diff from base to head to w.
+func (r *Repository) DiffRange(w io.Writer, base string, head string, opts DiffRangeOptions) error {
+	sep := ".."
+	if opts.MergeBase {
+		sep = "..."
+	}
+
+	args := []string{"diff", "--no-color"}
+	switch {
+	case opts.NameOnly:
+		args = append(args, "--name-only")
+	case opts.Stat:
+		args = append(args, "--stat")
+	}
+
+	return r.run(w, append(args, base+sep+head, "--")...)
+}
+
+// run runs the git command in the repository and writes its output to w.
+func (r *Repository) run(w io.Writer, args ...string) error {
+	var stderr bytes.Buffer
+	if err := NewCommand(args...).
+		RunInDirWithOptions(r.Path, RunInDirOptions{
+			Stdout: w,
+			Stderr: &stderr,
+		}); err != nil {
+		return fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestObjects(t *testing.T) {
+	is := is.New(t)
+	r := setupMergeRepo(t, false)
+
+	objs, err := r.Objects("main..feature")
+	is.NoErr(err)
+
+	blobs := map[string]int64{}
+	var commits int
+	for _, obj := range objs {
+		switch obj.Type {
+		case "blob":
+			blobs[obj.Path] = obj.Size
+		case "commit":
+			commits++
+		}
+	}
+	is.Equal(commits, 2)
+	// b.txt and c.txt have the same content, objects are only listed once.
+	is.Equal(blobs, map[string]int64{
+		"a.txt": int64(len("ONE\ntwo\nthree\nfour\nfive\n")),
+		"b.txt": int64(len("feature\n")),
+	})
+}
+
+func TestFiles(t *testing.T) {
+	is := is.New(t)
+	r := setupMergeRepo(t, false)
+
+	files, err := r.Files("feature")
+	is.NoErr(err)
+
+	sizes := map[string]int64{}
+	for _, f := range files {
+		is.Equal(f.Type, "blob")
+		sizes[f.Path] = f.Size
+	}
+	is.Equal(sizes, map[string]int64{
+		"a.txt": int64(len("ONE\ntwo\nthree\nfour\nfive\n")),
+		"b.txt": int64(len("feature\n")),
+		"c.txt": int64(len("feature\n")),
+	})
+}
+
+func TestCommitsFrom(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+
+	// A history with merges, and commits sharing the same date.
+	date := 1700000000
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			fmt.Sprintf("GIT_AUTHOR_DATE=%d +0000", date/2*2),
+			fmt.Sprintf("GIT_COMMITTER_DATE=%d +0000", date/2*2),
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		date++
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-b", "main")
+	run("commit", "--allow-empty", "-m", "initial")
+	for i := 0; i < 3; i++ {
+		run("checkout", "-b", fmt.Sprintf("feature%d", i), "main")
+		for j := 0; j < 3; j++ {
+			run("commit", "--allow-empty", "-m", fmt.Sprintf("feature %d %d", i, j))
+		}
+		run("checkout", "main")
+		run("commit", "--allow-empty", "-m", fmt.Sprintf("main %d", i))
+		run("merge", "--no-ff", "-m", fmt.Sprintf("merge %d", i), fmt.Sprintf("feature%d", i))
+	}
+	head := run("rev-parse", "HEAD")
+	want := strings.Fields(run("log", "--pretty=format:%H", "main"))
+
+	r, err := Open(dir)
+	is.NoErr(err)
+	for _, size := range []int{1, 2, 3, 5, len(want), len(want) + 1} {
+		var got []string
+		cursor := []string{head}
+		for len(cursor) > 0 {
+			var cc Commits
+			cc, cursor, err = r.CommitsFrom(cursor, size)
+			is.NoErr(err)
+			is.True(len(cc) <= size)
+			for _, c := range cc {
+				got = append(got, c.Hash.String())
+			}
+		}
+		is.Equal(got, want) // pages of the same commits in the same order
+	}
+}
+
+func TestLatestTags(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+
+	date := 1700000000
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			fmt.Sprintf("GIT_AUTHOR_DATE=%d +0000", date),
+			fmt.Sprintf("GIT_COMMITTER_DATE=%d +0000", date),
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+		date += 60
+	}
+	run("init", "-b", "main")
+	run("commit", "--allow-empty", "-m", "initial")
+	run("tag", "v1")
+	run("commit", "--allow-empty", "-m", "second")
+	// Annotated tags are dated by their tagger, not their commit.
+	run("tag", "-a", "-m", "release", "v0", "HEAD~1")
+	run("tag", "v2")
+
+	r, err := Open(dir)
+	is.NoErr(err)
+	tags, err := r.LatestTags(10)
+	is.NoErr(err)
+	is.Equal(tags, []string{"v0", "v2", "v1"})
+	tags, err = r.LatestTags(1)
+	is.NoErr(err)
+	is.Equal(tags, []string{"v0"})
+}
+
+func TestDiffRange(t *testing.T) {
+	is := is.New(t)
+	r := setupMergeRepo(t, false)
+
+	var names strings.Builder
+	is.NoErr(r.DiffRange(&names, "main", "feature", DiffRangeOptions{NameOnly: true}))
+	is.Equal(names.String(), "a.txt\nb.txt\nc.txt\n")
+
+	// main changed a.txt after feature branched off, the merge base only has
+	// the changes of feature.
+	var patch strings.Builder
+	is.NoErr(r.DiffRange(&patch, "main", "feature", DiffRangeOptions{}))
+	is.True(strings.Contains(patch.String(), "-FIVE\n"))
+	patch.Reset()
+	is.NoErr(r.DiffRange(&patch, "main", "feature", DiffRangeOptions{MergeBase: true}))
+	is.True(!strings.Contains(patch.String(), "FIVE"))
+	is.True(strings.Contains(patch.String(), "+ONE\n"))
+
+	var stat strings.Builder
+	is.NoErr(r.DiffRange(&stat, "main", "feature", DiffRangeOptions{Stat: true}))
+	is.True(strings.Contains(stat.String(), "3 files changed"))
+}
+
+func TestBlame(t *testing.T) {
+	is := is.New(t)
+	r := setupMergeRepo(t, false)
+
+	lines, err := r.Blame("feature", "a.txt", 0, 0)
+	is.NoErr(err)
+	is.Equal(len(lines), 5)
+	is.Equal(lines[0].Number, 1)
+	is.Equal(lines[0].Content, "ONE")
+	is.Equal(lines[0].Summary, "feature 1")
+	is.Equal(lines[0].Author, "test")
+	is.Equal(lines[0].AuthorEmail, "test@example.com")
+	is.True(!lines[0].AuthorTime.IsZero())
+	is.Equal(lines[1].Summary, "initial")
+
+	lines, err = r.Blame("main", "a.txt", 4, 5)
+	is.NoErr(err)
+	is.Equal(len(lines), 2)
+	is.Equal(lines[0].Number, 4)
+	is.Equal(lines[1].Content, "FIVE")
+	is.Equal(lines[1].Summary, "main 1")
+	is.True(lines[0].Commit != lines[1].Commit)
+
+	_, err = r.Blame("main", "b.txt", 0, 0)
+	is.True(err != nil)
+}
+
+func TestGrep(t *testing.T) {
+	is := is.New(t)
+	r := setupMergeRepo(t, false)
+
+	matches, more, err := r.Grep(context.Background(), "feature", "^(one|feature)$", GrepOptions{IgnoreCase: true})
+	is.NoErr(err)
+	is.True(!more)
+	is.Equal(matches, []GrepMatch{
+		{Path: "a.txt", Line: 1, Content: "ONE"},
+		{Path: "b.txt", Line: 1, Content: "feature"},
+		{Path: "c.txt", Line: 1, Content: "feature"},
+	})
+
+	matches, more, err = r.Grep(context.Background(), "feature", "feature", GrepOptions{Limit: 1})
+	is.NoErr(err)
+	is.True(more)
+	is.Equal(len(matches), 1)
+
+	matches, _, err = r.Grep(context.Background(), "main", "f", GrepOptions{Extensions: []string{"md"}})
+	is.NoErr(err)
+	is.Equal(len(matches), 0)
+
+	_, _, err = r.Grep(context.Background(), "main", "(", GrepOptions{})
+	is.True(err != nil)
+}
+
+func TestUnverifiedCommits(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	is := is.New(t)
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = work
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	keygen := func(name string) string {
+		key := filepath.Join(dir, name)
+		out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput()
+		if err != nil {
+			t.Fatalf("ssh-keygen: %v: %s", err, out)
+		}
+		return key
+	}
+	commit := func(msg, key string) {
+		args := []string{"commit", "--allow-empty", "-m", msg}
+		if key != "" {
+			args = append([]string{"-c", "gpg.format=ssh", "-c", "user.signingkey=" + key}, append(args, "-S")...)
+		}
+		run(args...)
+	}
+
+	trusted, untrusted := keygen("trusted"), keygen("untrusted")
+	pub, err := os.ReadFile(trusted + ".pub")
+	is.NoErr(err)
+	signers := filepath.Join(dir, "allowed_signers")
+	is.NoErr(os.WriteFile(signers, append([]byte("user1 "), pub...), 0o600))
+
+	is.NoErr(os.MkdirAll(work, 0o755))
+	run("init", "-b", "main")
+	commit("base", "")
+	commit("trusted", trusted)
+	commit("unsigned", "")
+	commit("untrusted", untrusted)
+
+	r, err := Open(work)
+	is.NoErr(err)
+
+	hashes := func(revs ...string) []string {
+		out, err := NewCommand("rev-list").AddArgs(revs...).RunInDir(work)
+		is.NoErr(err)
+		return strings.Fields(string(out))
+	}
+	all := hashes("main~3..main")
+
+	unverified, err := r.UnverifiedCommits(signers, "main~3..main")
+	is.NoErr(err)
+	is.Equal(unverified, []Hash{Hash(all[0]), Hash(all[1])})
+
+	unverified, err = r.UnverifiedCommits(signers, "main~3..main~2")
+	is.NoErr(err)
+	is.Equal(len(unverified), 0)
+}
@@ -0,0 +1,104 @@
+package access
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IPRules are the CIDR rules deciding which addresses can connect. An address
+// is allowed when it matches none of the deny rules, and matches one of the
+// allow rules, or there are no allow rules.
+type IPRules struct {
+	Allow []*net.IPNet
+	Deny  []*net.IPNet
+}
+
+// ParseIPRules parses the allow and deny rules. Rules are either CIDRs, like
+// "10.0.0.0/8", or single addresses.
+func ParseIPRules(allow, deny []string) (IPRules, error) {
+	var r IPRules
+	var err error
+	if r.Allow, err = parseCIDRs(allow); err != nil {
+		return IPRules{}, err
+	}
+	if r.Deny, err = parseCIDRs(deny); err != nil {
+		return IPRules{}, err
+	}
+	return r, nil
+}
+
+// ParseCIDR parses a CIDR, or a single address as a CIDR matching only that
+// address.
+func ParseCIDR(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address %q", s)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cidr %q", s)
+	}
+	return n, nil
+}
+
+func parseCIDRs(ss []string) ([]*net.IPNet, error) {
+	var ns []*net.IPNet
+	for _, s := range ss {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		n, err := ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// IsZero returns true if there are no rules.
+func (r IPRules) IsZero() bool {
+	return len(r.Allow) == 0 && len(r.Deny) == 0
+}
+
+// Allows returns true if ip is allowed by the rules.
+func (r IPRules) Allows(ip net.IP) bool {
+	if ip == nil {
+		return r.IsZero()
+	}
+	for _, n := range r.Deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(r.Allow) == 0 {
+		return true
+	}
+	for _, n := range r.Allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Strings returns the allow and deny rules as CIDRs.
+func (r IPRules) Strings() (allow []string, deny []string) {
+	for _, n := range r.Allow {
+		allow = append(allow, n.String())
+	}
+	for _, n := range r.Deny {
+		deny = append(deny, n.String())
+	}
+	return allow, deny
+}
@@ -0,0 +1,52 @@
+package access
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIPRules(t *testing.T) {
+	cases := []struct {
+		allow []string
+		deny  []string
+		ip    string
+		out   bool
+	}{
+		{nil, nil, "192.0.2.1", true},
+		{[]string{"10.0.0.0/8"}, nil, "10.1.2.3", true},
+		{[]string{"10.0.0.0/8"}, nil, "192.0.2.1", false},
+		{[]string{"10.0.0.0/8"}, []string{"10.1.0.0/16"}, "10.1.2.3", false},
+		{nil, []string{"192.0.2.1"}, "192.0.2.1", false},
+		{nil, []string{"192.0.2.1"}, "192.0.2.2", true},
+		{[]string{"192.0.2.1"}, nil, "::ffff:192.0.2.1", true},
+		{[]string{"2001:db8::/32"}, nil, "2001:db8::1", true},
+		{[]string{"2001:db8::/32"}, nil, "192.0.2.1", false},
+	}
+
+	for _, c := range cases {
+		r, err := ParseIPRules(c.allow, c.deny)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out := r.Allows(net.ParseIP(c.ip)); out != c.out {
+			t.Errorf("allow %v deny %v: Allows(%q) => %t, want %t", c.allow, c.deny, c.ip, out, c.out)
+		}
+	}
+}
+
+func TestParseIPRules(t *testing.T) {
+	for _, s := range []string{"foo", "10.0.0.0/33", "10.0.0"} {
+		if _, err := ParseIPRules([]string{s}, nil); err == nil {
+			t.Errorf("ParseIPRules(%q) => nil error", s)
+		}
+	}
+
+	r, err := ParseIPRules([]string{" 10.1.2.3/8 ", ""}, []string{"2001:db8::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow, deny := r.Strings()
+	if len(allow) != 1 || allow[0] != "10.0.0.0/8" || len(deny) != 1 || deny[0] != "2001:db8::1/128" {
+		t.Errorf("unexpected rules %v %v", allow, deny)
+	}
+}
@@ -0,0 +1,37 @@
+// Package auth implements the providers users are sourced from, such as a
+// corporate directory, and the identity providers they log in with.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrInvalidCredentials is returned when a provider rejects the credentials
+// of a user.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// User is a user of a provider.
+type User struct {
+	// Username is the username of the user.
+	Username string
+	// Admin is whether the user is an admin.
+	Admin bool
+	// PublicKeys are the SSH public keys of the user.
+	PublicKeys []ssh.PublicKey
+}
+
+// Provider is a source of users.
+type Provider interface {
+	// Name returns the name of the provider. It identifies the users managed
+	// by the provider.
+	Name() string
+	// Users returns all the users of the provider.
+	Users(ctx context.Context) ([]User, error)
+	// Authenticate verifies the password of a user. It returns
+	// ErrInvalidCredentials if the user doesn't exist or the password is
+	// wrong.
+	Authenticate(ctx context.Context, username, password string) error
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/ldap"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"golang.org/x/crypto/ssh"
+)
+
+// LDAP is a provider of the users of an LDAP directory. Members of the admin
+// groups are admins, and only members of the user groups are users when any
+// are configured.
+type LDAP struct {
+	cfg config.LDAPConfig
+}
+
+var _ Provider = (*LDAP)(nil)
+
+// NewLDAP returns a new LDAP provider.
+func NewLDAP(cfg config.LDAPConfig) *LDAP {
+	return &LDAP{cfg: cfg}
+}
+
+// Name implements Provider.
+func (l *LDAP) Name() string {
+	return "ldap"
+}
+
+// Users implements Provider.
+func (l *LDAP) Users(ctx context.Context) ([]User, error) {
+	logger := log.FromContext(ctx).WithPrefix("auth.ldap")
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close() // nolint: errcheck
+	entries, err := l.search(conn, l.cfg.UserFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, 0, len(entries))
+	for _, e := range entries {
+		u, ok := l.userFromEntry(e, logger)
+		if ok {
+			users = append(users, u)
+		}
+	}
+
+	return users, nil
+}
+
+// Authenticate implements Provider. It binds as the user's entry with the
+// password.
+func (l *LDAP) Authenticate(ctx context.Context, username, password string) error {
+	// Binds without a password are unauthenticated binds, and succeed for
+	// any DN.
+	if password == "" {
+		return ErrInvalidCredentials
+	}
+
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close() // nolint: errcheck
+	filter := fmt.Sprintf("(&%s(%s=%s))", l.cfg.UserFilter, l.cfg.UsernameAttribute, ldap.EscapeFilter(username))
+	entries, err := l.search(conn, filter)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) != 1 {
+		return ErrInvalidCredentials
+	}
+
+	if _, ok := l.userFromEntry(entries[0], log.FromContext(ctx).WithPrefix("auth.ldap")); !ok {
+		return ErrInvalidCredentials
+	}
+
+	if err := conn.Bind(entries[0].DN, password); err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			return ErrInvalidCredentials
+		}
+		return err
+	}
+
+	return nil
+}
+
+// dial connects to the directory and binds as the bind account.
+func (l *LDAP) dial(ctx context.Context) (*ldap.Conn, error) {
+	tlsConfig, err := l.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := ldap.Dial(ctx, l.cfg.URL, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close() // nolint: errcheck
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if l.cfg.BindDN != "" {
+		if err := conn.Bind(l.cfg.BindDN, l.cfg.BindPassword); err != nil {
+			conn.Close() // nolint: errcheck
+			return nil, fmt.Errorf("bind: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// tlsConfig returns the TLS config trusting the configured certificates, or
+// nil to use the system roots.
+func (l *LDAP) tlsConfig() (*tls.Config, error) {
+	if l.cfg.CACertPath == "" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(l.cfg.CACertPath)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", l.cfg.CACertPath)
+	}
+
+	return &tls.Config{RootCAs: roots}, nil // nolint: gosec
+}
+
+func (l *LDAP) search(conn *ldap.Conn, filter string) ([]*ldap.Entry, error) {
+	return conn.Search(l.cfg.BaseDN, ldap.ScopeWholeSubtree, filter,
+		l.cfg.UsernameAttribute,
+		l.cfg.PublicKeyAttribute,
+		l.cfg.GroupAttribute,
+	)
+}
+
+// userFromEntry maps a directory entry to a user. It returns false if the
+// entry isn't a member of the user groups or doesn't have a valid username.
+func (l *LDAP) userFromEntry(e *ldap.Entry, logger *log.Logger) (User, bool) {
+	groups := e.Values(l.cfg.GroupAttribute)
+	userGroups := nonEmpty(l.cfg.UserGroups)
+	if len(userGroups) > 0 && !intersects(groups, userGroups) {
+		return User{}, false
+	}
+
+	username := strings.ToLower(e.Value(l.cfg.UsernameAttribute))
+	if err := utils.ValidateUsername(username); err != nil {
+		logger.Warn("skipping entry with invalid username", "dn", e.DN, "err", err)
+		return User{}, false
+	}
+
+	var pks []ssh.PublicKey
+	for _, ak := range e.Values(l.cfg.PublicKeyAttribute) {
+		pk, _, err := sshutils.ParseAuthorizedKey(ak)
+		if err != nil {
+			logger.Warn("skipping invalid public key", "dn", e.DN, "err", err)
+			continue
+		}
+		pks = append(pks, pk)
+	}
+
+	return User{
+		Username:   username,
+		Admin:      intersects(groups, nonEmpty(l.cfg.AdminGroups)),
+		PublicKeys: pks,
+	}, true
+}
+
+// intersects returns whether any of the DNs of a are in b. DNs are compared
+// case-insensitively.
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(strings.TrimSpace(x), strings.TrimSpace(y)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func nonEmpty(ss []string) []string {
+	var out []string
+	for _, s := range ss {
+		if strings.TrimSpace(s) != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
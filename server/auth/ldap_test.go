@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/ldap"
+)
+
+const testKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINMwLvyV3ouVrTysUYGoJdl5Vgn5BACKov+n9PlzfPwH"
+
+func TestUserFromEntry(t *testing.T) {
+	cfg := config.DefaultConfig().LDAP
+	cfg.AdminGroups = []string{"cn=admins,ou=groups,dc=example,dc=org"}
+	l := NewLDAP(cfg)
+
+	e := &ldap.Entry{
+		DN: "uid=alice,ou=people,dc=example,dc=org",
+		Attributes: map[string][]string{
+			"uid":          {"Alice"},
+			"sshpublickey": {testKey + " alice@laptop", "not a key"},
+			"memberof":     {"CN=Admins,OU=Groups,DC=example,DC=org"},
+		},
+	}
+
+	u, ok := l.userFromEntry(e, log.Default())
+	if !ok {
+		t.Fatal("expected user")
+	}
+	if u.Username != "alice" {
+		t.Errorf("username = %q, want alice", u.Username)
+	}
+	if !u.Admin {
+		t.Error("expected admin")
+	}
+	if len(u.PublicKeys) != 1 {
+		t.Errorf("got %d public keys, want 1", len(u.PublicKeys))
+	}
+
+	// Only members of the user groups are users once any are configured.
+	l.cfg.UserGroups = []string{"cn=developers,ou=groups,dc=example,dc=org"}
+	if _, ok := l.userFromEntry(e, log.Default()); ok {
+		t.Error("expected non-member to be skipped")
+	}
+
+	e.Attributes["memberof"] = []string{"cn=developers,ou=groups,dc=example,dc=org"}
+	u, ok = l.userFromEntry(e, log.Default())
+	if !ok || u.Admin {
+		t.Errorf("got %+v, %t, want non-admin user", u, ok)
+	}
+
+	e.Attributes["uid"] = []string{"-alice"}
+	if _, ok := l.userFromEntry(e, log.Default()); ok {
+		t.Error("expected invalid username to be skipped")
+	}
+}
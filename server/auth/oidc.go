@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcSigningMethods are the algorithms ID tokens may be signed with.
+var oidcSigningMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Identity is an identity verified by an OpenID Connect provider.
+type Identity struct {
+	// Subject is the identifier of the identity at the provider.
+	Subject string
+	// Username is the username claim of the identity.
+	Username string
+}
+
+// OIDC is an OpenID Connect provider users log in with. It implements the
+// authorization code flow.
+type OIDC struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      *jose.JSONWebKeySet
+}
+
+// oidcDiscovery is the discovery document of a provider.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDC returns a new OpenID Connect provider.
+func NewOIDC(cfg config.OIDCConfig) *OIDC {
+	return &OIDC{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL returns the URL of the provider users are sent to to log in.
+// The provider redirects them to redirectURL with the state once they do.
+func (o *OIDC) AuthCodeURL(ctx context.Context, redirectURL, state, nonce string) (string, error) {
+	d, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", o.cfg.ClientID)
+	v.Set("redirect_uri", redirectURL)
+	v.Set("scope", strings.Join(o.scopes(), " "))
+	v.Set("state", state)
+	v.Set("nonce", nonce)
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return d.AuthorizationEndpoint + sep + v.Encode(), nil
+}
+
+// Exchange exchanges an authorization code for the identity of the user. The
+// ID token must be bound to nonce.
+func (o *OIDC) Exchange(ctx context.Context, redirectURL, code, nonce string) (Identity, error) {
+	d, err := o.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	v.Set("redirect_uri", redirectURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(v.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(o.cfg.ClientSecret))
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := o.do(req, &token); err != nil {
+		return Identity{}, fmt.Errorf("token exchange: %w", err)
+	}
+
+	if token.IDToken == "" {
+		return Identity{}, errors.New("token exchange: missing id token")
+	}
+
+	return o.verify(ctx, d, token.IDToken, nonce)
+}
+
+// verify verifies an ID token and returns its identity.
+func (o *OIDC) verify(ctx context.Context, d *oidcDiscovery, idToken, nonce string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return o.key(ctx, d, kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(o.cfg.ClientID),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return Identity{}, fmt.Errorf("%w: id token doesn't expire", ErrInvalidCredentials)
+	}
+
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return Identity{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidCredentials)
+	}
+
+	sub, _ := claims["sub"].(string)
+	username, _ := claims[o.cfg.UsernameClaim].(string)
+	if sub == "" || username == "" {
+		return Identity{}, fmt.Errorf("%w: missing sub or %s claim", ErrInvalidCredentials, o.cfg.UsernameClaim)
+	}
+
+	return Identity{Subject: sub, Username: username}, nil
+}
+
+// key returns the public key of the provider with the key ID kid. The keys
+// are fetched again when it isn't known, providers rotate their keys.
+func (o *OIDC) key(ctx context.Context, d *oidcDiscovery, kid string) (interface{}, error) {
+	find := func(ks *jose.JSONWebKeySet) interface{} {
+		for _, k := range ks.Keys {
+			if (kid == "" || k.KeyID == kid) && k.Use != "enc" {
+				return k.Key
+			}
+		}
+		return nil
+	}
+
+	o.mu.Lock()
+	keys := o.keys
+	o.mu.Unlock()
+	if keys != nil {
+		if k := find(keys); k != nil {
+			return k, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keys = &jose.JSONWebKeySet{}
+	if err := o.do(req, keys); err != nil {
+		return nil, fmt.Errorf("fetch keys: %w", err)
+	}
+
+	o.mu.Lock()
+	o.keys = keys
+	o.mu.Unlock()
+
+	if k := find(keys); k != nil {
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// discover returns the discovery document of the provider. It's fetched once.
+func (o *OIDC) discover(ctx context.Context) (*oidcDiscovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.discovery != nil {
+		return o.discovery, nil
+	}
+
+	issuer := strings.TrimSuffix(o.cfg.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var d oidcDiscovery
+	if err := o.do(req, &d); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	if strings.TrimSuffix(d.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("discovery: issuer %q doesn't match %q", d.Issuer, o.cfg.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("discovery: missing endpoints")
+	}
+
+	o.discovery = &d
+	return o.discovery, nil
+}
+
+// do sends a request and decodes the JSON response into v.
+func (o *OIDC) do(req *http.Request, v interface{}) error {
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func (o *OIDC) scopes() []string {
+	scopes := []string{"openid"}
+	for _, s := range o.cfg.Scopes {
+		if s = strings.TrimSpace(s); s != "" && s != "openid" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newProvider returns a fake OpenID Connect provider issuing ID tokens with
+// the claims returned by claims.
+func newProvider(t *testing.T, claims func(issuer string) jwt.MapClaims) *httptest.Server {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{ // nolint: errcheck
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"jwks_uri":               srv.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{ // nolint: errcheck
+			{Key: key.Public(), KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "soft-serve" || secret != "secret" || r.FormValue("code") != "code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims(srv.URL))
+		token.Header["kid"] = "test"
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": s}) // nolint: errcheck
+	})
+
+	return srv
+}
+
+func testOIDCConfig(issuer string) config.OIDCConfig {
+	cfg := config.DefaultConfig().OIDC
+	cfg.Enabled = true
+	cfg.Issuer = issuer
+	cfg.ClientID = "soft-serve"
+	cfg.ClientSecret = "secret"
+	return cfg
+}
+
+func TestOIDCExchange(t *testing.T) {
+	now := time.Now()
+	var aud atomic.Value
+	aud.Store("")
+	srv := newProvider(t, func(issuer string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                issuer,
+			"sub":                "1234",
+			"aud":                aud.Load(),
+			"exp":                now.Add(time.Minute).Unix(),
+			"iat":                now.Unix(),
+			"nonce":              "nonce",
+			"preferred_username": "Alice",
+		}
+	})
+
+	ctx := context.TODO()
+	o := NewOIDC(testOIDCConfig(srv.URL))
+	u, err := o.AuthCodeURL(ctx, "http://localhost/login/callback", "state", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pu, err := url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := pu.Query()
+	if pu.Path != "/authorize" || q.Get("state") != "state" || q.Get("client_id") != "soft-serve" || q.Get("scope") != "openid profile email" {
+		t.Errorf("unexpected auth code url %s", u)
+	}
+
+	aud.Store("soft-serve")
+	id, err := o.Exchange(ctx, "http://localhost/login/callback", "code", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "1234" || id.Username != "Alice" {
+		t.Errorf("got %+v", id)
+	}
+
+	if _, err := o.Exchange(ctx, "http://localhost/login/callback", "code", "other"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected nonce mismatch to be rejected, got %v", err)
+	}
+
+	aud.Store("other-client")
+	if _, err := o.Exchange(ctx, "http://localhost/login/callback", "code", "nonce"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected other audience to be rejected, got %v", err)
+	}
+
+	if _, err := o.Exchange(ctx, "http://localhost/login/callback", "bad", "nonce"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected token exchange error, got %v", err)
+	}
+}
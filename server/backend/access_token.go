@@ -3,39 +3,112 @@ package backend
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/proto"
 )
 
-// CreateAccessToken creates an access token for user.
-func (b *Backend) CreateAccessToken(ctx context.Context, user proto.User, name string, expiresAt time.Time) (string, error) {
+// CreateAccessToken creates an access token for user. The token grants at
+// most level access, and only to repo if it isn't empty.
+func (b *Backend) CreateAccessToken(ctx context.Context, user proto.User, name string, expiresAt time.Time, level access.AccessLevel, repo string) (string, error) {
+	if level < access.ReadOnlyAccess || level > access.AdminAccess {
+		return "", access.ErrInvalidAccessLevel
+	}
+
+	var repoID int64
+	if repo != "" {
+		r, err := b.Repository(ctx, repo)
+		if err != nil {
+			return "", err
+		}
+
+		// Don't reveal private repositories.
+		if b.AccessLevelForUser(ctx, r.Name(), user) < access.ReadOnlyAccess {
+			return "", proto.ErrRepoNotFound
+		}
+
+		repoID = r.ID()
+	}
+
 	token := GenerateToken()
 	tokenHash := HashToken(token)
 
+	var id int64
 	if err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
-		_, err := b.store.CreateAccessToken(ctx, tx, name, user.ID(), tokenHash, expiresAt)
+		t, err := b.store.CreateAccessToken(ctx, tx, name, user.ID(), tokenHash, expiresAt, level, repoID)
 		if err != nil {
 			return db.WrapError(err)
 		}
 
+		id = t.ID
 		return nil
 	}); err != nil {
 		return "", err
 	}
 
+	b.Audit(ctx, proto.AuditEvent{
+		Action:   proto.AuditTokenCreate,
+		Username: user.Username(),
+		Repo:     repo,
+		Target:   strconv.FormatInt(id, 10),
+		Details:  name,
+	})
+
 	return token, nil
 }
 
+// RotateAccessToken replaces the secret of an access token of a user and
+// returns the new one. The token keeps its name and scope. Tokens that expire
+// get a new expiration date with the same lifetime as before.
+func (b *Backend) RotateAccessToken(ctx context.Context, user proto.User, id int64) (string, time.Time, error) {
+	token := GenerateToken()
+	var expiresAt time.Time
+	err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		t, err := b.store.GetAccessToken(ctx, tx, id)
+		if err != nil {
+			return db.WrapError(err)
+		}
+
+		if t.UserID != user.ID() {
+			return proto.ErrTokenNotFound
+		}
+
+		if t.ExpiresAt.Valid {
+			expiresAt = time.Now().Add(t.ExpiresAt.Time.Sub(t.CreatedAt))
+		}
+
+		if err := b.store.RotateAccessTokenForUser(ctx, tx, user.ID(), id, HashToken(token), expiresAt); err != nil {
+			return db.WrapError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return "", time.Time{}, proto.ErrTokenNotFound
+		}
+		return "", time.Time{}, err
+	}
+
+	b.Audit(ctx, proto.AuditEvent{Action: proto.AuditTokenRotate, Username: user.Username(), Target: strconv.FormatInt(id, 10)})
+
+	return token, expiresAt, nil
+}
+
 // DeleteAccessToken deletes an access token for a user.
 func (b *Backend) DeleteAccessToken(ctx context.Context, user proto.User, id int64) error {
 	err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
-		_, err := b.store.GetAccessToken(ctx, tx, id)
+		t, err := b.store.GetAccessToken(ctx, tx, id)
 		if err != nil {
 			return db.WrapError(err)
 		}
 
+		if t.UserID != user.ID() {
+			return proto.ErrTokenNotFound
+		}
+
 		if err := b.store.DeleteAccessTokenForUser(ctx, tx, user.ID(), id); err != nil {
 			return db.WrapError(err)
 		}
@@ -48,6 +121,8 @@ func (b *Backend) DeleteAccessToken(ctx context.Context, user proto.User, id int
 		return err
 	}
 
+	b.Audit(ctx, proto.AuditEvent{Action: proto.AuditTokenDelete, Username: user.Username(), Target: strconv.FormatInt(id, 10)})
+
 	return nil
 }
 
@@ -58,21 +133,52 @@ func (b *Backend) ListAccessTokens(ctx context.Context, user proto.User) ([]prot
 		return nil, db.WrapError(err)
 	}
 
+	repoNames := make(map[int64]string)
+	for _, t := range accessTokens {
+		if t.RepoID.Valid {
+			repos, err := b.store.GetAllRepos(ctx, b.db)
+			if err != nil {
+				return nil, db.WrapError(err)
+			}
+
+			for _, r := range repos {
+				repoNames[r.ID] = r.Name
+			}
+			break
+		}
+	}
+
 	var tokens []proto.AccessToken
 	for _, t := range accessTokens {
 		token := proto.AccessToken{
-			ID:        t.ID,
-			Name:      t.Name,
-			TokenHash: t.Token,
-			UserID:    t.UserID,
-			CreatedAt: t.CreatedAt,
+			ID:          t.ID,
+			Name:        t.Name,
+			TokenHash:   t.Token,
+			UserID:      t.UserID,
+			CreatedAt:   t.CreatedAt,
+			AccessLevel: t.AccessLevel,
 		}
 		if t.ExpiresAt.Valid {
 			token.ExpiresAt = t.ExpiresAt.Time
 		}
+		if t.RepoID.Valid {
+			token.Repo = repoNames[t.RepoID.Int64]
+		}
 
 		tokens = append(tokens, token)
 	}
 
 	return tokens, nil
 }
+
+// AccessTokenScope returns the scope of the access token user authenticated
+// with, the highest access level it grants and the ID of the repository it's
+// limited to, if any. ok is false when user didn't authenticate with a token.
+func (b *Backend) AccessTokenScope(u proto.User) (level access.AccessLevel, repoID int64, ok bool) {
+	tu, isUser := u.(*user)
+	if !isUser || tu.token == nil {
+		return access.NoAccess, 0, false
+	}
+
+	return tu.token.AccessLevel, tu.token.RepoID.Int64, true
+}
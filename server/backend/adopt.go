@@ -0,0 +1,214 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// defaultGitDescription is the description git init writes.
+const defaultGitDescription = "Unnamed repository; edit this file 'description' to name the repository."
+
+// BareRepository is an existing bare git repository found on disk.
+type BareRepository struct {
+	// Name is the name the repository is registered with, its path relative
+	// to the searched directory without the .git suffix.
+	Name string
+	// Path is the path of the repository.
+	Path string
+}
+
+// FindBareRepositories returns the bare git repositories in a directory,
+// sorted by name. If recursive is true, the subdirectories that aren't
+// repositories are searched too, and the repositories found there are named
+// after their namespace, i.e. team/app for team/app.git.
+func FindBareRepositories(root string, recursive bool) ([]BareRepository, error) {
+	var repos []BareRepository
+	err := filepath.WalkDir(root, func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !de.IsDir() {
+			return nil
+		}
+
+		if p != root && isBareRepository(p) {
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+
+			repos = append(repos, BareRepository{
+				Name: utils.SanitizeRepo(filepath.ToSlash(rel)),
+				Path: p,
+			})
+			return fs.SkipDir
+		}
+
+		if p != root && !recursive {
+			return fs.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+
+	return repos, nil
+}
+
+// isBareRepository returns true if a directory looks like a bare git
+// repository.
+func isBareRepository(dir string) bool {
+	if fi, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || fi.IsDir() {
+		return false
+	}
+	for _, d := range []string{"objects", "refs"} {
+		if fi, err := os.Stat(filepath.Join(dir, d)); err != nil || !fi.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AdoptRepository registers the existing bare git repository at src as the
+// repository name, owned by user. The repository is copied to the repository
+// storage, or moved there if move is true. Its description is kept unless
+// opts has one.
+func (d *Backend) AdoptRepository(ctx context.Context, name string, src string, user proto.User, opts proto.RepositoryOptions, move bool) (proto.Repository, error) {
+	name = utils.SanitizeRepo(name)
+	if err := utils.ValidateRepo(name); err != nil {
+		return nil, err
+	}
+
+	if err := d.CheckMaintenance(ctx, name); err != nil {
+		return nil, err
+	}
+
+	if !isBareRepository(src) {
+		return nil, fmt.Errorf("%s is not a bare git repository", src)
+	}
+
+	unlock, err := d.repos.Lock(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if exists, err := d.repos.Exists(name); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, proto.ErrRepoExist
+	}
+
+	if opts.Description == "" {
+		if desc, err := os.ReadFile(filepath.Join(src, "description")); err == nil {
+			if s := strings.TrimSpace(string(desc)); s != defaultGitDescription {
+				opts.Description = s
+			}
+		}
+	}
+
+	rp := d.repos.Path(name)
+	if err := os.MkdirAll(filepath.Dir(rp), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if move {
+		if err := os.Rename(src, rp); err != nil {
+			return nil, err
+		}
+	} else {
+		// A local mirror clone hard links the objects when it can, and copies
+		// all the references.
+		if _, err := git.NewCommand("clone", "--mirror", "--quiet", src, rp).WithContext(ctx).Run(); err != nil {
+			return nil, fmt.Errorf("git clone: %w", err)
+		}
+		if _, err := git.NewCommand("remote", "remove", "origin").WithContext(ctx).RunInDir(rp); err != nil {
+			return nil, fmt.Errorf("git remote: %w", err)
+		}
+	}
+
+	var userID int64
+	if user != nil {
+		userID = user.ID()
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := d.store.CreateRepo(
+			ctx,
+			tx,
+			name,
+			userID,
+			opts.ProjectName,
+			opts.Description,
+			opts.Private,
+			opts.Hidden,
+			opts.Mirror,
+		); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(rp, "description"), []byte(opts.Description), fs.ModePerm); err != nil {
+			return err
+		}
+
+		export := filepath.Join(rp, "git-daemon-export-ok")
+		if opts.Private {
+			if err := os.Remove(export); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		} else if err := os.WriteFile(export, []byte{}, fs.ModePerm); err != nil {
+			return err
+		}
+
+		return hooks.GenerateHooks(ctx, rp)
+	}); err != nil {
+		err = db.WrapError(err)
+		if move {
+			if rerr := os.Rename(rp, src); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+		} else if rerr := d.repos.Remove(name); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return nil, proto.ErrRepoExist
+		}
+
+		return nil, err
+	}
+
+	if err := populateLastModified(ctx, d, name); err != nil {
+		d.logger.Debug("error populating last-modified", "repo", name, "err", err)
+	}
+	if _, err := d.UpdateRepoDiskUsage(ctx, name); err != nil {
+		d.logger.Error("error updating disk usage", "repo", name, "err", err)
+	}
+
+	event := proto.AuditEvent{Action: proto.AuditRepoCreate, Repo: name, Details: src}
+	if user != nil {
+		event.Username = user.Username()
+	}
+	d.Audit(ctx, event)
+	d.PublishEvent(ctx, events.Event{Type: events.RepoCreate, Repo: name, Username: event.Username, Public: !opts.Private})
+
+	return d.Repository(ctx, name)
+}
@@ -0,0 +1,94 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestFindBareRepositories(t *testing.T) {
+	root := t.TempDir()
+	for _, p := range []string{"top.git", "team/app.git", "team/sub/lib", "team/app.git/nested.git"} {
+		if _, err := git.Init(filepath.Join(root, p), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "empty"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(repos []BareRepository) []string {
+		var ns []string
+		for _, r := range repos {
+			ns = append(ns, r.Name)
+		}
+		return ns
+	}
+
+	repos, err := FindBareRepositories(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns := names(repos); !reflect.DeepEqual(ns, []string{"top"}) {
+		t.Fatalf("unexpected repositories %v", ns)
+	}
+
+	// Repositories aren't searched for nested repositories.
+	repos, err = FindBareRepositories(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns := names(repos); !reflect.DeepEqual(ns, []string{"team/app", "team/sub/lib", "top"}) {
+		t.Fatalf("unexpected repositories %v", ns)
+	}
+}
+
+func TestAdoptRepository(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx, be := newTestBackend(t, cfg)
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(t.TempDir(), "app.git")
+	if _, err := git.Init(src, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "description"), []byte("The app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := be.AdoptRepository(ctx, "team/app", src, user, proto.RepositoryOptions{Private: true}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Description() != "The app" || !r.IsPrivate() || r.UserID() != user.ID() {
+		t.Fatalf("unexpected repository %q private=%v user=%d", r.Description(), r.IsPrivate(), r.UserID())
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("the source was removed: %v", err)
+	}
+
+	if _, err := be.AdoptRepository(ctx, "team/app", src, user, proto.RepositoryOptions{}, false); !errors.Is(err, proto.ErrRepoExist) {
+		t.Fatalf("expected ErrRepoExist, got %v", err)
+	}
+
+	// Moving the repository.
+	if _, err := be.AdoptRepository(ctx, "moved", src, user, proto.RepositoryOptions{}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the source to be moved, got %v", err)
+	}
+
+	if _, err := be.AdoptRepository(ctx, "nope", t.TempDir(), user, proto.RepositoryOptions{}, false); err == nil {
+		t.Fatal("expected an error adopting a directory that isn't a repository")
+	}
+}
@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// anonAccessSetting is the repository setting key of the anonymous access
+// level of a repository.
+const anonAccessSetting = "anon-access"
+
+// ErrInvalidRepoAnonAccess is returned when overriding the anonymous access
+// of a repository with more than read-only access.
+var ErrInvalidRepoAnonAccess = errors.New("anonymous access to a repository must be no-access or read-only")
+
+// RepoAnonAccess returns the anonymous access level of a repository, and
+// whether it overrides the anonymous access level of the server.
+func (d *Backend) RepoAnonAccess(ctx context.Context, repo string) (access.AccessLevel, bool, error) {
+	v, err := d.RepoSetting(ctx, utils.SanitizeRepo(repo), anonAccessSetting)
+	if err != nil {
+		return access.NoAccess, false, err
+	}
+
+	if level := access.ParseAccessLevel(v); level >= 0 {
+		return level, true, nil
+	}
+
+	return d.AnonAccess(ctx), false, nil
+}
+
+// SetRepoAnonAccess overrides the anonymous access level of a public
+// repository, or removes the override when level is nil. Anonymous users get
+// at most read-only access to a repository.
+func (d *Backend) SetRepoAnonAccess(ctx context.Context, repo string, level *access.AccessLevel) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	var value string
+	if level != nil {
+		if *level != access.NoAccess && *level != access.ReadOnlyAccess {
+			return ErrInvalidRepoAnonAccess
+		}
+		value = level.String()
+	}
+
+	if err := d.SetRepoSetting(ctx, repo, anonAccessSetting, value); err != nil {
+		return err
+	}
+
+	details := "default"
+	if level != nil {
+		details = level.String()
+	}
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoAnonAccess, Repo: repo, Details: details})
+
+	return nil
+}
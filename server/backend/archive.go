@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// IsArchived returns whether the repository is archived.
+func (d *Backend) IsArchived(ctx context.Context, name string) (bool, error) {
+	name = utils.SanitizeRepo(name)
+	var archived bool
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		archived, err = d.store.GetRepoIsArchivedByName(ctx, tx, name)
+		return err
+	}); err != nil {
+		return false, db.WrapError(err)
+	}
+
+	return archived, nil
+}
+
+// SetArchived archives or unarchives the repository. Archived repositories
+// are read-only: pushes, LFS uploads, and ref changes are rejected until the
+// repository is unarchived.
+func (d *Backend) SetArchived(ctx context.Context, name string, archived bool) error {
+	name = utils.SanitizeRepo(name)
+	if _, err := d.Repository(ctx, name); err != nil {
+		return err
+	}
+
+	// Delete cache
+	d.cache.Delete(name)
+
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetRepoIsArchivedByName(ctx, tx, name, archived)
+	})); err != nil {
+		return err
+	}
+
+	action := proto.AuditRepoArchive
+	if !archived {
+		action = proto.AuditRepoUnarchive
+	}
+	d.Audit(ctx, proto.AuditEvent{Action: action, Repo: name})
+
+	return nil
+}
+
+// CheckArchived returns proto.ErrArchived when the repository is archived.
+// The repository doesn't have to exist.
+func (d *Backend) CheckArchived(ctx context.Context, repo string) error {
+	archived, err := d.IsArchived(ctx, repo)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if archived {
+		return fmt.Errorf("%w: %s", proto.ErrArchived, utils.SanitizeRepo(repo))
+	}
+
+	return nil
+}
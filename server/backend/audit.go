@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/ssh"
+)
+
+const (
+	// auditedSize is the number of recent authentications remembered.
+	auditedSize = 10000
+	// auditedTTL is how long an authentication isn't recorded again.
+	auditedTTL = 10 * time.Minute
+)
+
+// Audit records a security-relevant action in the audit log. The user, the
+// admin impersonating them, and the address of the client are taken from the
+// context unless set. A failure to record the event is logged, it doesn't
+// fail the action.
+func (d *Backend) Audit(ctx context.Context, event proto.AuditEvent) {
+	if event.Username == "" {
+		if user := proto.UserFromContext(ctx); user != nil {
+			event.Username = user.Username()
+		}
+	}
+	if event.Impersonator == "" {
+		if admin := proto.ImpersonatorFromContext(ctx); admin != nil {
+			event.Impersonator = admin.Username()
+		}
+	}
+	if event.RemoteAddr == "" {
+		event.RemoteAddr = remoteAddr(ctx)
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	// The event is recorded even if the request is canceled right after the
+	// action.
+	if err := d.db.TransactionContext(d.ctx, func(tx *db.Tx) error {
+		return d.store.CreateAuditEvent(d.ctx, tx, models.AuditEvent{
+			Action:       string(event.Action),
+			Username:     event.Username,
+			Impersonator: event.Impersonator,
+			Repo:         event.Repo,
+			Target:       event.Target,
+			RemoteAddr:   event.RemoteAddr,
+			Details:      event.Details,
+			CreatedAt:    event.CreatedAt,
+		})
+	}); err != nil {
+		d.logger.Error("error recording audit event", "action", event.Action, "username", event.Username, "err", err)
+	}
+}
+
+// AuditAuthentication records a successful authentication in the audit log
+// like Audit, unless the same user authenticated the same way from the same
+// host in the last minutes. Every HTTP request authenticates, recording them
+// all would flood the audit log.
+func (d *Backend) AuditAuthentication(ctx context.Context, event proto.AuditEvent) {
+	addr := event.RemoteAddr
+	if addr == "" {
+		addr = remoteAddr(ctx)
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	key := strings.Join([]string{string(event.Action), event.Username, event.Target, event.Details, addr}, "\x00")
+	if d.audited.Contains(key) {
+		return
+	}
+	d.audited.Add(key, struct{}{})
+
+	d.Audit(ctx, event)
+}
+
+// AuditEvents returns the most recent audit events matching filter in
+// chronological order.
+func (d *Backend) AuditEvents(ctx context.Context, filter proto.AuditFilter) ([]proto.AuditEvent, error) {
+	var ms []models.AuditEvent
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetAuditEvents(ctx, tx, store.AuditEventFilter{
+			Action:   filter.Action,
+			Username: filter.Username,
+			Repo:     filter.Repo,
+			Since:    filter.Since,
+			Until:    filter.Until,
+			Limit:    filter.Limit,
+		})
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	events := make([]proto.AuditEvent, 0, len(ms))
+	for _, m := range ms {
+		events = append(events, proto.AuditEvent{
+			ID:           m.ID,
+			Action:       proto.AuditAction(m.Action),
+			Username:     m.Username,
+			Impersonator: m.Impersonator,
+			Repo:         m.Repo,
+			Target:       m.Target,
+			RemoteAddr:   m.RemoteAddr,
+			Details:      m.Details,
+			CreatedAt:    m.CreatedAt,
+		})
+	}
+
+	return events, nil
+}
+
+// remoteAddr returns the address of the client in the context, if any.
+func remoteAddr(ctx context.Context) string {
+	if addr := proto.RemoteAddrFromContext(ctx); addr != "" {
+		return addr
+	}
+	if addr, ok := ctx.Value(ssh.ContextKeyRemoteAddr).(net.Addr); ok && addr != nil {
+		return addr.String()
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// ErrAvatarTooLarge is returned when an uploaded image is larger than the
+// maximum size.
+var ErrAvatarTooLarge = errors.New("avatar is too large")
+
+// avatarTypes are the content types of the images users can upload.
+var avatarTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// avatarProviders are the base URLs of the avatar providers.
+var avatarProviders = map[string]string{
+	"gravatar":   "https://www.gravatar.com/avatar/",
+	"libravatar": "https://seccdn.libravatar.org/avatar/",
+}
+
+// Avatar is the image of a user.
+type Avatar struct {
+	// Path is the file of the uploaded image, and ContentType its type.
+	Path        string
+	ContentType string
+
+	// URL is the image of the user at the avatar provider. It's empty when
+	// there's an uploaded image, no provider, or no email.
+	URL string
+}
+
+// Uploaded returns whether the avatar is an uploaded image.
+func (a Avatar) Uploaded() bool {
+	return a.Path != ""
+}
+
+// Email returns the email address of the user, used to find their avatar at
+// the avatar provider.
+func (d *Backend) Email(ctx context.Context, user proto.User) (string, error) {
+	return d.UserSetting(ctx, user, emailSetting)
+}
+
+// SetEmail sets the email address of the user. An empty address removes it.
+func (d *Backend) SetEmail(ctx context.Context, user proto.User, email string) error {
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			return fmt.Errorf("invalid email address %q", email)
+		}
+	}
+
+	return d.SetUserSetting(ctx, user, emailSetting, email)
+}
+
+// Avatar returns the avatar of the user. An avatar with neither a path nor a
+// URL means the user is shown with their initials. The size is the width of
+// the image asked from the provider, in pixels.
+func (d *Backend) Avatar(ctx context.Context, user proto.User, size int) (Avatar, error) {
+	var a Avatar
+	ct, err := d.UserSetting(ctx, user, avatarSetting)
+	if err != nil {
+		return a, err
+	}
+
+	if ct != "" {
+		a.Path = d.avatarPath(user)
+		a.ContentType = ct
+		return a, nil
+	}
+
+	base, ok := avatarProviders[d.cfg.Current().Avatar.Provider]
+	if !ok {
+		return a, nil
+	}
+
+	email, err := d.Email(ctx, user)
+	if err != nil || email == "" {
+		return a, err
+	}
+
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	q := url.Values{}
+	q.Set("s", strconv.Itoa(size))
+	// The provider falls back to the initials of the user.
+	q.Set("d", fmt.Sprintf("%s/api/v1/users/%s/avatar?default=initials", d.cfg.HTTP.PublicURL, user.Username()))
+	a.URL = base + hex.EncodeToString(hash[:]) + "?" + q.Encode()
+
+	return a, nil
+}
+
+// SetAvatar uploads the image of the user, a PNG, JPEG, GIF, or WebP image.
+func (d *Backend) SetAvatar(ctx context.Context, user proto.User, r io.Reader) error {
+	if user == nil {
+		return proto.ErrUserNotFound
+	}
+
+	max := d.cfg.Current().Avatar.MaxSize
+	if max <= 0 {
+		return errors.New("avatar uploads are disabled")
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, max+1); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if int64(buf.Len()) > max {
+		return fmt.Errorf("%w: the maximum size is %d bytes", ErrAvatarTooLarge, max)
+	}
+
+	ct := http.DetectContentType(buf.Bytes())
+	if !avatarTypes[ct] {
+		return errors.New("avatar must be a PNG, JPEG, GIF, or WebP image")
+	}
+
+	p := d.avatarPath(user)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Write the image aside, so that it's never served half written.
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return err
+	}
+
+	return d.SetUserSetting(ctx, user, avatarSetting, ct)
+}
+
+// DeleteAvatar removes the uploaded image of the user.
+func (d *Backend) DeleteAvatar(ctx context.Context, user proto.User) error {
+	if err := d.SetUserSetting(ctx, user, avatarSetting, ""); err != nil {
+		return err
+	}
+
+	d.removeAvatar(user)
+
+	return nil
+}
+
+// removeAvatar removes the file of the uploaded image of the user, if any.
+func (d *Backend) removeAvatar(user proto.User) {
+	if err := os.Remove(d.avatarPath(user)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		d.logger.Error("failed to remove avatar", "username", user.Username(), "err", err)
+	}
+}
+
+func (d *Backend) avatarPath(user proto.User) string {
+	return filepath.Join(d.cfg.DataPath, "avatars", strconv.FormatInt(user.ID(), 10))
+}
@@ -2,12 +2,24 @@ package backend
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/auth"
 	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/cron"
 	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/kvcache"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	"github.com/charmbracelet/soft-serve/server/pool"
+	"github.com/charmbracelet/soft-serve/server/ratelimit"
+	"github.com/charmbracelet/soft-serve/server/storage"
 	"github.com/charmbracelet/soft-serve/server/store"
 	"github.com/charmbracelet/soft-serve/server/task"
+	"github.com/hashicorp/golang-lru/v2/expirable"
 )
 
 // Backend is the Soft Serve backend that handles users, repositories, and
@@ -17,22 +29,112 @@ type Backend struct {
 	cfg     *config.Config
 	db      *db.DB
 	store   store.Store
+	repos   storage.RepoStorage
 	logger  *log.Logger
 	cache   *cache
 	manager *task.Manager
+	// reloadMtx guards the state rebuilt when the configuration is reloaded,
+	// the providers, the IP access rules, and the rate limiters.
+	reloadMtx sync.RWMutex
+	// provider is the provider users are synced from, if any.
+	provider auth.Provider
+	// oidc is the OpenID Connect provider users log in with, if any.
+	oidc *auth.OIDC
+	// ipRules are the addresses allowed to connect to the server.
+	ipRules access.IPRules
+	// rateLimits are the rate limiters of each operation.
+	rateLimits [rateOperations]rateLimiters
+	// workers is the pool of workers running git operations.
+	workers *pool.Pool
+	// gitCache is the cache of packs and objects, nil when it's disabled.
+	gitCache kvcache.Cache
+	// events is the broker of repository events.
+	events *events.Broker
+	// announcements is the broker of the MOTD changes and the broadcasts
+	// to the connected sessions.
+	announcements *events.Broker
+	// jobsWake wakes up the job workers when a job is queued.
+	jobsWake chan struct{}
+	// redis is the Redis server shared with the other servers, nil when it
+	// isn't configured.
+	redis *kvcache.Redis
+	// serverID is the random ID of the server in the shared events and the
+	// owners of its locks.
+	serverID string
+	// sharedEvents are the events waiting to be shared with the other
+	// servers.
+	sharedEvents chan sharedEvent
+	// meta is the cache of the metadata of the repositories, in Redis when
+	// it's configured.
+	meta kvcache.Cache
+	// startedAt is when the backend was created, the uptime of the server.
+	startedAt time.Time
+	// sched is the scheduler running the tasks of the server, nil on
+	// replicas.
+	sched *cron.Scheduler
+	// audited are the authentications recently recorded in the audit log,
+	// they aren't recorded again for a while.
+	audited *expirable.LRU[string, struct{}]
+	// twoFactorFailures throttles the wrong two-factor codes of each user.
+	twoFactorFailures *ratelimit.Limiter
 }
 
 // New returns a new Soft Serve backend.
 func New(ctx context.Context, cfg *config.Config, db *db.DB) *Backend {
 	dbstore := store.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("backend")
+	logger := logr.WithTrackedPrefix(log.FromContext(ctx), "backend")
+	repos := storage.RepoStorageFromContext(ctx)
+	if repos == nil {
+		repos = storage.NewLocalRepoStorage(cfg)
+	}
 	b := &Backend{
 		ctx:     ctx,
 		cfg:     cfg,
 		db:      db,
 		store:   dbstore,
+		repos:   repos,
 		logger:  logger,
 		manager: task.NewManager(ctx),
+		events:  events.NewBroker(recentEvents),
+		// Announcements aren't replayed.
+		announcements:     events.NewBroker(1),
+		jobsWake:          make(chan struct{}, 1),
+		serverID:          newServerID(),
+		startedAt:         time.Now(),
+		audited:           expirable.NewLRU[string, struct{}](auditedSize, nil, auditedTTL),
+		twoFactorFailures: ratelimit.New(twoFactorFailureLimit),
+	}
+
+	if cfg.HA.Enabled {
+		b.repos = &haRepoStorage{RepoStorage: repos, d: b}
+	}
+
+	if cfg.LDAP.Enabled {
+		b.provider = auth.NewLDAP(cfg.LDAP)
+	}
+
+	if cfg.OIDC.Enabled {
+		b.oidc = auth.NewOIDC(cfg.OIDC)
+	}
+
+	rules, err := access.ParseIPRules(cfg.IPAccess.Allow, cfg.IPAccess.Deny)
+	if err != nil {
+		logger.Error("invalid ip access rules", "err", err)
+	}
+	b.ipRules = rules
+	b.rateLimits = newRateLimiters(cfg.RateLimit)
+	b.workers = pool.New(pool.Options{
+		Workers: cfg.Workers.Size,
+		Queue:   cfg.Workers.Queue,
+		PerKey:  cfg.Workers.PerRepo,
+	})
+	b.gitCache = newGitCache(cfg.Cache)
+	b.redis = newRedis(cfg.Redis)
+	if b.redis != nil {
+		b.sharedEvents = make(chan sharedEvent, sharedEventsSize)
+		b.meta = b.redis
+	} else {
+		b.meta = kvcache.NewMemory(metaCacheSize)
 	}
 
 	// TODO: implement a proper caching interface
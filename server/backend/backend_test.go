@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/migrate"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/store/database"
+	_ "modernc.org/sqlite" // sqlite driver
+)
+
+// newTestBackend returns a backend with a migrated sqlite database.
+func newTestBackend(t *testing.T, cfg *config.Config) (context.Context, *Backend) {
+	t.Helper()
+	cfg.DataPath = t.TempDir()
+	ctx := config.WithContext(context.Background(), cfg)
+
+	dbx, err := db.Open(ctx, "sqlite", filepath.Join(cfg.DataPath, "soft-serve.db")+
+		"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbx.Close() }) // nolint: errcheck
+	if err := migrate.Migrate(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+	ctx = store.WithContext(ctx, database.New(ctx, dbx))
+
+	return ctx, New(ctx, cfg, dbx)
+}
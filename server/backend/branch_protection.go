@@ -0,0 +1,321 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// ProtectBranch creates or updates a branch protection rule for the branches
+// matching pattern.
+func (d *Backend) ProtectBranch(ctx context.Context, repo string, pattern string, opts proto.BranchProtectionOptions) error {
+	repo = utils.SanitizeRepo(repo)
+	pattern = strings.TrimPrefix(pattern, git.RefsHeads)
+	if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+		return fmt.Errorf("invalid branch pattern: %q", pattern)
+	}
+
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if err := d.store.UpsertBranchProtection(ctx, tx, repo, pattern,
+				!opts.AllowForcePush, !opts.AllowDeletion, opts.RequireSigned, len(opts.PushUsers) > 0); err != nil {
+				return err
+			}
+
+			m, err := d.store.GetBranchProtectionByRepoAndPattern(ctx, tx, repo, pattern)
+			if err != nil {
+				return err
+			}
+
+			if err := d.store.RemoveBranchProtectionUsers(ctx, tx, m.ID); err != nil {
+				return err
+			}
+
+			for _, u := range opts.PushUsers {
+				if _, err := d.store.FindUserByUsername(ctx, tx, u); err != nil {
+					if errors.Is(err, db.ErrRecordNotFound) {
+						return proto.ErrUserNotFound
+					}
+					return err
+				}
+				if err := d.store.AddBranchProtectionUser(ctx, tx, m.ID, u); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}),
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{
+		Action: proto.AuditBranchProtect,
+		Repo:   repo,
+		Target: pattern,
+		Details: fmt.Sprintf("allow-force-push=%t allow-deletion=%t require-signed=%t push-users=%s",
+			opts.AllowForcePush, opts.AllowDeletion, opts.RequireSigned, strings.Join(opts.PushUsers, ",")),
+	})
+
+	return nil
+}
+
+// UnprotectBranch deletes the branch protection rule for pattern.
+func (d *Backend) UnprotectBranch(ctx context.Context, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	pattern = strings.TrimPrefix(pattern, git.RefsHeads)
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if _, err := d.store.GetBranchProtectionByRepoAndPattern(ctx, tx, repo, pattern); err != nil {
+			return err
+		}
+
+		return d.store.DeleteBranchProtectionByRepoAndPattern(ctx, tx, repo, pattern)
+	})
+	if err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrBranchProtectionNotFound
+		}
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditBranchUnprotect, Repo: repo, Target: pattern})
+
+	return nil
+}
+
+// BranchProtections returns the branch protection rules of a repository.
+func (d *Backend) BranchProtections(ctx context.Context, repo string) ([]proto.BranchProtection, error) {
+	repo = utils.SanitizeRepo(repo)
+	var rules []proto.BranchProtection
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.GetBranchProtectionsByRepo(ctx, tx, repo)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range ms {
+			var users []models.User
+			if m.RestrictPush {
+				users, err = d.store.ListBranchProtectionUsers(ctx, tx, m.ID)
+				if err != nil {
+					return err
+				}
+			}
+
+			rule := proto.BranchProtection{
+				ID:            m.ID,
+				Pattern:       m.Pattern,
+				NoForcePush:   m.NoForcePush,
+				NoDeletion:    m.NoDeletion,
+				RequireSigned: m.RequireSigned,
+				RestrictPush:  m.RestrictPush,
+			}
+			for _, u := range users {
+				rule.PushUsers = append(rule.PushUsers, u.Username)
+			}
+
+			rules = append(rules, rule)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return rules, nil
+}
+
+// CheckBranchProtection checks whether the reference update described by arg
+// is allowed by the branch protection rules of the repository. It returns an
+// error wrapping proto.ErrBranchProtected if the update must be rejected.
+func (d *Backend) CheckBranchProtection(ctx context.Context, repo string, user proto.User, arg hooks.HookArg) error {
+	if !strings.HasPrefix(arg.RefName, git.RefsHeads) {
+		return nil
+	}
+
+	rules, err := d.BranchProtections(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	branch := strings.TrimPrefix(arg.RefName, git.RefsHeads)
+	var matched []proto.BranchProtection
+	for _, rule := range rules {
+		if rule.Matches(branch) {
+			matched = append(matched, rule)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil
+	}
+
+	isCreate := arg.OldSha == git.ZeroHash.String()
+	isDelete := arg.NewSha == git.ZeroHash.String()
+
+	var r *git.Repository
+	openRepo := func() (*git.Repository, error) {
+		if r != nil {
+			return r, nil
+		}
+		rr, err := d.Repository(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		r, err = rr.Open()
+		return r, err
+	}
+
+	for _, rule := range matched {
+		if rule.RestrictPush && !d.canPushRestricted(ctx, repo, user, rule) {
+			return fmt.Errorf("%w: you are not allowed to push to %q", proto.ErrBranchProtected, branch)
+		}
+
+		if isDelete {
+			if rule.NoDeletion {
+				return fmt.Errorf("%w: cannot delete %q", proto.ErrBranchProtected, branch)
+			}
+			continue
+		}
+
+		if rule.NoForcePush && !isCreate {
+			r, err := openRepo()
+			if err != nil {
+				return err
+			}
+			if !r.IsAncestor(arg.OldSha, arg.NewSha) {
+				return fmt.Errorf("%w: cannot force push to %q", proto.ErrBranchProtected, branch)
+			}
+		}
+
+		if rule.RequireSigned {
+			r, err := openRepo()
+			if err != nil {
+				return err
+			}
+			signers, err := d.writeAllowedSigners(ctx)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(signers) // nolint: errcheck
+			revs := []string{arg.OldSha + ".." + arg.NewSha}
+			if isCreate {
+				revs = []string{arg.NewSha, "--not", "--all"}
+			}
+			unverified, err := r.UnverifiedCommits(signers, revs...)
+			if err != nil {
+				return err
+			}
+			if len(unverified) > 0 {
+				return fmt.Errorf("%w: commit %s doesn't have a verified signature", proto.ErrBranchProtected, unverified[0])
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeAllowedSigners writes the public keys of the users to a temporary
+// allowed signers file, and returns its path. Commits signed with the SSH
+// key of a user are verified against it. Suspended users and expired keys
+// are left out. The caller removes the file.
+func (d *Backend) writeAllowedSigners(ctx context.Context) (string, error) {
+	var buf bytes.Buffer
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		users, err := d.store.GetAllUsers(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range users {
+			if u.Suspended {
+				continue
+			}
+
+			keys, err := d.store.ListPublicKeyDetailsByUsername(ctx, tx, u.Username)
+			if err != nil {
+				return err
+			}
+
+			for _, k := range keys {
+				if k.ExpiresAt.Valid && !k.ExpiresAt.Time.After(time.Now()) {
+					continue
+				}
+				fmt.Fprintf(&buf, "%s %s\n", u.Username, k.PublicKey) // nolint: errcheck
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return "", db.WrapError(err)
+	}
+
+	f, err := os.CreateTemp("", "soft-serve-allowed-signers-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() // nolint: errcheck
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		os.Remove(f.Name()) // nolint: errcheck
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// canPushRestricted returns true if the user is allowed to push to a branch
+// with restricted pushers. Admins are always allowed to push.
+func (d *Backend) canPushRestricted(ctx context.Context, repo string, user proto.User, rule proto.BranchProtection) bool {
+	if user == nil {
+		return false
+	}
+
+	if d.isPushAdmin(ctx, repo, user) {
+		return true
+	}
+
+	for _, u := range rule.PushUsers {
+		if u == user.Username() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPushAdmin returns true if the user pushes to the repository with admin
+// access. Git hooks get the access level the user authenticated with from
+// the context, since the user they load has no token scope or key
+// restriction. That level can only lower the access of the user, never
+// raise it.
+func (d *Backend) isPushAdmin(ctx context.Context, repo string, user proto.User) bool {
+	if user == nil {
+		return false
+	}
+
+	level := d.AccessLevelForUser(ctx, repo, user)
+	if pushed := access.FromContext(ctx); pushed >= 0 && pushed < level {
+		level = pushed
+	}
+
+	return level >= access.AdminAccess
+}
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestPushAdminAccessLevel(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	admin, err := be.User(ctx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateUser(ctx, "user1", proto.UserOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	collab, err := be.CreateUser(ctx, "user2", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepository(ctx, "repo1", admin, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.ProtectBranch(ctx, "repo1", "main", proto.BranchProtectionOptions{PushUsers: []string{"user1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.GrantRefPermission(ctx, "repo1", "release", "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.AddCollaborator(ctx, "repo1", "user2", access.ReadWriteAccess); err != nil {
+		t.Fatal(err)
+	}
+
+	create := hooks.HookArg{
+		OldSha:  git.ZeroHash.String(),
+		NewSha:  "0123456789012345678901234567890123456789",
+		RefName: "refs/heads/main",
+	}
+
+	// Git hooks load the user without the scope of the token they pushed
+	// with, the access level of the push comes from the context instead. It
+	// never raises the access of the user.
+	for _, tc := range []struct {
+		user  proto.User
+		level access.AccessLevel
+		ok    bool
+	}{
+		{admin, -1, true},
+		{admin, access.AdminAccess, true},
+		{admin, access.ReadWriteAccess, false},
+		{collab, -1, false},
+		{collab, access.AdminAccess, false},
+	} {
+		ctx := ctx
+		if tc.level >= 0 {
+			ctx = access.WithContext(ctx, tc.level)
+		}
+
+		err := be.CheckBranchProtection(ctx, "repo1", tc.user, create)
+		if tc.ok != (err == nil) || (err != nil && !errors.Is(err, proto.ErrBranchProtected)) {
+			t.Errorf("%s %s: unexpected branch protection error %v", tc.user.Username(), tc.level, err)
+		}
+
+		err = be.CheckRefPermission(ctx, "repo1", tc.user, "refs/heads/release")
+		if tc.ok != (err == nil) || (err != nil && !errors.Is(err, proto.ErrRefRestricted)) {
+			t.Errorf("%s %s: unexpected reference permission error %v", tc.user.Username(), tc.level, err)
+		}
+	}
+}
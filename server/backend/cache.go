@@ -1,11 +1,18 @@
 package backend
 
-import lru "github.com/hashicorp/golang-lru/v2"
+import (
+	"strings"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
 
 // TODO: implement a caching interface.
 type cache struct {
 	b     *Backend
 	repos *lru.Cache[string, *repo]
+	// objects are git objects read from the repositories, like pages of
+	// commits and trees. They're keyed by the commit they were read from.
+	objects *lru.Cache[string, any]
 }
 
 func newCache(b *Backend, size int) *cache {
@@ -15,6 +22,8 @@ func newCache(b *Backend, size int) *cache {
 	c := &cache{b: b}
 	cache, _ := lru.New[string, *repo](size)
 	c.repos = cache
+	objects, _ := lru.New[string, any](size)
+	c.objects = objects
 	return c
 }
 
@@ -33,3 +42,12 @@ func (c *cache) Delete(repo string) {
 func (c *cache) Len() int {
 	return c.repos.Len()
 }
+
+// DeleteObjects deletes the objects with keys starting with prefix.
+func (c *cache) DeleteObjects(prefix string) {
+	for _, k := range c.objects.Keys() {
+		if strings.HasPrefix(k, prefix) {
+			c.objects.Remove(k)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"golang.org/x/crypto/ssh"
+)
+
+// certificateUsername validates a user certificate against the trusted
+// certificate authorities and returns the username it maps to. That's the
+// first principal of the certificate that is a valid username.
+func (d *Backend) certificateUsername(cert *ssh.Certificate) (string, error) {
+	if cert.CertType != ssh.UserCert {
+		return "", proto.ErrUntrustedCertificate
+	}
+
+	trusted := false
+	for _, ca := range d.cfg.TrustedUserCAKeys() {
+		if sshutils.KeysEqual(cert.SignatureKey, ca) {
+			trusted = true
+			break
+		}
+	}
+
+	if !trusted {
+		return "", proto.ErrUntrustedCertificate
+	}
+
+	for _, p := range cert.ValidPrincipals {
+		username := strings.ToLower(p)
+		if utils.ValidateUsername(username) != nil {
+			continue
+		}
+
+		// CheckCert verifies the validity period and the signature.
+		checker := &ssh.CertChecker{
+			// The source address is checked by the SSH server.
+			SupportedCriticalOptions: []string{"source-address"},
+		}
+		if err := checker.CheckCert(p, cert); err != nil {
+			d.logger.Debug("invalid certificate", "principal", p, "err", err)
+			return "", proto.ErrUntrustedCertificate
+		}
+
+		return username, nil
+	}
+
+	// Certificates without principals are valid for any user, don't guess
+	// which one.
+	return "", proto.ErrUntrustedCertificate
+}
+
+// UserByCertificate returns the user a trusted user certificate maps to.
+func (d *Backend) UserByCertificate(ctx context.Context, cert *ssh.Certificate) (proto.User, error) {
+	username, err := d.certificateUsername(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.User(ctx, username)
+}
+
+// ProvisionUserByCertificate returns the user a trusted user certificate maps
+// to, and creates the user if it doesn't exist yet.
+func (d *Backend) ProvisionUserByCertificate(ctx context.Context, cert *ssh.Certificate) (proto.User, error) {
+	username, err := d.certificateUsername(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := d.User(ctx, username)
+	if !errors.Is(err, proto.ErrUserNotFound) {
+		return user, err
+	}
+
+	user, err = d.CreateUser(ctx, username, proto.UserOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("provisioned user from certificate", "username", username, "ca", ssh.FingerprintSHA256(cert.SignatureKey))
+	return user, nil
+}
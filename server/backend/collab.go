@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/utils"
 )
 
@@ -20,11 +21,17 @@ func (d *Backend) AddCollaborator(ctx context.Context, repo string, username str
 	}
 
 	repo = utils.SanitizeRepo(repo)
-	return db.WrapError(
+	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 			return d.store.AddCollabByUsernameAndRepo(ctx, tx, username, repo, level)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditCollabAdd, Repo: repo, Target: username, Details: level.String()})
+
+	return nil
 }
 
 // Collaborators returns a list of collaborators for a repository.
@@ -75,9 +82,15 @@ func (d *Backend) IsCollaborator(ctx context.Context, repo string, username stri
 // It implements backend.Backend.
 func (d *Backend) RemoveCollaborator(ctx context.Context, repo string, username string) error {
 	repo = utils.SanitizeRepo(repo)
-	return db.WrapError(
+	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 			return d.store.RemoveCollabByUsernameAndRepo(ctx, tx, username, repo)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditCollabRemove, Repo: repo, Target: username})
+
+	return nil
 }
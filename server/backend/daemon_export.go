@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// daemonExportSetting is the repository setting key of whether the Git daemon
+// exports a repository.
+const daemonExportSetting = "daemon-export"
+
+// DaemonExport returns whether the Git daemon exports a repository, and
+// whether it overrides the git.daemon_export configuration. Anonymous users
+// still need access to the repository to fetch it from the daemon.
+func (d *Backend) DaemonExport(ctx context.Context, repo string) (bool, bool, error) {
+	v, err := d.RepoSetting(ctx, utils.SanitizeRepo(repo), daemonExportSetting)
+	if err != nil {
+		return false, false, err
+	}
+
+	if export, err := strconv.ParseBool(v); err == nil {
+		return export, true, nil
+	}
+
+	return d.cfg.Git.DaemonExport, false, nil
+}
+
+// SetDaemonExport sets whether the Git daemon exports a repository, or
+// removes the override of the git.daemon_export configuration when export is
+// nil.
+func (d *Backend) SetDaemonExport(ctx context.Context, repo string, export *bool) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	value, details := "", "default"
+	if export != nil {
+		value = strconv.FormatBool(*export)
+		details = value
+	}
+
+	if err := d.SetRepoSetting(ctx, repo, daemonExportSetting, value); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoDaemonExport, Repo: repo, Details: details})
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	gitm "github.com/gogs/git-module"
+)
+
+// branchRedirectsSetting is the repository setting key of the renamed default
+// branches of a repository, one "old:new" pair per line.
+const branchRedirectsSetting = "branch-redirects"
+
+// SetDefaultBranch sets the default branch of a repository. If the branch
+// doesn't exist, it's created from the current default branch. If redirect
+// is true, the current default branch is recorded as renamed to branch, and
+// clients fetching it get a hint about the new name.
+func (d *Backend) SetDefaultBranch(ctx context.Context, repo string, user proto.User, branch string, redirect bool) error {
+	rn := utils.SanitizeRepo(repo)
+	if err := d.CheckMaintenance(ctx, rn); err != nil {
+		return err
+	}
+	if err := d.CheckArchived(ctx, rn); err != nil {
+		return err
+	}
+
+	rr, err := d.Repository(ctx, rn)
+	if err != nil {
+		return err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return err
+	}
+
+	head, err := r.HEAD()
+	if err != nil {
+		return err
+	}
+
+	old := head.Name().Short()
+	if old == branch {
+		return nil
+	}
+
+	ref := gitb.RefsHeads + branch
+	if strings.HasPrefix(branch, "-") {
+		return fmt.Errorf("invalid branch name %q", branch)
+	}
+	if _, err := gitb.NewCommand("check-ref-format", ref).WithContext(ctx).Run(); err != nil {
+		return fmt.Errorf("invalid branch name %q", branch)
+	}
+
+	branches, _ := r.Branches()
+	var exists bool
+	for _, b := range branches {
+		if branch == b {
+			exists = true
+			break
+		}
+	}
+
+	if !exists {
+		if err := d.CheckRefPermission(ctx, rn, user, ref); err != nil {
+			return err
+		}
+
+		oid := head.TargetHash().String()
+		// The empty old value makes git refuse to overwrite a concurrent branch.
+		if _, err := gitb.NewCommand("update-ref", ref, oid, "").WithContext(ctx).RunInDir(r.Path); err != nil {
+			return fmt.Errorf("%w: %s", proto.ErrBranchExist, branch)
+		}
+
+		e := refEvent(events.BranchCreate, rn, user, ref)
+		e.After = oid
+		d.PublishEvent(ctx, e)
+	}
+
+	if _, err := r.SymbolicRef(gitb.HEAD, ref, gitm.SymbolicRefOptions{
+		CommandOptions: gitm.CommandOptions{
+			Context: ctx,
+		},
+	}); err != nil {
+		return err
+	}
+
+	d.InvalidateCache(ctx, rn)
+
+	redirects, err := d.BranchRedirects(ctx, rn)
+	if err != nil {
+		return err
+	}
+
+	// The new default branch isn't renamed anymore, and the branches renamed
+	// to the old default branch are now renamed to the new one.
+	delete(redirects, branch)
+	if redirect {
+		for from, to := range redirects {
+			if to == old {
+				redirects[from] = branch
+			}
+		}
+		redirects[old] = branch
+	}
+
+	if err := d.setBranchRedirects(ctx, rn, redirects); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoDefaultBranch, Repo: rn, Details: old + " -> " + branch})
+
+	return nil
+}
+
+// BranchRedirects returns the renamed branches of a repository, mapped to
+// their new names.
+func (d *Backend) BranchRedirects(ctx context.Context, repo string) (map[string]string, error) {
+	v, err := d.RepoSetting(ctx, utils.SanitizeRepo(repo), branchRedirectsSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	redirects := map[string]string{}
+	for _, line := range strings.Split(v, "\n") {
+		if from, to, ok := strings.Cut(line, ":"); ok {
+			redirects[from] = to
+		}
+	}
+
+	return redirects, nil
+}
+
+func (d *Backend) setBranchRedirects(ctx context.Context, repo string, redirects map[string]string) error {
+	lines := make([]string, 0, len(redirects))
+	for from, to := range redirects {
+		lines = append(lines, from+":"+to)
+	}
+	sort.Strings(lines)
+
+	return d.SetRepoSetting(ctx, repo, branchRedirectsSetting, strings.Join(lines, "\n"))
+}
+
+// BranchRedirectHints returns hints for a client fetching the renamed
+// branches of a repository. Before the request is read, req is nil, and only
+// the renamed branches that don't exist anymore get hints since fetching them
+// fails. Afterwards, the renamed branches that still exist get hints if the
+// client fetched them into an existing clone.
+func (d *Backend) BranchRedirectHints(ctx context.Context, repo string, req *git.Request) []string {
+	rn := utils.SanitizeRepo(repo)
+	redirects, err := d.BranchRedirects(ctx, rn)
+	if err != nil || len(redirects) == 0 {
+		return nil
+	}
+
+	rr, err := d.Repository(ctx, rn)
+	if err != nil {
+		return nil
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return nil
+	}
+
+	existing := map[string]bool{}
+	branches, _ := r.Branches()
+	for _, b := range branches {
+		existing[b] = true
+	}
+
+	froms := make([]string, 0, len(redirects))
+	for from := range redirects {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	var hints []string
+	for _, from := range froms {
+		if req == nil {
+			if existing[from] {
+				continue
+			}
+		} else if !existing[from] || req.IsClone() || !fetchesRef(*req, gitb.RefsHeads+from) {
+			continue
+		}
+
+		to := redirects[from]
+		hints = append(hints,
+			fmt.Sprintf("branch %s of %s was renamed to %s, update your clone with:", from, rn, to),
+			fmt.Sprintf("  git branch -m %s %s", from, to),
+			"  git fetch origin",
+			fmt.Sprintf("  git branch -u origin/%s %s", to, to),
+			"  git remote set-head origin -a",
+		)
+	}
+
+	return hints
+}
+
+// fetchesRef returns true if a protocol v2 request lists a reference.
+func fetchesRef(req git.Request, ref string) bool {
+	for _, p := range req.RefPrefixes {
+		if strings.HasPrefix(ref, p) {
+			return true
+		}
+	}
+	return false
+}
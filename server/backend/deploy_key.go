@@ -0,0 +1,149 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"golang.org/x/crypto/ssh"
+)
+
+// DeployKeys returns the deploy keys of a repository.
+func (d *Backend) DeployKeys(ctx context.Context, repo string) ([]proto.DeployKey, error) {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	var ms []models.DeployKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.ListDeployKeysByRepo(ctx, tx, repo)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	keys := make([]proto.DeployKey, 0, len(ms))
+	for _, m := range ms {
+		pk, _, err := sshutils.ParseAuthorizedKey(m.PublicKey)
+		if err != nil {
+			d.logger.Error("error parsing deploy key", "id", m.ID, "err", err)
+			continue
+		}
+
+		keys = append(keys, proto.DeployKey{
+			ID:          m.ID,
+			Title:       m.Title,
+			PublicKey:   pk,
+			AccessLevel: m.AccessLevel,
+			CreatedAt:   m.CreatedAt,
+		})
+	}
+
+	return keys, nil
+}
+
+// AddDeployKey adds a deploy key to a repository. Deploy keys can only have
+// read-only or read-write access.
+func (d *Backend) AddDeployKey(ctx context.Context, repo string, title string, pk ssh.PublicKey, level access.AccessLevel) error {
+	repo = utils.SanitizeRepo(repo)
+	if level != access.ReadOnlyAccess && level != access.ReadWriteAccess {
+		return fmt.Errorf("%w: deploy keys can only be %s or %s", access.ErrInvalidAccessLevel, access.ReadOnlyAccess, access.ReadWriteAccess)
+	}
+
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	// A key that belongs to a user authenticates as that user, the deploy key
+	// would never be used.
+	if user, _ := d.UserByPublicKey(ctx, pk); user != nil {
+		return proto.ErrPublicKeyInUse
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CreateDeployKey(ctx, tx, repo, title, pk, level)
+		}),
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{
+		Action:  proto.AuditDeployKeyAdd,
+		Repo:    repo,
+		Target:  ssh.FingerprintSHA256(pk),
+		Details: level.String(),
+	})
+
+	return nil
+}
+
+// RemoveDeployKey removes a deploy key from a repository.
+func (d *Backend) RemoveDeployKey(ctx context.Context, repo string, id int64) error {
+	repo = utils.SanitizeRepo(repo)
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		keys, err := d.store.ListDeployKeysByRepo(ctx, tx, repo)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if k.ID == id {
+				return d.store.DeleteDeployKeyForRepo(ctx, tx, repo, id)
+			}
+		}
+
+		return proto.ErrDeployKeyNotFound
+	})
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditDeployKeyRemove, Repo: repo, Target: strconv.FormatInt(id, 10)})
+
+	return nil
+}
+
+// IsDeployKey returns whether the public key is a deploy key of any
+// repository.
+func (d *Backend) IsDeployKey(ctx context.Context, pk ssh.PublicKey) bool {
+	var ms []models.DeployKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.ListDeployKeysByPublicKey(ctx, tx, pk)
+		return err
+	}); err != nil {
+		d.logger.Error("error finding deploy keys", "pk", sshutils.MarshalAuthorizedKey(pk), "err", err)
+		return false
+	}
+
+	return len(ms) > 0
+}
+
+// deployKeyAccessLevel returns the access level a deploy key grants to a
+// repository. It returns false if the key isn't a deploy key of the
+// repository.
+func (d *Backend) deployKeyAccessLevel(ctx context.Context, repo string, pk ssh.PublicKey) (access.AccessLevel, bool) {
+	var m models.DeployKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetDeployKeyByRepoAndPublicKey(ctx, tx, repo, pk)
+		return err
+	}); err != nil {
+		if err = db.WrapError(err); !errors.Is(err, db.ErrRecordNotFound) {
+			d.logger.Error("error finding deploy key", "repo", repo, "err", err)
+		}
+		return access.NoAccess, false
+	}
+
+	return m.AccessLevel, true
+}
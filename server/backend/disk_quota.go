@@ -0,0 +1,323 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/dustin/go-humanize"
+)
+
+// RepoDiskUsage returns the disk usage of a repository, as of the last time
+// it was computed.
+func (d *Backend) RepoDiskUsage(ctx context.Context, repo string) (proto.DiskUsage, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return proto.DiskUsage{}, err
+	}
+
+	var m models.RepoDiskUsage
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetRepoDiskUsage(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.DiskUsage{}, nil
+		}
+		return proto.DiskUsage{}, err
+	}
+
+	return diskUsage(m), nil
+}
+
+// RepoDiskUsages returns the disk usage of the repositories by their ID, as of
+// the last time it was computed.
+func (d *Backend) RepoDiskUsages(ctx context.Context) (map[int64]proto.DiskUsage, error) {
+	var ms []models.RepoDiskUsage
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetRepoDiskUsages(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	usages := make(map[int64]proto.DiskUsage, len(ms))
+	for _, m := range ms {
+		usages[m.RepoID] = diskUsage(m)
+	}
+
+	return usages, nil
+}
+
+// UpdateRepoDiskUsage computes the disk usage of a repository, the size of
+// its git repository and of its Git LFS objects, and records it.
+func (d *Backend) UpdateRepoDiskUsage(ctx context.Context, repo string) (proto.DiskUsage, error) {
+	var usage proto.DiskUsage
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return usage, err
+	}
+
+	rp := d.repos.Path(r.Name())
+	usage.Objects, err = dirSize(rp)
+	if err != nil {
+		return usage, err
+	}
+
+	usage.ObjectCount, usage.PackSize, err = countObjects(ctx, rp)
+	if err != nil {
+		return usage, err
+	}
+
+	var objs []models.LFSObject
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		objs, err = d.store.GetLFSObjects(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		return usage, db.WrapError(err)
+	}
+	for _, obj := range objs {
+		usage.LFS += obj.Size
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetRepoDiskUsage(ctx, tx, models.RepoDiskUsage{
+			RepoID:      r.ID(),
+			ObjectsSize: usage.Objects,
+			LFSSize:     usage.LFS,
+			ObjectCount: usage.ObjectCount,
+			PackSize:    usage.PackSize,
+		})
+	}); err != nil {
+		return usage, db.WrapError(err)
+	}
+
+	usage.UpdatedAt = time.Now()
+	return usage, nil
+}
+
+// UserDiskUsage returns the total disk usage of the repositories of a user.
+func (d *Backend) UserDiskUsage(ctx context.Context, user proto.User) (int64, error) {
+	var size int64
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		size, err = d.store.GetUserDiskUsage(ctx, tx, user.ID())
+		return err
+	})
+	return size, db.WrapError(err)
+}
+
+// RepoDiskQuota returns the disk quota of a repository in bytes, 0 means no
+// limit. The repository override takes precedence over the server
+// configuration.
+func (d *Backend) RepoDiskQuota(ctx context.Context, repo string) (int64, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	var m models.DiskQuota
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetRepoDiskQuota(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return d.cfg.Quota.Repo, nil
+		}
+		return 0, err
+	}
+
+	return m.Size, nil
+}
+
+// SetRepoDiskQuota overrides the disk quota of a repository, 0 means no
+// limit.
+func (d *Backend) SetRepoDiskQuota(ctx context.Context, repo string, size int64) error {
+	r, err := d.Repository(ctx, utils.SanitizeRepo(repo))
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetRepoDiskQuota(ctx, tx, r.ID(), size)
+	}))
+}
+
+// ResetRepoDiskQuota removes the disk quota override of a repository.
+func (d *Backend) ResetRepoDiskQuota(ctx context.Context, repo string) error {
+	r, err := d.Repository(ctx, utils.SanitizeRepo(repo))
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.DeleteRepoDiskQuota(ctx, tx, r.ID())
+	}))
+}
+
+// UserDiskQuota returns the disk quota of the repositories of a user in
+// bytes, 0 means no limit. The user override takes precedence over the server
+// configuration.
+func (d *Backend) UserDiskQuota(ctx context.Context, user proto.User) (int64, error) {
+	var m models.DiskQuota
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetUserDiskQuota(ctx, tx, user.ID())
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return d.cfg.Quota.User, nil
+		}
+		return 0, err
+	}
+
+	return m.Size, nil
+}
+
+// SetUserDiskQuota overrides the disk quota of a user, 0 means no limit.
+func (d *Backend) SetUserDiskQuota(ctx context.Context, user proto.User, size int64) error {
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetUserDiskQuota(ctx, tx, user.ID(), size)
+	}))
+}
+
+// ResetUserDiskQuota removes the disk quota override of a user.
+func (d *Backend) ResetUserDiskQuota(ctx context.Context, user proto.User) error {
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.DeleteUserDiskQuota(ctx, tx, user.ID())
+	}))
+}
+
+// CheckDiskQuota checks whether size more bytes fit in the disk quota of a
+// repository, and in the disk quota of its owner. It returns an error
+// wrapping proto.ErrQuotaExceeded if they don't. The repository doesn't have
+// to exist.
+func (d *Backend) CheckDiskQuota(ctx context.Context, repo string, size int64) error {
+	if size <= 0 {
+		// Deleting references and rewriting history don't need any space,
+		// they have to work over the quota.
+		return nil
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	r, err := d.Repository(ctx, repo)
+	if errors.Is(err, proto.ErrRepoNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	quota, err := d.RepoDiskQuota(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if quota > 0 {
+		usage, err := d.RepoDiskUsage(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if usage.UpdatedAt.IsZero() {
+			// The usage was never computed.
+			usage, err = d.UpdateRepoDiskUsage(ctx, repo)
+			if err != nil {
+				return err
+			}
+		}
+		if usage.Total()+size > quota {
+			return fmt.Errorf("%w: repository %s uses %s of its %s quota, %s more don't fit",
+				proto.ErrQuotaExceeded, repo, humanize.IBytes(uint64(usage.Total())), humanize.IBytes(uint64(quota)), humanize.IBytes(uint64(size)))
+		}
+	}
+
+	if r.UserID() == 0 {
+		return nil
+	}
+	owner, err := d.UserByID(ctx, r.UserID())
+	if err != nil {
+		if errors.Is(err, proto.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	quota, err = d.UserDiskQuota(ctx, owner)
+	if err != nil || quota <= 0 {
+		return err
+	}
+	usage, err := d.UserDiskUsage(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if usage+size > quota {
+		return fmt.Errorf("%w: the repositories of %s use %s of their %s quota, %s more don't fit",
+			proto.ErrQuotaExceeded, owner.Username(), humanize.IBytes(uint64(usage)), humanize.IBytes(uint64(quota)), humanize.IBytes(uint64(size)))
+	}
+
+	return nil
+}
+
+// CheckPushQuota checks whether the objects of a push fit in the disk quotas
+// of the repository, see CheckDiskQuota. It's called by the pre-receive hook,
+// git keeps the pushed objects in a quarantine directory until the hook
+// accepts them.
+func (d *Backend) CheckPushQuota(ctx context.Context, repo string) error {
+	qp := os.Getenv("GIT_QUARANTINE_PATH")
+	if qp == "" {
+		return nil
+	}
+
+	size, err := dirSize(qp)
+	if err != nil {
+		return err
+	}
+
+	return d.CheckDiskQuota(ctx, repo, size)
+}
+
+// diskUsage returns the disk usage of a repository from its model.
+func diskUsage(m models.RepoDiskUsage) proto.DiskUsage {
+	return proto.DiskUsage{
+		Objects:     m.ObjectsSize,
+		LFS:         m.LFSSize,
+		ObjectCount: m.ObjectCount,
+		PackSize:    m.PackSize,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// dirSize returns the total size of the files in a directory.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !de.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+
+	return size, err
+}
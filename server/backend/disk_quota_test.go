@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestCheckDiskQuota(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx, be := newTestBackend(t, cfg)
+
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepository(ctx, "repo1", user, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := be.UpdateRepoDiskUsage(ctx, "repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Objects == 0 || usage.LFS != 0 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+
+	// No quota by default.
+	if err := be.CheckDiskQuota(ctx, "repo1", 1<<30); err != nil {
+		t.Fatal(err)
+	}
+
+	// The repository quota.
+	if err := be.SetRepoDiskQuota(ctx, "repo1", usage.Total()+10); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.CheckDiskQuota(ctx, "repo1", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.CheckDiskQuota(ctx, "repo1", 11); !errors.Is(err, proto.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	// Pushes without new objects are always allowed.
+	if err := be.CheckDiskQuota(ctx, "repo1", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The quota of the owner, inherited from the configuration.
+	if err := be.ResetRepoDiskQuota(ctx, "repo1"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Quota.User = usage.Total() + 5
+	if err := be.CheckDiskQuota(ctx, "repo1", 6); !errors.Is(err, proto.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if err := be.SetUserDiskQuota(ctx, user, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.CheckDiskQuota(ctx, "repo1", 6); err != nil {
+		t.Fatal(err)
+	}
+}
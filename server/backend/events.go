@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"strings"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// recentEvents is the number of events kept for subscribers catching up.
+const recentEvents = 256
+
+// PublishEvent publishes a repository event. The user defaults to the user
+// in the context.
+func (d *Backend) PublishEvent(ctx context.Context, e events.Event) {
+	if e.Username == "" {
+		if user := proto.UserFromContext(ctx); user != nil {
+			e.Username = user.Username()
+		}
+	}
+
+	d.events.Publish(e)
+	d.shareEvent(e, false)
+}
+
+// SubscribeEvents subscribes to the repository events published after the
+// event with ID lastID, zero to only get new events. The subscription must be
+// closed when done.
+func (d *Backend) SubscribeEvents(lastID int64) *events.Subscription {
+	return d.events.Subscribe(lastID)
+}
+
+// RecentEvents returns the most recent repository events, oldest first.
+func (d *Backend) RecentEvents() []events.Event {
+	return d.events.Recent()
+}
+
+// NotifyPush publishes the events of the reference updates of a push, with
+// its push options, and applies the push options. Updates rejected by the
+// server or by hooks aren't published.
+func (d *Backend) NotifyPush(ctx context.Context, repo string, req git.Request) {
+	updates := req.Updates
+	if len(updates) == 0 {
+		return
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		d.logger.Error("error opening repository", "repo", repo, "err", err)
+		return
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		d.logger.Error("error opening repository", "repo", repo, "err", err)
+		return
+	}
+
+	// An empty repository doesn't have any references.
+	refs := make(map[string]string)
+	if rs, err := rr.References(); err == nil {
+		for _, ref := range rs {
+			refs[ref.Refspec] = ref.Hash.String()
+		}
+	}
+
+	// The first push of a repository creates all of its references.
+	var created int
+	for _, u := range updates {
+		hash, ok := refs[u.RefName]
+		if u.IsDelete() && ok || !u.IsDelete() && hash != u.NewSha {
+			continue
+		}
+		if u.IsCreate() {
+			created++
+		}
+
+		e := events.Event{
+			Type:        events.Push,
+			Repo:        repo,
+			Ref:         u.RefName,
+			Before:      u.OldSha,
+			After:       u.NewSha,
+			PushOptions: req.PushOptions,
+		}
+		isTag := strings.HasPrefix(u.RefName, gitb.RefsTags)
+		switch {
+		case u.IsCreate() && isTag:
+			e.Type = events.TagCreate
+		case u.IsDelete() && isTag:
+			e.Type = events.TagDelete
+		case u.IsCreate():
+			e.Type = events.BranchCreate
+		case u.IsDelete():
+			e.Type = events.BranchDelete
+		}
+
+		d.PublishEvent(ctx, e)
+	}
+
+	d.applyPushOptions(ctx, repo, req, created > 0 && created == len(refs))
+}
@@ -0,0 +1,218 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/kvcache"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// newGitCache returns the cache of packs and objects, nil when it's disabled.
+func newGitCache(cfg config.CacheConfig) kvcache.Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Redis.Addr != "" {
+		return kvcache.NewRedis(kvcache.RedisOptions{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+			TTL:      time.Duration(cfg.Redis.TTL) * time.Second,
+			Prefix:   "soft-serve:",
+		})
+	}
+	return kvcache.NewMemory(cfg.Size)
+}
+
+// cacheKey returns the key of a cached value of repo. Keys of the same
+// repository share the same prefix, and values are keyed by the commit or
+// the state of the references they were read from, so they never go stale.
+func cacheKey(repo, kind string, parts ...string) string {
+	return repo + ":" + kind + ":" + strings.Join(parts, ":")
+}
+
+// CachedPack returns the cached upload-pack response to the request req of
+// the given protocol version. It returns the key to cache the response under
+// on a miss, or an empty key when the response can't be cached.
+func (d *Backend) CachedPack(ctx context.Context, repo string, version string, req []byte) (key string, pack []byte, ok bool) {
+	if d.gitCache == nil {
+		return "", nil, false
+	}
+
+	rr, err := d.Repository(ctx, repo)
+	if err != nil {
+		return "", nil, false
+	}
+	r, err := rr.Open()
+	if err != nil {
+		return "", nil, false
+	}
+
+	// The response depends on the references the request is made against.
+	h := sha256.New()
+	head, _ := r.SymbolicRef(git.HEAD, "")
+	fmt.Fprintf(h, "%s\n", head)
+	refs, err := r.References()
+	if err != nil {
+		// Empty repositories don't have any references.
+		return "", nil, false
+	}
+	for _, ref := range refs {
+		fmt.Fprintf(h, "%s %s\n", ref.Hash, ref.Name())
+	}
+	fmt.Fprintf(h, "%s\n", version)
+	h.Write(req) // nolint: errcheck
+
+	key = cacheKey(rr.Name(), "pack", hex.EncodeToString(h.Sum(nil)))
+	pack, err = d.gitCache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, kvcache.ErrNotFound) {
+			d.logger.Error("error getting cached pack", "repo", repo, "err", err)
+		}
+		return key, nil, false
+	}
+
+	d.logger.Debug("serving cached pack", "repo", repo, "size", len(pack))
+	return key, pack, true
+}
+
+// CachePack caches the upload-pack response pack under key, responses larger
+// than the maximum pack size aren't cached.
+func (d *Backend) CachePack(ctx context.Context, key string, pack []byte) {
+	if d.gitCache == nil || key == "" {
+		return
+	}
+	if max := d.cfg.Cache.MaxPackSize; max > 0 && int64(len(pack)) > max {
+		return
+	}
+
+	d.cacheSet(ctx, key, append([]byte(nil), pack...))
+}
+
+// Readme returns the README of the repository and its path, like Readme, from
+// the cache when possible.
+func (d *Backend) Readme(ctx context.Context, r proto.Repository) (string, string, error) {
+	if d.gitCache == nil {
+		return Readme(r)
+	}
+
+	gr, err := r.Open()
+	if err != nil {
+		return "", "", err
+	}
+	head, err := gr.HEAD()
+	if err != nil {
+		return Readme(r)
+	}
+
+	key := cacheKey(r.Name(), "readme", head.Hash.String())
+	if v, err := d.gitCache.Get(ctx, key); err == nil {
+		path, readme, _ := strings.Cut(string(v), "\x00")
+		return readme, path, nil
+	}
+
+	readme, path, err := Readme(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	d.cacheSet(ctx, key, []byte(path+"\x00"+readme))
+	return readme, path, nil
+}
+
+// CommitsFrom returns a page of commits from cursor, like
+// git.Repository.CommitsFrom, from the cache when possible.
+func (d *Backend) CommitsFrom(_ context.Context, r proto.Repository, cursor []string, size int) (git.Commits, []string, error) {
+	gr, err := r.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.gitCache == nil {
+		return gr.CommitsFrom(cursor, size)
+	}
+
+	type page struct {
+		commits git.Commits
+		next    []string
+	}
+
+	key := cacheKey(r.Name(), "commits", strings.Join(cursor, ","), fmt.Sprint(size))
+	if v, ok := d.cache.objects.Get(key); ok {
+		if p, ok := v.(page); ok {
+			return append(git.Commits(nil), p.commits...), p.next, nil
+		}
+	}
+
+	cc, next, err := gr.CommitsFrom(cursor, size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d.cache.objects.Add(key, page{commits: append(git.Commits(nil), cc...), next: next})
+	return cc, next, nil
+}
+
+// TreeEntries returns the sorted entries of the tree at path in ref, from the
+// cache when possible.
+func (d *Backend) TreeEntries(_ context.Context, r proto.Repository, ref *git.Reference, path string) (git.Entries, error) {
+	key := cacheKey(r.Name(), "tree", ref.Hash.String(), path)
+	if d.gitCache != nil && ref.Hash != "" {
+		if v, ok := d.cache.objects.Get(key); ok {
+			if ents, ok := v.(git.Entries); ok {
+				return append(git.Entries(nil), ents...), nil
+			}
+		}
+	}
+
+	gr, err := r.Open()
+	if err != nil {
+		return nil, err
+	}
+	t, err := gr.TreePath(ref, path)
+	if err != nil {
+		return nil, err
+	}
+	ents, err := t.Entries()
+	if err != nil {
+		return nil, err
+	}
+	ents.Sort()
+
+	if d.gitCache != nil && ref.Hash != "" {
+		d.cache.objects.Add(key, append(git.Entries(nil), ents...))
+	}
+	return ents, nil
+}
+
+// InvalidateCache drops the cached metadata, packs, and objects of repo,
+// after a push or when it's deleted.
+func (d *Backend) InvalidateCache(ctx context.Context, repo string) {
+	prefix := utils.SanitizeRepo(repo) + ":"
+	if err := d.meta.DeletePrefix(ctx, prefix); err != nil {
+		d.logger.Error("error invalidating metadata", "repo", repo, "err", err)
+	}
+
+	if d.gitCache == nil {
+		return
+	}
+
+	d.cache.DeleteObjects(prefix)
+	if err := d.gitCache.DeletePrefix(ctx, prefix); err != nil {
+		d.logger.Error("error invalidating cache", "repo", repo, "err", err)
+	}
+}
+
+func (d *Backend) cacheSet(ctx context.Context, key string, value []byte) {
+	if err := d.gitCache.Set(ctx, key, value); err != nil {
+		d.logger.Error("error caching value", "key", key, "err", err)
+	}
+}
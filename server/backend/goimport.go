@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// GoImportDomain returns the root of the Go import paths of repositories.
+func (d *Backend) GoImportDomain() string {
+	if d.cfg.HTTP.GoImportDomain != "" {
+		return d.cfg.HTTP.GoImportDomain
+	}
+
+	u, err := url.Parse(d.cfg.HTTP.PublicURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
+
+// GoModule returns the Go module path of the repository under the import
+// domain. It returns an empty string if the repository uses its name.
+func (d *Backend) GoModule(ctx context.Context, repo string) (string, error) {
+	return d.RepoSetting(ctx, repo, goModuleSetting)
+}
+
+// GoImportPath returns the Go import path of the repository.
+func (d *Backend) GoImportPath(ctx context.Context, repo string) (string, error) {
+	m, err := d.GoModule(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	if m == "" {
+		m = utils.SanitizeRepo(repo)
+	}
+
+	return path.Join(d.GoImportDomain(), m), nil
+}
+
+// SetGoModule sets the Go module path of the repository under the import
+// domain. An empty path makes the repository use its name.
+func (d *Backend) SetGoModule(ctx context.Context, repo string, module string) error {
+	if module != "" {
+		if !validGoModule(module) {
+			return fmt.Errorf("invalid go module path %q", module)
+		}
+
+		// Repository names take precedence over module paths.
+		if _, err := d.Repository(ctx, module); err == nil && module != utils.SanitizeRepo(repo) {
+			return fmt.Errorf("go module path %q is a repository name", module)
+		}
+
+		if r, err := d.RepositoryByGoModule(ctx, module); err == nil && r.Name() != utils.SanitizeRepo(repo) {
+			return fmt.Errorf("go module path %q is used by %s", module, r.Name())
+		} else if err != nil && !errors.Is(err, proto.ErrRepoNotFound) {
+			return err
+		}
+	}
+
+	return d.SetRepoSetting(ctx, repo, goModuleSetting, module)
+}
+
+// RepositoryByGoModule returns the repository with the given Go module path.
+func (d *Backend) RepositoryByGoModule(ctx context.Context, module string) (proto.Repository, error) {
+	var name string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		name, err = d.store.GetRepoNameBySetting(ctx, tx, goModuleSetting, module)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, proto.ErrRepoNotFound
+		}
+		return nil, err
+	}
+
+	return d.Repository(ctx, name)
+}
+
+// validGoModule reports whether the module path is a clean relative path of
+// repository name characters.
+func validGoModule(module string) bool {
+	if err := utils.ValidateRepo(module); err != nil {
+		return false
+	}
+
+	for _, s := range strings.Split(module, "/") {
+		if s == "" || s == "." || s == ".." || strings.HasSuffix(s, ".git") {
+			return false
+		}
+	}
+
+	return true
+}
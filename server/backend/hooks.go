@@ -5,8 +5,10 @@ import (
 	"io"
 	"sync"
 
+	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/server/hooks"
 	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
 )
 
 var _ hooks.Hooks = (*Backend)(nil)
@@ -14,22 +16,89 @@ var _ hooks.Hooks = (*Backend)(nil)
 // PostReceive is called by the git post-receive hook.
 //
 // It implements Hooks.
-func (d *Backend) PostReceive(_ context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) {
+func (d *Backend) PostReceive(ctx context.Context, _ io.Writer, stderr io.Writer, repo string, args []hooks.HookArg) {
 	d.logger.Debug("post-receive hook called", "repo", repo, "args", args)
+
+	d.TimestampTags(ctx, stderr, repo, args)
+	d.ApplyRepoConfig(ctx, stderr, repo, args)
 }
 
 // PreReceive is called by the git pre-receive hook.
 //
 // It implements Hooks.
-func (d *Backend) PreReceive(_ context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) {
+func (d *Backend) PreReceive(ctx context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) error {
 	d.logger.Debug("pre-receive hook called", "repo", repo, "args", args)
+
+	if err := d.CheckMaintenance(ctx, repo); err != nil {
+		d.logger.Info("rejected push", "repo", repo, "err", err)
+		return err
+	}
+
+	if err := d.CheckArchived(ctx, repo); err != nil {
+		d.logger.Info("rejected push", "repo", repo, "err", err)
+		return err
+	}
+
+	if err := d.CheckPushPolicy(ctx, repo, args); err != nil {
+		d.logger.Info("rejected push", "repo", repo, "err", err)
+		return err
+	}
+
+	if err := d.CheckPushQuota(ctx, repo); err != nil {
+		d.logger.Info("rejected push", "repo", repo, "err", err)
+		return err
+	}
+
+	return nil
 }
 
 // Update is called by the git update hook.
 //
 // It implements Hooks.
-func (d *Backend) Update(_ context.Context, _ io.Writer, _ io.Writer, repo string, arg hooks.HookArg) {
+func (d *Backend) Update(ctx context.Context, _ io.Writer, _ io.Writer, repo string, arg hooks.HookArg) error {
 	d.logger.Debug("update hook called", "repo", repo, "arg", arg)
+
+	user := proto.UserFromContext(ctx)
+	if err := d.CheckRefPermission(ctx, repo, user, arg.RefName); err != nil {
+		d.logger.Info("rejected reference update", "repo", repo, "ref", arg.RefName, "err", err)
+		return err
+	}
+
+	if err := d.CheckBranchProtection(ctx, repo, user, arg); err != nil {
+		d.logger.Info("rejected reference update", "repo", repo, "ref", arg.RefName, "err", err)
+		return err
+	}
+
+	if d.isForcePush(ctx, repo, arg) {
+		d.Audit(ctx, proto.AuditEvent{
+			Action:  proto.AuditRepoForcePush,
+			Repo:    utils.SanitizeRepo(repo),
+			Target:  arg.RefName,
+			Details: arg.OldSha + ".." + arg.NewSha,
+		})
+	}
+
+	return nil
+}
+
+// isForcePush returns true if the reference update described by arg rewrites
+// its history.
+func (d *Backend) isForcePush(ctx context.Context, repo string, arg hooks.HookArg) bool {
+	if arg.OldSha == git.ZeroHash.String() || arg.NewSha == git.ZeroHash.String() {
+		return false
+	}
+
+	rr, err := d.Repository(ctx, repo)
+	if err != nil {
+		return false
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return false
+	}
+
+	return !r.IsAncestor(arg.OldSha, arg.NewSha)
 }
 
 // PostUpdate is called by the git post-update hook.
@@ -38,6 +107,10 @@ func (d *Backend) Update(_ context.Context, _ io.Writer, _ io.Writer, repo strin
 func (d *Backend) PostUpdate(ctx context.Context, _ io.Writer, _ io.Writer, repo string, args ...string) {
 	d.logger.Debug("post-update hook called", "repo", repo, "args", args)
 
+	// The hook might run in another process than the server, this reaches
+	// the server when the cache is shared.
+	d.InvalidateCache(ctx, repo)
+
 	var wg sync.WaitGroup
 
 	// Populate last-modified file.
@@ -50,6 +123,15 @@ func (d *Backend) PostUpdate(ctx context.Context, _ io.Writer, _ io.Writer, repo
 		}
 	}()
 
+	// Update the disk usage of the repository.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := d.UpdateRepoDiskUsage(ctx, repo); err != nil {
+			d.logger.Error("error updating disk usage", "repo", repo, "err", err)
+		}
+	}()
+
 	wg.Wait()
 }
 
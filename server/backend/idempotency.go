@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// Idempotent runs fn once for the user's idempotency key and records its
+// response. Calling it again with the same key and request within the
+// idempotency window returns the recorded response without running fn.
+// Failed requests aren't recorded so they can be retried.
+//
+// It returns proto.ErrIdempotencyKeyReused if the key was used for a
+// different request.
+func (d *Backend) Idempotent(ctx context.Context, user proto.User, key string, request string, fn func() (string, error)) (string, error) {
+	if key == "" || d.cfg.IdempotencyWindow <= 0 {
+		return fn()
+	}
+
+	if user == nil {
+		return "", proto.ErrUserNotFound
+	}
+
+	window := time.Duration(d.cfg.IdempotencyWindow) * time.Second
+	var m models.IdempotencyKey
+	err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetIdempotencyKey(ctx, tx, user.ID(), key)
+		return err
+	}))
+	switch {
+	case err == nil && time.Since(m.CreatedAt) > window:
+		// The key has expired, forget about it.
+		if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeleteIdempotencyKey(ctx, tx, user.ID(), key)
+		}); err != nil {
+			return "", db.WrapError(err)
+		}
+	case err == nil:
+		if m.Request != request {
+			return "", proto.ErrIdempotencyKeyReused
+		}
+		d.logger.Debug("replaying idempotent request", "key", key, "username", user.Username())
+		return m.Response, nil
+	case !errors.Is(err, db.ErrRecordNotFound):
+		return "", err
+	}
+
+	resp, err := fn()
+	if err != nil {
+		return resp, err
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateIdempotencyKey(ctx, tx, user.ID(), key, request, resp)
+	}); err != nil {
+		d.logger.Error("error recording idempotency key", "key", key, "err", err)
+	}
+
+	return resp, nil
+}
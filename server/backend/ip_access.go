@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// User setting keys of the IP access rules of a user.
+const (
+	ipAllowSetting = "ip-allow"
+	ipDenySetting  = "ip-deny"
+)
+
+// CheckAddress returns proto.ErrAddressDenied if a client connecting to
+// listener from addr isn't allowed by the server IP access rules or, when user
+// isn't nil, by the rules of user. Rejected connections are recorded in the
+// audit log.
+func (d *Backend) CheckAddress(ctx context.Context, listener string, addr string, user proto.User) error {
+	var ip net.IP
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		ip = net.ParseIP(host)
+	} else {
+		ip = net.ParseIP(addr)
+	}
+
+	reason := "server rules"
+	d.reloadMtx.RLock()
+	allowed := d.ipRules.Allows(ip)
+	d.reloadMtx.RUnlock()
+	if allowed && user != nil {
+		rules, err := d.UserIPRules(ctx, user)
+		if err != nil {
+			d.logger.Error("error getting user ip rules", "username", user.Username(), "err", err)
+		}
+		reason = "user rules"
+		allowed = rules.Allows(ip)
+	}
+
+	if allowed {
+		return nil
+	}
+
+	event := proto.AuditEvent{
+		Action:  proto.AuditConnectionReject,
+		Details: listener + ": " + reason,
+	}
+	if user != nil {
+		event.Username = user.Username()
+	}
+	d.logger.Info("connection rejected", "listener", listener, "addr", addr, "reason", reason)
+	d.Audit(proto.WithRemoteAddrContext(ctx, addr), event)
+	return proto.ErrAddressDenied
+}
+
+// UserIPRules returns the IP access rules of a user, on top of the server
+// rules.
+func (d *Backend) UserIPRules(ctx context.Context, user proto.User) (access.IPRules, error) {
+	allow, err := d.UserSetting(ctx, user, ipAllowSetting)
+	if err != nil {
+		return access.IPRules{}, err
+	}
+
+	deny, err := d.UserSetting(ctx, user, ipDenySetting)
+	if err != nil {
+		return access.IPRules{}, err
+	}
+
+	return access.ParseIPRules(splitList(allow), splitList(deny))
+}
+
+// SetUserIPRules sets the IP access rules of a user. Empty rules only apply
+// the server rules.
+func (d *Backend) SetUserIPRules(ctx context.Context, username string, rules access.IPRules) error {
+	user, err := d.User(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	allow, deny := rules.Strings()
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			for _, s := range []struct {
+				key   string
+				cidrs []string
+			}{{ipAllowSetting, allow}, {ipDenySetting, deny}} {
+				var err error
+				if len(s.cidrs) == 0 {
+					err = d.store.DeleteUserSetting(ctx, tx, user.ID(), s.key)
+				} else {
+					err = d.store.SetUserSetting(ctx, tx, user.ID(), s.key, strings.Join(s.cidrs, ","))
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{
+		Action:  proto.AuditUserIPRules,
+		Target:  user.Username(),
+		Details: "allow=" + strings.Join(allow, ",") + " deny=" + strings.Join(deny, ","),
+	})
+	return nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,293 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+const (
+	// jobPollInterval is how often the queue is polled for due jobs.
+	jobPollInterval = 5 * time.Second
+
+	// jobBaseBackoff is the delay before the second attempt of a job, it
+	// doubles with each attempt.
+	jobBaseBackoff = 10 * time.Second
+
+	// jobMaxBackoff is the maximum delay between two attempts of a job.
+	jobMaxBackoff = time.Hour
+
+	// jobStaleAfter is how long a job runs before it's considered interrupted
+	// in HA mode, where the other servers might still be running their jobs.
+	jobStaleAfter = time.Hour
+)
+
+// JobHandler runs a job of the queue with its JSON payload. A returned error
+// fails the attempt, and the job is retried until it runs out of attempts.
+type JobHandler func(ctx context.Context, payload []byte) error
+
+var (
+	jobHandlersMtx sync.RWMutex
+	jobHandlers    = map[string]JobHandler{}
+)
+
+// RegisterJobHandler registers the handler of a kind of job.
+func RegisterJobHandler(kind string, fn JobHandler) {
+	jobHandlersMtx.Lock()
+	defer jobHandlersMtx.Unlock()
+	jobHandlers[kind] = fn
+}
+
+func jobHandler(kind string) JobHandler {
+	jobHandlersMtx.RLock()
+	defer jobHandlersMtx.RUnlock()
+	return jobHandlers[kind]
+}
+
+// jobBackoff returns the delay before the next attempt of a job that failed
+// its nth attempt.
+func jobBackoff(attempts int) time.Duration {
+	d := jobBaseBackoff
+	for i := 1; i < attempts && d < jobMaxBackoff; i++ {
+		d *= 2
+	}
+	if d > jobMaxBackoff {
+		d = jobMaxBackoff
+	}
+
+	return d
+}
+
+// EnqueueJob queues a job of a kind with the JSON encoding of payload. A job
+// of the same kind and payload that's pending or running isn't queued twice.
+func (d *Backend) EnqueueJob(ctx context.Context, kind string, payload interface{}) error {
+	bts, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateJob(ctx, tx, kind, string(bts), atLeastOne(d.cfg.Jobs.MaxAttempts), time.Now())
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	// Wake up the workers.
+	select {
+	case d.jobsWake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Jobs returns the jobs of the queue with a status, or all of them when
+// status is empty.
+func (d *Backend) Jobs(ctx context.Context, status proto.JobStatus) ([]proto.Job, error) {
+	var ms []models.Job
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetJobs(ctx, tx, string(status))
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	jobs := make([]proto.Job, 0, len(ms))
+	for _, m := range ms {
+		jobs = append(jobs, jobFromModel(m))
+	}
+
+	return jobs, nil
+}
+
+// RetryJob queues a dead job again with a fresh set of attempts.
+func (d *Backend) RetryJob(ctx context.Context, id int64) error {
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.RetryJob(ctx, tx, id, time.Now())
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrJobNotFound
+		}
+		return err
+	}
+
+	select {
+	case d.jobsWake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// RunJobs runs the queued jobs until ctx is done, with at most
+// cfg.Jobs.Workers of them at once. The jobs left running by a previous
+// process are queued again first. In HA mode, the jobs running for longer
+// than jobStaleAfter are queued again instead, periodically.
+func (d *Backend) RunJobs(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	d.requeueRunningJobs(ctx)
+	if d.cfg.HA.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(jobStaleAfter)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					d.requeueRunningJobs(ctx)
+				}
+			}
+		}()
+	}
+
+	workers := make(chan struct{}, atLeastOne(d.cfg.Jobs.Workers))
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case workers <- struct{}{}:
+		}
+
+		job, err := d.claimJob(ctx)
+		if err != nil {
+			<-workers
+			if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+				d.logger.Error("error claiming job", "err", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.jobsWake:
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			d.runJob(ctx, job)
+		}()
+	}
+}
+
+func (d *Backend) requeueRunningJobs(ctx context.Context) {
+	now := time.Now()
+	claimedBefore := now
+	if d.cfg.HA.Enabled {
+		claimedBefore = now.Add(-jobStaleAfter)
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.RequeueRunningJobs(ctx, tx, claimedBefore, now)
+	}); err != nil && ctx.Err() == nil {
+		d.logger.Error("error requeuing running jobs", "err", err)
+	}
+}
+
+func (d *Backend) claimJob(ctx context.Context) (models.Job, error) {
+	var job models.Job
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		job, err = d.store.ClaimJob(ctx, tx, time.Now())
+		return err
+	})
+
+	return job, err
+}
+
+func (d *Backend) runJob(ctx context.Context, job models.Job) {
+	logger := d.logger.With("job", job.ID, "kind", job.Kind, "attempt", job.Attempts)
+	logger.Debug("running job")
+
+	var err error
+	if fn := jobHandler(job.Kind); fn != nil {
+		err = fn(ctx, []byte(job.Payload))
+	} else {
+		err = fmt.Errorf("unknown job kind: %q", job.Kind)
+		job.Attempts = job.MaxAttempts
+	}
+
+	if ctx.Err() != nil {
+		// The server is shutting down, the job is queued again on the next
+		// start.
+		return
+	}
+
+	if err == nil {
+		if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CompleteJob(ctx, tx, job.ID)
+		}); err != nil {
+			logger.Error("error completing job", "err", err)
+		}
+		return
+	}
+
+	var retryAt time.Time
+	if job.Attempts < job.MaxAttempts {
+		retryAt = time.Now().Add(jobBackoff(job.Attempts))
+		logger.Warn("job failed, retrying", "err", err, "retry_at", retryAt)
+	} else {
+		logger.Error("job failed, giving up", "err", err)
+	}
+
+	lastError := err.Error()
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.FailJob(ctx, tx, job.ID, lastError, retryAt)
+	}); err != nil {
+		logger.Error("error failing job", "err", err)
+	}
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func jobFromModel(m models.Job) proto.Job {
+	// The payloads of the jobs about a repository have its name, the
+	// webhook ones in their event.
+	var p struct {
+		Repo  string `json:"repo"`
+		Event struct {
+			Repo string `json:"repo"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal([]byte(m.Payload), &p); err == nil && p.Repo == "" {
+		p.Repo = p.Event.Repo
+	}
+
+	return proto.Job{
+		ID:          m.ID,
+		Kind:        m.Kind,
+		Payload:     m.Payload,
+		Repo:        p.Repo,
+		Status:      proto.JobStatus(m.Status),
+		Attempts:    m.Attempts,
+		MaxAttempts: m.MaxAttempts,
+		LastError:   m.LastError,
+		RunAt:       m.RunAt,
+		CreatedAt:   m.CreatedAt,
+	}
+}
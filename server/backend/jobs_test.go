@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestJobBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		4:  80 * time.Second,
+		10: time.Hour,
+		64: time.Hour,
+	} {
+		if got := jobBackoff(attempts); got != want {
+			t.Errorf("jobBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestJobQueue(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Jobs.MaxAttempts = 1
+	ctx, be := newTestBackend(t, cfg)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fail := true
+	ran := make(chan struct{}, 1)
+	RegisterJobHandler("test", func(context.Context, []byte) error {
+		defer func() { ran <- struct{}{} }()
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if err := be.EnqueueJob(ctx, "test", "payload"); err != nil {
+		t.Fatal(err)
+	}
+	// The same job isn't queued twice.
+	if err := be.EnqueueJob(ctx, "test", "payload"); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := be.Jobs(ctx, proto.JobPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 pending job, got %d", len(jobs))
+	}
+
+	go be.RunJobs(ctx)
+	<-ran
+	waitForJobs(t, be, proto.JobDead, 1)
+	jobs, _ = be.Jobs(ctx, proto.JobDead)
+	if jobs[0].LastError != "boom" || jobs[0].Attempts != 1 {
+		t.Errorf("unexpected dead job: %+v", jobs[0])
+	}
+
+	fail = false
+	if err := be.RetryJob(ctx, jobs[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	<-ran
+	waitForJobs(t, be, "", 0)
+
+	if err := be.RetryJob(ctx, jobs[0].ID); !errors.Is(err, proto.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func waitForJobs(t *testing.T, be *Backend, status proto.JobStatus, n int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		jobs, err := be.Jobs(context.Background(), status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs) == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d %q jobs", n, status)
+}
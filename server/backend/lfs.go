@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"io"
+	"io/fs"
 	"path"
-	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
 	"github.com/charmbracelet/soft-serve/server/lfs"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/storage"
@@ -20,10 +22,7 @@ import (
 func StoreRepoMissingLFSObjects(ctx context.Context, repo proto.Repository, dbx *db.DB, store store.Store, lfsClient lfs.Client) error {
 	cfg := config.FromContext(ctx)
 	repoID := strconv.FormatInt(repo.ID(), 10)
-	lfsRoot := filepath.Join(cfg.DataPath, "lfs", repoID)
-
-	// TODO: support S3 storage
-	strg := storage.NewLocalStorage(lfsRoot)
+	strg := storage.NewLFSStorage(cfg, repoID)
 	pointerChan := make(chan lfs.PointerBlob)
 	errChan := make(chan error, 1)
 	r, err := repo.Open()
@@ -86,3 +85,149 @@ func StoreRepoMissingLFSObjects(ctx context.Context, repo proto.Repository, dbx
 
 	return nil
 }
+
+// LFSStatus returns the Git LFS storage usage of a repository.
+func (d *Backend) LFSStatus(ctx context.Context, repo proto.Repository) (proto.LFSStatus, error) {
+	var status proto.LFSStatus
+	var objs []models.LFSObject
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		objs, err = d.store.GetLFSObjects(ctx, tx, repo.ID())
+		return err
+	}); err != nil {
+		return status, db.WrapError(err)
+	}
+
+	reachable, err := reachableLFSObjects(ctx, repo)
+	if err != nil {
+		return status, err
+	}
+
+	for _, obj := range objs {
+		status.Objects++
+		status.Size += obj.Size
+		if _, ok := reachable[obj.Oid]; !ok {
+			status.Unreachable++
+			status.UnreachableSize += obj.Size
+		}
+	}
+
+	return status, nil
+}
+
+// PruneLFSObjects removes the Git LFS objects of a repository that aren't
+// referenced by any ref and were uploaded more than retention ago. If dryRun
+// is true, it only returns the objects that would be removed.
+func (d *Backend) PruneLFSObjects(ctx context.Context, repo proto.Repository, retention time.Duration, dryRun bool) ([]proto.LFSObject, error) {
+	var objs []models.LFSObject
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		objs, err = d.store.GetLFSObjects(ctx, tx, repo.ID())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	reachable, err := reachableLFSObjects(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	strg := storage.NewLFSStorage(d.cfg, strconv.FormatInt(repo.ID(), 10))
+	pruned := make([]proto.LFSObject, 0)
+	for _, obj := range objs {
+		if _, ok := reachable[obj.Oid]; ok || time.Since(obj.CreatedAt) < retention {
+			continue
+		}
+
+		if !dryRun {
+			p := lfs.Pointer{Oid: obj.Oid, Size: obj.Size}
+			if err := strg.Delete(path.Join("objects", p.RelativePath())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return pruned, err
+			}
+
+			if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+				return d.store.DeleteLFSObjectByOid(ctx, tx, repo.ID(), obj.Oid)
+			}); err != nil {
+				return pruned, db.WrapError(err)
+			}
+		}
+
+		pruned = append(pruned, proto.LFSObject{
+			Oid:       obj.Oid,
+			Size:      obj.Size,
+			CreatedAt: obj.CreatedAt,
+		})
+	}
+
+	if len(pruned) > 0 && !dryRun {
+		d.logger.Info("pruned lfs objects", "repo", repo.Name(), "count", len(pruned))
+	}
+
+	return pruned, nil
+}
+
+// LFSObjects returns the stored Git LFS objects of a repository.
+func (d *Backend) LFSObjects(ctx context.Context, repo proto.Repository) ([]proto.LFSObject, error) {
+	var objs []models.LFSObject
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		objs, err = d.store.GetLFSObjects(ctx, tx, repo.ID())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	lobjs := make([]proto.LFSObject, 0, len(objs))
+	for _, obj := range objs {
+		lobjs = append(lobjs, proto.LFSObject{
+			Oid:       obj.Oid,
+			Size:      obj.Size,
+			CreatedAt: obj.CreatedAt,
+		})
+	}
+
+	return lobjs, nil
+}
+
+// OpenLFSObject opens the content of a stored Git LFS object of a repository.
+// It returns fs.ErrNotExist if the repository has no such object.
+func (d *Backend) OpenLFSObject(ctx context.Context, repo proto.Repository, oid string) (storage.Object, error) {
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		_, err := d.store.GetLFSObjectByOid(ctx, tx, repo.ID(), oid)
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, fs.ErrNotExist
+		}
+		return nil, db.WrapError(err)
+	}
+
+	p := lfs.Pointer{Oid: oid}
+	strg := storage.NewLFSStorage(d.cfg, strconv.FormatInt(repo.ID(), 10))
+	return strg.Open(path.Join("objects", p.RelativePath()))
+}
+
+// reachableLFSObjects returns the oids of the LFS objects referenced by any
+// ref of the repository.
+func reachableLFSObjects(ctx context.Context, repo proto.Repository) (map[string]struct{}, error) {
+	r, err := repo.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	pointerChan := make(chan lfs.PointerBlob)
+	errChan := make(chan error, 1)
+	go lfs.SearchPointerBlobs(ctx, r, pointerChan, errChan)
+
+	oids := make(map[string]struct{})
+	for pointer := range pointerChan {
+		oids[pointer.Oid] = struct{}{}
+	}
+
+	if err, ok := <-errChan; ok {
+		return nil, err
+	}
+
+	return oids, nil
+}
@@ -0,0 +1,136 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/storage"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// lockRetryInterval is how often a lock held by another server is tried
+// again.
+const lockRetryInterval = 250 * time.Millisecond
+
+// lockSeq numbers the locks taken by the server, so that each of them has its
+// own owner.
+var lockSeq atomic.Uint64
+
+// Lock takes the lock with a name shared between the servers in HA mode, and
+// returns a function that releases it. The lock is renewed until it's
+// released. It returns proto.ErrRepoLocked when the lock isn't taken within
+// the lock timeout. Out of HA mode, Lock is a no-op.
+func (d *Backend) Lock(ctx context.Context, name string) (func(), error) {
+	if !d.cfg.HA.Enabled {
+		return func() {}, nil
+	}
+
+	if timeout := d.cfg.HA.LockTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	owner := fmt.Sprintf("%s-%d", d.serverID, lockSeq.Add(1))
+	ttl := time.Duration(atLeastOne(d.cfg.HA.LockTTL)) * time.Second
+	for {
+		err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			now := time.Now()
+			return d.store.AcquireLock(ctx, tx, name, owner, now, now.Add(ttl))
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(db.WrapError(err), db.ErrDuplicateKey) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				d.logger.Warn("timed out waiting for lock", "lock", name)
+				return nil, proto.ErrRepoLocked
+			}
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := d.db.TransactionContext(d.ctx, func(tx *db.Tx) error {
+					return d.store.RefreshLock(d.ctx, tx, name, owner, time.Now().Add(ttl))
+				}); err != nil {
+					d.logger.Error("error renewing lock", "lock", name, "err", err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			if err := d.db.TransactionContext(d.ctx, func(tx *db.Tx) error {
+				return d.store.ReleaseLock(d.ctx, tx, name, owner)
+			}); err != nil {
+				d.logger.Error("error releasing lock", "lock", name, "err", err)
+			}
+		})
+	}, nil
+}
+
+// LockPush takes the lock of a repository while it's pushed to, so that
+// pushes to the same repository through different servers run one after the
+// other in HA mode.
+func (d *Backend) LockPush(ctx context.Context, repo string) (func(), error) {
+	return d.Lock(ctx, "push/"+utils.SanitizeRepo(repo))
+}
+
+// LockHooks takes the lock of a repository while its hooks run in HA mode.
+func (d *Backend) LockHooks(ctx context.Context, repo string) (func(), error) {
+	return d.Lock(ctx, "hooks/"+utils.SanitizeRepo(repo))
+}
+
+// haRepoStorage is a repository storage whose repositories are locked for all
+// the servers while they're created, renamed, or removed.
+type haRepoStorage struct {
+	storage.RepoStorage
+	d *Backend
+}
+
+// Lock implements storage.RepoStorage.
+func (s *haRepoStorage) Lock(ctx context.Context, name string) (func(), error) {
+	unlock, err := s.RepoStorage.Lock(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.d.Lock(ctx, "repo/"+name)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return func() {
+		release()
+		unlock()
+	}, nil
+}
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestLock(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HA.Enabled = true
+	ctx, be := newTestBackend(t, cfg)
+
+	unlock, err := be.LockPush(ctx, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock is held until it's released.
+	tctx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if _, err := be.LockPush(tctx, "repo"); !errors.Is(err, proto.ErrRepoLocked) {
+		t.Fatalf("expected ErrRepoLocked, got %v", err)
+	}
+	other, err := be.LockPush(ctx, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other()
+
+	unlock()
+	unlock, err = be.LockPush(ctx, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	// Expired locks are taken over.
+	now := time.Now()
+	if err := be.store.AcquireLock(ctx, be.db, "push/repo", "gone", now.Add(-time.Minute), now.Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err = be.LockPush(ctx, "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// maintenanceSetting is the repository setting key of the maintenance mode.
+const maintenanceSetting = "maintenance"
+
+// Maintenance returns the maintenance mode of the server, or nil when it's
+// off.
+func (d *Backend) Maintenance(ctx context.Context) (*proto.Maintenance, error) {
+	var value string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		value, err = d.store.GetMaintenance(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return d.parseMaintenance(value, ""), nil
+}
+
+// RepoMaintenance returns the maintenance mode of a repository, or nil when
+// it's off. It doesn't take the maintenance mode of the server into account.
+func (d *Backend) RepoMaintenance(ctx context.Context, repo string) (*proto.Maintenance, error) {
+	value, err := d.RepoSetting(ctx, utils.SanitizeRepo(repo), maintenanceSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.parseMaintenance(value, repo), nil
+}
+
+// SetMaintenance turns the maintenance mode of a repository on with a message
+// for the users, or off when m is nil. An empty repo sets the maintenance
+// mode of the whole server.
+func (d *Backend) SetMaintenance(ctx context.Context, repo string, m *proto.Maintenance) error {
+	if repo != "" {
+		repo = utils.SanitizeRepo(repo)
+	}
+
+	var value string
+	if m != nil {
+		if m.Since.IsZero() {
+			m.Since = time.Now().UTC()
+		}
+		bts, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		value = string(bts)
+	}
+
+	if repo == "" {
+		if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.SetMaintenance(ctx, tx, value)
+		}); err != nil {
+			return db.WrapError(err)
+		}
+	} else if err := d.SetRepoSetting(ctx, repo, maintenanceSetting, value); err != nil {
+		return err
+	}
+
+	details := "off"
+	if m != nil {
+		details = "on"
+		if m.Message != "" {
+			details += ": " + m.Message
+		}
+	}
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditSettingsMaintenance, Repo: repo, Details: details})
+
+	return nil
+}
+
+// CheckMaintenance returns a *proto.MaintenanceError when the server or the
+// repository is in maintenance mode, and a *proto.ReplicaError when the
+// server is a read-only replica. The repository doesn't have to exist.
+func (d *Backend) CheckMaintenance(ctx context.Context, repo string) error {
+	if d.cfg.Replica.Enabled {
+		return &proto.ReplicaError{Primary: d.cfg.Replica.Primary}
+	}
+
+	m, err := d.Maintenance(ctx)
+	if err != nil {
+		return err
+	}
+	if m != nil {
+		return &proto.MaintenanceError{Maintenance: *m}
+	}
+
+	if repo == "" {
+		return nil
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	m, err = d.RepoMaintenance(ctx, repo)
+	if err != nil {
+		if errors.Is(err, proto.ErrRepoNotFound) {
+			return nil
+		}
+		return err
+	}
+	if m != nil {
+		return &proto.MaintenanceError{Repo: repo, Maintenance: *m}
+	}
+
+	return nil
+}
+
+// parseMaintenance parses a stored maintenance mode. Invalid values are
+// logged and treated as maintenance without a message, the safe side.
+func (d *Backend) parseMaintenance(value string, repo string) *proto.Maintenance {
+	if value == "" {
+		return nil
+	}
+
+	var m proto.Maintenance
+	if err := json.Unmarshal([]byte(value), &m); err != nil {
+		d.logger.Error("invalid maintenance setting", "repo", repo, "err", err)
+	}
+
+	return &m
+}
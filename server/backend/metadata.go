@@ -0,0 +1,67 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/kvcache"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+const (
+	// metaCacheSize is the size of the in-memory metadata cache in bytes.
+	metaCacheSize = 8 << 20 // 8 MiB
+
+	// metaMaxAge is how long the cached metadata of a repository is used,
+	// in case an update of the repository was missed.
+	metaMaxAge = 10 * time.Minute
+)
+
+// cachedRepoMetadata is the cached metadata of a repository.
+type cachedRepoMetadata struct {
+	proto.RepoMetadata
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// metaKey returns the key of the metadata of repo, it's dropped with the
+// other cached values of the repository by InvalidateCache.
+func metaKey(repo string) string {
+	return repo + ":"
+}
+
+// RepoMetadata returns the metadata of a repository read from git, from the
+// metadata cache when possible. Listing many repositories doesn't have to
+// open each of them.
+func (d *Backend) RepoMetadata(ctx context.Context, r proto.Repository) proto.RepoMetadata {
+	key := metaKey(r.Name())
+	v, err := d.meta.Get(ctx, key)
+	if err == nil {
+		var m cachedRepoMetadata
+		if err := json.Unmarshal(v, &m); err == nil && time.Since(m.CachedAt) < metaMaxAge {
+			return m.RepoMetadata
+		}
+	} else if !errors.Is(err, kvcache.ErrNotFound) {
+		d.logger.Error("error getting cached metadata", "repo", r.Name(), "err", err)
+	}
+
+	m := cachedRepoMetadata{
+		RepoMetadata: proto.RepoMetadata{UpdatedAt: r.UpdatedAt()},
+		CachedAt:     time.Now(),
+	}
+	// Empty repositories don't have a default branch yet.
+	if rr, err := r.Open(); err == nil {
+		if head, err := rr.HEAD(); err == nil {
+			m.DefaultBranch = head.Name().Short()
+		}
+	}
+
+	if v, err := json.Marshal(m); err == nil {
+		if err := d.meta.Set(ctx, key, v); err != nil {
+			d.logger.Error("error caching metadata", "repo", r.Name(), "err", err)
+		}
+	}
+
+	return m.RepoMetadata
+}
@@ -0,0 +1,73 @@
+package backend
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// MOTD returns the message of the day, markdown shown at the top of the TUI.
+// It's empty when it isn't set.
+func (d *Backend) MOTD(ctx context.Context) (string, error) {
+	var motd string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		motd, err = d.store.GetMOTD(ctx, tx)
+		return err
+	}); err != nil {
+		return "", db.WrapError(err)
+	}
+
+	return motd, nil
+}
+
+// SetMOTD sets the message of the day, an empty motd clears it. The
+// connected sessions show the new message right away.
+func (d *Backend) SetMOTD(ctx context.Context, motd string) error {
+	motd = strings.TrimSpace(motd)
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetMOTD(ctx, tx, motd)
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	details := "cleared"
+	if motd != "" {
+		details = "set"
+	}
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditSettingsMOTD, Details: details})
+	d.announce(ctx, events.Event{Type: events.MOTD, Message: motd})
+
+	return nil
+}
+
+// Broadcast sends a message to the TUIs of the connected sessions, it isn't
+// stored.
+func (d *Backend) Broadcast(ctx context.Context, msg string) {
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditBroadcast, Details: msg})
+	d.announce(ctx, events.Event{Type: events.Broadcast, Message: msg})
+}
+
+// SubscribeAnnouncements subscribes to the MOTD changes and the broadcasts.
+// The subscription is closed when ctx is done.
+func (d *Backend) SubscribeAnnouncements(ctx context.Context) *events.Subscription {
+	sub := d.announcements.Subscribe(0)
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+
+	return sub
+}
+
+func (d *Backend) announce(ctx context.Context, e events.Event) {
+	if user := proto.UserFromContext(ctx); user != nil {
+		e.Username = user.Username()
+	}
+
+	d.announcements.Publish(e)
+	d.shareEvent(e, true)
+}
@@ -0,0 +1,128 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/auth"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// loginTokenName is the name of the access tokens issued on login.
+const loginTokenName = "oidc login"
+
+var (
+	// ErrIdentityNotLinked is returned when an identity that isn't linked to
+	// a user logs in.
+	ErrIdentityNotLinked = errors.New("identity is not linked to a user")
+	// ErrIdentityLinked is returned when linking an identity that's already
+	// linked to another user.
+	ErrIdentityLinked = errors.New("identity is linked to another user")
+)
+
+// OIDC returns the OpenID Connect provider users log in with, or nil.
+func (d *Backend) OIDC() *auth.OIDC {
+	d.reloadMtx.RLock()
+	defer d.reloadMtx.RUnlock()
+	return d.oidc
+}
+
+// UserByIdentity returns the user an OpenID Connect identity logs in as. The
+// identity must have been linked to the user with LinkIdentity, identities
+// are never matched by their username claim since providers often let users
+// change it.
+func (d *Backend) UserByIdentity(ctx context.Context, id auth.Identity) (proto.User, error) {
+	if id.Subject == "" {
+		return nil, ErrIdentityNotLinked
+	}
+
+	var userID int64
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		userID, err = d.store.GetUserIDBySetting(ctx, tx, subjectSetting, id.Subject)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, ErrIdentityNotLinked
+		}
+		return nil, err
+	}
+
+	user, err := d.UserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsSuspended() {
+		return nil, proto.ErrUserSuspended
+	}
+
+	return user, nil
+}
+
+// UserIdentity returns the subject of the OpenID Connect identity linked to
+// the user, or an empty string.
+func (d *Backend) UserIdentity(ctx context.Context, user proto.User) (string, error) {
+	return d.UserSetting(ctx, user, subjectSetting)
+}
+
+// LinkIdentity links an OpenID Connect identity, by its subject, to a user.
+// The identity logs in as the user from then on. An identity can only be
+// linked to one user, and a user to one identity.
+func (d *Backend) LinkIdentity(ctx context.Context, user proto.User, subject string) error {
+	if user == nil {
+		return proto.ErrUserNotFound
+	}
+	if subject == "" {
+		return errors.New("subject cannot be empty")
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			id, err := d.store.GetUserIDBySetting(ctx, tx, subjectSetting, subject)
+			switch {
+			case err == nil && id != user.ID():
+				return ErrIdentityLinked
+			case err != nil && !errors.Is(db.WrapError(err), db.ErrRecordNotFound):
+				return err
+			}
+
+			return d.store.SetUserSetting(ctx, tx, user.ID(), subjectSetting, subject)
+		}),
+	)
+}
+
+// UnlinkIdentity removes the OpenID Connect identity linked to a user.
+func (d *Backend) UnlinkIdentity(ctx context.Context, user proto.User) error {
+	return d.SetUserSetting(ctx, user, subjectSetting, "")
+}
+
+// CreateLoginToken creates a short-lived access token for a user that logged
+// in with the OpenID Connect provider. It grants at most read-write access,
+// enough to push over HTTP. The expired login tokens of the user are deleted.
+func (d *Backend) CreateLoginToken(ctx context.Context, user proto.User) (string, time.Time, error) {
+	tokens, err := d.ListAccessTokens(ctx, user)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	for _, t := range tokens {
+		if t.Name == loginTokenName && t.IsExpired() {
+			if err := d.DeleteAccessToken(ctx, user, t.ID); err != nil {
+				return "", time.Time{}, err
+			}
+		}
+	}
+
+	expiresAt := time.Now().Add(time.Duration(d.cfg.Current().OIDC.TokenExpiry) * time.Second)
+	token, err := d.CreateAccessToken(ctx, user, loginTokenName, expiresAt, access.ReadWriteAccess, "")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
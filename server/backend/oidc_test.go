@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/auth"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestUserByIdentity(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	admin, err := be.User(ctx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := be.CreateUser(ctx, "bob", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An unlinked user is never claimed by an identity with the same
+	// username.
+	for _, id := range []auth.Identity{
+		{Subject: "mallory", Username: "admin"},
+		{Subject: "mallory", Username: "bob"},
+		{Subject: "", Username: "bob"},
+	} {
+		if _, err := be.UserByIdentity(ctx, id); !errors.Is(err, ErrIdentityNotLinked) {
+			t.Fatalf("%+v: expected %v, got %v", id, ErrIdentityNotLinked, err)
+		}
+	}
+	if subject, err := be.UserIdentity(ctx, admin); err != nil || subject != "" {
+		t.Fatalf("expected admin to stay unlinked, got %q (%v)", subject, err)
+	}
+
+	if err := be.LinkIdentity(ctx, bob, "bob-subject"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Linked identities log in as their user whatever their username.
+	user, err := be.UserByIdentity(ctx, auth.Identity{Subject: "bob-subject", Username: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username() != "bob" {
+		t.Fatalf("expected bob, got %q", user.Username())
+	}
+
+	if err := be.LinkIdentity(ctx, admin, "bob-subject"); !errors.Is(err, ErrIdentityLinked) {
+		t.Fatalf("expected %v, got %v", ErrIdentityLinked, err)
+	}
+
+	if err := be.SetSuspended(ctx, "bob", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.UserByIdentity(ctx, auth.Identity{Subject: "bob-subject"}); !errors.Is(err, proto.ErrUserSuspended) {
+		t.Fatalf("expected %v, got %v", proto.ErrUserSuspended, err)
+	}
+
+	if err := be.UnlinkIdentity(ctx, bob); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.UserByIdentity(ctx, auth.Identity{Subject: "bob-subject"}); !errors.Is(err, ErrIdentityNotLinked) {
+		t.Fatalf("expected %v, got %v", ErrIdentityNotLinked, err)
+	}
+}
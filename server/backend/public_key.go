@@ -0,0 +1,180 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"golang.org/x/crypto/ssh"
+)
+
+// AddPublicKeyWithOptions adds a public key to a user, with a label, an
+// expiry, and a restriction.
+func (d *Backend) AddPublicKeyWithOptions(ctx context.Context, username string, pk ssh.PublicKey, opts proto.PublicKeyOptions) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	var expiresAt sql.NullTime
+	if !opts.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: opts.ExpiresAt, Valid: true}
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if err := d.store.AddPublicKeyByUsername(ctx, tx, username, pk); err != nil {
+				return err
+			}
+			if opts.Label == "" && !expiresAt.Valid && opts.Restriction == proto.KeyUnrestricted {
+				return nil
+			}
+
+			return d.store.UpdatePublicKeyByUsername(ctx, tx, username, pk, opts.Label, expiresAt, string(opts.Restriction))
+		}),
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUserKeyAdd, Target: username, Details: ssh.FingerprintSHA256(pk)})
+
+	return nil
+}
+
+// UserPublicKeys returns the public keys of a user, with their labels, when
+// they were last used, and when they expire.
+func (d *Backend) UserPublicKeys(ctx context.Context, username string) ([]proto.PublicKey, error) {
+	var ms []models.PublicKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.ListPublicKeyDetailsByUsername(ctx, tx, username)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	keys := make([]proto.PublicKey, 0, len(ms))
+	for _, m := range ms {
+		pk, _, err := sshutils.ParseAuthorizedKey(m.PublicKey)
+		if err != nil {
+			d.logger.Error("error parsing public key", "id", m.ID, "err", err)
+			continue
+		}
+
+		key := proto.PublicKey{
+			ID:          m.ID,
+			PublicKey:   pk,
+			Label:       m.Label,
+			Restriction: proto.KeyRestriction(m.Restriction),
+			CreatedAt:   m.CreatedAt,
+		}
+		if m.LastUsedAt.Valid {
+			key.LastUsedAt = m.LastUsedAt.Time
+		}
+		if m.ExpiresAt.Valid {
+			key.ExpiresAt = m.ExpiresAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// RemovePublicKeyByID removes the public key of a user with an ID.
+func (d *Backend) RemovePublicKeyByID(ctx context.Context, username string, id int64) error {
+	keys, err := d.UserPublicKeys(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.ID == id {
+			return d.RemovePublicKey(ctx, username, k.PublicKey)
+		}
+	}
+
+	return proto.ErrPublicKeyNotFound
+}
+
+// SetPublicKeyRestriction sets the restriction of the public key of a user
+// with an ID.
+func (d *Backend) SetPublicKeyRestriction(ctx context.Context, username string, id int64, r proto.KeyRestriction) error {
+	keys, err := d.UserPublicKeys(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if k.ID != id {
+			continue
+		}
+
+		var expiresAt sql.NullTime
+		if !k.ExpiresAt.IsZero() {
+			expiresAt = sql.NullTime{Time: k.ExpiresAt, Valid: true}
+		}
+		if err := db.WrapError(
+			d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+				return d.store.UpdatePublicKeyByUsername(ctx, tx, username, k.PublicKey, k.Label, expiresAt, string(r))
+			}),
+		); err != nil {
+			return err
+		}
+
+		d.Audit(ctx, proto.AuditEvent{
+			Action:  proto.AuditUserKeyRestrict,
+			Target:  username,
+			Details: ssh.FingerprintSHA256(k.PublicKey) + " " + r.String(),
+		})
+
+		return nil
+	}
+
+	return proto.ErrPublicKeyNotFound
+}
+
+// KeyRestriction returns the restriction of the public key a user
+// authenticated with, KeyUnrestricted if the user didn't authenticate with a
+// public key.
+func KeyRestriction(u proto.User) proto.KeyRestriction {
+	if tu, ok := u.(*user); ok {
+		return tu.keyRestriction
+	}
+	return proto.KeyUnrestricted
+}
+
+// CheckPublicKey returns ErrPublicKeyExpired if a user public key is
+// expired. Keys that don't belong to a user, like deploy keys, are never
+// expired.
+func (d *Backend) CheckPublicKey(ctx context.Context, pk ssh.PublicKey) error {
+	var m models.PublicKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetPublicKey(ctx, tx, pk)
+		return err
+	}); err != nil {
+		if err = db.WrapError(err); errors.Is(err, db.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if (proto.PublicKey{ExpiresAt: m.ExpiresAt.Time}).IsExpired() {
+		return proto.ErrPublicKeyExpired
+	}
+
+	return nil
+}
+
+// RecordPublicKeyUse records a user public key was used to authenticate.
+func (d *Backend) RecordPublicKeyUse(ctx context.Context, pk ssh.PublicKey) error {
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetPublicKeyLastUsed(ctx, tx, pk)
+	}))
+}
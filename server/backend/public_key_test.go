@@ -0,0 +1,129 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+)
+
+func TestCheckPublicKey(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	if _, err := be.CreateUser(ctx, "user1", proto.UserOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pk1, _, err := sshutils.ParseAuthorizedKey(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk2, _, err := sshutils.ParseAuthorizedKey(testKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := be.AddPublicKeyWithOptions(ctx, "user1", pk1, proto.PublicKeyOptions{Label: "laptop"}); err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := time.Now().Add(-time.Minute)
+	if err := be.AddPublicKeyWithOptions(ctx, "user1", pk2, proto.PublicKeyOptions{ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expired keys are rejected, checking a key doesn't record it was used.
+	if err := be.CheckPublicKey(ctx, pk1); err != nil {
+		t.Fatal(err)
+	}
+	if keys, err := be.UserPublicKeys(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	} else if !keys[0].LastUsedAt.IsZero() {
+		t.Errorf("expected checked keys not to be used, got %v", keys[0].LastUsedAt)
+	}
+	if err := be.RecordPublicKeyUse(ctx, pk1); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.CheckPublicKey(ctx, pk2); !errors.Is(err, proto.ErrPublicKeyExpired) {
+		t.Fatalf("expected %v, got %v", proto.ErrPublicKeyExpired, err)
+	}
+
+	keys, err := be.UserPublicKeys(ctx, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	if k := keys[0]; k.Label != "laptop" || k.LastUsedAt.IsZero() || k.IsExpired() {
+		t.Errorf("unexpected first key %+v", k)
+	}
+	if k := keys[1]; !k.LastUsedAt.IsZero() || !k.IsExpired() {
+		t.Errorf("unexpected second key %+v", k)
+	}
+
+	// Keys are removed by ID.
+	if err := be.RemovePublicKeyByID(ctx, "user1", 42); !errors.Is(err, proto.ErrPublicKeyNotFound) {
+		t.Errorf("expected %v, got %v", proto.ErrPublicKeyNotFound, err)
+	}
+	if err := be.RemovePublicKeyByID(ctx, "user1", keys[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.CheckPublicKey(ctx, pk2); err != nil {
+		t.Errorf("expected removed keys to be unknown, got %v", err)
+	}
+}
+
+func TestPublicKeyRestriction(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	if _, err := be.CreateUser(ctx, "user1", proto.UserOptions{Admin: true}); err != nil {
+		t.Fatal(err)
+	}
+	pk1, _, err := sshutils.ParseAuthorizedKey(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := be.AddPublicKeyWithOptions(ctx, "user1", pk1, proto.PublicKeyOptions{Restriction: proto.KeyReadOnly}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read-only keys cap the access of admins.
+	u, err := be.UserByPublicKey(ctx, pk1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := KeyRestriction(u); r != proto.KeyReadOnly {
+		t.Errorf("expected %q, got %q", proto.KeyReadOnly, r)
+	}
+	if u.IsAdmin() {
+		t.Error("expected read-only keys not to be admins")
+	}
+	if level := be.AccessLevelForUser(ctx, "repo1", u); level != access.ReadOnlyAccess {
+		t.Errorf("expected %s, got %s", access.ReadOnlyAccess, level)
+	}
+	if level := be.AccessLevelByPublicKey(ctx, "repo1", pk1); level != access.ReadOnlyAccess {
+		t.Errorf("expected %s by public key, got %s", access.ReadOnlyAccess, level)
+	}
+
+	// Git-only keys keep the access of their user.
+	keys, err := be.UserPublicKeys(ctx, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetPublicKeyRestriction(ctx, "user1", keys[0].ID, proto.KeyGitOnly); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetPublicKeyRestriction(ctx, "user1", 42, proto.KeyGitOnly); !errors.Is(err, proto.ErrPublicKeyNotFound) {
+		t.Errorf("expected %v, got %v", proto.ErrPublicKeyNotFound, err)
+	}
+	u, err = be.UserByPublicKey(ctx, pk1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := KeyRestriction(u); r != proto.KeyGitOnly {
+		t.Errorf("expected %q, got %q", proto.KeyGitOnly, r)
+	}
+	if level := be.AccessLevelForUser(ctx, "repo1", u); level != access.AdminAccess {
+		t.Errorf("expected %s, got %s", access.AdminAccess, level)
+	}
+}
@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/git"
+)
+
+// Push options handled by the server, sent with git push -o. The options are
+// also published with the events of the push, and hooks get them in the
+// GIT_PUSH_OPTION_COUNT and GIT_PUSH_OPTION_<n> environment variables.
+const (
+	// PushOptionSkipCI skips the webhook deliveries of a push. Other
+	// consumers of the events of the push can skip it too.
+	PushOptionSkipCI = "skip-ci"
+	// PushOptionDescription sets the description of a repository on its
+	// first push.
+	PushOptionDescription = "description"
+)
+
+// applyPushOptions applies the push options of a push to a repository. first
+// is true for the first push of the repository.
+func (d *Backend) applyPushOptions(ctx context.Context, repo string, req git.Request, first bool) {
+	if desc, ok := req.PushOption(PushOptionDescription); ok && first {
+		if err := d.SetDescription(ctx, repo, desc); err != nil {
+			d.logger.Error("error setting description from push option", "repo", repo, "err", err)
+		}
+	}
+}
+
+// hasPushOption returns true if the push options contain the option name,
+// with or without a value.
+func hasPushOption(opts []string, name string) bool {
+	for _, o := range opts {
+		if k, _, _ := strings.Cut(o, "="); k == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/lfs"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/dustin/go-humanize"
+)
+
+// PushPolicy returns the push policy of a repository. Repository overrides
+// take precedence over the server configuration.
+func (d *Backend) PushPolicy(ctx context.Context, repo string) (proto.PushPolicy, error) {
+	policy := proto.PushPolicy{
+		MaxBlobSize:      d.cfg.Current().Push.MaxBlobSize,
+		MaxPushSize:      d.cfg.Current().Push.MaxPushSize,
+		BannedExtensions: proto.NormalizeExtensions(d.cfg.Current().Push.BannedExtensions),
+	}
+
+	m, err := d.pushPolicy(ctx, repo)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return policy, nil
+		}
+		return policy, err
+	}
+
+	if m.MaxBlobSize.Valid {
+		policy.MaxBlobSize = m.MaxBlobSize.Int64
+	}
+	if m.MaxPushSize.Valid {
+		policy.MaxPushSize = m.MaxPushSize.Int64
+	}
+	if m.BannedExtensions.Valid {
+		policy.BannedExtensions = splitExtensions(m.BannedExtensions.String)
+	}
+
+	return policy, nil
+}
+
+// SetPushPolicy overrides the push policy of a repository. Options that are
+// not set keep their current value.
+func (d *Backend) SetPushPolicy(ctx context.Context, repo string, opts proto.PushPolicyOptions) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	m, err := d.pushPolicy(ctx, repo)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return err
+	}
+
+	if opts.MaxBlobSize != nil {
+		m.MaxBlobSize = sql.NullInt64{Int64: *opts.MaxBlobSize, Valid: true}
+	}
+	if opts.MaxPushSize != nil {
+		m.MaxPushSize = sql.NullInt64{Int64: *opts.MaxPushSize, Valid: true}
+	}
+	if opts.BannedExtensions != nil {
+		m.BannedExtensions = sql.NullString{
+			String: strings.Join(proto.NormalizeExtensions(*opts.BannedExtensions), ","),
+			Valid:  true,
+		}
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.UpsertPushPolicy(ctx, tx, repo, m.MaxBlobSize, m.MaxPushSize, m.BannedExtensions)
+		}),
+	)
+}
+
+// ResetPushPolicy removes the push policy overrides of a repository.
+func (d *Backend) ResetPushPolicy(ctx context.Context, repo string) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeletePushPolicyByRepo(ctx, tx, repo)
+		}),
+	)
+}
+
+// CheckPushPolicy checks whether the objects pushed by the reference updates
+// in args are allowed by the push policy of the repository. It returns an
+// error wrapping proto.ErrPushRejected if the push must be rejected.
+func (d *Backend) CheckPushPolicy(ctx context.Context, repo string, args []hooks.HookArg) error {
+	policy, err := d.PushPolicy(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if policy.IsZero() {
+		return nil
+	}
+
+	// Only look at the objects that don't exist in the repository yet.
+	revs := make([]string, 0, len(args)+2)
+	for _, arg := range args {
+		if arg.NewSha != git.ZeroHash.String() {
+			revs = append(revs, arg.NewSha)
+		}
+	}
+
+	if len(revs) == 0 {
+		return nil
+	}
+
+	revs = append(revs, "--not", "--all")
+	rr, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return err
+	}
+
+	objs, err := r.Objects(revs...)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, obj := range objs {
+		total += obj.Size
+		if obj.Type != "blob" {
+			continue
+		}
+
+		if ext, ok := policy.BannedExtension(obj.Path); ok && !isLFSPointer(r, obj) {
+			return fmt.Errorf("%w: %q files are not allowed (%s), use Git LFS to store them instead", proto.ErrPushRejected, ext, obj.Path)
+		}
+
+		if policy.MaxBlobSize > 0 && obj.Size > policy.MaxBlobSize {
+			return fmt.Errorf("%w: %s is %s which exceeds the maximum file size of %s, use Git LFS to store large files",
+				proto.ErrPushRejected, obj.Path, humanize.IBytes(uint64(obj.Size)), humanize.IBytes(uint64(policy.MaxBlobSize)))
+		}
+	}
+
+	if policy.MaxPushSize > 0 && total > policy.MaxPushSize {
+		return fmt.Errorf("%w: push size of %s exceeds the maximum of %s, use Git LFS to store large files or split your push",
+			proto.ErrPushRejected, humanize.IBytes(uint64(total)), humanize.IBytes(uint64(policy.MaxPushSize)))
+	}
+
+	return nil
+}
+
+func (d *Backend) pushPolicy(ctx context.Context, repo string) (models.PushPolicy, error) {
+	var m models.PushPolicy
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetPushPolicyByRepo(ctx, tx, repo)
+		return err
+	})
+	return m, db.WrapError(err)
+}
+
+// isLFSPointer returns whether the blob is a Git LFS pointer file.
+func isLFSPointer(r *git.Repository, obj git.Object) bool {
+	// Pointer files are tiny, don't bother reading large blobs.
+	if obj.Size > 1024 {
+		return false
+	}
+
+	content, err := git.NewCommand("cat-file", "blob", obj.Hash.String()).RunInDir(r.Path)
+	if err != nil {
+		return false
+	}
+
+	_, err = lfs.ReadPointerFromBuffer(content)
+	return err == nil
+}
+
+func splitExtensions(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
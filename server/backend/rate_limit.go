@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ratelimit"
+)
+
+// RateOperation is a kind of operation rate limited per user and address.
+type RateOperation int
+
+// Rate limited operations.
+const (
+	// RateSSHSession is an SSH session, a command or the TUI.
+	RateSSHSession RateOperation = iota
+	// RateGitOperation is a clone, a fetch, or a push.
+	RateGitOperation
+	// RateHTTPRequest is an HTTP request.
+	RateHTTPRequest
+
+	rateOperations
+)
+
+// String returns the name of the operation.
+func (o RateOperation) String() string {
+	switch o {
+	case RateSSHSession:
+		return "ssh-session"
+	case RateGitOperation:
+		return "git-operation"
+	case RateHTTPRequest:
+		return "http-request"
+	}
+	return "unknown"
+}
+
+// rateLimiters are the limiters of an operation.
+type rateLimiters struct {
+	user *ratelimit.Limiter
+	ip   *ratelimit.Limiter
+}
+
+func newRateLimiters(cfg config.RateLimitConfig) [rateOperations]rateLimiters {
+	limit := func(l config.RateLimit) ratelimit.Limit {
+		return ratelimit.Limit{PerMinute: l.PerMinute, Burst: l.Burst}
+	}
+
+	var ls [rateOperations]rateLimiters
+	for op, l := range map[RateOperation][2]config.RateLimit{
+		RateSSHSession:   {cfg.User.SSHSessions, cfg.IP.SSHSessions},
+		RateGitOperation: {cfg.User.GitOperations, cfg.IP.GitOperations},
+		RateHTTPRequest:  {cfg.User.HTTPRequests, cfg.IP.HTTPRequests},
+	} {
+		ls[op] = rateLimiters{
+			user: ratelimit.New(limit(l[0])),
+			ip:   ratelimit.New(limit(l[1])),
+		}
+	}
+	return ls
+}
+
+// RateLimit counts an operation of user from addr against the rate limits of
+// both, either can be empty. It returns a *proto.RateLimitError when one of
+// the limits is exceeded.
+func (d *Backend) RateLimit(_ context.Context, op RateOperation, user proto.User, addr string) error {
+	if op < 0 || op >= rateOperations {
+		return nil
+	}
+
+	d.reloadMtx.RLock()
+	ls := d.rateLimits[op]
+	d.reloadMtx.RUnlock()
+	var wait time.Duration
+	if user != nil {
+		if ok, w := ls.user.Allow(strconv.FormatInt(user.ID(), 10)); !ok {
+			wait = w
+		}
+	}
+	if addr != "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+		if ok, w := ls.ip.Allow(addr); !ok && w > wait {
+			wait = w
+		}
+	}
+
+	if wait == 0 {
+		return nil
+	}
+
+	d.logger.Debug("rate limited", "operation", op, "addr", addr, "retry", wait)
+	return rateLimitError(wait)
+}
+
+// rateLimitError returns the error of a limit exceeded for wait.
+func rateLimitError(wait time.Duration) error {
+	// Round up to the second, retrying earlier would be limited again.
+	return &proto.RateLimitError{RetryAfter: (wait + time.Second - 1).Truncate(time.Second)}
+}
@@ -0,0 +1,85 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// ReadMarkers returns the commits the user has seen in a repository. The
+// first time a user looks at a repository, all the existing commits are
+// marked as read.
+func (d *Backend) ReadMarkers(ctx context.Context, repo string, user proto.User) (proto.ReadMarkers, error) {
+	markers := proto.ReadMarkers{
+		Commits: map[string]struct{}{},
+	}
+	if user == nil {
+		return markers, nil
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		readAt, err := d.store.GetRepoReadAt(ctx, tx, user.ID(), repo)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			if err := d.store.MarkRepoRead(ctx, tx, user.ID(), repo); err != nil {
+				return err
+			}
+			readAt, err = d.store.GetRepoReadAt(ctx, tx, user.ID(), repo)
+		}
+		if err != nil {
+			return err
+		}
+
+		hashes, err := d.store.GetCommitReads(ctx, tx, user.ID(), repo)
+		if err != nil {
+			return err
+		}
+
+		markers.ReadAt = readAt
+		for _, h := range hashes {
+			markers.Commits[h] = struct{}{}
+		}
+
+		return nil
+	}); err != nil {
+		return proto.ReadMarkers{}, db.WrapError(err)
+	}
+
+	return markers, nil
+}
+
+// MarkCommitRead marks a commit as seen by the user.
+func (d *Backend) MarkCommitRead(ctx context.Context, repo string, user proto.User, hash string) error {
+	if user == nil {
+		return nil
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.AddCommitRead(ctx, tx, user.ID(), repo, hash)
+		}),
+	)
+}
+
+// MarkRepoRead marks all the commits of a repository as seen by the user.
+func (d *Backend) MarkRepoRead(ctx context.Context, repo string, user proto.User) error {
+	if user == nil {
+		return nil
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if err := d.store.MarkRepoRead(ctx, tx, user.ID(), repo); err != nil {
+				return err
+			}
+
+			// Commits older than the read marker don't need to be tracked.
+			return d.store.DeleteCommitReads(ctx, tx, user.ID(), repo)
+		}),
+	)
+}
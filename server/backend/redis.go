@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/kvcache"
+)
+
+const (
+	// eventsChannel is the Redis channel the servers share their events
+	// through.
+	eventsChannel = "events"
+
+	// sharedEventsSize is the number of events waiting to be shared before
+	// new ones are dropped.
+	sharedEventsSize = 256
+
+	// relayMaxBackoff is the maximum delay before subscribing again to the
+	// events of the other servers.
+	relayMaxBackoff = 30 * time.Second
+)
+
+// sharedEvent is an event shared with the other servers.
+type sharedEvent struct {
+	// Server is the ID of the server the event happened on.
+	Server string `json:"server"`
+	// Announcement is true for the MOTD changes and the broadcasts.
+	Announcement bool         `json:"announcement,omitempty"`
+	Public       bool         `json:"public,omitempty"`
+	Event        events.Event `json:"event"`
+}
+
+// newRedis returns the Redis server shared with the other servers, nil when
+// it isn't configured.
+func newRedis(cfg config.RedisConfig) *kvcache.Redis {
+	if cfg.Addr == "" {
+		return nil
+	}
+
+	return kvcache.NewRedis(kvcache.RedisOptions{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		TTL:      time.Duration(cfg.TTL) * time.Second,
+		Prefix:   "soft-serve:shared:",
+	})
+}
+
+func newServerID() string {
+	b := make([]byte, 8)
+	rand.Read(b) // nolint: errcheck
+	return hex.EncodeToString(b)
+}
+
+// shareEvent queues an event to be shared with the other servers.
+func (d *Backend) shareEvent(e events.Event, announcement bool) {
+	if d.redis == nil || e.Remote {
+		return
+	}
+
+	select {
+	case d.sharedEvents <- sharedEvent{Server: d.serverID, Announcement: announcement, Public: e.Public, Event: e}:
+	default:
+		d.logger.Warn("too many events to share, dropping event", "type", e.Type, "repo", e.Repo)
+	}
+}
+
+// RelayEvents shares the events with the other servers using the same Redis
+// server, and publishes theirs, until ctx is done. It returns right away
+// when Redis isn't configured.
+func (d *Backend) RelayEvents(ctx context.Context) {
+	if d.redis == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case se := <-d.sharedEvents:
+				msg, err := json.Marshal(se)
+				if err != nil {
+					d.logger.Error("error encoding event", "err", err)
+					continue
+				}
+				if err := d.redis.Publish(ctx, eventsChannel, msg); err != nil && ctx.Err() == nil {
+					d.logger.Error("error sharing event", "type", se.Event.Type, "err", err)
+				}
+			}
+		}
+	}()
+
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := d.redis.Subscribe(ctx, eventsChannel, d.relayEvent)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > relayMaxBackoff {
+			backoff = time.Second
+		}
+		d.logger.Error("error subscribing to shared events", "err", err, "retry_in", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > relayMaxBackoff {
+			backoff = relayMaxBackoff
+		}
+	}
+}
+
+// relayEvent publishes an event of another server, and drops what it
+// changed from the caches of the server.
+func (d *Backend) relayEvent(msg []byte) {
+	var se sharedEvent
+	if err := json.Unmarshal(msg, &se); err != nil {
+		d.logger.Error("error decoding shared event", "err", err)
+		return
+	}
+	if se.Server == d.serverID {
+		return
+	}
+
+	e := se.Event
+	e.ID = 0
+	e.Public = se.Public
+	e.Remote = true
+	if se.Announcement {
+		d.announcements.Publish(e)
+		return
+	}
+
+	switch e.Type {
+	case events.RepoCreate, events.RepoImport, events.RepoDelete:
+		d.cache.Delete(e.Repo)
+	case events.RepoRename:
+		d.cache.Delete(e.From)
+		d.cache.Delete(e.Repo)
+	default:
+		d.cache.DeleteObjects(e.Repo + ":")
+	}
+
+	d.events.Publish(e)
+}
@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/kvcache"
+)
+
+func TestRelayEvent(t *testing.T) {
+	d := &Backend{
+		logger:        log.New(io.Discard),
+		serverID:      "local",
+		events:        events.NewBroker(8),
+		announcements: events.NewBroker(1),
+		redis:         kvcache.NewRedis(kvcache.RedisOptions{}),
+		sharedEvents:  make(chan sharedEvent, 8),
+	}
+	d.cache = newCache(d, 8)
+	sub := d.events.Subscribe(0)
+	defer sub.Close()
+	ann := d.announcements.Subscribe(0)
+	defer ann.Close()
+
+	relay := func(se sharedEvent) {
+		msg, err := json.Marshal(se)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.relayEvent(msg)
+	}
+
+	// The events of the server itself aren't published twice.
+	relay(sharedEvent{Server: "local", Event: events.Event{Type: events.Push, Repo: "a"}})
+	relay(sharedEvent{Server: "remote", Public: true, Event: events.Event{ID: 1, Type: events.Push, Repo: "b"}})
+	relay(sharedEvent{Server: "remote", Announcement: true, Event: events.Event{Type: events.MOTD, Message: "hi"}})
+
+	e := <-sub.Events()
+	if e.Repo != "b" || !e.Remote || !e.Public {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	select {
+	case e := <-sub.Events():
+		t.Errorf("unexpected event: %+v", e)
+	default:
+	}
+	if e := <-ann.Events(); e.Message != "hi" || !e.Remote {
+		t.Errorf("unexpected announcement: %+v", e)
+	}
+
+	// Relayed events aren't shared again.
+	d.shareEvent(e, false)
+	d.shareEvent(events.Event{Type: events.Push, Repo: "c"}, false)
+	if len(d.sharedEvents) != 1 {
+		t.Fatalf("expected 1 shared event, got %d", len(d.sharedEvents))
+	}
+	if se := <-d.sharedEvents; se.Server != "local" || se.Event.Repo != "c" {
+		t.Errorf("unexpected shared event: %+v", se)
+	}
+}
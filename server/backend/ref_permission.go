@@ -0,0 +1,193 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// GrantRefPermission allows users to push to the references matching
+// pattern. Once a reference matches a pattern, only admins and the users
+// granted a matching pattern can push to it.
+func (d *Backend) GrantRefPermission(ctx context.Context, repo string, pattern string, usernames ...string) error {
+	repo = utils.SanitizeRepo(repo)
+	pattern = proto.RefPattern(pattern)
+	if _, err := path.Match(pattern, ""); err != nil || pattern == "refs/heads/" {
+		return fmt.Errorf("invalid reference pattern: %q", pattern)
+	}
+
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			for _, username := range usernames {
+				u, err := d.store.FindUserByUsername(ctx, tx, username)
+				if err != nil {
+					if errors.Is(err, db.ErrRecordNotFound) {
+						return proto.ErrUserNotFound
+					}
+					return err
+				}
+
+				if err := d.store.AddRefPermission(ctx, tx, repo, pattern, u.ID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}),
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{
+		Action:  proto.AuditRefPermissionGrant,
+		Repo:    repo,
+		Target:  pattern,
+		Details: strings.Join(usernames, ","),
+	})
+
+	return nil
+}
+
+// RevokeRefPermission disallows users to push to the references matching
+// pattern. Revoking all the users of a pattern lifts its restriction. When no
+// users are given, the pattern is removed.
+func (d *Backend) RevokeRefPermission(ctx context.Context, repo string, pattern string, usernames ...string) error {
+	repo = utils.SanitizeRepo(repo)
+	pattern = proto.RefPattern(pattern)
+	perms, err := d.RefPermissions(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	for _, p := range perms {
+		if p.Pattern == pattern {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return proto.ErrRefPermissionNotFound
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if len(usernames) == 0 {
+				return d.store.RemoveRefPermissionsByPattern(ctx, tx, repo, pattern)
+			}
+
+			for _, username := range usernames {
+				u, err := d.store.FindUserByUsername(ctx, tx, username)
+				if err != nil {
+					if errors.Is(err, db.ErrRecordNotFound) {
+						return proto.ErrUserNotFound
+					}
+					return err
+				}
+
+				if err := d.store.RemoveRefPermission(ctx, tx, repo, pattern, u.ID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}),
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{
+		Action:  proto.AuditRefPermissionRevoke,
+		Repo:    repo,
+		Target:  pattern,
+		Details: strings.Join(usernames, ","),
+	})
+
+	return nil
+}
+
+// RefPermissions returns the reference permissions of a repository sorted by
+// pattern.
+func (d *Backend) RefPermissions(ctx context.Context, repo string) ([]proto.RefPermission, error) {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	var perms []proto.RefPermission
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.GetRefPermissionsByRepo(ctx, tx, repo)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range ms {
+			u, err := d.store.GetUserByID(ctx, tx, m.UserID)
+			if err != nil {
+				return err
+			}
+
+			if n := len(perms); n > 0 && perms[n-1].Pattern == m.Pattern {
+				perms[n-1].Users = append(perms[n-1].Users, u.Username)
+				continue
+			}
+
+			perms = append(perms, proto.RefPermission{
+				Pattern: m.Pattern,
+				Users:   []string{u.Username},
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return perms, nil
+}
+
+// CheckRefPermission checks whether the user is allowed to update the full
+// reference name ref. It returns an error wrapping proto.ErrRefRestricted if
+// the reference matches a permission pattern the user isn't granted. Admins
+// can always push.
+func (d *Backend) CheckRefPermission(ctx context.Context, repo string, user proto.User, ref string) error {
+	perms, err := d.RefPermissions(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	var restricted bool
+	for _, p := range perms {
+		if !p.Matches(ref) {
+			continue
+		}
+
+		restricted = true
+		if user == nil {
+			continue
+		}
+
+		for _, u := range p.Users {
+			if u == user.Username() {
+				return nil
+			}
+		}
+	}
+
+	if !restricted || d.isPushAdmin(ctx, repo, user) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: you are not allowed to push to %q", proto.ErrRefRestricted, ref)
+}
@@ -0,0 +1,364 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	gitm "github.com/gogs/git-module"
+)
+
+// DeleteBranch deletes a branch of a repository. It refuses to delete the
+// default branch, branches protected from deletion, and branches the user
+// isn't allowed to push to.
+func (d *Backend) DeleteBranch(ctx context.Context, repo string, user proto.User, branch string) error {
+	rn := utils.SanitizeRepo(repo)
+	rr, err := d.Repository(ctx, rn)
+	if err != nil {
+		return err
+	}
+	if err := d.CheckArchived(ctx, rn); err != nil {
+		return err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return err
+	}
+
+	branches, _ := r.Branches()
+	var exists bool
+	for _, b := range branches {
+		if branch == b {
+			exists = true
+			break
+		}
+	}
+
+	if !exists {
+		return git.ErrReferenceNotExist
+	}
+
+	head, err := r.HEAD()
+	if err != nil {
+		return err
+	}
+
+	if head.Name().Short() == branch {
+		return proto.ErrDefaultBranch
+	}
+
+	rules, err := d.BranchProtections(ctx, rn)
+	if err != nil {
+		return err
+	}
+
+	if err := d.checkDeleteBranch(ctx, rn, user, rules, branch); err != nil {
+		return err
+	}
+
+	if err := r.DeleteBranch(branch, gitm.DeleteBranchOptions{Force: true}); err != nil {
+		return err
+	}
+
+	d.PublishEvent(ctx, refEvent(events.BranchDelete, rn, user, git.RefsHeads+branch))
+
+	return nil
+}
+
+// checkDeleteBranch returns an error if the branch protection rules or the
+// reference permissions of a repository don't let user delete a branch.
+func (d *Backend) checkDeleteBranch(ctx context.Context, repo string, user proto.User, rules []proto.BranchProtection, branch string) error {
+	for _, rule := range rules {
+		if !rule.Matches(branch) {
+			continue
+		}
+		if rule.RestrictPush && !d.canPushRestricted(ctx, repo, user, rule) {
+			return fmt.Errorf("%w: you are not allowed to push to %q", proto.ErrBranchProtected, branch)
+		}
+		if rule.NoDeletion {
+			return fmt.Errorf("%w: cannot delete %q", proto.ErrBranchProtected, branch)
+		}
+	}
+
+	return d.CheckRefPermission(ctx, repo, user, git.RefsHeads+branch)
+}
+
+// PruneBranches deletes the stale branches of a repository: the branches
+// fully merged into the default branch if merged is true, and whose last
+// commit is older than olderThan if it's positive. The default branch and the
+// branches user isn't allowed to delete are kept. If dryRun is true, it only
+// returns the branches that would be deleted.
+func (d *Backend) PruneBranches(ctx context.Context, repo string, user proto.User, merged bool, olderThan time.Duration, dryRun bool) ([]proto.StaleBranch, error) {
+	if !merged && olderThan <= 0 {
+		return nil, errors.New("stale branches must be merged or older than a duration")
+	}
+
+	rn := utils.SanitizeRepo(repo)
+	rr, err := d.Repository(ctx, rn)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		if err := d.CheckArchived(ctx, rn); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := r.HEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := listBranches(ctx, r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	mergedBranches, err := listBranches(ctx, r.Path, "--merged="+head.Name().String())
+	if err != nil {
+		return nil, err
+	}
+	isMerged := make(map[string]bool, len(mergedBranches))
+	for _, b := range mergedBranches {
+		isMerged[b.Name] = true
+	}
+
+	rules, err := d.BranchProtections(ctx, rn)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := make([]proto.StaleBranch, 0)
+	for _, b := range branches {
+		b.Merged = isMerged[b.Name]
+		if b.Name == head.Name().Short() ||
+			merged && !b.Merged ||
+			olderThan > 0 && time.Since(b.CommittedAt) < olderThan {
+			continue
+		}
+
+		if err := d.checkDeleteBranch(ctx, rn, user, rules, b.Name); err != nil {
+			d.logger.Debug("keeping stale branch", "repo", rn, "branch", b.Name, "err", err)
+			continue
+		}
+
+		if !dryRun {
+			if err := r.DeleteBranch(b.Name, gitm.DeleteBranchOptions{Force: true}); err != nil {
+				return pruned, err
+			}
+
+			d.PublishEvent(ctx, refEvent(events.BranchDelete, rn, user, git.RefsHeads+b.Name))
+		}
+
+		pruned = append(pruned, b)
+	}
+
+	if len(pruned) > 0 && !dryRun {
+		d.InvalidateCache(ctx, rn)
+		d.logger.Info("pruned branches", "repo", rn, "count", len(pruned))
+	}
+
+	return pruned, nil
+}
+
+// listBranches returns the branches of a git repository with their last
+// commit, filtered by the extra git for-each-ref args.
+func listBranches(ctx context.Context, rp string, args ...string) ([]proto.StaleBranch, error) {
+	args = append([]string{"for-each-ref", "--format=%(refname)%09%(objectname)%09%(committerdate:unix)"}, args...)
+	out, err := git.NewCommand(append(args, git.RefsHeads)...).WithContext(ctx).RunInDir(rp)
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+
+	var branches []proto.StaleBranch
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+
+		ts, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		branches = append(branches, proto.StaleBranch{
+			Name:        strings.TrimPrefix(fields[0], git.RefsHeads),
+			Commit:      fields[1],
+			CommittedAt: time.Unix(ts, 0),
+		})
+	}
+
+	return branches, nil
+}
+
+// DeleteTag deletes a tag of a repository, and its timestamp. It refuses to
+// delete tags the user isn't allowed to push to.
+func (d *Backend) DeleteTag(ctx context.Context, repo string, user proto.User, tag string) error {
+	rn := utils.SanitizeRepo(repo)
+	rr, err := d.Repository(ctx, rn)
+	if err != nil {
+		return err
+	}
+	if err := d.CheckArchived(ctx, rn); err != nil {
+		return err
+	}
+
+	if err := d.CheckRefPermission(ctx, rn, user, git.RefsTags+tag); err != nil {
+		return err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return err
+	}
+
+	if err := r.DeleteTag(tag); err != nil {
+		return err
+	}
+
+	d.PublishEvent(ctx, refEvent(events.TagDelete, rn, user, git.RefsTags+tag))
+
+	return d.DeleteTagTimestamp(ctx, rn, tag)
+}
+
+// CreateTag creates a tag of a repository pointing to the commit of the
+// revision rev, HEAD if empty, and returns the ID of the object of the tag.
+// The tag is annotated when it has a message or is signed, and lightweight
+// otherwise. It refuses to create tags the user isn't allowed to push to.
+func (d *Backend) CreateTag(ctx context.Context, repo string, user proto.User, tag string, rev string, opts proto.TagOptions) (string, error) {
+	rn := utils.SanitizeRepo(repo)
+	if err := d.CheckMaintenance(ctx, rn); err != nil {
+		return "", err
+	}
+	if err := d.CheckArchived(ctx, rn); err != nil {
+		return "", err
+	}
+
+	rr, err := d.Repository(ctx, rn)
+	if err != nil {
+		return "", err
+	}
+
+	ref := git.RefsTags + tag
+	if strings.HasPrefix(tag, "-") {
+		return "", fmt.Errorf("invalid tag name %q", tag)
+	}
+	if _, err := git.NewCommand("check-ref-format", ref).WithContext(ctx).Run(); err != nil {
+		return "", fmt.Errorf("invalid tag name %q", tag)
+	}
+
+	if err := d.CheckRefPermission(ctx, rn, user, ref); err != nil {
+		return "", err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return "", err
+	}
+
+	if r.HasTag(tag) {
+		return "", fmt.Errorf("%w: %s", proto.ErrTagExist, tag)
+	}
+
+	if rev == "" {
+		rev = "HEAD"
+	}
+	if strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("%w: %s", git.ErrRevisionNotExist, rev)
+	}
+	c, err := r.CatFileCommit(rev + "^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", git.ErrRevisionNotExist, rev)
+	}
+
+	oid := c.ID.String()
+	if opts.Message != "" || opts.Sign {
+		if oid, err = d.writeTagObject(ctx, r.Path, user, tag, oid, opts); err != nil {
+			return "", err
+		}
+	}
+
+	// The empty old value makes git refuse to overwrite a concurrent tag.
+	if _, err := git.NewCommand("update-ref", ref, oid, "").WithContext(ctx).RunInDir(r.Path); err != nil {
+		return "", fmt.Errorf("%w: %s", proto.ErrTagExist, tag)
+	}
+
+	d.InvalidateCache(ctx, rn)
+
+	e := refEvent(events.TagCreate, rn, user, ref)
+	e.After = oid
+	d.PublishEvent(ctx, e)
+
+	if d.cfg.Timestamp.URL != "" {
+		if err := d.timestampTag(ctx, rn, tag, oid); err != nil {
+			d.logger.Error("error timestamping tag", "repo", rn, "tag", tag, "err", err)
+		}
+	}
+
+	return oid, nil
+}
+
+// writeTagObject writes the object of an annotated tag of a commit, tagged by
+// user and signed with the SSH key of the server if opts.Sign is true, and
+// returns its ID.
+func (d *Backend) writeTagObject(ctx context.Context, rp string, user proto.User, tag string, commit string, opts proto.TagOptions) (string, error) {
+	msg := strings.TrimSpace(opts.Message)
+	if msg == "" {
+		msg = tag
+	}
+
+	name, email := d.initAuthor(ctx, user)
+	var obj strings.Builder
+	fmt.Fprintf(&obj, "object %s\ntype commit\ntag %s\n", commit, tag)
+	now := time.Now()
+	fmt.Fprintf(&obj, "tagger %s <%s> %d %s\n\n%s\n", name, email, now.Unix(), now.Format("-0700"), msg)
+
+	if opts.Sign {
+		kp, err := d.cfg.SSH.KeyPair()
+		if err != nil {
+			return "", fmt.Errorf("server key: %w", err)
+		}
+
+		sig, err := sshutils.Sign(kp.Signer(), "git", []byte(obj.String()))
+		if err != nil {
+			return "", fmt.Errorf("sign tag: %w", err)
+		}
+		obj.WriteString(sig)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := git.NewCommand("mktag").WithContext(ctx).RunInDirWithOptions(rp, git.RunInDirOptions{
+		Stdin:  strings.NewReader(obj.String()),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}); err != nil {
+		return "", fmt.Errorf("git mktag: %w: %s", err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// refEvent returns the event of a reference deleted by user.
+func refEvent(t events.Type, repo string, user proto.User, ref string) events.Event {
+	e := events.Event{Type: t, Repo: repo, Ref: ref}
+	if user != nil {
+		e.Username = user.Username()
+	}
+	return e
+}
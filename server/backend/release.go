@@ -0,0 +1,381 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// ErrReleaseAssetTooLarge is returned when an uploaded release asset is
+// larger than the maximum size.
+var ErrReleaseAssetTooLarge = errors.New("release asset is too large")
+
+// Releases returns the releases of a repository, newest first. Assets are not
+// included.
+func (d *Backend) Releases(ctx context.Context, repo string) ([]proto.Release, error) {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	var rels []proto.Release
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.GetReleasesByRepo(ctx, tx, repo)
+		if err != nil {
+			return err
+		}
+
+		rels = make([]proto.Release, 0, len(ms))
+		for _, m := range ms {
+			rels = append(rels, d.newRelease(ctx, tx, m))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return rels, nil
+}
+
+// Release returns the release of a tag with its assets.
+func (d *Backend) Release(ctx context.Context, repo string, tag string) (proto.Release, error) {
+	repo = utils.SanitizeRepo(repo)
+	var rel proto.Release
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		m, err := d.store.GetReleaseByRepoAndTag(ctx, tx, repo, tag)
+		if err != nil {
+			return err
+		}
+
+		rel = d.newRelease(ctx, tx, m)
+		assets, err := d.store.GetReleaseAssets(ctx, tx, m.ID)
+		if err != nil {
+			return err
+		}
+
+		rel.Assets = make([]proto.ReleaseAsset, 0, len(assets))
+		for _, a := range assets {
+			rel.Assets = append(rel.Assets, newReleaseAsset(a))
+		}
+
+		return nil
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return rel, proto.ErrReleaseNotFound
+		}
+		return rel, err
+	}
+
+	return rel, nil
+}
+
+// CreateRelease creates a release of an existing tag. The notes are markdown.
+// The user of the context is the author of the release.
+func (d *Backend) CreateRelease(ctx context.Context, repo string, tag string, name string, notes string) (proto.Release, error) {
+	repo = utils.SanitizeRepo(repo)
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return proto.Release{}, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return proto.Release{}, err
+	}
+
+	if !rr.HasTag(tag) {
+		return proto.Release{}, fmt.Errorf("%w: tag %q", git.ErrReferenceNotExist, tag)
+	}
+
+	if _, err := d.Release(ctx, repo, tag); err == nil {
+		return proto.Release{}, proto.ErrReleaseExist
+	} else if !errors.Is(err, proto.ErrReleaseNotFound) {
+		return proto.Release{}, err
+	}
+
+	var userID int64
+	if user := proto.UserFromContext(ctx); user != nil {
+		userID = user.ID()
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CreateRelease(ctx, tx, repo, tag, name, notes, userID)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.Release{}, proto.ErrReleaseExist
+		}
+		return proto.Release{}, err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditReleaseCreate, Repo: repo, Target: tag})
+
+	return d.Release(ctx, repo, tag)
+}
+
+// UpdateRelease sets the name and the notes of a release.
+func (d *Backend) UpdateRelease(ctx context.Context, repo string, tag string, name string, notes string) error {
+	m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.UpdateRelease(ctx, tx, m.ID, name, notes)
+		}),
+	)
+}
+
+// DeleteRelease deletes a release and its assets. The tag is kept.
+func (d *Backend) DeleteRelease(ctx context.Context, repo string, tag string) error {
+	repo = utils.SanitizeRepo(repo)
+	m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeleteReleaseByID(ctx, tx, m.ID)
+		}),
+	); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(d.releasePath(m)); err != nil {
+		d.logger.Error("failed to remove release assets", "repo", repo, "tag", tag, "err", err)
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditReleaseDelete, Repo: repo, Target: tag})
+
+	return nil
+}
+
+// UploadReleaseAsset stores the file read from r as an asset of a release. It
+// replaces the asset with the same name.
+func (d *Backend) UploadReleaseAsset(ctx context.Context, repo string, tag string, name string, r io.Reader) (proto.ReleaseAsset, error) {
+	if err := ValidateReleaseAssetName(name); err != nil {
+		return proto.ReleaseAsset{}, err
+	}
+
+	max := d.cfg.Releases.MaxAssetSize
+	if max <= 0 {
+		return proto.ReleaseAsset{}, errors.New("release asset uploads are disabled")
+	}
+
+	m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return proto.ReleaseAsset{}, err
+	}
+
+	dir := d.releasePath(m)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return proto.ReleaseAsset{}, err
+	}
+
+	// Write the file aside, so that it's never served half written.
+	f, err := os.CreateTemp(dir, ".upload-")
+	if err != nil {
+		return proto.ReleaseAsset{}, err
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, max+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return proto.ReleaseAsset{}, err
+	}
+	if n > max {
+		return proto.ReleaseAsset{}, fmt.Errorf("%w: the maximum size is %d bytes", ErrReleaseAssetTooLarge, max)
+	}
+
+	if err := os.Rename(f.Name(), filepath.Join(dir, name)); err != nil {
+		return proto.ReleaseAsset{}, err
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	var a models.ReleaseAsset
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if err := d.store.SetReleaseAsset(ctx, tx, m.ID, name, n, sum); err != nil {
+				return err
+			}
+
+			var err error
+			a, err = d.store.GetReleaseAssetByName(ctx, tx, m.ID, name)
+			return err
+		}),
+	); err != nil {
+		return proto.ReleaseAsset{}, err
+	}
+
+	return newReleaseAsset(a), nil
+}
+
+// OpenReleaseAsset opens the file of a release asset. The caller must close
+// the file.
+func (d *Backend) OpenReleaseAsset(ctx context.Context, repo string, tag string, name string) (*os.File, proto.ReleaseAsset, error) {
+	m, a, err := d.releaseAsset(ctx, repo, tag, name)
+	if err != nil {
+		return nil, proto.ReleaseAsset{}, err
+	}
+
+	f, err := os.Open(filepath.Join(d.releasePath(m), a.Name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, proto.ReleaseAsset{}, proto.ErrReleaseNotFound
+		}
+		return nil, proto.ReleaseAsset{}, err
+	}
+
+	return f, newReleaseAsset(a), nil
+}
+
+// DeleteReleaseAsset deletes an asset of a release.
+func (d *Backend) DeleteReleaseAsset(ctx context.Context, repo string, tag string, name string) error {
+	m, a, err := d.releaseAsset(ctx, repo, tag, name)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeleteReleaseAsset(ctx, tx, m.ID, a.Name)
+		}),
+	); err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(d.releasePath(m), a.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		d.logger.Error("failed to remove release asset", "repo", repo, "tag", tag, "name", name, "err", err)
+	}
+
+	return nil
+}
+
+// ValidateReleaseAssetName returns an error if the name isn't a valid release
+// asset file name.
+func ValidateReleaseAssetName(name string) error {
+	if name == "" {
+		return errors.New("asset name cannot be empty")
+	}
+
+	if strings.HasPrefix(name, ".") {
+		return errors.New("asset name cannot start with a dot")
+	}
+
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') &&
+			r != '-' && r != '_' && r != '.' && r != '+' {
+			return errors.New("asset name can only contain letters, digits, and -_.+")
+		}
+	}
+
+	return nil
+}
+
+// release returns the release model of a tag.
+func (d *Backend) release(ctx context.Context, repo string, tag string) (models.Release, error) {
+	var m models.Release
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetReleaseByRepoAndTag(ctx, tx, utils.SanitizeRepo(repo), tag)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return m, proto.ErrReleaseNotFound
+		}
+		return m, err
+	}
+
+	return m, nil
+}
+
+// releaseAsset returns the release and the asset models of an asset.
+func (d *Backend) releaseAsset(ctx context.Context, repo string, tag string, name string) (models.Release, models.ReleaseAsset, error) {
+	var a models.ReleaseAsset
+	m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return m, a, err
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		a, err = d.store.GetReleaseAssetByName(ctx, tx, m.ID, name)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return m, a, proto.ErrReleaseNotFound
+		}
+		return m, a, err
+	}
+
+	return m, a, nil
+}
+
+// newRelease returns the release of a model, with the username of its author.
+func (d *Backend) newRelease(ctx context.Context, tx *db.Tx, m models.Release) proto.Release {
+	rel := proto.Release{
+		ID:        m.ID,
+		Tag:       m.Tag,
+		Name:      m.Name,
+		Notes:     m.Notes,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+
+	if m.UserID.Valid {
+		if u, err := d.store.GetUserByID(ctx, tx, m.UserID.Int64); err == nil {
+			rel.Username = u.Username
+		}
+	}
+
+	return rel
+}
+
+func newReleaseAsset(m models.ReleaseAsset) proto.ReleaseAsset {
+	return proto.ReleaseAsset{
+		Name:      m.Name,
+		Size:      m.Size,
+		SHA256:    m.SHA256,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// releasesPath returns the directory of the release assets of a repository.
+func (d *Backend) releasesPath(repoID int64) string {
+	return filepath.Join(d.cfg.DataPath, "releases", strconv.FormatInt(repoID, 10))
+}
+
+// releasePath returns the directory of the assets of a release.
+func (d *Backend) releasePath(m models.Release) string {
+	return filepath.Join(d.releasesPath(m.RepoID), strconv.FormatInt(m.ID, 10))
+}
+
+// ReleaseAssetURL returns the HTTP download URL of a release asset.
+func (d *Backend) ReleaseAssetURL(repo string, tag string, name string) string {
+	return fmt.Sprintf("%s/%s/releases/download/%s/%s", d.cfg.HTTP.PublicURL, utils.SanitizeRepo(repo), tag, name)
+}
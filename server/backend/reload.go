@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/auth"
+	"github.com/charmbracelet/soft-serve/server/config"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// ReloadConfig reads the configuration file and the environment variables
+// again, and applies the settings that can change while the server runs, see
+// config.Config.Reload. Active sessions and connections are kept. Nothing is
+// applied when the new configuration is invalid.
+func (d *Backend) ReloadConfig(ctx context.Context) error {
+	cfg := config.DefaultConfig()
+	cfg.DataPath = d.cfg.DataPath
+	if cfg.Exist() {
+		if err := cfg.ParseFile(); err != nil {
+			return fmt.Errorf("parse config file: %w", err)
+		}
+	}
+
+	if err := cfg.ParseEnv(); err != nil {
+		return fmt.Errorf("parse environment variables: %w", err)
+	}
+
+	rules, err := access.ParseIPRules(cfg.IPAccess.Allow, cfg.IPAccess.Deny)
+	if err != nil {
+		return fmt.Errorf("invalid ip access rules: %w", err)
+	}
+
+	d.reloadMtx.Lock()
+	// The providers and the rate limiters keep their state, like the
+	// counts of the limiters, unless their settings change.
+	cur := d.cfg.Current()
+	if !reflect.DeepEqual(cur.LDAP, cfg.LDAP) {
+		d.provider = nil
+		if cfg.LDAP.Enabled {
+			d.provider = auth.NewLDAP(cfg.LDAP)
+		}
+	}
+	if !reflect.DeepEqual(cur.OIDC, cfg.OIDC) {
+		d.oidc = nil
+		if cfg.OIDC.Enabled {
+			d.oidc = auth.NewOIDC(cfg.OIDC)
+		}
+	}
+	if cur.RateLimit != cfg.RateLimit {
+		d.rateLimits = newRateLimiters(cfg.RateLimit)
+	}
+	d.ipRules = rules
+	d.cfg.Reload(cfg)
+	d.reloadMtx.Unlock()
+
+	logr.Reload(cfg)
+	d.logger.Info("configuration reloaded", "path", cfg.ConfigPath())
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditConfigReload})
+
+	return nil
+}
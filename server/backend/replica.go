@@ -0,0 +1,147 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// replicaFetchTimeout is the maximum time of the sync of a repository.
+const replicaFetchTimeout = 10 * time.Minute
+
+// RunReplica syncs the repositories of a replica with the primary every
+// cfg.Replica.SyncInterval seconds until ctx is done.
+func (d *Backend) RunReplica(ctx context.Context) {
+	if !d.cfg.Replica.Enabled {
+		return
+	}
+
+	interval := time.Duration(atLeastOne(d.cfg.Replica.SyncInterval)) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := d.SyncReplica(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Error("error syncing replica", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncReplica syncs each repository of the replica with the primary. The
+// repositories are listed from the database shared with the primary.
+func (d *Backend) SyncReplica(ctx context.Context) error {
+	repos, err := d.Repositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range repos {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := d.SyncReplicaRepository(ctx, r.Name()); err != nil {
+			d.logger.Error("error syncing repository", "repo", r.Name(), "err", err)
+		}
+	}
+
+	return nil
+}
+
+// SyncReplicaRepository fetches the references of a repository from the
+// primary, creating the local copy of the repository when it's missing.
+func (d *Backend) SyncReplicaRepository(ctx context.Context, name string) error {
+	name = utils.SanitizeRepo(name)
+	unlock, err := d.repos.Lock(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, replicaFetchTimeout)
+	defer cancel()
+
+	rp := d.repos.Path(name)
+	if _, err := os.Stat(rp); errors.Is(err, fs.ErrNotExist) {
+		if _, err := git.Init(rp, true); err != nil {
+			return fmt.Errorf("init repository: %w", err)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	remote := d.cfg.Replica.Primary + "/" + name + ".git"
+	envs := []string{}
+	if token := d.cfg.Replica.Token; token != "" {
+		// The token is passed through the environment so that it doesn't
+		// show up in the process list.
+		envs = append(envs,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Token "+token,
+		)
+	}
+
+	cmd := git.NewCommand("fetch", "--prune", "--force", "--no-tags", remote, "+refs/*:refs/*").WithContext(ctx)
+	cmd.AddEnvs(envs...)
+	if _, err := cmd.RunInDir(rp); err != nil {
+		return fmt.Errorf("git fetch: %w", err)
+	}
+
+	// Follow the default branch of the primary.
+	cmd = git.NewCommand("ls-remote", "--symref", remote, "HEAD").WithContext(ctx)
+	cmd.AddEnvs(envs...)
+	out, err := cmd.RunInDir(rp)
+	if err != nil {
+		return fmt.Errorf("git ls-remote: %w", err)
+	}
+	if head, ok := parseSymref(string(out)); ok {
+		if _, err := git.NewCommand("symbolic-ref", "HEAD", head).WithContext(ctx).RunInDir(rp); err != nil {
+			return fmt.Errorf("git symbolic-ref: %w", err)
+		}
+	}
+
+	d.InvalidateCache(ctx, name)
+	if err := populateLastModified(ctx, d, name); err != nil {
+		d.logger.Debug("error populating last-modified", "repo", name, "err", err)
+	}
+
+	return nil
+}
+
+// ReplicaPushed syncs a repository in the background after a push to it was
+// proxied to the primary, so that it can be fetched from the replica right
+// away.
+func (d *Backend) ReplicaPushed(name string) {
+	go func() {
+		if err := d.SyncReplicaRepository(d.ctx, name); err != nil {
+			d.logger.Error("error syncing repository", "repo", name, "err", err)
+		}
+	}()
+}
+
+// parseSymref returns the reference HEAD points to in the output of
+// "git ls-remote --symref".
+func parseSymref(out string) (string, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		ref, name, ok := strings.Cut(line, "\t")
+		if !ok || name != "HEAD" || !strings.HasPrefix(ref, "ref: ") {
+			continue
+		}
+
+		return strings.TrimPrefix(ref, "ref: "), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,15 @@
+package backend
+
+import "testing"
+
+func TestParseSymref(t *testing.T) {
+	out := "ref: refs/heads/main\tHEAD\n8e37968e0d0bfb6e8e5d3c8b4dbd4de1e0f37e2a\tHEAD\n"
+	if ref, ok := parseSymref(out); !ok || ref != "refs/heads/main" {
+		t.Errorf("parseSymref() = %q, %t", ref, ok)
+	}
+
+	// Empty repositories don't have a HEAD.
+	if ref, ok := parseSymref(""); ok {
+		t.Errorf("parseSymref() = %q, %t", ref, ok)
+	}
+}
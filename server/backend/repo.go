@@ -15,6 +15,7 @@ import (
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/events"
 	"github.com/charmbracelet/soft-serve/server/hooks"
 	"github.com/charmbracelet/soft-serve/server/lfs"
 	"github.com/charmbracelet/soft-serve/server/proto"
@@ -23,21 +24,42 @@ import (
 	"github.com/charmbracelet/soft-serve/server/utils"
 )
 
-func (d *Backend) reposPath() string {
-	return filepath.Join(d.cfg.DataPath, "repos")
-}
-
-// CreateRepository creates a new repository.
+// CreateRepository creates a new repository. If opts has initial files, they
+// are committed to the default branch.
 //
 // It implements backend.Backend.
 func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.User, opts proto.RepositoryOptions) (proto.Repository, error) {
+	files, err := initialFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := d.createRepository(ctx, name, user, opts)
+	if err != nil || len(files) == 0 {
+		return r, err
+	}
+
+	return d.initRepository(ctx, r.Name(), user, opts, files, nil)
+}
+
+// createRepository creates a new empty repository.
+func (d *Backend) createRepository(ctx context.Context, name string, user proto.User, opts proto.RepositoryOptions) (proto.Repository, error) {
 	name = utils.SanitizeRepo(name)
 	if err := utils.ValidateRepo(name); err != nil {
 		return nil, err
 	}
 
-	repo := name + ".git"
-	rp := filepath.Join(d.reposPath(), repo)
+	if err := d.CheckMaintenance(ctx, name); err != nil {
+		return nil, err
+	}
+
+	unlock, err := d.repos.Lock(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	rp := d.repos.Path(name)
 
 	var userID int64
 	if user != nil {
@@ -77,7 +99,7 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 			}
 		}
 
-		return hooks.GenerateHooks(ctx, d.cfg, repo)
+		return hooks.GenerateHooks(ctx, rp)
 	}); err != nil {
 		d.logger.Debug("failed to create repository in database", "err", err)
 		err = db.WrapError(err)
@@ -88,26 +110,38 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 		return nil, err
 	}
 
+	event := proto.AuditEvent{Action: proto.AuditRepoCreate, Repo: name}
+	if user != nil {
+		event.Username = user.Username()
+	}
+	d.Audit(ctx, event)
+	d.PublishEvent(ctx, events.Event{Type: events.RepoCreate, Repo: name, Username: event.Username, Public: !opts.Private})
+
 	return d.Repository(ctx, name)
 }
 
 // ImportRepository imports a repository from remote.
 // XXX: This a expensive operation and should be run in a goroutine.
-func (d *Backend) ImportRepository(_ context.Context, name string, user proto.User, remote string, opts proto.RepositoryOptions) (proto.Repository, error) {
+func (d *Backend) ImportRepository(ctx context.Context, name string, user proto.User, remote string, opts proto.RepositoryOptions) (proto.Repository, error) {
 	name = utils.SanitizeRepo(name)
 	if err := utils.ValidateRepo(name); err != nil {
 		return nil, err
 	}
 
-	repo := name + ".git"
-	rp := filepath.Join(d.reposPath(), repo)
+	if err := d.CheckMaintenance(ctx, name); err != nil {
+		return nil, err
+	}
+
+	rp := d.repos.Path(name)
 
 	tid := "import:" + name
 	if d.manager.Exists(tid) {
 		return nil, task.ErrAlreadyStarted
 	}
 
-	if _, err := os.Stat(rp); err == nil || os.IsExist(err) {
+	if exists, err := d.repos.Exists(name); err != nil {
+		return nil, err
+	} else if exists {
 		return nil, proto.ErrRepoExist
 	}
 
@@ -134,14 +168,14 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 		if err := git.Clone(remote, rp, copts); err != nil {
 			d.logger.Error("failed to clone repository", "err", err, "mirror", opts.Mirror, "remote", remote, "path", rp)
 			// Cleanup the mess!
-			if rerr := os.RemoveAll(rp); rerr != nil {
+			if rerr := d.repos.Remove(name); rerr != nil {
 				err = errors.Join(err, rerr)
 			}
 
 			return err
 		}
 
-		r, err := d.CreateRepository(ctx, name, user, opts)
+		r, err := d.createRepository(ctx, name, user, opts)
 		if err != nil {
 			d.logger.Error("failed to create repository", "err", err, "name", name)
 			return err
@@ -197,6 +231,10 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 			return err
 		}
 
+		if _, err := d.UpdateRepoDiskUsage(ctx, name); err != nil {
+			d.logger.Error("failed to update disk usage", "err", err, "name", name)
+		}
+
 		return nil
 	})
 
@@ -205,7 +243,13 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 		d.manager.Run(tid, done)
 	}()
 
-	return <-repoc, <-done
+	r, err := <-repoc, <-done
+	if err == nil {
+		d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoImport, Repo: name, Details: remote})
+		d.PublishEvent(ctx, events.Event{Type: events.RepoImport, Repo: name, Public: !opts.Private})
+	}
+
+	return r, err
 }
 
 // DeleteRepository deletes a repository.
@@ -213,28 +257,43 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 // It implements backend.Backend.
 func (d *Backend) DeleteRepository(ctx context.Context, name string) error {
 	name = utils.SanitizeRepo(name)
-	repo := name + ".git"
-	rp := filepath.Join(d.reposPath(), repo)
+	if err := d.CheckMaintenance(ctx, name); err != nil {
+		return err
+	}
+
+	unlock, err := d.repos.Lock(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	var public bool
+	err = d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		// Delete repo from cache
 		defer d.cache.Delete(name)
+		defer d.InvalidateCache(ctx, name)
 
 		repom, dberr := d.store.GetRepoByName(ctx, tx, name)
-		_, ferr := os.Stat(rp)
-		if dberr != nil && ferr != nil {
+		if dberr == nil {
+			public = !repom.Private && !repom.Internal
+		}
+		exists, ferr := d.repos.Exists(name)
+		if ferr != nil {
+			return ferr
+		}
+		if dberr != nil && !exists {
 			return proto.ErrRepoNotFound
 		}
 
 		// If the repo is not in the database but the directory exists, remove it
-		if dberr != nil && ferr == nil {
-			return os.RemoveAll(rp)
+		if dberr != nil && exists {
+			return d.repos.Remove(name)
 		} else if dberr != nil {
 			return db.WrapError(dberr)
 		}
 
 		repoID := strconv.FormatInt(repom.ID, 10)
-		strg := storage.NewLocalStorage(filepath.Join(d.cfg.DataPath, "lfs", repoID))
+		strg := storage.NewLFSStorage(d.cfg, repoID)
 		objs, err := d.store.GetLFSObjectsByName(ctx, tx, name)
 		if err != nil {
 			return db.WrapError(err)
@@ -256,12 +315,21 @@ func (d *Backend) DeleteRepository(ctx context.Context, name string) error {
 			return db.WrapError(err)
 		}
 
-		return os.RemoveAll(rp)
+		if err := os.RemoveAll(d.releasesPath(repom.ID)); err != nil {
+			d.logger.Error("failed to remove release assets", "repo", name, "err", err)
+		}
+
+		return d.repos.Remove(name)
 	})
 	if errors.Is(err, db.ErrRecordNotFound) {
 		return proto.ErrRepoNotFound
 	}
 
+	if err == nil {
+		d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoDelete, Repo: name})
+		d.PublishEvent(ctx, events.Event{Type: events.RepoDelete, Repo: name, Public: public})
+	}
+
 	return err
 }
 
@@ -301,40 +369,64 @@ func (d *Backend) RenameRepository(ctx context.Context, oldName string, newName
 	if err := utils.ValidateRepo(newName); err != nil {
 		return err
 	}
-	oldRepo := oldName + ".git"
-	newRepo := newName + ".git"
-	op := filepath.Join(d.reposPath(), oldRepo)
-	np := filepath.Join(d.reposPath(), newRepo)
-	if _, err := os.Stat(op); err != nil {
+
+	if err := d.CheckMaintenance(ctx, oldName); err != nil {
+		return err
+	}
+
+	// Lock the repositories in the same order to avoid deadlocks.
+	names := []string{oldName, newName}
+	switch {
+	case oldName == newName:
+		names = names[:1]
+	case newName < oldName:
+		names[0], names[1] = newName, oldName
+	}
+	for _, n := range names {
+		unlock, err := d.repos.Lock(ctx, n)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	if exists, err := d.repos.Exists(oldName); err != nil || !exists {
 		return proto.ErrRepoNotFound
 	}
 
-	if _, err := os.Stat(np); err == nil {
+	if exists, err := d.repos.Exists(newName); err != nil {
+		return err
+	} else if exists {
 		return proto.ErrRepoExist
 	}
 
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		// Delete cache
 		defer d.cache.Delete(oldName)
+		defer d.InvalidateCache(ctx, oldName)
 
 		if err := d.store.SetRepoNameByName(ctx, tx, oldName, newName); err != nil {
 			return err
 		}
 
-		// Make sure the new repository parent directory exists.
-		if err := os.MkdirAll(filepath.Dir(np), os.ModePerm); err != nil {
-			return err
-		}
-
-		return os.Rename(op, np)
+		return d.repos.Rename(oldName, newName)
 	}); err != nil {
 		return db.WrapError(err)
 	}
 
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoRename, Repo: newName, Details: oldName})
+	d.PublishEvent(ctx, events.Event{Type: events.RepoRename, Repo: newName, From: oldName})
+
 	return nil
 }
 
-// Repositories returns a list of repositories per page.
+// RepoPath returns the path of a repository in the repository storage, where
+// git runs.
+func (d *Backend) RepoPath(name string) string {
+	return d.repos.Path(utils.SanitizeRepo(name))
+}
+
+// Repositories returns all repositories, in the order they were created.
 //
 // It implements backend.Backend.
 func (d *Backend) Repositories(ctx context.Context) ([]proto.Repository, error) {
@@ -349,7 +441,7 @@ func (d *Backend) Repositories(ctx context.Context) ([]proto.Repository, error)
 		for _, m := range ms {
 			r := &repo{
 				name: m.Name,
-				path: filepath.Join(d.reposPath(), m.Name+".git"),
+				path: d.repos.Path(m.Name),
 				repo: m,
 			}
 
@@ -378,9 +470,9 @@ func (d *Backend) Repository(ctx context.Context, name string) (proto.Repository
 		return r, nil
 	}
 
-	rp := filepath.Join(d.reposPath(), name+".git")
-	if _, err := os.Stat(rp); err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
+	rp := d.repos.Path(name)
+	if exists, err := d.repos.Exists(name); err != nil || !exists {
+		if err != nil {
 			d.logger.Errorf("failed to stat repository path: %v", err)
 		}
 		return nil, proto.ErrRepoNotFound
@@ -512,7 +604,7 @@ func (d *Backend) SetHidden(ctx context.Context, name string, hidden bool) error
 // It implements backend.Backend.
 func (d *Backend) SetDescription(ctx context.Context, name string, desc string) error {
 	name = utils.SanitizeRepo(name)
-	rp := filepath.Join(d.reposPath(), name+".git")
+	rp := d.repos.Path(name)
 
 	// Delete cache
 	d.cache.Delete(name)
@@ -527,20 +619,42 @@ func (d *Backend) SetDescription(ctx context.Context, name string, desc string)
 	})
 }
 
-// SetPrivate sets the private flag of a repository.
+// SetPrivate sets the private flag of a repository. Making a repository
+// not private makes it public.
 //
 // It implements backend.Backend.
 func (d *Backend) SetPrivate(ctx context.Context, name string, private bool) error {
+	v := proto.VisibilityPublic
+	if private {
+		v = proto.VisibilityPrivate
+	}
+
+	return d.SetVisibility(ctx, name, v)
+}
+
+// Visibility returns the visibility of a repository.
+func (d *Backend) Visibility(ctx context.Context, name string) (proto.Visibility, error) {
+	r, err := d.Repository(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	return proto.RepositoryVisibility(r), nil
+}
+
+// SetVisibility sets the visibility of a repository. Only public
+// repositories are exported by the Git daemon.
+func (d *Backend) SetVisibility(ctx context.Context, name string, v proto.Visibility) error {
 	name = utils.SanitizeRepo(name)
-	rp := filepath.Join(d.reposPath(), name+".git")
+	rp := d.repos.Path(name)
 
 	// Delete cache
 	d.cache.Delete(name)
 
-	return db.WrapError(
+	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 			fp := filepath.Join(rp, "git-daemon-export-ok")
-			if !private {
+			if v == proto.VisibilityPublic {
 				if err := os.WriteFile(fp, []byte{}, fs.ModePerm); err != nil {
 					d.logger.Error("failed to write git-daemon-export-ok", "repo", name, "err", err)
 					return err
@@ -554,9 +668,19 @@ func (d *Backend) SetPrivate(ctx context.Context, name string, private bool) err
 				}
 			}
 
-			return d.store.SetRepoIsPrivateByName(ctx, tx, name, private)
+			if err := d.store.SetRepoIsPrivateByName(ctx, tx, name, v == proto.VisibilityPrivate); err != nil {
+				return err
+			}
+
+			return d.store.SetRepoIsInternalByName(ctx, tx, name, v == proto.VisibilityInternal)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoVisibility, Repo: name, Details: string(v)})
+
+	return nil
 }
 
 // SetProjectName sets the project name of a repository.
@@ -623,6 +747,13 @@ func (r *repo) IsPrivate() bool {
 	return r.repo.Private
 }
 
+// IsInternal returns whether the repository is internal.
+//
+// It implements backend.Repository.
+func (r *repo) IsInternal() bool {
+	return r.repo.Internal
+}
+
 // Name returns the repository's name.
 //
 // It implements backend.Repository.
@@ -651,6 +782,20 @@ func (r *repo) IsHidden() bool {
 	return r.repo.Hidden
 }
 
+// IsTemplate returns whether the repository is a template.
+//
+// It implements backend.Repository.
+func (r *repo) IsTemplate() bool {
+	return r.repo.Template
+}
+
+// IsArchived returns whether the repository is archived.
+//
+// It implements backend.Repository.
+func (r *repo) IsArchived() bool {
+	return r.repo.Archived
+}
+
 // UpdatedAt returns the repository's last update time.
 func (r *repo) UpdatedAt() time.Time {
 	// Try to read the last modified time from the info directory.
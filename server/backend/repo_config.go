@@ -0,0 +1,296 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFile is the file of the default branch holding the repository
+// configuration.
+const RepoConfigFile = ".soft-serve.yaml"
+
+// maxRepoConfigSize is the maximum size of the repository configuration file.
+const maxRepoConfigSize = 64 << 10
+
+// RepoConfig is the configuration of a repository versioned in its
+// RepoConfigFile. Missing fields leave the current settings untouched.
+type RepoConfig struct {
+	// Description is the description of the repository.
+	Description *string `yaml:"description"`
+	// ProjectName is the project name of the repository.
+	ProjectName *string `yaml:"project_name"`
+	// DefaultTab is the tab opened first when browsing the repository.
+	DefaultTab *string `yaml:"default_tab"`
+	// BranchProtections replace the branch protection rules of the
+	// repository.
+	BranchProtections []RepoConfigBranchProtection `yaml:"branch_protections"`
+	// Webhooks replace the webhook URLs of the repository.
+	Webhooks []string `yaml:"webhooks"`
+}
+
+// RepoConfigBranchProtection is a branch protection rule of a RepoConfig.
+type RepoConfigBranchProtection struct {
+	Pattern        string   `yaml:"pattern"`
+	AllowForcePush bool     `yaml:"allow_force_push"`
+	AllowDeletion  bool     `yaml:"allow_deletion"`
+	RequireSigned  bool     `yaml:"require_signed"`
+	PushUsers      []string `yaml:"push_users"`
+}
+
+// ParseRepoConfig parses a repository configuration file.
+func ParseRepoConfig(data []byte) (*RepoConfig, error) {
+	var rc RepoConfig
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if rc.DefaultTab != nil && *rc.DefaultTab != "" {
+		if _, err := proto.ParseTab(*rc.DefaultTab); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, bp := range rc.BranchProtections {
+		pattern := strings.TrimPrefix(bp.Pattern, git.RefsHeads)
+		if pattern == "" {
+			return nil, errors.New("branch protection without a pattern")
+		}
+		if seen[pattern] {
+			return nil, fmt.Errorf("duplicate branch protection: %q", pattern)
+		}
+		seen[pattern] = true
+	}
+
+	for _, u := range rc.Webhooks {
+		if err := validateWebhookURL(u); err != nil {
+			return nil, err
+		}
+	}
+	if len(rc.Webhooks) > maxWebhooks {
+		return nil, fmt.Errorf("too many webhooks, the maximum is %d", maxWebhooks)
+	}
+
+	return &rc, nil
+}
+
+// ApplyRepoConfig applies the RepoConfigFile of the default branch when a
+// push updates it. The branch protections and the webhooks are only applied
+// for repository admins. Problems are reported to the pusher on stderr.
+func (d *Backend) ApplyRepoConfig(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) {
+	repo = utils.SanitizeRepo(repo)
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		d.logger.Error("error opening repository", "repo", repo, "err", err)
+		return
+	}
+
+	head, err := rr.HEAD()
+	if err != nil {
+		return
+	}
+
+	var updated bool
+	for _, arg := range args {
+		if arg.RefName == head.Refspec && arg.NewSha != git.ZeroHash.String() {
+			updated = true
+		}
+	}
+	if !updated {
+		return
+	}
+
+	tree, err := rr.LsTree(head.Hash.String())
+	if err != nil {
+		d.logger.Error("error reading repository tree", "repo", repo, "err", err)
+		return
+	}
+
+	te, err := tree.TreeEntry(RepoConfigFile)
+	if err != nil || te.IsTree() {
+		// The repository isn't configured with a file.
+		return
+	}
+
+	if te.Size() > maxRepoConfigSize {
+		fmt.Fprintf(stderr, "Ignoring %s: larger than %d bytes\n", RepoConfigFile, maxRepoConfigSize) // nolint: errcheck
+		return
+	}
+
+	data, err := te.Contents()
+	if err != nil {
+		d.logger.Error("error reading repository config", "repo", repo, "err", err)
+		return
+	}
+
+	rc, err := ParseRepoConfig(data)
+	if err != nil {
+		fmt.Fprintf(stderr, "Ignoring %s: %v\n", RepoConfigFile, err) // nolint: errcheck
+		return
+	}
+
+	if err := d.applyRepoConfig(ctx, stderr, repo, rc); err != nil {
+		d.logger.Error("error applying repository config", "repo", repo, "err", err)
+		fmt.Fprintf(stderr, "Failed to apply %s: %v\n", RepoConfigFile, err) // nolint: errcheck
+	}
+}
+
+func (d *Backend) applyRepoConfig(ctx context.Context, stderr io.Writer, repo string, rc *RepoConfig) error {
+	if rc.Description != nil {
+		desc, err := d.Description(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if desc != *rc.Description {
+			if err := d.SetDescription(ctx, repo, *rc.Description); err != nil {
+				return err
+			}
+			fmt.Fprintf(stderr, "Updated the description from %s\n", RepoConfigFile) // nolint: errcheck
+		}
+	}
+
+	if rc.ProjectName != nil {
+		name, err := d.ProjectName(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if name != *rc.ProjectName {
+			if err := d.SetProjectName(ctx, repo, *rc.ProjectName); err != nil {
+				return err
+			}
+			fmt.Fprintf(stderr, "Updated the project name from %s\n", RepoConfigFile) // nolint: errcheck
+		}
+	}
+
+	if rc.DefaultTab != nil {
+		tab, _ := proto.ParseTab(*rc.DefaultTab)
+		l, err := d.TabLayout(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if l.Default != tab {
+			if err := d.SetDefaultTab(ctx, repo, tab); err != nil {
+				return err
+			}
+			fmt.Fprintf(stderr, "Updated the default tab from %s\n", RepoConfigFile) // nolint: errcheck
+		}
+	}
+
+	if rc.BranchProtections == nil && rc.Webhooks == nil {
+		return nil
+	}
+
+	user := proto.UserFromContext(ctx)
+	if !d.isPushAdmin(ctx, repo, user) {
+		fmt.Fprintf(stderr, "Ignoring the branch protections and webhooks of %s: only repository admins can change them\n", RepoConfigFile) // nolint: errcheck
+		return nil
+	}
+
+	if rc.BranchProtections != nil {
+		changed, err := d.applyBranchProtections(ctx, repo, rc.BranchProtections)
+		if err != nil {
+			return err
+		}
+		if changed {
+			fmt.Fprintf(stderr, "Updated the branch protections from %s\n", RepoConfigFile) // nolint: errcheck
+		}
+	}
+
+	if rc.Webhooks != nil {
+		urls, err := d.Webhooks(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if strings.Join(urls, "\n") != strings.Join(rc.Webhooks, "\n") {
+			if err := d.SetWebhooks(ctx, repo, rc.Webhooks); err != nil {
+				return err
+			}
+			fmt.Fprintf(stderr, "Updated the webhooks from %s\n", RepoConfigFile) // nolint: errcheck
+		}
+	}
+
+	return nil
+}
+
+// applyBranchProtections replaces the branch protection rules of a
+// repository, only the rules that differ are changed.
+func (d *Backend) applyBranchProtections(ctx context.Context, repo string, bps []RepoConfigBranchProtection) (bool, error) {
+	rules, err := d.BranchProtections(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+
+	current := make(map[string]proto.BranchProtection, len(rules))
+	for _, r := range rules {
+		current[r.Pattern] = r
+	}
+
+	var changed bool
+	wanted := make(map[string]bool, len(bps))
+	for _, bp := range bps {
+		pattern := strings.TrimPrefix(bp.Pattern, git.RefsHeads)
+		wanted[pattern] = true
+		opts := proto.BranchProtectionOptions{
+			AllowForcePush: bp.AllowForcePush,
+			AllowDeletion:  bp.AllowDeletion,
+			RequireSigned:  bp.RequireSigned,
+			PushUsers:      bp.PushUsers,
+		}
+		if r, ok := current[pattern]; ok && sameBranchProtection(r, opts) {
+			continue
+		}
+		if err := d.ProtectBranch(ctx, repo, pattern, opts); err != nil {
+			return changed, fmt.Errorf("branch protection %q: %w", pattern, err)
+		}
+		changed = true
+	}
+
+	for _, r := range rules {
+		if wanted[r.Pattern] {
+			continue
+		}
+		if err := d.UnprotectBranch(ctx, repo, r.Pattern); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+func sameBranchProtection(r proto.BranchProtection, opts proto.BranchProtectionOptions) bool {
+	if r.NoForcePush == opts.AllowForcePush || r.NoDeletion == opts.AllowDeletion ||
+		r.RequireSigned != opts.RequireSigned || r.RestrictPush != (len(opts.PushUsers) > 0) ||
+		len(r.PushUsers) != len(opts.PushUsers) {
+		return false
+	}
+
+	users := append([]string(nil), opts.PushUsers...)
+	current := append([]string(nil), r.PushUsers...)
+	sort.Strings(users)
+	sort.Strings(current)
+	for i := range users {
+		if users[i] != current[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestParseRepoConfig(t *testing.T) {
+	rc, err := ParseRepoConfig([]byte(`description: versioned
+default_tab: files
+branch_protections:
+  - pattern: refs/heads/main
+    allow_deletion: true
+    push_users: [user1]
+webhooks:
+  - https://example.com/hook
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Description == nil || *rc.Description != "versioned" {
+		t.Errorf("unexpected description: %v", rc.Description)
+	}
+	if rc.ProjectName != nil {
+		t.Errorf("expected no project name, got %q", *rc.ProjectName)
+	}
+	if len(rc.BranchProtections) != 1 || len(rc.Webhooks) != 1 {
+		t.Fatalf("unexpected config: %+v", rc)
+	}
+
+	// Empty lists remove all the rules, missing ones leave them untouched.
+	rc, err = ParseRepoConfig([]byte("branch_protections: []\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.BranchProtections == nil || rc.Webhooks != nil {
+		t.Errorf("unexpected config: %+v", rc)
+	}
+
+	if _, err := ParseRepoConfig(nil); err != nil {
+		t.Errorf("expected an empty file to be valid, got %v", err)
+	}
+
+	for _, data := range []string{
+		"default_tab: nope\n",
+		"unknown: true\n",
+		"branch_protections:\n  - allow_deletion: true\n",
+		"branch_protections:\n  - pattern: main\n  - pattern: refs/heads/main\n",
+		"webhooks:\n  - ftp://example.com\n",
+		"webhooks:\n  - https://\n",
+	} {
+		if _, err := ParseRepoConfig([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func TestSameBranchProtection(t *testing.T) {
+	r := proto.BranchProtection{
+		Pattern:      "main",
+		NoForcePush:  true,
+		RestrictPush: true,
+		PushUsers:    []string{"a", "b"},
+	}
+	if !sameBranchProtection(r, proto.BranchProtectionOptions{AllowDeletion: true, PushUsers: []string{"b", "a"}}) {
+		t.Error("expected the rules to be the same")
+	}
+	if sameBranchProtection(r, proto.BranchProtectionOptions{AllowDeletion: true, PushUsers: []string{"a"}}) {
+		t.Error("expected the push users to differ")
+	}
+	if sameBranchProtection(r, proto.BranchProtectionOptions{AllowForcePush: true, AllowDeletion: true, PushUsers: []string{"a", "b"}}) {
+		t.Error("expected the force push option to differ")
+	}
+}
+
+func TestApplyRepoConfigAdmin(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	admin, err := be.User(ctx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collab, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepository(ctx, "repo1", admin, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.AddCollaborator(ctx, "repo1", "user1", access.ReadWriteAccess); err != nil {
+		t.Fatal(err)
+	}
+	rc := &RepoConfig{BranchProtections: []RepoConfigBranchProtection{{Pattern: "main"}}}
+
+	// A collaborator that claims to push with admin access can't change the
+	// branch protections.
+	var stderr bytes.Buffer
+	cctx := access.WithContext(proto.WithUserContext(ctx, collab), access.AdminAccess)
+	if err := be.applyRepoConfig(cctx, &stderr, "repo1", rc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "only repository admins can change them") {
+		t.Errorf("expected the branch protections to be ignored, got %q", stderr.String())
+	}
+	if rules, err := be.BranchProtections(ctx, "repo1"); err != nil || len(rules) != 0 {
+		t.Fatalf("expected no branch protections, got %v (%v)", rules, err)
+	}
+
+	stderr.Reset()
+	if err := be.applyRepoConfig(proto.WithUserContext(ctx, admin), &stderr, "repo1", rc); err != nil {
+		t.Fatal(err)
+	}
+	if rules, err := be.BranchProtections(ctx, "repo1"); err != nil || len(rules) != 1 {
+		t.Fatalf("expected a branch protection, got %v (%v)", rules, err)
+	}
+}
@@ -0,0 +1,317 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// repoHooksDir is the directory of the hook scripts in a repository. It's
+// outside of the hooks directory, as the scripts run through RunRepoHook.
+const repoHooksDir = "custom_hooks"
+
+// RepoHookNames are the names of the hooks a repository can have scripts
+// for.
+var RepoHookNames = []string{
+	hooks.PreReceiveHook,
+	hooks.UpdateHook,
+	hooks.PostReceiveHook,
+	hooks.PostUpdateHook,
+}
+
+// RepoHooks returns the hook scripts of a repository, by name.
+func (d *Backend) RepoHooks(ctx context.Context, repo string) ([]proto.RepoHook, error) {
+	dir, err := d.repoHooksPath(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	hks := make([]proto.RepoHook, 0)
+	for _, name := range RepoHookNames {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		hks = append(hks, proto.RepoHook{Name: name, Size: fi.Size(), UpdatedAt: fi.ModTime()})
+	}
+	sort.Slice(hks, func(i, j int) bool { return hks[i].Name < hks[j].Name })
+
+	return hks, nil
+}
+
+// SetRepoHook installs the script of a hook of a repository, replacing the
+// previous one.
+func (d *Backend) SetRepoHook(ctx context.Context, repo string, hook string, script []byte) error {
+	if err := checkRepoHookName(hook); err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(script)) == 0 {
+		return errors.New("empty hook script")
+	}
+
+	dir, err := d.repoHooksPath(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// Write the script next to the previous one so that a running push never
+	// sees half of it.
+	f, err := os.CreateTemp(dir, "."+hook+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+	if _, err := f.Write(script); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o755); err != nil { //nolint:gosec
+		return err
+	}
+	if err := os.Rename(f.Name(), filepath.Join(dir, hook)); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoHookSet, Repo: utils.SanitizeRepo(repo), Target: hook})
+	return nil
+}
+
+// RemoveRepoHook removes the script of a hook of a repository.
+func (d *Backend) RemoveRepoHook(ctx context.Context, repo string, hook string) error {
+	if err := checkRepoHookName(hook); err != nil {
+		return err
+	}
+
+	dir, err := d.repoHooksPath(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, hook)); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", proto.ErrRepoHookNotFound, hook)
+	} else if err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoHookRemove, Repo: utils.SanitizeRepo(repo), Target: hook})
+	return nil
+}
+
+// RepoHookRuns returns the limit most recent runs of the hook scripts of a
+// repository in chronological order, all the logged ones if limit is 0.
+func (d *Backend) RepoHookRuns(ctx context.Context, repo string, limit int) ([]proto.RepoHookRun, error) {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	var ms []models.RepoHookRun
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetRepoHookRunsByRepo(ctx, tx, repo, limit)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	runs := make([]proto.RepoHookRun, 0, len(ms))
+	for _, m := range ms {
+		runs = append(runs, proto.RepoHookRun{
+			Hook:      m.Hook,
+			Username:  m.Username,
+			ExitCode:  m.ExitCode,
+			Duration:  time.Duration(m.Duration) * time.Millisecond,
+			Output:    m.Output,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+
+	return runs, nil
+}
+
+// RunRepoHook runs the script of a hook of a repository, if it has one, and
+// logs the run. The output of the script is written to w. It returns an error
+// if the script fails, which rejects the push for a pre-receive or an update
+// hook.
+//
+// The script runs in an empty temporary directory, with a minimal
+// environment, and is killed after the timeout of the configuration. Its CPU
+// time and virtual memory are limited with ulimit.
+func (d *Backend) RunRepoHook(ctx context.Context, repo string, hook string, stdin io.Reader, w io.Writer, args ...string) error {
+	repo = utils.SanitizeRepo(repo)
+	if err := checkRepoHookName(hook); err != nil {
+		return err
+	}
+
+	dir, err := d.repoHooksPath(ctx, repo)
+	if err != nil {
+		return err
+	}
+	script := filepath.Join(dir, hook)
+	if _, err := os.Stat(script); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	wd, err := os.MkdirTemp("", "soft-serve-hook-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(wd) // nolint: errcheck
+
+	timeout := time.Duration(atLeastOne(d.cfg.RepoHooks.Timeout)) * time.Second
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The shell applies the limits, and is replaced by the script. Scripts
+	// without a shebang run in the shell.
+	limits := "ulimit -t " + strconv.Itoa(atLeastOne(d.cfg.RepoHooks.Timeout))
+	if kb := d.cfg.RepoHooks.MaxMemory / 1024; kb > 0 {
+		limits += "; ulimit -v " + strconv.FormatInt(kb, 10)
+	}
+	cmd := exec.CommandContext(runCtx, "sh", append([]string{"-c", limits + `; exec "$0" "$@"`, script}, args...)...)
+	cmd.Dir = wd
+	cmd.Env = d.repoHookEnv(ctx, repo, hook, wd)
+	cmd.Stdin = stdin
+	cmd.WaitDelay = time.Second
+
+	out := &cappedBuffer{max: d.cfg.RepoHooks.MaxOutput}
+	cmd.Stdout = io.MultiWriter(w, out)
+	cmd.Stderr = cmd.Stdout
+
+	start := time.Now()
+	runErr := cmd.Run()
+	run := models.RepoHookRun{
+		Hook:      hook,
+		Duration:  time.Since(start).Milliseconds(),
+		Output:    out.String(),
+		CreatedAt: time.Now(),
+	}
+	if runErr != nil {
+		run.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) && exitErr.ExitCode() >= 0 {
+			run.ExitCode = exitErr.ExitCode()
+		}
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			runErr = fmt.Errorf("timed out after %s", timeout)
+		}
+		run.Output += fmt.Sprintf("\n%s hook failed: %v\n", hook, runErr)
+	}
+	if user := proto.UserFromContext(ctx); user != nil {
+		run.Username = user.Username()
+	}
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateRepoHookRun(ctx, tx, repo, run, d.cfg.RepoHooks.Logs)
+	})); err != nil {
+		d.logger.Error("failed to log hook run", "repo", repo, "hook", hook, "err", err)
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("%s hook failed: %w", hook, runErr)
+	}
+
+	return nil
+}
+
+// repoHookEnv returns the environment of the hook scripts of a repository:
+// the git variables of the push, i.e. the quarantine directory of the pushed
+// objects, and the name of the repository and of the user.
+func (d *Backend) repoHookEnv(ctx context.Context, repo string, hook string, wd string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + wd,
+		"TMPDIR=" + wd,
+	}
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "GIT_") && !strings.HasPrefix(e, "GIT_DIR=") {
+			env = append(env, e)
+		}
+	}
+
+	var username string
+	if user := proto.UserFromContext(ctx); user != nil {
+		username = user.Username()
+	}
+
+	return append(env,
+		"GIT_DIR="+d.RepoPath(repo),
+		"SOFT_SERVE_REPO_NAME="+repo,
+		"SOFT_SERVE_USERNAME="+username,
+		"SOFT_SERVE_HOOK="+hook,
+	)
+}
+
+// repoHooksPath returns the directory of the hook scripts of a repository.
+func (d *Backend) repoHooksPath(ctx context.Context, repo string) (string, error) {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(d.RepoPath(repo), repoHooksDir), nil
+}
+
+// checkRepoHookName returns an error if a repository can't have a script for
+// the hook.
+func checkRepoHookName(hook string) error {
+	for _, name := range RepoHookNames {
+		if hook == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported hook %q, use one of %s", hook, strings.Join(RepoHookNames, ", "))
+}
+
+// cappedBuffer keeps the first max bytes written to it, and drops the rest.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Write implements io.Writer. It never fails, so that the writers of a
+// MultiWriter after it still get the whole output.
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if left := b.max - b.buf.Len(); left < len(p) {
+		b.truncated = true
+		if left > 0 {
+			b.buf.Write(p[:left])
+		}
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// String returns the bytes kept, with a note if some were dropped.
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n[output truncated]\n"
+	}
+	return b.buf.String()
+}
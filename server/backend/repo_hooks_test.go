@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestRunRepoHook(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.RepoHooks.Timeout = 1
+	cfg.RepoHooks.MaxOutput = 64
+	cfg.RepoHooks.Logs = 3
+	ctx, be := newTestBackend(t, cfg)
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepository(ctx, "repo1", user, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx = proto.WithUserContext(ctx, user)
+
+	// Repositories without a script don't run anything.
+	if err := be.RunRepoHook(ctx, "repo1", "pre-receive", strings.NewReader(""), &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetRepoHook(ctx, "repo1", "pre-push", []byte("exit 0\n")); err == nil {
+		t.Fatal("expected an error for an unsupported hook")
+	}
+	if err := be.SetRepoHook(ctx, "repo1", "update", []byte("  \n")); err == nil {
+		t.Fatal("expected an error for an empty script")
+	}
+
+	// Scripts get the input of the hook, and the repository in their
+	// environment.
+	script := "#!/bin/sh\nread old new ref\necho \"$SOFT_SERVE_REPO_NAME $SOFT_SERVE_USERNAME $ref $1\"\n"
+	if err := be.SetRepoHook(ctx, "repo1", "pre-receive", []byte(script)); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := be.RunRepoHook(ctx, "repo1", "pre-receive", strings.NewReader("a b refs/heads/main\n"), &out, "arg"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "repo1 user1 refs/heads/main arg\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	// Failing scripts return an error, and the output of the log is capped.
+	if err := be.SetRepoHook(ctx, "repo1", "update", []byte("printf '%0100d' 0\nexit 3\n")); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := be.RunRepoHook(ctx, "repo1", "update", nil, &out); err == nil {
+		t.Fatal("expected an error for a failing script")
+	}
+	if out.Len() != 100 {
+		t.Errorf("output length = %d, want 100", out.Len())
+	}
+
+	// Scripts are killed after the timeout.
+	if err := be.SetRepoHook(ctx, "repo1", "post-receive", []byte("sleep 5\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.RunRepoHook(ctx, "repo1", "post-receive", nil, &out); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	hks, err := be.RepoHooks(ctx, "repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, h := range hks {
+		names = append(names, h.Name)
+	}
+	if got, want := strings.Join(names, " "), "post-receive pre-receive update"; got != want {
+		t.Errorf("hooks = %q, want %q", got, want)
+	}
+
+	// Only the most recent runs are logged.
+	if err := be.RunRepoHook(ctx, "repo1", "pre-receive", strings.NewReader("a b refs/heads/dev\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := be.RepoHookRuns(ctx, "repo1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("runs = %d, want 3", len(runs))
+	}
+	if runs[0].Hook != "update" || runs[0].ExitCode != 3 || runs[0].Username != "user1" {
+		t.Errorf("unexpected run: %+v", runs[0])
+	}
+	if !strings.Contains(runs[0].Output, "[output truncated]") {
+		t.Errorf("expected a truncated output, got %q", runs[0].Output)
+	}
+	if runs[1].Hook != "post-receive" || runs[1].ExitCode != -1 || !strings.Contains(runs[1].Output, "timed out") {
+		t.Errorf("unexpected run: %+v", runs[1])
+	}
+	if runs[2].Hook != "pre-receive" || runs[2].ExitCode != 0 || !strings.Contains(runs[2].Output, "refs/heads/dev") {
+		t.Errorf("unexpected run: %+v", runs[2])
+	}
+
+	if err := be.RemoveRepoHook(ctx, "repo1", "update"); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.RemoveRepoHook(ctx, "repo1", "update"); !errors.Is(err, proto.ErrRepoHookNotFound) {
+		t.Fatalf("expected ErrRepoHookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/initfiles"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// initialFiles returns the initial files of a repository created with opts,
+// by path.
+func initialFiles(opts proto.RepositoryOptions) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if opts.Readme {
+		files["README.md"] = []byte(initfiles.Readme)
+	}
+	if opts.Gitignore != "" {
+		content, err := initfiles.Gitignore(opts.Gitignore)
+		if err != nil {
+			return nil, err
+		}
+		files[".gitignore"] = content
+	}
+	if opts.License != "" {
+		content, err := initfiles.License(opts.License)
+		if err != nil {
+			return nil, err
+		}
+		files["LICENSE"] = content
+	}
+
+	return files, nil
+}
+
+// initRepository makes the initial commit of the new repository name, with
+// the files of the template revision tpl if any, and the initial files the
+// template doesn't have. The repository is deleted if it fails.
+func (d *Backend) initRepository(ctx context.Context, name string, user proto.User, opts proto.RepositoryOptions, files map[string][]byte, tpl *templateRev) (proto.Repository, error) {
+	author, email := d.initAuthor(ctx, user)
+	if err := d.commitInitialFiles(ctx, name, author, email, opts, files, tpl); err != nil {
+		if rerr := d.DeleteRepository(ctx, name); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+		return nil, err
+	}
+
+	d.InvalidateCache(ctx, name)
+	if err := populateLastModified(ctx, d, name); err != nil {
+		d.logger.Debug("error populating last-modified", "repo", name, "err", err)
+	}
+	if _, err := d.UpdateRepoDiskUsage(ctx, name); err != nil {
+		d.logger.Error("error updating disk usage", "repo", name, "err", err)
+	}
+
+	return d.Repository(ctx, name)
+}
+
+// commitInitialFiles commits the initial files of a repository in a fresh
+// history, on the default branch of the template if any, or on the default
+// branch of the repository. The files are staged in a temporary work tree
+// and index, the repository is bare.
+func (d *Backend) commitInitialFiles(ctx context.Context, name, author, email string, opts proto.RepositoryOptions, files map[string][]byte, tpl *templateRev) error {
+	rp := d.repos.Path(name)
+	rep := templateReplacer(name, author, opts)
+
+	tmp, err := os.MkdirTemp("", "soft-serve-init-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+
+	work := filepath.Join(tmp, "tree")
+	if err := os.MkdirAll(work, os.ModePerm); err != nil {
+		return err
+	}
+
+	msg := "Initial commit"
+	ref := ""
+	if tpl != nil {
+		if err := extractTemplate(ctx, tpl, work, rep); err != nil {
+			return fmt.Errorf("copy template %s: %w", tpl.name, err)
+		}
+		msg = fmt.Sprintf("Initial commit from %s", tpl.name)
+		ref = "refs/heads/" + tpl.branch
+	} else {
+		out, err := git.NewCommand("symbolic-ref", "HEAD").WithContext(ctx).RunInDir(rp)
+		if err != nil {
+			return fmt.Errorf("git symbolic-ref: %w", err)
+		}
+		ref = strings.TrimSpace(string(out))
+	}
+
+	for p, content := range files {
+		fp := filepath.Join(work, p)
+		if _, err := os.Lstat(fp); err == nil {
+			// Keep the file of the template.
+			continue
+		}
+		if err := os.WriteFile(fp, []byte(rep.Replace(string(content))), 0o644); err != nil {
+			return err
+		}
+	}
+
+	index := "GIT_INDEX_FILE=" + filepath.Join(tmp, "index")
+	if _, err := git.NewCommand("--work-tree="+work, "add", "--all").WithContext(ctx).
+		AddEnvs(index).RunInDir(rp); err != nil {
+		return fmt.Errorf("git add: %w", err)
+	}
+	out, err := git.NewCommand("write-tree").WithContext(ctx).AddEnvs(index).RunInDir(rp)
+	if err != nil {
+		return fmt.Errorf("git write-tree: %w", err)
+	}
+
+	out, err = git.NewCommand("commit-tree", strings.TrimSpace(string(out)), "-m", msg).WithContext(ctx).
+		AddEnvs(
+			"GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+email,
+			"GIT_COMMITTER_NAME="+author, "GIT_COMMITTER_EMAIL="+email,
+		).RunInDir(rp)
+	if err != nil {
+		return fmt.Errorf("git commit-tree: %w", err)
+	}
+
+	if _, err := git.NewCommand("update-ref", ref, strings.TrimSpace(string(out))).WithContext(ctx).RunInDir(rp); err != nil {
+		return fmt.Errorf("git update-ref: %w", err)
+	}
+	if _, err := git.NewCommand("symbolic-ref", "HEAD", ref).WithContext(ctx).RunInDir(rp); err != nil {
+		return fmt.Errorf("git symbolic-ref: %w", err)
+	}
+
+	return nil
+}
+
+// initAuthor returns the name and the email address the initial commit of a
+// repository is authored with.
+func (d *Backend) initAuthor(ctx context.Context, user proto.User) (string, string) {
+	host := "localhost"
+	if u, err := url.Parse(d.cfg.SSH.PublicURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	if user == nil {
+		return d.cfg.Current().Name, "soft-serve@" + host
+	}
+
+	email, _ := d.Email(ctx, user)
+	if email == "" {
+		email = user.Username() + "@" + host
+	}
+
+	return user.Username(), email
+}
@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/initfiles"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestCreateRepositoryWithInitialFiles(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx, be := newTestBackend(t, cfg)
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := be.CreateRepository(ctx, "repo1", user, proto.RepositoryOptions{License: "nope"}); !errors.Is(err, initfiles.ErrLicenseNotFound) {
+		t.Fatalf("expected ErrLicenseNotFound, got %v", err)
+	}
+	if _, err := be.Repository(ctx, "repo1"); !errors.Is(err, proto.ErrRepoNotFound) {
+		t.Fatalf("expected ErrRepoNotFound, got %v", err)
+	}
+
+	r, err := be.CreateRepository(ctx, "repo1", user, proto.RepositoryOptions{
+		Description: "The first repo",
+		Readme:      true,
+		Gitignore:   "go",
+		License:     "MIT",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rp := be.repos.Path(r.Name())
+	out, err := git.NewCommand("log", "--format=%an %s", "HEAD").RunInDir(rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "user1 Initial commit" {
+		t.Fatalf("unexpected history %q", got)
+	}
+
+	gitignore, _ := initfiles.Gitignore("Go")
+	for p, want := range map[string]string{
+		"README.md":  "# repo1\n\nThe first repo\n",
+		".gitignore": string(gitignore),
+		"LICENSE":    fmt.Sprintf("Copyright (c) %d user1", time.Now().Year()),
+	} {
+		out, err := git.NewCommand("show", "HEAD:"+p).RunInDir(rp)
+		if err != nil {
+			t.Errorf("git show %s: %v", p, err)
+		} else if !strings.Contains(string(out), want) {
+			t.Errorf("unexpected %s %q", p, out)
+		}
+	}
+
+	// Repositories without initial files are empty.
+	r, err = be.CreateRepository(ctx, "repo2", user, proto.RepositoryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git.NewCommand("rev-parse", "--verify", "HEAD").RunInDir(be.repos.Path(r.Name())); err == nil {
+		t.Fatal("expected an empty repository")
+	}
+}
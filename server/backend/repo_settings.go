@@ -0,0 +1,142 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// Repository setting keys.
+const (
+	defaultTabSetting = "default-tab"
+	hiddenTabsSetting = "hidden-tabs"
+	goModuleSetting   = "go-module"
+)
+
+// RepoSetting returns the value of a repository setting. It returns an empty
+// string if the setting is not set.
+func (d *Backend) RepoSetting(ctx context.Context, repo string, key string) (string, error) {
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return "", err
+	}
+
+	var value string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		value, err = d.store.GetRepoSetting(ctx, tx, repo, key)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return value, nil
+}
+
+// SetRepoSetting sets the value of a repository setting. An empty value
+// removes the setting.
+func (d *Backend) SetRepoSetting(ctx context.Context, repo string, key string, value string) error {
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if value == "" {
+				return d.store.DeleteRepoSetting(ctx, tx, repo, key)
+			}
+			return d.store.SetRepoSetting(ctx, tx, repo, key, value)
+		}),
+	)
+}
+
+// TabLayout returns which tabs of the repository are shown in the TUI and
+// which one is opened first. Invalid stored values are ignored.
+func (d *Backend) TabLayout(ctx context.Context, repo string) (proto.TabLayout, error) {
+	var l proto.TabLayout
+	v, err := d.RepoSetting(ctx, repo, defaultTabSetting)
+	if err != nil {
+		return l, err
+	}
+
+	if v != "" {
+		if t, err := proto.ParseTab(v); err == nil {
+			l.Default = t
+		} else {
+			d.logger.Error("invalid repo setting", "repo", repo, "key", defaultTabSetting, "err", err)
+		}
+	}
+
+	v, err = d.RepoSetting(ctx, repo, hiddenTabsSetting)
+	if err != nil {
+		return l, err
+	}
+
+	if v != "" {
+		for _, s := range strings.Split(v, ",") {
+			t, err := proto.ParseTab(s)
+			if err != nil || t == proto.TabSettings {
+				d.logger.Error("invalid repo setting", "repo", repo, "key", hiddenTabsSetting, "tab", s)
+				continue
+			}
+			l.Hidden = append(l.Hidden, t)
+		}
+	}
+
+	return l, nil
+}
+
+// SetDefaultTab sets the tab opened first when browsing the repository. An
+// empty tab opens the first shown tab.
+func (d *Backend) SetDefaultTab(ctx context.Context, repo string, tab proto.Tab) error {
+	if tab != "" {
+		l, err := d.TabLayout(ctx, repo)
+		if err != nil {
+			return err
+		}
+
+		if l.IsHidden(tab) {
+			return fmt.Errorf("tab %q is hidden", tab)
+		}
+	}
+
+	return d.SetRepoSetting(ctx, repo, defaultTabSetting, string(tab))
+}
+
+// SetHiddenTabs sets the tabs that aren't shown when browsing the repository.
+// The settings tab and the default tab can't be hidden.
+func (d *Backend) SetHiddenTabs(ctx context.Context, repo string, tabs []proto.Tab) error {
+	l, err := d.TabLayout(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(tabs))
+	seen := make(map[proto.Tab]bool)
+	for _, t := range proto.Tabs {
+		for _, h := range tabs {
+			if h != t || seen[t] {
+				continue
+			}
+
+			switch t {
+			case proto.TabSettings:
+				return fmt.Errorf("tab %q can't be hidden", t)
+			case l.Default:
+				return fmt.Errorf("tab %q is the default tab", t)
+			}
+
+			seen[t] = true
+			names = append(names, string(t))
+		}
+	}
+
+	return d.SetRepoSetting(ctx, repo, hiddenTabsSetting, strings.Join(names, ","))
+}
@@ -0,0 +1,173 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// repoLargeBlobs is the number of the largest blobs recorded per repository.
+const repoLargeBlobs = 10
+
+// SizeRepository computes the disk usage of a repository like
+// UpdateRepoDiskUsage, and records its largest blobs too. Listing the blobs
+// goes through all the objects of the repository, it's run by the scheduled
+// sizing job rather than after each push.
+func (d *Backend) SizeRepository(ctx context.Context, repo string) (proto.DiskUsage, error) {
+	usage, err := d.UpdateRepoDiskUsage(ctx, repo)
+	if err != nil {
+		return usage, err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return usage, err
+	}
+
+	blobs, err := largestBlobs(ctx, d.repos.Path(r.Name()), repoLargeBlobs)
+	if err != nil {
+		return usage, err
+	}
+
+	ms := make([]models.RepoLargeBlob, 0, len(blobs))
+	for _, b := range blobs {
+		ms = append(ms, models.RepoLargeBlob{Oid: b.Oid, Path: b.Path, Size: b.Size})
+	}
+
+	return usage, db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetRepoLargeBlobs(ctx, tx, r.ID(), ms)
+	}))
+}
+
+// RepoLargeBlobs returns the largest blobs of a repository, as of the last
+// time it was sized.
+func (d *Backend) RepoLargeBlobs(ctx context.Context, repo string) ([]proto.LargeBlob, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []models.RepoLargeBlob
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetRepoLargeBlobs(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	blobs := make([]proto.LargeBlob, 0, len(ms))
+	for _, m := range ms {
+		blobs = append(blobs, proto.LargeBlob{Oid: m.Oid, Path: m.Path, Size: m.Size})
+	}
+
+	return blobs, nil
+}
+
+// countObjects returns the number of objects of a git repository, and the
+// size of its packfiles.
+func countObjects(ctx context.Context, rp string) (count int64, packSize int64, err error) {
+	out, err := git.NewCommand("count-objects", "-v").WithContext(ctx).RunInDir(rp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("git count-objects: %w", err)
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		k, v, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch k {
+		case "count", "in-pack":
+			count += n
+		case "size-pack":
+			// In KiB.
+			packSize = n * 1024
+		}
+	}
+
+	return count, packSize, nil
+}
+
+// largestBlobs returns the n largest blobs of a git repository, with a path
+// they're found at in the history of its references.
+func largestBlobs(ctx context.Context, rp string, n int) ([]proto.LargeBlob, error) {
+	out, err := git.NewCommand("cat-file", "--batch-all-objects", "--batch-check=%(objecttype) %(objectname) %(objectsize)").
+		WithContext(ctx).RunInDir(rp)
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+
+	blobs := parseBlobSizes(out)
+	sort.SliceStable(blobs, func(i, j int) bool {
+		return blobs[i].Size > blobs[j].Size
+	})
+	if len(blobs) > n {
+		blobs = blobs[:n]
+	}
+	if len(blobs) == 0 {
+		return blobs, nil
+	}
+
+	out, err = git.NewCommand("rev-list", "--objects", "--all").WithContext(ctx).RunInDir(rp)
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list: %w", err)
+	}
+
+	paths := make(map[string]string, len(blobs))
+	for _, b := range blobs {
+		paths[b.Oid] = ""
+	}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		oid, path, ok := strings.Cut(s.Text(), " ")
+		if p, found := paths[oid]; ok && found && p == "" {
+			paths[oid] = path
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range blobs {
+		blobs[i].Path = paths[blobs[i].Oid]
+	}
+
+	return blobs, nil
+}
+
+// parseBlobSizes parses the blobs in the output of
+// "git cat-file --batch-check='%(objecttype) %(objectname) %(objectsize)'".
+func parseBlobSizes(out []byte) []proto.LargeBlob {
+	var blobs []proto.LargeBlob
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "blob" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		blobs = append(blobs, proto.LargeBlob{Oid: fields[1], Size: size})
+	}
+
+	return blobs
+}
@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestParseBlobSizes(t *testing.T) {
+	out := "commit 1111 200\nblob 2222 30\ntree 3333 40\nblob 4444 5\n\n"
+	blobs := parseBlobSizes([]byte(out))
+	if len(blobs) != 2 {
+		t.Fatalf("expected 2 blobs, got %+v", blobs)
+	}
+	if blobs[0] != (proto.LargeBlob{Oid: "2222", Size: 30}) || blobs[1] != (proto.LargeBlob{Oid: "4444", Size: 5}) {
+		t.Fatalf("unexpected blobs %+v", blobs)
+	}
+}
+
+func TestSizeRepository(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx, be := newTestBackend(t, cfg)
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepository(ctx, "repo1", user, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	wd := t.TempDir()
+	if err := os.WriteFile(filepath.Join(wd, "small.txt"), []byte("small"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wd, "big.txt"), []byte(strings.Repeat("big", 1000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "first"},
+		{"push", "-q", be.repos.Path("repo1"), "HEAD:refs/heads/main"},
+	} {
+		if _, err := git.NewCommand(args...).RunInDir(wd); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	usage, err := be.SizeRepository(ctx, "repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A commit, a tree, and two blobs.
+	if usage.ObjectCount != 4 {
+		t.Fatalf("expected 4 objects, got %d", usage.ObjectCount)
+	}
+
+	blobs, err := be.RepoLargeBlobs(ctx, "repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 2 || blobs[0].Path != "big.txt" || blobs[0].Size != 3000 || blobs[1].Path != "small.txt" {
+		t.Fatalf("unexpected blobs %+v", blobs)
+	}
+}
@@ -0,0 +1,241 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/dustin/go-humanize"
+)
+
+// repoTransfer is a repository transfer checked by checkRepoTransfer.
+type repoTransfer struct {
+	from proto.User
+	to   proto.User
+	// name is the name of the repository after the transfer.
+	name string
+}
+
+// RepoTransfer returns the pending transfer of a repository.
+func (d *Backend) RepoTransfer(ctx context.Context, repo string) (proto.RepoTransfer, error) {
+	repo = utils.SanitizeRepo(repo)
+	var m models.RepoTransfer
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetRepoTransferByRepo(ctx, tx, repo)
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.RepoTransfer{}, proto.ErrTransferNotFound
+		}
+		return proto.RepoTransfer{}, err
+	}
+
+	return d.newRepoTransfer(ctx, m), nil
+}
+
+// RepoTransfers returns the pending repository transfers, in the order they
+// were requested.
+func (d *Backend) RepoTransfers(ctx context.Context) ([]proto.RepoTransfer, error) {
+	var ms []models.RepoTransfer
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.ListRepoTransfers(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	ts := make([]proto.RepoTransfer, 0, len(ms))
+	for _, m := range ms {
+		ts = append(ts, d.newRepoTransfer(ctx, m))
+	}
+
+	return ts, nil
+}
+
+// RequestRepoTransfer requests the transfer of a repository to the user
+// username, who becomes its owner once they accept it with
+// TransferRepository. It replaces the pending transfer of the repository, if
+// any.
+func (d *Backend) RequestRepoTransfer(ctx context.Context, repo string, username string) (proto.RepoTransfer, error) {
+	repo = utils.SanitizeRepo(repo)
+	t, err := d.checkRepoTransfer(ctx, repo, username)
+	if err != nil {
+		return proto.RepoTransfer{}, err
+	}
+
+	var by int64
+	if user := proto.UserFromContext(ctx); user != nil {
+		by = user.ID()
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CreateRepoTransfer(ctx, tx, repo, t.to.ID(), by)
+		}),
+	); err != nil {
+		return proto.RepoTransfer{}, err
+	}
+
+	return d.RepoTransfer(ctx, repo)
+}
+
+// DeleteRepoTransfer deletes the pending transfer of a repository.
+func (d *Backend) DeleteRepoTransfer(ctx context.Context, repo string) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.RepoTransfer(ctx, repo); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeleteRepoTransferByRepo(ctx, tx, repo)
+		}),
+	)
+}
+
+// TransferRepository makes the user username the owner of a repository, and
+// returns its new name. A repository in the namespace of its owner, i.e.
+// alice/app, moves to the namespace of the new owner, bob/app. The new owner
+// is removed from the collaborators, the previous owner becomes a read-write
+// collaborator, and the pending transfer is deleted.
+func (d *Backend) TransferRepository(ctx context.Context, repo string, username string) (string, error) {
+	repo = utils.SanitizeRepo(repo)
+	t, err := d.checkRepoTransfer(ctx, repo, username)
+	if err != nil {
+		return "", err
+	}
+
+	if t.name != repo {
+		if err := d.RenameRepository(ctx, repo, t.name); err != nil {
+			return "", err
+		}
+	} else if err := d.CheckMaintenance(ctx, repo); err != nil {
+		return "", err
+	}
+
+	// Delete cache
+	d.cache.Delete(t.name)
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if err := d.store.SetRepoUserIDByName(ctx, tx, t.name, t.to.ID()); err != nil {
+				return err
+			}
+
+			if err := d.store.RemoveCollabByUsernameAndRepo(ctx, tx, t.to.Username(), t.name); err != nil {
+				return err
+			}
+
+			if t.from != nil {
+				if _, err := d.store.GetCollabByUsernameAndRepo(ctx, tx, t.from.Username(), t.name); errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+					if err := d.store.AddCollabByUsernameAndRepo(ctx, tx, t.from.Username(), t.name, access.ReadWriteAccess); err != nil {
+						return err
+					}
+				} else if err != nil {
+					return err
+				}
+			}
+
+			return d.store.DeleteRepoTransferByRepo(ctx, tx, t.name)
+		}),
+	); err != nil {
+		return "", err
+	}
+
+	var from string
+	if t.from != nil {
+		from = t.from.Username()
+	}
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditRepoTransfer, Repo: t.name, Target: t.to.Username(), Details: from})
+	d.logger.Info("transferred repository", "repo", repo, "name", t.name, "from", from, "to", t.to.Username())
+
+	return t.name, nil
+}
+
+// checkRepoTransfer returns an error if the user username can't become the
+// owner of a repository: it already owns it, it's suspended, the repository
+// wouldn't fit in its disk quota, or its namespace already has a repository
+// with the same name.
+func (d *Backend) checkRepoTransfer(ctx context.Context, repo string, username string) (repoTransfer, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return repoTransfer{}, err
+	}
+
+	to, err := d.User(ctx, username)
+	if err != nil {
+		return repoTransfer{}, err
+	}
+	if to.IsSuspended() {
+		return repoTransfer{}, proto.ErrUserSuspended
+	}
+	if r.UserID() == to.ID() {
+		return repoTransfer{}, fmt.Errorf("%s already owns %s", to.Username(), repo)
+	}
+
+	t := repoTransfer{to: to, name: repo}
+	if r.UserID() > 0 {
+		t.from, err = d.UserByID(ctx, r.UserID())
+		if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+			return repoTransfer{}, err
+		}
+	}
+	if t.from != nil {
+		if rest, ok := strings.CutPrefix(repo, t.from.Username()+"/"); ok {
+			t.name = to.Username() + "/" + rest
+		}
+	}
+	if t.name != repo {
+		if _, err := d.Repository(ctx, t.name); err == nil {
+			return repoTransfer{}, fmt.Errorf("%w: %s", proto.ErrRepoExist, t.name)
+		}
+	}
+
+	quota, err := d.UserDiskQuota(ctx, to)
+	if err != nil || quota <= 0 {
+		return t, err
+	}
+	usage, err := d.RepoDiskUsage(ctx, repo)
+	if err != nil {
+		return repoTransfer{}, err
+	}
+	used, err := d.UserDiskUsage(ctx, to)
+	if err != nil {
+		return repoTransfer{}, err
+	}
+	if used+usage.Total() > quota {
+		return repoTransfer{}, fmt.Errorf("%w: the repositories of %s use %s of their %s quota, %s more don't fit",
+			proto.ErrQuotaExceeded, to.Username(), humanize.IBytes(uint64(used)), humanize.IBytes(uint64(quota)), humanize.IBytes(uint64(usage.Total())))
+	}
+
+	return t, nil
+}
+
+// newRepoTransfer returns the transfer of a database model, with the
+// usernames of its users.
+func (d *Backend) newRepoTransfer(ctx context.Context, m models.RepoTransfer) proto.RepoTransfer {
+	t := proto.RepoTransfer{Repo: m.Repo, CreatedAt: m.CreatedAt}
+	if u, err := d.UserByID(ctx, m.UserID); err == nil {
+		t.To = u.Username()
+	}
+	if m.RequestedBy.Valid {
+		if u, err := d.UserByID(ctx, m.RequestedBy.Int64); err == nil {
+			t.RequestedBy = u.Username()
+		}
+	}
+	if r, err := d.Repository(ctx, m.Repo); err == nil && r.UserID() > 0 {
+		if u, err := d.UserByID(ctx, r.UserID()); err == nil {
+			t.From = u.Username()
+		}
+	}
+
+	return t
+}
@@ -2,9 +2,11 @@ package backend
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
 )
 
 // AllowKeyless returns whether or not keyless access is allowed.
@@ -27,9 +29,15 @@ func (b *Backend) AllowKeyless(ctx context.Context) bool {
 //
 // It implements backend.Backend.
 func (b *Backend) SetAllowKeyless(ctx context.Context, allow bool) error {
-	return b.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		return b.store.SetAllowKeylessAccess(ctx, tx, allow)
-	})
+	}); err != nil {
+		return err
+	}
+
+	b.Audit(ctx, proto.AuditEvent{Action: proto.AuditSettingsAllowKeyless, Details: strconv.FormatBool(allow)})
+
+	return nil
 }
 
 // AnonAccess returns the level of anonymous access.
@@ -52,7 +60,13 @@ func (b *Backend) AnonAccess(ctx context.Context) access.AccessLevel {
 //
 // It implements backend.Backend.
 func (b *Backend) SetAnonAccess(ctx context.Context, level access.AccessLevel) error {
-	return b.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		return b.store.SetAnonAccess(ctx, tx, level)
-	})
+	}); err != nil {
+		return err
+	}
+
+	b.Audit(ctx, proto.AuditEvent{Action: proto.AuditSettingsAnonAccess, Details: level.String()})
+
+	return nil
 }
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// StartedAt returns when the backend was created, i.e. when the server
+// started.
+func (d *Backend) StartedAt() time.Time {
+	return d.startedAt
+}
+
+// ServerStats returns the statistics of the server: the number of
+// repositories and users, the disk usage of the repositories, and the depth
+// of the job queue.
+func (d *Backend) ServerStats(ctx context.Context) (proto.ServerStats, error) {
+	var stats proto.ServerStats
+	repos, err := d.Repositories(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stats.Repos = len(repos)
+	for _, r := range repos {
+		if r.IsMirror() {
+			stats.Mirrors++
+		}
+		if r.IsPrivate() {
+			stats.Private++
+		}
+		if r.IsArchived() {
+			stats.Archived++
+		}
+	}
+
+	users, err := d.Users(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stats.Users = len(users)
+
+	usages, err := d.RepoDiskUsages(ctx)
+	if err != nil {
+		return stats, err
+	}
+	for _, u := range usages {
+		stats.DiskUsage.Objects += u.Objects
+		stats.DiskUsage.LFS += u.LFS
+		stats.DiskUsage.ObjectCount += u.ObjectCount
+		stats.DiskUsage.PackSize += u.PackSize
+		if u.UpdatedAt.After(stats.DiskUsage.UpdatedAt) {
+			stats.DiskUsage.UpdatedAt = u.UpdatedAt
+		}
+	}
+
+	for status, n := range map[proto.JobStatus]*int{
+		proto.JobPending: &stats.JobsPending,
+		proto.JobRunning: &stats.JobsRunning,
+		proto.JobDead:    &stats.JobsDead,
+	} {
+		jobs, err := d.Jobs(ctx, status)
+		if err != nil {
+			return stats, err
+		}
+		*n = len(jobs)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/server/cron"
+)
+
+// ErrTasksNotScheduled is returned when running a scheduled task on a
+// replica, the primary runs them.
+var ErrTasksNotScheduled = errors.New("this server is a read-only replica, scheduled tasks run on the primary")
+
+// SetScheduler sets the scheduler running the tasks of the server.
+func (d *Backend) SetScheduler(s *cron.Scheduler) {
+	d.sched = s
+}
+
+// ScheduledTasks returns the state of the scheduled tasks, the mirror
+// updates, the LFS pruning, the repository sizing, and the user sync, sorted
+// by name.
+func (d *Backend) ScheduledTasks() []cron.Task {
+	if d.sched == nil {
+		return nil
+	}
+
+	return d.sched.Tasks()
+}
+
+// RunScheduledTask runs a scheduled task now, and waits for it to finish. It
+// returns the error of the task.
+func (d *Backend) RunScheduledTask(name string) error {
+	if d.sched == nil {
+		return ErrTasksNotScheduled
+	}
+
+	return d.sched.Run(name)
+}
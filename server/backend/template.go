@@ -0,0 +1,186 @@
+package backend
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// IsTemplate returns true if the repository is a template.
+func (d *Backend) IsTemplate(ctx context.Context, name string) (bool, error) {
+	name = utils.SanitizeRepo(name)
+	var template bool
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		template, err = d.store.GetRepoIsTemplateByName(ctx, tx, name)
+		return err
+	}); err != nil {
+		return false, db.WrapError(err)
+	}
+
+	return template, nil
+}
+
+// SetTemplate sets whether new repositories can be created from the
+// repository.
+func (d *Backend) SetTemplate(ctx context.Context, name string, template bool) error {
+	name = utils.SanitizeRepo(name)
+
+	// Delete cache
+	d.cache.Delete(name)
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetRepoIsTemplateByName(ctx, tx, name, template)
+	}))
+}
+
+// CreateRepositoryFromTemplate creates the repository name with the files of
+// the default branch of the template repository, and the initial files of
+// opts the template doesn't have, in a single commit authored by user. The
+// placeholders of the file names and of the text files are replaced, see
+// templateReplacer.
+func (d *Backend) CreateRepositoryFromTemplate(ctx context.Context, name string, template string, user proto.User, opts proto.RepositoryOptions) (proto.Repository, error) {
+	tr, err := d.Repository(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	if !tr.IsTemplate() {
+		return nil, fmt.Errorf("%w: %s", proto.ErrNotTemplate, tr.Name())
+	}
+
+	tpl, err := tr.Open()
+	if err != nil {
+		return nil, err
+	}
+	head, err := tpl.HEAD()
+	if err != nil {
+		return nil, fmt.Errorf("template %s is empty", tr.Name())
+	}
+
+	files, err := initialFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := d.createRepository(ctx, name, user, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.initRepository(ctx, r.Name(), user, opts, files, &templateRev{
+		name:   tr.Name(),
+		path:   tpl.Path,
+		rev:    head.ID,
+		branch: head.Name().Short(),
+	})
+}
+
+// templateRev is the revision of a template a repository is created from.
+type templateRev struct {
+	name   string
+	path   string
+	rev    string
+	branch string
+}
+
+// templateReplacer returns the replacer of the placeholders of a template
+// for the repository name:
+//
+//	{{REPO_NAME}}     the name of the repository, without its namespace
+//	{{REPO_PATH}}     the full name of the repository
+//	{{PROJECT_NAME}}  the project name, or the name of the repository
+//	{{DESCRIPTION}}   the description of the repository
+//	{{OWNER}}         the user creating the repository
+//	{{YEAR}}          the current year
+func templateReplacer(name string, owner string, opts proto.RepositoryOptions) *strings.Replacer {
+	base := path.Base(name)
+	project := opts.ProjectName
+	if project == "" {
+		project = base
+	}
+
+	return strings.NewReplacer(
+		"{{REPO_NAME}}", base,
+		"{{REPO_PATH}}", name,
+		"{{PROJECT_NAME}}", project,
+		"{{DESCRIPTION}}", opts.Description,
+		"{{OWNER}}", owner,
+		"{{YEAR}}", strconv.Itoa(time.Now().Year()),
+	)
+}
+
+// extractTemplate extracts the files of the template revision to dir. The
+// files marked export-ignore in the template aren't extracted.
+func extractTemplate(ctx context.Context, tpl *templateRev, dir string, rep *strings.Replacer) error {
+	var archive, stderr bytes.Buffer
+	if err := git.NewCommand("archive", "--format=tar", tpl.rev).WithContext(ctx).
+		RunInDirPipeline(&archive, &stderr, tpl.path); err != nil {
+		return fmt.Errorf("git archive: %w - %s", err, stderr.String())
+	}
+
+	return extractArchive(&archive, dir, rep)
+}
+
+// extractArchive extracts the tar archive of a template to dir, replacing
+// the placeholders of the file names, of the symbolic link targets, and of
+// the text files.
+func extractArchive(r io.Reader, dir string, rep *strings.Replacer) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(rep.Replace(hdr.Name))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid path %q", hdr.Name)
+		}
+		p := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Symlink(rep.Replace(hdr.Linkname), p); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if bin, _ := git.IsBinary(bytes.NewReader(content)); !bin {
+				content = []byte(rep.Replace(string(content)))
+			}
+			if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.WriteFile(p, content, os.FileMode(hdr.Mode)&os.ModePerm); err != nil {
+				return err
+			}
+		}
+	}
+}
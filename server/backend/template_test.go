@@ -0,0 +1,114 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestCreateRepositoryFromTemplate(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx, be := newTestBackend(t, cfg)
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepository(ctx, "tpl", user, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	wd := t.TempDir()
+	files := map[string]string{
+		"README.md":                 "# {{PROJECT_NAME}}\n\n{{DESCRIPTION}}\n",
+		"cmd/{{REPO_NAME}}/main.go": "package main // {{REPO_PATH}} by {{OWNER}}\n",
+		"logo.bin":                  "\x00{{REPO_NAME}}",
+		"secret.txt":                "not copied\n",
+		".gitattributes":            "secret.txt export-ignore\n",
+	}
+	for p, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(wd, p)), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(wd, p), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "first"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "second"},
+		{"push", "-q", be.repos.Path("tpl"), "HEAD:refs/heads/main"},
+	} {
+		if _, err := git.NewCommand(args...).RunInDir(wd); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	if _, err := git.NewCommand("symbolic-ref", "HEAD", "refs/heads/main").RunInDir(be.repos.Path("tpl")); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := proto.RepositoryOptions{ProjectName: "My App", Description: "An app"}
+	if _, err := be.CreateRepositoryFromTemplate(ctx, "team/app", "tpl", user, opts); !errors.Is(err, proto.ErrNotTemplate) {
+		t.Fatalf("expected ErrNotTemplate, got %v", err)
+	}
+
+	if err := be.SetTemplate(ctx, "tpl", true); err != nil {
+		t.Fatal(err)
+	}
+	r, err := be.CreateRepositoryFromTemplate(ctx, "team/app", "tpl", user, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rp := be.repos.Path(r.Name())
+	out, err := git.NewCommand("log", "--format=%an %s", "main").RunInDir(rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "user1 Initial commit from tpl" {
+		t.Fatalf("unexpected history %q", got)
+	}
+
+	for p, want := range map[string]string{
+		"README.md":         "# My App\n\nAn app\n",
+		"cmd/app/main.go":   "package main // team/app by user1\n",
+		"logo.bin":          "\x00{{REPO_NAME}}",
+		"secret.txt":        "",
+		".gitattributes":    "secret.txt export-ignore\n",
+		"cmd/{{REPO_NAME}}": "",
+	} {
+		out, err := git.NewCommand("show", "main:"+p).RunInDir(rp)
+		if want == "" {
+			if err == nil {
+				t.Errorf("expected %s not to be copied", p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("git show %s: %v", p, err)
+		} else if string(out) != want {
+			t.Errorf("unexpected %s %q", p, out)
+		}
+	}
+
+	// The repository is removed if the template is empty.
+	if _, err := be.CreateRepository(ctx, "empty", user, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetTemplate(ctx, "empty", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepositoryFromTemplate(ctx, "new", "empty", user, proto.RepositoryOptions{}); err == nil {
+		t.Fatal("expected an error creating a repository from an empty template")
+	}
+	if _, err := be.Repository(ctx, "new"); !errors.Is(err, proto.ErrRepoNotFound) {
+		t.Fatalf("expected ErrRepoNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/hooks"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/timestamp"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+// timestampTimeout is the maximum time to wait for the timestamp authority.
+const timestampTimeout = 30 * time.Second
+
+// TimestampTags requests timestamps for the pushed tags when a timestamp
+// authority is configured, and forgets the timestamps of deleted tags.
+// Failures are reported to the pusher but don't fail the push, the tags
+// are already updated.
+func (d *Backend) TimestampTags(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) {
+	repo = utils.SanitizeRepo(repo)
+	for _, arg := range args {
+		if !strings.HasPrefix(arg.RefName, git.RefsTags) {
+			continue
+		}
+
+		tag := strings.TrimPrefix(arg.RefName, git.RefsTags)
+		if arg.NewSha == git.ZeroHash.String() {
+			if err := d.DeleteTagTimestamp(ctx, repo, tag); err != nil {
+				d.logger.Error("error deleting tag timestamp", "repo", repo, "tag", tag, "err", err)
+			}
+			continue
+		}
+
+		if d.cfg.Timestamp.URL == "" {
+			continue
+		}
+
+		if err := d.timestampTag(ctx, repo, tag, arg.NewSha); err != nil {
+			d.logger.Error("error timestamping tag", "repo", repo, "tag", tag, "err", err)
+			fmt.Fprintf(stderr, "Failed to timestamp tag %s: %v\n", tag, err) // nolint: errcheck
+			continue
+		}
+
+		fmt.Fprintf(stderr, "Timestamped tag %s\n", tag) // nolint: errcheck
+	}
+}
+
+func (d *Backend) timestampTag(ctx context.Context, repo string, tag string, oid string) error {
+	digest, err := d.objectDigest(ctx, repo, oid)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timestampTimeout)
+	defer cancel()
+
+	token, err := timestamp.Request(ctx, http.DefaultClient, d.cfg.Timestamp.URL, digest)
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.UpsertTagTimestamp(ctx, tx, repo, tag, oid, token)
+		}),
+	)
+}
+
+// DeleteTagTimestamp deletes the timestamp of a tag.
+func (d *Backend) DeleteTagTimestamp(ctx context.Context, repo string, tag string) error {
+	repo = utils.SanitizeRepo(repo)
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeleteTagTimestampByRepoAndTag(ctx, tx, repo, tag)
+		}),
+	)
+}
+
+// VerifyTagTimestamp verifies the timestamp of a tag against the current
+// tag object. The timestamp authority certificates are checked against the
+// configured CA certificates, or the system roots.
+func (d *Backend) VerifyTagTimestamp(ctx context.Context, repo string, tag string) (proto.TagTimestamp, error) {
+	repo = utils.SanitizeRepo(repo)
+	rr, err := d.Repository(ctx, repo)
+	if err != nil {
+		return proto.TagTimestamp{}, err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return proto.TagTimestamp{}, err
+	}
+
+	var m models.TagTimestamp
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetTagTimestampByRepoAndTag(ctx, tx, repo, tag)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.TagTimestamp{}, proto.ErrTimestampNotFound
+		}
+		return proto.TagTimestamp{}, err
+	}
+
+	oid, err := r.RevParse(git.RefsTags + tag)
+	if err != nil {
+		return proto.TagTimestamp{}, err
+	}
+
+	if oid != m.ObjectID {
+		return proto.TagTimestamp{}, fmt.Errorf("%w: tag %s was moved after it was timestamped", timestamp.ErrMismatch, tag)
+	}
+
+	digest, err := d.objectDigest(ctx, repo, oid)
+	if err != nil {
+		return proto.TagTimestamp{}, err
+	}
+
+	ts, err := timestamp.Parse(m.Token)
+	if err != nil {
+		return proto.TagTimestamp{}, err
+	}
+
+	roots, err := d.timestampRoots()
+	if err != nil {
+		return proto.TagTimestamp{}, err
+	}
+
+	if err := ts.Verify(digest, roots); err != nil {
+		return proto.TagTimestamp{}, err
+	}
+
+	t := proto.TagTimestamp{
+		Tag:          tag,
+		ObjectID:     oid,
+		Time:         ts.Time,
+		SerialNumber: ts.SerialNumber,
+	}
+	if signer := ts.Signer(); signer != nil {
+		t.Authority = signer.Subject.String()
+	}
+
+	return t, nil
+}
+
+// objectDigest returns the SHA-256 digest of the raw content of a git
+// object. This is the content of the tag object for annotated tags, or the
+// commit for lightweight tags.
+func (d *Backend) objectDigest(ctx context.Context, repo string, oid string) ([]byte, error) {
+	rr, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := git.NewCommand("cat-file", "-t", oid).RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := git.NewCommand("cat-file", strings.TrimSpace(string(typ)), oid).RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(content)
+	return digest[:], nil
+}
+
+// timestampRoots returns the certificates trusted to verify timestamps, a
+// nil pool means the system roots.
+func (d *Backend) timestampRoots() (*x509.CertPool, error) {
+	if d.cfg.Timestamp.CACertPath == "" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(d.cfg.Timestamp.CACertPath)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", d.cfg.Timestamp.CACertPath)
+	}
+
+	return roots, nil
+}
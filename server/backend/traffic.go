@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"net"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+const trafficDayLayout = "2006-01-02"
+
+// RecordTraffic counts a clone, a fetch, or a push of a repository described
+// by req. The client is the user in the context, or their address when
+// anonymous. Requests that don't transfer objects, like ls-remote, aren't
+// counted.
+func (d *Backend) RecordTraffic(ctx context.Context, repo string, req git.Request) {
+	var clones, fetches, pushes int64
+	switch {
+	case req.IsClone():
+		clones = 1
+	case req.IsFetch():
+		fetches = 1
+	case req.IsPush():
+		pushes = 1
+	default:
+		return
+	}
+
+	client := remoteAddr(ctx)
+	if host, _, err := net.SplitHostPort(client); err == nil {
+		client = host
+	}
+	if user := proto.UserFromContext(ctx); user != nil {
+		client = "user:" + user.Username()
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	day := time.Now().UTC().Format(trafficDayLayout)
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var sketch clientSketch
+		m, err := d.store.GetRepoTrafficByDay(ctx, tx, repo, day)
+		if err == nil {
+			sketch = m.Clients
+		} else if !errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+			return err
+		}
+
+		sketch = sketch.add(client)
+		return d.store.AddRepoTraffic(ctx, tx, repo, day, clones, fetches, pushes, sketch)
+	}); err != nil {
+		d.logger.Error("error recording traffic", "repo", repo, "err", err)
+	}
+}
+
+// Traffic returns the traffic of a repository over the last days, today
+// included.
+func (d *Backend) Traffic(ctx context.Context, repo string, days int) (proto.Traffic, error) {
+	if days < 1 {
+		days = 1
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return proto.Traffic{}, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-days)
+	var ms []models.RepoTraffic
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetRepoTrafficSince(ctx, tx, repo, since.Format(trafficDayLayout))
+		return err
+	}); err != nil {
+		return proto.Traffic{}, db.WrapError(err)
+	}
+
+	var t proto.Traffic
+	var all clientSketch
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		td := proto.TrafficDay{Day: day}
+		for len(ms) > 0 && ms[0].Day <= day.Format(trafficDayLayout) {
+			m := ms[0]
+			ms = ms[1:]
+			if m.Day != day.Format(trafficDayLayout) {
+				continue
+			}
+
+			td.Clones, td.Fetches, td.Pushes = m.Clones, m.Fetches, m.Pushes
+			td.Clients = clientSketch(m.Clients).count()
+			all = all.merge(m.Clients)
+		}
+
+		t.Days = append(t.Days, td)
+		t.Clones += td.Clones
+		t.Fetches += td.Fetches
+		t.Pushes += td.Pushes
+	}
+	t.Clients = all.count()
+
+	return t, nil
+}
+
+// clientSketch is a HyperLogLog sketch of distinct clients. It takes 1 KiB
+// however many clients it counts, with a standard error of about 3%.
+type clientSketch []byte
+
+const (
+	sketchPrecision = 10
+	sketchSize      = 1 << sketchPrecision
+)
+
+// add returns the sketch with client added.
+func (s clientSketch) add(client string) clientSketch {
+	if len(s) != sketchSize {
+		s = make(clientSketch, sketchSize)
+	}
+
+	sum := sha256.Sum256([]byte(client))
+	h := binary.BigEndian.Uint64(sum[:8])
+	i := h >> (64 - sketchPrecision)
+	rank := byte(bits.LeadingZeros64(h<<sketchPrecision|1<<(sketchPrecision-1)) + 1)
+	if rank > s[i] {
+		s[i] = rank
+	}
+
+	return s
+}
+
+// merge returns the sketch of the clients of both sketches.
+func (s clientSketch) merge(o clientSketch) clientSketch {
+	if len(o) != sketchSize {
+		return s
+	}
+	if len(s) != sketchSize {
+		s = make(clientSketch, sketchSize)
+	}
+
+	for i := range s {
+		if o[i] > s[i] {
+			s[i] = o[i]
+		}
+	}
+
+	return s
+}
+
+// count returns the estimated number of clients in the sketch.
+func (s clientSketch) count() int64 {
+	if len(s) != sketchSize {
+		return 0
+	}
+
+	var sum float64
+	var zeros int
+	for _, r := range s {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	m := float64(sketchSize)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// Small cardinalities are better estimated by linear counting.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return int64(math.Round(estimate))
+}
@@ -0,0 +1,43 @@
+package backend
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestClientSketch(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 1000, 50000} {
+		var s clientSketch
+		for i := 0; i < n; i++ {
+			s = s.add(strconv.Itoa(i))
+			// Adding a client twice doesn't count it twice.
+			s = s.add(strconv.Itoa(i))
+		}
+
+		got := s.count()
+		if diff := math.Abs(float64(got) - float64(n)); diff > float64(n)*0.1 {
+			t.Errorf("expected about %d clients, got %d", n, got)
+		}
+	}
+}
+
+func TestClientSketchMerge(t *testing.T) {
+	var a, b clientSketch
+	for i := 0; i < 500; i++ {
+		a = a.add("a" + strconv.Itoa(i))
+		b = b.add("b" + strconv.Itoa(i))
+		// Clients of both days are counted once.
+		a = a.add(strconv.Itoa(i))
+		b = b.add(strconv.Itoa(i))
+	}
+
+	var all clientSketch
+	all = all.merge(a).merge(b).merge(nil)
+	if got := all.count(); math.Abs(float64(got)-1500) > 150 {
+		t.Errorf("expected about 1500 clients, got %d", got)
+	}
+	if got := a.count(); math.Abs(float64(got)-1000) > 100 {
+		t.Errorf("merging changed the merged sketch, got %d clients", got)
+	}
+}
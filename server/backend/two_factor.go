@@ -0,0 +1,160 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ratelimit"
+	"github.com/charmbracelet/soft-serve/server/totp"
+)
+
+// twoFactorFailureLimit is how many wrong two-factor codes a user can try, 5
+// in a row, then 1 per minute.
+var twoFactorFailureLimit = ratelimit.Limit{PerMinute: 1, Burst: 5}
+
+// User setting keys of two-factor authentication.
+const (
+	totpSetting        = "totp"
+	totpPendingSetting = "totp-pending"
+	totpStepSetting    = "totp-step"
+)
+
+// HasTwoFactor returns whether the user enrolled in two-factor
+// authentication.
+func (d *Backend) HasTwoFactor(ctx context.Context, user proto.User) bool {
+	secret, err := d.UserSetting(ctx, user, totpSetting)
+	if err != nil {
+		d.logger.Error("error getting two-factor secret", "err", err)
+	}
+	return secret != ""
+}
+
+// EnrollTwoFactor starts the two-factor enrollment of a user. It returns the
+// secret to add to an authenticator app, and its otpauth:// URL. The
+// enrollment is confirmed with a code of the app by ConfirmTwoFactor.
+func (d *Backend) EnrollTwoFactor(ctx context.Context, user proto.User) (string, string, error) {
+	if d.HasTwoFactor(ctx, user) {
+		return "", "", proto.ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := d.SetUserSetting(ctx, user, totpPendingSetting, secret); err != nil {
+		return "", "", err
+	}
+
+	issuer := d.cfg.Current().Name
+	if issuer == "" {
+		issuer = "Soft Serve"
+	}
+	return secret, totp.URL(issuer, user.Username(), secret), nil
+}
+
+// ConfirmTwoFactor confirms the two-factor enrollment of a user with a code
+// of their authenticator app. Two-factor codes are required by destructive
+// commands from then on.
+func (d *Backend) ConfirmTwoFactor(ctx context.Context, user proto.User, code string) error {
+	secret, err := d.UserSetting(ctx, user, totpPendingSetting)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return proto.ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return proto.ErrInvalidTwoFactorCode
+	}
+
+	for _, s := range []struct{ key, value string }{
+		{totpSetting, secret},
+		{totpStepSetting, strconv.FormatInt(step, 10)},
+		{totpPendingSetting, ""},
+	} {
+		if err := d.SetUserSetting(ctx, user, s.key, s.value); err != nil {
+			return err
+		}
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUser2FAEnable, Target: user.Username()})
+	return nil
+}
+
+// DisableTwoFactor disables the two-factor authentication of a user, with a
+// code of their authenticator app.
+func (d *Backend) DisableTwoFactor(ctx context.Context, user proto.User, code string) error {
+	if !d.HasTwoFactor(ctx, user) {
+		return proto.ErrTwoFactorNotEnrolled
+	}
+	if err := d.VerifyTwoFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	for _, key := range []string{totpSetting, totpStepSetting, totpPendingSetting} {
+		if err := d.SetUserSetting(ctx, user, key, ""); err != nil {
+			return err
+		}
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUser2FADisable, Target: user.Username()})
+	return nil
+}
+
+// VerifyTwoFactor checks the two-factor code of a user before a destructive
+// operation. It returns nil if the user didn't enroll in two-factor
+// authentication. Codes can only be used once. Wrong codes are recorded in
+// the audit log, and users trying too many get a *proto.RateLimitError.
+func (d *Backend) VerifyTwoFactor(ctx context.Context, user proto.User, code string) error {
+	if user == nil {
+		return nil
+	}
+
+	secret, err := d.UserSetting(ctx, user, totpSetting)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return nil
+	}
+	if code == "" {
+		return proto.ErrTwoFactorRequired
+	}
+
+	key := strconv.FormatInt(user.ID(), 10)
+	if wait := d.twoFactorFailures.Wait(key); wait > 0 {
+		return rateLimitError(wait)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return d.twoFactorFailed(ctx, user, key)
+	}
+
+	// Reject codes of the last step used, or of older steps.
+	last, err := d.UserSetting(ctx, user, totpStepSetting)
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.ParseInt(last, 10, 64); err == nil && step <= n {
+		return d.twoFactorFailed(ctx, user, key)
+	}
+
+	return d.SetUserSetting(ctx, user, totpStepSetting, strconv.FormatInt(step, 10))
+}
+
+// twoFactorFailed records a wrong two-factor code of user, and counts it
+// against the codes they can try.
+func (d *Backend) twoFactorFailed(ctx context.Context, user proto.User, key string) error {
+	d.twoFactorFailures.Allow(key)
+	d.Audit(ctx, proto.AuditEvent{
+		Action:   proto.AuditAuthFailure,
+		Username: user.Username(),
+		Details:  "2fa: " + proto.ErrInvalidTwoFactorCode.Error(),
+	})
+	return proto.ErrInvalidTwoFactorCode
+}
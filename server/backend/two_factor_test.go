@@ -0,0 +1,125 @@
+package backend
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/totp"
+)
+
+func TestTwoFactor(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Users that didn't enroll don't need codes.
+	if err := be.VerifyTwoFactor(ctx, user, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.ConfirmTwoFactor(ctx, user, "123456"); !errors.Is(err, proto.ErrTwoFactorNotEnrolled) {
+		t.Fatalf("expected %v, got %v", proto.ErrTwoFactorNotEnrolled, err)
+	}
+
+	secret, url, err := be.EnrollTwoFactor(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "otpauth://totp/") || !strings.Contains(url, ":user1?") {
+		t.Errorf("unexpected url %q", url)
+	}
+	if be.HasTwoFactor(ctx, user) {
+		t.Fatal("expected two-factor to be pending until confirmed")
+	}
+
+	code := func(offset int64) string {
+		c, err := totp.Code(secret, totp.Step(time.Now())+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if err := be.ConfirmTwoFactor(ctx, user, "abcdef"); !errors.Is(err, proto.ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected %v, got %v", proto.ErrInvalidTwoFactorCode, err)
+	}
+	if err := be.ConfirmTwoFactor(ctx, user, code(-1)); err != nil {
+		t.Fatal(err)
+	}
+	if !be.HasTwoFactor(ctx, user) {
+		t.Fatal("expected two-factor to be enabled")
+	}
+	if _, _, err := be.EnrollTwoFactor(ctx, user); !errors.Is(err, proto.ErrTwoFactorEnabled) {
+		t.Errorf("expected %v, got %v", proto.ErrTwoFactorEnabled, err)
+	}
+
+	// Codes are required, and can't be reused.
+	if err := be.VerifyTwoFactor(ctx, user, ""); !errors.Is(err, proto.ErrTwoFactorRequired) {
+		t.Errorf("expected %v, got %v", proto.ErrTwoFactorRequired, err)
+	}
+	if err := be.VerifyTwoFactor(ctx, user, code(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.VerifyTwoFactor(ctx, user, code(0)); !errors.Is(err, proto.ErrInvalidTwoFactorCode) {
+		t.Errorf("expected reused codes to be invalid, got %v", err)
+	}
+
+	if err := be.DisableTwoFactor(ctx, user, code(1)); err != nil {
+		t.Fatal(err)
+	}
+	if be.HasTwoFactor(ctx, user) {
+		t.Fatal("expected two-factor to be disabled")
+	}
+	if err := be.VerifyTwoFactor(ctx, user, ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTwoFactorFailures(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, _, err := be.EnrollTwoFactor(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := be.ConfirmTwoFactor(ctx, user, code); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wrong codes are recorded, and throttled after a few.
+	for i := 0; i < twoFactorFailureLimit.Burst; i++ {
+		if err := be.VerifyTwoFactor(ctx, user, "000000x"); !errors.Is(err, proto.ErrInvalidTwoFactorCode) {
+			t.Fatalf("expected %v, got %v", proto.ErrInvalidTwoFactorCode, err)
+		}
+	}
+	code, err = totp.Code(secret, totp.Step(time.Now())+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := be.VerifyTwoFactor(ctx, user, code); !errors.Is(err, proto.ErrRateLimited) {
+		t.Fatalf("expected %v, got %v", proto.ErrRateLimited, err)
+	}
+
+	events, err := be.AuditEvents(ctx, proto.AuditFilter{Action: string(proto.AuditAuthFailure), Username: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != twoFactorFailureLimit.Burst {
+		t.Fatalf("expected %d audit events, got %d", twoFactorFailureLimit.Burst, len(events))
+	}
+	if d := events[0].Details; d != "2fa: invalid two-factor code" {
+		t.Errorf("unexpected details %q", d)
+	}
+}
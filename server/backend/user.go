@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,15 +36,65 @@ func (d *Backend) AccessLevelByPublicKey(ctx context.Context, repo string, pk ss
 
 	user, _ := d.UserByPublicKey(ctx, pk)
 	if user != nil {
-		return d.AccessLevel(ctx, repo, user.Username())
+		return d.AccessLevelForUser(ctx, repo, user)
 	}
 
-	return d.AccessLevel(ctx, repo, "")
+	anon := d.AccessLevel(ctx, repo, "")
+	if level, ok := d.deployKeyAccessLevel(ctx, repo, pk); ok && level > anon {
+		return level
+	}
+
+	return anon
 }
 
 // AccessLevelForUser returns the access level of a user for a repository.
+// Users authenticated with a scoped access token never get more access than
+// the token grants, and users authenticated with a read-only public key never
+// get more than read-only access.
+func (d *Backend) AccessLevelForUser(ctx context.Context, repo string, u proto.User) access.AccessLevel {
+	// Suspended users have no access at all, not even anonymous access.
+	if u != nil && u.IsSuspended() {
+		return access.NoAccess
+	}
+
+	tu, ok := u.(*user)
+	if ok && tu.keyRestriction == proto.KeyReadOnly {
+		unrestricted := *tu
+		unrestricted.keyRestriction = proto.KeyUnrestricted
+		if level := d.AccessLevelForUser(ctx, repo, &unrestricted); level < access.ReadOnlyAccess {
+			return level
+		}
+		return access.ReadOnlyAccess
+	}
+	if !ok || tu.token == nil {
+		return d.accessLevelForUser(ctx, repo, u)
+	}
+
+	// Tokens limited to a repository are anonymous everywhere else.
+	t := tu.token
+	if t.RepoID.Valid {
+		r := proto.RepositoryFromContext(ctx)
+		if r == nil || r.Name() != repo {
+			r, _ = d.Repository(ctx, repo)
+		}
+		if r == nil || r.ID() != t.RepoID.Int64 {
+			return d.accessLevelForUser(ctx, repo, nil)
+		}
+	}
+
+	// Check the access of the user itself, then cap it to the token scope.
+	unscoped := *tu
+	unscoped.token = nil
+	level := d.accessLevelForUser(ctx, repo, &unscoped)
+	if level > t.AccessLevel {
+		return t.AccessLevel
+	}
+
+	return level
+}
+
 // TODO: user repository ownership
-func (d *Backend) AccessLevelForUser(ctx context.Context, repo string, user proto.User) access.AccessLevel {
+func (d *Backend) accessLevelForUser(ctx context.Context, repo string, user proto.User) access.AccessLevel {
 	var username string
 	anon := d.AnonAccess(ctx)
 	if user != nil {
@@ -83,6 +134,19 @@ func (d *Backend) AccessLevelForUser(ctx context.Context, repo string, user prot
 			return access.NoAccess
 		}
 
+		// Internal repositories are only visible to authenticated users.
+		if r.IsInternal() && user == nil {
+			return access.NoAccess
+		}
+
+		// Anonymous users get the anonymous access level of the repository,
+		// up to read-only access.
+		if user == nil {
+			if level, _, err := d.RepoAnonAccess(ctx, r.Name()); err != nil || level < access.ReadOnlyAccess {
+				return access.NoAccess
+			}
+		}
+
 		// Otherwise, the user has read-only access.
 		return access.ReadOnlyAccess
 	}
@@ -167,8 +231,13 @@ func (d *Backend) UserByID(ctx context.Context, id int64) (proto.User, error) {
 //
 // It implements backend.Backend.
 func (d *Backend) UserByPublicKey(ctx context.Context, pk ssh.PublicKey) (proto.User, error) {
+	if cert, ok := pk.(*ssh.Certificate); ok {
+		return d.UserByCertificate(ctx, cert)
+	}
+
 	var m models.User
 	var pks []ssh.PublicKey
+	var key models.PublicKey
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		var err error
 		m, err = d.store.FindUserByPublicKey(ctx, tx, pk)
@@ -176,6 +245,11 @@ func (d *Backend) UserByPublicKey(ctx context.Context, pk ssh.PublicKey) (proto.
 			return db.WrapError(err)
 		}
 
+		key, err = d.store.GetPublicKey(ctx, tx, pk)
+		if err != nil {
+			return err
+		}
+
 		pks, err = d.store.ListPublicKeysByUserID(ctx, tx, m.ID)
 		return err
 	}); err != nil {
@@ -188,20 +262,22 @@ func (d *Backend) UserByPublicKey(ctx context.Context, pk ssh.PublicKey) (proto.
 	}
 
 	return &user{
-		user:       m,
-		publicKeys: pks,
+		user:           m,
+		publicKeys:     pks,
+		keyRestriction: proto.KeyRestriction(key.Restriction),
 	}, nil
 }
 
 // UserByAccessToken finds a user by access token.
 // This also validates the token for expiration and returns proto.ErrTokenExpired.
-func (d *Backend) UserByAccessToken(ctx context.Context, token string) (proto.User, error) {
+func (d *Backend) UserByAccessToken(ctx context.Context, tokenStr string) (proto.User, error) {
 	var m models.User
 	var pks []ssh.PublicKey
-	token = HashToken(token)
+	var token *models.AccessToken
+	tokenHash := HashToken(tokenStr)
 
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
-		t, err := d.store.GetAccessTokenByToken(ctx, tx, token)
+		t, err := d.store.GetAccessTokenByToken(ctx, tx, tokenHash)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -210,7 +286,9 @@ func (d *Backend) UserByAccessToken(ctx context.Context, token string) (proto.Us
 			return proto.ErrTokenExpired
 		}
 
-		m, err = d.store.FindUserByAccessToken(ctx, tx, token)
+		token = &t
+
+		m, err = d.store.FindUserByAccessToken(ctx, tx, tokenHash)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -222,17 +300,25 @@ func (d *Backend) UserByAccessToken(ctx context.Context, token string) (proto.Us
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return nil, proto.ErrUserNotFound
 		}
-		d.logger.Error("failed to find user by access token", "err", err, "token", token)
+		d.logger.Error("failed to find user by access token", "err", err, "token", tokenHash)
 		return nil, err
 	}
 
+	d.AuditAuthentication(ctx, proto.AuditEvent{
+		Action:   proto.AuditTokenUse,
+		Username: m.Username,
+		Target:   strconv.FormatInt(token.ID, 10),
+		Details:  token.Name,
+	})
+
 	return &user{
 		user:       m,
 		publicKeys: pks,
+		token:      token,
 	}, nil
 }
 
-// Users returns all users.
+// Users returns the usernames of all users, in the order they were created.
 //
 // It implements backend.Backend.
 func (d *Backend) Users(ctx context.Context) ([]string, error) {
@@ -259,16 +345,7 @@ func (d *Backend) Users(ctx context.Context) ([]string, error) {
 //
 // It implements backend.Backend.
 func (d *Backend) AddPublicKey(ctx context.Context, username string, pk ssh.PublicKey) error {
-	username = strings.ToLower(username)
-	if err := utils.ValidateUsername(username); err != nil {
-		return err
-	}
-
-	return db.WrapError(
-		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
-			return d.store.AddPublicKeyByUsername(ctx, tx, username, pk)
-		}),
-	)
+	return d.AddPublicKeyWithOptions(ctx, username, pk, proto.PublicKeyOptions{})
 }
 
 // CreateUser creates a new user.
@@ -286,6 +363,8 @@ func (d *Backend) CreateUser(ctx context.Context, username string, opts proto.Us
 		return nil, db.WrapError(err)
 	}
 
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUserCreate, Target: username, Details: "admin=" + strconv.FormatBool(opts.Admin)})
+
 	return d.User(ctx, username)
 }
 
@@ -298,24 +377,42 @@ func (d *Backend) DeleteUser(ctx context.Context, username string) error {
 		return err
 	}
 
-	return d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	// The user is looked up first, its avatar is stored by ID.
+	user, _ := d.User(ctx, username)
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		if err := d.store.DeleteUserByUsername(ctx, tx, username); err != nil {
 			return db.WrapError(err)
 		}
 
 		return d.DeleteUserRepositories(ctx, username)
-	})
+	}); err != nil {
+		return err
+	}
+
+	if user != nil {
+		d.removeAvatar(user)
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUserDelete, Target: username})
+
+	return nil
 }
 
 // RemovePublicKey removes a public key from a user.
 //
 // It implements backend.Backend.
 func (d *Backend) RemovePublicKey(ctx context.Context, username string, pk ssh.PublicKey) error {
-	return db.WrapError(
+	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 			return d.store.RemovePublicKeyByUsername(ctx, tx, username, pk)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUserKeyRemove, Target: username, Details: ssh.FingerprintSHA256(pk)})
+
+	return nil
 }
 
 // ListPublicKeys lists the public keys of a user.
@@ -346,11 +443,17 @@ func (d *Backend) SetUsername(ctx context.Context, username string, newUsername
 		return err
 	}
 
-	return db.WrapError(
+	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 			return d.store.SetUsernameByUsername(ctx, tx, username, newUsername)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUserRename, Target: newUsername, Details: username})
+
+	return nil
 }
 
 // SetAdmin sets the admin flag of a user.
@@ -362,11 +465,49 @@ func (d *Backend) SetAdmin(ctx context.Context, username string, admin bool) err
 		return err
 	}
 
-	return db.WrapError(
+	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 			return d.store.SetAdminByUsername(ctx, tx, username, admin)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUserAdmin, Target: username, Details: strconv.FormatBool(admin)})
+
+	return nil
+}
+
+// SetSuspended suspends or reactivates a user. Suspended users can't
+// authenticate over SSH or HTTP, their public keys and access tokens are kept.
+func (d *Backend) SetSuspended(ctx context.Context, username string, suspended bool) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if _, err := d.store.FindUserByUsername(ctx, tx, username); err != nil {
+			return err
+		}
+
+		return d.store.SetSuspendedByUsername(ctx, tx, username, suspended)
+	})
+	if err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrUserNotFound
+		}
+		return err
+	}
+
+	action := proto.AuditUserActivate
+	if suspended {
+		action = proto.AuditUserSuspend
+	}
+	d.Audit(ctx, proto.AuditEvent{Action: action, Target: username})
+
+	return nil
 }
 
 // SetPassword sets the password of a user.
@@ -381,25 +522,49 @@ func (d *Backend) SetPassword(ctx context.Context, username string, rawPassword
 		return err
 	}
 
-	return db.WrapError(
+	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 			return d.store.SetUserPasswordByUsername(ctx, tx, username, password)
 		}),
-	)
+	); err != nil {
+		return err
+	}
+
+	d.Audit(ctx, proto.AuditEvent{Action: proto.AuditUserPassword, Target: username})
+
+	return nil
 }
 
 type user struct {
 	user       models.User
 	publicKeys []ssh.PublicKey
+	// token is the access token the user authenticated with.
+	token *models.AccessToken
+	// keyRestriction is the restriction of the public key the user
+	// authenticated with.
+	keyRestriction proto.KeyRestriction
 }
 
 var _ proto.User = (*user)(nil)
 
-// IsAdmin implements proto.User
+// IsAdmin implements proto.User. Admins authenticated with an access token
+// are only admins if the token has the admin scope on all repositories, and
+// admins authenticated with a read-only public key aren't admins.
 func (u *user) IsAdmin() bool {
+	if u.token != nil && (u.token.AccessLevel < access.AdminAccess || u.token.RepoID.Valid) {
+		return false
+	}
+	if u.keyRestriction == proto.KeyReadOnly {
+		return false
+	}
 	return u.user.Admin
 }
 
+// IsSuspended implements proto.User.
+func (u *user) IsSuspended() bool {
+	return u.user.Suspended
+}
+
 // PublicKeys implements proto.User
 func (u *user) PublicKeys() []ssh.PublicKey {
 	return u.publicKeys
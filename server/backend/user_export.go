@@ -0,0 +1,361 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"golang.org/x/crypto/ssh"
+)
+
+// ExportedUser is a user as exported by ExportUsers and imported by
+// ImportUsers.
+type ExportedUser struct {
+	Username       string                 `json:"username" yaml:"username"`
+	Admin          bool                   `json:"admin" yaml:"admin"`
+	Suspended      bool                   `json:"suspended" yaml:"suspended"`
+	PublicKeys     []ExportedPublicKey    `json:"public_keys" yaml:"public_keys"`
+	AllowedAddrs   []string               `json:"allowed_addresses,omitempty" yaml:"allowed_addresses,omitempty"`
+	DeniedAddrs    []string               `json:"denied_addresses,omitempty" yaml:"denied_addresses,omitempty"`
+	Collaborations []ExportedCollabAccess `json:"collaborations,omitempty" yaml:"collaborations,omitempty"`
+}
+
+// ExportedPublicKey is a public key of an exported user, with its label,
+// restriction, and expiry.
+type ExportedPublicKey struct {
+	Key         string     `json:"key" yaml:"key"`
+	Label       string     `json:"label,omitempty" yaml:"label,omitempty"`
+	Restriction string     `json:"restriction,omitempty" yaml:"restriction,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+}
+
+// ExportedCollabAccess is the access level of an exported user on a
+// repository they collaborate on.
+type ExportedCollabAccess struct {
+	Repo        string `json:"repo" yaml:"repo"`
+	AccessLevel string `json:"access_level" yaml:"access_level"`
+}
+
+// UserImportResult is the result of importing users.
+type UserImportResult struct {
+	// Created are the users created.
+	Created []string
+	// Updated are the existing users that changed.
+	Updated []string
+	// Unchanged are the existing users that already matched the import.
+	Unchanged []string
+	// MissingRepos are the collaborations skipped because the repository
+	// doesn't exist, as "username: repo".
+	MissingRepos []string
+}
+
+// ExportUsers returns all users, in the order they were created, with their
+// public keys, address rules, and the repositories they collaborate on. Public
+// keys keep their label, restriction, and expiry. Passwords and access tokens
+// aren't exported.
+func (d *Backend) ExportUsers(ctx context.Context) ([]ExportedUser, error) {
+	usernames, err := d.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collabs := make(map[string][]ExportedCollabAccess)
+	repos, err := d.Repositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range repos {
+		names, err := d.Collaborators(ctx, r.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			level, ok, err := d.IsCollaborator(ctx, r.Name(), name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				collabs[name] = append(collabs[name], ExportedCollabAccess{Repo: r.Name(), AccessLevel: level.String()})
+			}
+		}
+	}
+
+	users := make([]ExportedUser, 0, len(usernames))
+	for _, username := range usernames {
+		user, err := d.User(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+
+		rules, err := d.UserIPRules(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		allow, deny := rules.Strings()
+
+		pks, err := d.UserPublicKeys(ctx, user.Username())
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]ExportedPublicKey, 0, len(pks))
+		for _, pk := range pks {
+			key := ExportedPublicKey{
+				Key:         sshutils.MarshalAuthorizedKey(pk.PublicKey),
+				Label:       pk.Label,
+				Restriction: string(pk.Restriction),
+			}
+			if !pk.ExpiresAt.IsZero() {
+				expiresAt := pk.ExpiresAt.UTC()
+				key.ExpiresAt = &expiresAt
+			}
+			keys = append(keys, key)
+		}
+
+		users = append(users, ExportedUser{
+			Username:       user.Username(),
+			Admin:          user.IsAdmin(),
+			Suspended:      user.IsSuspended(),
+			PublicKeys:     keys,
+			AllowedAddrs:   allow,
+			DeniedAddrs:    deny,
+			Collaborations: collabs[user.Username()],
+		})
+	}
+
+	return users, nil
+}
+
+// importedUser is a validated user to import.
+type importedUser struct {
+	ExportedUser
+	keys   []importedKey
+	rules  access.IPRules
+	levels []access.AccessLevel
+}
+
+// importedKey is a validated public key to import.
+type importedKey struct {
+	pk   ssh.PublicKey
+	opts proto.PublicKeyOptions
+}
+
+// ImportUsers creates the missing users and updates the existing ones. The
+// admin status, suspension, and address rules are set from the import,
+// public keys and collaborations are added to the existing ones. The label,
+// restriction, and expiry of existing public keys are set from the import. Every user
+// is validated before anything changes, collaborations on repositories that
+// don't exist are skipped.
+func (d *Backend) ImportUsers(ctx context.Context, users []ExportedUser) (UserImportResult, error) {
+	var res UserImportResult
+	imports := make([]importedUser, 0, len(users))
+	seen := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		iu, err := d.validateImportedUser(ctx, u)
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", u.Username, err)
+		}
+		if _, ok := seen[iu.Username]; ok {
+			return res, fmt.Errorf("%s: duplicate user", iu.Username)
+		}
+		seen[iu.Username] = struct{}{}
+		imports = append(imports, iu)
+	}
+
+	for _, u := range imports {
+		changed, created, err := d.importUser(ctx, u, &res)
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", u.Username, err)
+		}
+		switch {
+		case created:
+			res.Created = append(res.Created, u.Username)
+		case changed:
+			res.Updated = append(res.Updated, u.Username)
+		default:
+			res.Unchanged = append(res.Unchanged, u.Username)
+		}
+	}
+
+	return res, nil
+}
+
+// validateImportedUser parses the public keys, address rules, and access
+// levels of a user to import. Public keys of other users are rejected.
+func (d *Backend) validateImportedUser(ctx context.Context, u ExportedUser) (importedUser, error) {
+	u.Username = strings.ToLower(u.Username)
+	iu := importedUser{ExportedUser: u}
+	if u.Username == "" {
+		return iu, errors.New("missing username")
+	}
+
+	for _, k := range u.PublicKeys {
+		pk, _, err := sshutils.ParseAuthorizedKey(k.Key)
+		if err != nil {
+			return iu, err
+		}
+
+		restriction, err := proto.ParseKeyRestriction(k.Restriction)
+		if err != nil {
+			return iu, err
+		}
+		opts := proto.PublicKeyOptions{Label: k.Label, Restriction: restriction}
+		if k.ExpiresAt != nil {
+			opts.ExpiresAt = *k.ExpiresAt
+		}
+
+		owner, err := d.UserByPublicKey(ctx, pk)
+		if err == nil && owner.Username() != u.Username {
+			return iu, proto.ErrPublicKeyInUse
+		} else if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+			return iu, err
+		}
+		iu.keys = append(iu.keys, importedKey{pk: pk, opts: opts})
+	}
+
+	rules, err := access.ParseIPRules(u.AllowedAddrs, u.DeniedAddrs)
+	if err != nil {
+		return iu, err
+	}
+	iu.rules = rules
+
+	for _, c := range u.Collaborations {
+		level := access.ParseAccessLevel(c.AccessLevel)
+		if level < 0 {
+			return iu, fmt.Errorf("invalid access level %q for %s", c.AccessLevel, c.Repo)
+		}
+		iu.levels = append(iu.levels, level)
+	}
+
+	return iu, nil
+}
+
+// importUser creates or updates a validated user, and reports whether it
+// changed or was created.
+func (d *Backend) importUser(ctx context.Context, u importedUser, res *UserImportResult) (changed bool, created bool, err error) {
+	user, err := d.User(ctx, u.Username)
+	if errors.Is(err, proto.ErrUserNotFound) {
+		user, err = d.CreateUser(ctx, u.Username, proto.UserOptions{Admin: u.Admin})
+		if err != nil {
+			return false, false, err
+		}
+		for _, k := range u.keys {
+			if err := d.AddPublicKeyWithOptions(ctx, u.Username, k.pk, k.opts); err != nil {
+				return false, false, err
+			}
+		}
+		created, changed = true, true
+	} else if err != nil {
+		return false, false, err
+	} else {
+		if user.IsAdmin() != u.Admin {
+			if err := d.SetAdmin(ctx, u.Username, u.Admin); err != nil {
+				return false, false, err
+			}
+			changed = true
+		}
+
+		pks, err := d.UserPublicKeys(ctx, u.Username)
+		if err != nil {
+			return false, false, err
+		}
+		existing := make(map[string]proto.PublicKey, len(pks))
+		for _, pk := range pks {
+			existing[sshutils.MarshalAuthorizedKey(pk.PublicKey)] = pk
+		}
+		for _, k := range u.keys {
+			pk, ok := existing[sshutils.MarshalAuthorizedKey(k.pk)]
+			if !ok {
+				if err := d.AddPublicKeyWithOptions(ctx, u.Username, k.pk, k.opts); err != nil {
+					return false, false, err
+				}
+				changed = true
+				continue
+			}
+			if pk.Label == k.opts.Label && pk.Restriction == k.opts.Restriction && pk.ExpiresAt.Equal(k.opts.ExpiresAt) {
+				continue
+			}
+			if err := d.updatePublicKey(ctx, u.Username, k.pk, k.opts); err != nil {
+				return false, false, err
+			}
+			changed = true
+		}
+	}
+
+	if user.IsSuspended() != u.Suspended {
+		if err := d.SetSuspended(ctx, u.Username, u.Suspended); err != nil {
+			return false, false, err
+		}
+		changed = true
+	}
+
+	rules, err := d.UserIPRules(ctx, user)
+	if err != nil {
+		return false, false, err
+	}
+	if !sameIPRules(rules, u.rules) {
+		if err := d.SetUserIPRules(ctx, u.Username, u.rules); err != nil {
+			return false, false, err
+		}
+		changed = true
+	}
+
+	for i, c := range u.Collaborations {
+		if _, err := d.Repository(ctx, c.Repo); errors.Is(err, proto.ErrRepoNotFound) {
+			res.MissingRepos = append(res.MissingRepos, u.Username+": "+c.Repo)
+			continue
+		} else if err != nil {
+			return false, false, err
+		}
+
+		level, ok, err := d.IsCollaborator(ctx, c.Repo, u.Username)
+		if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+			return false, false, err
+		}
+		if ok && level == u.levels[i] {
+			continue
+		}
+		if ok {
+			if err := d.RemoveCollaborator(ctx, c.Repo, u.Username); err != nil {
+				return false, false, err
+			}
+		}
+		if err := d.AddCollaborator(ctx, c.Repo, u.Username, u.levels[i]); err != nil {
+			return false, false, err
+		}
+		changed = true
+	}
+
+	return changed, created, nil
+}
+
+// updatePublicKey sets the label, restriction, and expiry of a public key of
+// a user.
+func (d *Backend) updatePublicKey(ctx context.Context, username string, pk ssh.PublicKey, opts proto.PublicKeyOptions) error {
+	var expiresAt sql.NullTime
+	if !opts.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: opts.ExpiresAt, Valid: true}
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.UpdatePublicKeyByUsername(ctx, tx, username, pk, opts.Label, expiresAt, string(opts.Restriction))
+		}),
+	)
+}
+
+// sameIPRules reports whether two address rules allow and deny the same
+// CIDRs.
+func sameIPRules(a, b access.IPRules) bool {
+	aAllow, aDeny := a.Strings()
+	bAllow, bDeny := b.Strings()
+	return strings.Join(aAllow, ",") == strings.Join(bAllow, ",") &&
+		strings.Join(aDeny, ",") == strings.Join(bDeny, ",")
+}
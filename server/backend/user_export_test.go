@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+)
+
+const (
+	testKey1 = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINMwLvyV3ouVrTysUYGoJdl5Vgn5BACKov+n9PlzfPwH"
+	testKey2 = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIFxIobhwtfdwN7m1TFt9wx3PsfvcAkISGPxmbmbauST8"
+)
+
+func TestExportImportUsers(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	pk, _, err := sshutils.ParseAuthorizedKey(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := be.CreateUser(ctx, "user1", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if err := be.AddPublicKeyWithOptions(ctx, "user1", pk, proto.PublicKeyOptions{
+		Label:       "ci",
+		Restriction: proto.KeyReadOnly,
+		ExpiresAt:   expiresAt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateUser(ctx, "user2", proto.UserOptions{Admin: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetSuspended(ctx, "user2", true); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := access.ParseIPRules([]string{"10.0.0.0/8"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetUserIPRules(ctx, "user1", rules); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"repo1", "repo2"} {
+		if _, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := be.AddCollaborator(ctx, "repo1", "user1", access.ReadWriteAccess); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.AddCollaborator(ctx, "repo2", "user1", access.ReadOnlyAccess); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := be.ExportUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first user is the initial admin.
+	users = users[1:]
+	want := []ExportedUser{
+		{
+			Username: "user1",
+			PublicKeys: []ExportedPublicKey{
+				{Key: testKey1, Label: "ci", Restriction: "read-only", ExpiresAt: &expiresAt},
+			},
+			AllowedAddrs: []string{"10.0.0.0/8"},
+			Collaborations: []ExportedCollabAccess{
+				{Repo: "repo1", AccessLevel: "read-write"},
+				{Repo: "repo2", AccessLevel: "read-only"},
+			},
+		},
+		{Username: "user2", Admin: true, Suspended: true, PublicKeys: []ExportedPublicKey{}},
+	}
+	if !reflect.DeepEqual(users, want) {
+		t.Fatalf("export = %#v, want %#v", users, want)
+	}
+
+	// Importing into another server creates the users, collaborations on
+	// missing repositories are skipped.
+	ctx2, be2 := newTestBackend(t, config.DefaultConfig())
+	owner, err := be2.CreateUser(ctx2, "owner", proto.UserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be2.CreateRepository(ctx2, "repo1", owner, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := be2.ImportUsers(ctx2, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user1", "user2"}; !reflect.DeepEqual(res.Created, want) {
+		t.Errorf("created = %v, want %v", res.Created, want)
+	}
+	if want := []string{"user1: repo2"}; !reflect.DeepEqual(res.MissingRepos, want) {
+		t.Errorf("missing repos = %v, want %v", res.MissingRepos, want)
+	}
+	imported, err := be2.ExportUsers(ctx2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := imported[2]; !reflect.DeepEqual(got.Collaborations, want[0].Collaborations[:1]) ||
+		!reflect.DeepEqual(got.AllowedAddrs, want[0].AllowedAddrs) ||
+		!reflect.DeepEqual(got.PublicKeys, want[0].PublicKeys) {
+		t.Errorf("imported user1 = %+v", got)
+	}
+	if got := imported[3]; !got.Admin || !got.Suspended {
+		t.Errorf("imported user2 = %+v", got)
+	}
+
+	// Importing again changes nothing, updates keep the existing keys and
+	// set the admin status.
+	res, err = be2.ImportUsers(ctx2, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user1", "user2"}; !reflect.DeepEqual(res.Unchanged, want) {
+		t.Errorf("unchanged = %v, want %v", res.Unchanged, want)
+	}
+	res, err = be2.ImportUsers(ctx2, []ExportedUser{{Username: "user2", PublicKeys: []ExportedPublicKey{{Key: testKey2}}, Suspended: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user2"}; !reflect.DeepEqual(res.Updated, want) {
+		t.Errorf("updated = %v, want %v", res.Updated, want)
+	}
+	user2, err := be2.User(ctx2, "user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user2.IsAdmin() || len(user2.PublicKeys()) != 1 {
+		t.Errorf("user2 admin = %t, keys = %d", user2.IsAdmin(), len(user2.PublicKeys()))
+	}
+
+	// Importing existing keys sets their label, restriction, and expiry.
+	res, err = be2.ImportUsers(ctx2, []ExportedUser{{Username: "user1", PublicKeys: []ExportedPublicKey{{Key: testKey1, Label: "laptop"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user1"}; !reflect.DeepEqual(res.Updated, want) {
+		t.Errorf("updated = %v, want %v", res.Updated, want)
+	}
+	keys, err := be2.UserPublicKeys(ctx2, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Label != "laptop" || keys[0].Restriction != proto.KeyUnrestricted || !keys[0].ExpiresAt.IsZero() {
+		t.Errorf("user1 keys = %+v", keys)
+	}
+
+	// Invalid users, and keys of other users, fail the whole import.
+	for _, u := range []ExportedUser{
+		{Username: "user3", PublicKeys: []ExportedPublicKey{{Key: "nope"}}},
+		{Username: "user3", PublicKeys: []ExportedPublicKey{{Key: testKey2, Restriction: "admin-only"}}},
+		{Username: "user3", Collaborations: []ExportedCollabAccess{{Repo: "repo1", AccessLevel: "owner"}}},
+		{Username: "user3", AllowedAddrs: []string{"nope"}},
+	} {
+		if _, err := be2.ImportUsers(ctx2, []ExportedUser{{Username: "user4"}, u}); err == nil {
+			t.Errorf("expected an error importing %+v", u)
+		}
+	}
+	if _, err := be2.ImportUsers(ctx2, []ExportedUser{{Username: "user3", PublicKeys: []ExportedPublicKey{{Key: testKey1}}}}); !errors.Is(err, proto.ErrPublicKeyInUse) {
+		t.Errorf("expected %v, got %v", proto.ErrPublicKeyInUse, err)
+	}
+	if _, err := be2.User(ctx2, "user4"); !errors.Is(err, proto.ErrUserNotFound) {
+		t.Errorf("expected user4 not to be created, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// User setting keys.
+const (
+	presenceSetting = "presence"
+	sessionSetting  = "session"
+	dateSetting     = "date-format"
+	timezoneSetting = "timezone"
+	providerSetting = "auth-provider"
+	subjectSetting  = "oidc-subject"
+	emailSetting    = "email"
+	avatarSetting   = "avatar"
+)
+
+// UserSetting returns the value of a user setting. It returns an empty string
+// if the setting is not set.
+func (d *Backend) UserSetting(ctx context.Context, user proto.User, key string) (string, error) {
+	if user == nil {
+		return "", proto.ErrUserNotFound
+	}
+
+	var value string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		value, err = d.store.GetUserSetting(ctx, tx, user.ID(), key)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return value, nil
+}
+
+// SetUserSetting sets the value of a user setting. An empty value removes the
+// setting.
+func (d *Backend) SetUserSetting(ctx context.Context, user proto.User, key string, value string) error {
+	if user == nil {
+		return proto.ErrUserNotFound
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if value == "" {
+				return d.store.DeleteUserSetting(ctx, tx, user.ID(), key)
+			}
+			return d.store.SetUserSetting(ctx, tx, user.ID(), key, value)
+		}),
+	)
+}
+
+// Presence returns whether the user opted in to share their presence with
+// other users browsing the same repository.
+func (d *Backend) Presence(ctx context.Context, user proto.User) bool {
+	v, err := d.UserSetting(ctx, user, presenceSetting)
+	if err != nil {
+		d.logger.Error("error getting user setting", "key", presenceSetting, "err", err)
+		return false
+	}
+
+	enabled, _ := strconv.ParseBool(v)
+	return enabled
+}
+
+// SetPresence sets whether the user shares their presence with other users.
+func (d *Backend) SetPresence(ctx context.Context, user proto.User, enabled bool) error {
+	return d.SetUserSetting(ctx, user, presenceSetting, strconv.FormatBool(enabled))
+}
+
+// SessionState returns the UI state of the user's last TUI session.
+func (d *Backend) SessionState(ctx context.Context, user proto.User) (proto.SessionState, error) {
+	var state proto.SessionState
+	v, err := d.UserSetting(ctx, user, sessionSetting)
+	if err != nil || v == "" {
+		return state, err
+	}
+
+	if err := json.Unmarshal([]byte(v), &state); err != nil {
+		return proto.SessionState{}, err
+	}
+
+	return state, nil
+}
+
+// SetSessionState saves the UI state of the user's TUI session.
+func (d *Backend) SetSessionState(ctx context.Context, user proto.User, state proto.SessionState) error {
+	if state == (proto.SessionState{}) {
+		return d.SetUserSetting(ctx, user, sessionSetting, "")
+	}
+
+	v, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return d.SetUserSetting(ctx, user, sessionSetting, string(v))
+}
+
+// TimeFormat returns the user's date and time formatting preferences.
+func (d *Backend) TimeFormat(ctx context.Context, user proto.User) proto.TimeFormat {
+	var tf proto.TimeFormat
+	if user == nil {
+		return tf
+	}
+
+	v, err := d.UserSetting(ctx, user, dateSetting)
+	if err != nil {
+		d.logger.Error("error getting user setting", "key", dateSetting, "err", err)
+	} else if ds, err := proto.ParseDateStyle(v); err == nil {
+		tf.Style = ds
+	}
+
+	v, err = d.UserSetting(ctx, user, timezoneSetting)
+	if err != nil {
+		d.logger.Error("error getting user setting", "key", timezoneSetting, "err", err)
+	} else if v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			d.logger.Error("invalid timezone", "timezone", v, "err", err)
+		} else {
+			tf.Location = loc
+		}
+	}
+
+	return tf
+}
+
+// SetDateStyle sets how dates are displayed to the user.
+func (d *Backend) SetDateStyle(ctx context.Context, user proto.User, style proto.DateStyle) error {
+	return d.SetUserSetting(ctx, user, dateSetting, string(style))
+}
+
+// SetTimezone sets the time zone dates are displayed in. An empty timezone
+// displays dates in their own time zone.
+func (d *Backend) SetTimezone(ctx context.Context, user proto.User, tz string) error {
+	if tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+	}
+
+	return d.SetUserSetting(ctx, user, timezoneSetting, tz)
+}
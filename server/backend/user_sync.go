@@ -0,0 +1,230 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/server/auth"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrNoAuthProvider is returned when syncing users without a configured
+// provider.
+var ErrNoAuthProvider = errors.New("no user provider is configured")
+
+// UserSyncResult is the result of syncing the users of a provider.
+type UserSyncResult struct {
+	// Created are the users created.
+	Created []string
+	// Updated are the users whose admin status or public keys changed.
+	Updated []string
+	// Disabled are the users that were removed from the provider. They lose
+	// their public keys, access tokens, and admin status.
+	Disabled []string
+	// Skipped are the users of the provider that conflict with local users.
+	Skipped []string
+}
+
+// AuthProvider returns the provider users are synced from, or nil.
+func (d *Backend) AuthProvider() auth.Provider {
+	d.reloadMtx.RLock()
+	defer d.reloadMtx.RUnlock()
+	return d.provider
+}
+
+// UserProvider returns the name of the provider managing a user, or an empty
+// string for local users.
+func (d *Backend) UserProvider(ctx context.Context, user proto.User) (string, error) {
+	return d.UserSetting(ctx, user, providerSetting)
+}
+
+// SyncUsers syncs the users of the configured provider. Users are created
+// with their public keys and admin status, which are then kept in sync. Local
+// users with the same username as a provider user are left untouched.
+func (d *Backend) SyncUsers(ctx context.Context) (UserSyncResult, error) {
+	var res UserSyncResult
+	p := d.AuthProvider()
+	if p == nil {
+		return res, ErrNoAuthProvider
+	}
+
+	users, err := p.Users(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	name := p.Name()
+	seen := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		seen[u.Username] = struct{}{}
+		existing, err := d.User(ctx, u.Username)
+		if errors.Is(err, proto.ErrUserNotFound) {
+			created, err := d.CreateUser(ctx, u.Username, proto.UserOptions{
+				Admin:      u.Admin,
+				PublicKeys: u.PublicKeys,
+			})
+			if err != nil {
+				d.logger.Error("error creating user", "username", u.Username, "err", err)
+				continue
+			}
+			if err := d.SetUserSetting(ctx, created, providerSetting, name); err != nil {
+				return res, err
+			}
+			res.Created = append(res.Created, u.Username)
+			continue
+		} else if err != nil {
+			return res, err
+		}
+
+		provider, err := d.UserProvider(ctx, existing)
+		if err != nil {
+			return res, err
+		}
+		if provider != name {
+			res.Skipped = append(res.Skipped, u.Username)
+			continue
+		}
+
+		changed, err := d.syncUser(ctx, existing, u.Admin, u.PublicKeys)
+		if err != nil {
+			d.logger.Error("error syncing user", "username", u.Username, "err", err)
+			continue
+		}
+		if changed {
+			res.Updated = append(res.Updated, u.Username)
+		}
+	}
+
+	// Disable the users that were removed from the provider.
+	usernames, err := d.Users(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	for _, username := range usernames {
+		if _, ok := seen[username]; ok {
+			continue
+		}
+
+		existing, err := d.User(ctx, username)
+		if err != nil {
+			return res, err
+		}
+
+		provider, err := d.UserProvider(ctx, existing)
+		if err != nil {
+			return res, err
+		}
+		if provider != name {
+			continue
+		}
+
+		if err := d.disableUser(ctx, existing); err != nil {
+			d.logger.Error("error disabling user", "username", username, "err", err)
+			continue
+		}
+		if existing.IsAdmin() || len(existing.PublicKeys()) > 0 {
+			res.Disabled = append(res.Disabled, username)
+		}
+	}
+
+	return res, nil
+}
+
+// syncUser updates the admin status and public keys of a user. It returns
+// whether anything changed.
+func (d *Backend) syncUser(ctx context.Context, user proto.User, admin bool, pks []ssh.PublicKey) (bool, error) {
+	var changed bool
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if user.IsAdmin() != admin {
+			if err := d.store.SetAdminByUsername(ctx, tx, user.Username(), admin); err != nil {
+				return err
+			}
+			changed = true
+		}
+
+		for _, pk := range user.PublicKeys() {
+			if !containsKey(pks, pk) {
+				if err := d.store.RemovePublicKeyByUsername(ctx, tx, user.Username(), pk); err != nil {
+					return err
+				}
+				changed = true
+			}
+		}
+
+		for _, pk := range pks {
+			if !containsKey(user.PublicKeys(), pk) {
+				if err := d.store.AddPublicKeyByUsername(ctx, tx, user.Username(), pk); err != nil {
+					return err
+				}
+				changed = true
+			}
+		}
+
+		return nil
+	})
+
+	return changed, db.WrapError(err)
+}
+
+// disableUser removes the public keys, access tokens, and admin status of a
+// user.
+func (d *Backend) disableUser(ctx context.Context, user proto.User) error {
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if err := d.store.SetAdminByUsername(ctx, tx, user.Username(), false); err != nil {
+				return err
+			}
+
+			for _, pk := range user.PublicKeys() {
+				if err := d.store.RemovePublicKeyByUsername(ctx, tx, user.Username(), pk); err != nil {
+					return err
+				}
+			}
+
+			tokens, err := d.store.GetAccessTokensByUserID(ctx, tx, user.ID())
+			if err != nil {
+				return err
+			}
+
+			for _, t := range tokens {
+				if err := d.store.DeleteAccessToken(ctx, tx, t.ID); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}),
+	)
+}
+
+// AuthenticateUser verifies the password of a user managed by the configured
+// provider.
+func (d *Backend) AuthenticateUser(ctx context.Context, user proto.User, password string) error {
+	p := d.AuthProvider()
+	if p == nil {
+		return auth.ErrInvalidCredentials
+	}
+
+	provider, err := d.UserProvider(ctx, user)
+	if err != nil {
+		return err
+	}
+	if provider != p.Name() {
+		return auth.ErrInvalidCredentials
+	}
+
+	return p.Authenticate(ctx, user.Username(), password)
+}
+
+func containsKey(pks []ssh.PublicKey, pk ssh.PublicKey) bool {
+	for _, k := range pks {
+		if sshutils.KeysEqual(k, pk) {
+			return true
+		}
+	}
+	return false
+}
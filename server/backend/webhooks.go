@@ -0,0 +1,166 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/events"
+)
+
+const (
+	// webhooksSetting is the repository setting of the webhook URLs, one per
+	// line.
+	webhooksSetting = "webhooks"
+
+	// maxWebhooks is the maximum number of webhooks of a repository.
+	maxWebhooks = 16
+
+	// webhookTimeout is the maximum time to wait for a webhook.
+	webhookTimeout = 10 * time.Second
+)
+
+// Webhooks returns the webhook URLs of a repository.
+func (d *Backend) Webhooks(ctx context.Context, repo string) ([]string, error) {
+	v, err := d.RepoSetting(ctx, repo, webhooksSetting)
+	if err != nil || v == "" {
+		return nil, err
+	}
+
+	return strings.Split(v, "\n"), nil
+}
+
+// SetWebhooks sets the webhook URLs of a repository. The repository events
+// are posted to them as JSON.
+func (d *Backend) SetWebhooks(ctx context.Context, repo string, urls []string) error {
+	if len(urls) > maxWebhooks {
+		return fmt.Errorf("too many webhooks, the maximum is %d", maxWebhooks)
+	}
+	for _, u := range urls {
+		if err := validateWebhookURL(u); err != nil {
+			return err
+		}
+	}
+
+	return d.SetRepoSetting(ctx, repo, webhooksSetting, strings.Join(urls, "\n"))
+}
+
+// webhookJob is the kind of the jobs delivering an event to a webhook.
+const webhookJob = "webhook"
+
+// webhookPayload is the payload of a webhook job.
+type webhookPayload struct {
+	URL   string          `json:"url"`
+	Type  events.Type     `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+func init() {
+	RegisterJobHandler(webhookJob, func(ctx context.Context, payload []byte) error {
+		var p webhookPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+
+		return deliverWebhook(ctx, p.URL, p.Type, p.Event)
+	})
+}
+
+// DeliverWebhooks queues the delivery of the repository events to the
+// webhooks of their repository until ctx is done. Failed deliveries are
+// retried by the job queue.
+func (d *Backend) DeliverWebhooks(ctx context.Context) {
+	var lastID int64
+	for {
+		sub := d.SubscribeEvents(lastID)
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return
+			case e, ok := <-sub.Events():
+				if !ok {
+					// Too far behind, resume from the last event.
+					open = false
+					break
+				}
+				lastID = e.ID
+				d.enqueueWebhooks(ctx, e)
+			}
+		}
+	}
+}
+
+func (d *Backend) enqueueWebhooks(ctx context.Context, e events.Event) {
+	// The server an event happened on delivers it.
+	if e.Repo == "" || e.Remote {
+		return
+	}
+
+	// Pushes sent with the skip-ci push option aren't delivered.
+	if e.Type == events.Push && hasPushOption(e.PushOptions, PushOptionSkipCI) {
+		return
+	}
+
+	urls, err := d.Webhooks(ctx, e.Repo)
+	if err != nil || len(urls) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		d.logger.Error("error encoding event", "err", err)
+		return
+	}
+
+	for _, u := range urls {
+		if err := d.EnqueueJob(ctx, webhookJob, webhookPayload{
+			URL:   u,
+			Type:  e.Type,
+			Event: body,
+		}); err != nil {
+			d.logger.Error("error queuing webhook", "repo", e.Repo, "event", e.Type, "url", u, "err", err)
+		}
+	}
+}
+
+func deliverWebhook(ctx context.Context, u string, typ events.Type, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Soft-Serve-Event", string(typ))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return nil
+}
+
+func validateWebhookURL(u string) error {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if pu.Scheme != "http" && pu.Scheme != "https" || pu.Host == "" {
+		return fmt.Errorf("invalid webhook URL: %q", u)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+func TestEnqueueWebhooksSkipCI(t *testing.T) {
+	ctx, be := newTestBackend(t, config.DefaultConfig())
+	admin, err := be.User(ctx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := be.CreateRepository(ctx, "repo1", admin, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetWebhooks(ctx, "repo1", []string{"https://ci.example.com/hook"}); err != nil {
+		t.Fatal(err)
+	}
+
+	be.enqueueWebhooks(ctx, events.Event{Type: events.Push, Repo: "repo1", PushOptions: []string{"skip-ci"}})
+	jobs, err := be.Jobs(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("expected skip-ci pushes not to be delivered, got %d jobs", len(jobs))
+	}
+
+	be.enqueueWebhooks(ctx, events.Event{Type: events.Push, Repo: "repo1", PushOptions: []string{"description=skip-ci"}})
+	jobs, err = be.Jobs(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+}
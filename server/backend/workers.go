@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/pool"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// AcquireWorker waits in line for a worker to run a git operation on repo. It
+// returns a function that releases the worker once the operation is done.
+// It returns proto.ErrServerBusy when too many operations are waiting, or
+// they've waited for too long.
+func (d *Backend) AcquireWorker(ctx context.Context, repo string) (func(), error) {
+	if timeout := d.cfg.Workers.QueueTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	release, err := d.workers.Acquire(ctx, repo)
+	switch {
+	case errors.Is(err, pool.ErrQueueFull), errors.Is(err, context.DeadlineExceeded):
+		running, waiting := d.workers.Stats()
+		d.logger.Warn("no git worker available", "repo", repo, "running", running, "waiting", waiting, "err", err)
+		return nil, proto.ErrServerBusy
+	case err != nil:
+		return nil, err
+	}
+
+	return release, nil
+}
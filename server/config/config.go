@@ -1,14 +1,19 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/caarlos0/env/v8"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/listener"
 	"github.com/charmbracelet/soft-serve/server/sshutils"
 	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v3"
@@ -16,7 +21,8 @@ import (
 
 // SSHConfig is the configuration for the SSH server.
 type SSHConfig struct {
-	// ListenAddr is the address on which the SSH server will listen.
+	// ListenAddr is the comma-separated list of addresses on which the
+	// SSH server will listen.
 	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
 
 	// PublicURL is the public URL of the SSH server.
@@ -25,6 +31,11 @@ type SSHConfig struct {
 	// KeyPath is the path to the SSH server's private key.
 	KeyPath string `env:"KEY_PATH" yaml:"key_path"`
 
+	// KeyPaths are the paths to additional host keys, like RSA and ECDSA
+	// keys. Clients negotiate the first key of each type, all of them are
+	// advertised to the clients to rotate the keys.
+	KeyPaths []string `env:"KEY_PATHS" envSeparator:"," yaml:"key_paths"`
+
 	// ClientKeyPath is the path to the server's client private key.
 	ClientKeyPath string `env:"CLIENT_KEY_PATH" yaml:"client_key_path"`
 
@@ -33,11 +44,16 @@ type SSHConfig struct {
 
 	// IdleTimeout is the number of seconds a connection can be idle before it is closed.
 	IdleTimeout int `env:"IDLE_TIMEOUT" yaml:"idle_timeout"`
+
+	// TrustedUserCAKeys is a list of certificate authority public keys, or
+	// paths to them, trusted to sign user certificates.
+	TrustedUserCAKeys []string `env:"TRUSTED_USER_CA_KEYS" envSeparator:"\n" yaml:"trusted_user_ca_keys"`
 }
 
 // GitConfig is the Git daemon configuration for the server.
 type GitConfig struct {
-	// ListenAddr is the address on which the Git daemon will listen.
+	// ListenAddr is the comma-separated list of addresses on which the
+	// Git daemon will listen.
 	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
 
 	// MaxTimeout is the maximum number of seconds a connection can take.
@@ -48,11 +64,16 @@ type GitConfig struct {
 
 	// MaxConnections is the maximum number of concurrent connections.
 	MaxConnections int `env:"MAX_CONNECTIONS" yaml:"max_connections"`
+
+	// DaemonExport is whether repositories are exported by the Git daemon,
+	// unless their daemon-export setting says otherwise.
+	DaemonExport bool `env:"DAEMON_EXPORT" yaml:"daemon_export"`
 }
 
 // HTTPConfig is the HTTP configuration for the server.
 type HTTPConfig struct {
-	// ListenAddr is the address on which the HTTP server will listen.
+	// ListenAddr is the comma-separated list of addresses on which the
+	// HTTP server will listen.
 	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
 
 	// TLSKeyPath is the path to the TLS private key.
@@ -63,12 +84,71 @@ type HTTPConfig struct {
 
 	// PublicURL is the public URL of the HTTP server.
 	PublicURL string `env:"PUBLIC_URL" yaml:"public_url"`
+
+	// GoImportDomain is the root of the Go import paths of repositories. It
+	// defaults to the host of the public URL.
+	GoImportDomain string `env:"GO_IMPORT_DOMAIN" yaml:"go_import_domain"`
+
+	// ACME is the configuration of the certificates provisioned with ACME.
+	ACME ACMEConfig `envPrefix:"ACME_" yaml:"acme"`
+}
+
+// ACMEConfig is the configuration of the TLS certificates of the HTTP server
+// provisioned and renewed with ACME, like Let's Encrypt.
+type ACMEConfig struct {
+	// Enabled is whether the certificates are provisioned with ACME. It
+	// can't be used with a TLS certificate and key.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// Domains are the domains certificates are requested for. It defaults
+	// to the host of the public URL.
+	Domains []string `env:"DOMAINS" envSeparator:"," yaml:"domains"`
+
+	// Email is the contact email of the ACME account, for expiry notices.
+	Email string `env:"EMAIL" yaml:"email"`
+
+	// DirectoryURL is the directory URL of the ACME server.
+	DirectoryURL string `env:"DIRECTORY_URL" yaml:"directory_url"`
+
+	// CacheDir is the directory the account key and the certificates are
+	// stored in.
+	CacheDir string `env:"CACHE_DIR" yaml:"cache_dir"`
+
+	// ChallengeListenAddr is the address of the HTTP server answering the
+	// HTTP-01 challenges and redirecting to HTTPS, usually ":80". The
+	// TLS-ALPN-01 challenges are answered by the HTTP server itself.
+	ChallengeListenAddr string `env:"CHALLENGE_LISTEN_ADDR" yaml:"challenge_listen_addr"`
 }
 
 // StatsConfig is the configuration for the stats server.
 type StatsConfig struct {
-	// ListenAddr is the address on which the stats server will listen.
+	// ListenAddr is the comma-separated list of addresses on which the
+	// stats server will listen.
 	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
+
+	// MaxRepoLabels is the maximum number of repositories with their own
+	// repo label in the git metrics, the others share the "_other" label.
+	// Zero disables the repo labels.
+	MaxRepoLabels int `env:"MAX_REPO_LABELS" yaml:"max_repo_labels"`
+}
+
+// TracingConfig is the OpenTelemetry tracing configuration.
+type TracingConfig struct {
+	// Enabled exports the traces to an OTLP collector.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// Endpoint is the host and port of the OTLP HTTP collector.
+	Endpoint string `env:"ENDPOINT" yaml:"endpoint"`
+
+	// Insecure connects to the collector without TLS.
+	Insecure bool `env:"INSECURE" yaml:"insecure"`
+
+	// ServiceName is the service name of the traces.
+	ServiceName string `env:"SERVICE_NAME" yaml:"service_name"`
+
+	// SampleRatio is the ratio of the traces that are sampled, between 0 and
+	// 1. Traces started by clients follow their sampling decision.
+	SampleRatio float64 `env:"SAMPLE_RATIO" yaml:"sample_ratio"`
 }
 
 // LogConfig is the logger configuration.
@@ -84,6 +164,28 @@ type LogConfig struct {
 	// Path to a file to write logs to.
 	// If not set, logs will be written to stderr.
 	Path string `env:"PATH" yaml:"path"`
+
+	// Level is the log level, one of "debug", "info", "warn", "error" and
+	// "fatal". Debug mode logs at the debug level.
+	Level string `env:"LEVEL" yaml:"level"`
+
+	// Levels are the log levels of the subsystems, they default to Level.
+	Levels LogLevelsConfig `envPrefix:"LEVELS_" yaml:"levels"`
+
+	// TransportSampling logs the requests and sessions of one in every
+	// TransportSampling HTTP requests, SSH sessions and git daemon requests.
+	// Zero and one log all of them.
+	TransportSampling int `env:"TRANSPORT_SAMPLING" yaml:"transport_sampling"`
+}
+
+// LogLevelsConfig is the log levels of the subsystems. Empty levels use the
+// log level.
+type LogLevelsConfig struct {
+	SSH  string `env:"SSH" yaml:"ssh"`
+	Git  string `env:"GIT" yaml:"git"`
+	HTTP string `env:"HTTP" yaml:"http"`
+	UI   string `env:"UI" yaml:"ui"`
+	DB   string `env:"DB" yaml:"db"`
 }
 
 // DBConfig is the database connection configuration.
@@ -93,6 +195,10 @@ type DBConfig struct {
 
 	// DataSource is the database data source name.
 	DataSource string `env:"DATA_SOURCE" yaml:"data_source"`
+
+	// AutoMigrate is whether the server migrates the database when it starts.
+	// Otherwise, it doesn't start while migrations are pending.
+	AutoMigrate bool `env:"AUTO_MIGRATE" yaml:"auto_migrate"`
 }
 
 // LFSConfig is the configuration for Git LFS.
@@ -103,6 +209,359 @@ type LFSConfig struct {
 	// SSHEnabled is whether or not Git LFS over SSH is enabled.
 	// This is only used if LFS is enabled.
 	SSHEnabled bool `env:"SSH_ENABLED" yaml:"ssh_enabled"`
+
+	// Storage is the storage backend of LFS objects. It can be "local" or
+	// "s3". Defaults to "local".
+	Storage string `env:"STORAGE" yaml:"storage"`
+
+	// S3 is the configuration for the S3 storage backend.
+	// This is only used if Storage is "s3".
+	S3 S3Config `envPrefix:"S3_" yaml:"s3"`
+
+	// PruneRetentionDays is the number of days LFS objects that aren't
+	// referenced by any ref are kept before the prune job removes them.
+	// A value of 0 disables the prune job.
+	PruneRetentionDays int `env:"PRUNE_RETENTION_DAYS" yaml:"prune_retention_days"`
+}
+
+// S3Config is the configuration for an S3-compatible object storage.
+type S3Config struct {
+	// Endpoint is the URL of the object storage service, e.g.
+	// "https://s3.us-east-1.amazonaws.com", or "http://localhost:9000" for
+	// MinIO.
+	Endpoint string `env:"ENDPOINT" yaml:"endpoint"`
+
+	// Region is the region of the bucket. Defaults to "us-east-1".
+	Region string `env:"REGION" yaml:"region"`
+
+	// Bucket is the name of the bucket objects are stored in.
+	Bucket string `env:"BUCKET" yaml:"bucket"`
+
+	// AccessKeyID is the access key ID used to sign requests.
+	AccessKeyID string `env:"ACCESS_KEY_ID" yaml:"access_key_id"`
+
+	// SecretAccessKey is the secret access key used to sign requests.
+	SecretAccessKey string `env:"SECRET_ACCESS_KEY" yaml:"secret_access_key"`
+
+	// Prefix is prepended to the keys of all the objects.
+	Prefix string `env:"PREFIX" yaml:"prefix"`
+
+	// PathStyle is whether to use path-style URLs (endpoint/bucket/key)
+	// instead of virtual-hosted-style URLs (bucket.endpoint/key). MinIO
+	// usually requires path-style URLs.
+	PathStyle bool `env:"PATH_STYLE" yaml:"path_style"`
+}
+
+// PushConfig is the configuration for the limits enforced on pushes. These
+// can be overridden per repository.
+type PushConfig struct {
+	// MaxBlobSize is the maximum size of a single file in bytes.
+	// A value of 0 means no limit.
+	MaxBlobSize int64 `env:"MAX_BLOB_SIZE" yaml:"max_blob_size"`
+
+	// MaxPushSize is the maximum total size of the objects in a push in bytes.
+	// A value of 0 means no limit.
+	MaxPushSize int64 `env:"MAX_PUSH_SIZE" yaml:"max_push_size"`
+
+	// BannedExtensions is a list of file extensions that can't be pushed,
+	// unless they're stored using Git LFS.
+	BannedExtensions []string `env:"BANNED_EXTENSIONS" envSeparator:"," yaml:"banned_extensions"`
+
+	// AutoCreate is whether pushing to a repository that doesn't exist
+	// creates it, when the user is allowed to create repositories.
+	AutoCreate bool `env:"AUTO_CREATE" yaml:"auto_create"`
+
+	// AutoCreatePrivate is whether the repositories created on push are
+	// private.
+	AutoCreatePrivate bool `env:"AUTO_CREATE_PRIVATE" yaml:"auto_create_private"`
+}
+
+// TimestampConfig is the configuration for the RFC 3161 timestamps of tags.
+type TimestampConfig struct {
+	// URL is the URL of the timestamp authority. Tags aren't timestamped
+	// when it's empty.
+	URL string `env:"URL" yaml:"url"`
+
+	// CACertPath is the path to a PEM file of the certificates trusted to
+	// verify timestamps. The system roots are used when it's empty.
+	CACertPath string `env:"CA_CERT_PATH" yaml:"ca_cert_path"`
+}
+
+// LDAPConfig is the configuration for sourcing users from an LDAP directory.
+type LDAPConfig struct {
+	// Enabled is whether users are synced from the directory.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// URL is the URL of the directory, either ldap://host[:port] or
+	// ldaps://host[:port].
+	URL string `env:"URL" yaml:"url"`
+
+	// StartTLS is whether ldap:// connections are upgraded to TLS.
+	StartTLS bool `env:"START_TLS" yaml:"start_tls"`
+
+	// CACertPath is the path to a PEM file of the certificates trusted to
+	// verify the directory. The system roots are used when it's empty.
+	CACertPath string `env:"CA_CERT_PATH" yaml:"ca_cert_path"`
+
+	// BindDN is the DN of the account used to search the directory.
+	BindDN string `env:"BIND_DN" yaml:"bind_dn"`
+
+	// BindPassword is the password of the bind account.
+	BindPassword string `env:"BIND_PASSWORD" yaml:"bind_password"`
+
+	// BaseDN is the DN users are searched under.
+	BaseDN string `env:"BASE_DN" yaml:"base_dn"`
+
+	// UserFilter is the filter matching user entries.
+	UserFilter string `env:"USER_FILTER" yaml:"user_filter"`
+
+	// UsernameAttribute is the attribute holding usernames.
+	UsernameAttribute string `env:"USERNAME_ATTRIBUTE" yaml:"username_attribute"`
+
+	// PublicKeyAttribute is the attribute holding SSH public keys.
+	PublicKeyAttribute string `env:"PUBLIC_KEY_ATTRIBUTE" yaml:"public_key_attribute"`
+
+	// GroupAttribute is the attribute holding the DNs of the groups of a
+	// user.
+	GroupAttribute string `env:"GROUP_ATTRIBUTE" yaml:"group_attribute"`
+
+	// AdminGroups are the DNs of the groups whose members are admins.
+	AdminGroups []string `env:"ADMIN_GROUPS" envSeparator:";" yaml:"admin_groups"`
+
+	// UserGroups are the DNs of the groups whose members are synced. All
+	// users matching the filter are synced when it's empty.
+	UserGroups []string `env:"USER_GROUPS" envSeparator:";" yaml:"user_groups"`
+}
+
+// OIDCConfig is the configuration for logging in to the HTTP server with an
+// OpenID Connect provider.
+type OIDCConfig struct {
+	// Enabled is whether users can log in with the provider.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// Issuer is the issuer URL of the provider. The provider is discovered
+	// from it.
+	Issuer string `env:"ISSUER" yaml:"issuer"`
+
+	// ClientID is the ID of the client registered with the provider.
+	ClientID string `env:"CLIENT_ID" yaml:"client_id"`
+
+	// ClientSecret is the secret of the client.
+	ClientSecret string `env:"CLIENT_SECRET" yaml:"client_secret"`
+
+	// Scopes are the scopes requested besides "openid".
+	Scopes []string `env:"SCOPES" envSeparator:"," yaml:"scopes"`
+
+	// UsernameClaim is the ID token claim holding the username of an
+	// identity. It's only used in logs, identities log in as the user their
+	// subject is linked to.
+	UsernameClaim string `env:"USERNAME_CLAIM" yaml:"username_claim"`
+
+	// TokenExpiry is the number of seconds the access tokens issued on login
+	// are valid for.
+	TokenExpiry int `env:"TOKEN_EXPIRY" yaml:"token_expiry"`
+}
+
+// IPAccessConfig is the configuration for the addresses allowed to connect
+// to the server. These apply to all the listeners, and can be restricted
+// further per user.
+type IPAccessConfig struct {
+	// Allow is a list of CIDRs, or single addresses, allowed to connect. All
+	// addresses are allowed when it's empty.
+	Allow []string `env:"ALLOW" envSeparator:"," yaml:"allow"`
+
+	// Deny is a list of CIDRs, or single addresses, that can't connect, even
+	// if they're allowed.
+	Deny []string `env:"DENY" envSeparator:"," yaml:"deny"`
+}
+
+// RateLimit is a token bucket rate limit.
+type RateLimit struct {
+	// PerMinute is the number of requests allowed per minute on average.
+	// A value of 0 means no limit.
+	PerMinute int `env:"PER_MINUTE" yaml:"per_minute"`
+
+	// Burst is the number of requests allowed at once.
+	Burst int `env:"BURST" yaml:"burst"`
+}
+
+// RateLimits are the rate limits of a client.
+type RateLimits struct {
+	// SSHSessions is the limit of SSH sessions, commands and TUI included.
+	SSHSessions RateLimit `envPrefix:"SSH_SESSIONS_" yaml:"ssh_sessions"`
+
+	// GitOperations is the limit of clones, fetches, and pushes over all the
+	// protocols.
+	GitOperations RateLimit `envPrefix:"GIT_OPERATIONS_" yaml:"git_operations"`
+
+	// HTTPRequests is the limit of HTTP requests.
+	HTTPRequests RateLimit `envPrefix:"HTTP_REQUESTS_" yaml:"http_requests"`
+}
+
+// RateLimitConfig is the configuration for the rate limits of clients.
+type RateLimitConfig struct {
+	// User are the limits of each user.
+	User RateLimits `envPrefix:"USER_" yaml:"user"`
+
+	// IP are the limits of each address, whether the client is a user or not.
+	IP RateLimits `envPrefix:"IP_" yaml:"ip"`
+}
+
+// WorkersConfig is the configuration for the pool of workers running git
+// operations, i.e. clones, fetches, and pushes, and the git queries of the
+// TUI.
+type WorkersConfig struct {
+	// Size is the maximum number of git operations running at once.
+	// A value of 0 means no limit.
+	Size int `env:"SIZE" yaml:"size"`
+
+	// PerRepo is the maximum number of git operations running at once on a
+	// repository. A value of 0 means no limit.
+	PerRepo int `env:"PER_REPO" yaml:"per_repo"`
+
+	// Queue is the maximum number of git operations waiting for a worker.
+	// Operations are rejected when the queue is full. A value of 0 means no
+	// limit.
+	Queue int `env:"QUEUE" yaml:"queue"`
+
+	// QueueTimeout is the maximum number of seconds an operation waits for a
+	// worker. A value of 0 means no timeout.
+	QueueTimeout int `env:"QUEUE_TIMEOUT" yaml:"queue_timeout"`
+}
+
+// CacheConfig is the configuration for the cache of the responses to clones
+// and fetches, and of commonly read git objects.
+type CacheConfig struct {
+	// Enabled is whether or not the cache is enabled.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// Size is the maximum size of the in-memory cache in bytes.
+	Size int64 `env:"SIZE" yaml:"size"`
+
+	// MaxPackSize is the maximum size of a cached clone or fetch response in
+	// bytes. Larger responses aren't cached. A value of 0 means no limit.
+	MaxPackSize int64 `env:"MAX_PACK_SIZE" yaml:"max_pack_size"`
+
+	// Redis is the configuration for a Redis server the cache is stored in,
+	// to share it between servers. The cache is kept in memory when the
+	// address is empty.
+	Redis RedisConfig `envPrefix:"REDIS_" yaml:"redis"`
+}
+
+// RedisConfig is the configuration for a Redis server.
+type RedisConfig struct {
+	// Addr is the address of the server, host:port.
+	Addr string `env:"ADDR" yaml:"addr"`
+
+	// Password is the password of the server, if any.
+	Password string `env:"PASSWORD" yaml:"password"`
+
+	// DB is the database number.
+	DB int `env:"DB" yaml:"db"`
+
+	// TTL is the number of seconds keys are kept. A value of 0 means keys
+	// are kept until they're evicted by the server.
+	TTL int `env:"TTL" yaml:"ttl"`
+}
+
+// AvatarConfig is the configuration for the avatars of users.
+type AvatarConfig struct {
+	// Provider is the service the avatars of users without an uploaded image
+	// are fetched from. It can be "gravatar", "libravatar", or "none" to only
+	// show uploaded images and initials.
+	Provider string `env:"PROVIDER" yaml:"provider"`
+
+	// MaxSize is the maximum size of an uploaded image in bytes. A value of 0
+	// disables uploads.
+	MaxSize int64 `env:"MAX_SIZE" yaml:"max_size"`
+}
+
+// ReleasesConfig is the configuration for the releases of repositories.
+type ReleasesConfig struct {
+	// MaxAssetSize is the maximum size of an uploaded release asset in bytes.
+	// A value of 0 disables uploads.
+	MaxAssetSize int64 `env:"MAX_ASSET_SIZE" yaml:"max_asset_size"`
+}
+
+// RepoHooksConfig is the configuration for the hook scripts admins install
+// in repositories with repo hooks set.
+type RepoHooksConfig struct {
+	// Timeout is the number of seconds a hook script runs before it's
+	// killed, at least 1.
+	Timeout int `env:"TIMEOUT" yaml:"timeout"`
+
+	// MaxMemory is the maximum virtual memory of a hook script in bytes. A
+	// value of 0 means no limit.
+	MaxMemory int64 `env:"MAX_MEMORY" yaml:"max_memory"`
+
+	// MaxOutput is the number of bytes of the output of a run kept in its
+	// log.
+	MaxOutput int `env:"MAX_OUTPUT" yaml:"max_output"`
+
+	// Logs is the number of runs logged per repository.
+	Logs int `env:"LOGS" yaml:"logs"`
+}
+
+// JobsConfig is the configuration for the persistent job queue running the
+// webhook deliveries, the mirror updates, and the LFS pruning.
+type JobsConfig struct {
+	// Workers is the number of jobs running at once, at least 1.
+	Workers int `env:"WORKERS" yaml:"workers"`
+
+	// MaxAttempts is the number of times a job is attempted before it's
+	// marked as dead, at least 1.
+	MaxAttempts int `env:"MAX_ATTEMPTS" yaml:"max_attempts"`
+}
+
+// QuotaConfig is the configuration for the default disk quotas, the space the
+// git objects and the Git LFS objects of repositories take. These can be
+// overridden per user and per repository.
+type QuotaConfig struct {
+	// User is the maximum disk usage of the repositories of a user in bytes.
+	// A value of 0 means no limit.
+	User int64 `env:"USER" yaml:"user"`
+
+	// Repo is the maximum disk usage of a repository in bytes. A value of 0
+	// means no limit.
+	Repo int64 `env:"REPO" yaml:"repo"`
+}
+
+// HAConfig is the configuration for running multiple servers behind a load
+// balancer, with a shared repository storage and a shared database.
+type HAConfig struct {
+	// Enabled locks the repositories through the database while they're
+	// pushed to, created, renamed, or removed, and while their hooks run, so
+	// that the servers don't step on each other.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// LockTTL is the number of seconds a lock is held for without being
+	// renewed, i.e. when the server holding it stops.
+	LockTTL int `env:"LOCK_TTL" yaml:"lock_ttl"`
+
+	// LockTimeout is the number of seconds to wait for a lock, 0 waits as
+	// long as the request lasts.
+	LockTimeout int `env:"LOCK_TIMEOUT" yaml:"lock_timeout"`
+}
+
+// ReplicaConfig is the configuration for running the server as a read-only
+// replica of a primary server. The replica reads the users, permissions, and
+// repositories from the database of the primary, or a read-only copy of it,
+// and syncs the repositories from the primary.
+type ReplicaConfig struct {
+	// Enabled runs the server as a replica.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// Primary is the HTTP URL of the primary server. Pushes over HTTP are
+	// proxied to it.
+	Primary string `env:"PRIMARY" yaml:"primary"`
+
+	// Token is an access token of an admin of the primary, the
+	// repositories are fetched with it.
+	Token string `env:"TOKEN" yaml:"token"`
+
+	// SyncInterval is the number of seconds between two syncs of the
+	// repositories.
+	SyncInterval int `env:"SYNC_INTERVAL" yaml:"sync_interval"`
 }
 
 // Config is the configuration for Soft Serve.
@@ -122,6 +581,9 @@ type Config struct {
 	// Stats is the configuration for the stats server.
 	Stats StatsConfig `envPrefix:"STATS_" yaml:"stats"`
 
+	// Tracing is the OpenTelemetry tracing configuration.
+	Tracing TracingConfig `envPrefix:"TRACING_" yaml:"tracing"`
+
 	// Log is the logger configuration.
 	Log LogConfig `envPrefix:"LOG_" yaml:"log"`
 
@@ -131,11 +593,83 @@ type Config struct {
 	// LFS is the configuration for Git LFS.
 	LFS LFSConfig `envPrefix:"LFS_" yaml:"lfs"`
 
+	// Push is the configuration for the push limits.
+	Push PushConfig `envPrefix:"PUSH_" yaml:"push"`
+
+	// Quota is the configuration for the default disk quotas.
+	Quota QuotaConfig `envPrefix:"QUOTA_" yaml:"quota"`
+
+	// Timestamp is the configuration for the timestamps of tags.
+	Timestamp TimestampConfig `envPrefix:"TIMESTAMP_" yaml:"timestamp"`
+
+	// LDAP is the configuration for syncing users from an LDAP directory.
+	LDAP LDAPConfig `envPrefix:"LDAP_" yaml:"ldap"`
+
+	// OIDC is the configuration for logging in with an OpenID Connect
+	// provider.
+	OIDC OIDCConfig `envPrefix:"OIDC_" yaml:"oidc"`
+
+	// IPAccess is the configuration for the addresses allowed to connect.
+	IPAccess IPAccessConfig `envPrefix:"IP_ACCESS_" yaml:"ip_access"`
+
+	// RateLimit is the configuration for the rate limits of clients.
+	RateLimit RateLimitConfig `envPrefix:"RATE_LIMIT_" yaml:"rate_limit"`
+
+	// Workers is the configuration for the pool of workers running git
+	// operations.
+	Workers WorkersConfig `envPrefix:"WORKERS_" yaml:"workers"`
+
+	// Cache is the configuration for the cache of git packs and objects.
+	Cache CacheConfig `envPrefix:"CACHE_" yaml:"cache"`
+
+	// Redis is the configuration for a Redis server the repository metadata
+	// cache and the events are shared through, to run multiple servers with
+	// the same database. The metadata cache is kept in memory, and the events
+	// stay in the server, when the address is empty.
+	Redis RedisConfig `envPrefix:"REDIS_" yaml:"redis"`
+
+	// Avatar is the configuration for the avatars of users.
+	Avatar AvatarConfig `envPrefix:"AVATAR_" yaml:"avatar"`
+
+	// Releases is the configuration for the releases of repositories.
+	Releases ReleasesConfig `envPrefix:"RELEASES_" yaml:"releases"`
+
+	// RepoHooks is the configuration for the hook scripts of repositories.
+	RepoHooks RepoHooksConfig `envPrefix:"REPO_HOOKS_" yaml:"repo_hooks"`
+
+	// Jobs is the configuration for the persistent job queue.
+	Jobs JobsConfig `envPrefix:"JOBS_" yaml:"jobs"`
+
+	// HA is the configuration for running multiple servers.
+	HA HAConfig `envPrefix:"HA_" yaml:"ha"`
+
+	// Replica is the configuration for running the server as a read-only
+	// replica.
+	Replica ReplicaConfig `envPrefix:"REPLICA_" yaml:"replica"`
+
+	// RepoPathTemplate is the text/template of the paths of the
+	// repositories in the repos directory, see RepoPathData. It defaults to
+	// FlatRepoPathTemplate.
+	RepoPathTemplate string `env:"REPO_PATH_TEMPLATE" yaml:"repo_path_template"`
+
+	// IdempotencyWindow is the number of seconds the results of requests made
+	// with an idempotency key are kept and replayed on retries.
+	IdempotencyWindow int `env:"IDEMPOTENCY_WINDOW" yaml:"idempotency_window"`
+
+	// ShutdownTimeout is the number of seconds in-flight git transfers and
+	// TUI sessions are given to finish when the server shuts down, before
+	// their connections are closed.
+	ShutdownTimeout int `env:"SHUTDOWN_TIMEOUT" yaml:"shutdown_timeout"`
+
 	// InitialAdminKeys is a list of public keys that will be added to the list of admins.
 	InitialAdminKeys []string `env:"INITIAL_ADMIN_KEYS" envSeparator:"\n" yaml:"initial_admin_keys"`
 
 	// DataPath is the path to the directory where Soft Serve will store its data.
 	DataPath string `env:"DATA_PATH" yaml:"-"`
+
+	// reloaded is the configuration with the settings reloaded while the
+	// server runs, see Reload.
+	reloaded *atomic.Pointer[Config]
 }
 
 // Environ returns the config as a list of environment variables.
@@ -144,6 +678,7 @@ func (c *Config) Environ() []string {
 	if c == nil {
 		return envs
 	}
+	c = c.Current()
 
 	// TODO: do this dynamically
 	envs = append(envs, []string{
@@ -153,24 +688,134 @@ func (c *Config) Environ() []string {
 		fmt.Sprintf("SOFT_SERVE_SSH_LISTEN_ADDR=%s", c.SSH.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_SSH_PUBLIC_URL=%s", c.SSH.PublicURL),
 		fmt.Sprintf("SOFT_SERVE_SSH_KEY_PATH=%s", c.SSH.KeyPath),
+		fmt.Sprintf("SOFT_SERVE_SSH_KEY_PATHS=%s", strings.Join(c.SSH.KeyPaths, ",")),
 		fmt.Sprintf("SOFT_SERVE_SSH_CLIENT_KEY_PATH=%s", c.SSH.ClientKeyPath),
 		fmt.Sprintf("SOFT_SERVE_SSH_MAX_TIMEOUT=%d", c.SSH.MaxTimeout),
 		fmt.Sprintf("SOFT_SERVE_SSH_IDLE_TIMEOUT=%d", c.SSH.IdleTimeout),
+		fmt.Sprintf("SOFT_SERVE_SSH_TRUSTED_USER_CA_KEYS=%s", strings.Join(c.SSH.TrustedUserCAKeys, "\n")),
 		fmt.Sprintf("SOFT_SERVE_GIT_LISTEN_ADDR=%s", c.Git.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_GIT_MAX_TIMEOUT=%d", c.Git.MaxTimeout),
 		fmt.Sprintf("SOFT_SERVE_GIT_IDLE_TIMEOUT=%d", c.Git.IdleTimeout),
 		fmt.Sprintf("SOFT_SERVE_GIT_MAX_CONNECTIONS=%d", c.Git.MaxConnections),
+		fmt.Sprintf("SOFT_SERVE_GIT_DAEMON_EXPORT=%t", c.Git.DaemonExport),
 		fmt.Sprintf("SOFT_SERVE_HTTP_LISTEN_ADDR=%s", c.HTTP.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_HTTP_TLS_KEY_PATH=%s", c.HTTP.TLSKeyPath),
 		fmt.Sprintf("SOFT_SERVE_HTTP_TLS_CERT_PATH=%s", c.HTTP.TLSCertPath),
 		fmt.Sprintf("SOFT_SERVE_HTTP_PUBLIC_URL=%s", c.HTTP.PublicURL),
+		fmt.Sprintf("SOFT_SERVE_HTTP_GO_IMPORT_DOMAIN=%s", c.HTTP.GoImportDomain),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ACME_ENABLED=%t", c.HTTP.ACME.Enabled),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ACME_DOMAINS=%s", strings.Join(c.HTTP.ACME.Domains, ",")),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ACME_EMAIL=%s", c.HTTP.ACME.Email),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ACME_DIRECTORY_URL=%s", c.HTTP.ACME.DirectoryURL),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ACME_CACHE_DIR=%s", c.HTTP.ACME.CacheDir),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ACME_CHALLENGE_LISTEN_ADDR=%s", c.HTTP.ACME.ChallengeListenAddr),
 		fmt.Sprintf("SOFT_SERVE_STATS_LISTEN_ADDR=%s", c.Stats.ListenAddr),
+		fmt.Sprintf("SOFT_SERVE_STATS_MAX_REPO_LABELS=%d", c.Stats.MaxRepoLabels),
+		fmt.Sprintf("SOFT_SERVE_TRACING_ENABLED=%t", c.Tracing.Enabled),
+		fmt.Sprintf("SOFT_SERVE_TRACING_ENDPOINT=%s", c.Tracing.Endpoint),
+		fmt.Sprintf("SOFT_SERVE_TRACING_INSECURE=%t", c.Tracing.Insecure),
+		fmt.Sprintf("SOFT_SERVE_TRACING_SERVICE_NAME=%s", c.Tracing.ServiceName),
+		fmt.Sprintf("SOFT_SERVE_TRACING_SAMPLE_RATIO=%g", c.Tracing.SampleRatio),
 		fmt.Sprintf("SOFT_SERVE_LOG_FORMAT=%s", c.Log.Format),
 		fmt.Sprintf("SOFT_SERVE_LOG_TIME_FORMAT=%s", c.Log.TimeFormat),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVEL=%s", c.Log.Level),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVELS_SSH=%s", c.Log.Levels.SSH),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVELS_GIT=%s", c.Log.Levels.Git),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVELS_HTTP=%s", c.Log.Levels.HTTP),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVELS_UI=%s", c.Log.Levels.UI),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVELS_DB=%s", c.Log.Levels.DB),
+		fmt.Sprintf("SOFT_SERVE_LOG_TRANSPORT_SAMPLING=%d", c.Log.TransportSampling),
 		fmt.Sprintf("SOFT_SERVE_DB_DRIVER=%s", c.DB.Driver),
 		fmt.Sprintf("SOFT_SERVE_DB_DATA_SOURCE=%s", c.DB.DataSource),
+		fmt.Sprintf("SOFT_SERVE_DB_AUTO_MIGRATE=%t", c.DB.AutoMigrate),
 		fmt.Sprintf("SOFT_SERVE_LFS_ENABLED=%t", c.LFS.Enabled),
 		fmt.Sprintf("SOFT_SERVE_LFS_SSH_ENABLED=%t", c.LFS.SSHEnabled),
+		fmt.Sprintf("SOFT_SERVE_LFS_STORAGE=%s", c.LFS.Storage),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_ENDPOINT=%s", c.LFS.S3.Endpoint),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_REGION=%s", c.LFS.S3.Region),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_BUCKET=%s", c.LFS.S3.Bucket),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_ACCESS_KEY_ID=%s", c.LFS.S3.AccessKeyID),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_SECRET_ACCESS_KEY=%s", c.LFS.S3.SecretAccessKey),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_PREFIX=%s", c.LFS.S3.Prefix),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_PATH_STYLE=%t", c.LFS.S3.PathStyle),
+		fmt.Sprintf("SOFT_SERVE_LFS_PRUNE_RETENTION_DAYS=%d", c.LFS.PruneRetentionDays),
+		fmt.Sprintf("SOFT_SERVE_PUSH_MAX_BLOB_SIZE=%d", c.Push.MaxBlobSize),
+		fmt.Sprintf("SOFT_SERVE_PUSH_MAX_PUSH_SIZE=%d", c.Push.MaxPushSize),
+		fmt.Sprintf("SOFT_SERVE_QUOTA_USER=%d", c.Quota.User),
+		fmt.Sprintf("SOFT_SERVE_QUOTA_REPO=%d", c.Quota.Repo),
+		fmt.Sprintf("SOFT_SERVE_PUSH_BANNED_EXTENSIONS=%s", strings.Join(c.Push.BannedExtensions, ",")),
+		fmt.Sprintf("SOFT_SERVE_PUSH_AUTO_CREATE=%t", c.Push.AutoCreate),
+		fmt.Sprintf("SOFT_SERVE_PUSH_AUTO_CREATE_PRIVATE=%t", c.Push.AutoCreatePrivate),
+		fmt.Sprintf("SOFT_SERVE_TIMESTAMP_URL=%s", c.Timestamp.URL),
+		fmt.Sprintf("SOFT_SERVE_TIMESTAMP_CA_CERT_PATH=%s", c.Timestamp.CACertPath),
+		fmt.Sprintf("SOFT_SERVE_LDAP_ENABLED=%t", c.LDAP.Enabled),
+		fmt.Sprintf("SOFT_SERVE_LDAP_URL=%s", c.LDAP.URL),
+		fmt.Sprintf("SOFT_SERVE_LDAP_START_TLS=%t", c.LDAP.StartTLS),
+		fmt.Sprintf("SOFT_SERVE_LDAP_CA_CERT_PATH=%s", c.LDAP.CACertPath),
+		fmt.Sprintf("SOFT_SERVE_LDAP_BIND_DN=%s", c.LDAP.BindDN),
+		fmt.Sprintf("SOFT_SERVE_LDAP_BIND_PASSWORD=%s", c.LDAP.BindPassword),
+		fmt.Sprintf("SOFT_SERVE_LDAP_BASE_DN=%s", c.LDAP.BaseDN),
+		fmt.Sprintf("SOFT_SERVE_LDAP_USER_FILTER=%s", c.LDAP.UserFilter),
+		fmt.Sprintf("SOFT_SERVE_LDAP_USERNAME_ATTRIBUTE=%s", c.LDAP.UsernameAttribute),
+		fmt.Sprintf("SOFT_SERVE_LDAP_PUBLIC_KEY_ATTRIBUTE=%s", c.LDAP.PublicKeyAttribute),
+		fmt.Sprintf("SOFT_SERVE_LDAP_GROUP_ATTRIBUTE=%s", c.LDAP.GroupAttribute),
+		fmt.Sprintf("SOFT_SERVE_LDAP_ADMIN_GROUPS=%s", strings.Join(c.LDAP.AdminGroups, ";")),
+		fmt.Sprintf("SOFT_SERVE_LDAP_USER_GROUPS=%s", strings.Join(c.LDAP.UserGroups, ";")),
+		fmt.Sprintf("SOFT_SERVE_OIDC_ENABLED=%t", c.OIDC.Enabled),
+		fmt.Sprintf("SOFT_SERVE_OIDC_ISSUER=%s", c.OIDC.Issuer),
+		fmt.Sprintf("SOFT_SERVE_OIDC_CLIENT_ID=%s", c.OIDC.ClientID),
+		fmt.Sprintf("SOFT_SERVE_OIDC_CLIENT_SECRET=%s", c.OIDC.ClientSecret),
+		fmt.Sprintf("SOFT_SERVE_OIDC_SCOPES=%s", strings.Join(c.OIDC.Scopes, ",")),
+		fmt.Sprintf("SOFT_SERVE_OIDC_USERNAME_CLAIM=%s", c.OIDC.UsernameClaim),
+		fmt.Sprintf("SOFT_SERVE_OIDC_TOKEN_EXPIRY=%d", c.OIDC.TokenExpiry),
+		fmt.Sprintf("SOFT_SERVE_IP_ACCESS_ALLOW=%s", strings.Join(c.IPAccess.Allow, ",")),
+		fmt.Sprintf("SOFT_SERVE_IP_ACCESS_DENY=%s", strings.Join(c.IPAccess.Deny, ",")),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_USER_SSH_SESSIONS_PER_MINUTE=%d", c.RateLimit.User.SSHSessions.PerMinute),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_USER_SSH_SESSIONS_BURST=%d", c.RateLimit.User.SSHSessions.Burst),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_USER_GIT_OPERATIONS_PER_MINUTE=%d", c.RateLimit.User.GitOperations.PerMinute),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_USER_GIT_OPERATIONS_BURST=%d", c.RateLimit.User.GitOperations.Burst),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_USER_HTTP_REQUESTS_PER_MINUTE=%d", c.RateLimit.User.HTTPRequests.PerMinute),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_USER_HTTP_REQUESTS_BURST=%d", c.RateLimit.User.HTTPRequests.Burst),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_IP_SSH_SESSIONS_PER_MINUTE=%d", c.RateLimit.IP.SSHSessions.PerMinute),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_IP_SSH_SESSIONS_BURST=%d", c.RateLimit.IP.SSHSessions.Burst),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_IP_GIT_OPERATIONS_PER_MINUTE=%d", c.RateLimit.IP.GitOperations.PerMinute),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_IP_GIT_OPERATIONS_BURST=%d", c.RateLimit.IP.GitOperations.Burst),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_IP_HTTP_REQUESTS_PER_MINUTE=%d", c.RateLimit.IP.HTTPRequests.PerMinute),
+		fmt.Sprintf("SOFT_SERVE_RATE_LIMIT_IP_HTTP_REQUESTS_BURST=%d", c.RateLimit.IP.HTTPRequests.Burst),
+		fmt.Sprintf("SOFT_SERVE_WORKERS_SIZE=%d", c.Workers.Size),
+		fmt.Sprintf("SOFT_SERVE_WORKERS_PER_REPO=%d", c.Workers.PerRepo),
+		fmt.Sprintf("SOFT_SERVE_WORKERS_QUEUE=%d", c.Workers.Queue),
+		fmt.Sprintf("SOFT_SERVE_WORKERS_QUEUE_TIMEOUT=%d", c.Workers.QueueTimeout),
+		fmt.Sprintf("SOFT_SERVE_CACHE_ENABLED=%t", c.Cache.Enabled),
+		fmt.Sprintf("SOFT_SERVE_CACHE_SIZE=%d", c.Cache.Size),
+		fmt.Sprintf("SOFT_SERVE_CACHE_MAX_PACK_SIZE=%d", c.Cache.MaxPackSize),
+		fmt.Sprintf("SOFT_SERVE_CACHE_REDIS_ADDR=%s", c.Cache.Redis.Addr),
+		fmt.Sprintf("SOFT_SERVE_CACHE_REDIS_PASSWORD=%s", c.Cache.Redis.Password),
+		fmt.Sprintf("SOFT_SERVE_CACHE_REDIS_DB=%d", c.Cache.Redis.DB),
+		fmt.Sprintf("SOFT_SERVE_CACHE_REDIS_TTL=%d", c.Cache.Redis.TTL),
+		fmt.Sprintf("SOFT_SERVE_REDIS_ADDR=%s", c.Redis.Addr),
+		fmt.Sprintf("SOFT_SERVE_REDIS_PASSWORD=%s", c.Redis.Password),
+		fmt.Sprintf("SOFT_SERVE_REDIS_DB=%d", c.Redis.DB),
+		fmt.Sprintf("SOFT_SERVE_REDIS_TTL=%d", c.Redis.TTL),
+		fmt.Sprintf("SOFT_SERVE_AVATAR_PROVIDER=%s", c.Avatar.Provider),
+		fmt.Sprintf("SOFT_SERVE_AVATAR_MAX_SIZE=%d", c.Avatar.MaxSize),
+		fmt.Sprintf("SOFT_SERVE_RELEASES_MAX_ASSET_SIZE=%d", c.Releases.MaxAssetSize),
+		fmt.Sprintf("SOFT_SERVE_REPO_HOOKS_TIMEOUT=%d", c.RepoHooks.Timeout),
+		fmt.Sprintf("SOFT_SERVE_REPO_HOOKS_MAX_MEMORY=%d", c.RepoHooks.MaxMemory),
+		fmt.Sprintf("SOFT_SERVE_REPO_HOOKS_MAX_OUTPUT=%d", c.RepoHooks.MaxOutput),
+		fmt.Sprintf("SOFT_SERVE_REPO_HOOKS_LOGS=%d", c.RepoHooks.Logs),
+		fmt.Sprintf("SOFT_SERVE_JOBS_WORKERS=%d", c.Jobs.Workers),
+		fmt.Sprintf("SOFT_SERVE_JOBS_MAX_ATTEMPTS=%d", c.Jobs.MaxAttempts),
+		fmt.Sprintf("SOFT_SERVE_HA_ENABLED=%t", c.HA.Enabled),
+		fmt.Sprintf("SOFT_SERVE_HA_LOCK_TTL=%d", c.HA.LockTTL),
+		fmt.Sprintf("SOFT_SERVE_HA_LOCK_TIMEOUT=%d", c.HA.LockTimeout),
+		fmt.Sprintf("SOFT_SERVE_REPLICA_ENABLED=%t", c.Replica.Enabled),
+		fmt.Sprintf("SOFT_SERVE_REPLICA_PRIMARY=%s", c.Replica.Primary),
+		fmt.Sprintf("SOFT_SERVE_REPLICA_TOKEN=%s", c.Replica.Token),
+		fmt.Sprintf("SOFT_SERVE_REPLICA_SYNC_INTERVAL=%d", c.Replica.SyncInterval),
+		fmt.Sprintf("SOFT_SERVE_REPO_PATH_TEMPLATE=%s", c.RepoPathTemplate),
+		fmt.Sprintf("SOFT_SERVE_IDEMPOTENCY_WINDOW=%d", c.IdempotencyWindow),
+		fmt.Sprintf("SOFT_SERVE_SHUTDOWN_TIMEOUT=%d", c.ShutdownTimeout),
 	}...)
 
 	return envs
@@ -247,6 +892,46 @@ func (c *Config) Parse() error {
 	return c.ParseEnv()
 }
 
+// Reload replaces the settings that can change while the server runs with
+// the ones of cfg: the server name, the log levels, the authentication
+// settings, the IP access rules, the rate limits, the avatars, and the push
+// settings. The other settings, like the listen addresses, take effect when
+// the server restarts.
+//
+// The configuration itself isn't changed, the reloaded settings are read with
+// Current while the server runs.
+func (c *Config) Reload(cfg *Config) {
+	r := *c.Current()
+	r.Name = cfg.Name
+	r.Log.Level = cfg.Log.Level
+	r.Log.Levels = cfg.Log.Levels
+	r.Log.TransportSampling = cfg.Log.TransportSampling
+	r.SSH.TrustedUserCAKeys = cfg.SSH.TrustedUserCAKeys
+	r.LDAP = cfg.LDAP
+	r.OIDC = cfg.OIDC
+	r.IPAccess = cfg.IPAccess
+	r.RateLimit = cfg.RateLimit
+	r.Avatar = cfg.Avatar
+	r.Push = cfg.Push
+	if c.reloaded == nil {
+		c.reloaded = new(atomic.Pointer[Config])
+		r.reloaded = c.reloaded
+	}
+	c.reloaded.Store(&r)
+}
+
+// Current returns the configuration with the settings reloaded while the
+// server runs, or the configuration itself if it was never reloaded. The
+// returned configuration must not be changed.
+func (c *Config) Current() *Config {
+	if c.reloaded != nil {
+		if r := c.reloaded.Load(); r != nil {
+			return r
+		}
+	}
+	return c
+}
+
 // writeConfig writes the configuration to the given file.
 func writeConfig(cfg *Config, path string) error {
 	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
@@ -294,6 +979,7 @@ func DefaultConfig() *Config {
 	return &Config{
 		Name:     "Soft Serve",
 		DataPath: DefaultDataPath(),
+		reloaded: new(atomic.Pointer[Config]),
 		SSH: SSHConfig{
 			ListenAddr:    ":23231",
 			PublicURL:     "ssh://localhost:23231",
@@ -307,27 +993,101 @@ func DefaultConfig() *Config {
 			MaxTimeout:     0,
 			IdleTimeout:    3,
 			MaxConnections: 32,
+			DaemonExport:   true,
 		},
 		HTTP: HTTPConfig{
 			ListenAddr: ":23232",
 			PublicURL:  "http://localhost:23232",
+			ACME: ACMEConfig{
+				DirectoryURL: "https://acme-v02.api.letsencrypt.org/directory",
+				CacheDir:     "acme",
+			},
 		},
 		Stats: StatsConfig{
-			ListenAddr: "localhost:23233",
+			ListenAddr:    "localhost:23233",
+			MaxRepoLabels: 100,
+		},
+		Tracing: TracingConfig{
+			Endpoint:    "localhost:4318",
+			ServiceName: "soft-serve",
+			SampleRatio: 1,
 		},
 		Log: LogConfig{
 			Format:     "text",
 			TimeFormat: time.DateTime,
+			Level:      "info",
 		},
 		DB: DBConfig{
 			Driver: "sqlite",
 			DataSource: "soft-serve.db" +
 				"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)",
+			AutoMigrate: true,
 		},
 		LFS: LFSConfig{
-			Enabled:    true,
-			SSHEnabled: true,
+			Enabled:            true,
+			SSHEnabled:         true,
+			Storage:            "local",
+			PruneRetentionDays: 7,
+		},
+		Push: PushConfig{
+			AutoCreate:        true,
+			AutoCreatePrivate: true,
+		},
+		LDAP: LDAPConfig{
+			UserFilter:         "(objectClass=person)",
+			UsernameAttribute:  "uid",
+			PublicKeyAttribute: "sshPublicKey",
+			GroupAttribute:     "memberOf",
+		},
+		OIDC: OIDCConfig{
+			Scopes:        []string{"profile", "email"},
+			UsernameClaim: "preferred_username",
+			TokenExpiry:   60 * 60, // 1 hour
 		},
+		Workers: WorkersConfig{
+			Size:         32,
+			PerRepo:      8,
+			Queue:        128,
+			QueueTimeout: 60,
+		},
+		Cache: CacheConfig{
+			Enabled:     true,
+			Size:        64 << 20, // 64 MiB
+			MaxPackSize: 8 << 20,  // 8 MiB
+			Redis: RedisConfig{
+				TTL: 60 * 60, // 1 hour
+			},
+		},
+		Redis: RedisConfig{
+			TTL: 60 * 60, // 1 hour
+		},
+		Avatar: AvatarConfig{
+			Provider: "none",
+			MaxSize:  1 << 20, // 1 MiB
+		},
+		Releases: ReleasesConfig{
+			MaxAssetSize: 512 << 20, // 512 MiB
+		},
+		RepoHooks: RepoHooksConfig{
+			Timeout:   30,
+			MaxMemory: 1 << 30,  // 1 GiB
+			MaxOutput: 64 << 10, // 64 KiB
+			Logs:      100,
+		},
+		Jobs: JobsConfig{
+			Workers:     4,
+			MaxAttempts: 5,
+		},
+		HA: HAConfig{
+			LockTTL:     30,
+			LockTimeout: 120,
+		},
+		Replica: ReplicaConfig{
+			SyncInterval: 60,
+		},
+		RepoPathTemplate:  FlatRepoPathTemplate,
+		IdempotencyWindow: 24 * 60 * 60, // 24 hours
+		ShutdownTimeout:   30,
 	}
 }
 
@@ -345,11 +1105,18 @@ func (c *Config) Validate() error {
 
 	c.SSH.PublicURL = strings.TrimSuffix(c.SSH.PublicURL, "/")
 	c.HTTP.PublicURL = strings.TrimSuffix(c.HTTP.PublicURL, "/")
+	c.HTTP.GoImportDomain = strings.Trim(c.HTTP.GoImportDomain, "/")
 
 	if c.SSH.KeyPath != "" && !filepath.IsAbs(c.SSH.KeyPath) {
 		c.SSH.KeyPath = filepath.Join(c.DataPath, c.SSH.KeyPath)
 	}
 
+	for i, p := range c.SSH.KeyPaths {
+		if !filepath.IsAbs(p) {
+			c.SSH.KeyPaths[i] = filepath.Join(c.DataPath, p)
+		}
+	}
+
 	if c.SSH.ClientKeyPath != "" && !filepath.IsAbs(c.SSH.ClientKeyPath) {
 		c.SSH.ClientKeyPath = filepath.Join(c.DataPath, c.SSH.ClientKeyPath)
 	}
@@ -362,10 +1129,182 @@ func (c *Config) Validate() error {
 		c.HTTP.TLSCertPath = filepath.Join(c.DataPath, c.HTTP.TLSCertPath)
 	}
 
+	if c.HTTP.ACME.CacheDir != "" && !filepath.IsAbs(c.HTTP.ACME.CacheDir) {
+		c.HTTP.ACME.CacheDir = filepath.Join(c.DataPath, c.HTTP.ACME.CacheDir)
+	}
+
+	if c.Timestamp.CACertPath != "" && !filepath.IsAbs(c.Timestamp.CACertPath) {
+		c.Timestamp.CACertPath = filepath.Join(c.DataPath, c.Timestamp.CACertPath)
+	}
+
+	if c.LDAP.CACertPath != "" && !filepath.IsAbs(c.LDAP.CACertPath) {
+		c.LDAP.CACertPath = filepath.Join(c.DataPath, c.LDAP.CACertPath)
+	}
+
+	for name, addrs := range map[string]string{
+		"ssh":   c.SSH.ListenAddr,
+		"git":   c.Git.ListenAddr,
+		"http":  c.HTTP.ListenAddr,
+		"stats": c.Stats.ListenAddr,
+	} {
+		if err := listener.ValidateAddrs(addrs); err != nil {
+			return fmt.Errorf("%s listen address: %w", name, err)
+		}
+	}
+
+	if strings.Contains(c.HTTP.GoImportDomain, "://") || strings.ContainsAny(c.HTTP.GoImportDomain, " \t\"'<>") {
+		return errors.New("go import domain must be a host and an optional path")
+	}
+
+	if c.HTTP.ACME.Enabled {
+		if c.HTTP.TLSKeyPath != "" || c.HTTP.TLSCertPath != "" {
+			return errors.New("acme can't be used with a tls key and certificate")
+		}
+		if len(c.HTTP.ACME.Domains) == 0 {
+			if u, err := url.Parse(c.HTTP.PublicURL); err == nil && u.Hostname() != "" {
+				c.HTTP.ACME.Domains = []string{u.Hostname()}
+			}
+		}
+		if len(c.HTTP.ACME.Domains) == 0 {
+			return errors.New("acme requires a domain or the http public url")
+		}
+		if c.HTTP.ACME.DirectoryURL == "" || c.HTTP.ACME.CacheDir == "" {
+			return errors.New("acme requires a directory url and a cache directory")
+		}
+	}
+
+	if c.LDAP.Enabled && (c.LDAP.URL == "" || c.LDAP.BaseDN == "") {
+		return errors.New("ldap requires a url and a base dn")
+	}
+
+	if c.OIDC.Enabled {
+		if c.OIDC.Issuer == "" || c.OIDC.ClientID == "" {
+			return errors.New("oidc requires an issuer and a client id")
+		}
+		if c.HTTP.PublicURL == "" {
+			return errors.New("oidc requires the http public url")
+		}
+		if c.OIDC.TokenExpiry <= 0 {
+			return errors.New("oidc token expiry must be positive")
+		}
+	}
+
+	if _, err := access.ParseIPRules(c.IPAccess.Allow, c.IPAccess.Deny); err != nil {
+		return fmt.Errorf("ip access: %w", err)
+	}
+
+	for _, rl := range []RateLimits{c.RateLimit.User, c.RateLimit.IP} {
+		for _, l := range []RateLimit{rl.SSHSessions, rl.GitOperations, rl.HTTPRequests} {
+			if l.PerMinute < 0 || l.Burst < 0 {
+				return errors.New("rate limits can't be negative")
+			}
+		}
+	}
+
+	if c.Workers.Size < 0 || c.Workers.PerRepo < 0 || c.Workers.Queue < 0 || c.Workers.QueueTimeout < 0 {
+		return errors.New("workers limits can't be negative")
+	}
+
+	if c.Cache.Size < 0 || c.Cache.MaxPackSize < 0 || c.Cache.Redis.TTL < 0 || c.Redis.TTL < 0 {
+		return errors.New("cache limits can't be negative")
+	}
+
 	if strings.HasPrefix(c.DB.Driver, "sqlite") && !filepath.IsAbs(c.DB.DataSource) {
 		c.DB.DataSource = filepath.Join(c.DataPath, c.DB.DataSource)
 	}
 
+	switch c.LFS.Storage {
+	case "", "local":
+	case "s3":
+		if c.LFS.S3.Endpoint == "" || c.LFS.S3.Bucket == "" {
+			return errors.New("lfs s3 storage requires an endpoint and a bucket")
+		}
+	default:
+		return fmt.Errorf("unknown lfs storage: %q", c.LFS.Storage)
+	}
+
+	switch c.Avatar.Provider {
+	case "", "none", "gravatar", "libravatar":
+	default:
+		return fmt.Errorf("unknown avatar provider: %q", c.Avatar.Provider)
+	}
+
+	if c.Avatar.MaxSize < 0 {
+		return errors.New("avatar max size can't be negative")
+	}
+
+	if c.Releases.MaxAssetSize < 0 {
+		return errors.New("releases max asset size can't be negative")
+	}
+
+	if c.RepoHooks.Timeout < 0 || c.RepoHooks.MaxMemory < 0 || c.RepoHooks.MaxOutput < 0 || c.RepoHooks.Logs < 0 {
+		return errors.New("repo hooks timeout, max memory, max output, and logs can't be negative")
+	}
+
+	if c.Jobs.Workers < 0 || c.Jobs.MaxAttempts < 0 {
+		return errors.New("jobs workers and max attempts can't be negative")
+	}
+
+	if c.Quota.User < 0 || c.Quota.Repo < 0 {
+		return errors.New("disk quotas can't be negative")
+	}
+
+	if c.HA.LockTTL < 0 || c.HA.LockTimeout < 0 {
+		return errors.New("ha lock ttl and timeout can't be negative")
+	}
+
+	if c.HA.Enabled && strings.HasPrefix(c.DB.Driver, "sqlite") {
+		return errors.New("ha mode requires a shared database, use postgres or mysql")
+	}
+
+	if c.Replica.SyncInterval < 0 {
+		return errors.New("replica sync interval can't be negative")
+	}
+
+	if c.Replica.Enabled {
+		if u, err := url.Parse(c.Replica.Primary); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid replica primary url: %q", c.Replica.Primary)
+		}
+		c.Replica.Primary = strings.TrimSuffix(c.Replica.Primary, "/")
+	}
+
+	for _, lvl := range []string{
+		c.Log.Level,
+		c.Log.Levels.SSH,
+		c.Log.Levels.Git,
+		c.Log.Levels.HTTP,
+		c.Log.Levels.UI,
+		c.Log.Levels.DB,
+	} {
+		if !isLogLevel(lvl) {
+			return fmt.Errorf("invalid log level %q", lvl)
+		}
+	}
+
+	if c.Log.TransportSampling < 0 {
+		return errors.New("log transport sampling can't be negative")
+	}
+
+	if c.Stats.MaxRepoLabels < 0 {
+		return errors.New("stats max repo labels can't be negative")
+	}
+
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		return errors.New("tracing endpoint is required")
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return errors.New("tracing sample ratio must be between 0 and 1")
+	}
+
+	if c.ShutdownTimeout < 0 {
+		return errors.New("shutdown timeout can't be negative")
+	}
+
+	if err := validateRepoPathTemplate(c.RepoPathTemplate); err != nil {
+		return fmt.Errorf("repo path template: %w", err)
+	}
+
 	// Validate keys
 	pks := make([]string, 0)
 	for _, key := range parseAuthKeys(c.InitialAdminKeys) {
@@ -378,6 +1317,15 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// isLogLevel reports whether a log level is valid, empty levels are.
+func isLogLevel(lvl string) bool {
+	switch strings.ToLower(lvl) {
+	case "", "debug", "info", "warn", "error", "fatal":
+		return true
+	}
+	return false
+}
+
 // parseAuthKeys parses authorized keys from either file paths or string authorized_keys.
 func parseAuthKeys(aks []string) []ssh.PublicKey {
 	exist := make(map[string]struct{}, 0)
@@ -402,3 +1350,9 @@ func parseAuthKeys(aks []string) []ssh.PublicKey {
 func (c *Config) AdminKeys() []ssh.PublicKey {
 	return parseAuthKeys(c.InitialAdminKeys)
 }
+
+// TrustedUserCAKeys returns the certificate authority keys trusted to sign
+// user certificates.
+func (c *Config) TrustedUserCAKeys() []ssh.PublicKey {
+	return parseAuthKeys(c.Current().SSH.TrustedUserCAKeys)
+}
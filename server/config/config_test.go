@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/matryer/is"
@@ -56,3 +57,97 @@ func TestValidateInitAdminKeys(t *testing.T) {
 		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINMwLvyV3ouVrTysUYGoJdl5Vgn5BACKov+n9PlzfPwH",
 	})
 }
+
+func TestValidateTracing(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.Validate())
+
+	cfg.Tracing.SampleRatio = 1.5
+	is.True(cfg.Validate() != nil)
+
+	cfg.Tracing.SampleRatio = 0.5
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.Endpoint = ""
+	is.True(cfg.Validate() != nil)
+}
+
+func TestValidateACME(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.HTTP.PublicURL = "https://git.example.com"
+	cfg.HTTP.ACME.Enabled = true
+	is.NoErr(cfg.Validate())
+	is.Equal(cfg.HTTP.ACME.Domains, []string{"git.example.com"})
+	is.Equal(cfg.HTTP.ACME.CacheDir, filepath.Join(cfg.DataPath, "acme"))
+
+	cfg.HTTP.TLSCertPath = "cert.pem"
+	cfg.HTTP.TLSKeyPath = "key.pem"
+	is.True(cfg.Validate() != nil)
+}
+
+func TestValidateListenAddrs(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.SSH.ListenAddr = "0.0.0.0:23231, [::]:23231"
+	is.NoErr(cfg.Validate())
+
+	cfg.SSH.ListenAddr = "0.0.0.0:23231,localhost"
+	is.True(cfg.Validate() != nil)
+}
+
+func TestRepoPath(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.Validate())
+	is.Equal(cfg.RepoPath("group/repo"), filepath.Join(cfg.DataPath, "repos", "group", "repo.git"))
+
+	// The SHA-256 of "repo" starts with "07 1c".
+	cfg.RepoPathTemplate = ShardedRepoPathTemplate
+	is.NoErr(cfg.Validate())
+	is.Equal(cfg.RepoPath("repo"), filepath.Join(cfg.DataPath, "repos", "07", "1c", "repo.git"))
+
+	for _, tmpl := range []string{
+		"{{ .Hash }}",
+		"../{{ .Name }}.git",
+		"repo.git",
+		"{{ .Nope }}.git",
+	} {
+		cfg.RepoPathTemplate = tmpl
+		is.True(cfg.Validate() != nil) // invalid template
+	}
+}
+
+func TestReload(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+
+	// Readers keep running while the configuration is reloaded.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = cfg.Current().Name
+			_ = cfg.Current().Push.AutoCreate
+			_ = cfg.TrustedUserCAKeys()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		ncfg := DefaultConfig()
+		ncfg.Name = "Reloaded"
+		ncfg.Push.AutoCreate = false
+		ncfg.SSH.ListenAddr = ":2222"
+		cfg.Reload(ncfg)
+	}
+	<-done
+
+	is.Equal(cfg.Name, "Soft Serve")
+	is.Equal(cfg.Current().Name, "Reloaded")
+	is.Equal(cfg.Current().Push.AutoCreate, false)
+	is.Equal(cfg.Current().SSH.ListenAddr, cfg.SSH.ListenAddr)
+}
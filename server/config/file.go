@@ -20,10 +20,23 @@ log:
   time_format: "{{ .Log.TimeFormat }}"
   # Path to the log file. Leave empty to write to stderr.
   #path: "{{ .Log.Path }}"
+  # Log level to use. Valid values are "debug", "info", "warn", "error", and
+  # "fatal".
+  level: "{{ .Log.Level }}"
+  # The log levels of the subsystems. Leave empty to use the log level.
+  levels:
+    ssh: "{{ .Log.Levels.SSH }}"
+    git: "{{ .Log.Levels.Git }}"
+    http: "{{ .Log.Levels.HTTP }}"
+    ui: "{{ .Log.Levels.UI }}"
+    db: "{{ .Log.Levels.DB }}"
+  # Log the requests and sessions of one in every N HTTP requests, SSH
+  # sessions, and git daemon requests. 0 and 1 log all of them.
+  transport_sampling: {{ .Log.TransportSampling }}
 
 # The SSH server configuration.
 ssh:
-  # The address on which the SSH server will listen.
+  # The addresses on which the SSH server will listen, separated by commas.
   listen_addr: "{{ .SSH.ListenAddr }}"
 
   # The public URL of the SSH server.
@@ -33,6 +46,12 @@ ssh:
   # The path to the SSH server's private key.
   key_path: {{ .SSH.KeyPath }}
 
+  # The paths to additional host keys, like RSA and ECDSA keys. Clients
+  # negotiate the first key of each type, the key_path one first. All of them
+  # are advertised to OpenSSH clients with UpdateHostKeys to rotate the keys.
+  key_paths:{{ range .SSH.KeyPaths }}
+    - "{{ . }}"{{ end }}
+
   # The path to the server's client private key. This key will be used to
   # authenticate the server to make git requests to ssh remotes.
   client_key_path: {{ .SSH.ClientKeyPath }}
@@ -45,9 +64,15 @@ ssh:
   # A value of 0 means no timeout.
   idle_timeout: {{ .SSH.IdleTimeout }}
 
+  # Certificate authority public keys, or paths to them, trusted to sign user
+  # certificates. Certificates are mapped to users by principal, users are
+  # created on first login.
+  #trusted_user_ca_keys:
+  #  - "ssh-ed25519 AAAAC3NzaC1lZDI1..."
+
 # The Git daemon configuration.
 git:
-  # The address on which the Git daemon will listen.
+  # The addresses on which the Git daemon will listen, separated by commas.
   listen_addr: "{{ .Git.ListenAddr }}"
 
   # The maximum number of seconds a connection can take.
@@ -60,9 +85,13 @@ git:
   # The maximum number of concurrent connections.
   max_connections: {{ .Git.MaxConnections }}
 
+  # Whether repositories are exported by the Git daemon by default. Set it to
+  # false to only export the repositories with "repo set REPO daemon-export true".
+  daemon_export: {{ .Git.DaemonExport }}
+
 # The HTTP server configuration.
 http:
-  # The address on which the HTTP server will listen.
+  # The addresses on which the HTTP server will listen, separated by commas.
   listen_addr: "{{ .HTTP.ListenAddr }}"
 
   # The path to the TLS private key.
@@ -76,20 +105,65 @@ http:
   # Make sure to use https:// if you are using TLS.
   public_url: "{{ .HTTP.PublicURL }}"
 
+  # The root of the Go import paths of repositories, a host and an optional
+  # path, like "go.example.com". Defaults to the host of the public URL.
+  go_import_domain: "{{ .HTTP.GoImportDomain }}"
+
+  # Provision and renew the TLS certificates with ACME, like Let's Encrypt,
+  # instead of using tls_key_path and tls_cert_path. Set the public URL to
+  # https:// and listen on :443.
+  acme:
+    enabled: {{ .HTTP.ACME.Enabled }}
+    # The domains certificates are requested for. Defaults to the host of
+    # the public URL.
+    domains:{{ range .HTTP.ACME.Domains }}
+      - "{{ . }}"{{ end }}
+    # The contact email of the ACME account, for expiry notices.
+    email: "{{ .HTTP.ACME.Email }}"
+    # The directory URL of the ACME server.
+    directory_url: "{{ .HTTP.ACME.DirectoryURL }}"
+    # The directory the account key and the certificates are stored in.
+    cache_dir: "{{ .HTTP.ACME.CacheDir }}"
+    # The address of the server answering the HTTP-01 challenges and
+    # redirecting to HTTPS, usually ":80". Leave it empty to only use the
+    # TLS-ALPN-01 challenges of the HTTP server.
+    challenge_listen_addr: "{{ .HTTP.ACME.ChallengeListenAddr }}"
+
 # The stats server configuration.
 stats:
-  # The address on which the stats server will listen.
+  # The addresses on which the stats server will listen, separated by commas.
   listen_addr: "{{ .Stats.ListenAddr }}"
 
+  # The maximum number of repositories with their own repo label in the git
+  # metrics, the others share the "_other" label. 0 disables the repo labels.
+  max_repo_labels: {{ .Stats.MaxRepoLabels }}
+
+# The OpenTelemetry tracing configuration.
+tracing:
+  # Export traces to an OTLP HTTP collector.
+  enabled: {{ .Tracing.Enabled }}
+  # The host and port of the collector.
+  endpoint: "{{ .Tracing.Endpoint }}"
+  # Connect to the collector without TLS.
+  insecure: {{ .Tracing.Insecure }}
+  # The service name of the traces.
+  service_name: "{{ .Tracing.ServiceName }}"
+  # The ratio of the traces that are sampled, between 0 and 1.
+  sample_ratio: {{ .Tracing.SampleRatio }}
+
 # The database configuration.
 db:
   # The database driver to use.
-  # Valid values are "sqlite" and "postgres".
+  # Valid values are "sqlite", "postgres", and "mysql".
   driver: "{{ .DB.Driver }}"
   # The database data source name.
   # This is driver specific and can be a file path or connection string.
   # Make sure foreign key support is enabled when using SQLite.
   data_source: "{{ .DB.DataSource }}"
+  # Whether to migrate the database when the server starts. When disabled, the
+  # server doesn't start while migrations are pending, apply them with
+  # "soft admin migrate up".
+  auto_migrate: {{ .DB.AutoMigrate }}
 
 # Git LFS configuration.
 lfs:
@@ -97,6 +171,271 @@ lfs:
   enabled: {{ .LFS.Enabled }}
   # Enable Git SSH transfer.
   ssh_enabled: {{ .LFS.SSHEnabled }}
+  # The storage backend of LFS objects, "local" or "s3".
+  storage: "{{ .LFS.Storage }}"
+  # S3-compatible object storage configuration, used when storage is "s3".
+  s3:
+    # The URL of the object storage service.
+    endpoint: "{{ .LFS.S3.Endpoint }}"
+    region: "{{ .LFS.S3.Region }}"
+    bucket: "{{ .LFS.S3.Bucket }}"
+    access_key_id: "{{ .LFS.S3.AccessKeyID }}"
+    secret_access_key: "{{ .LFS.S3.SecretAccessKey }}"
+    # A prefix prepended to the keys of all the objects.
+    prefix: "{{ .LFS.S3.Prefix }}"
+    # Use path-style URLs, required by MinIO.
+    path_style: {{ .LFS.S3.PathStyle }}
+  # The number of days LFS objects that aren't referenced by any ref are kept
+  # before they're pruned. A value of 0 disables pruning.
+  prune_retention_days: {{ .LFS.PruneRetentionDays }}
+
+# Push limits configuration.
+# These can be overridden per repository using "repo push-policy".
+push:
+  # The maximum size of a single file in bytes.
+  # A value of 0 means no limit.
+  max_blob_size: {{ .Push.MaxBlobSize }}
+  # The maximum total size of a push in bytes.
+  # A value of 0 means no limit.
+  max_push_size: {{ .Push.MaxPushSize }}
+  # File extensions that can only be pushed using Git LFS.
+  #banned_extensions:
+  #  - ".zip"
+  # Whether pushing to a repository that doesn't exist creates it, when the
+  # user is allowed to create repositories.
+  auto_create: {{ .Push.AutoCreate }}
+  # Whether the repositories created on push are private.
+  auto_create_private: {{ .Push.AutoCreatePrivate }}
+
+# Disk quotas configuration, the space the git objects and the Git LFS objects
+# take. Pushes over a quota are rejected. These can be overridden per user and
+# per repository using "admin quota".
+quota:
+  # The maximum disk usage of the repositories of a user in bytes.
+  # A value of 0 means no limit.
+  user: {{ .Quota.User }}
+  # The maximum disk usage of a repository in bytes.
+  # A value of 0 means no limit.
+  repo: {{ .Quota.Repo }}
+
+# RFC 3161 timestamps configuration.
+# Pushed tags are timestamped when a timestamp authority is set, use
+# "repo verify" to verify them.
+timestamp:
+  # The URL of the timestamp authority.
+  url: "{{ .Timestamp.URL }}"
+  # The path to a PEM file of the certificates trusted to verify timestamps.
+  # The system roots are used when it's empty.
+  ca_cert_path: "{{ .Timestamp.CACertPath }}"
+
+# LDAP directory configuration.
+# Users, their SSH public keys, and admin status are synced from the directory
+# every hour, use "user sync" to sync them immediately. Synced users can use
+# their directory password over HTTP.
+ldap:
+  # Whether users are synced from the directory.
+  enabled: {{ .LDAP.Enabled }}
+  # The URL of the directory, ldap://host[:port] or ldaps://host[:port].
+  url: "{{ .LDAP.URL }}"
+  # Whether ldap:// connections are upgraded using StartTLS.
+  start_tls: {{ .LDAP.StartTLS }}
+  # The path to a PEM file of the certificates trusted to verify the
+  # directory. The system roots are used when it's empty.
+  ca_cert_path: "{{ .LDAP.CACertPath }}"
+  # The account used to search the directory.
+  bind_dn: "{{ .LDAP.BindDN }}"
+  bind_password: "{{ .LDAP.BindPassword }}"
+  # The DN users are searched under, and the filter matching them.
+  base_dn: "{{ .LDAP.BaseDN }}"
+  user_filter: "{{ .LDAP.UserFilter }}"
+  # The attributes holding usernames, SSH public keys, and group DNs.
+  username_attribute: "{{ .LDAP.UsernameAttribute }}"
+  public_key_attribute: "{{ .LDAP.PublicKeyAttribute }}"
+  group_attribute: "{{ .LDAP.GroupAttribute }}"
+  # Members of these groups are admins.
+  #admin_groups:
+  #  - "cn=admins,ou=groups,dc=example,dc=org"
+  # Only members of these groups are synced. All the users matching the
+  # filter are synced when it's empty.
+  #user_groups:
+  #  - "cn=developers,ou=groups,dc=example,dc=org"
+
+# OpenID Connect configuration.
+# Users log in to the HTTP server at /login with the provider, and get a
+# short-lived access token for Git over HTTP. Register
+# "<http.public_url>/login/callback" as the redirect URL of the client.
+oidc:
+  # Whether users can log in with the provider.
+  enabled: {{ .OIDC.Enabled }}
+  # The issuer URL of the provider.
+  issuer: "{{ .OIDC.Issuer }}"
+  # The client registered with the provider.
+  client_id: "{{ .OIDC.ClientID }}"
+  client_secret: "{{ .OIDC.ClientSecret }}"
+  # The scopes requested besides "openid".
+  scopes:{{ range .OIDC.Scopes }}
+    - "{{ . }}"{{ end }}
+  # The ID token claim holding the username of an identity, shown in logs.
+  # Identities log in as the user their subject is linked to.
+  username_claim: "{{ .OIDC.UsernameClaim }}"
+  # The number of seconds the access tokens issued on login are valid for.
+  token_expiry: {{ .OIDC.TokenExpiry }}
+
+# The addresses allowed to connect to the SSH, HTTP, Git daemon, and stats
+# servers. These can be restricted further per user using "user set-ip-rules".
+ip_access:
+  # CIDRs or addresses allowed to connect. All addresses are allowed when it's
+  # empty.
+  allow:{{ range .IPAccess.Allow }}
+    - "{{ . }}"{{ end }}
+  # CIDRs or addresses that can't connect, even if they're allowed.
+  deny:{{ range .IPAccess.Deny }}
+    - "{{ . }}"{{ end }}
+
+# Token bucket rate limits of clients. A client can make "burst" requests at
+# once, and "per_minute" requests per minute on average. A value of 0 means no
+# limit.
+rate_limit:
+  # The limits of each user.
+  user:
+    # SSH sessions, commands and TUI included.
+    ssh_sessions:
+      per_minute: {{ .RateLimit.User.SSHSessions.PerMinute }}
+      burst: {{ .RateLimit.User.SSHSessions.Burst }}
+    # Clones, fetches, and pushes over SSH, HTTP, and the Git daemon.
+    git_operations:
+      per_minute: {{ .RateLimit.User.GitOperations.PerMinute }}
+      burst: {{ .RateLimit.User.GitOperations.Burst }}
+    http_requests:
+      per_minute: {{ .RateLimit.User.HTTPRequests.PerMinute }}
+      burst: {{ .RateLimit.User.HTTPRequests.Burst }}
+  # The limits of each address, whether the client is a user or not.
+  ip:
+    ssh_sessions:
+      per_minute: {{ .RateLimit.IP.SSHSessions.PerMinute }}
+      burst: {{ .RateLimit.IP.SSHSessions.Burst }}
+    git_operations:
+      per_minute: {{ .RateLimit.IP.GitOperations.PerMinute }}
+      burst: {{ .RateLimit.IP.GitOperations.Burst }}
+    http_requests:
+      per_minute: {{ .RateLimit.IP.HTTPRequests.PerMinute }}
+      burst: {{ .RateLimit.IP.HTTPRequests.Burst }}
+
+# The pool of workers running git operations, i.e. clones, fetches, and pushes
+# over all the protocols, and the git queries of the TUI. Operations wait in
+# line for a worker, and are rejected when the queue is full. A value of 0
+# means no limit.
+workers:
+  # The number of git operations running at once.
+  size: {{ .Workers.Size }}
+  # The number of git operations running at once on a repository.
+  per_repo: {{ .Workers.PerRepo }}
+  # The number of git operations waiting for a worker.
+  queue: {{ .Workers.Queue }}
+  # The number of seconds an operation waits for a worker.
+  queue_timeout: {{ .Workers.QueueTimeout }}
+
+# The cache of the responses to clones and fetches over HTTP, and of commonly
+# read objects like READMEs and commit logs.
+cache:
+  enabled: {{ .Cache.Enabled }}
+  # The maximum size of the in-memory cache in bytes.
+  size: {{ .Cache.Size }}
+  # The maximum size of a cached clone or fetch response in bytes.
+  max_pack_size: {{ .Cache.MaxPackSize }}
+  # A Redis server to store the cache in, to share it between servers.
+  redis:
+    addr: "{{ .Cache.Redis.Addr }}"
+    password: "{{ .Cache.Redis.Password }}"
+    db: {{ .Cache.Redis.DB }}
+    # The number of seconds keys are kept.
+    ttl: {{ .Cache.Redis.TTL }}
+
+# A Redis server to share the repository metadata cache and the events through,
+# to run multiple servers with the same database. The git cache above has its
+# own Redis server setting.
+redis:
+  addr: "{{ .Redis.Addr }}"
+  password: "{{ .Redis.Password }}"
+  db: {{ .Redis.DB }}
+  # The number of seconds keys are kept.
+  ttl: {{ .Redis.TTL }}
+
+# The avatars of users. Users without an uploaded image get the image of their
+# email address from the provider, "gravatar" or "libravatar", or their
+# initials when the provider is "none".
+avatar:
+  provider: "{{ .Avatar.Provider }}"
+  # The maximum size of an uploaded image in bytes, 0 disables uploads.
+  max_size: {{ .Avatar.MaxSize }}
+
+# The releases of repositories.
+releases:
+  # The maximum size of an uploaded asset in bytes, 0 disables uploads.
+  max_asset_size: {{ .Releases.MaxAssetSize }}
+
+# The hook scripts admins install in repositories with "repo hooks set". They
+# run after the hooks of Soft Serve, in a temporary directory, with a minimal
+# environment.
+repo_hooks:
+  # The number of seconds a script runs before it's killed.
+  timeout: {{ .RepoHooks.Timeout }}
+  # The maximum virtual memory of a script in bytes, 0 means no limit.
+  max_memory: {{ .RepoHooks.MaxMemory }}
+  # The number of bytes of the output of a run kept in its log.
+  max_output: {{ .RepoHooks.MaxOutput }}
+  # The number of runs logged per repository, list them with "repo hooks logs".
+  logs: {{ .RepoHooks.Logs }}
+
+# The persistent job queue running the webhook deliveries, the mirror updates,
+# and the LFS pruning. Failed jobs are retried with an exponential backoff.
+jobs:
+  # The number of jobs running at once.
+  workers: {{ .Jobs.Workers }}
+  # The number of attempts before a job is marked as dead, list them with
+  # "admin jobs list --status dead".
+  max_attempts: {{ .Jobs.MaxAttempts }}
+
+# Run multiple servers behind a load balancer. The servers share the
+# repository storage, and a postgres or mysql database they lock the
+# repositories through while they're pushed to. Set "redis" to share the
+# metadata cache and the events too.
+ha:
+  enabled: {{ .HA.Enabled }}
+  # The number of seconds a lock outlives a server that stopped holding it.
+  lock_ttl: {{ .HA.LockTTL }}
+  # The number of seconds to wait for a lock, 0 waits as long as needed.
+  lock_timeout: {{ .HA.LockTimeout }}
+
+# Run the server as a read-only replica of a primary server, close to the users
+# far from it. The replica reads the users, permissions, and repositories from
+# the database of the primary, point "db" at it, or at a read-only copy of it.
+# It serves fetches and the TUI from its own copy of the repositories, synced
+# from the primary, and proxies the pushes over HTTP to the primary.
+replica:
+  enabled: {{ .Replica.Enabled }}
+  # The HTTP URL of the primary server.
+  primary: "{{ .Replica.Primary }}"
+  # An access token of an admin of the primary to fetch the repositories with.
+  token: "{{ .Replica.Token }}"
+  # The number of seconds between two syncs of the repositories.
+  sync_interval: {{ .Replica.SyncInterval }}
+
+# The paths of the repositories in the "repos" directory of the data path, a
+# Go template of the repository .Name and the SHA-256 .Hash of the name. Use
+# "{{ "{{ slice .Hash 0 2 }}/{{ slice .Hash 2 4 }}/{{ .Name }}.git" }}" to shard the
+# repositories of large servers. Run "soft admin migrate-repos" after changing
+# it.
+repo_path_template: "{{ .RepoPathTemplate }}"
+
+# The number of seconds the results of commands run with an idempotency key
+# are kept. Retrying a command with the same key within this window replays
+# the original result instead of running the command again.
+idempotency_window: {{ .IdempotencyWindow }}
+
+# The number of seconds in-flight git transfers and TUI sessions are given to
+# finish when the server shuts down, before their connections are closed.
+shutdown_timeout: {{ .ShutdownTimeout }}
 
 # Additional admin keys.
 #initial_admin_keys:
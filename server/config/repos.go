@@ -0,0 +1,121 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const (
+	// FlatRepoPathTemplate stores the repositories at <name>.git in the
+	// repos directory, the default.
+	FlatRepoPathTemplate = "{{ .Name }}.git"
+
+	// ShardedRepoPathTemplate stores the repositories in two levels of
+	// directories named after the SHA-256 of their name, like
+	// ab/cd/<name>.git.
+	ShardedRepoPathTemplate = "{{ slice .Hash 0 2 }}/{{ slice .Hash 2 4 }}/{{ .Name }}.git"
+)
+
+// repoPathTemplates caches the parsed repository path templates.
+var repoPathTemplates sync.Map
+
+// RepoPathData is the data of the repository path templates.
+type RepoPathData struct {
+	// Name is the name of the repository, without the .git suffix.
+	Name string
+	// Hash is the hex-encoded SHA-256 of the name.
+	Hash string
+}
+
+// ReposPath returns the path to the directory the repositories are stored in.
+func (c *Config) ReposPath() string {
+	return filepath.Join(c.DataPath, "repos")
+}
+
+// RepoPath returns the path to a repository, following the repository path
+// template. name must be sanitized.
+func (c *Config) RepoPath(name string) string {
+	rel, err := RepoPathWithTemplate(c.RepoPathTemplate, name)
+	if err != nil {
+		// The template is checked by Validate.
+		rel = name + ".git"
+	}
+
+	return filepath.Join(c.ReposPath(), rel)
+}
+
+// RemoveEmptyRepoDirs removes the directories left empty in the repos
+// directory by moving or removing the repository at rp, like the shards of a
+// sharded layout.
+func (c *Config) RemoveEmptyRepoDirs(rp string) {
+	root := c.ReposPath()
+	for dir := filepath.Dir(rp); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		// Remove fails on directories that aren't empty.
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+// RepoPathWithTemplate returns the path to a repository relative to the repos
+// directory, following a repository path template. An empty template is the
+// flat template.
+func RepoPathWithTemplate(tmpl string, name string) (string, error) {
+	if tmpl == "" {
+		tmpl = FlatRepoPathTemplate
+	}
+
+	var t *template.Template
+	if v, ok := repoPathTemplates.Load(tmpl); ok {
+		t = v.(*template.Template)
+	} else {
+		var err error
+		t, err = template.New("repo_path").Option("missingkey=error").Parse(tmpl)
+		if err != nil {
+			return "", err
+		}
+		repoPathTemplates.Store(tmpl, t)
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	var b strings.Builder
+	if err := t.Execute(&b, RepoPathData{Name: name, Hash: hex.EncodeToString(sum[:])}); err != nil {
+		return "", err
+	}
+
+	return filepath.FromSlash(b.String()), nil
+}
+
+// validateRepoPathTemplate checks that a template gives the repositories
+// their own path in the repos directory, not nested in each other. Like bare
+// repositories, the paths end with .git.
+func validateRepoPathTemplate(tmpl string) error {
+	var paths []string
+	for _, name := range []string{"repo", "other", "group", "group/repo"} {
+		p, err := RepoPathWithTemplate(tmpl, name)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsLocal(p) {
+			return fmt.Errorf("%q is outside of the repos directory", p)
+		}
+		if !strings.HasSuffix(p, ".git") {
+			return fmt.Errorf("%q doesn't end with .git", p)
+		}
+		for _, o := range paths {
+			if p == o || strings.HasPrefix(p, o+string(filepath.Separator)) || strings.HasPrefix(o, p+string(filepath.Separator)) {
+				return errors.New("repositories must have their own path")
+			}
+		}
+		paths = append(paths, p)
+	}
+
+	return nil
+}
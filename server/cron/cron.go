@@ -2,15 +2,53 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/robfig/cron/v3"
 )
 
+var (
+	// ErrTaskNotFound is returned when a task is not found.
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskRunning is returned when running a task that is already
+	// running.
+	ErrTaskRunning = errors.New("task is already running")
+)
+
 // Scheduler is a cron-like job scheduler.
 type Scheduler struct {
 	*cron.Cron
+
+	logger *log.Logger
+	mu     sync.Mutex
+	tasks  map[string]*task
+}
+
+// Task is the state of a task of the Scheduler.
+type Task struct {
+	Name string
+	Spec string
+	// Running is true while the task runs.
+	Running bool
+	// LastRun is when the last run started, zero if the task didn't run
+	// since the server started.
+	LastRun time.Time
+	// Duration is how long the last run took.
+	Duration time.Duration
+	// Err is the error of the last run, nil if it succeeded.
+	Err error
+	// Manual is true if the last run was triggered with Run.
+	Manual bool
+}
+
+// task is a named task of the Scheduler.
+type task struct {
+	fn    func() error
+	state Task
 }
 
 // cronLogger is a wrapper around the logger to make it compatible with the
@@ -31,9 +69,11 @@ func (l cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
 
 // NewScheduler returns a new Cron.
 func NewScheduler(ctx context.Context) *Scheduler {
-	logger := cronLogger{log.FromContext(ctx).WithPrefix("cron")}
+	logger := log.FromContext(ctx).WithPrefix("cron")
 	return &Scheduler{
-		Cron: cron.New(cron.WithLogger(logger)),
+		Cron:   cron.New(cron.WithLogger(cronLogger{logger})),
+		logger: logger,
+		tasks:  make(map[string]*task),
 	}
 }
 
@@ -59,3 +99,65 @@ func (s *Scheduler) AddFunc(spec string, fn func()) (int, error) {
 func (s *Scheduler) Remove(id int) {
 	s.Cron.Remove(cron.EntryID(id))
 }
+
+// AddTask adds a named task to the Scheduler, its runs are recorded. A
+// scheduled run is skipped while the previous one isn't done.
+func (s *Scheduler) AddTask(name, spec string, fn func() error) (int, error) {
+	s.mu.Lock()
+	s.tasks[name] = &task{fn: fn, state: Task{Name: name, Spec: spec}}
+	s.mu.Unlock()
+	return s.AddFunc(spec, func() {
+		if err := s.run(name, false); errors.Is(err, ErrTaskRunning) {
+			s.logger.Warn("skipping task, the previous run isn't done", "task", name)
+		} else if err != nil {
+			s.logger.Error("task failed", "task", name, "err", err)
+		}
+	})
+}
+
+// Tasks returns the state of the named tasks, sorted by name.
+func (s *Scheduler) Tasks() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t.state)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Name < tasks[j].Name
+	})
+	return tasks
+}
+
+// Run runs a named task now and waits for it to finish. It returns the
+// error of the task.
+func (s *Scheduler) Run(name string) error {
+	return s.run(name, true)
+}
+
+func (s *Scheduler) run(name string, manual bool) error {
+	s.mu.Lock()
+	t, ok := s.tasks[name]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return ErrTaskNotFound
+	case t.state.Running:
+		s.mu.Unlock()
+		return ErrTaskRunning
+	}
+	start := time.Now()
+	t.state.Running = true
+	t.state.LastRun = start
+	t.state.Manual = manual
+	s.mu.Unlock()
+
+	err := t.fn()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.state.Running = false
+	t.state.Duration = time.Since(start)
+	t.state.Err = err
+	return err
+}
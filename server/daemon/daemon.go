@@ -3,6 +3,7 @@ package daemon
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"path/filepath"
@@ -15,10 +16,16 @@ import (
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/charmbracelet/soft-serve/server/listener"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/stats"
+	"github.com/charmbracelet/soft-serve/server/tracing"
 	"github.com/charmbracelet/soft-serve/server/utils"
 	"github.com/go-git/go-git/v5/plumbing/format/pktline"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -45,49 +52,55 @@ var (
 
 // GitDaemon represents a Git daemon.
 type GitDaemon struct {
-	ctx      context.Context
-	listener net.Listener
-	addr     string
-	finished chan struct{}
-	conns    connections
-	cfg      *config.Config
-	be       *backend.Backend
-	wg       sync.WaitGroup
-	once     sync.Once
-	logger   *log.Logger
+	ctx       context.Context
+	listeners []net.Listener
+	finished  chan struct{}
+	conns     connections
+	cfg       *config.Config
+	be        *backend.Backend
+	wg        sync.WaitGroup
+	once      sync.Once
+	logger    *log.Logger
 }
 
 // NewDaemon returns a new Git daemon.
 func NewGitDaemon(ctx context.Context) (*GitDaemon, error) {
 	cfg := config.FromContext(ctx)
-	addr := cfg.Git.ListenAddr
 	d := &GitDaemon{
 		ctx:      ctx,
-		addr:     addr,
 		finished: make(chan struct{}, 1),
 		cfg:      cfg,
 		be:       backend.FromContext(ctx),
 		conns:    connections{m: make(map[net.Conn]struct{})},
-		logger:   log.FromContext(ctx).WithPrefix("gitdaemon"),
+		logger:   logr.WithTrackedPrefix(log.FromContext(ctx), logr.SubsystemGit+".daemon"),
 	}
-	listener, err := net.Listen("tcp", d.addr)
+	listeners, err := listener.Listen(listener.Git, cfg.Git.ListenAddr)
 	if err != nil {
 		return nil, err
 	}
-	d.listener = listener
+	d.listeners = listeners
 	return d, nil
 }
 
+// Addr returns the addresses the daemon listens on.
+func (d *GitDaemon) Addr() string {
+	return listener.String(d.listeners)
+}
+
 // Start starts the Git TCP daemon.
 func (d *GitDaemon) Start() error {
-	defer d.listener.Close() // nolint: errcheck
+	return listener.Serve(d.listeners, d.serve)
+}
+
+func (d *GitDaemon) serve(l net.Listener) error {
+	defer l.Close() // nolint: errcheck
 
 	d.wg.Add(1)
 	defer d.wg.Done()
 
 	var tempDelay time.Duration
 	for {
-		conn, err := d.listener.Accept()
+		conn, err := l.Accept()
 		if err != nil {
 			select {
 			case <-d.finished:
@@ -117,6 +130,11 @@ func (d *GitDaemon) Start() error {
 			continue
 		}
 
+		if err := d.be.CheckAddress(d.ctx, "git-daemon", conn.RemoteAddr().String(), nil); err != nil {
+			d.fatal(conn, err)
+			continue
+		}
+
 		d.wg.Add(1)
 		go func() {
 			d.handleClient(conn)
@@ -231,15 +249,28 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 			return
 		}
 
+		if err := be.RateLimit(ctx, backend.RateGitOperation, nil, c.RemoteAddr().String()); err != nil {
+			d.fatal(c, err)
+			return
+		}
+
 		name := utils.SanitizeRepo(string(opts[0]))
-		d.logger.Debugf("git: connect %s %s %s", c.RemoteAddr(), service, name)
-		defer d.logger.Debugf("git: disconnect %s %s %s", c.RemoteAddr(), service, name)
+		if logr.SampleTransport() {
+			d.logger.Debugf("git: connect %s %s %s", c.RemoteAddr(), service, name)
+			defer d.logger.Debugf("git: disconnect %s %s %s", c.RemoteAddr(), service, name)
+		}
+
+		ctx, span := tracing.Start(ctx, "daemon.request",
+			attribute.String("git.service", service.String()),
+			attribute.String("repo", name),
+			attribute.String("net.peer", c.RemoteAddr().String()),
+		)
+		defer span.End()
 
 		// git bare repositories should end in ".git"
 		// https://git-scm.com/docs/gitrepository-layout
 		repo := name + ".git"
-		reposDir := filepath.Join(d.cfg.DataPath, "repos")
-		if err := git.EnsureWithin(reposDir, repo); err != nil {
+		if err := git.EnsureWithin(d.cfg.ReposPath(), repo); err != nil {
 			d.logger.Debugf("git: error ensuring repo path: %v", err)
 			d.fatal(c, git.ErrInvalidRepo)
 			return
@@ -256,10 +287,15 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 			return
 		}
 
+		if export, _, err := be.DaemonExport(ctx, name); err != nil || !export {
+			d.fatal(c, git.ErrNotExported)
+			return
+		}
+
 		// Environment variables to pass down to git hooks.
 		envs := []string{
 			"SOFT_SERVE_REPO_NAME=" + name,
-			"SOFT_SERVE_REPO_PATH=" + filepath.Join(reposDir, repo),
+			"SOFT_SERVE_REPO_PATH=" + d.be.RepoPath(name),
 			"SOFT_SERVE_HOST=" + host,
 			"SOFT_SERVE_LOG_PATH=" + filepath.Join(d.cfg.DataPath, "log", "hooks.log"),
 		}
@@ -278,28 +314,38 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 
 		envs = append(envs, d.cfg.Environ()...)
 
+		req := git.NewRequestReader(c, service)
 		cmd := git.ServiceCommand{
-			Stdin:  c,
+			Stdin:  req,
 			Stdout: c,
 			Stderr: c,
 			Env:    envs,
-			Dir:    filepath.Join(reposDir, repo),
+			Dir:    d.be.RepoPath(name),
+		}
+
+		release, err := d.be.AcquireWorker(ctx, name)
+		if err != nil {
+			d.fatal(c, err)
+			return
 		}
+		defer release()
 
+		defer stats.ObserveGit(stats.TransportDaemon, service, name, &cmd)()
 		if err := service.Handler(ctx, cmd); err != nil {
 			d.logger.Debugf("git: error handling request: %v", err)
 			d.fatal(c, err)
 			return
 		}
 
+		d.be.RecordTraffic(proto.WithRemoteAddrContext(ctx, c.RemoteAddr().String()), name, req.Request())
 		counter.WithLabelValues(name)
 	}
 }
 
-// Close closes the underlying listener.
+// Close closes the underlying listeners.
 func (d *GitDaemon) Close() error {
 	d.once.Do(func() { close(d.finished) })
-	err := d.listener.Close()
+	err := d.closeListeners()
 	d.conns.CloseAll() // nolint: errcheck
 	return err
 }
@@ -307,7 +353,7 @@ func (d *GitDaemon) Close() error {
 // Shutdown gracefully shuts down the daemon.
 func (d *GitDaemon) Shutdown(ctx context.Context) error {
 	d.once.Do(func() { close(d.finished) })
-	err := d.listener.Close()
+	err := d.closeListeners()
 	finished := make(chan struct{}, 1)
 	go func() {
 		d.wg.Wait()
@@ -320,3 +366,13 @@ func (d *GitDaemon) Shutdown(ctx context.Context) error {
 		return err
 	}
 }
+
+func (d *GitDaemon) closeListeners() error {
+	var errs []error
+	for _, l := range d.listeners {
+		if err := l.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
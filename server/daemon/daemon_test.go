@@ -77,7 +77,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestIdleTimeout(t *testing.T) {
-	c, err := net.Dial("tcp", testDaemon.addr)
+	c, err := net.Dial("tcp", testDaemon.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -91,7 +91,7 @@ func TestIdleTimeout(t *testing.T) {
 }
 
 func TestInvalidRepo(t *testing.T) {
-	c, err := net.Dial("tcp", testDaemon.addr)
+	c, err := net.Dial("tcp", testDaemon.Addr())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/server/config"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	_ "github.com/go-sql-driver/mysql" // mysql driver
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"  // postgres driver
 	_ "modernc.org/sqlite" // sqlite driver
@@ -21,6 +23,14 @@ type DB struct {
 
 // Open opens a database connection.
 func Open(ctx context.Context, driverName string, dsn string) (*DB, error) {
+	if driverName == "mysql" {
+		var err error
+		dsn, err = mysqlDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := sqlx.ConnectContext(ctx, driverName, dsn)
 	if err != nil {
 		return nil, err
@@ -30,9 +40,11 @@ func Open(ctx context.Context, driverName string, dsn string) (*DB, error) {
 		DB: db,
 	}
 
-	if config.IsVerbose() {
-		logger := log.FromContext(ctx).WithPrefix("db")
-		d.logger = logger
+	// Queries are logged in verbose mode, or when the database log level is
+	// debug.
+	lvl, ok := logr.SubsystemLevel(logr.SubsystemDB)
+	if config.IsVerbose() || (ok && lvl == log.DebugLevel) {
+		d.logger = logr.WithPrefix(log.FromContext(ctx), logr.SubsystemDB)
 	}
 
 	return d, nil
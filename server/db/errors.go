@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/lib/pq"
 	sqlite "modernc.org/sqlite"
 	sqlite3 "modernc.org/sqlite/lib"
@@ -43,6 +44,16 @@ func WrapError(err error) error {
 				return ErrDuplicateKey
 			}
 		}
+
+		// Handle mysql constraint error.
+		if myErr, ok := err.(*mysql.MySQLError); ok {
+			if myErr.Number == 1062 ||
+				myErr.Number == 1451 ||
+				myErr.Number == 1452 ||
+				myErr.Number == 3819 {
+				return ErrDuplicateKey
+			}
+		}
 	}
 	return err
 }
@@ -6,18 +6,30 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/tracing"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 func trace(l *log.Logger, query string, args ...interface{}) {
 	if l != nil {
-		// Remove newlines and tabs
-		query = strings.ReplaceAll(query, "\t", "")
-		query = strings.TrimSpace(query)
-		l.Debug("trace", "query", query, "args", args)
+		l.Debug("trace", "query", cleanQuery(query), "args", args)
 	}
 }
 
+// cleanQuery removes the tabs and the surrounding spaces of a query.
+func cleanQuery(query string) string {
+	query = strings.ReplaceAll(query, "\t", "")
+	return strings.TrimSpace(query)
+}
+
+// startSpan starts the tracing span of a query. The arguments aren't
+// recorded, they can be secrets.
+func startSpan(ctx context.Context, query string) (context.Context, oteltrace.Span) {
+	return tracing.Start(ctx, "db.query", attribute.String("db.statement", cleanQuery(query)))
+}
+
 // Select is a wrapper around sqlx.Select that logs the query and arguments.
 func (d *DB) Select(dest interface{}, query string, args ...interface{}) error {
 	trace(d.logger, query, args...)
@@ -48,34 +60,48 @@ func (d *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return d.DB.Exec(query, args...)
 }
 
-// SelectContext is a wrapper around sqlx.SelectContext that logs the query and arguments.
+// SelectContext is a wrapper around sqlx.SelectContext that logs the query and arguments, and traces the query.
 func (d *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
 	trace(d.logger, query, args...)
-	return d.DB.SelectContext(ctx, dest, query, args...)
+	ctx, span := startSpan(ctx, query)
+	err := d.DB.SelectContext(ctx, dest, query, args...)
+	tracing.End(span, err)
+	return err
 }
 
-// GetContext is a wrapper around sqlx.GetContext that logs the query and arguments.
+// GetContext is a wrapper around sqlx.GetContext that logs the query and arguments, and traces the query.
 func (d *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
 	trace(d.logger, query, args...)
-	return d.DB.GetContext(ctx, dest, query, args...)
+	ctx, span := startSpan(ctx, query)
+	err := d.DB.GetContext(ctx, dest, query, args...)
+	tracing.End(span, err)
+	return err
 }
 
-// QueryxContext is a wrapper around sqlx.QueryxContext that logs the query and arguments.
+// QueryxContext is a wrapper around sqlx.QueryxContext that logs the query and arguments, and traces the query.
 func (d *DB) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
 	trace(d.logger, query, args...)
-	return d.DB.QueryxContext(ctx, query, args...)
+	ctx, span := startSpan(ctx, query)
+	res, err := d.DB.QueryxContext(ctx, query, args...)
+	tracing.End(span, err)
+	return res, err
 }
 
-// QueryRowxContext is a wrapper around sqlx.QueryRowxContext that logs the query and arguments.
+// QueryRowxContext is a wrapper around sqlx.QueryRowxContext that logs the query and arguments, and traces the query.
 func (d *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
 	trace(d.logger, query, args...)
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
 	return d.DB.QueryRowxContext(ctx, query, args...)
 }
 
-// ExecContext is a wrapper around sqlx.ExecContext that logs the query and arguments.
+// ExecContext is a wrapper around sqlx.ExecContext that logs the query and arguments, and traces the query.
 func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	trace(d.logger, query, args...)
-	return d.DB.ExecContext(ctx, query, args...)
+	ctx, span := startSpan(ctx, query)
+	res, err := d.DB.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return res, err
 }
 
 // Select is a wrapper around sqlx.Select that logs the query and arguments.
@@ -108,32 +134,46 @@ func (t *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return t.Tx.Exec(query, args...)
 }
 
-// SelectContext is a wrapper around sqlx.SelectContext that logs the query and arguments.
+// SelectContext is a wrapper around sqlx.SelectContext that logs the query and arguments, and traces the query.
 func (t *Tx) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
 	trace(t.logger, query, args...)
-	return t.Tx.SelectContext(ctx, dest, query, args...)
+	ctx, span := startSpan(ctx, query)
+	err := t.Tx.SelectContext(ctx, dest, query, args...)
+	tracing.End(span, err)
+	return err
 }
 
-// GetContext is a wrapper around sqlx.GetContext that logs the query and arguments.
+// GetContext is a wrapper around sqlx.GetContext that logs the query and arguments, and traces the query.
 func (t *Tx) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
 	trace(t.logger, query, args...)
-	return t.Tx.GetContext(ctx, dest, query, args...)
+	ctx, span := startSpan(ctx, query)
+	err := t.Tx.GetContext(ctx, dest, query, args...)
+	tracing.End(span, err)
+	return err
 }
 
-// QueryxContext is a wrapper around sqlx.QueryxContext that logs the query and arguments.
+// QueryxContext is a wrapper around sqlx.QueryxContext that logs the query and arguments, and traces the query.
 func (t *Tx) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
 	trace(t.logger, query, args...)
-	return t.Tx.QueryxContext(ctx, query, args...)
+	ctx, span := startSpan(ctx, query)
+	res, err := t.Tx.QueryxContext(ctx, query, args...)
+	tracing.End(span, err)
+	return res, err
 }
 
-// QueryRowxContext is a wrapper around sqlx.QueryRowxContext that logs the query and arguments.
+// QueryRowxContext is a wrapper around sqlx.QueryRowxContext that logs the query and arguments, and traces the query.
 func (t *Tx) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
 	trace(t.logger, query, args...)
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
 	return t.Tx.QueryRowxContext(ctx, query, args...)
 }
 
-// ExecContext is a wrapper around sqlx.ExecContext that logs the query and arguments.
+// ExecContext is a wrapper around sqlx.ExecContext that logs the query and arguments, and traces the query.
 func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	trace(t.logger, query, args...)
-	return t.Tx.ExecContext(ctx, query, args...)
+	ctx, span := startSpan(ctx, query)
+	res, err := t.Tx.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return res, err
 }
@@ -155,7 +155,7 @@ var createTables = Migration{
 		}
 
 		// Insert default settings
-		insertSettings := insert + "INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)"
+		insertSettings := insert + "INTO settings (\"key\", value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)"
 		insertSettings = tx.Rebind(insertSettings)
 		settings := []struct {
 			Key   string
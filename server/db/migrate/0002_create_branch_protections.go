@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createBranchProtectionsName    = "create branch protections"
+	createBranchProtectionsVersion = 2
+)
+
+var createBranchProtections = Migration{
+	Version: createBranchProtectionsVersion,
+	Name:    createBranchProtectionsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createBranchProtectionsVersion, createBranchProtectionsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createBranchProtectionsVersion, createBranchProtectionsName)
+	},
+}
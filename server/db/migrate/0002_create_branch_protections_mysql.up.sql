@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS branch_protections (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  pattern VARCHAR(255) NOT NULL,
+  no_force_push BOOLEAN NOT NULL,
+  no_deletion BOOLEAN NOT NULL,
+  require_signed BOOLEAN NOT NULL,
+  restrict_push BOOLEAN NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, pattern),
+  CONSTRAINT branch_protections_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS branch_protection_users (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  branch_protection_id INT NOT NULL,
+  user_id INT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (branch_protection_id, user_id),
+  CONSTRAINT branch_protection_users_branch_protection_id_fk
+  FOREIGN KEY(branch_protection_id) REFERENCES branch_protections(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT branch_protection_users_user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
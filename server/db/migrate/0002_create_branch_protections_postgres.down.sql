@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS branch_protection_users;
+DROP TABLE IF EXISTS branch_protections;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS branch_protections (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  repo_id INTEGER NOT NULL,
+  pattern TEXT NOT NULL,
+  no_force_push BOOLEAN NOT NULL,
+  no_deletion BOOLEAN NOT NULL,
+  require_signed BOOLEAN NOT NULL,
+  restrict_push BOOLEAN NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, pattern),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS branch_protection_users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  branch_protection_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (branch_protection_id, user_id),
+  CONSTRAINT branch_protection_id_fk
+  FOREIGN KEY(branch_protection_id) REFERENCES branch_protections(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
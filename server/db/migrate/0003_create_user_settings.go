@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createUserSettingsName    = "create user settings"
+	createUserSettingsVersion = 3
+)
+
+var createUserSettings = Migration{
+	Version: createUserSettingsVersion,
+	Name:    createUserSettingsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createUserSettingsVersion, createUserSettingsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createUserSettingsVersion, createUserSettingsName)
+	},
+}
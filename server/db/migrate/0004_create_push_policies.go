@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createPushPoliciesName    = "create push policies"
+	createPushPoliciesVersion = 4
+)
+
+var createPushPolicies = Migration{
+	Version: createPushPoliciesVersion,
+	Name:    createPushPoliciesName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createPushPoliciesVersion, createPushPoliciesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createPushPoliciesVersion, createPushPoliciesName)
+	},
+}
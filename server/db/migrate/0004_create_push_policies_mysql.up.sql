@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS push_policies (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL UNIQUE,
+  max_blob_size BIGINT,
+  max_push_size BIGINT,
+  banned_extensions TEXT,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT push_policies_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
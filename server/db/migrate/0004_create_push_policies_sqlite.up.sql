@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS push_policies (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  repo_id INTEGER NOT NULL UNIQUE,
+  max_blob_size BIGINT,
+  max_push_size BIGINT,
+  banned_extensions TEXT,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createReadMarkersName    = "create read markers"
+	createReadMarkersVersion = 5
+)
+
+var createReadMarkers = Migration{
+	Version: createReadMarkersVersion,
+	Name:    createReadMarkersName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createReadMarkersVersion, createReadMarkersName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createReadMarkersVersion, createReadMarkersName)
+	},
+}
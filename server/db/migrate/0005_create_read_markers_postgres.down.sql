@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS commit_reads;
+DROP TABLE IF EXISTS repo_reads;
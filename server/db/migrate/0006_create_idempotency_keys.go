@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createIdempotencyKeysName    = "create idempotency keys"
+	createIdempotencyKeysVersion = 6
+)
+
+var createIdempotencyKeys = Migration{
+	Version: createIdempotencyKeysVersion,
+	Name:    createIdempotencyKeysName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createIdempotencyKeysVersion, createIdempotencyKeysName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createIdempotencyKeysVersion, createIdempotencyKeysName)
+	},
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createDeployKeysName    = "create deploy keys"
+	createDeployKeysVersion = 7
+)
+
+var createDeployKeys = Migration{
+	Version: createDeployKeysVersion,
+	Name:    createDeployKeysName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createDeployKeysVersion, createDeployKeysName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createDeployKeysVersion, createDeployKeysName)
+	},
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS deploy_keys (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  title TEXT NOT NULL,
+  public_key VARCHAR(2048) CHARACTER SET ascii NOT NULL,
+  access_level INT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, public_key),
+  CONSTRAINT deploy_keys_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
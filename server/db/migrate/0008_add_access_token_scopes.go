@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addAccessTokenScopesName    = "add access token scopes"
+	addAccessTokenScopesVersion = 8
+)
+
+var addAccessTokenScopes = Migration{
+	Version: addAccessTokenScopesVersion,
+	Name:    addAccessTokenScopesName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addAccessTokenScopesVersion, addAccessTokenScopesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addAccessTokenScopesVersion, addAccessTokenScopesName)
+	},
+}
@@ -0,0 +1,3 @@
+ALTER TABLE access_tokens DROP FOREIGN KEY access_tokens_repo_id_fk;
+ALTER TABLE access_tokens DROP COLUMN repo_id;
+ALTER TABLE access_tokens DROP COLUMN access_level;
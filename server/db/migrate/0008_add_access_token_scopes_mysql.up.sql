@@ -0,0 +1,7 @@
+-- Existing tokens keep the full access of their user.
+ALTER TABLE access_tokens ADD COLUMN access_level INT NOT NULL DEFAULT 3;
+ALTER TABLE access_tokens ADD COLUMN repo_id INT,
+  ADD CONSTRAINT access_tokens_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE;
@@ -0,0 +1,2 @@
+ALTER TABLE access_tokens DROP COLUMN repo_id;
+ALTER TABLE access_tokens DROP COLUMN access_level;
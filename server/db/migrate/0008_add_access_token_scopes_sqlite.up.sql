@@ -0,0 +1,3 @@
+-- Existing tokens keep the full access of their user.
+ALTER TABLE access_tokens ADD COLUMN access_level INTEGER NOT NULL DEFAULT 3;
+ALTER TABLE access_tokens ADD COLUMN repo_id INTEGER REFERENCES repos(id) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createTagTimestampsName    = "create tag timestamps"
+	createTagTimestampsVersion = 9
+)
+
+var createTagTimestamps = Migration{
+	Version: createTagTimestampsVersion,
+	Name:    createTagTimestampsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createTagTimestampsVersion, createTagTimestampsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createTagTimestampsVersion, createTagTimestampsName)
+	},
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS tag_timestamps (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  tag VARCHAR(255) NOT NULL,
+  object_id TEXT NOT NULL,
+  token LONGBLOB NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, tag),
+  CONSTRAINT tag_timestamps_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
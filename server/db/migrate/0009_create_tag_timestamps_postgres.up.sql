@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS tag_timestamps (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  tag TEXT NOT NULL,
+  object_id TEXT NOT NULL,
+  token BYTEA NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (repo_id, tag),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addRepoInternalName    = "add repo internal"
+	addRepoInternalVersion = 10
+)
+
+var addRepoInternal = Migration{
+	Version: addRepoInternalVersion,
+	Name:    addRepoInternalName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addRepoInternalVersion, addRepoInternalName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addRepoInternalVersion, addRepoInternalName)
+	},
+}
@@ -0,0 +1 @@
+ALTER TABLE repos ADD COLUMN internal BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createRepoSettingsName    = "create repo settings"
+	createRepoSettingsVersion = 11
+)
+
+var createRepoSettings = Migration{
+	Version: createRepoSettingsVersion,
+	Name:    createRepoSettingsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createRepoSettingsVersion, createRepoSettingsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createRepoSettingsVersion, createRepoSettingsName)
+	},
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createRefPermissionsName    = "create ref permissions"
+	createRefPermissionsVersion = 12
+)
+
+var createRefPermissions = Migration{
+	Version: createRefPermissionsVersion,
+	Name:    createRefPermissionsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createRefPermissionsVersion, createRefPermissionsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createRefPermissionsVersion, createRefPermissionsName)
+	},
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addUserSuspendedName    = "add user suspended"
+	addUserSuspendedVersion = 13
+)
+
+var addUserSuspended = Migration{
+	Version: addUserSuspendedVersion,
+	Name:    addUserSuspendedName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addUserSuspendedVersion, addUserSuspendedName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addUserSuspendedVersion, addUserSuspendedName)
+	},
+}
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN suspended BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createAuditEventsName    = "create audit events"
+	createAuditEventsVersion = 14
+)
+
+var createAuditEvents = Migration{
+	Version: createAuditEventsVersion,
+	Name:    createAuditEventsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createAuditEventsVersion, createAuditEventsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createAuditEventsVersion, createAuditEventsName)
+	},
+}
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  action VARCHAR(255) NOT NULL,
+  username VARCHAR(255) NOT NULL DEFAULT '',
+  impersonator VARCHAR(255) NOT NULL DEFAULT '',
+  repo VARCHAR(255) NOT NULL DEFAULT '',
+  target TEXT NOT NULL,
+  remote_addr VARCHAR(255) NOT NULL DEFAULT '',
+  details TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX audit_events_created_at_idx (created_at)
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TRIGGER audit_events_no_update
+BEFORE UPDATE ON audit_events
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit events are append-only';
+
+CREATE TRIGGER audit_events_no_delete
+BEFORE DELETE ON audit_events
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit events are append-only';
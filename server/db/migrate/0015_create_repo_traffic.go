@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createRepoTrafficName    = "create repo traffic"
+	createRepoTrafficVersion = 15
+)
+
+var createRepoTraffic = Migration{
+	Version: createRepoTrafficVersion,
+	Name:    createRepoTrafficName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createRepoTrafficVersion, createRepoTrafficName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createRepoTrafficVersion, createRepoTrafficName)
+	},
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS repo_traffic (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  day VARCHAR(255) NOT NULL,
+  clones INT NOT NULL DEFAULT 0,
+  fetches INT NOT NULL DEFAULT 0,
+  pushes INT NOT NULL DEFAULT 0,
+  clients LONGBLOB,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, day),
+  CONSTRAINT repo_traffic_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS repo_traffic (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  day TEXT NOT NULL,
+  clones INTEGER NOT NULL DEFAULT 0,
+  fetches INTEGER NOT NULL DEFAULT 0,
+  pushes INTEGER NOT NULL DEFAULT 0,
+  clients BYTEA,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (repo_id, day),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createReleasesName    = "create releases"
+	createReleasesVersion = 16
+)
+
+var createReleases = Migration{
+	Version: createReleasesVersion,
+	Name:    createReleasesName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createReleasesVersion, createReleasesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createReleasesVersion, createReleasesName)
+	},
+}
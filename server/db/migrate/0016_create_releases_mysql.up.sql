@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS releases (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  tag VARCHAR(255) NOT NULL,
+  name TEXT NOT NULL,
+  notes TEXT NOT NULL,
+  user_id INT,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, tag),
+  CONSTRAINT releases_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT releases_user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS release_assets (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  release_id INT NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  size BIGINT NOT NULL,
+  sha256 TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (release_id, name),
+  CONSTRAINT release_assets_release_id_fk
+  FOREIGN KEY(release_id) REFERENCES releases(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS releases (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  tag TEXT NOT NULL,
+  name TEXT NOT NULL,
+  notes TEXT NOT NULL,
+  user_id INTEGER,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (repo_id, tag),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS release_assets (
+  id SERIAL PRIMARY KEY,
+  release_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  size BIGINT NOT NULL,
+  sha256 TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (release_id, name),
+  CONSTRAINT release_id_fk
+  FOREIGN KEY(release_id) REFERENCES releases(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
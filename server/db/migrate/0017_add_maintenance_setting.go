@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addMaintenanceSettingName    = "add maintenance setting"
+	addMaintenanceSettingVersion = 17
+)
+
+var addMaintenanceSetting = Migration{
+	Version: addMaintenanceSettingVersion,
+	Name:    addMaintenanceSettingName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addMaintenanceSettingVersion, addMaintenanceSettingName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addMaintenanceSettingVersion, addMaintenanceSettingName)
+	},
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addMOTDSettingName    = "add motd setting"
+	addMOTDSettingVersion = 18
+)
+
+var addMOTDSetting = Migration{
+	Version: addMOTDSettingVersion,
+	Name:    addMOTDSettingName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addMOTDSettingVersion, addMOTDSettingName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addMOTDSettingVersion, addMOTDSettingName)
+	},
+}
@@ -0,0 +1 @@
+DELETE FROM settings WHERE "key" = 'motd';
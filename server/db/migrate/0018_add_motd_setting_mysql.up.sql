@@ -0,0 +1 @@
+INSERT INTO settings ("key", value, updated_at) VALUES ('motd', '', CURRENT_TIMESTAMP);
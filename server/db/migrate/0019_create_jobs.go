@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createJobsName    = "create jobs"
+	createJobsVersion = 19
+)
+
+var createJobs = Migration{
+	Version: createJobsVersion,
+	Name:    createJobsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createJobsVersion, createJobsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createJobsVersion, createJobsName)
+	},
+}
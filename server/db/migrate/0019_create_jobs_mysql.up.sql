@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS jobs (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  kind VARCHAR(255) NOT NULL,
+  payload LONGTEXT NOT NULL,
+  status VARCHAR(32) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL,
+  last_error TEXT NOT NULL,
+  run_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  INDEX jobs_status_run_at_idx (status, run_at)
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createLocksName    = "create locks"
+	createLocksVersion = 20
+)
+
+var createLocks = Migration{
+	Version: createLocksVersion,
+	Name:    createLocksName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createLocksVersion, createLocksName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createLocksVersion, createLocksName)
+	},
+}
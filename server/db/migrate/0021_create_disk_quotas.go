@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createDiskQuotasName    = "create disk quotas"
+	createDiskQuotasVersion = 21
+)
+
+var createDiskQuotas = Migration{
+	Version: createDiskQuotasVersion,
+	Name:    createDiskQuotasName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createDiskQuotasVersion, createDiskQuotasName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createDiskQuotasVersion, createDiskQuotasName)
+	},
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS disk_quotas;
+DROP TABLE IF EXISTS repo_disk_usages;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS repo_disk_usages (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL UNIQUE,
+  objects_size BIGINT NOT NULL,
+  lfs_size BIGINT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS disk_quotas (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER UNIQUE,
+  repo_id INTEGER UNIQUE,
+  size BIGINT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  CONSTRAINT user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
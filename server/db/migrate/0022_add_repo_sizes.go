@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addRepoSizesName    = "add repo sizes"
+	addRepoSizesVersion = 22
+)
+
+var addRepoSizes = Migration{
+	Version: addRepoSizesVersion,
+	Name:    addRepoSizesName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addRepoSizesVersion, addRepoSizesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addRepoSizesVersion, addRepoSizesName)
+	},
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS repo_large_blobs;
+ALTER TABLE repo_disk_usages DROP COLUMN pack_size;
+ALTER TABLE repo_disk_usages DROP COLUMN object_count;
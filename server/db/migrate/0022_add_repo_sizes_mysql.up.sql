@@ -0,0 +1,15 @@
+ALTER TABLE repo_disk_usages ADD COLUMN object_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE repo_disk_usages ADD COLUMN pack_size BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS repo_large_blobs (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  oid VARCHAR(255) NOT NULL,
+  path TEXT NOT NULL,
+  size BIGINT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_large_blobs_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
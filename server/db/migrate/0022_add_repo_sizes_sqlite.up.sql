@@ -0,0 +1,15 @@
+ALTER TABLE repo_disk_usages ADD COLUMN object_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE repo_disk_usages ADD COLUMN pack_size BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS repo_large_blobs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  repo_id INTEGER NOT NULL,
+  oid TEXT NOT NULL,
+  path TEXT NOT NULL,
+  size BIGINT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
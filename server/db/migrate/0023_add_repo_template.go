@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addRepoTemplateName    = "add repo template"
+	addRepoTemplateVersion = 23
+)
+
+var addRepoTemplate = Migration{
+	Version: addRepoTemplateVersion,
+	Name:    addRepoTemplateName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addRepoTemplateVersion, addRepoTemplateName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addRepoTemplateVersion, addRepoTemplateName)
+	},
+}
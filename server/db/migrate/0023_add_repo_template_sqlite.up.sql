@@ -0,0 +1 @@
+ALTER TABLE repos ADD COLUMN template BOOLEAN NOT NULL DEFAULT false;
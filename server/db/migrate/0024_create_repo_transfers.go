@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createRepoTransfersName    = "create repo transfers"
+	createRepoTransfersVersion = 24
+)
+
+var createRepoTransfers = Migration{
+	Version: createRepoTransfersVersion,
+	Name:    createRepoTransfersName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createRepoTransfersVersion, createRepoTransfersName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createRepoTransfersVersion, createRepoTransfersName)
+	},
+}
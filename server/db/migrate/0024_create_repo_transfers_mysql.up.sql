@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS repo_transfers (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL UNIQUE,
+  user_id INT NOT NULL,
+  requested_by INT,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_transfers_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT repo_transfers_user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT repo_transfers_requested_by_fk
+  FOREIGN KEY(requested_by) REFERENCES users(id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS repo_transfers (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL,
+  requested_by INTEGER,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE,
+  CONSTRAINT requested_by_fk
+  FOREIGN KEY(requested_by) REFERENCES users(id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE
+);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addRepoArchivedName    = "add repo archived"
+	addRepoArchivedVersion = 25
+)
+
+var addRepoArchived = Migration{
+	Version: addRepoArchivedVersion,
+	Name:    addRepoArchivedName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addRepoArchivedVersion, addRepoArchivedName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addRepoArchivedVersion, addRepoArchivedName)
+	},
+}
@@ -0,0 +1 @@
+ALTER TABLE repos ADD COLUMN archived BOOLEAN NOT NULL DEFAULT false;
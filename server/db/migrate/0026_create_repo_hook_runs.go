@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	createRepoHookRunsName    = "create repo hook runs"
+	createRepoHookRunsVersion = 26
+)
+
+var createRepoHookRuns = Migration{
+	Version: createRepoHookRunsVersion,
+	Name:    createRepoHookRunsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, createRepoHookRunsVersion, createRepoHookRunsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, createRepoHookRunsVersion, createRepoHookRunsName)
+	},
+}
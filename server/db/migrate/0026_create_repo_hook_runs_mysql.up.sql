@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS repo_hook_runs (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  hook VARCHAR(255) NOT NULL,
+  username VARCHAR(255) NOT NULL DEFAULT '',
+  exit_code INT NOT NULL,
+  duration BIGINT NOT NULL,
+  output MEDIUMTEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_hook_runs_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
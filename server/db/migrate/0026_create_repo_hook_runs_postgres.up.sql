@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS repo_hook_runs (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  hook TEXT NOT NULL,
+  username TEXT NOT NULL DEFAULT '',
+  exit_code INTEGER NOT NULL,
+  duration BIGINT NOT NULL,
+  output TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS repo_hook_runs_repo_id_idx ON repo_hook_runs (repo_id);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addPublicKeyDetailsName    = "add public key details"
+	addPublicKeyDetailsVersion = 27
+)
+
+var addPublicKeyDetails = Migration{
+	Version: addPublicKeyDetailsVersion,
+	Name:    addPublicKeyDetailsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addPublicKeyDetailsVersion, addPublicKeyDetailsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addPublicKeyDetailsVersion, addPublicKeyDetailsName)
+	},
+}
@@ -0,0 +1,3 @@
+ALTER TABLE public_keys ADD COLUMN label VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE public_keys ADD COLUMN last_used_at DATETIME NULL;
+ALTER TABLE public_keys ADD COLUMN expires_at DATETIME NULL;
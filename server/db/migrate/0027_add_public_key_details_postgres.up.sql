@@ -0,0 +1,3 @@
+ALTER TABLE public_keys ADD COLUMN label TEXT NOT NULL DEFAULT '';
+ALTER TABLE public_keys ADD COLUMN last_used_at TIMESTAMP;
+ALTER TABLE public_keys ADD COLUMN expires_at TIMESTAMP;
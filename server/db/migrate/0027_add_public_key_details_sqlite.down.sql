@@ -0,0 +1,3 @@
+ALTER TABLE public_keys DROP COLUMN expires_at;
+ALTER TABLE public_keys DROP COLUMN last_used_at;
+ALTER TABLE public_keys DROP COLUMN label;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+const (
+	addPublicKeyRestrictionsName    = "add public key restrictions"
+	addPublicKeyRestrictionsVersion = 28
+)
+
+var addPublicKeyRestrictions = Migration{
+	Version: addPublicKeyRestrictionsVersion,
+	Name:    addPublicKeyRestrictionsName,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, addPublicKeyRestrictionsVersion, addPublicKeyRestrictionsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, addPublicKeyRestrictionsVersion, addPublicKeyRestrictionsName)
+	},
+}
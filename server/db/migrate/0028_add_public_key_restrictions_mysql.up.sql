@@ -0,0 +1 @@
+ALTER TABLE public_keys ADD COLUMN restriction VARCHAR(32) NOT NULL DEFAULT '';
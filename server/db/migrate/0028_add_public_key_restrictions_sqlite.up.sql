@@ -0,0 +1 @@
+ALTER TABLE public_keys ADD COLUMN restriction TEXT NOT NULL DEFAULT '';
@@ -59,6 +59,113 @@ func (Migrations) schema(driverName string) string {
 	}
 }
 
+// ErrInitialRollback is returned when rolling back the migration creating the
+// tables, which would delete all the data.
+var ErrInitialRollback = errors.New("the initial migration can't be rolled back")
+
+// MigrationStatus is the status of a migration.
+type MigrationStatus struct {
+	Version int64
+	Name    string
+	Applied bool
+}
+
+// SQL returns the SQL statements of the migration for a database driver, or
+// the ones rolling it back when down is true. Some migrations also run code
+// that isn't shown, like the one creating the tables.
+func (m Migration) SQL(driverName string, down bool) (string, error) {
+	direction := "up"
+	if down {
+		direction = "down"
+	}
+
+	sqlstr, err := migrationSQL(driverName, int(m.Version), m.Name, direction)
+	return string(sqlstr), err
+}
+
+// Version returns the version of the last migration applied to the
+// database, zero when none is.
+func Version(ctx context.Context, dbx *db.DB) (int64, error) {
+	var version int64
+	err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		version, err = currentVersion(tx)
+		return err
+	})
+	return version, err
+}
+
+// Status returns the status of all the migrations, oldest first.
+func Status(ctx context.Context, dbx *db.DB) ([]MigrationStatus, error) {
+	version, err := Version(ctx, dbx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		status[i] = MigrationStatus{
+			Version: m.Version,
+			Name:    m.Name,
+			Applied: m.Version <= version,
+		}
+	}
+
+	return status, nil
+}
+
+// Pending returns the migrations that aren't applied to the database yet,
+// oldest first.
+func Pending(ctx context.Context, dbx *db.DB) ([]Migration, error) {
+	version, err := Version(ctx, dbx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// Last returns the last migration applied to the database, the one Rollback
+// rolls back.
+func Last(ctx context.Context, dbx *db.DB) (Migration, error) {
+	version, err := Version(ctx, dbx)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	if version == 0 || len(migrations) < int(version) {
+		return Migration{}, fmt.Errorf("there are no migrations to rollback")
+	}
+	if version == createTablesVersion {
+		return Migration{}, ErrInitialRollback
+	}
+
+	return migrations[version-1], nil
+}
+
+// currentVersion returns the version of the last applied migration.
+func currentVersion(tx *db.Tx) (int64, error) {
+	if !hasTable(tx, "migrations") {
+		return 0, nil
+	}
+
+	var migrs Migrations
+	if err := tx.Get(&migrs, tx.Rebind("SELECT * FROM migrations ORDER BY version DESC LIMIT 1")); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+	}
+
+	return migrs.Version, nil
+}
+
 // Migrate runs the migrations.
 func Migrate(ctx context.Context, dbx *db.DB) error {
 	logger := log.FromContext(ctx).WithPrefix("migrate")
@@ -69,15 +176,13 @@ func Migrate(ctx context.Context, dbx *db.DB) error {
 			}
 		}
 
-		var migrs Migrations
-		if err := tx.Get(&migrs, tx.Rebind("SELECT * FROM migrations ORDER BY version DESC LIMIT 1")); err != nil {
-			if !errors.Is(err, sql.ErrNoRows) {
-				return err
-			}
+		version, err := currentVersion(tx)
+		if err != nil {
+			return err
 		}
 
 		for _, m := range migrations {
-			if m.Version <= migrs.Version {
+			if m.Version <= version {
 				continue
 			}
 
@@ -109,6 +214,9 @@ func Rollback(ctx context.Context, dbx *db.DB) error {
 		if migrs.Version == 0 || len(migrations) < int(migrs.Version) {
 			return fmt.Errorf("there are no migrations to rollback")
 		}
+		if migrs.Version == createTablesVersion {
+			return ErrInitialRollback
+		}
 
 		m := migrations[migrs.Version-1]
 		logger.Infof("rolling back migration %d. %s", m.Version, m.Name)
@@ -130,9 +238,9 @@ func hasTable(tx *db.Tx, tableName string) bool {
 	case "sqlite3", "sqlite":
 		query = "SELECT name FROM sqlite_master WHERE type='table' AND name=?"
 	case "postgres":
-		fallthrough
-	case "mysql":
 		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_name = ?"
+	case "mysql":
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
 	}
 
 	query = tx.Rebind(query)
@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/db"
+	_ "modernc.org/sqlite" // sqlite driver
+)
+
+func TestMigrateStatus(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	ctx := config.WithContext(context.TODO(), cfg)
+	dbx, err := db.Open(ctx, "sqlite", filepath.Join(cfg.DataPath, "soft-serve.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbx.Close() // nolint: errcheck
+
+	pending, err := Pending(ctx, dbx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(migrations) {
+		t.Fatalf("expected %d pending migrations, got %d", len(migrations), len(pending))
+	}
+	if _, err := Last(ctx, dbx); err == nil {
+		t.Error("expected an error without applied migrations")
+	}
+
+	if err := Migrate(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := Last(ctx, dbx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Version != migrations[len(migrations)-1].Version {
+		t.Errorf("unexpected last migration: %d", last.Version)
+	}
+	if _, err := last.SQL(dbx.DriverName(), true); err != nil {
+		t.Error(err)
+	}
+
+	if err := Rollback(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+	status, err := Status(ctx, dbx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range status {
+		if s.Applied != (i < len(status)-1) {
+			t.Errorf("unexpected status of migration %d: %v", s.Version, s.Applied)
+		}
+	}
+
+	// Roll back everything but the initial migration.
+	for len(status) > 2 {
+		if err := Rollback(ctx, dbx); err != nil {
+			t.Fatal(err)
+		}
+		status = status[:len(status)-1]
+	}
+	if err := Rollback(ctx, dbx); !errors.Is(err, ErrInitialRollback) {
+		t.Errorf("expected ErrInitialRollback, got %v", err)
+	}
+}
@@ -16,6 +16,33 @@ var sqls embed.FS
 // Keep this in order of execution, oldest to newest.
 var migrations = []Migration{
 	createTables,
+	createBranchProtections,
+	createUserSettings,
+	createPushPolicies,
+	createReadMarkers,
+	createIdempotencyKeys,
+	createDeployKeys,
+	addAccessTokenScopes,
+	createTagTimestamps,
+	addRepoInternal,
+	createRepoSettings,
+	createRefPermissions,
+	addUserSuspended,
+	createAuditEvents,
+	createRepoTraffic,
+	createReleases,
+	addMaintenanceSetting,
+	addMOTDSetting,
+	createJobs,
+	createLocks,
+	createDiskQuotas,
+	addRepoSizes,
+	addRepoTemplate,
+	createRepoTransfers,
+	addRepoArchived,
+	createRepoHookRuns,
+	addPublicKeyDetails,
+	addPublicKeyRestrictions,
 }
 
 func execMigration(ctx context.Context, tx *db.Tx, version int, name string, down bool) error {
@@ -24,13 +51,7 @@ func execMigration(ctx context.Context, tx *db.Tx, version int, name string, dow
 		direction = "down"
 	}
 
-	driverName := tx.DriverName()
-	if driverName == "sqlite3" {
-		driverName = "sqlite"
-	}
-
-	fn := fmt.Sprintf("%04d_%s_%s.%s.sql", version, toSnakeCase(name), driverName, direction)
-	sqlstr, err := sqls.ReadFile(fn)
+	sqlstr, err := migrationSQL(tx.DriverName(), version, name, direction)
 	if err != nil {
 		return err
 	}
@@ -42,6 +63,16 @@ func execMigration(ctx context.Context, tx *db.Tx, version int, name string, dow
 	return nil
 }
 
+// migrationSQL returns the SQL file of a migration for a database driver.
+func migrationSQL(driverName string, version int, name string, direction string) ([]byte, error) {
+	if driverName == "sqlite3" {
+		driverName = "sqlite"
+	}
+
+	fn := fmt.Sprintf("%04d_%s_%s.%s.sql", version, toSnakeCase(name), driverName, direction)
+	return sqls.ReadFile(fn)
+}
+
 func migrateUp(ctx context.Context, tx *db.Tx, version int, name string) error {
 	return execMigration(ctx, tx, version, name, false)
 }
@@ -3,6 +3,8 @@ package models
 import (
 	"database/sql"
 	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
 )
 
 // AccessToken represents an access token.
@@ -12,6 +14,10 @@ type AccessToken struct {
 	UserID    int64        `db:"user_id"`
 	Token     string       `db:"token"`
 	ExpiresAt sql.NullTime `db:"expires_at"`
-	CreatedAt time.Time    `db:"created_at"`
-	UpdatedAt time.Time    `db:"updated_at"`
+	// AccessLevel caps the access of the token below the access of its user.
+	AccessLevel access.AccessLevel `db:"access_level"`
+	// RepoID limits the token to a single repository.
+	RepoID    sql.NullInt64 `db:"repo_id"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
 }
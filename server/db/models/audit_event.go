@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AuditEvent is a database model for a security-relevant action recorded in
+// the audit log. Users and repositories are stored by name so that events
+// outlive them.
+type AuditEvent struct {
+	ID           int64     `db:"id"`
+	Action       string    `db:"action"`
+	Username     string    `db:"username"`
+	Impersonator string    `db:"impersonator"`
+	Repo         string    `db:"repo"`
+	Target       string    `db:"target"`
+	RemoteAddr   string    `db:"remote_addr"`
+	Details      string    `db:"details"`
+	CreatedAt    time.Time `db:"created_at"`
+}
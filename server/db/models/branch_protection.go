@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// BranchProtection is a database model for a branch protection rule.
+type BranchProtection struct {
+	ID            int64     `db:"id"`
+	RepoID        int64     `db:"repo_id"`
+	Pattern       string    `db:"pattern"`
+	NoForcePush   bool      `db:"no_force_push"`
+	NoDeletion    bool      `db:"no_deletion"`
+	RequireSigned bool      `db:"require_signed"`
+	RestrictPush  bool      `db:"restrict_push"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+)
+
+// DeployKey represents a public key that has access to a single repository.
+type DeployKey struct {
+	ID          int64              `db:"id"`
+	RepoID      int64              `db:"repo_id"`
+	Title       string             `db:"title"`
+	PublicKey   string             `db:"public_key"`
+	AccessLevel access.AccessLevel `db:"access_level"`
+	CreatedAt   time.Time          `db:"created_at"`
+	UpdatedAt   time.Time          `db:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RepoDiskUsage is the disk space used by a repository, in bytes.
+type RepoDiskUsage struct {
+	ID          int64     `db:"id"`
+	RepoID      int64     `db:"repo_id"`
+	ObjectsSize int64     `db:"objects_size"`
+	LFSSize     int64     `db:"lfs_size"`
+	ObjectCount int64     `db:"object_count"`
+	PackSize    int64     `db:"pack_size"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// RepoLargeBlob is one of the largest blobs of a repository.
+type RepoLargeBlob struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	Oid       string    `db:"oid"`
+	Path      string    `db:"path"`
+	Size      int64     `db:"size"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// DiskQuota is the disk quota of a user or of a repository, in bytes.
+type DiskQuota struct {
+	ID        int64         `db:"id"`
+	UserID    sql.NullInt64 `db:"user_id"`
+	RepoID    sql.NullInt64 `db:"repo_id"`
+	Size      int64         `db:"size"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+}
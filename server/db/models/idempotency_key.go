@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IdempotencyKey represents the recorded result of an idempotent request.
+type IdempotencyKey struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Key       string    `db:"key"`
+	Request   string    `db:"request"`
+	Response  string    `db:"response"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Job is a database model for a job of the persistent job queue.
+type Job struct {
+	ID int64 `db:"id"`
+	// Kind selects the handler running the job.
+	Kind string `db:"kind"`
+	// Payload is the JSON encoded input of the handler.
+	Payload string `db:"payload"`
+	// Status is "pending", "running", or "dead".
+	Status      string `db:"status"`
+	Attempts    int    `db:"attempts"`
+	MaxAttempts int    `db:"max_attempts"`
+	LastError   string `db:"last_error"`
+	// RunAt is when the job is due.
+	RunAt     time.Time `db:"run_at"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
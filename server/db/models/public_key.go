@@ -1,10 +1,21 @@
 package models
 
+import (
+	"database/sql"
+	"time"
+)
+
 // PublicKey represents a public key.
 type PublicKey struct {
 	ID        int64  `db:"id"`
 	UserID    int64  `db:"user_id"`
 	PublicKey string `db:"public_key"`
-	CreatedAt string `db:"created_at"`
-	UpdatedAt string `db:"updated_at"`
+	// Label describes the key, it defaults to the comment of the key.
+	Label string `db:"label"`
+	// Restriction limits what the key can do, empty for unrestricted keys.
+	Restriction string       `db:"restriction"`
+	LastUsedAt  sql.NullTime `db:"last_used_at"`
+	ExpiresAt   sql.NullTime `db:"expires_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at"`
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PushPolicy is a database model for a repository push policy. Null values
+// fall back to the server configuration.
+type PushPolicy struct {
+	ID               int64          `db:"id"`
+	RepoID           int64          `db:"repo_id"`
+	MaxBlobSize      sql.NullInt64  `db:"max_blob_size"`
+	MaxPushSize      sql.NullInt64  `db:"max_push_size"`
+	BannedExtensions sql.NullString `db:"banned_extensions"`
+	CreatedAt        time.Time      `db:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// RefPermission is a database model for a user allowed to push to the
+// references matching a pattern.
+type RefPermission struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	Pattern   string    `db:"pattern"`
+	UserID    int64     `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Release represents a release of a repository tag.
+type Release struct {
+	ID        int64         `db:"id"`
+	RepoID    int64         `db:"repo_id"`
+	Tag       string        `db:"tag"`
+	Name      string        `db:"name"`
+	Notes     string        `db:"notes"`
+	UserID    sql.NullInt64 `db:"user_id"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+}
+
+// ReleaseAsset represents a file attached to a release.
+type ReleaseAsset struct {
+	ID        int64     `db:"id"`
+	ReleaseID int64     `db:"release_id"`
+	Name      string    `db:"name"`
+	Size      int64     `db:"size"`
+	SHA256    string    `db:"sha256"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
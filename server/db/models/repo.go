@@ -12,8 +12,11 @@ type Repo struct {
 	ProjectName string        `db:"project_name"`
 	Description string        `db:"description"`
 	Private     bool          `db:"private"`
+	Internal    bool          `db:"internal"`
 	Mirror      bool          `db:"mirror"`
 	Hidden      bool          `db:"hidden"`
+	Template    bool          `db:"template"`
+	Archived    bool          `db:"archived"`
 	UserID      sql.NullInt64 `db:"user_id"`
 	CreatedAt   time.Time     `db:"created_at"`
 	UpdatedAt   time.Time     `db:"updated_at"`
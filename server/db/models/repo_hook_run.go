@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RepoHookRun is a database model for a run of a hook script of a
+// repository. The user is stored by name so that runs outlive them.
+type RepoHookRun struct {
+	ID       int64  `db:"id"`
+	RepoID   int64  `db:"repo_id"`
+	Hook     string `db:"hook"`
+	Username string `db:"username"`
+	ExitCode int    `db:"exit_code"`
+	// Duration is the run time in milliseconds.
+	Duration  int64     `db:"duration"`
+	Output    string    `db:"output"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RepoTraffic is a database model for the daily traffic of a repository.
+type RepoTraffic struct {
+	ID      int64  `db:"id"`
+	RepoID  int64  `db:"repo_id"`
+	Day     string `db:"day"`
+	Clones  int64  `db:"clones"`
+	Fetches int64  `db:"fetches"`
+	Pushes  int64  `db:"pushes"`
+	// Clients is a sketch of the distinct clients of the day.
+	Clients   []byte    `db:"clients"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
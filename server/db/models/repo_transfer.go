@@ -0,0 +1,19 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RepoTransfer represents a pending transfer of a repository to a new owner.
+type RepoTransfer struct {
+	ID     int64 `db:"id"`
+	RepoID int64 `db:"repo_id"`
+	// UserID is the ID of the new owner.
+	UserID      int64         `db:"user_id"`
+	RequestedBy sql.NullInt64 `db:"requested_by"`
+	CreatedAt   time.Time     `db:"created_at"`
+
+	// Repo is the name of the repository, joined from repos.
+	Repo string `db:"repo"`
+}
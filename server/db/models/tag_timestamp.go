@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TagTimestamp represents the RFC 3161 timestamp token of a repository tag.
+type TagTimestamp struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	Tag       string    `db:"tag"`
+	ObjectID  string    `db:"object_id"`
+	Token     []byte    `db:"token"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -10,6 +10,7 @@ type User struct {
 	ID        int64          `db:"id"`
 	Username  string         `db:"username"`
 	Admin     bool           `db:"admin"`
+	Suspended bool           `db:"suspended"`
 	Password  sql.NullString `db:"password"`
 	CreatedAt time.Time      `db:"created_at"`
 	UpdatedAt time.Time      `db:"updated_at"`
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UserSetting represents a user setting record.
+type UserSetting struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Key       string    `db:"key"`
+	Value     string    `db:"value"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
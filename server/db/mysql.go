@@ -0,0 +1,28 @@
+package db
+
+import (
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDSN sets the connection options the queries and migrations rely on:
+// ANSI quoted identifiers, multiple statements for the migration files, and
+// times in UTC.
+func mysqlDSN(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	cfg.ParseTime = true
+	cfg.MultiStatements = true
+	cfg.Loc = time.UTC
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["sql_mode"] = "'ANSI,STRICT_TRANS_TABLES'"
+	cfg.Params["time_zone"] = "'+00:00'"
+
+	return cfg.FormatDSN(), nil
+}
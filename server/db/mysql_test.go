@@ -0,0 +1,22 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMySQLDSN(t *testing.T) {
+	dsn, err := mysqlDSN("user:pass@tcp(localhost:3306)/softserve?charset=utf8mb4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"parseTime=true", "multiStatements=true", "sql_mode=", "time_zone=", "charset=utf8mb4"} {
+		if !strings.Contains(dsn, s) {
+			t.Errorf("expected %q in %q", s, dsn)
+		}
+	}
+
+	if _, err := mysqlDSN("postgres://localhost"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,197 @@
+// Package events broadcasts repository events to subscribers in real time.
+//
+// Events are published to a Broker, which keeps the most recent ones so that
+// subscribers reconnecting after a dropped connection can catch up on what
+// they missed.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type is the type of an event.
+type Type string
+
+const (
+	// Push is published when commits are pushed to an existing branch.
+	Push Type = "push"
+	// BranchCreate is published when a branch is created.
+	BranchCreate Type = "branch.create"
+	// BranchDelete is published when a branch is deleted.
+	BranchDelete Type = "branch.delete"
+	// TagCreate is published when a tag is created.
+	TagCreate Type = "tag.create"
+	// TagDelete is published when a tag is deleted.
+	TagDelete Type = "tag.delete"
+	// RepoCreate is published when a repository is created.
+	RepoCreate Type = "repo.create"
+	// RepoImport is published when a repository is imported.
+	RepoImport Type = "repo.import"
+	// RepoDelete is published when a repository is deleted.
+	RepoDelete Type = "repo.delete"
+	// RepoRename is published when a repository is renamed.
+	RepoRename Type = "repo.rename"
+
+	// MOTD is published when the message of the day changes.
+	MOTD Type = "motd"
+	// Broadcast is a message sent by an admin to the connected sessions.
+	Broadcast Type = "broadcast"
+)
+
+// Event is something that happened to a repository, or an announcement to
+// the sessions.
+type Event struct {
+	// ID is the unique increasing ID of the event.
+	ID int64 `json:"id"`
+	// Type is the type of the event.
+	Type Type `json:"type"`
+	// Repo is the name of the repository, empty for announcements.
+	Repo string `json:"repo"`
+	// Username is the user who caused the event, if any.
+	Username string `json:"username,omitempty"`
+	// Ref is the full name of the updated reference, if any.
+	Ref string `json:"ref,omitempty"`
+	// Before is the hash the reference pointed to before the update.
+	Before string `json:"before,omitempty"`
+	// After is the hash the reference points to after the update.
+	After string `json:"after,omitempty"`
+	// PushOptions are the options of the push that updated the reference,
+	// sent with git push -o, e.g. skip-ci.
+	PushOptions []string `json:"push_options,omitempty"`
+	// From is the previous name of a renamed repository.
+	From string `json:"from,omitempty"`
+	// Message is the message of an announcement.
+	Message string `json:"message,omitempty"`
+	// CreatedAt is the time of the event.
+	CreatedAt time.Time `json:"created_at"`
+	// Public is true if anyone could read the repository when the event
+	// happened. It's used to filter events of repositories that no longer
+	// exist.
+	Public bool `json:"-"`
+	// Remote is true for the events relayed from another server sharing the
+	// same database.
+	Remote bool `json:"-"`
+}
+
+// subscriptionSize is the number of events a subscriber can lag behind.
+const subscriptionSize = 64
+
+// Broker broadcasts events to its subscribers.
+type Broker struct {
+	mu     sync.Mutex
+	lastID int64
+	recent []Event
+	next   int
+	subs   map[*Subscription]struct{}
+}
+
+// NewBroker returns a new Broker keeping the size most recent events.
+func NewBroker(size int) *Broker {
+	if size < 1 {
+		size = 1
+	}
+	return &Broker{
+		// IDs start from the current time, so they keep increasing across
+		// restarts and a stale Last-Event-ID doesn't replay new events.
+		lastID: time.Now().UnixMicro(),
+		recent: make([]Event, 0, size),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns an ID to e and sends it to all the subscribers. It returns
+// the published event.
+func (b *Broker) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = b.lastID
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	if len(b.recent) < cap(b.recent) {
+		b.recent = append(b.recent, e)
+	} else {
+		b.recent[b.next] = e
+		b.next = (b.next + 1) % len(b.recent)
+	}
+
+	for s := range b.subs {
+		// Subscribers too slow to keep up are dropped, they can resume
+		// from the last event they got.
+		select {
+		case s.events <- e:
+		default:
+			b.remove(s)
+		}
+	}
+
+	return e
+}
+
+// Subscribe returns a new subscription to the events. Recent events with an
+// ID greater than lastID are sent first, lastID is zero to only get new
+// events.
+func (b *Broker) Subscribe(lastID int64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &Subscription{
+		broker: b,
+		events: make(chan Event, subscriptionSize+len(b.recent)),
+	}
+	if lastID > 0 {
+		for i := range b.recent {
+			e := b.recent[(b.next+i)%len(b.recent)]
+			if e.ID > lastID {
+				s.events <- e
+			}
+		}
+	}
+	b.subs[s] = struct{}{}
+
+	return s
+}
+
+// Recent returns the recent events kept by the broker, oldest first.
+func (b *Broker) Recent() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recent := make([]Event, 0, len(b.recent))
+	for i := range b.recent {
+		recent = append(recent, b.recent[(b.next+i)%len(b.recent)])
+	}
+
+	return recent
+}
+
+// remove removes a subscription. It must be called with the broker lock held.
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.events)
+	}
+}
+
+// Subscription receives the events of a Broker.
+type Subscription struct {
+	broker *Broker
+	events chan Event
+}
+
+// Events returns the channel of events. It's closed when the subscription
+// is closed, or when the subscriber falls too far behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unsubscribes from the events.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPublish(t *testing.T) {
+	is := is.New(t)
+	b := NewBroker(10)
+	s1 := b.Subscribe(0)
+	s2 := b.Subscribe(0)
+
+	e := b.Publish(Event{Type: RepoCreate, Repo: "repo1"})
+	is.True(e.ID > 0)
+	is.True(!e.CreatedAt.IsZero())
+	is.Equal(<-s1.Events(), e)
+	is.Equal(<-s2.Events(), e)
+
+	e2 := b.Publish(Event{Type: Push, Repo: "repo1"})
+	is.True(e2.ID > e.ID)
+
+	// Closed subscriptions don't get new events.
+	s2.Close()
+	b.Publish(Event{Type: Push, Repo: "repo2"})
+	is.Equal(<-s1.Events(), e2)
+	is.Equal(<-s2.Events(), e2)
+	_, ok := <-s2.Events()
+	is.True(!ok)
+	s2.Close()
+}
+
+func TestReplay(t *testing.T) {
+	is := is.New(t)
+	b := NewBroker(2)
+	e1 := b.Publish(Event{Type: Push, Repo: "repo1"})
+	e2 := b.Publish(Event{Type: Push, Repo: "repo2"})
+	e3 := b.Publish(Event{Type: Push, Repo: "repo3"})
+
+	// Only the most recent events are kept.
+	s := b.Subscribe(e1.ID - 1)
+	defer s.Close()
+	is.Equal(<-s.Events(), e2)
+	is.Equal(<-s.Events(), e3)
+
+	s2 := b.Subscribe(e2.ID)
+	defer s2.Close()
+	is.Equal(<-s2.Events(), e3)
+	e4 := b.Publish(Event{Type: Push, Repo: "repo4"})
+	is.Equal(<-s2.Events(), e4)
+}
+
+func TestRecent(t *testing.T) {
+	is := is.New(t)
+	b := NewBroker(2)
+	is.Equal(len(b.Recent()), 0)
+
+	b.Publish(Event{Type: Push, Repo: "repo1"})
+	e2 := b.Publish(Event{Type: Push, Repo: "repo2"})
+	e3 := b.Publish(Event{Type: Push, Repo: "repo3"})
+	is.Equal(b.Recent(), []Event{e2, e3})
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	is := is.New(t)
+	b := NewBroker(1)
+	s := b.Subscribe(0)
+
+	for i := 0; i <= subscriptionSize+1; i++ {
+		b.Publish(Event{Type: Push, Repo: "repo"})
+	}
+
+	var n int
+	for range s.Events() {
+		n++
+	}
+	is.Equal(n, subscriptionSize)
+}
@@ -12,6 +12,10 @@ var (
 	// ErrInvalidRepo represents an attempt to access a non-existent repo.
 	ErrInvalidRepo = errors.New("invalid repo")
 
+	// ErrNotExported represents an attempt to access a repo that isn't
+	// exported by the Git daemon.
+	ErrNotExported = errors.New("repository is not exported")
+
 	// ErrInvalidRequest represents an invalid request.
 	ErrInvalidRequest = errors.New("invalid request")
 
@@ -8,13 +8,13 @@ import (
 	"io"
 	"io/fs"
 	"path"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/git-lfs-transfer/transfer"
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/db/models"
@@ -42,6 +42,7 @@ type lfsTransfer struct {
 	logger  *log.Logger
 	storage storage.Storage
 	repo    proto.Repository
+	quota   func(size int64) error
 }
 
 var _ transfer.Backend = &lfsTransfer{}
@@ -93,16 +94,18 @@ func LFSTransfer(ctx context.Context, cmd ServiceCommand) error {
 		dbx:     db.FromContext(ctx),
 		store:   store.FromContext(ctx),
 		logger:  logger,
-		storage: storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID)),
+		storage: storage.NewLFSStorage(cfg, repoID),
 		repo:    repo,
+		quota:   cmd.CheckQuota,
 	})
 
 	return processor.ProcessCommands(op)
 }
 
 // Batch implements transfer.Backend.
-func (t *lfsTransfer) Batch(_ string, pointers []transfer.Pointer, _ map[string]string) ([]transfer.BatchItem, error) {
+func (t *lfsTransfer) Batch(op string, pointers []transfer.Pointer, _ map[string]string) ([]transfer.BatchItem, error) {
 	items := make([]transfer.BatchItem, 0)
+	var size int64
 	for _, p := range pointers {
 		obj, err := t.store.GetLFSObjectByOid(t.ctx, t.dbx, t.repo.ID(), p.Oid)
 		if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
@@ -120,6 +123,10 @@ func (t *lfsTransfer) Batch(_ string, pointers []transfer.Pointer, _ map[string]
 			}
 		}
 
+		if !exist {
+			size += p.Size
+		}
+
 		item := transfer.BatchItem{
 			Pointer: p,
 			Present: exist,
@@ -127,6 +134,12 @@ func (t *lfsTransfer) Batch(_ string, pointers []transfer.Pointer, _ map[string]
 		items = append(items, item)
 	}
 
+	if op == transfer.UploadOperation && t.quota != nil {
+		if err := t.quota(size); err != nil {
+			return nil, err
+		}
+	}
+
 	return items, nil
 }
 
@@ -134,7 +147,7 @@ func (t *lfsTransfer) Batch(_ string, pointers []transfer.Pointer, _ map[string]
 func (t *lfsTransfer) Download(oid string, _ map[string]string) (fs.File, error) {
 	cfg := config.FromContext(t.ctx)
 	repoID := strconv.FormatInt(t.repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg := storage.NewLFSStorage(cfg, repoID)
 	pointer := transfer.Pointer{Oid: oid}
 	return strg.Open(path.Join("objects", pointer.RelativePath()))
 }
@@ -310,11 +323,15 @@ func (l *lfsLockBackend) FromID(id string) (transfer.Lock, error) {
 
 	if err := l.dbx.TransactionContext(l.ctx, func(tx *db.Tx) error {
 		var err error
-		lock.lock, err = l.store.GetLFSLockForUserByID(l.ctx, tx, l.repo.ID(), l.user.ID(), iid)
+		lock.lock, err = l.store.GetLFSLockByID(l.ctx, tx, iid)
 		if err != nil {
 			return db.WrapError(err)
 		}
 
+		if lock.lock.RepoID != l.repo.ID() {
+			return db.ErrRecordNotFound
+		}
+
 		lock.owner, err = l.store.GetUserByID(l.ctx, tx, lock.lock.UserID)
 		return db.WrapError(err)
 	}); err != nil {
@@ -336,7 +353,7 @@ func (l *lfsLockBackend) FromPath(path string) (transfer.Lock, error) {
 
 	if err := l.dbx.TransactionContext(l.ctx, func(tx *db.Tx) error {
 		var err error
-		lock.lock, err = l.store.GetLFSLockForUserPath(l.ctx, tx, l.repo.ID(), l.user.ID(), path)
+		lock.lock, err = l.store.GetLFSLockForPath(l.ctx, tx, l.repo.ID(), path)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -420,14 +437,37 @@ func (l *lfsLockBackend) Unlock(lock transfer.Lock) error {
 	}
 
 	err = l.dbx.TransactionContext(l.ctx, func(tx *db.Tx) error {
+		mlock, err := l.store.GetLFSLockByID(l.ctx, tx, id)
+		if err != nil {
+			return db.WrapError(err)
+		}
+
+		if mlock.RepoID != l.repo.ID() {
+			return db.ErrRecordNotFound
+		}
+
+		if mlock.UserID == l.user.ID() {
+			return db.WrapError(
+				l.store.DeleteLFSLockForUserByID(l.ctx, tx, l.repo.ID(), l.user.ID(), id),
+			)
+		}
+
+		// Only repository admins can force delete another user's lock.
+		if l.args["force"] != "true" || access.FromContext(l.ctx) < access.AdminAccess {
+			return fs.ErrPermission
+		}
+
 		return db.WrapError(
-			l.store.DeleteLFSLockForUserByID(l.ctx, tx, l.repo.ID(), l.user.ID(), id),
+			l.store.DeleteLFSLock(l.ctx, tx, l.repo.ID(), id),
 		)
 	})
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return transfer.ErrNotFound
 		}
+		if errors.Is(err, fs.ErrPermission) {
+			return err
+		}
 		l.logger.Error("error unlocking lock", "err", err)
 		return err
 	}
@@ -467,7 +507,7 @@ func (l *LFSLock) AsLockSpec(ownerID bool) ([]string, error) {
 
 	if ownerID {
 		who := "theirs"
-		if l.lock.UserID == l.owner.ID {
+		if l.lock.UserID == l.backend.user.ID() {
 			who = "ours"
 		}
 
@@ -0,0 +1,240 @@
+package git
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Request describes what a client asked a git service for. It's filled in as
+// the request is read by the service.
+type Request struct {
+	// Wants is the number of objects the client wants from upload-pack.
+	Wants int
+	// Haves is the number of objects the client has. A fetch without haves
+	// is a clone.
+	Haves int
+	// Commands is the number of references the client updates with
+	// receive-pack.
+	Commands int
+	// Updates are the reference updates of receive-pack commands. Commands
+	// too long to be inspected are counted but not recorded.
+	Updates []RefUpdate
+	// RefPrefixes are the prefixes of the references a protocol v2 client
+	// lists from upload-pack to find the references it fetches.
+	RefPrefixes []string
+	// PushOptions are the options a client sends with receive-pack, with
+	// git push -o, as sent.
+	PushOptions []string
+}
+
+// RefUpdate is a reference a client updates with receive-pack. The old hash
+// of a created reference and the new hash of a deleted reference are zeros.
+type RefUpdate struct {
+	OldSha  string
+	NewSha  string
+	RefName string
+}
+
+// IsCreate returns true if the update creates the reference.
+func (u RefUpdate) IsCreate() bool {
+	return isZeroHash(u.OldSha)
+}
+
+// IsDelete returns true if the update deletes the reference.
+func (u RefUpdate) IsDelete() bool {
+	return isZeroHash(u.NewSha)
+}
+
+func isZeroHash(h string) bool {
+	return strings.Trim(h, "0") == ""
+}
+
+// PushOption returns the value of a push option of the form key=value, and
+// whether the client sent it. Options without a value have an empty one.
+func (r Request) PushOption(key string) (string, bool) {
+	for _, o := range r.PushOptions {
+		k, v, _ := strings.Cut(o, "=")
+		if k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// IsClone returns true if the request fetches a repository from scratch.
+func (r Request) IsClone() bool {
+	return r.Wants > 0 && r.Haves == 0
+}
+
+// IsFetch returns true if the request fetches objects missing from an
+// existing clone.
+func (r Request) IsFetch() bool {
+	return r.Wants > 0 && r.Haves > 0
+}
+
+// IsPush returns true if the request updates references.
+func (r Request) IsPush() bool {
+	return r.Commands > 0
+}
+
+// RequestReader reads the pkt-lines a client sends to a service and records
+// the request. Reading stops being inspected at the pack data of a push, or
+// at the first malformed pkt-line.
+type RequestReader struct {
+	r       io.Reader
+	service Service
+	req     Request
+
+	hdr       []byte
+	left      int
+	payload   []byte
+	truncated bool
+	done      bool
+
+	// pushOptions is true when the client sends push options after the
+	// receive-pack commands, and inOptions when they are being read.
+	pushOptions bool
+	inOptions   bool
+}
+
+// NewRequestReader returns a reader recording the request read from r by
+// service.
+func NewRequestReader(r io.Reader, service Service) *RequestReader {
+	// Lines are kept whole to record the listed reference prefixes and the
+	// updated references.
+	return &RequestReader{
+		r:       r,
+		service: service,
+		hdr:     make([]byte, 0, 4),
+		payload: make([]byte, 0, maxCommandSize),
+	}
+}
+
+// maxCommandSize is the size of the longest line recorded.
+const maxCommandSize = 1024
+
+// Read implements io.Reader.
+func (r *RequestReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.inspect(p[:n])
+	return n, err
+}
+
+// Request returns the request read so far.
+func (r *RequestReader) Request() Request {
+	return r.req
+}
+
+func (r *RequestReader) inspect(p []byte) {
+	for len(p) > 0 && !r.done {
+		if r.left == 0 {
+			n := copy(r.hdr[len(r.hdr):cap(r.hdr)], p)
+			r.hdr = r.hdr[:len(r.hdr)+n]
+			p = p[n:]
+			if len(r.hdr) < cap(r.hdr) {
+				return
+			}
+
+			size, err := strconv.ParseUint(string(r.hdr), 16, 16)
+			r.hdr = r.hdr[:0]
+			switch {
+			case err != nil || size == 3 || size > 65520:
+				r.done = true
+			case size < 4:
+				// Flush, delimiter and response end packets. Pushes send
+				// their push options, and then their pack data, after the
+				// commands.
+				if size == 0 && r.service == ReceivePackService {
+					if r.pushOptions && !r.inOptions {
+						r.inOptions = true
+					} else {
+						r.done = true
+					}
+				}
+			default:
+				r.left = int(size) - 4
+				r.payload = r.payload[:0]
+				r.truncated = r.left > cap(r.payload)
+				if r.left == 0 {
+					r.line()
+				}
+			}
+			continue
+		}
+
+		n := r.left
+		if n > len(p) {
+			n = len(p)
+		}
+		if keep := cap(r.payload) - len(r.payload); keep > 0 {
+			if keep > n {
+				keep = n
+			}
+			r.payload = append(r.payload, p[:keep]...)
+		}
+		r.left -= n
+		p = p[n:]
+		if r.left == 0 {
+			r.line()
+		}
+	}
+}
+
+var (
+	wantPrefix      = []byte("want ")
+	havePrefix      = []byte("have ")
+	refPrefixPrefix = []byte("ref-prefix ")
+	shallowPrefix   = []byte("shallow ")
+)
+
+func (r *RequestReader) line() {
+	switch r.service {
+	case UploadPackService:
+		switch {
+		case bytes.HasPrefix(r.payload, wantPrefix):
+			r.req.Wants++
+		case bytes.HasPrefix(r.payload, havePrefix):
+			r.req.Haves++
+		case bytes.HasPrefix(r.payload, refPrefixPrefix) && !r.truncated:
+			prefix := bytes.TrimSuffix(r.payload[len(refPrefixPrefix):], []byte("\n"))
+			r.req.RefPrefixes = append(r.req.RefPrefixes, string(prefix))
+		}
+	case ReceivePackService:
+		if r.inOptions {
+			if !r.truncated {
+				r.req.PushOptions = append(r.req.PushOptions, string(bytes.TrimSuffix(r.payload, []byte("\n"))))
+			}
+			return
+		}
+		if bytes.HasPrefix(r.payload, shallowPrefix) {
+			return
+		}
+		r.req.Commands++
+		if r.truncated {
+			return
+		}
+		// The first command carries the capabilities after a NUL byte.
+		cmd := r.payload
+		if i := bytes.IndexByte(cmd, 0); i >= 0 {
+			if r.req.Commands == 1 {
+				caps := strings.Fields(string(bytes.TrimSuffix(cmd[i+1:], []byte("\n"))))
+				for _, c := range caps {
+					if c == "push-options" {
+						r.pushOptions = true
+					}
+				}
+			}
+			cmd = cmd[:i]
+		}
+		fields := strings.Fields(string(cmd))
+		if len(fields) == 3 {
+			r.req.Updates = append(r.req.Updates, RefUpdate{
+				OldSha:  fields[0],
+				NewSha:  fields[1],
+				RefName: fields[2],
+			})
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestRequestReader(t *testing.T) {
+	const (
+		oid1 = "1111111111111111111111111111111111111111"
+		oid2 = "2222222222222222222222222222222222222222"
+		zero = "0000000000000000000000000000000000000000"
+	)
+
+	cases := []struct {
+		name    string
+		service Service
+		in      string
+		want    Request
+		clone   bool
+		fetch   bool
+		push    bool
+	}{
+		{
+			name:    "ls-remote",
+			service: UploadPackService,
+			in:      "0000",
+		},
+		{
+			name:    "clone",
+			service: UploadPackService,
+			in:      "0032want " + oid1 + "\n" + "0000" + "0009done\n",
+			want:    Request{Wants: 1},
+			clone:   true,
+		},
+		{
+			name:    "fetch",
+			service: UploadPackService,
+			in:      "0032want " + oid1 + "\n" + "0000" + "0032have " + oid2 + "\n" + "0009done\n",
+			want:    Request{Wants: 1, Haves: 1},
+			fetch:   true,
+		},
+		{
+			name:    "fetch v2",
+			service: UploadPackService,
+			in: "0012command=fetch\n" + "0001" + "0032want " + oid1 + "\n" +
+				"0032have " + oid2 + "\n" + "0032have " + oid1 + "\n" + "0000",
+			want:  Request{Wants: 1, Haves: 2},
+			fetch: true,
+		},
+		{
+			name:    "ls-refs v2",
+			service: UploadPackService,
+			in: "0014command=ls-refs\n" + "0001" + "0009peel\n" +
+				"0014ref-prefix HEAD\n" + "001fref-prefix refs/heads/main\n" + "0000",
+			want: Request{RefPrefixes: []string{"HEAD", "refs/heads/main"}},
+		},
+		{
+			name:    "push",
+			service: ReceivePackService,
+			in:      "0074" + oid1 + " " + oid2 + " refs/heads/main\x00report-status\n" + "0000" + "PACK0000want",
+			want: Request{Commands: 1, Updates: []RefUpdate{
+				{OldSha: oid1, NewSha: oid2, RefName: "refs/heads/main"},
+			}},
+			push: true,
+		},
+		{
+			name:    "push many",
+			service: ReceivePackService,
+			in: "0013shallow " + oid1[:6] + "\n" +
+				"0073" + zero + " " + oid2 + " refs/tags/v1.0\x00report-status\n" +
+				"0064" + oid1 + " " + zero + " refs/heads/old\n" + "0000" + "PACK",
+			want: Request{Commands: 2, Updates: []RefUpdate{
+				{OldSha: zero, NewSha: oid2, RefName: "refs/tags/v1.0"},
+				{OldSha: oid1, NewSha: zero, RefName: "refs/heads/old"},
+			}},
+			push: true,
+		},
+		{
+			name:    "push options",
+			service: ReceivePackService,
+			in: "0081" + oid1 + " " + oid2 + " refs/heads/main\x00report-status push-options\n" + "0000" +
+				"000bskip-ci" + "001bdescription=Hello world" + "0000" + "PACK0000want",
+			want: Request{Commands: 1, Updates: []RefUpdate{
+				{OldSha: oid1, NewSha: oid2, RefName: "refs/heads/main"},
+			}, PushOptions: []string{"skip-ci", "description=Hello world"}},
+			push: true,
+		},
+		{
+			name:    "push long ref",
+			service: ReceivePackService,
+			in:      "044b" + oid1 + " " + oid2 + " refs/heads/" + strings.Repeat("x", 1001) + "\n" + "0000",
+			want:    Request{Commands: 1},
+			push:    true,
+		},
+		{
+			name:    "malformed",
+			service: UploadPackService,
+			in:      "0032want " + oid1 + "\n" + "zzzz" + "0032have " + oid2 + "\n",
+			want:    Request{Wants: 1},
+			clone:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, oneByte := range []bool{false, true} {
+				var in io.Reader = bytes.NewBufferString(c.in)
+				if oneByte {
+					in = iotest.OneByteReader(in)
+				}
+
+				rr := NewRequestReader(in, c.service)
+				out, err := io.ReadAll(rr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(out) != c.in {
+					t.Errorf("expected the request to be read as is, got %q", out)
+				}
+
+				req := rr.Request()
+				if !reflect.DeepEqual(req, c.want) {
+					t.Errorf("expected %+v, got %+v", c.want, req)
+				}
+				if req.IsClone() != c.clone || req.IsFetch() != c.fetch || req.IsPush() != c.push {
+					t.Errorf("unexpected kind of request %+v", req)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestPushOption(t *testing.T) {
+	req := Request{PushOptions: []string{"skip-ci", "description=Hello=world"}}
+	if v, ok := req.PushOption("skip-ci"); !ok || v != "" {
+		t.Errorf("expected skip-ci without a value, got %q %t", v, ok)
+	}
+	if v, ok := req.PushOption("description"); !ok || v != "Hello=world" {
+		t.Errorf("expected the description, got %q %t", v, ok)
+	}
+	if _, ok := req.PushOption("create-pr"); ok {
+		t.Error("expected create-pr to be missing")
+	}
+}
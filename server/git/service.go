@@ -9,6 +9,8 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/charmbracelet/soft-serve/server/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -56,7 +58,10 @@ func (s Service) Handler(ctx context.Context, cmd ServiceCommand) error {
 type ServiceHandler func(ctx context.Context, cmd ServiceCommand) error
 
 // gitServiceHandler is the default service handler using the git binary.
-func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) error {
+func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) (err error) {
+	ctx, span := tracing.Start(ctx, svc.Name(), attribute.String("git.dir", scmd.Dir))
+	defer func() { tracing.End(span, err) }()
+
 	cmd := exec.CommandContext(ctx, "git")
 	cmd.Dir = scmd.Dir
 	cmd.Args = append(cmd.Args, []string{
@@ -84,7 +89,6 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 	}
 
 	var (
-		err    error
 		stdin  io.WriteCloser
 		stdout io.ReadCloser
 		stderr io.ReadCloser
@@ -166,6 +170,10 @@ type ServiceCommand struct {
 
 	// Modifier functions
 	CmdFunc func(*exec.Cmd)
+
+	// CheckQuota, when set, is called by the Git LFS transfer service with
+	// the size of the objects about to be uploaded.
+	CheckQuota func(size int64) error
 }
 
 // UploadPack runs the git upload-pack protocol against the provided repo.
@@ -0,0 +1,539 @@
+// Package graphql executes GraphQL queries against a schema of Go resolvers.
+//
+// It implements the query language of the GraphQL specification: fields,
+// arguments, aliases, variables, fragments, and the @include and @skip
+// directives. Schemas are made of objects whose fields are resolved by Go
+// functions, values are either objects or JSON scalars. Mutations,
+// subscriptions, and introspection other than __typename aren't supported.
+// https://spec.graphql.org/October2021/
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Kind is the kind of an argument.
+type Kind int
+
+const (
+	// String is a string argument.
+	String Kind = iota
+	// Int is an integer argument.
+	Int
+	// Boolean is a boolean argument.
+	Boolean
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Int:
+		return "Int"
+	case Boolean:
+		return "Boolean"
+	default:
+		return "String"
+	}
+}
+
+// Object is an object type of a schema.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field is a field of an object.
+type Field struct {
+	// Type is the object type of the value, or of the values of the list,
+	// returned by Resolve. It's nil for scalars.
+	Type *Object
+	// Args are the kinds of the arguments the field accepts.
+	Args map[string]Kind
+	// Resolve returns the value of the field of source. Nil values are
+	// null.
+	Resolve func(ctx context.Context, source interface{}, args Args) (interface{}, error)
+}
+
+// Args are the arguments of a field. Arguments not set by the query aren't
+// present.
+type Args map[string]interface{}
+
+// String returns a string argument, or def when it's not set.
+func (a Args) String(name string, def string) string {
+	if s, ok := a[name].(string); ok {
+		return s
+	}
+	return def
+}
+
+// Int returns an integer argument, or def when it's not set.
+func (a Args) Int(name string, def int) int {
+	if n, ok := a[name].(int); ok {
+		return n
+	}
+	return def
+}
+
+// Bool returns a boolean argument, or def when it's not set.
+func (a Args) Bool(name string, def bool) bool {
+	if b, ok := a[name].(bool); ok {
+		return b
+	}
+	return def
+}
+
+// Schema is a GraphQL schema.
+type Schema struct {
+	// Query is the root object of queries.
+	Query *Object
+	// MaxDepth is the maximum depth of the selections of a query, zero for
+	// no limit.
+	MaxDepth int
+	// MaxFields is the maximum number of fields resolved by a query, zero
+	// for no limit.
+	MaxFields int
+}
+
+// Request is a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is a GraphQL response. Data is nil when the request couldn't be
+// executed.
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Location is a location in a query.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an error of a request, or of the field at Path.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// errTooComplex is returned when a query resolves too many fields.
+var errTooComplex = errors.New("query is too complex")
+
+// Execute executes a query request.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		var se *syntaxError
+		if errors.As(err, &se) {
+			return &Response{Errors: []*Error{{Message: se.Error(), Locations: []Location{se.loc}}}}
+		}
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	vars, err := coerceVariables(op, req.Variables)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{schema: s, doc: doc, vars: vars}
+	if s.MaxDepth > 0 {
+		if err := e.checkDepth(op.selections, 1, map[string]bool{}); err != nil {
+			return &Response{Errors: []*Error{err}}
+		}
+	}
+
+	data := e.selectionSet(ctx, s.Query, nil, op.selections, nil)
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// operation returns the operation to execute.
+func (d *document) operation(name string) (*operation, error) {
+	var op *operation
+	for _, o := range d.operations {
+		if name == "" && len(d.operations) > 1 {
+			return nil, errors.New("operation name needed, the document has many operations")
+		}
+		if name == "" || o.name == name {
+			op = o
+			break
+		}
+	}
+
+	switch {
+	case op == nil:
+		return nil, fmt.Errorf("operation %q not found", name)
+	case op.kind != "query":
+		return nil, fmt.Errorf("%s operations are not supported", op.kind)
+	}
+
+	return op, nil
+}
+
+// coerceVariables returns the values of the variables of op.
+func coerceVariables(op *operation, in map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	for _, v := range op.variables {
+		val, ok := in[v.name]
+		if !ok {
+			if v.defValue != nil {
+				vars[v.name] = v.defValue
+			} else if v.nonNull {
+				return nil, fmt.Errorf("variable $%s of type %s! is required", v.name, v.typ)
+			}
+			continue
+		}
+		if val == nil && v.nonNull {
+			return nil, fmt.Errorf("variable $%s of type %s! can't be null", v.name, v.typ)
+		}
+
+		// JSON numbers are floats.
+		if f, ok := val.(float64); ok && v.typ == "Int" {
+			if f != math.Trunc(f) || f > math.MaxInt32 || f < math.MinInt32 {
+				return nil, fmt.Errorf("variable $%s is not an Int", v.name)
+			}
+			val = int(f)
+		}
+		vars[v.name] = val
+	}
+
+	return vars, nil
+}
+
+// executor executes an operation.
+type executor struct {
+	schema *Schema
+	doc    *document
+	vars   map[string]interface{}
+	errors []*Error
+	fields int
+}
+
+// checkDepth returns an error if sels are deeper than the limit of the
+// schema.
+func (e *executor) checkDepth(sels []selection, depth int, visited map[string]bool) *Error {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			if len(sel.selections) == 0 {
+				continue
+			}
+			if depth >= e.schema.MaxDepth {
+				return &Error{Message: "query is too deep", Locations: []Location{sel.loc}}
+			}
+			if err := e.checkDepth(sel.selections, depth+1, visited); err != nil {
+				return err
+			}
+		case *inlineFragment:
+			if err := e.checkDepth(sel.selections, depth, visited); err != nil {
+				return err
+			}
+		case *fragmentSpread:
+			f, ok := e.doc.fragments[sel.name]
+			if !ok || visited[sel.name] {
+				continue
+			}
+			visited[sel.name] = true
+			err := e.checkDepth(f.selections, depth, visited)
+			delete(visited, sel.name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// result is the result of a selection set, its fields are kept in the
+// order of the query.
+type result struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r *result) MarshalJSON() ([]byte, error) {
+	buf := []byte{'{'}
+	for i, k := range r.keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(r.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, kb...)
+		buf = append(buf, ':')
+		buf = append(buf, vb...)
+	}
+	return append(buf, '}'), nil
+}
+
+// fieldError records an error of the field at path.
+func (e *executor) fieldError(f *field, path []interface{}, err error) {
+	var gerr *Error
+	if !errors.As(err, &gerr) {
+		gerr = &Error{Message: err.Error()}
+	}
+	e.errors = append(e.errors, &Error{
+		Message:   gerr.Message,
+		Locations: []Location{f.loc},
+		Path:      append([]interface{}{}, path...),
+	})
+}
+
+// selectionSet resolves the selections of source of type obj.
+func (e *executor) selectionSet(ctx context.Context, obj *Object, source interface{}, sels []selection, path []interface{}) *result {
+	res := &result{values: make(map[string]interface{})}
+	fields := make(map[string][]*field)
+	e.collectFields(obj, sels, map[string]bool{}, res, fields)
+
+	for _, key := range res.keys {
+		fs := fields[key]
+		f := fs[0]
+		fpath := append(append([]interface{}{}, path...), key)
+		if f.name == "__typename" {
+			res.values[key] = obj.Name
+			continue
+		}
+
+		def, ok := obj.Fields[f.name]
+		if !ok {
+			e.fieldError(f, fpath, fmt.Errorf("cannot query field %q on type %q", f.name, obj.Name))
+			continue
+		}
+
+		if ctx.Err() != nil {
+			e.fieldError(f, fpath, ctx.Err())
+			continue
+		}
+		if e.schema.MaxFields > 0 {
+			e.fields++
+			if e.fields > e.schema.MaxFields {
+				e.fieldError(f, fpath, errTooComplex)
+				continue
+			}
+		}
+
+		args, err := e.arguments(def, f.args)
+		if err != nil {
+			e.fieldError(f, fpath, err)
+			continue
+		}
+
+		// Fields with the same response key are merged.
+		var subs []selection
+		for _, ff := range fs {
+			subs = append(subs, ff.selections...)
+		}
+		switch {
+		case def.Type == nil && len(subs) > 0:
+			e.fieldError(f, fpath, fmt.Errorf("field %q of type %q is a scalar and can't have a selection", f.name, obj.Name))
+			continue
+		case def.Type != nil && len(subs) == 0:
+			e.fieldError(f, fpath, fmt.Errorf("field %q of type %q needs a selection of subfields", f.name, obj.Name))
+			continue
+		}
+
+		val, err := def.Resolve(ctx, source, args)
+		if err != nil {
+			e.fieldError(f, fpath, err)
+			continue
+		}
+
+		res.values[key] = e.complete(ctx, def.Type, val, subs, fpath)
+	}
+
+	return res
+}
+
+// complete returns the result of a resolved value.
+func (e *executor) complete(ctx context.Context, typ *Object, val interface{}, sels []selection, path []interface{}) interface{} {
+	if isNil(val) {
+		return nil
+	}
+	if t, ok := val.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	rv := reflect.ValueOf(val)
+	if typ == nil {
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return []interface{}{}
+		}
+		return val
+	}
+
+	if rv.Kind() == reflect.Slice {
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = e.complete(ctx, typ, rv.Index(i).Interface(), sels, append(path, i))
+		}
+		return list
+	}
+
+	return e.selectionSet(ctx, typ, val, sels, path)
+}
+
+// isNil returns true if v is nil, or a nil pointer or map. Nil slices are
+// empty lists.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// collectFields collects the fields of sels applying to obj, grouped by
+// response key.
+func (e *executor) collectFields(obj *Object, sels []selection, visited map[string]bool, res *result, fields map[string][]*field) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			key := sel.key()
+			if _, ok := fields[key]; !ok {
+				res.keys = append(res.keys, key)
+			}
+			fields[key] = append(fields[key], sel)
+		case *inlineFragment:
+			if !e.included(sel.directives) || sel.on != "" && sel.on != obj.Name {
+				continue
+			}
+			e.collectFields(obj, sel.selections, visited, res, fields)
+		case *fragmentSpread:
+			if !e.included(sel.directives) || visited[sel.name] {
+				continue
+			}
+			visited[sel.name] = true
+			f, ok := e.doc.fragments[sel.name]
+			if !ok {
+				e.errors = append(e.errors, &Error{
+					Message:   fmt.Sprintf("fragment %q not found", sel.name),
+					Locations: []Location{sel.loc},
+				})
+				continue
+			}
+			if f.on != obj.Name || !e.included(f.directives) {
+				continue
+			}
+			e.collectFields(obj, f.selections, visited, res, fields)
+		}
+	}
+}
+
+// included returns true unless the @skip or @include directives exclude a
+// selection.
+func (e *executor) included(dirs []*directive) bool {
+	for _, d := range dirs {
+		var cond bool
+		for _, a := range d.args {
+			if a.name == "if" {
+				cond, _ = e.resolve(a.value).(bool)
+			}
+		}
+		switch d.name {
+		case "skip":
+			if cond {
+				return false
+			}
+		case "include":
+			if !cond {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resolve returns the value of an input value with its variables replaced.
+func (e *executor) resolve(v value) interface{} {
+	switch v := v.(type) {
+	case variable:
+		return e.resolve(e.vars[string(v)])
+	case enumValue:
+		return string(v)
+	case listValue:
+		list := make([]interface{}, len(v))
+		for i, vv := range v {
+			list[i] = e.resolve(vv)
+		}
+		return list
+	case objectValue:
+		obj := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			obj[k] = e.resolve(vv)
+		}
+		return obj
+	default:
+		return v
+	}
+}
+
+// arguments returns the arguments of a field.
+func (e *executor) arguments(def *Field, in []*argument) (Args, error) {
+	args := make(Args, len(in))
+	for _, a := range in {
+		kind, ok := def.Args[a.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown argument %q", a.name)
+		}
+		if _, ok := args[a.name]; ok {
+			return nil, fmt.Errorf("argument %q is set twice", a.name)
+		}
+
+		// Unset variables are the same as unset arguments.
+		if v, ok := a.value.(variable); ok {
+			if _, ok := e.vars[string(v)]; !ok {
+				continue
+			}
+		}
+
+		val := e.resolve(a.value)
+		switch val.(type) {
+		case nil:
+			continue
+		case string:
+			ok = kind == String
+		case int:
+			ok = kind == Int
+		case bool:
+			ok = kind == Boolean
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, fmt.Errorf("argument %q must be a %s", a.name, kind)
+		}
+		args[a.name] = val
+	}
+
+	return args, nil
+}
@@ -0,0 +1,267 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type testBranch struct {
+	name    string
+	commits int
+}
+
+type testRepo struct {
+	name     string
+	branches []testBranch
+}
+
+func testSchema() *Schema {
+	repos := []*testRepo{
+		{name: "repo1", branches: []testBranch{{"main", 3}, {"dev", 1}}},
+		{name: "repo2"},
+	}
+
+	branch := &Object{Name: "Branch", Fields: map[string]*Field{
+		"name": {Resolve: func(_ context.Context, src interface{}, _ Args) (interface{}, error) {
+			return src.(testBranch).name, nil
+		}},
+		"commits": {Resolve: func(_ context.Context, src interface{}, _ Args) (interface{}, error) {
+			return src.(testBranch).commits, nil
+		}},
+	}}
+	repo := &Object{Name: "Repository", Fields: map[string]*Field{
+		"name": {Resolve: func(_ context.Context, src interface{}, _ Args) (interface{}, error) {
+			return src.(*testRepo).name, nil
+		}},
+		"branches": {
+			Type: branch,
+			Args: map[string]Kind{"first": Int},
+			Resolve: func(_ context.Context, src interface{}, args Args) (interface{}, error) {
+				bs := src.(*testRepo).branches
+				if n := args.Int("first", len(bs)); n < len(bs) {
+					bs = bs[:n]
+				}
+				return bs, nil
+			},
+		},
+		"broken": {Resolve: func(context.Context, interface{}, Args) (interface{}, error) {
+			return nil, errors.New("broken")
+		}},
+	}}
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"repository": {
+			Type: repo,
+			Args: map[string]Kind{"name": String},
+			Resolve: func(_ context.Context, _ interface{}, args Args) (interface{}, error) {
+				for _, r := range repos {
+					if r.name == args.String("name", "") {
+						return r, nil
+					}
+				}
+				return (*testRepo)(nil), nil
+			},
+		},
+		"repositories": {
+			Type: repo,
+			Resolve: func(context.Context, interface{}, Args) (interface{}, error) {
+				return repos, nil
+			},
+		},
+	}}
+
+	return &Schema{Query: query, MaxDepth: 3, MaxFields: 50}
+}
+
+func execute(t *testing.T, query string, vars map[string]interface{}) (string, []*Error) {
+	t.Helper()
+	res := testSchema().Execute(context.Background(), Request{Query: query, Variables: vars})
+	out, err := json.Marshal(res.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), res.Errors
+}
+
+func TestExecute(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		vars  map[string]interface{}
+		want  string
+	}{
+		{
+			name:  "shorthand",
+			query: `{ repositories { name } }`,
+			want:  `{"repositories":[{"name":"repo1"},{"name":"repo2"}]}`,
+		},
+		{
+			name: "nested",
+			query: `query Repo {
+				repository(name: "repo1") { name, branches(first: 1) { name commits } }
+			}`,
+			want: `{"repository":{"name":"repo1","branches":[{"name":"main","commits":3}]}}`,
+		},
+		{
+			name:  "aliases",
+			query: `{ a: repository(name: "repo2") { n: name } b: repository(name: "nope") { name } }`,
+			want:  `{"a":{"n":"repo2"},"b":null}`,
+		},
+		{
+			name:  "variables",
+			query: `query ($name: String!, $first: Int = 5) { repository(name: $name) { branches(first: $first) { name } } }`,
+			vars:  map[string]interface{}{"name": "repo1", "first": float64(1)},
+			want:  `{"repository":{"branches":[{"name":"main"}]}}`,
+		},
+		{
+			name: "fragments",
+			query: `{ repository(name: "repo1") { ...Repo ... on Repository { __typename } } }
+				fragment Repo on Repository { name branches { ... on Branch { name } } }`,
+			want: `{"repository":{"name":"repo1","branches":[{"name":"main"},{"name":"dev"}],"__typename":"Repository"}}`,
+		},
+		{
+			name:  "directives",
+			query: `query ($yes: Boolean!) { repository(name: "repo2") { name @skip(if: $yes) n: name @include(if: $yes) } }`,
+			vars:  map[string]interface{}{"yes": true},
+			want:  `{"repository":{"n":"repo2"}}`,
+		},
+		{
+			name:  "merged fields",
+			query: `{ repository(name: "repo1") { branches { name } branches { commits } } }`,
+			want:  `{"repository":{"branches":[{"name":"main","commits":3},{"name":"dev","commits":1}]}}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			is := is.New(t)
+			out, errs := execute(t, c.query, c.vars)
+			is.Equal(len(errs), 0)
+			is.Equal(out, c.want)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		vars  map[string]interface{}
+		data  string
+		err   string
+	}{
+		{
+			name:  "syntax",
+			query: `{ repositories { name }`,
+			data:  `null`,
+			err:   "syntax error: unexpected end of document at 1:24",
+		},
+		{
+			name:  "mutation",
+			query: `mutation { repositories { name } }`,
+			data:  `null`,
+			err:   "mutation operations are not supported",
+		},
+		{
+			name:  "missing variable",
+			query: `query ($name: String!) { repository(name: $name) { name } }`,
+			data:  `null`,
+			err:   "variable $name of type String! is required",
+		},
+		{
+			name:  "many operations",
+			query: `{ repository(name: "repo1") { branches { name } } } { repositories { branches { name } } }`,
+			data:  `null`,
+			err:   "operation name needed, the document has many operations",
+		},
+		{
+			name:  "depth",
+			query: `{ repositories { ...F } } fragment F on Repository { branches { name } ...F }`,
+			data:  `{"repositories":[{"branches":[{"name":"main"},{"name":"dev"}]},{"branches":[]}]}`,
+		},
+		{
+			name:  "unknown field",
+			query: `{ repository(name: "repo1") { name nope } }`,
+			data:  `{"repository":{"name":"repo1","nope":null}}`,
+			err:   `cannot query field "nope" on type "Repository"`,
+		},
+		{
+			name:  "resolver error",
+			query: `{ repositories { broken } }`,
+			data:  `{"repositories":[{"broken":null},{"broken":null}]}`,
+			err:   "broken",
+		},
+		{
+			name:  "argument type",
+			query: `{ repository(name: 1) { name } }`,
+			data:  `{"repository":null}`,
+			err:   `argument "name" must be a String`,
+		},
+		{
+			name:  "scalar selection",
+			query: `{ repositories { name { x } } }`,
+			data:  `{"repositories":[{"name":null},{"name":null}]}`,
+			err:   `field "name" of type "Repository" is a scalar and can't have a selection`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			is := is.New(t)
+			out, errs := execute(t, c.query, c.vars)
+			is.Equal(out, c.data)
+			if c.err == "" {
+				is.Equal(len(errs), 0)
+				return
+			}
+			is.True(len(errs) > 0)
+			is.Equal(errs[0].Message, c.err)
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	is := is.New(t)
+	_, errs := execute(t, `{ repositories { branches { name { x } } } }`, nil)
+	is.Equal(len(errs), 1)
+	is.Equal(errs[0].Message, "query is too deep")
+	is.Equal(errs[0].Locations, []Location{{Line: 1, Column: 29}})
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i < 60; i++ {
+		sb.WriteString(" r" + strings.Repeat("x", i) + `: repository(name: "repo2") { name }`)
+	}
+	sb.WriteString("}")
+	_, errs = execute(t, sb.String(), nil)
+	is.True(len(errs) > 0)
+	is.Equal(errs[0].Message, "query is too complex")
+}
+
+func TestParse(t *testing.T) {
+	is := is.New(t)
+	doc, err := parse("\ufeff# comment\nquery Q($a: [Int!]! = [1, 2]) {\n  f(s: \"a\\n\\u0041\", b: \"\"\"\n    block\n      string\n  \"\"\", o: {x: -1.5e3, e: ENUM, n: null}) @skip(if: false)\n}")
+	is.NoErr(err)
+	is.Equal(len(doc.operations), 1)
+	op := doc.operations[0]
+	is.Equal(op.name, "Q")
+	is.Equal(op.variables[0].typ, "[Int!]")
+	is.True(op.variables[0].nonNull)
+	is.Equal(op.variables[0].defValue, listValue{1, 2})
+
+	f := op.selections[0].(*field)
+	is.Equal(f.loc, Location{Line: 3, Column: 3})
+	is.Equal(f.args[0].value, "a\nA")
+	is.Equal(f.args[1].value, "block\n  string")
+	is.Equal(f.args[2].value, objectValue{"x": -1500.0, "e": enumValue("ENUM"), "n": nil})
+	is.Equal(f.directives[0].name, "skip")
+
+	for _, q := range []string{``, `{}`, `{ a(`, `{ "a" }`, `query { a } fragment on on X { a }`, `{ a(b: "x\q") }`, `{ a(b: "x) }`} {
+		_, err := parse(q)
+		is.True(err != nil)
+	}
+}
@@ -0,0 +1,632 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed GraphQL document.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is an operation definition.
+type operation struct {
+	kind       string
+	name       string
+	variables  []*variableDefinition
+	selections []selection
+}
+
+// variableDefinition is the definition of an operation variable.
+type variableDefinition struct {
+	name     string
+	typ      string
+	nonNull  bool
+	defValue value
+}
+
+// fragment is a named fragment definition.
+type fragment struct {
+	name       string
+	on         string
+	directives []*directive
+	selections []selection
+}
+
+// selection is a field, a fragment spread, or an inline fragment.
+type selection interface{}
+
+// field is a field selection.
+type field struct {
+	alias      string
+	name       string
+	args       []*argument
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+// key returns the response key of the field.
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// fragmentSpread is a spread of a named fragment.
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+// inlineFragment is an inline fragment.
+type inlineFragment struct {
+	on         string
+	directives []*directive
+	selections []selection
+}
+
+// argument is an argument of a field or a directive.
+type argument struct {
+	name  string
+	value value
+}
+
+// directive is a directive of a selection.
+type directive struct {
+	name string
+	args []*argument
+	loc  Location
+}
+
+// value is an input value. Literals are Go values, variables, enums, lists
+// and objects have their own types.
+type value interface{}
+
+type (
+	variable    string
+	enumValue   string
+	listValue   []value
+	objectValue map[string]value
+)
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token is a lexical token.
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+// parser is a recursive descent parser of GraphQL documents.
+// https://spec.graphql.org/October2021/#sec-Appendix-Grammar-Summary
+type parser struct {
+	src  string
+	pos  int
+	line int
+	col  int
+	tok  token
+}
+
+// syntaxError is an error at a location of a document.
+type syntaxError struct {
+	msg string
+	loc Location
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("syntax error: %s at %d:%d", e.msg, e.loc.Line, e.loc.Column)
+}
+
+// parse parses a GraphQL document.
+func parse(src string) (doc *document, err error) {
+	p := &parser{src: src, line: 1, col: 1}
+	defer func() {
+		if r := recover(); r != nil {
+			se, ok := r.(*syntaxError)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, se
+		}
+	}()
+
+	p.next()
+	doc = &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunct, "{"):
+			doc.operations = append(doc.operations, &operation{
+				kind:       "query",
+				selections: p.parseSelectionSet(),
+			})
+		case p.peek(tokenName, "fragment"):
+			f := p.parseFragment()
+			if _, ok := doc.fragments[f.name]; ok {
+				p.fail(fmt.Sprintf("fragment %q is defined twice", f.name))
+			}
+			doc.fragments[f.name] = f
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			doc.operations = append(doc.operations, p.parseOperation())
+		default:
+			p.unexpected()
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		p.fail("no operation")
+	}
+
+	return doc, nil
+}
+
+func (p *parser) fail(msg string) {
+	panic(&syntaxError{msg: msg, loc: p.tok.loc})
+}
+
+func (p *parser) unexpected() {
+	if p.tok.kind == tokenEOF {
+		p.fail("unexpected end of document")
+	}
+	p.fail(fmt.Sprintf("unexpected %q", p.tok.value))
+}
+
+// peek returns true if the current token is of kind with value v, any value
+// when v is empty.
+func (p *parser) peek(kind tokenKind, v string) bool {
+	return p.tok.kind == kind && (v == "" || p.tok.value == v)
+}
+
+// skip consumes the current token if it's the punctuator v.
+func (p *parser) skip(v string) bool {
+	if p.peek(tokenPunct, v) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// expect consumes the punctuator v.
+func (p *parser) expect(v string) {
+	if !p.skip(v) {
+		p.unexpected()
+	}
+}
+
+// name consumes a name.
+func (p *parser) name() string {
+	if p.tok.kind != tokenName {
+		p.unexpected()
+	}
+	n := p.tok.value
+	p.next()
+	return n
+}
+
+func (p *parser) parseOperation() *operation {
+	op := &operation{kind: p.name()}
+	if p.tok.kind == tokenName {
+		op.name = p.name()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			p.expect("$")
+			v := &variableDefinition{name: p.name()}
+			p.expect(":")
+			v.typ, v.nonNull = p.parseType()
+			if p.skip("=") {
+				v.defValue = p.parseValue(true)
+			}
+			p.parseDirectives()
+			op.variables = append(op.variables, v)
+		}
+	}
+	p.parseDirectives()
+	op.selections = p.parseSelectionSet()
+	return op
+}
+
+// parseType parses a variable type, it returns the type without its
+// non-null marker.
+func (p *parser) parseType() (string, bool) {
+	var typ string
+	if p.skip("[") {
+		t, nonNull := p.parseType()
+		if nonNull {
+			t += "!"
+		}
+		p.expect("]")
+		typ = "[" + t + "]"
+	} else {
+		typ = p.name()
+	}
+	return typ, p.skip("!")
+}
+
+func (p *parser) parseFragment() *fragment {
+	p.next()
+	f := &fragment{name: p.name()}
+	if f.name == "on" {
+		p.fail("fragments can't be named on")
+	}
+	if p.name() != "on" {
+		p.fail("expected a type condition")
+	}
+	f.on = p.name()
+	f.directives = p.parseDirectives()
+	f.selections = p.parseSelectionSet()
+	return f
+}
+
+func (p *parser) parseSelectionSet() []selection {
+	p.expect("{")
+	var sels []selection
+	for !p.skip("}") {
+		sels = append(sels, p.parseSelection())
+	}
+	if len(sels) == 0 {
+		p.fail("empty selection set")
+	}
+	return sels
+}
+
+func (p *parser) parseSelection() selection {
+	loc := p.tok.loc
+	if p.skip("...") {
+		if p.peek(tokenName, "") && p.tok.value != "on" {
+			return &fragmentSpread{name: p.name(), directives: p.parseDirectives(), loc: loc}
+		}
+		f := &inlineFragment{}
+		if p.peek(tokenName, "on") {
+			p.next()
+			f.on = p.name()
+		}
+		f.directives = p.parseDirectives()
+		f.selections = p.parseSelectionSet()
+		return f
+	}
+
+	f := &field{name: p.name(), loc: loc}
+	if p.skip(":") {
+		f.alias, f.name = f.name, p.name()
+	}
+	f.args = p.parseArguments(false)
+	f.directives = p.parseDirectives()
+	if p.peek(tokenPunct, "{") {
+		f.selections = p.parseSelectionSet()
+	}
+	return f
+}
+
+func (p *parser) parseArguments(constant bool) []*argument {
+	var args []*argument
+	if p.skip("(") {
+		for !p.skip(")") {
+			a := &argument{name: p.name()}
+			p.expect(":")
+			a.value = p.parseValue(constant)
+			args = append(args, a)
+		}
+	}
+	return args
+}
+
+func (p *parser) parseDirectives() []*directive {
+	var dirs []*directive
+	for p.peek(tokenPunct, "@") {
+		loc := p.tok.loc
+		p.next()
+		dirs = append(dirs, &directive{name: p.name(), args: p.parseArguments(false), loc: loc})
+	}
+	return dirs
+}
+
+// parseValue parses an input value, constant values can't have variables.
+func (p *parser) parseValue(constant bool) value {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				p.fail("unexpected variable")
+			}
+			p.next()
+			return variable(p.name())
+		case "[":
+			p.next()
+			list := listValue{}
+			for !p.skip("]") {
+				list = append(list, p.parseValue(constant))
+			}
+			return list
+		case "{":
+			p.next()
+			obj := objectValue{}
+			for !p.skip("}") {
+				n := p.name()
+				p.expect(":")
+				obj[n] = p.parseValue(constant)
+			}
+			return obj
+		}
+	case tokenInt:
+		p.next()
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			p.fail(fmt.Sprintf("invalid integer %q", tok.value))
+		}
+		return n
+	case tokenFloat:
+		p.next()
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			p.fail(fmt.Sprintf("invalid number %q", tok.value))
+		}
+		return f
+	case tokenString:
+		p.next()
+		return tok.value
+	case tokenName:
+		p.next()
+		switch tok.value {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		default:
+			return enumValue(tok.value)
+		}
+	}
+
+	p.unexpected()
+	return nil
+}
+
+// next reads the next token.
+func (p *parser) next() {
+	p.skipIgnored()
+	loc := Location{Line: p.line, Column: p.col}
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, loc: loc}
+		return
+	}
+
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.advance(3)
+		p.tok = token{kind: tokenPunct, value: "...", loc: loc}
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		p.advance(1)
+		p.tok = token{kind: tokenPunct, value: string(c), loc: loc}
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.advance(1)
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], loc: loc}
+	case c == '-' || isDigit(c):
+		p.tok = p.readNumber(loc)
+	case c == '"':
+		p.tok = p.readString(loc)
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.tok = token{loc: loc}
+		p.fail(fmt.Sprintf("unexpected character %q", r))
+	}
+}
+
+// advance moves n bytes forward on the same line.
+func (p *parser) advance(n int) {
+	p.pos += n
+	p.col += n
+}
+
+func (p *parser) skipIgnored() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; c {
+		case ' ', '\t', ',':
+			p.advance(1)
+		case '\n', '\r':
+			p.pos++
+			if c == '\r' && p.pos < len(p.src) && p.src[p.pos] == '\n' {
+				p.pos++
+			}
+			p.line++
+			p.col = 1
+		case '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+				p.advance(1)
+			}
+		default:
+			// The byte order mark is ignored.
+			if strings.HasPrefix(p.src[p.pos:], "\ufeff") {
+				p.pos += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (p *parser) readNumber(loc Location) token {
+	start := p.pos
+	kind := tokenInt
+	if p.src[p.pos] == '-' {
+		p.advance(1)
+	}
+	digits := func() {
+		n := 0
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.advance(1)
+			n++
+		}
+		if n == 0 {
+			p.tok = token{loc: loc}
+			p.fail("invalid number")
+		}
+	}
+	digits()
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		kind = tokenFloat
+		p.advance(1)
+		digits()
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		kind = tokenFloat
+		p.advance(1)
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.advance(1)
+		}
+		digits()
+	}
+	return token{kind: kind, value: p.src[start:p.pos], loc: loc}
+}
+
+func (p *parser) readString(loc Location) token {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		return p.readBlockString(loc)
+	}
+
+	p.advance(1)
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+			p.tok = token{loc: loc}
+			p.fail("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.advance(1)
+			return token{kind: tokenString, value: sb.String(), loc: loc}
+		case '\\':
+			if p.pos+1 >= len(p.src) {
+				p.tok = token{loc: loc}
+				p.fail("unterminated string")
+			}
+			esc := p.src[p.pos+1]
+			p.advance(2)
+			switch esc {
+			case '"', '\\', '/':
+				sb.WriteByte(esc)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.src) {
+					p.tok = token{loc: loc}
+					p.fail("invalid unicode escape")
+				}
+				n, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 16)
+				if err != nil {
+					p.tok = token{loc: loc}
+					p.fail("invalid unicode escape")
+				}
+				p.advance(4)
+				sb.WriteRune(rune(n))
+			default:
+				p.tok = token{loc: loc}
+				p.fail(fmt.Sprintf("invalid escape \\%c", esc))
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+			p.col++
+		}
+	}
+}
+
+// readBlockString reads a block string. Their common indentation and their
+// leading and trailing blank lines are removed.
+func (p *parser) readBlockString(loc Location) token {
+	p.advance(3)
+	end := strings.Index(p.src[p.pos:], `"""`)
+	for end > 0 && p.src[p.pos+end-1] == '\\' {
+		next := strings.Index(p.src[p.pos+end+3:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += 3 + next
+	}
+	if end < 0 {
+		p.tok = token{loc: loc}
+		p.fail("unterminated string")
+	}
+
+	raw := p.src[p.pos : p.pos+end]
+	for _, c := range raw + `"""` {
+		if c == '\n' {
+			p.line++
+			p.col = 1
+		} else {
+			p.col++
+		}
+	}
+	p.pos += end + 3
+
+	raw = strings.ReplaceAll(raw, `\"""`, `"""`)
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, l := range lines[1:] {
+		trimmed := strings.TrimLeft(l, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(l) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		} else {
+			lines[i] = ""
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return token{kind: tokenString, value: strings.Join(lines, "\n"), loc: loc}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -9,8 +9,6 @@ import (
 	"text/template"
 
 	"github.com/charmbracelet/log"
-	"github.com/charmbracelet/soft-serve/server/config"
-	"github.com/charmbracelet/soft-serve/server/utils"
 )
 
 // The names of git server-side hooks.
@@ -21,7 +19,7 @@ const (
 	PostUpdateHook  = "post-update"
 )
 
-// GenerateHooks generates git server-side hooks for a repository. Currently, it supports the following hooks:
+// GenerateHooks generates git server-side hooks for the repository at rp. Currently, it supports the following hooks:
 // - pre-receive
 // - update
 // - post-receive
@@ -29,14 +27,13 @@ const (
 //
 // This function should be called by the backend when a repository is created.
 // TODO: support context.
-func GenerateHooks(_ context.Context, cfg *config.Config, repo string) error {
+func GenerateHooks(_ context.Context, rp string) error {
 	// TODO: support git hook tests.
 	if flag.Lookup("test.v") != nil {
 		log.WithPrefix("backend.hooks").Warn("refusing to set up hooks when in test")
 		return nil
 	}
-	repo = utils.SanitizeRepo(repo) + ".git"
-	hooksPath := filepath.Join(cfg.DataPath, "repos", repo, "hooks")
+	hooksPath := filepath.Join(rp, "hooks")
 	if err := os.MkdirAll(hooksPath, os.ModePerm); err != nil {
 		return err
 	}
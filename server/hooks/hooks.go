@@ -13,9 +13,11 @@ type HookArg struct {
 }
 
 // Hooks provides an interface for git server-side hooks.
+//
+// PreReceive and Update can reject a push by returning an error.
 type Hooks interface {
-	PreReceive(ctx context.Context, stdout io.Writer, stderr io.Writer, repo string, args []HookArg)
-	Update(ctx context.Context, stdout io.Writer, stderr io.Writer, repo string, arg HookArg)
+	PreReceive(ctx context.Context, stdout io.Writer, stderr io.Writer, repo string, args []HookArg) error
+	Update(ctx context.Context, stdout io.Writer, stderr io.Writer, repo string, arg HookArg) error
 	PostReceive(ctx context.Context, stdout io.Writer, stderr io.Writer, repo string, args []HookArg)
 	PostUpdate(ctx context.Context, stdout io.Writer, stderr io.Writer, repo string, args ...string)
 }
@@ -0,0 +1,75 @@
+// Package initfiles provides the .gitignore and license templates new
+// repositories can be initialized with.
+package initfiles
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrGitignoreNotFound is returned when there is no .gitignore template
+	// with a name.
+	ErrGitignoreNotFound = errors.New(".gitignore template not found")
+	// ErrLicenseNotFound is returned when there is no license with a name.
+	ErrLicenseNotFound = errors.New("license not found")
+)
+
+//go:embed gitignore/*.gitignore
+var gitignores embed.FS
+
+//go:embed license/*
+var licenses embed.FS
+
+// Readme is the README of new repositories. Its placeholders are replaced
+// like the ones of repository templates.
+const Readme = "# {{PROJECT_NAME}}\n\n{{DESCRIPTION}}\n"
+
+// Gitignores returns the names of the .gitignore templates.
+func Gitignores() []string {
+	return names(gitignores, "gitignore", ".gitignore")
+}
+
+// Gitignore returns the .gitignore template name. Names are case
+// insensitive.
+func Gitignore(name string) ([]byte, error) {
+	return find(gitignores, "gitignore", ".gitignore", name, ErrGitignoreNotFound)
+}
+
+// Licenses returns the SPDX identifiers of the licenses.
+func Licenses() []string {
+	return names(licenses, "license", "")
+}
+
+// License returns the text of the license with the SPDX identifier name.
+// Names are case insensitive. The copyright notice of the licenses that have
+// one uses the {{YEAR}} and {{OWNER}} placeholders.
+func License(name string) ([]byte, error) {
+	return find(licenses, "license", "", name, ErrLicenseNotFound)
+}
+
+func names(fsys embed.FS, dir string, ext string) []string {
+	entries, _ := fs.ReadDir(fsys, dir)
+	ns := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ns = append(ns, strings.TrimSuffix(e.Name(), ext))
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+func find(fsys embed.FS, dir string, ext string, name string, notFound error) ([]byte, error) {
+	all := names(fsys, dir, ext)
+	for _, n := range all {
+		if strings.EqualFold(n, name) {
+			return fsys.ReadFile(path.Join(dir, n+ext))
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s, use one of %s", notFound, name, strings.Join(all, ", "))
+}
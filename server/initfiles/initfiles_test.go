@@ -0,0 +1,36 @@
+package initfiles
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLicense(t *testing.T) {
+	for _, name := range Licenses() {
+		if _, err := License(name); err != nil {
+			t.Errorf("license %s: %v", name, err)
+		}
+	}
+
+	content, err := License("mit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Copyright (c) {{YEAR}} {{OWNER}}") {
+		t.Fatalf("unexpected MIT license %q", content)
+	}
+
+	if _, err := License("nope"); !errors.Is(err, ErrLicenseNotFound) {
+		t.Fatalf("expected ErrLicenseNotFound, got %v", err)
+	}
+}
+
+func TestGitignore(t *testing.T) {
+	if _, err := Gitignore("go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Gitignore("nope"); !errors.Is(err, ErrGitignoreNotFound) {
+		t.Fatalf("expected ErrGitignoreNotFound, got %v", err)
+	}
+}
@@ -9,7 +9,7 @@ import (
 type Job struct {
 	ID   int
 	Spec string
-	Func func(context.Context) func()
+	Func func(context.Context) func() error
 }
 
 var (
@@ -18,7 +18,7 @@ var (
 )
 
 // Register registers a job.
-func Register(name, spec string, fn func(context.Context) func()) {
+func Register(name, spec string, fn func(context.Context) func() error) {
 	mtx.Lock()
 	defer mtx.Unlock()
 	jobs[name] = &Job{Spec: spec, Func: fn}
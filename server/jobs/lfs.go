@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// lfsPruneJob is the kind of the queued jobs pruning the LFS objects of a
+// repository.
+const lfsPruneJob = "lfs-prune"
+
+// lfsPrunePayload is the payload of a lfs-prune job.
+type lfsPrunePayload struct {
+	Repo string `json:"repo"`
+}
+
+func init() {
+	Register("lfs-prune", "@every 24h", lfsPrune)
+	backend.RegisterJobHandler(lfsPruneJob, lfsPruneRepo)
+}
+
+// lfsPrune queues the pruning of the LFS objects of each repository, the
+// objects that aren't referenced by any ref and are older than the retention
+// window are removed.
+func lfsPrune(ctx context.Context) func() error {
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx).WithPrefix("jobs.lfs-prune")
+	b := backend.FromContext(ctx)
+	return func() error {
+		if !cfg.LFS.Enabled || cfg.LFS.PruneRetentionDays <= 0 {
+			return nil
+		}
+
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			return fmt.Errorf("get repositories: %w", err)
+		}
+
+		logger.Debug("pruning lfs objects")
+		var failed int
+		for _, repo := range repos {
+			if err := b.EnqueueJob(ctx, lfsPruneJob, lfsPrunePayload{Repo: repo.Name()}); err != nil {
+				logger.Error("error queuing lfs prune", "repo", repo.Name(), "err", err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to queue the pruning of %d repositories", failed)
+		}
+
+		return nil
+	}
+}
+
+// lfsPruneRepo prunes the LFS objects of a repository.
+func lfsPruneRepo(ctx context.Context, payload []byte) error {
+	var p lfsPrunePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	cfg := config.FromContext(ctx)
+	b := backend.FromContext(ctx)
+	if !cfg.LFS.Enabled || cfg.LFS.PruneRetentionDays <= 0 {
+		return nil
+	}
+
+	repo, err := b.Repository(ctx, p.Repo)
+	if errors.Is(err, proto.ErrRepoNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	retention := time.Duration(cfg.LFS.PruneRetentionDays) * 24 * time.Hour
+	_, err = b.PruneLFSObjects(ctx, repo, retention, false)
+	return err
+}
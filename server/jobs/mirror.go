@@ -2,9 +2,10 @@ package jobs
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
-	"runtime"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/git"
@@ -12,92 +13,125 @@ import (
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/lfs"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/store"
-	"github.com/charmbracelet/soft-serve/server/sync"
 )
 
+// mirrorPullJob is the kind of the queued jobs updating a mirror.
+const mirrorPullJob = "mirror-pull"
+
+// mirrorPullPayload is the payload of a mirror-pull job.
+type mirrorPullPayload struct {
+	Repo string `json:"repo"`
+}
+
 func init() {
 	Register("mirror-pull", "@every 10m", mirrorPull)
+	backend.RegisterJobHandler(mirrorPullJob, mirrorPullRepo)
 }
 
-// mirrorPull runs the (pull) mirror job task.
-func mirrorPull(ctx context.Context) func() {
-	cfg := config.FromContext(ctx)
+// mirrorPull queues the update of each mirror repository.
+func mirrorPull(ctx context.Context) func() error {
 	logger := log.FromContext(ctx).WithPrefix("jobs.mirror")
 	b := backend.FromContext(ctx)
-	dbx := db.FromContext(ctx)
-	datastore := store.FromContext(ctx)
-	return func() {
+	return func() error {
 		repos, err := b.Repositories(ctx)
 		if err != nil {
-			logger.Error("error getting repositories", "err", err)
-			return
+			return fmt.Errorf("get repositories: %w", err)
 		}
 
-		// Divide the work up among the number of CPUs.
-		wq := sync.NewWorkPool(ctx, runtime.GOMAXPROCS(0),
-			sync.WithWorkPoolLogger(logger.Errorf),
-		)
-
 		logger.Debug("updating mirror repos")
+		var failed int
 		for _, repo := range repos {
-			if repo.IsMirror() {
-				r, err := repo.Open()
-				if err != nil {
-					logger.Error("error opening repository", "repo", repo.Name(), "err", err)
-					continue
-				}
-
-				name := repo.Name()
-				wq.Add(name, func() {
-					repo := repo
-					cmd := git.NewCommand("remote", "update", "--prune").WithContext(ctx)
-					cmd.AddEnvs(
-						fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
-							filepath.Join(cfg.DataPath, "ssh", "known_hosts"),
-							cfg.SSH.ClientKeyPath,
-						),
-					)
-
-					if _, err := cmd.RunInDir(r.Path); err != nil {
-						logger.Error("error running git remote update", "repo", name, "err", err)
-					}
-
-					if cfg.LFS.Enabled {
-						rcfg, err := r.Config()
-						if err != nil {
-							logger.Error("error getting git config", "repo", name, "err", err)
-							return
-						}
-
-						lfsEndpoint := rcfg.Section("lfs").Option("url")
-						if lfsEndpoint == "" {
-							// If there is no LFS url defined, means the repo
-							// doesn't use LFS and we can skip it.
-							return
-						}
-
-						ep, err := lfs.NewEndpoint(lfsEndpoint)
-						if err != nil {
-							logger.Error("error creating LFS endpoint", "repo", name, "err", err)
-							return
-						}
-
-						client := lfs.NewClient(ep)
-						if client == nil {
-							logger.Errorf("failed to create lfs client: unsupported endpoint %s", lfsEndpoint)
-							return
-						}
-
-						if err := backend.StoreRepoMissingLFSObjects(ctx, repo, dbx, datastore, client); err != nil {
-							logger.Error("failed to store missing lfs objects", "err", err, "path", r.Path)
-							return
-						}
-					}
-				})
+			// Archived mirrors are frozen.
+			if !repo.IsMirror() || repo.IsArchived() {
+				continue
 			}
+
+			if err := b.EnqueueJob(ctx, mirrorPullJob, mirrorPullPayload{Repo: repo.Name()}); err != nil {
+				logger.Error("error queuing mirror update", "repo", repo.Name(), "err", err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to queue the update of %d mirrors", failed)
+		}
+
+		return nil
+	}
+}
+
+// mirrorPullRepo updates a mirror repository and its missing LFS objects.
+func mirrorPullRepo(ctx context.Context, payload []byte) error {
+	var p mirrorPullPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	cfg := config.FromContext(ctx)
+	b := backend.FromContext(ctx)
+	dbx := db.FromContext(ctx)
+	datastore := store.FromContext(ctx)
+	repo, err := b.Repository(ctx, p.Repo)
+	if errors.Is(err, proto.ErrRepoNotFound) || err == nil && (!repo.IsMirror() || repo.IsArchived()) {
+		// The repository was deleted, isn't a mirror anymore, or was archived.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	r, err := repo.Open()
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	cmd := git.NewCommand("remote", "update", "--prune").WithContext(ctx)
+	cmd.AddEnvs(
+		fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
+			filepath.Join(cfg.DataPath, "ssh", "known_hosts"),
+			cfg.SSH.ClientKeyPath,
+		),
+	)
+
+	if _, err := cmd.RunInDir(r.Path); err != nil {
+		return fmt.Errorf("git remote update: %w", err)
+	}
+	b.InvalidateCache(ctx, repo.Name())
+	defer func() {
+		if _, err := b.UpdateRepoDiskUsage(ctx, repo.Name()); err != nil {
+			log.FromContext(ctx).Error("error updating disk usage", "repo", repo.Name(), "err", err)
 		}
+	}()
+
+	if !cfg.LFS.Enabled {
+		return nil
+	}
 
-		wq.Run()
+	rcfg, err := r.Config()
+	if err != nil {
+		return fmt.Errorf("git config: %w", err)
 	}
+
+	lfsEndpoint := rcfg.Section("lfs").Option("url")
+	if lfsEndpoint == "" {
+		// If there is no LFS url defined, means the repo
+		// doesn't use LFS and we can skip it.
+		return nil
+	}
+
+	ep, err := lfs.NewEndpoint(lfsEndpoint)
+	if err != nil {
+		return fmt.Errorf("create LFS endpoint: %w", err)
+	}
+
+	client := lfs.NewClient(ep)
+	if client == nil {
+		return fmt.Errorf("unsupported LFS endpoint %s", lfsEndpoint)
+	}
+
+	if err := backend.StoreRepoMissingLFSObjects(ctx, repo, dbx, datastore, client); err != nil {
+		return fmt.Errorf("store missing LFS objects: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/backend"
+)
+
+func init() {
+	Register("repo-size", "@every 24h", repoSize)
+}
+
+// repoSize sizes each repository, its disk usage is updated after each push
+// but the repositories can change on disk otherwise, i.e. when git gc runs,
+// and the largest blobs are only listed here.
+func repoSize(ctx context.Context) func() error {
+	logger := log.FromContext(ctx).WithPrefix("jobs.repo-size")
+	b := backend.FromContext(ctx)
+	return func() error {
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			return fmt.Errorf("get repositories: %w", err)
+		}
+
+		logger.Debug("sizing repos")
+		var failed int
+		for _, repo := range repos {
+			if _, err := b.SizeRepository(ctx, repo.Name()); err != nil {
+				logger.Error("error sizing repository", "repo", repo.Name(), "err", err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to size %d repositories", failed)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/backend"
+)
+
+func init() {
+	Register("user-sync", "@every 1h", userSync)
+}
+
+// userSync syncs the users of the configured provider.
+func userSync(ctx context.Context) func() error {
+	logger := log.FromContext(ctx).WithPrefix("jobs.user-sync")
+	b := backend.FromContext(ctx)
+	return func() error {
+		if b.AuthProvider() == nil {
+			return nil
+		}
+
+		res, err := b.SyncUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("sync users: %w", err)
+		}
+
+		logger.Debug("synced users",
+			"created", len(res.Created),
+			"updated", len(res.Updated),
+			"disabled", len(res.Disabled),
+			"skipped", len(res.Skipped),
+		)
+		return nil
+	}
+}
@@ -0,0 +1,22 @@
+// Package kvcache implements caches of byte values, in memory or in Redis,
+// and the Redis pub/sub servers share their events through.
+package kvcache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a key isn't cached.
+var ErrNotFound = errors.New("not found")
+
+// Cache is a cache of byte values. Values must not be modified once they're
+// set or returned.
+type Cache interface {
+	// Get returns the value of key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set sets the value of key.
+	Set(ctx context.Context, key string, value []byte) error
+	// DeletePrefix deletes the keys starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
@@ -0,0 +1,90 @@
+package kvcache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+)
+
+type entry struct {
+	key   string
+	value []byte
+}
+
+// Memory is an in-memory LRU cache bounded by the size of its values.
+type Memory struct {
+	size int64
+
+	mu    sync.Mutex
+	used  int64
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+var _ Cache = (*Memory)(nil)
+
+// NewMemory returns a new in-memory cache of up to size bytes.
+func NewMemory(size int64) *Memory {
+	return &Memory{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	m.ll.MoveToFront(e)
+	return e.Value.(*entry).value, nil
+}
+
+// Set implements Cache. Values larger than the cache aren't cached.
+func (m *Memory) Set(_ context.Context, key string, value []byte) error {
+	if int64(len(value)) > m.size {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.items[key]; ok {
+		m.remove(e)
+	}
+	m.items[key] = m.ll.PushFront(&entry{key: key, value: value})
+	m.used += int64(len(value))
+	for m.used > m.size {
+		m.remove(m.ll.Back())
+	}
+	return nil
+}
+
+// DeletePrefix implements Cache.
+func (m *Memory) DeletePrefix(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, e := range m.items {
+		if strings.HasPrefix(k, prefix) {
+			m.remove(e)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of cached values.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+func (m *Memory) remove(e *list.Element) {
+	ent := m.ll.Remove(e).(*entry)
+	delete(m.items, ent.key)
+	m.used -= int64(len(ent.value))
+}
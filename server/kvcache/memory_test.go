@@ -0,0 +1,51 @@
+package kvcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+
+	if _, err := m.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	m.Set(ctx, "a", []byte("1234"))        // nolint: errcheck
+	m.Set(ctx, "b", []byte("1234"))        // nolint: errcheck
+	m.Get(ctx, "a")                        // nolint: errcheck
+	m.Set(ctx, "c", []byte("1234"))        // nolint: errcheck
+	m.Set(ctx, "d", []byte("way too big")) // nolint: errcheck
+
+	if _, err := m.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Error("the least recently used value should be evicted")
+	}
+	if v, err := m.Get(ctx, "a"); err != nil || string(v) != "1234" {
+		t.Errorf("expected a to be cached, got %q %v", v, err)
+	}
+	if _, err := m.Get(ctx, "d"); !errors.Is(err, ErrNotFound) {
+		t.Error("values larger than the cache should be skipped")
+	}
+	if m.used != 8 {
+		t.Errorf("expected 8 bytes used, got %d", m.used)
+	}
+}
+
+func TestMemoryDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(100)
+	for _, k := range []string{"repo:a", "repo:b", "repo/sub:a"} {
+		m.Set(ctx, k, []byte(k)) // nolint: errcheck
+	}
+
+	m.DeletePrefix(ctx, "repo:") // nolint: errcheck
+	if m.Len() != 1 {
+		t.Errorf("expected 1 value left, got %d", m.Len())
+	}
+	if _, err := m.Get(ctx, "repo/sub:a"); err != nil {
+		t.Errorf("expected other prefixes to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package kvcache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redisTimeout is the timeout of commands when the context has no
+	// deadline.
+	redisTimeout = 2 * time.Second
+	// redisMaxIdle is the number of idle connections kept open.
+	redisMaxIdle = 8
+)
+
+// RedisOptions are the options of a Redis cache.
+type RedisOptions struct {
+	// Addr is the address of the server, host:port.
+	Addr string
+	// Password is the password of the server, if any.
+	Password string
+	// DB is the database number.
+	DB int
+	// TTL is how long keys are kept, 0 means forever.
+	TTL time.Duration
+	// Prefix is prepended to all the keys.
+	Prefix string
+}
+
+// Redis is a cache stored in a Redis server, so that it can be shared by
+// multiple servers.
+type Redis struct {
+	opts RedisOptions
+	idle chan *redisConn
+}
+
+var _ Cache = (*Redis)(nil)
+
+// NewRedis returns a new Redis cache. Connections are made on demand.
+func NewRedis(opts RedisOptions) *Redis {
+	return &Redis{
+		opts: opts,
+		idle: make(chan *redisConn, redisMaxIdle),
+	}
+}
+
+// Get implements Cache.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	v, err := r.do(ctx, "GET", r.opts.Prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+// Set implements Cache.
+func (r *Redis) Set(ctx context.Context, key string, value []byte) error {
+	args := []interface{}{"SET", r.opts.Prefix + key, value}
+	if r.opts.TTL > 0 {
+		args = append(args, "PX", strconv.FormatInt(r.opts.TTL.Milliseconds(), 10))
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// DeletePrefix implements Cache.
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) error {
+	match := escapeGlob(r.opts.Prefix+prefix) + "*"
+	cursor := "0"
+	for {
+		v, err := r.do(ctx, "SCAN", cursor, "MATCH", match, "COUNT", "100")
+		if err != nil {
+			return err
+		}
+		reply, ok := v.([]interface{})
+		if !ok || len(reply) != 2 {
+			return fmt.Errorf("redis: unexpected scan reply %v", v)
+		}
+		next, _ := reply[0].([]byte)
+		keys, _ := reply[1].([]interface{})
+		if len(keys) > 0 {
+			if _, err := r.do(ctx, append([]interface{}{"DEL"}, keys...)...); err != nil {
+				return err
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// Publish publishes msg to the subscribers of channel.
+func (r *Redis) Publish(ctx context.Context, channel string, msg []byte) error {
+	_, err := r.do(ctx, "PUBLISH", r.opts.Prefix+channel, msg)
+	return err
+}
+
+// Subscribe calls fn with the messages published to channel, until ctx is
+// done or the connection fails. It always returns a non-nil error.
+func (r *Redis) Subscribe(ctx context.Context, channel string, fn func(msg []byte)) error {
+	c, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close() // nolint: errcheck
+
+	channel = r.opts.Prefix + channel
+	if _, err := c.do(ctx, "SUBSCRIBE", channel); err != nil {
+		return err
+	}
+
+	// Messages can take any time to come, reads are only interrupted by ctx.
+	if err := c.SetDeadline(time.Time{}); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close() // nolint: errcheck
+		case <-done:
+		}
+	}()
+
+	for {
+		v, err := c.read()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		// Messages are ["message", channel, payload].
+		reply, _ := v.([]interface{})
+		if len(reply) != 3 {
+			continue
+		}
+		if kind, _ := reply[0].([]byte); string(kind) != "message" {
+			continue
+		}
+		if msg, ok := reply[2].([]byte); ok {
+			fn(msg)
+		}
+	}
+}
+
+// do runs a command on an idle connection, or a new one.
+func (r *Redis) do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	var c *redisConn
+	select {
+	case c = <-r.idle:
+	default:
+		var err error
+		c, err = r.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := c.do(ctx, args...)
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		// The connection is in an unknown state.
+		c.Close() // nolint: errcheck
+		return nil, err
+	}
+
+	select {
+	case r.idle <- c:
+	default:
+		c.Close() // nolint: errcheck
+	}
+	return v, err
+}
+
+func (r *Redis) dial(ctx context.Context) (*redisConn, error) {
+	var d net.Dialer
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	conn, err := d.DialContext(ctx, "tcp", r.opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &redisConn{Conn: conn, r: bufio.NewReader(conn)}
+	if r.opts.Password != "" {
+		if _, err := c.do(ctx, "AUTH", r.opts.Password); err != nil {
+			c.Close() // nolint: errcheck
+			return nil, err
+		}
+	}
+	if r.opts.DB != 0 {
+		if _, err := c.do(ctx, "SELECT", strconv.Itoa(r.opts.DB)); err != nil {
+			c.Close() // nolint: errcheck
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// redisError is an error replied by the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is a connection speaking RESP, the Redis protocol.
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// do sends a command, and returns its reply. Bulk strings are returned as
+// []byte, arrays as []interface{}, and nil values as nil.
+func (c *redisConn) do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+
+	return c.read()
+}
+
+// send sends a command without reading its reply.
+func (c *redisConn) send(args ...interface{}) error {
+	w := bufio.NewWriter(c.Conn)
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch arg := arg.(type) {
+		case string:
+			b = []byte(arg)
+		case []byte:
+			b = arg
+		default:
+			return fmt.Errorf("redis: unsupported argument type %T", arg)
+		}
+		fmt.Fprintf(w, "$%d\r\n", len(b))
+		w.Write(b)            // nolint: errcheck
+		w.WriteString("\r\n") // nolint: errcheck
+	}
+
+	return w.Flush()
+}
+
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		vs := make([]interface{}, n)
+		for i := range vs {
+			if vs[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return vs, nil
+	}
+
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+// escapeGlob escapes the special characters of a Redis glob pattern.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package kvcache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a Redis server supporting the commands used by the cache.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	cmds []string
+	subs map[string][]net.Conn
+}
+
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() }) // nolint: errcheck
+
+	s := &fakeRedis{data: make(map[string][]byte), subs: make(map[string][]net.Conn)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, l.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close() // nolint: errcheck
+	c := &redisConn{Conn: conn, r: bufio.NewReader(conn)}
+	for {
+		v, err := c.read()
+		if err != nil {
+			return
+		}
+		args, _ := v.([]interface{})
+		if len(args) == 0 {
+			return
+		}
+		cmd := string(args[0].([]byte))
+
+		s.mu.Lock()
+		s.cmds = append(s.cmds, cmd)
+		switch cmd {
+		case "AUTH":
+			fmt.Fprint(conn, "+OK\r\n")
+		case "GET":
+			if v, ok := s.data[string(args[1].([]byte))]; ok {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		case "SET":
+			s.data[string(args[1].([]byte))] = args[2].([]byte)
+			fmt.Fprint(conn, "+OK\r\n")
+		case "SCAN":
+			match := strings.ReplaceAll(string(args[3].([]byte)), `\`, "")
+			var keys []string
+			for k := range s.data {
+				if ok, _ := path.Match(match, k); ok {
+					keys = append(keys, k)
+				}
+			}
+			fmt.Fprintf(conn, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+			for _, k := range keys {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(k), k)
+			}
+		case "DEL":
+			for _, k := range args[1:] {
+				delete(s.data, string(k.([]byte)))
+			}
+			fmt.Fprintf(conn, ":%d\r\n", len(args)-1)
+		case "SUBSCRIBE":
+			ch := string(args[1].([]byte))
+			s.subs[ch] = append(s.subs[ch], conn)
+			fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(ch), ch)
+		case "PUBLISH":
+			ch, msg := string(args[1].([]byte)), args[2].([]byte)
+			for _, sub := range s.subs[ch] {
+				fmt.Fprintf(sub, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(ch), ch, len(msg), msg)
+			}
+			fmt.Fprintf(conn, ":%d\r\n", len(s.subs[ch]))
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", cmd)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestRedis(t *testing.T) {
+	s, addr := newFakeRedis(t)
+	ctx := context.Background()
+	r := NewRedis(RedisOptions{Addr: addr, Password: "secret", Prefix: "test:"})
+
+	if _, err := r.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	value := []byte("binary\r\n\x00value")
+	if err := r.Set(ctx, "repo:a", value); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(ctx, "other:a", value); err != nil {
+		t.Fatal(err)
+	}
+	v, err := r.Get(ctx, "repo:a")
+	if err != nil || string(v) != string(value) {
+		t.Fatalf("expected %q, got %q %v", value, v, err)
+	}
+
+	if err := r.DeletePrefix(ctx, "repo:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get(ctx, "repo:a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the key to be deleted, got %v", err)
+	}
+	if _, err := r.Get(ctx, "other:a"); err != nil {
+		t.Errorf("expected other keys to be kept, got %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data["test:other:a"]; !ok {
+		t.Error("expected keys to be prefixed")
+	}
+	// Connections are reused, so there's a single AUTH.
+	var auths int
+	for _, c := range s.cmds {
+		if c == "AUTH" {
+			auths++
+		}
+	}
+	if auths != 1 {
+		t.Errorf("expected 1 AUTH, got %d", auths)
+	}
+}
+
+func TestRedisError(t *testing.T) {
+	_, addr := newFakeRedis(t)
+	r := NewRedis(RedisOptions{Addr: addr, DB: 1})
+	if _, err := r.Get(context.Background(), "a"); err == nil || !strings.Contains(err.Error(), "unknown command 'SELECT'") {
+		t.Errorf("expected the server error, got %v", err)
+	}
+}
+
+func TestRedisPubSub(t *testing.T) {
+	s, addr := newFakeRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewRedis(RedisOptions{Addr: addr, Prefix: "test:"})
+
+	msgs := make(chan string, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- r.Subscribe(ctx, "events", func(msg []byte) { msgs <- string(msg) })
+	}()
+
+	// Wait for the subscription.
+	for subscribed := false; !subscribed; {
+		s.mu.Lock()
+		subscribed = len(s.subs["test:events"]) > 0
+		s.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := r.Publish(ctx, "events", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if msg := <-msgs; msg != "hello" {
+		t.Errorf("expected hello, got %q", msg)
+	}
+
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the subscription to be canceled, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxElementSize is the maximum size of the BER elements read from the
+// server.
+const maxElementSize = 16 << 20 // 16 MiB
+
+// BER tags used by LDAP.
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	classApplication = 0x40
+	classContext     = 0x80
+	constructed      = 0x20
+)
+
+var errMalformed = errors.New("ldap: malformed message")
+
+// element is a BER encoded element.
+type element struct {
+	tag     byte
+	content []byte
+}
+
+// encode encodes a BER element of the concatenated contents.
+func encode(tag byte, contents ...[]byte) []byte {
+	var n int
+	for _, c := range contents {
+		n += len(c)
+	}
+
+	b := append([]byte{tag}, encodeLength(n)...)
+	for _, c := range contents {
+		b = append(b, c...)
+	}
+
+	return b
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+func encodeInt(tag byte, v int64) []byte {
+	b := []byte{byte(v)}
+	for v > 0x7f || v < -0x80 {
+		v >>= 8
+		b = append([]byte{byte(v)}, b...)
+	}
+
+	return encode(tag, b)
+}
+
+func encodeString(tag byte, s string) []byte {
+	return encode(tag, []byte(s))
+}
+
+func encodeBool(tag byte, v bool) []byte {
+	if v {
+		return encode(tag, []byte{0xff})
+	}
+	return encode(tag, []byte{0x00})
+}
+
+// readElement reads a BER element from r.
+func readElement(r *bufio.Reader) (element, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+
+	n, err := readLength(r)
+	if err != nil {
+		return element{}, err
+	}
+
+	content := make([]byte, n)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return element{}, err
+	}
+
+	return element{tag: tag, content: content}, nil
+}
+
+func readLength(r io.ByteReader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if b < 0x80 {
+		return int(b), nil
+	}
+
+	// Indefinite lengths are not allowed in LDAP.
+	size := int(b & 0x7f)
+	if size == 0 || size > 4 {
+		return 0, errMalformed
+	}
+
+	var n int
+	for i := 0; i < size; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n = n<<8 | int(b)
+	}
+
+	if n > maxElementSize {
+		return 0, fmt.Errorf("ldap: message too large: %d bytes", n)
+	}
+
+	return n, nil
+}
+
+// parseElement parses the first BER element of b and returns the remaining
+// bytes.
+func parseElement(b []byte) (element, []byte, error) {
+	if len(b) < 2 {
+		return element{}, nil, errMalformed
+	}
+
+	tag := b[0]
+	n := int(b[1])
+	b = b[2:]
+	if n >= 0x80 {
+		size := n & 0x7f
+		if size == 0 || size > 4 || len(b) < size {
+			return element{}, nil, errMalformed
+		}
+
+		n = 0
+		for _, c := range b[:size] {
+			n = n<<8 | int(c)
+		}
+		b = b[size:]
+	}
+
+	if n < 0 || n > len(b) {
+		return element{}, nil, errMalformed
+	}
+
+	return element{tag: tag, content: b[:n]}, b[n:], nil
+}
+
+// children parses the elements of a constructed element.
+func (e element) children() ([]element, error) {
+	var elems []element
+	b := e.content
+	for len(b) > 0 {
+		var child element
+		var err error
+		child, b, err = parseElement(b)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, child)
+	}
+
+	return elems, nil
+}
+
+// int returns the value of an integer or enumerated element.
+func (e element) int() (int64, error) {
+	if len(e.content) == 0 || len(e.content) > 8 {
+		return 0, errMalformed
+	}
+
+	// Sign extend the first byte.
+	v := int64(int8(e.content[0]))
+	for _, b := range e.content[1:] {
+		v = v<<8 | int64(b)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,208 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Filter choice tags.
+const (
+	filterAnd            = classContext | constructed | 0
+	filterOr             = classContext | constructed | 1
+	filterNot            = classContext | constructed | 2
+	filterEqualityMatch  = classContext | constructed | 3
+	filterSubstrings     = classContext | constructed | 4
+	filterGreaterOrEqual = classContext | constructed | 5
+	filterLessOrEqual    = classContext | constructed | 6
+	filterPresent        = classContext | 7
+	filterApproxMatch    = classContext | constructed | 8
+
+	substringInitial = classContext | 0
+	substringAny     = classContext | 1
+	substringFinal   = classContext | 2
+)
+
+// maxFilterDepth is the maximum nesting of filters.
+const maxFilterDepth = 32
+
+// filterSpecialChars are the characters escaped in assertion values.
+const filterSpecialChars = `\*()` + "\x00"
+
+// EscapeFilter escapes the special characters of a filter assertion value.
+func EscapeFilter(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(filterSpecialChars, s[i]) >= 0 {
+			fmt.Fprintf(&sb, `\%02x`, s[i])
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// compileFilter compiles an RFC 4515 string filter, i.e.
+// "(&(objectClass=person)(uid=alice))", to its BER encoding. Extensible
+// matches are not supported.
+func compileFilter(s string) ([]byte, error) {
+	b, rest, err := compileFilterAt(s, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid filter %q: %w", s, err)
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("ldap: invalid filter %q: unexpected trailing characters", s)
+	}
+	return b, nil
+}
+
+func compileFilterAt(s string, depth int) ([]byte, string, error) {
+	if depth > maxFilterDepth {
+		return nil, "", errors.New("too deeply nested")
+	}
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", errors.New("expected '('")
+	}
+	s = s[1:]
+	if s == "" {
+		return nil, "", errors.New("unexpected end")
+	}
+
+	var b []byte
+	switch s[0] {
+	case '&', '|':
+		tag := byte(filterAnd)
+		if s[0] == '|' {
+			tag = filterOr
+		}
+		s = s[1:]
+		var filters [][]byte
+		for strings.HasPrefix(s, "(") {
+			f, rest, err := compileFilterAt(s, depth+1)
+			if err != nil {
+				return nil, "", err
+			}
+			filters = append(filters, f)
+			s = rest
+		}
+		if len(filters) == 0 {
+			return nil, "", errors.New("empty filter list")
+		}
+		b = encode(tag, filters...)
+	case '!':
+		f, rest, err := compileFilterAt(s[1:], depth+1)
+		if err != nil {
+			return nil, "", err
+		}
+		b = encode(filterNot, f)
+		s = rest
+	default:
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return nil, "", errors.New("missing ')'")
+		}
+		item, err := compileItem(s[:end])
+		if err != nil {
+			return nil, "", err
+		}
+		b = item
+		s = s[end:]
+	}
+
+	if !strings.HasPrefix(s, ")") {
+		return nil, "", errors.New("missing ')'")
+	}
+
+	return b, s[1:], nil
+}
+
+func compileItem(s string) ([]byte, error) {
+	eq := strings.IndexByte(s, '=')
+	if eq <= 0 {
+		return nil, fmt.Errorf("invalid item %q", s)
+	}
+
+	attr, value := s[:eq], s[eq+1:]
+	tag := byte(filterEqualityMatch)
+	switch attr[len(attr)-1] {
+	case '~':
+		tag = filterApproxMatch
+	case '>':
+		tag = filterGreaterOrEqual
+	case '<':
+		tag = filterLessOrEqual
+	}
+	if tag != filterEqualityMatch {
+		attr = attr[:len(attr)-1]
+	}
+
+	if attr == "" || strings.ContainsAny(attr, "():*\\") {
+		return nil, fmt.Errorf("invalid attribute %q", attr)
+	}
+
+	if tag == filterEqualityMatch && value == "*" {
+		return encodeString(filterPresent, attr), nil
+	}
+
+	if tag == filterEqualityMatch && strings.Contains(value, "*") {
+		parts := strings.Split(value, "*")
+		var subs [][]byte
+		for i, p := range parts {
+			if p == "" {
+				continue
+			}
+			v, err := unescapeFilter(p)
+			if err != nil {
+				return nil, err
+			}
+			st := byte(substringAny)
+			switch i {
+			case 0:
+				st = substringInitial
+			case len(parts) - 1:
+				st = substringFinal
+			}
+			subs = append(subs, encodeString(st, v))
+		}
+		return encode(filterSubstrings,
+			encodeString(tagOctetString, attr),
+			encode(tagSequence, subs...),
+		), nil
+	}
+
+	v, err := unescapeFilter(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return encode(tag,
+		encodeString(tagOctetString, attr),
+		encodeString(tagOctetString, v),
+	), nil
+}
+
+func unescapeFilter(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		b, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		sb.Write(b)
+		i += 2
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,399 @@
+// Package ldap implements a minimal LDAP v3 client, enough to bind to and
+// search a directory.
+// https://www.rfc-editor.org/rfc/rfc4511
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Protocol operation tags.
+const (
+	opBindRequest       = classApplication | constructed | 0
+	opBindResponse      = classApplication | constructed | 1
+	opUnbindRequest     = classApplication | 2
+	opSearchRequest     = classApplication | constructed | 3
+	opSearchResultEntry = classApplication | constructed | 4
+	opSearchResultDone  = classApplication | constructed | 5
+	opSearchResultRef   = classApplication | constructed | 19
+	opExtendedRequest   = classApplication | constructed | 23
+	opExtendedResponse  = classApplication | constructed | 24
+)
+
+// oidStartTLS is the name of the StartTLS extended operation.
+const oidStartTLS = "1.3.6.1.4.1.1466.20037"
+
+// ResultInvalidCredentials is the result code of binds with invalid
+// credentials.
+const ResultInvalidCredentials = 49
+
+// DefaultTimeout is the default timeout of LDAP operations.
+const DefaultTimeout = 30 * time.Second
+
+// ErrInvalidCredentials is returned when a bind fails because of invalid
+// credentials.
+var ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+
+// Error is an unsuccessful LDAP result.
+type Error struct {
+	Code    int
+	Message string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.Code)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.Code, e.Message)
+}
+
+// Scope is the scope of a search.
+type Scope int
+
+const (
+	// ScopeBaseObject only searches the base object.
+	ScopeBaseObject Scope = iota
+	// ScopeSingleLevel searches the immediate children of the base object.
+	ScopeSingleLevel
+	// ScopeWholeSubtree searches the base object and all its descendants.
+	ScopeWholeSubtree
+)
+
+// Entry is an entry returned by a search.
+type Entry struct {
+	DN string
+	// Attributes maps the lowercase attribute names to their values.
+	Attributes map[string][]string
+}
+
+// Values returns the values of an attribute.
+func (e *Entry) Values(attr string) []string {
+	return e.Attributes[strings.ToLower(attr)]
+}
+
+// Value returns the first value of an attribute, or an empty string.
+func (e *Entry) Value(attr string) string {
+	if v := e.Values(attr); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Conn is a connection to an LDAP server. Operations are serialized.
+type Conn struct {
+	// Timeout is the timeout of each operation.
+	Timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+	id   int64
+}
+
+// Dial connects to the LDAP server at rawURL, either ldap://host[:port] or
+// ldaps://host[:port]. The TLS config is used for ldaps:// URLs.
+func Dial(ctx context.Context, rawURL string, tlsConfig *tls.Config) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid url: %w", err)
+	}
+
+	host := u.Host
+	var d net.Dialer
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+		conn, err = d.DialContext(ctx, "tcp", host)
+	case "ldaps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+		td := tls.Dialer{NetDialer: &d, Config: tlsConfigFor(tlsConfig, u.Hostname())}
+		conn, err = td.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("ldap: unsupported url scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConn(conn), nil
+}
+
+// NewConn returns a new LDAP connection over conn.
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		Timeout: DefaultTimeout,
+		conn:    conn,
+		r:       bufio.NewReader(conn),
+	}
+}
+
+func tlsConfigFor(cfg *tls.Config, host string) *tls.Config {
+	if cfg == nil {
+		cfg = &tls.Config{} // nolint: gosec
+	} else {
+		cfg = cfg.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	return cfg
+}
+
+// StartTLS upgrades the connection to TLS.
+func (c *Conn) StartTLS(tlsConfig *tls.Config) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, err := c.roundTrip(encode(opExtendedRequest,
+		encodeString(classContext|0, oidStartTLS),
+	), opExtendedResponse)
+	if err != nil {
+		return err
+	}
+	if err := parseResult(resp); err != nil {
+		return err
+	}
+
+	host, _, _ := net.SplitHostPort(c.conn.RemoteAddr().String())
+	tc := tls.Client(c.conn, tlsConfigFor(tlsConfig, host))
+	c.setDeadline()
+	if err := tc.Handshake(); err != nil {
+		return err
+	}
+
+	c.conn = tc
+	c.r = bufio.NewReader(tc)
+	return nil
+}
+
+// Bind authenticates the connection with a simple bind. An empty password
+// is an unauthenticated bind, which most servers accept for any DN, so
+// callers authenticating users must reject empty passwords.
+func (c *Conn) Bind(dn, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, err := c.roundTrip(encode(opBindRequest,
+		encodeInt(tagInteger, 3),
+		encodeString(tagOctetString, dn),
+		encodeString(classContext|0, password),
+	), opBindResponse)
+	if err != nil {
+		return err
+	}
+
+	err = parseResult(resp)
+	var lerr *Error
+	if errors.As(err, &lerr) && lerr.Code == ResultInvalidCredentials {
+		return ErrInvalidCredentials
+	}
+
+	return err
+}
+
+// Search returns the entries under baseDN matching the RFC 4515 filter. Only
+// the given attributes are returned, or all user attributes if none are
+// given. Search references are ignored.
+func (c *Conn) Search(baseDN string, scope Scope, filter string, attrs ...string) ([]*Entry, error) {
+	f, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	as := make([][]byte, len(attrs))
+	for i, a := range attrs {
+		as[i] = encodeString(tagOctetString, a)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, err := c.send(encode(opSearchRequest,
+		encodeString(tagOctetString, baseDN),
+		encodeInt(tagEnumerated, int64(scope)),
+		encodeInt(tagEnumerated, 0), // neverDerefAliases
+		encodeInt(tagInteger, 0),    // no size limit
+		encodeInt(tagInteger, 0),    // no time limit
+		encodeBool(tagBoolean, false),
+		f,
+		encode(tagSequence, as...),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for {
+		op, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.tag {
+		case opSearchResultEntry:
+			e, err := parseEntry(op)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		case opSearchResultRef:
+		case opSearchResultDone:
+			if err := parseResult(op); err != nil {
+				return nil, err
+			}
+			return entries, nil
+		default:
+			return nil, errMalformed
+		}
+	}
+}
+
+// Close sends an unbind request and closes the connection.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = c.send(encode(opUnbindRequest))
+	return c.conn.Close()
+}
+
+func (c *Conn) setDeadline() {
+	if c.Timeout > 0 {
+		_ = c.conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+}
+
+// send sends a request and returns its message ID.
+func (c *Conn) send(op []byte) (int64, error) {
+	c.id++
+	c.setDeadline()
+	msg := encode(tagSequence, encodeInt(tagInteger, c.id), op)
+	if _, err := c.conn.Write(msg); err != nil {
+		return 0, err
+	}
+	return c.id, nil
+}
+
+// receive reads the next response to the request with the given message ID
+// and returns its protocol operation.
+func (c *Conn) receive(id int64) (element, error) {
+	for {
+		c.setDeadline()
+		msg, err := readElement(c.r)
+		if err != nil {
+			return element{}, err
+		}
+		if msg.tag != tagSequence {
+			return element{}, errMalformed
+		}
+
+		elems, err := msg.children()
+		if err != nil || len(elems) < 2 {
+			return element{}, errMalformed
+		}
+
+		mid, err := elems[0].int()
+		if err != nil {
+			return element{}, err
+		}
+
+		// Unsolicited notifications have a message ID of 0. They're only
+		// sent before the server closes the connection.
+		if mid == 0 {
+			if err := parseResult(elems[1]); err != nil {
+				return element{}, err
+			}
+			return element{}, errors.New("ldap: connection closed by the server")
+		}
+
+		if mid == id {
+			return elems[1], nil
+		}
+	}
+}
+
+func (c *Conn) roundTrip(op []byte, respTag byte) (element, error) {
+	id, err := c.send(op)
+	if err != nil {
+		return element{}, err
+	}
+
+	resp, err := c.receive(id)
+	if err != nil {
+		return element{}, err
+	}
+	if resp.tag != respTag {
+		return element{}, errMalformed
+	}
+
+	return resp, nil
+}
+
+// parseResult returns the error of an LDAPResult, if any.
+func parseResult(op element) error {
+	elems, err := op.children()
+	if err != nil || len(elems) < 3 {
+		return errMalformed
+	}
+
+	code, err := elems[0].int()
+	if err != nil {
+		return err
+	}
+	if code == 0 {
+		return nil
+	}
+
+	return &Error{Code: int(code), Message: string(elems[2].content)}
+}
+
+func parseEntry(op element) (*Entry, error) {
+	elems, err := op.children()
+	if err != nil || len(elems) != 2 {
+		return nil, errMalformed
+	}
+
+	e := &Entry{
+		DN:         string(elems[0].content),
+		Attributes: make(map[string][]string),
+	}
+
+	attrs, err := elems[1].children()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attr := range attrs {
+		parts, err := attr.children()
+		if err != nil || len(parts) != 2 {
+			return nil, errMalformed
+		}
+
+		vals, err := parts[1].children()
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.ToLower(string(parts[0].content))
+		for _, v := range vals {
+			e.Attributes[name] = append(e.Attributes[name], string(v.content))
+		}
+	}
+
+	return e, nil
+}
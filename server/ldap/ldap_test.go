@@ -0,0 +1,218 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// server is a fake LDAP server. Binds succeed for the DNs and passwords of
+// passwords, and searches return the entries under the base DN.
+type server struct {
+	ln        net.Listener
+	passwords map[string]string
+	entries   []*Entry
+	// filter is the BER encoded filter searches must use.
+	filter []byte
+}
+
+func newServer(t *testing.T, passwords map[string]string, entries []*Entry) *server {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &server{ln: ln, passwords: passwords, entries: entries}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() }) // nolint: errcheck
+	return s
+}
+
+func (s *server) url() string {
+	return "ldap://" + s.ln.Addr().String()
+}
+
+func (s *server) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func result(code int, msg string) []byte {
+	return bytes.Join([][]byte{
+		encodeInt(tagEnumerated, int64(code)),
+		encodeString(tagOctetString, ""),
+		encodeString(tagOctetString, msg),
+	}, nil)
+}
+
+func (s *server) handle(conn net.Conn) {
+	defer conn.Close() // nolint: errcheck
+	r := bufio.NewReader(conn)
+	var bound string
+	for {
+		msg, err := readElement(r)
+		if err != nil {
+			return
+		}
+
+		elems, _ := msg.children()
+		id, _ := elems[0].int()
+		op := elems[1]
+		reply := func(tag byte, contents ...[]byte) {
+			conn.Write(encode(tagSequence, encodeInt(tagInteger, id), encode(tag, contents...))) // nolint: errcheck
+		}
+
+		switch op.tag {
+		case opUnbindRequest:
+			return
+		case opBindRequest:
+			parts, _ := op.children()
+			dn, pw := string(parts[1].content), string(parts[2].content)
+			if want, ok := s.passwords[dn]; ok && want == pw {
+				bound = dn
+				reply(opBindResponse, result(0, ""))
+			} else {
+				reply(opBindResponse, result(ResultInvalidCredentials, "invalid credentials"))
+			}
+		case opSearchRequest:
+			if bound == "" {
+				reply(opSearchResultDone, result(50, "insufficient access"))
+				continue
+			}
+
+			parts, _ := op.children()
+			base := string(parts[0].content)
+			if s.filter != nil && !bytes.Equal(parts[6].content, s.filter[2:]) {
+				reply(opSearchResultDone, result(1, "unexpected filter "+hex.EncodeToString(parts[6].content)))
+				continue
+			}
+
+			for _, e := range s.entries {
+				if !strings.HasSuffix(e.DN, base) {
+					continue
+				}
+				var attrs [][]byte
+				for name, vals := range e.Attributes {
+					var vs [][]byte
+					for _, v := range vals {
+						vs = append(vs, encodeString(tagOctetString, v))
+					}
+					attrs = append(attrs, encode(tagSequence,
+						encodeString(tagOctetString, name),
+						encode(tagSet, vs...),
+					))
+				}
+				reply(opSearchResultEntry,
+					encodeString(tagOctetString, e.DN),
+					encode(tagSequence, attrs...),
+				)
+			}
+			reply(opSearchResultDone, result(0, ""))
+		default:
+			reply(classApplication|constructed|1, result(2, "unsupported operation"))
+		}
+	}
+}
+
+func TestBindAndSearch(t *testing.T) {
+	entries := []*Entry{
+		{DN: "uid=alice,ou=people,dc=example,dc=org", Attributes: map[string][]string{
+			"uid":      {"alice"},
+			"memberof": {"cn=admins,ou=groups,dc=example,dc=org", "cn=devs,ou=groups,dc=example,dc=org"},
+		}},
+		{DN: "uid=bob,ou=people,dc=example,dc=org", Attributes: map[string][]string{
+			"uid": {"bob"},
+		}},
+		{DN: "cn=admins,ou=groups,dc=example,dc=org"},
+	}
+	srv := newServer(t, map[string]string{"cn=admin,dc=example,dc=org": "secret"}, entries)
+	f, err := compileFilter("(objectClass=person)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.filter = f
+
+	c, err := Dial(context.Background(), srv.url(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close() // nolint: errcheck
+
+	if err := c.Bind("cn=admin,dc=example,dc=org", "nope"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("bind with wrong password: %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	var lerr *Error
+	if _, err := c.Search("dc=example,dc=org", ScopeWholeSubtree, "(objectClass=person)"); !errors.As(err, &lerr) || lerr.Code != 50 {
+		t.Errorf("search without bind: %v, want result code 50", err)
+	}
+
+	if err := c.Bind("cn=admin,dc=example,dc=org", "secret"); err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+
+	got, err := c.Search("ou=people,dc=example,dc=org", ScopeWholeSubtree, "(objectClass=person)", "uid", "memberOf")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if got[0].DN != entries[0].DN || got[0].Value("UID") != "alice" || len(got[0].Values("memberOf")) != 2 {
+		t.Errorf("unexpected entry %+v", got[0])
+	}
+	if got[1].Value("memberOf") != "" {
+		t.Errorf("unexpected memberOf %q", got[1].Value("memberOf"))
+	}
+
+	if _, err := c.Search("dc=example,dc=org", ScopeWholeSubtree, "(uid=alice)"); !errors.As(err, &lerr) || lerr.Code != 1 {
+		t.Errorf("search with other filter: %v, want result code 1", err)
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+	cases := map[string]string{
+		"(uid=alice)":           "a30c04037569640405616c696365",
+		"(objectClass=*)":       "870b6f626a656374436c617373",
+		"(cn=a*b*c)":            "a40f0402636e3009800161810162820163",
+		"(!(uid=a))":            "a20aa3080403756964040161",
+		"(&(uid=a)(cn>=b))":     "a013a3080403756964040161a5070402636e040162",
+		"(|(uid=a)(cn~=b))":     "a113a3080403756964040161a8070402636e040162",
+		`(cn=\2a\28\29)`:        "a3090402636e04032a2829",
+		"(cn=*b)":               "a4090402636e3003820162",
+		"(memberOf<=cn=x,dc=y)": "a61504086d656d6265724f660409636e3d782c64633d79",
+	}
+	for filter, want := range cases {
+		got, err := compileFilter(filter)
+		if err != nil {
+			t.Errorf("compile %q: %v", filter, err)
+			continue
+		}
+		if hex.EncodeToString(got) != want {
+			t.Errorf("compile %q = %x, want %s", filter, got, want)
+		}
+	}
+
+	for _, filter := range []string{"", "uid=a", "(uid=a", "(&)", "(=a)", "(uid=a))", `(uid=\2)`, "(u(id=a)"} {
+		if _, err := compileFilter(filter); err == nil {
+			t.Errorf("compile %q: expected error", filter)
+		}
+	}
+}
+
+func TestEscapeFilter(t *testing.T) {
+	if got, want := EscapeFilter(`a*(b)\c`), `a\2a\28b\29\5cc`; got != want {
+		t.Errorf("EscapeFilter = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+// Package listener creates the listeners of the servers, on several
+// addresses or from the sockets passed by systemd.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Names of the inherited sockets of the servers, the FileDescriptorName of
+// their systemd socket units.
+const (
+	SSH   = "ssh"
+	HTTP  = "http"
+	Git   = "git"
+	Stats = "stats"
+	ACME  = "acme"
+)
+
+// firstFD is the first file descriptor passed by systemd, SD_LISTEN_FDS_START.
+const firstFD = 3
+
+var (
+	inheritedOnce sync.Once
+	inheritedMtx  sync.Mutex
+	inherited     map[string][]*os.File
+	inheritedErr  error
+)
+
+// Addrs splits a comma-separated list of listen addresses.
+func Addrs(addrs string) []string {
+	var list []string
+	for _, addr := range strings.Split(addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			list = append(list, addr)
+		}
+	}
+
+	return list
+}
+
+// ValidateAddrs checks a comma-separated list of listen addresses.
+func ValidateAddrs(addrs string) error {
+	for _, addr := range Addrs(addrs) {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Listen returns the listeners of a server. These are the sockets named name
+// passed by systemd, or new listeners on each address of addrs otherwise.
+// IPv4 and IPv6 addresses only listen on their own family, so "0.0.0.0:22"
+// and "[::]:22" can be used together; addresses without a host listen on
+// both.
+func Listen(name string, addrs string) ([]net.Listener, error) {
+	files, err := inheritedFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		return fileListeners(files)
+	}
+
+	list := Addrs(addrs)
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%s: no listen address", name)
+	}
+
+	ls := make([]net.Listener, 0, len(list))
+	for _, addr := range list {
+		l, err := net.Listen(network(addr), addr)
+		if err != nil {
+			closeAll(ls)
+			return nil, err
+		}
+		ls = append(ls, l)
+	}
+
+	return ls, nil
+}
+
+// Serve runs serve on each listener, and returns the first error, like
+// http.ErrServerClosed once the server is closed.
+func Serve(ls []net.Listener, serve func(net.Listener) error) error {
+	errc := make(chan error, len(ls))
+	for _, l := range ls {
+		l := l
+		go func() {
+			errc <- serve(l)
+		}()
+	}
+
+	return <-errc
+}
+
+// String returns the addresses of listeners, for logging.
+func String(ls []net.Listener) string {
+	addrs := make([]string, len(ls))
+	for i, l := range ls {
+		addrs[i] = l.Addr().String()
+	}
+
+	return strings.Join(addrs, ",")
+}
+
+// network returns the network of an address, the family of its host when
+// it's an IP. The "tcp6" network sets IPV6_V6ONLY.
+func network(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "tcp"
+	}
+
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return "tcp"
+	case ip.To4() != nil:
+		return "tcp4"
+	default:
+		return "tcp6"
+	}
+}
+
+func fileListeners(files []*os.File) ([]net.Listener, error) {
+	ls := make([]net.Listener, 0, len(files))
+	for _, f := range files {
+		// FileListener duplicates the descriptor.
+		l, err := net.FileListener(f)
+		f.Close() // nolint: errcheck
+		if err != nil {
+			closeAll(ls)
+			return nil, fmt.Errorf("inherited socket %q: %w", f.Name(), err)
+		}
+		ls = append(ls, l)
+	}
+
+	return ls, nil
+}
+
+func closeAll(ls []net.Listener) {
+	for _, l := range ls {
+		l.Close() // nolint: errcheck
+	}
+}
+
+// inheritedFiles returns the sockets named name passed by systemd, and
+// forgets them so they're only used once.
+func inheritedFiles(name string) ([]*os.File, error) {
+	inheritedOnce.Do(func() {
+		var fds map[string][]int
+		fds, inheritedErr = parseEnv(os.Getpid(), os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES"))
+		// The sockets aren't passed on to the processes we start, like
+		// git and the hooks.
+		os.Unsetenv("LISTEN_PID")     // nolint: errcheck
+		os.Unsetenv("LISTEN_FDS")     // nolint: errcheck
+		os.Unsetenv("LISTEN_FDNAMES") // nolint: errcheck
+
+		inherited = map[string][]*os.File{}
+		for n, list := range fds {
+			for _, fd := range list {
+				inherited[n] = append(inherited[n], os.NewFile(uintptr(fd), n))
+			}
+		}
+	})
+	if inheritedErr != nil {
+		return nil, inheritedErr
+	}
+
+	inheritedMtx.Lock()
+	defer inheritedMtx.Unlock()
+	files := inherited[name]
+	delete(inherited, name)
+	return files, nil
+}
+
+// parseEnv parses the socket activation environment variables of systemd,
+// and returns the descriptors by name. Nothing is returned when they're meant
+// for another process.
+func parseEnv(pid int, listenPID, listenFDs, listenFDNames string) (map[string][]int, error) {
+	if listenPID == "" || listenFDs == "" {
+		return nil, nil
+	}
+
+	p, err := strconv.Atoi(listenPID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_PID: %w", err)
+	}
+	if p != pid {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(listenFDs)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %q", listenFDs)
+	}
+
+	var names []string
+	if listenFDNames != "" {
+		names = strings.Split(listenFDNames, ":")
+	}
+	if len(names) != n {
+		return nil, errors.New("inherited sockets must be named after their server with FileDescriptorName")
+	}
+
+	fds := map[string][]int{}
+	for i, name := range names {
+		fds[name] = append(fds[name], firstFD+i)
+	}
+
+	return fds, nil
+}
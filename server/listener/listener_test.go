@@ -0,0 +1,84 @@
+package listener
+
+import (
+	"net"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestAddrs(t *testing.T) {
+	is := is.New(t)
+	is.Equal(Addrs(""), []string(nil))
+	is.Equal(Addrs(":22"), []string{":22"})
+	is.Equal(Addrs("0.0.0.0:22, [::]:22,"), []string{"0.0.0.0:22", "[::]:22"})
+	is.NoErr(ValidateAddrs("0.0.0.0:22,[::]:22"))
+	is.True(ValidateAddrs("localhost") != nil)
+}
+
+func TestNetwork(t *testing.T) {
+	is := is.New(t)
+	is.Equal(network(":22"), "tcp")
+	is.Equal(network("localhost:22"), "tcp")
+	is.Equal(network("0.0.0.0:22"), "tcp4")
+	is.Equal(network("[::]:22"), "tcp6")
+}
+
+func TestListen(t *testing.T) {
+	is := is.New(t)
+	ls, err := Listen(SSH, "127.0.0.1:0,127.0.0.1:0")
+	is.NoErr(err)
+	is.Equal(len(ls), 2)
+	closeAll(ls)
+
+	_, err = Listen(SSH, "")
+	is.True(err != nil)
+}
+
+func TestServe(t *testing.T) {
+	is := is.New(t)
+	ls, err := Listen(HTTP, "127.0.0.1:0,127.0.0.1:0")
+	is.NoErr(err)
+	accepted := make(chan string, len(ls))
+	done := make(chan error)
+	go func() {
+		done <- Serve(ls, func(l net.Listener) error {
+			c, err := l.Accept()
+			if err != nil {
+				return err
+			}
+			accepted <- l.Addr().String()
+			return c.Close()
+		})
+	}()
+
+	for _, l := range ls {
+		c, err := net.Dial("tcp", l.Addr().String())
+		is.NoErr(err)
+		c.Close() // nolint: errcheck
+	}
+	is.NoErr(<-done)
+	<-accepted
+	closeAll(ls)
+}
+
+func TestParseEnv(t *testing.T) {
+	is := is.New(t)
+	fds, err := parseEnv(42, "", "", "")
+	is.NoErr(err)
+	is.Equal(len(fds), 0)
+
+	// Meant for another process.
+	fds, err = parseEnv(42, "41", "1", "ssh")
+	is.NoErr(err)
+	is.Equal(len(fds), 0)
+
+	fds, err = parseEnv(42, "42", "3", "ssh:ssh:http")
+	is.NoErr(err)
+	is.Equal(fds, map[string][]int{"ssh": {3, 4}, "http": {5}})
+
+	_, err = parseEnv(42, "42", "2", "")
+	is.True(err != nil)
+	_, err = parseEnv(42, "42", "x", "ssh")
+	is.True(err != nil)
+}
@@ -3,12 +3,36 @@ package log
 import (
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/server/config"
 )
 
+// Subsystems with their own log level.
+const (
+	SubsystemSSH  = "ssh"
+	SubsystemGit  = "git"
+	SubsystemHTTP = "http"
+	SubsystemUI   = "ui"
+	SubsystemDB   = "db"
+)
+
+var (
+	levelsMtx sync.RWMutex
+	levels    = map[string]log.Level{}
+	rootLevel = log.InfoLevel
+	// tracked are the long-lived loggers whose level follows the reloads.
+	tracked []trackedLogger
+
+	// transportSampling logs the transport lines of one in every
+	// transportSampling requests and sessions.
+	transportSampling atomic.Int64
+	transportCount    atomic.Int64
+)
+
 // NewLogger returns a new logger with default settings.
 func NewLogger(cfg *config.Config) (*log.Logger, *os.File, error) {
 	logger := log.NewWithOptions(os.Stderr, log.Options{
@@ -16,13 +40,8 @@ func NewLogger(cfg *config.Config) (*log.Logger, *os.File, error) {
 		TimeFormat:      time.DateOnly,
 	})
 
-	switch {
-	case config.IsVerbose():
-		logger.SetReportCaller(true)
-		fallthrough
-	case config.IsDebug():
-		logger.SetLevel(log.DebugLevel)
-	}
+	logger.SetLevel(level(cfg))
+	logger.SetReportCaller(config.IsVerbose())
 
 	logger.SetTimeFormat(cfg.Log.TimeFormat)
 
@@ -35,6 +54,9 @@ func NewLogger(cfg *config.Config) (*log.Logger, *os.File, error) {
 		logger.SetFormatter(log.TextFormatter)
 	}
 
+	Track(logger, "")
+	Reload(cfg)
+
 	var f *os.File
 	if cfg.Log.Path != "" {
 		f, err := os.OpenFile(cfg.Log.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
@@ -46,3 +68,106 @@ func NewLogger(cfg *config.Config) (*log.Logger, *os.File, error) {
 
 	return logger, f, nil
 }
+
+type trackedLogger struct {
+	logger *log.Logger
+	prefix string
+}
+
+// level returns the level of the loggers without an overridden level.
+func level(cfg *config.Config) log.Level {
+	if config.IsDebug() {
+		return log.DebugLevel
+	}
+	if cfg.Log.Level != "" {
+		return log.ParseLevel(cfg.Log.Level)
+	}
+	return log.InfoLevel
+}
+
+// Reload applies the log levels and the transport sampling of cfg to the
+// loggers created with Track. The other loggers keep their level until
+// they're created again, i.e. for the next request or session.
+func Reload(cfg *config.Config) {
+	levelsMtx.Lock()
+	defer levelsMtx.Unlock()
+	rootLevel = level(cfg)
+	setLevels(cfg.Log.Levels)
+	for _, t := range tracked {
+		t.logger.SetLevel(subsystemLevel(t.prefix))
+	}
+	transportSampling.Store(int64(cfg.Log.TransportSampling))
+}
+
+// Track makes the level of a long-lived logger, like the logger of a server,
+// follow Reload, at the level of the subsystem of prefix. It returns l.
+func Track(l *log.Logger, prefix string) *log.Logger {
+	levelsMtx.Lock()
+	defer levelsMtx.Unlock()
+	tracked = append(tracked, trackedLogger{logger: l, prefix: prefix})
+	return l
+}
+
+func setLevels(cfg config.LogLevelsConfig) {
+	levels = map[string]log.Level{}
+	for sub, lvl := range map[string]string{
+		SubsystemSSH:  cfg.SSH,
+		SubsystemGit:  cfg.Git,
+		SubsystemHTTP: cfg.HTTP,
+		SubsystemUI:   cfg.UI,
+		SubsystemDB:   cfg.DB,
+	} {
+		if lvl != "" {
+			levels[sub] = log.ParseLevel(lvl)
+		}
+	}
+}
+
+// SubsystemLevel returns the level of the subsystem of a prefix, if it's
+// overridden. The subsystem is the part of the prefix before the first dot.
+func SubsystemLevel(prefix string) (log.Level, bool) {
+	sub, _, _ := strings.Cut(prefix, ".")
+	levelsMtx.RLock()
+	defer levelsMtx.RUnlock()
+	lvl, ok := levels[sub]
+	return lvl, ok
+}
+
+// subsystemLevel returns the level of the subsystem of a prefix, or the root
+// level. levelsMtx must be held.
+func subsystemLevel(prefix string) log.Level {
+	sub, _, _ := strings.Cut(prefix, ".")
+	if lvl, ok := levels[sub]; ok {
+		return lvl
+	}
+	return rootLevel
+}
+
+// WithPrefix returns a new logger with the given prefix, at the level of its
+// subsystem if it's overridden. Loggers derived from it keep its level.
+func WithPrefix(l *log.Logger, prefix string) *log.Logger {
+	sl := l.WithPrefix(prefix)
+	if lvl, ok := SubsystemLevel(prefix); ok {
+		sl.SetLevel(lvl)
+	}
+
+	return sl
+}
+
+// WithTrackedPrefix is like WithPrefix for long-lived loggers, their level
+// follows Reload.
+func WithTrackedPrefix(l *log.Logger, prefix string) *log.Logger {
+	return Track(WithPrefix(l, prefix), prefix)
+}
+
+// SampleTransport reports whether the transport lines of a request or a
+// session, like HTTP requests and SSH connections, are logged. Errors are
+// always logged.
+func SampleTransport() bool {
+	n := transportSampling.Load()
+	if n <= 1 {
+		return true
+	}
+
+	return (transportCount.Add(1)-1)%n == 0
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/config"
+)
+
+func TestWithPrefix(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Log.Levels.Git = "debug"
+	cfg.Log.Levels.DB = "error"
+	logger, _, err := NewLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Reload(config.DefaultConfig())
+
+	cases := []struct {
+		prefix string
+		want   log.Level
+	}{
+		{"git.daemon", log.DebugLevel},
+		{"db", log.ErrorLevel},
+		{"ssh", log.InfoLevel},
+		{"gitweb", log.InfoLevel},
+	}
+	for _, c := range cases {
+		if got := WithPrefix(logger, c.prefix).GetLevel(); got != c.want {
+			t.Errorf("WithPrefix(%q) level = %s, want %s", c.prefix, got, c.want)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Log.Levels.SSH = "debug"
+	logger, _, err := NewLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Reload(config.DefaultConfig())
+
+	ssh := WithTrackedPrefix(logger, SubsystemSSH)
+	http := WithTrackedPrefix(logger, SubsystemHTTP)
+	if got := ssh.GetLevel(); got != log.DebugLevel {
+		t.Errorf("ssh level = %s, want %s", got, log.DebugLevel)
+	}
+
+	cfg.Log.Level = "warn"
+	cfg.Log.Levels.SSH = ""
+	cfg.Log.Levels.HTTP = "error"
+	Reload(cfg)
+	for l, want := range map[*log.Logger]log.Level{
+		logger: log.WarnLevel,
+		ssh:    log.WarnLevel,
+		http:   log.ErrorLevel,
+	} {
+		if got := l.GetLevel(); got != want {
+			t.Errorf("%q level = %s, want %s", l.GetPrefix(), got, want)
+		}
+	}
+}
+
+func TestSampleTransport(t *testing.T) {
+	transportSampling.Store(3)
+	transportCount.Store(0)
+	defer transportSampling.Store(0)
+
+	var n int
+	for i := 0; i < 9; i++ {
+		if SampleTransport() {
+			n++
+		}
+	}
+	if n != 3 {
+		t.Errorf("sampled %d of 9 transport logs, want 3", n)
+	}
+}
@@ -0,0 +1,171 @@
+// Package pool implements a bounded pool of workers, with a queue of waiting
+// operations and a limit of concurrent operations per key, like a repository.
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrQueueFull is returned when there are too many operations waiting for a
+// worker.
+var ErrQueueFull = errors.New("queue is full")
+
+// Options are the limits of a pool. A value of 0 means no limit.
+type Options struct {
+	// Workers is the number of operations running at once.
+	Workers int
+	// Queue is the number of operations waiting for a worker.
+	Queue int
+	// PerKey is the number of operations of each key running at once.
+	PerKey int
+}
+
+type key struct {
+	sem  chan struct{}
+	refs int
+}
+
+// Pool limits the number of operations running at once. Operations wait in
+// line for a worker until the queue is full.
+type Pool struct {
+	opts    Options
+	workers chan struct{}
+
+	mu      sync.Mutex
+	waiting int
+	running int
+	keys    map[string]*key
+}
+
+// New returns a new pool with the given limits.
+func New(opts Options) *Pool {
+	p := &Pool{
+		opts: opts,
+		keys: make(map[string]*key),
+	}
+	if opts.Workers > 0 {
+		p.workers = make(chan struct{}, opts.Workers)
+	}
+	return p
+}
+
+// Acquire waits for a worker to run an operation of k. It returns a function
+// that releases the worker once the operation is done, ErrQueueFull when
+// there are too many operations waiting, or the error of ctx when it's done
+// before a worker is available.
+//
+// Operations wait for a slot of their key before waiting for a worker, so
+// that a busy key doesn't hold workers other keys could use.
+func (p *Pool) Acquire(ctx context.Context, k string) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+
+	kk := p.ref(k)
+	var queued bool
+	defer func() {
+		if queued {
+			p.mu.Lock()
+			p.waiting--
+			p.mu.Unlock()
+		}
+	}()
+
+	acquire := func(sem chan struct{}) error {
+		if sem == nil {
+			return nil
+		}
+		select {
+		case sem <- struct{}{}:
+			return nil
+		default:
+		}
+
+		if !queued {
+			p.mu.Lock()
+			if p.opts.Queue > 0 && p.waiting >= p.opts.Queue {
+				p.mu.Unlock()
+				return ErrQueueFull
+			}
+			p.waiting++
+			queued = true
+			p.mu.Unlock()
+		}
+
+		select {
+		case sem <- struct{}{}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := acquire(kk.sem); err != nil {
+		p.unref(k, kk)
+		return nil, err
+	}
+	if err := acquire(p.workers); err != nil {
+		if kk.sem != nil {
+			<-kk.sem
+		}
+		p.unref(k, kk)
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.running++
+	p.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if p.workers != nil {
+				<-p.workers
+			}
+			if kk.sem != nil {
+				<-kk.sem
+			}
+			p.mu.Lock()
+			p.running--
+			p.mu.Unlock()
+			p.unref(k, kk)
+		})
+	}, nil
+}
+
+// Stats returns the number of operations running and waiting for a worker.
+func (p *Pool) Stats() (running int, waiting int) {
+	if p == nil {
+		return 0, 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running, p.waiting
+}
+
+// ref returns the slots of k, the slots are dropped once unused.
+func (p *Pool) ref(k string) *key {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	kk, ok := p.keys[k]
+	if !ok {
+		kk = &key{}
+		if p.opts.PerKey > 0 {
+			kk.sem = make(chan struct{}, p.opts.PerKey)
+		}
+		p.keys[k] = kk
+	}
+	kk.refs++
+	return kk
+}
+
+func (p *Pool) unref(k string, kk *key) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	kk.refs--
+	if kk.refs == 0 {
+		delete(p.keys, k)
+	}
+}
@@ -0,0 +1,94 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	p := New(Options{Workers: 2, Queue: 1})
+	ctx := context.Background()
+
+	r1, err := p.Acquire(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := p.Acquire(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		release, err := p.Acquire(ctx, "c")
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+
+	// Wait for the operation to be queued.
+	for {
+		if _, waiting := p.Stats(); waiting == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := p.Acquire(ctx, "d"); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected a full queue, got %v", err)
+	}
+
+	r1()
+	r1() // releasing twice is a no-op
+	if err := <-acquired; err != nil {
+		t.Errorf("queued operation failed: %v", err)
+	}
+
+	r2()
+	if running, waiting := p.Stats(); running != 0 || waiting != 0 {
+		t.Errorf("expected an idle pool, got %d running and %d waiting", running, waiting)
+	}
+	if len(p.keys) != 0 {
+		t.Errorf("expected unused keys to be dropped, got %d", len(p.keys))
+	}
+}
+
+func TestPoolPerKey(t *testing.T) {
+	p := New(Options{Workers: 2, PerKey: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	release, err := p.Acquire(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	// Other keys can use the second worker.
+	rb, err := p.Acquire(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb()
+
+	if _, err := p.Acquire(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected to wait for the key, got %v", err)
+	}
+	if running, waiting := p.Stats(); running != 1 || waiting != 0 {
+		t.Errorf("expected 1 running, got %d running and %d waiting", running, waiting)
+	}
+}
+
+func TestPoolUnlimited(t *testing.T) {
+	var nilPool *Pool
+	for _, p := range []*Pool{New(Options{}), nilPool} {
+		for i := 0; i < 100; i++ {
+			if _, err := p.Acquire(context.Background(), "a"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package presence
+
+import "context"
+
+// ContextKey is the key for the presence tracker in the context.
+var ContextKey = &struct{ string }{"presence"}
+
+// FromContext returns the presence tracker from a context.
+func FromContext(ctx context.Context) *Tracker {
+	if t, ok := ctx.Value(ContextKey).(*Tracker); ok {
+		return t
+	}
+
+	return nil
+}
+
+// WithContext returns a new context with the presence tracker attached.
+func WithContext(ctx context.Context, t *Tracker) context.Context {
+	return context.WithValue(ctx, ContextKey, t)
+}
+
+// ClientContextKey is the key for the current session's presence client in
+// the context.
+var ClientContextKey = &struct{ string }{"presence-client"}
+
+// ClientFromContext returns the presence client from a context.
+func ClientFromContext(ctx context.Context) *Client {
+	if c, ok := ctx.Value(ClientContextKey).(*Client); ok {
+		return c
+	}
+
+	return nil
+}
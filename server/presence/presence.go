@@ -0,0 +1,141 @@
+// Package presence keeps track of what users are currently looking at in
+// their TUI sessions.
+//
+// Sessions join a Tracker and report their location as the user navigates.
+// Every time a location changes, all the other sessions are notified so that
+// they can show who else is viewing the same repository or commit.
+package presence
+
+import (
+	"sort"
+	"sync"
+)
+
+// Location describes what a session is currently viewing.
+type Location struct {
+	// Repo is the name of the repository.
+	Repo string
+	// Commit is the hash of the commit, if any.
+	Commit string
+}
+
+// Tracker keeps track of the sessions' locations.
+type Tracker struct {
+	mu      sync.Mutex
+	clients map[*Client]struct{}
+}
+
+// NewTracker returns a new Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		clients: make(map[*Client]struct{}),
+	}
+}
+
+// Join registers a new session for the user with the given username.
+func (t *Tracker) Join(username string) *Client {
+	c := &Client{
+		tracker:  t,
+		username: username,
+		events:   make(chan struct{}, 1),
+	}
+	t.mu.Lock()
+	t.clients[c] = struct{}{}
+	t.mu.Unlock()
+	return c
+}
+
+// notify notifies all the clients except c that something has changed.
+// It must be called with the tracker lock held.
+func (t *Tracker) notify(c *Client) {
+	for cc := range t.clients {
+		if cc == c {
+			continue
+		}
+		// Events are coalesced, a pending event is as good as a new one.
+		select {
+		case cc.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Client is a session registered with a Tracker.
+type Client struct {
+	tracker  *Tracker
+	username string
+	loc      Location
+	events   chan struct{}
+	left     bool
+}
+
+// Username returns the username of the client.
+func (c *Client) Username() string {
+	return c.username
+}
+
+// Location returns the current location of the client.
+func (c *Client) Location() Location {
+	c.tracker.mu.Lock()
+	defer c.tracker.mu.Unlock()
+	return c.loc
+}
+
+// Move updates the location of the client and notifies the other clients.
+func (c *Client) Move(loc Location) {
+	c.tracker.mu.Lock()
+	defer c.tracker.mu.Unlock()
+	if c.left || c.loc == loc {
+		return
+	}
+	c.loc = loc
+	c.tracker.notify(c)
+}
+
+// Leave unregisters the client from the tracker. The events channel is closed
+// afterwards.
+func (c *Client) Leave() {
+	c.tracker.mu.Lock()
+	defer c.tracker.mu.Unlock()
+	if c.left {
+		return
+	}
+	c.left = true
+	delete(c.tracker.clients, c)
+	close(c.events)
+	c.tracker.notify(c)
+}
+
+// Events returns a channel that receives a value every time another client
+// changes its location. The channel is closed when the client leaves.
+func (c *Client) Events() <-chan struct{} {
+	return c.events
+}
+
+// Viewers returns the sorted usernames of the other users viewing loc. If loc
+// has no commit, all the users viewing the repository are returned.
+func (c *Client) Viewers(loc Location) []string {
+	if loc.Repo == "" {
+		return nil
+	}
+
+	c.tracker.mu.Lock()
+	defer c.tracker.mu.Unlock()
+	seen := map[string]struct{}{}
+	for cc := range c.tracker.clients {
+		if cc.username == c.username || cc.loc.Repo != loc.Repo {
+			continue
+		}
+		if loc.Commit != "" && cc.loc.Commit != loc.Commit {
+			continue
+		}
+		seen[cc.username] = struct{}{}
+	}
+
+	viewers := make([]string, 0, len(seen))
+	for u := range seen {
+		viewers = append(viewers, u)
+	}
+	sort.Strings(viewers)
+	return viewers
+}
@@ -0,0 +1,70 @@
+package presence
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestViewers(t *testing.T) {
+	is := is.New(t)
+	tr := NewTracker()
+	alice := tr.Join("alice")
+	bob := tr.Join("bob")
+	bob2 := tr.Join("bob")
+	carol := tr.Join("carol")
+
+	alice.Move(Location{Repo: "repo1"})
+	bob.Move(Location{Repo: "repo1", Commit: "abc"})
+	bob2.Move(Location{Repo: "repo1", Commit: "def"})
+	carol.Move(Location{Repo: "repo2"})
+
+	is.Equal(alice.Viewers(Location{Repo: "repo1"}), []string{"bob"})
+	is.Equal(bob.Viewers(Location{Repo: "repo1"}), []string{"alice"})
+	is.Equal(alice.Viewers(Location{Repo: "repo1", Commit: "abc"}), []string{"bob"})
+	is.Equal(alice.Viewers(Location{Repo: "repo1", Commit: "123"}), []string{})
+	is.Equal(carol.Viewers(Location{Repo: "repo2"}), []string{})
+	is.Equal(carol.Viewers(Location{}), nil)
+
+	bob.Leave()
+	bob2.Leave()
+	is.Equal(alice.Viewers(Location{Repo: "repo1"}), []string{})
+}
+
+func TestEvents(t *testing.T) {
+	is := is.New(t)
+	tr := NewTracker()
+	alice := tr.Join("alice")
+	bob := tr.Join("bob")
+
+	// Moving notifies the other clients only.
+	bob.Move(Location{Repo: "repo"})
+	bob.Move(Location{Repo: "repo", Commit: "abc"})
+	_, ok := <-alice.Events()
+	is.True(ok)
+	select {
+	case <-alice.Events():
+		t.Fatal("events should be coalesced")
+	case <-bob.Events():
+		t.Fatal("a client should not be notified of its own moves")
+	default:
+	}
+
+	// Moving to the same location is a no-op.
+	bob.Move(Location{Repo: "repo", Commit: "abc"})
+	select {
+	case <-alice.Events():
+		t.Fatal("unexpected event")
+	default:
+	}
+
+	bob.Leave()
+	_, ok = <-alice.Events()
+	is.True(ok)
+	_, ok = <-bob.Events()
+	is.True(!ok)
+
+	// Moving after leaving is a no-op.
+	bob.Move(Location{Repo: "other"})
+	is.Equal(bob.Location(), Location{Repo: "repo", Commit: "abc"})
+}
@@ -1,6 +1,10 @@
 package proto
 
-import "time"
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+)
 
 // AccessToken represents an access token.
 type AccessToken struct {
@@ -10,4 +14,15 @@ type AccessToken struct {
 	TokenHash string
 	ExpiresAt time.Time
 	CreatedAt time.Time
+	// AccessLevel is the highest access level the token grants, the token
+	// never grants more than its user has.
+	AccessLevel access.AccessLevel
+	// Repo is the name of the only repository the token grants access to. An
+	// empty name means all repositories.
+	Repo string
+}
+
+// IsExpired returns whether the token is expired.
+func (t AccessToken) IsExpired() bool {
+	return !t.ExpiresAt.IsZero() && time.Now().After(t.ExpiresAt)
 }
@@ -0,0 +1,108 @@
+package proto
+
+import "time"
+
+// AuditAction is a kind of security-relevant action recorded in the audit
+// log. Actions are grouped by the prefix before the dot.
+type AuditAction string
+
+// Audit actions.
+const (
+	AuditAuthSuccess AuditAction = "auth.success"
+	AuditAuthFailure AuditAction = "auth.failure"
+
+	AuditConnectionReject AuditAction = "connection.reject"
+
+	AuditTokenCreate AuditAction = "token.create"
+	AuditTokenRotate AuditAction = "token.rotate"
+	AuditTokenDelete AuditAction = "token.delete"
+	AuditTokenUse    AuditAction = "token.use"
+
+	AuditRepoCreate        AuditAction = "repo.create"
+	AuditRepoImport        AuditAction = "repo.import"
+	AuditRepoDelete        AuditAction = "repo.delete"
+	AuditRepoRename        AuditAction = "repo.rename"
+	AuditRepoVisibility    AuditAction = "repo.visibility"
+	AuditRepoAnonAccess    AuditAction = "repo.anon-access"
+	AuditRepoDaemonExport  AuditAction = "repo.daemon-export"
+	AuditRepoDefaultBranch AuditAction = "repo.default-branch"
+	AuditRepoForcePush     AuditAction = "repo.force-push"
+	AuditRepoTransfer      AuditAction = "repo.transfer"
+	AuditRepoArchive       AuditAction = "repo.archive"
+	AuditRepoUnarchive     AuditAction = "repo.unarchive"
+	AuditRepoHookSet       AuditAction = "repo.hook-set"
+	AuditRepoHookRemove    AuditAction = "repo.hook-remove"
+
+	AuditCollabAdd    AuditAction = "collab.add"
+	AuditCollabRemove AuditAction = "collab.remove"
+
+	AuditDeployKeyAdd    AuditAction = "deploy-key.add"
+	AuditDeployKeyRemove AuditAction = "deploy-key.remove"
+
+	AuditBranchProtect   AuditAction = "branch.protect"
+	AuditBranchUnprotect AuditAction = "branch.unprotect"
+
+	AuditRefPermissionGrant  AuditAction = "ref-permission.grant"
+	AuditRefPermissionRevoke AuditAction = "ref-permission.revoke"
+
+	AuditReleaseCreate AuditAction = "release.create"
+	AuditReleaseDelete AuditAction = "release.delete"
+
+	AuditUserCreate      AuditAction = "user.create"
+	AuditUserDelete      AuditAction = "user.delete"
+	AuditUserRename      AuditAction = "user.rename"
+	AuditUserAdmin       AuditAction = "user.admin"
+	AuditUserSuspend     AuditAction = "user.suspend"
+	AuditUserActivate    AuditAction = "user.activate"
+	AuditUserPassword    AuditAction = "user.password"
+	AuditUserKeyAdd      AuditAction = "user.key-add"
+	AuditUserKeyRemove   AuditAction = "user.key-remove"
+	AuditUserKeyRestrict AuditAction = "user.key-restrict"
+	AuditUserImpersonate AuditAction = "user.impersonate"
+	AuditUserIPRules     AuditAction = "user.ip-rules"
+	AuditUser2FAEnable   AuditAction = "user.2fa-enable"
+	AuditUser2FADisable  AuditAction = "user.2fa-disable"
+
+	AuditSessionKill AuditAction = "session.kill"
+
+	AuditTaskRun AuditAction = "task.run"
+
+	AuditSettingsAnonAccess   AuditAction = "settings.anon-access"
+	AuditSettingsAllowKeyless AuditAction = "settings.allow-keyless"
+	AuditSettingsMaintenance  AuditAction = "settings.maintenance"
+	AuditSettingsMOTD         AuditAction = "settings.motd"
+
+	AuditConfigReload AuditAction = "config.reload"
+	AuditBroadcast    AuditAction = "broadcast"
+)
+
+// AuditEvent is a security-relevant action recorded in the audit log.
+type AuditEvent struct {
+	ID     int64       `json:"id"`
+	Action AuditAction `json:"action"`
+	// Username is the user who performed the action, if any.
+	Username string `json:"username,omitempty"`
+	// Impersonator is the admin impersonating the user, if any.
+	Impersonator string `json:"impersonator,omitempty"`
+	// Repo is the repository the action applies to, if any.
+	Repo string `json:"repo,omitempty"`
+	// Target is what the action applies to, i.e. a user, a branch, or a
+	// token.
+	Target     string    `json:"target,omitempty"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	Details    string    `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditFilter selects audit events. Zero fields match all events.
+type AuditFilter struct {
+	// Action matches the action, or the group of actions it names, i.e.
+	// "repo" matches "repo.create".
+	Action   string
+	Username string
+	Repo     string
+	Since    time.Time
+	Until    time.Time
+	// Limit is the maximum number of most recent events returned.
+	Limit int
+}
@@ -0,0 +1,16 @@
+package proto
+
+import "time"
+
+// StaleBranch is a branch pruned, or that would be pruned, by
+// PruneBranches.
+type StaleBranch struct {
+	// Name is the name of the branch.
+	Name string
+	// Commit is the ID of the last commit of the branch.
+	Commit string
+	// CommittedAt is the committer date of the last commit of the branch.
+	CommittedAt time.Time
+	// Merged is true if the branch is fully merged into the default branch.
+	Merged bool
+}
@@ -0,0 +1,44 @@
+package proto
+
+import (
+	"path"
+	"strings"
+)
+
+// BranchProtection represents a branch protection rule.
+type BranchProtection struct {
+	ID int64
+	// Pattern is a branch name or a glob that matches branch names, i.e.
+	// "main" or "release/*".
+	Pattern string
+	// NoForcePush rejects non fast-forward pushes.
+	NoForcePush bool
+	// NoDeletion rejects deleting matching branches.
+	NoDeletion bool
+	// RequireSigned rejects pushes that contain commits without a verified
+	// signature.
+	RequireSigned bool
+	// RestrictPush only allows admins and PushUsers to push.
+	RestrictPush bool
+	// PushUsers are the usernames allowed to push when RestrictPush is set.
+	PushUsers []string
+}
+
+// BranchProtectionOptions are options for protecting a branch.
+type BranchProtectionOptions struct {
+	AllowForcePush bool
+	AllowDeletion  bool
+	RequireSigned  bool
+	PushUsers      []string
+}
+
+// Matches returns true if the rule applies to the given branch. The branch
+// can be either a short name or a full reference name.
+func (p BranchProtection) Matches(branch string) bool {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	if p.Pattern == branch {
+		return true
+	}
+	ok, err := path.Match(p.Pattern, branch)
+	return err == nil && ok
+}
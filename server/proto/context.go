@@ -8,6 +8,10 @@ var ContextKeyRepository = &struct{ string }{"repository"}
 // ContextKeyUser is the context key for the user.
 var ContextKeyUser = &struct{ string }{"user"}
 
+// ContextKeyImpersonator is the context key for the admin impersonating the
+// user.
+var ContextKeyImpersonator = &struct{ string }{"impersonator"}
+
 // RepositoryFromContext returns the repository from the context.
 func RepositoryFromContext(ctx context.Context) Repository {
 	if r, ok := ctx.Value(ContextKeyRepository).(Repository); ok {
@@ -33,3 +37,34 @@ func WithRepositoryContext(ctx context.Context, r Repository) context.Context {
 func WithUserContext(ctx context.Context, u User) context.Context {
 	return context.WithValue(ctx, ContextKeyUser, u)
 }
+
+// ImpersonatorFromContext returns the admin impersonating the user from the
+// context.
+func ImpersonatorFromContext(ctx context.Context) User {
+	if u, ok := ctx.Value(ContextKeyImpersonator).(User); ok {
+		return u
+	}
+	return nil
+}
+
+// WithImpersonatorContext returns a new context with the admin impersonating
+// the user.
+func WithImpersonatorContext(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, ContextKeyImpersonator, u)
+}
+
+// ContextKeyRemoteAddr is the context key for the address of the client.
+var ContextKeyRemoteAddr = &struct{ string }{"remote-addr"}
+
+// RemoteAddrFromContext returns the address of the client from the context.
+func RemoteAddrFromContext(ctx context.Context) string {
+	if addr, ok := ctx.Value(ContextKeyRemoteAddr).(string); ok {
+		return addr
+	}
+	return ""
+}
+
+// WithRemoteAddrContext returns a new context with the address of the client.
+func WithRemoteAddrContext(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, ContextKeyRemoteAddr, addr)
+}
@@ -0,0 +1,18 @@
+package proto
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"golang.org/x/crypto/ssh"
+)
+
+// DeployKey represents a public key that has access to a single repository
+// without belonging to a user.
+type DeployKey struct {
+	ID          int64
+	Title       string
+	PublicKey   ssh.PublicKey
+	AccessLevel access.AccessLevel
+	CreatedAt   time.Time
+}
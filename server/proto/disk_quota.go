@@ -0,0 +1,32 @@
+package proto
+
+import "time"
+
+// DiskUsage is the disk space a repository takes, in bytes.
+type DiskUsage struct {
+	// Objects is the size of the git repository.
+	Objects int64
+	// LFS is the total size of the Git LFS objects.
+	LFS int64
+	// ObjectCount is the number of git objects, loose and packed.
+	ObjectCount int64
+	// PackSize is the size of the packfiles.
+	PackSize int64
+	// UpdatedAt is when the usage was last computed, zero if it never was.
+	UpdatedAt time.Time
+}
+
+// Total returns the total disk usage.
+func (u DiskUsage) Total() int64 {
+	return u.Objects + u.LFS
+}
+
+// LargeBlob is one of the largest blobs of a repository.
+type LargeBlob struct {
+	// Oid is the object ID of the blob.
+	Oid string
+	// Path is a path the blob is found at in the history.
+	Path string
+	// Size is the size of the blob.
+	Size int64
+}
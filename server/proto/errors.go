@@ -2,6 +2,8 @@ package proto
 
 import (
 	"errors"
+	"fmt"
+	"time"
 )
 
 var (
@@ -15,8 +17,139 @@ var (
 	ErrRepoExist = errors.New("repository already exists")
 	// ErrUserNotFound is returned when a user is not found.
 	ErrUserNotFound = errors.New("user not found")
+	// ErrUserSuspended is returned when a suspended user authenticates.
+	ErrUserSuspended = errors.New("user is suspended")
 	// ErrTokenNotFound is returned when a token is not found.
 	ErrTokenNotFound = errors.New("token not found")
 	// ErrTokenExpired is returned when a token is expired.
 	ErrTokenExpired = errors.New("token expired")
+	// ErrBranchProtected is returned when an operation is rejected by a branch
+	// protection rule.
+	ErrBranchProtected = errors.New("branch is protected")
+	// ErrDefaultBranch is returned when deleting the default branch of a
+	// repository.
+	ErrDefaultBranch = errors.New("cannot delete the default branch")
+	// ErrBranchProtectionNotFound is returned when a branch protection rule is
+	// not found.
+	ErrBranchProtectionNotFound = errors.New("branch protection not found")
+	// ErrRefRestricted is returned when a user isn't allowed to push to a
+	// reference by the reference permissions of a repository.
+	ErrRefRestricted = errors.New("reference is restricted")
+	// ErrRefPermissionNotFound is returned when a reference permission is not
+	// found.
+	ErrRefPermissionNotFound = errors.New("reference permission not found")
+	// ErrPushRejected is returned when a push is rejected by the push policy.
+	ErrPushRejected = errors.New("push rejected")
+	// ErrIdempotencyKeyReused is returned when an idempotency key is reused
+	// for a different request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+	// ErrDeployKeyNotFound is returned when a deploy key is not found.
+	ErrDeployKeyNotFound = errors.New("deploy key not found")
+	// ErrSessionNotFound is returned when no active session has an ID.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrPublicKeyInUse is returned when a public key is already registered to
+	// a user.
+	ErrPublicKeyInUse = errors.New("public key is already in use")
+	// ErrPublicKeyNotFound is returned when a user has no public key matching
+	// a key or an ID.
+	ErrPublicKeyNotFound = errors.New("public key not found")
+	// ErrPublicKeyExpired is returned when authenticating with an expired
+	// public key.
+	ErrPublicKeyExpired = errors.New("public key expired")
+	// ErrKeyRestricted is returned when a restricted public key runs a
+	// command it isn't allowed to.
+	ErrKeyRestricted = errors.New("public key is restricted")
+	// ErrTwoFactorRequired is returned when a command needs a two-factor code
+	// and none was given.
+	ErrTwoFactorRequired = errors.New("two-factor code required")
+	// ErrInvalidTwoFactorCode is returned when a two-factor code is wrong,
+	// expired, or was already used.
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorEnabled is returned when enrolling a user that already
+	// enrolled in two-factor authentication.
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnrolled is returned when confirming or disabling the
+	// two-factor authentication of a user that didn't enroll.
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication is not enabled")
+	// ErrUntrustedCertificate is returned when a certificate isn't a valid
+	// user certificate signed by a trusted certificate authority.
+	ErrUntrustedCertificate = errors.New("untrusted certificate")
+	// ErrAddressDenied is returned when a client connects from an address
+	// that isn't allowed by the IP access rules.
+	ErrAddressDenied = errors.New("address not allowed")
+	// ErrRateLimited is returned when a client exceeds a rate limit.
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrServerBusy is returned when there are too many git operations
+	// waiting for a worker.
+	ErrServerBusy = errors.New("server is busy, try again later")
+	// ErrMaintenance is returned when writing to the server or to a
+	// repository in maintenance mode.
+	ErrMaintenance = errors.New("under maintenance")
+	// ErrTimestampNotFound is returned when a tag has no timestamp.
+	ErrTimestampNotFound = errors.New("timestamp not found")
+	// ErrReleaseNotFound is returned when a release or a release asset is not
+	// found.
+	ErrReleaseNotFound = errors.New("release not found")
+	// ErrReleaseExist is returned when a tag already has a release.
+	ErrReleaseExist = errors.New("release already exists")
+	// ErrTagExist is returned when creating a tag that already exists.
+	ErrTagExist = errors.New("tag already exists")
+	// ErrBranchExist is returned when creating a branch that already exists.
+	ErrBranchExist = errors.New("branch already exists")
+	// ErrJobNotFound is returned when a job is not found, or isn't dead when
+	// retrying it.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrRepoLocked is returned when another server holds the lock of a
+	// repository for too long in HA mode.
+	ErrRepoLocked = errors.New("repository is locked, try again later")
+	// ErrQuotaExceeded is returned when a push or an upload would exceed
+	// the disk quota of a user or of a repository.
+	ErrQuotaExceeded = errors.New("disk quota exceeded")
+	// ErrReadOnly is returned when writing to a read-only replica.
+	ErrReadOnly = errors.New("read-only replica")
+	// ErrTransferNotFound is returned when a repository has no pending
+	// transfer to a user.
+	ErrTransferNotFound = errors.New("transfer not found")
+	// ErrNotTemplate is returned when creating a repository from a repository
+	// that isn't a template.
+	ErrNotTemplate = errors.New("repository is not a template")
+	// ErrArchived is returned when writing to an archived repository.
+	ErrArchived = errors.New("repository is archived")
+	// ErrRepoHookNotFound is returned when a repository has no hook script
+	// with a name.
+	ErrRepoHookNotFound = errors.New("hook not found")
 )
+
+// RateLimitError is returned when a client exceeds a rate limit. It matches
+// ErrRateLimited.
+type RateLimitError struct {
+	// RetryAfter is how long the client has to wait before retrying.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrRateLimited, e.RetryAfter)
+}
+
+// Is returns true if target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// ReplicaError is returned when writing to a read-only replica. It matches
+// ErrReadOnly.
+type ReplicaError struct {
+	// Primary is the URL of the primary server writes go to.
+	Primary string
+}
+
+// Error implements error.
+func (e *ReplicaError) Error() string {
+	return fmt.Sprintf("this server is a read-only replica, push to the primary at %s", e.Primary)
+}
+
+// Is returns true if target is ErrReadOnly.
+func (e *ReplicaError) Is(target error) bool {
+	return target == ErrReadOnly
+}
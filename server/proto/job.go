@@ -0,0 +1,48 @@
+package proto
+
+import (
+	"fmt"
+	"time"
+)
+
+// JobStatus is the status of a job of the queue.
+type JobStatus string
+
+const (
+	// JobPending is a job waiting for a worker, or for its next attempt.
+	JobPending JobStatus = "pending"
+	// JobRunning is a job being run by a worker.
+	JobRunning JobStatus = "running"
+	// JobDead is a job that failed its last attempt. Dead jobs are kept until
+	// they're retried.
+	JobDead JobStatus = "dead"
+)
+
+// ParseJobStatus parses a job status.
+func ParseJobStatus(s string) (JobStatus, error) {
+	switch st := JobStatus(s); st {
+	case JobPending, JobRunning, JobDead:
+		return st, nil
+	}
+
+	return "", fmt.Errorf("invalid job status: %q", s)
+}
+
+// Job is a job of the persistent job queue.
+type Job struct {
+	ID int64
+	// Kind selects the handler running the job, i.e. "webhook".
+	Kind string
+	// Payload is the JSON encoded input of the handler.
+	Payload string
+	// Repo is the repository the job is about, if any.
+	Repo        string
+	Status      JobStatus
+	Attempts    int
+	MaxAttempts int
+	// LastError is the error of the last failed attempt.
+	LastError string
+	// RunAt is when the job is due.
+	RunAt     time.Time
+	CreatedAt time.Time
+}
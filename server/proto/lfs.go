@@ -0,0 +1,23 @@
+package proto
+
+import "time"
+
+// LFSObject is a Git LFS object stored for a repository.
+type LFSObject struct {
+	Oid       string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// LFSStatus is the Git LFS storage usage of a repository.
+type LFSStatus struct {
+	// Objects is the number of stored objects.
+	Objects int
+	// Size is the total size of the stored objects in bytes.
+	Size int64
+	// Unreachable is the number of stored objects that aren't referenced by
+	// any ref.
+	Unreachable int
+	// UnreachableSize is the total size of the unreachable objects in bytes.
+	UnreachableSize int64
+}
@@ -0,0 +1,41 @@
+package proto
+
+import (
+	"fmt"
+	"time"
+)
+
+// Maintenance is the maintenance mode of the server or of a repository.
+// Pushes are rejected while it's on, fetches keep working.
+type Maintenance struct {
+	// Message is shown to the users, i.e. why and until when.
+	Message string `json:"message,omitempty"`
+	// Since is when the maintenance started.
+	Since time.Time `json:"since"`
+}
+
+// MaintenanceError is returned when writing to the server or to a repository
+// in maintenance mode. It matches ErrMaintenance.
+type MaintenanceError struct {
+	// Repo is the repository in maintenance mode, or empty when the whole
+	// server is.
+	Repo string
+	Maintenance
+}
+
+// Error implements error.
+func (e *MaintenanceError) Error() string {
+	msg := "the server is under maintenance, pushes are disabled"
+	if e.Repo != "" {
+		msg = fmt.Sprintf("repository %s is under maintenance, pushes are disabled", e.Repo)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is returns true if target is ErrMaintenance.
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrMaintenance
+}
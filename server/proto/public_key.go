@@ -0,0 +1,76 @@
+package proto
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// PublicKey is a public key of a user, with its label and when it was last
+// used.
+type PublicKey struct {
+	ID        int64
+	PublicKey ssh.PublicKey
+	// Label describes the key, it defaults to the comment of the key.
+	Label string
+	// Restriction limits what the key can do.
+	Restriction KeyRestriction
+	CreatedAt   time.Time
+	LastUsedAt  time.Time
+	// ExpiresAt is when the key stops authenticating, zero if it never
+	// expires.
+	ExpiresAt time.Time
+}
+
+// IsExpired returns whether the key is expired.
+func (k PublicKey) IsExpired() bool {
+	return !k.ExpiresAt.IsZero() && time.Now().After(k.ExpiresAt)
+}
+
+// PublicKeyOptions are options for adding a public key to a user.
+type PublicKeyOptions struct {
+	// Label describes the key.
+	Label string
+	// Restriction limits what the key can do.
+	Restriction KeyRestriction
+	// ExpiresAt is when the key stops authenticating, zero if it never
+	// expires.
+	ExpiresAt time.Time
+}
+
+// KeyRestriction limits what a public key can do, on top of the access of its
+// user.
+type KeyRestriction string
+
+const (
+	// KeyUnrestricted keys can do everything their user can.
+	KeyUnrestricted KeyRestriction = ""
+	// KeyGitOnly keys can only run git commands, they can't open the TUI or
+	// run the other commands.
+	KeyGitOnly KeyRestriction = "git-only"
+	// KeyReadOnly keys only have read-only access, and can't change the
+	// account of their user.
+	KeyReadOnly KeyRestriction = "read-only"
+)
+
+// String returns the name of the restriction, "none" for unrestricted keys.
+func (r KeyRestriction) String() string {
+	if r == KeyUnrestricted {
+		return "none"
+	}
+	return string(r)
+}
+
+// ParseKeyRestriction parses a key restriction, "none" or an empty string
+// for unrestricted keys.
+func ParseKeyRestriction(s string) (KeyRestriction, error) {
+	switch r := KeyRestriction(s); r {
+	case KeyGitOnly, KeyReadOnly:
+		return r, nil
+	case "none", KeyUnrestricted:
+		return KeyUnrestricted, nil
+	default:
+		return KeyUnrestricted, fmt.Errorf("invalid key restriction %q, must be git-only, read-only, or none", s)
+	}
+}
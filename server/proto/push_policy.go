@@ -0,0 +1,66 @@
+package proto
+
+import (
+	"path"
+	"strings"
+)
+
+// PushPolicy is the set of limits enforced when pushing to a repository.
+type PushPolicy struct {
+	// MaxBlobSize is the maximum size of a single file in bytes. Zero means
+	// no limit.
+	MaxBlobSize int64
+	// MaxPushSize is the maximum total size of a push in bytes. Zero means no
+	// limit.
+	MaxPushSize int64
+	// BannedExtensions is the list of file extensions that can only be pushed
+	// using Git LFS.
+	BannedExtensions []string
+}
+
+// IsZero returns whether the policy doesn't enforce any limit.
+func (p PushPolicy) IsZero() bool {
+	return p.MaxBlobSize <= 0 && p.MaxPushSize <= 0 && len(p.BannedExtensions) == 0
+}
+
+// BannedExtension returns the banned extension matching the file at fp,
+// if any.
+func (p PushPolicy) BannedExtension(fp string) (string, bool) {
+	name := strings.ToLower(path.Base(fp))
+	for _, ext := range p.BannedExtensions {
+		if strings.HasSuffix(name, ext) {
+			return ext, true
+		}
+	}
+	return "", false
+}
+
+// PushPolicyOptions are the options to override the push policy of a
+// repository. Nil fields inherit the server configuration.
+type PushPolicyOptions struct {
+	MaxBlobSize      *int64
+	MaxPushSize      *int64
+	BannedExtensions *[]string
+}
+
+// NormalizeExtensions returns the list of extensions lower-cased, prefixed
+// with a dot, and without duplicates or empty values.
+func NormalizeExtensions(exts []string) []string {
+	seen := map[string]struct{}{}
+	normalized := make([]string, 0, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" || ext == "." {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if _, ok := seen[ext]; ok {
+			continue
+		}
+		seen[ext] = struct{}{}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
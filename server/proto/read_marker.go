@@ -0,0 +1,22 @@
+package proto
+
+import "time"
+
+// ReadMarkers keeps track of the commits a user has seen in a repository.
+type ReadMarkers struct {
+	// ReadAt is the last time the user marked the repository as read.
+	// Commits older than this are considered read.
+	ReadAt time.Time
+	// Commits are the commits the user has seen since ReadAt.
+	Commits map[string]struct{}
+}
+
+// IsUnread returns whether the commit with the given hash and commit time
+// hasn't been seen yet.
+func (m ReadMarkers) IsUnread(hash string, when time.Time) bool {
+	if m.ReadAt.IsZero() || !when.After(m.ReadAt) {
+		return false
+	}
+	_, ok := m.Commits[hash]
+	return !ok
+}
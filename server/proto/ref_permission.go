@@ -0,0 +1,35 @@
+package proto
+
+import (
+	"path"
+	"strings"
+)
+
+// RefPermission restricts pushes to the references matching a pattern to a
+// set of users.
+type RefPermission struct {
+	// Pattern is a full reference name or a glob that matches full reference
+	// names, i.e. "refs/heads/main" or "refs/heads/release/*".
+	Pattern string
+	// Users are the usernames allowed to push to matching references.
+	Users []string
+}
+
+// Matches returns true if the permission applies to the given full reference
+// name.
+func (p RefPermission) Matches(ref string) bool {
+	if p.Pattern == ref {
+		return true
+	}
+	ok, err := path.Match(p.Pattern, ref)
+	return err == nil && ok
+}
+
+// RefPattern returns the full reference pattern of pattern. Patterns that
+// don't start with "refs/" match branches.
+func RefPattern(pattern string) string {
+	if strings.HasPrefix(pattern, "refs/") {
+		return pattern
+	}
+	return "refs/heads/" + pattern
+}
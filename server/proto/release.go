@@ -0,0 +1,34 @@
+package proto
+
+import "time"
+
+// Release is a release of a repository, a tag with notes and assets.
+type Release struct {
+	ID    int64
+	Tag   string
+	Name  string
+	Notes string
+	// Username is the user who created the release, if any.
+	Username  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Assets    []ReleaseAsset
+}
+
+// Title returns the name of the release, or its tag if it has no name.
+func (r Release) Title() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Tag
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	Name string
+	Size int64
+	// SHA256 is the hex encoded SHA-256 checksum of the file.
+	SHA256    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
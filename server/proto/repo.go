@@ -18,10 +18,18 @@ type Repository interface {
 	Description() string
 	// IsPrivate returns whether the repository is private.
 	IsPrivate() bool
+	// IsInternal returns whether the repository is internal, only
+	// authenticated users can see it.
+	IsInternal() bool
 	// IsMirror returns whether the repository is a mirror.
 	IsMirror() bool
 	// IsHidden returns whether the repository is hidden.
 	IsHidden() bool
+	// IsTemplate returns whether new repositories can be created from the
+	// repository.
+	IsTemplate() bool
+	// IsArchived returns whether the repository is archived, i.e. read-only.
+	IsArchived() bool
 	// UserID returns the ID of the user who owns the repository.
 	// It returns 0 if the repository is not owned by a user.
 	UserID() int64
@@ -41,4 +49,18 @@ type RepositoryOptions struct {
 	Hidden      bool
 	LFS         bool
 	LFSEndpoint string
+	// Readme, Gitignore, and License are the files of the initial commit of
+	// the repository: a README, the name of a .gitignore template, and the
+	// SPDX identifier of a license.
+	Readme    bool
+	Gitignore string
+	License   string
+}
+
+// RepoMetadata is the metadata of a repository read from git.
+type RepoMetadata struct {
+	// UpdatedAt is the time of the last update of the repository.
+	UpdatedAt time.Time `json:"updated_at"`
+	// DefaultBranch is the default branch, empty for empty repositories.
+	DefaultBranch string `json:"default_branch,omitempty"`
 }
@@ -0,0 +1,26 @@
+package proto
+
+import "time"
+
+// RepoHook is a hook script installed in a repository. It runs on pushes,
+// after the hooks of Soft Serve with the same name.
+type RepoHook struct {
+	// Name is the name of the git hook, i.e. pre-receive.
+	Name      string
+	Size      int64
+	UpdatedAt time.Time
+}
+
+// RepoHookRun is a logged run of the hook script of a repository.
+type RepoHookRun struct {
+	Hook string
+	// Username is the user who pushed, empty for anonymous pushes.
+	Username string
+	// ExitCode is the exit status of the script, -1 if it was killed.
+	ExitCode int
+	Duration time.Duration
+	// Output is the combined stdout and stderr of the script, truncated to
+	// the max output of the configuration.
+	Output    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,18 @@
+package proto
+
+import "time"
+
+// RepoTransfer is a pending transfer of a repository to a new owner, who has
+// to accept it.
+type RepoTransfer struct {
+	// Repo is the name of the repository.
+	Repo string
+	// From is the username of the current owner, empty if the repository has
+	// no owner.
+	From string
+	// To is the username of the new owner.
+	To string
+	// RequestedBy is the username of the user who requested the transfer.
+	RequestedBy string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,13 @@
+package proto
+
+// SessionState is the UI state of a TUI session. It's saved per user so that
+// reconnecting resumes roughly where the user left off.
+type SessionState struct {
+	// Repo is the name of the selected repository. An empty string means the
+	// user was on the selection page.
+	Repo string `json:"repo,omitempty"`
+	// Tab is the name of the active repository tab.
+	Tab string `json:"tab,omitempty"`
+	// Position is the index of the selected item in the active tab.
+	Position int `json:"position,omitempty"`
+}
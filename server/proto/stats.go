@@ -0,0 +1,21 @@
+package proto
+
+// ServerStats are the statistics of the server reported by admin info.
+type ServerStats struct {
+	// Repos is the number of repositories, of which Mirrors are mirrors,
+	// Private are private, and Archived are archived.
+	Repos    int
+	Mirrors  int
+	Private  int
+	Archived int
+	// Users is the number of users.
+	Users int
+	// DiskUsage is the disk usage of all the repositories, as of their last
+	// computation.
+	DiskUsage DiskUsage
+	// JobsPending, JobsRunning, and JobsDead are the number of jobs of the
+	// queue by status.
+	JobsPending int
+	JobsRunning int
+	JobsDead    int
+}
@@ -0,0 +1,100 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tab is a tab of the repository page of the TUI.
+type Tab string
+
+const (
+	// TabReadme shows the repository readme.
+	TabReadme Tab = "readme"
+	// TabFiles shows the repository files.
+	TabFiles Tab = "files"
+	// TabCommits shows the repository commits.
+	TabCommits Tab = "commits"
+	// TabBranches shows the repository branches.
+	TabBranches Tab = "branches"
+	// TabTags shows the repository tags.
+	TabTags Tab = "tags"
+	// TabReleases shows the repository releases.
+	TabReleases Tab = "releases"
+	// TabInsights shows the repository traffic.
+	TabInsights Tab = "insights"
+	// TabSettings shows the repository settings. It can't be hidden.
+	TabSettings Tab = "settings"
+)
+
+// Tabs are the tabs of the repository page in the order they're displayed.
+var Tabs = []Tab{
+	TabReadme,
+	TabFiles,
+	TabCommits,
+	TabBranches,
+	TabTags,
+	TabReleases,
+	TabInsights,
+	TabSettings,
+}
+
+// ParseTab parses a repository tab name.
+func ParseTab(s string) (Tab, error) {
+	t := Tab(strings.ToLower(strings.TrimSpace(s)))
+	for _, tab := range Tabs {
+		if t == tab {
+			return t, nil
+		}
+	}
+
+	names := make([]string, len(Tabs))
+	for i, tab := range Tabs {
+		names[i] = string(tab)
+	}
+
+	return "", fmt.Errorf("invalid tab %q: must be one of %s", s, strings.Join(names, ", "))
+}
+
+// String returns the string representation of the tab.
+func (t Tab) String() string {
+	return string(t)
+}
+
+// TabLayout is which tabs of a repository are shown and which one is
+// opened first.
+type TabLayout struct {
+	// Default is the tab opened first. It's the first shown tab when empty.
+	Default Tab
+	// Hidden are the tabs that aren't shown.
+	Hidden []Tab
+}
+
+// IsHidden returns whether the tab is hidden.
+func (l TabLayout) IsHidden(t Tab) bool {
+	for _, h := range l.Hidden {
+		if h == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Shown returns the shown tabs in the order they're displayed.
+func (l TabLayout) Shown() []Tab {
+	tabs := make([]Tab, 0, len(Tabs))
+	for _, t := range Tabs {
+		if !l.IsHidden(t) {
+			tabs = append(tabs, t)
+		}
+	}
+	return tabs
+}
+
+// DefaultTab returns the tab opened first.
+func (l TabLayout) DefaultTab() Tab {
+	if l.Default != "" && !l.IsHidden(l.Default) {
+		return l.Default
+	}
+	return l.Shown()[0]
+}
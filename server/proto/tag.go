@@ -0,0 +1,10 @@
+package proto
+
+// TagOptions are options for creating a tag.
+type TagOptions struct {
+	// Message is the message of an annotated tag. Without a message, the tag
+	// is lightweight unless it's signed.
+	Message string
+	// Sign is whether the tag is signed with the SSH key of the server.
+	Sign bool
+}
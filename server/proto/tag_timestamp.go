@@ -0,0 +1,15 @@
+package proto
+
+import (
+	"math/big"
+	"time"
+)
+
+// TagTimestamp is a verified RFC 3161 timestamp of a tag.
+type TagTimestamp struct {
+	Tag          string
+	ObjectID     string
+	Time         time.Time
+	Authority    string
+	SerialNumber *big.Int
+}
@@ -0,0 +1,85 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// DateStyle is how dates are displayed.
+type DateStyle string
+
+const (
+	// DateStyleDefault uses the default style of each view.
+	DateStyleDefault DateStyle = ""
+	// DateStyleRelative displays dates relative to now, e.g. "3 days ago".
+	DateStyleRelative DateStyle = "relative"
+	// DateStyleAbsolute displays absolute dates.
+	DateStyleAbsolute DateStyle = "absolute"
+)
+
+// ParseDateStyle parses a date style. "default" and an empty string both
+// return DateStyleDefault.
+func ParseDateStyle(s string) (DateStyle, error) {
+	switch ds := DateStyle(strings.ToLower(strings.TrimSpace(s))); ds {
+	case DateStyleRelative, DateStyleAbsolute:
+		return ds, nil
+	case DateStyleDefault, "default":
+		return DateStyleDefault, nil
+	default:
+		return "", fmt.Errorf("invalid date format %q: must be one of default, relative, or absolute", s)
+	}
+}
+
+// String returns the string representation of the date style.
+func (s DateStyle) String() string {
+	if s == DateStyleDefault {
+		return "default"
+	}
+	return string(s)
+}
+
+// TimeFormat is a user's date and time formatting preferences.
+type TimeFormat struct {
+	// Style is the date style.
+	Style DateStyle
+	// Location is the time zone dates are displayed in. A nil Location keeps
+	// the time zone of each date.
+	Location *time.Location
+}
+
+// IsRelative returns whether dates are displayed relative to now. def is the
+// default of the view.
+func (f TimeFormat) IsRelative(def bool) bool {
+	switch f.Style {
+	case DateStyleRelative:
+		return true
+	case DateStyleAbsolute:
+		return false
+	default:
+		return def
+	}
+}
+
+// Absolute formats t using layout unless the user prefers relative dates.
+func (f TimeFormat) Absolute(t time.Time, layout string) string {
+	return f.format(t, layout, false)
+}
+
+// Relative formats t relative to now unless the user prefers absolute dates,
+// in which case layout is used.
+func (f TimeFormat) Relative(t time.Time, layout string) string {
+	return f.format(t, layout, true)
+}
+
+func (f TimeFormat) format(t time.Time, layout string, relative bool) string {
+	if f.IsRelative(relative) {
+		return humanize.Time(t)
+	}
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+	return t.Format(layout)
+}
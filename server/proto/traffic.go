@@ -0,0 +1,28 @@
+package proto
+
+import "time"
+
+// TrafficDay is the Git traffic of a repository on a day.
+type TrafficDay struct {
+	// Day is the start of the day in UTC.
+	Day     time.Time
+	Clones  int64
+	Fetches int64
+	Pushes  int64
+	// Clients is the approximate number of distinct users and anonymous
+	// addresses that cloned, fetched, or pushed.
+	Clients int64
+}
+
+// Traffic is the Git traffic of a repository over a number of days.
+type Traffic struct {
+	// Days are the days of the period, oldest first, including the days
+	// without traffic.
+	Days    []TrafficDay
+	Clones  int64
+	Fetches int64
+	Pushes  int64
+	// Clients is the approximate number of distinct clients over the whole
+	// period.
+	Clients int64
+}
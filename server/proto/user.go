@@ -10,6 +10,8 @@ type User interface {
 	Username() string
 	// IsAdmin returns whether the user is an admin.
 	IsAdmin() bool
+	// IsSuspended returns whether the user is suspended.
+	IsSuspended() bool
 	// PublicKeys returns the user's public keys.
 	PublicKeys() []ssh.PublicKey
 	// Password returns the user's password hash.
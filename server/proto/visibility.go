@@ -0,0 +1,46 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Visibility is who can see a repository.
+type Visibility string
+
+const (
+	// VisibilityPublic repositories can be seen by anyone, including
+	// anonymous users.
+	VisibilityPublic Visibility = "public"
+	// VisibilityInternal repositories can be seen by any authenticated user.
+	VisibilityInternal Visibility = "internal"
+	// VisibilityPrivate repositories can only be seen by their collaborators.
+	VisibilityPrivate Visibility = "private"
+)
+
+// ParseVisibility parses a repository visibility.
+func ParseVisibility(s string) (Visibility, error) {
+	switch v := Visibility(strings.ToLower(strings.TrimSpace(s))); v {
+	case VisibilityPublic, VisibilityInternal, VisibilityPrivate:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid visibility %q: must be one of public, internal, or private", s)
+	}
+}
+
+// String returns the string representation of the visibility.
+func (v Visibility) String() string {
+	return string(v)
+}
+
+// RepositoryVisibility returns the visibility of a repository.
+func RepositoryVisibility(r Repository) Visibility {
+	switch {
+	case r.IsPrivate():
+		return VisibilityPrivate
+	case r.IsInternal():
+		return VisibilityInternal
+	default:
+		return VisibilityPublic
+	}
+}
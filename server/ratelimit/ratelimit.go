@@ -0,0 +1,132 @@
+// Package ratelimit implements token bucket rate limiting of keys, like users
+// or addresses.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the buckets that are full again are dropped.
+const sweepInterval = time.Minute
+
+// Limit is the rate of a token bucket. Buckets hold up to Burst tokens, and
+// are refilled at PerMinute tokens per minute.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// IsZero returns true if the limit doesn't limit anything.
+func (l Limit) IsZero() bool {
+	return l.PerMinute <= 0
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter rate limits keys with a token bucket each. The zero limit allows
+// everything.
+type Limiter struct {
+	limit Limit
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// New returns a new limiter of keys to limit.
+func New(limit Limit) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of key. When the bucket is empty, it
+// returns false and how long to wait until a token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || l.limit.IsZero() {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.swept) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit.burst(), last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = l.refill(b, now)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, l.wait(b.tokens)
+}
+
+// Wait returns how long to wait until a token is available in the bucket of
+// key, without taking it. It returns 0 when a token is available.
+func (l *Limiter) Wait(key string) time.Duration {
+	if l == nil || l.limit.IsZero() {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		return 0
+	}
+
+	if tokens := l.refill(b, l.now()); tokens < 1 {
+		return l.wait(tokens)
+	}
+	return 0
+}
+
+// wait returns how long a bucket with tokens takes to refill a token.
+func (l *Limiter) wait(tokens float64) time.Duration {
+	perToken := time.Minute / time.Duration(l.limit.PerMinute)
+	return time.Duration((1 - tokens) * float64(perToken))
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.last).Minutes()*float64(l.limit.PerMinute)
+	if max := l.limit.burst(); tokens > max {
+		tokens = max
+	}
+	return tokens
+}
+
+// sweep drops the buckets that are full, they're the same as new buckets.
+func (l *Limiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		if l.refill(b, now) >= l.limit.burst() {
+			delete(l.buckets, k)
+		}
+	}
+	l.swept = now
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New(Limit{PerMinute: 60, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d should be allowed by the burst", i)
+		}
+	}
+
+	ok, wait := l.Allow("a")
+	if ok {
+		t.Fatal("request should be limited")
+	}
+	if wait != time.Second {
+		t.Errorf("expected to wait 1s, got %s", wait)
+	}
+	if wait := l.Wait("a"); wait != time.Second {
+		t.Errorf("expected to wait 1s without taking a token, got %s", wait)
+	}
+	if wait := l.Wait("c"); wait != 0 {
+		t.Errorf("expected new keys not to wait, got %s", wait)
+	}
+
+	// Keys have their own bucket.
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("other keys should be allowed")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, wait := l.Allow("a"); ok || wait != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms, got %t %s", ok, wait)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("request should be allowed after a token is refilled")
+	}
+
+	// Buckets don't refill past the burst.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d should be allowed by the burst", i)
+		}
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("request should be limited")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New(Limit{PerMinute: 1, Burst: 1})
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	now = now.Add(2 * time.Minute)
+	l.Allow("b")
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("full buckets should be dropped")
+	}
+	if _, ok := l.buckets["b"]; !ok {
+		t.Error("buckets in use should be kept")
+	}
+}
+
+func TestLimiterZero(t *testing.T) {
+	var nilLimiter *Limiter
+	for _, l := range []*Limiter{New(Limit{}), nilLimiter} {
+		for i := 0; i < 100; i++ {
+			if ok, _ := l.Allow("a"); !ok {
+				t.Fatal("the zero limit should allow everything")
+			}
+		}
+	}
+}
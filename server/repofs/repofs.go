@@ -0,0 +1,410 @@
+// Package repofs implements a read-only file system of the trees of a
+// repository. The top-level directories are the repository branches and
+// tags, branch and tag names with slashes are nested directories. A commit
+// hash or HEAD can be used to browse any other revision.
+package repofs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+// FS is a read-only fs.FS of the trees of a repository.
+type FS struct {
+	repo *git.Repository
+	// refs maps branch and tag names to their full reference names.
+	refs map[string]string
+
+	mu    sync.Mutex
+	infos map[string]*fileInfo
+	times map[string]time.Time
+}
+
+var (
+	_ fs.StatFS    = (*FS)(nil)
+	_ fs.ReadDirFS = (*FS)(nil)
+)
+
+// New returns a new file system of the trees of r. The branches and tags are
+// read once, the file system doesn't see references created after it.
+func New(r *git.Repository) *FS {
+	fsys := &FS{
+		repo:  r,
+		refs:  make(map[string]string),
+		infos: make(map[string]*fileInfo),
+		times: make(map[string]time.Time),
+	}
+
+	// Repositories without commits have no references.
+	refs, _ := r.References()
+	for _, ref := range refs {
+		switch {
+		case ref.IsBranch():
+			fsys.refs[strings.TrimPrefix(ref.Name().String(), git.RefsHeads)] = ref.Name().String()
+		case ref.IsTag():
+			// Branches win over tags with the same name.
+			name := strings.TrimPrefix(ref.Name().String(), git.RefsTags)
+			if _, ok := fsys.refs[name]; !ok {
+				fsys.refs[name] = ref.Name().String()
+			}
+		}
+	}
+
+	return fsys
+}
+
+// Revision returns the revision name belongs to and the path within the
+// revision tree. The longest matching branch or tag name wins.
+func (f *FS) Revision(name string) (rev string, p string, ok bool) {
+	if name == "." {
+		return "", "", false
+	}
+
+	segs := strings.Split(name, "/")
+	for i := len(segs); i > 0; i-- {
+		if rev, ok := f.refs[strings.Join(segs[:i], "/")]; ok {
+			return rev, strings.Join(segs[i:], "/"), true
+		}
+	}
+
+	if len(segs[0]) >= 4 {
+		if rev, err := f.repo.RevParse(segs[0]); err == nil {
+			return rev, strings.Join(segs[1:], "/"), true
+		}
+	}
+
+	return "", "", false
+}
+
+// Children returns the sorted names of the next path segments of the names
+// under dir. A dir of "." returns the top-level segments.
+func Children(names []string, dir string) []string {
+	seen := make(map[string]bool)
+	var children []string
+	for _, name := range names {
+		rest := name
+		if dir != "." {
+			if !strings.HasPrefix(name, dir+"/") {
+				continue
+			}
+			rest = strings.TrimPrefix(name, dir+"/")
+		}
+
+		child := strings.SplitN(rest, "/", 2)[0]
+		if !seen[child] {
+			seen[child] = true
+			children = append(children, child)
+		}
+	}
+
+	sort.Strings(children)
+	return children
+}
+
+// References returns the sorted names of the branches and tags.
+func (f *FS) References() []string {
+	names := make([]string, 0, len(f.refs))
+	for name := range f.refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *FS) modTime(rev string) time.Time {
+	if t, ok := f.times[rev]; ok {
+		return t
+	}
+
+	var t time.Time
+	if c, err := f.repo.CatFileCommit(rev + "^{commit}"); err == nil {
+		t = c.Committer.When
+	}
+
+	f.times[rev] = t
+	return t
+}
+
+func (f *FS) stat(name string) (*fileInfo, error) {
+	if fi, ok := f.infos[name]; ok {
+		return fi, nil
+	}
+
+	fi := &fileInfo{name: path.Base(name), dir: true}
+	if name == "." {
+		fi.name = "/"
+	}
+
+	if rev, p, ok := f.Revision(name); ok {
+		fi.rev = rev
+		fi.path = p
+		fi.modTime = f.modTime(rev)
+		if p != "" {
+			tree, err := f.repo.LsTree(rev)
+			if err != nil {
+				return nil, err
+			}
+
+			entry, err := tree.TreeEntry(p)
+			if err != nil {
+				return nil, fs.ErrNotExist
+			}
+
+			fi.setEntry(entry)
+		}
+	} else if name != "." && len(Children(f.References(), name)) == 0 {
+		return nil, fs.ErrNotExist
+	}
+
+	f.infos[name] = fi
+	return fi, nil
+}
+
+func (f *FS) readDir(name string, fi *fileInfo) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	if fi.rev == "" {
+		for _, child := range Children(f.References(), name) {
+			cfi, err := f.stat(path.Join(name, child))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, fs.FileInfoToDirEntry(cfi))
+		}
+
+		return entries, nil
+	}
+
+	tree, err := f.repo.LsTree(fi.rev)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.path != "" {
+		tree, err = tree.SubTree(fi.path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ents, err := tree.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	ents.Sort()
+	for _, e := range ents {
+		cfi := &fileInfo{
+			name:    e.Name(),
+			rev:     fi.rev,
+			path:    path.Join(fi.path, e.Name()),
+			modTime: fi.modTime,
+		}
+		cfi.setEntry(e)
+		f.infos[path.Join(name, e.Name())] = cfi
+		entries = append(entries, fs.FileInfoToDirEntry(cfi))
+	}
+
+	return entries, nil
+}
+
+// Open implements fs.FS. Files implement io.Seeker and io.ReaderAt.
+func (f *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, err := f.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	if fi.IsDir() {
+		return &dir{fsys: f, name: name, info: fi}, nil
+	}
+
+	content, err := fi.entry.Contents()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &file{Reader: bytes.NewReader(content), info: fi}, nil
+}
+
+// Stat implements fs.StatFS. The Sys method of the returned fs.FileInfo
+// returns the *git.TreeEntry of the file, or nil for branch and tag
+// directories.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, err := f.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	return fi, nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, err := f.stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	if !fi.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return f.readDir(name, fi)
+}
+
+// fileInfo describes a branch or tag directory, or a tree entry.
+type fileInfo struct {
+	name    string
+	dir     bool
+	exec    bool
+	size    int64
+	modTime time.Time
+	rev     string
+	path    string
+	entry   *git.TreeEntry
+}
+
+var _ fs.FileInfo = (*fileInfo)(nil)
+
+func (fi *fileInfo) setEntry(e *git.TreeEntry) {
+	fi.entry = e
+	// Submodules are shown as empty directories.
+	fi.dir = e.IsTree() || e.IsCommit()
+	fi.exec = e.IsExec()
+	if !fi.dir {
+		fi.size = e.Size()
+	}
+}
+
+// Name implements fs.FileInfo.
+func (fi *fileInfo) Name() string { return fi.name }
+
+// Size implements fs.FileInfo.
+func (fi *fileInfo) Size() int64 { return fi.size }
+
+// Mode implements fs.FileInfo.
+func (fi *fileInfo) Mode() fs.FileMode {
+	switch {
+	case fi.dir:
+		return fs.ModeDir | 0o555
+	case fi.exec:
+		return 0o555
+	default:
+		return 0o444
+	}
+}
+
+// ModTime implements fs.FileInfo. It's the commit time of the revision.
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+
+// IsDir implements fs.FileInfo.
+func (fi *fileInfo) IsDir() bool { return fi.dir }
+
+// Sys implements fs.FileInfo.
+func (fi *fileInfo) Sys() any {
+	if fi.entry == nil {
+		return nil
+	}
+	return fi.entry
+}
+
+// file is a blob of a tree.
+type file struct {
+	*bytes.Reader
+	info *fileInfo
+}
+
+var (
+	_ fs.File     = (*file)(nil)
+	_ io.Seeker   = (*file)(nil)
+	_ io.ReaderAt = (*file)(nil)
+)
+
+// Close implements fs.File.
+func (f *file) Close() error { return nil }
+
+// Stat implements fs.File.
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+// dir is a tree, or a branch and tag directory.
+type dir struct {
+	fsys    *FS
+	name    string
+	info    *fileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+var _ fs.ReadDirFile = (*dir)(nil)
+
+// Close implements fs.File.
+func (d *dir) Close() error { return nil }
+
+// Read implements fs.File.
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// Stat implements fs.File.
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+// ReadDir implements fs.ReadDirFile.
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		d.fsys.mu.Lock()
+		entries, err := d.fsys.readDir(d.name, d.info)
+		d.fsys.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"sync"
 
 	"github.com/charmbracelet/log"
 
@@ -14,8 +16,10 @@ import (
 	"github.com/charmbracelet/soft-serve/server/daemon"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/jobs"
+	logr "github.com/charmbracelet/soft-serve/server/log"
 	sshsrv "github.com/charmbracelet/soft-serve/server/ssh"
 	"github.com/charmbracelet/soft-serve/server/stats"
+	"github.com/charmbracelet/soft-serve/server/tracing"
 	"github.com/charmbracelet/soft-serve/server/web"
 	"github.com/charmbracelet/ssh"
 	"golang.org/x/sync/errgroup"
@@ -32,8 +36,17 @@ type Server struct {
 	Backend     *backend.Backend
 	DB          *db.DB
 
-	logger *log.Logger
-	ctx    context.Context
+	logger          *log.Logger
+	ctx             context.Context
+	shutdownTracing func(context.Context) error
+
+	// workersCtx is the context of the background workers of the backend,
+	// like the job workers and the webhook deliveries. It's canceled when the
+	// server shuts down, and the workers are waited for before the database
+	// is closed.
+	workersCtx    context.Context
+	cancelWorkers context.CancelFunc
+	workers       sync.WaitGroup
 }
 
 // NewServer returns a new *Server configured to serve Soft Serve. The SSH
@@ -45,19 +58,25 @@ func NewServer(ctx context.Context) (*Server, error) {
 	cfg := config.FromContext(ctx)
 	be := backend.FromContext(ctx)
 	db := db.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("server")
+	logger := logr.WithTrackedPrefix(log.FromContext(ctx), "server")
 	srv := &Server{
 		Config:  cfg,
 		Backend: be,
 		DB:      db,
-		logger:  log.FromContext(ctx).WithPrefix("server"),
+		logger:  logger,
 		ctx:     ctx,
 	}
+	srv.workersCtx, srv.cancelWorkers = context.WithCancel(ctx)
+
+	srv.shutdownTracing, err = tracing.Init(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("init tracing: %w", err)
+	}
 
 	// Add cron jobs.
 	sched := cron.NewScheduler(ctx)
 	for n, j := range jobs.List() {
-		id, err := sched.AddFunc(j.Spec, j.Func(ctx))
+		id, err := sched.AddTask(n, j.Spec, stats.InstrumentJob(n, j.Func(ctx)))
 		if err != nil {
 			logger.Warn("error adding cron job", "job", n, "err", err)
 		}
@@ -66,6 +85,10 @@ func NewServer(ctx context.Context) (*Server, error) {
 	}
 
 	srv.Cron = sched
+	// Replicas don't run the scheduled tasks.
+	if !cfg.Replica.Enabled {
+		be.SetScheduler(sched)
+	}
 
 	srv.SSHServer, err = sshsrv.NewSSHServer(ctx)
 	if err != nil {
@@ -94,42 +117,81 @@ func NewServer(ctx context.Context) (*Server, error) {
 func (s *Server) Start() error {
 	errg, _ := errgroup.WithContext(s.ctx)
 	errg.Go(func() error {
-		s.logger.Print("Starting Git daemon", "addr", s.Config.Git.ListenAddr)
+		s.logger.Print("Starting Git daemon", "addr", s.GitDaemon.Addr())
 		if err := s.GitDaemon.Start(); !errors.Is(err, daemon.ErrServerClosed) {
 			return err
 		}
 		return nil
 	})
 	errg.Go(func() error {
-		s.logger.Print("Starting HTTP server", "addr", s.Config.HTTP.ListenAddr)
+		s.logger.Print("Starting HTTP server", "addr", s.HTTPServer.Addr())
 		if err := s.HTTPServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 			return err
 		}
 		return nil
 	})
 	errg.Go(func() error {
-		s.logger.Print("Starting SSH server", "addr", s.Config.SSH.ListenAddr)
+		s.logger.Print("Starting SSH server", "addr", s.SSHServer.Addr())
 		if err := s.SSHServer.ListenAndServe(); !errors.Is(err, ssh.ErrServerClosed) {
 			return err
 		}
 		return nil
 	})
 	errg.Go(func() error {
-		s.logger.Print("Starting Stats server", "addr", s.Config.Stats.ListenAddr)
+		s.logger.Print("Starting Stats server", "addr", s.StatsServer.Addr())
 		if err := s.StatsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 			return err
 		}
 		return nil
 	})
-	errg.Go(func() error {
-		s.Cron.Start()
-		return nil
-	})
+	if s.Config.Replica.Enabled {
+		// The primary runs the cron and the queued jobs.
+		s.startWorker(s.Backend.RunReplica)
+	} else {
+		errg.Go(func() error {
+			s.Cron.Start()
+			return nil
+		})
+		s.startWorker(s.Backend.DeliverWebhooks)
+		s.startWorker(s.Backend.RunJobs)
+	}
+	s.startWorker(s.Backend.RelayEvents)
 	return errg.Wait()
 }
 
-// Shutdown lets the server gracefully shutdown.
+// startWorker runs a background worker until the server shuts down.
+func (s *Server) startWorker(run func(context.Context)) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		run(s.workersCtx)
+	}()
+}
+
+// stopWorkers stops the background workers and waits for them.
+func (s *Server) stopWorkers() {
+	s.cancelWorkers()
+	s.workers.Wait()
+}
+
+// Shutdown lets the server gracefully shutdown. The servers stop accepting
+// connections, and the in-flight git transfers and TUI sessions are given
+// until ctx is done to finish, the remaining connections are closed then.
 func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.logger.Warn("grace period is over, closing the remaining connections")
+		// The listeners are closed already.
+		if err := s.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			return err
+		}
+		return nil
+	}
+
+	return err
+}
+
+func (s *Server) shutdown(ctx context.Context) error {
 	errg, ctx := errgroup.WithContext(ctx)
 	errg.Go(func() error {
 		return s.GitDaemon.Shutdown(ctx)
@@ -150,8 +212,14 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.Cron.Shutdown()
 		return nil
 	})
-	// defer s.DB.Close() // nolint: errcheck
-	return errg.Wait()
+	errg.Go(func() error {
+		return s.shutdownTracing(ctx)
+	})
+	err := errg.Wait()
+
+	// The workers use the database, they're done before it's closed.
+	s.stopWorkers()
+	return err
 }
 
 // Close closes the SSH server.
@@ -165,6 +233,7 @@ func (s *Server) Close() error {
 		s.Cron.Stop()
 		return nil
 	})
-	// defer s.DB.Close() // nolint: errcheck
-	return errg.Wait()
+	err := errg.Wait()
+	s.stopWorkers()
+	return err
 }
@@ -0,0 +1,27 @@
+package sessions
+
+import "context"
+
+// ContextKey is the key for the session registry in the context.
+var ContextKey = &struct{ string }{"sessions"}
+
+// FromContext returns the session registry from a context.
+func FromContext(ctx context.Context) *Registry {
+	if r, ok := ctx.Value(ContextKey).(*Registry); ok {
+		return r
+	}
+
+	return nil
+}
+
+// SessionContextKey is the key for the current session in the context.
+var SessionContextKey = &struct{ string }{"sessions-session"}
+
+// SessionFromContext returns the current session from a context.
+func SessionFromContext(ctx context.Context) *Session {
+	if s, ok := ctx.Value(SessionContextKey).(*Session); ok {
+		return s
+	}
+
+	return nil
+}
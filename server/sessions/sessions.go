@@ -0,0 +1,134 @@
+// Package sessions keeps track of the active SSH sessions, so that admins can
+// see who is connected and terminate their sessions.
+package sessions
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// Info describes an active session.
+type Info struct {
+	// ID is the short ID of the SSH connection, the sessions of a
+	// connection share it.
+	ID string `json:"id"`
+	// Username is the user of the session, empty for anonymous users.
+	Username string `json:"username,omitempty"`
+	// RemoteAddr is the source address of the connection.
+	RemoteAddr string `json:"remote_addr"`
+	// Type is the type of the session, "tui", "command", or the subsystem.
+	Type string `json:"type"`
+	// Command is the name of the command of a command session.
+	Command string `json:"command,omitempty"`
+	// Location is what the TUI of the session is showing.
+	Location proto.SessionState `json:"location"`
+	// StartedAt is when the session started.
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Registry keeps track of the active sessions.
+type Registry struct {
+	mu       sync.Mutex
+	sessions map[*Session]struct{}
+}
+
+// NewRegistry returns a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		sessions: make(map[*Session]struct{}),
+	}
+}
+
+// Add registers an active session. kill terminates the connection of the
+// session.
+func (r *Registry) Add(info Info, kill func() error) *Session {
+	if info.StartedAt.IsZero() {
+		info.StartedAt = time.Now()
+	}
+	s := &Session{
+		registry: r,
+		info:     info,
+		kill:     kill,
+	}
+	r.mu.Lock()
+	r.sessions[s] = struct{}{}
+	r.mu.Unlock()
+	return s
+}
+
+// List returns the active sessions, the oldest first.
+func (r *Registry) List() []Info {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	infos := make([]Info, 0, len(r.sessions))
+	for s := range r.sessions {
+		infos = append(infos, s.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].StartedAt.Equal(infos[j].StartedAt) {
+			return infos[i].ID < infos[j].ID
+		}
+		return infos[i].StartedAt.Before(infos[j].StartedAt)
+	})
+	return infos
+}
+
+// Users returns the number of distinct users with active sessions. Anonymous
+// sessions count as one user.
+func (r *Registry) Users() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := map[string]struct{}{}
+	for s := range r.sessions {
+		users[s.info.Username] = struct{}{}
+	}
+	return len(users)
+}
+
+// Kill terminates the connection of the sessions with the ID. It returns the
+// number of terminated sessions.
+func (r *Registry) Kill(id string) (int, error) {
+	r.mu.Lock()
+	var kills []func() error
+	for s := range r.sessions {
+		if s.info.ID == id {
+			kills = append(kills, s.kill)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(kills) == 0 {
+		return 0, proto.ErrSessionNotFound
+	}
+
+	// The sessions of a connection close it once.
+	if err := kills[0](); err != nil {
+		return 0, err
+	}
+
+	return len(kills), nil
+}
+
+// Session is an active session registered with a Registry.
+type Session struct {
+	registry *Registry
+	info     Info
+	kill     func() error
+}
+
+// SetLocation updates what the TUI of the session is showing.
+func (s *Session) SetLocation(loc proto.SessionState) {
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	s.info.Location = loc
+}
+
+// Remove unregisters the session once it's done.
+func (s *Session) Remove() {
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	delete(s.registry.sessions, s)
+}
@@ -0,0 +1,44 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/matryer/is"
+)
+
+func TestRegistry(t *testing.T) {
+	is := is.New(t)
+	r := NewRegistry()
+	now := time.Now()
+	var killed int
+	kill := func() error {
+		killed++
+		return nil
+	}
+
+	alice := r.Add(Info{ID: "a", Username: "alice", Type: "tui", StartedAt: now}, kill)
+	r.Add(Info{ID: "b", Username: "bob", Type: "command", StartedAt: now.Add(time.Second)}, kill)
+	r.Add(Info{ID: "b", Username: "bob", Type: "sftp", StartedAt: now.Add(2 * time.Second)}, kill)
+	is.Equal(r.Users(), 2)
+
+	alice.SetLocation(proto.SessionState{Repo: "repo1", Tab: "Files"})
+	infos := r.List()
+	is.Equal(len(infos), 3)
+	is.Equal(infos[0].Username, "alice")
+	is.Equal(infos[0].Location.Repo, "repo1")
+	is.Equal(infos[2].Type, "sftp")
+
+	// Killing a session closes its connection once.
+	n, err := r.Kill("b")
+	is.NoErr(err)
+	is.Equal(n, 2)
+	is.Equal(killed, 1)
+
+	_, err = r.Kill("c")
+	is.Equal(err, proto.ErrSessionNotFound)
+
+	alice.Remove()
+	is.Equal(len(r.List()), 2)
+}
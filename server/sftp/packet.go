@@ -0,0 +1,195 @@
+package sftp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// Packet types of version 3 of the SFTP protocol.
+// https://datatracker.ietf.org/doc/html/draft-ietf-secsh-filexfer-02
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpWrite    = 6
+	fxpLstat    = 7
+	fxpFstat    = 8
+	fxpSetstat  = 9
+	fxpFsetstat = 10
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRemove   = 13
+	fxpMkdir    = 14
+	fxpRmdir    = 15
+	fxpRealpath = 16
+	fxpStat     = 17
+	fxpRename   = 18
+	fxpReadlink = 19
+	fxpSymlink  = 20
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+	fxpExtended = 200
+)
+
+// Status codes.
+const (
+	fxOK               = 0
+	fxEOF              = 1
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+	fxFailure          = 4
+	fxBadMessage       = 5
+	fxOpUnsupported    = 8
+)
+
+// Attribute flags.
+const (
+	attrSize        = 0x00000001
+	attrPermissions = 0x00000004
+	attrACModTime   = 0x00000008
+)
+
+// Open flags.
+const (
+	fxfRead   = 0x00000001
+	fxfWrite  = 0x00000002
+	fxfAppend = 0x00000004
+	fxfCreat  = 0x00000008
+	fxfTrunc  = 0x00000010
+)
+
+// Unix file type bits of the permissions attribute.
+const (
+	modeDir  = 0o040000
+	modeFile = 0o100000
+)
+
+// maxPacketSize is the largest packet accepted from clients. Clients must
+// accept packets of at least 34000 bytes, so reads are capped to fit.
+const maxPacketSize = 256 * 1024
+
+// maxReadSize is the largest chunk of data returned by a single read.
+const maxReadSize = 32 * 1024
+
+var errBadMessage = errors.New("bad message")
+
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(hdr[:4])
+	if length < 1 || length > maxPacketSize {
+		return 0, nil, errBadMessage
+	}
+
+	data := make([]byte, length-1)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+
+	return hdr[4], data, nil
+}
+
+// buffer decodes the fields of a packet.
+type buffer struct {
+	data []byte
+	err  error
+}
+
+func (b *buffer) uint32() uint32 {
+	if len(b.data) < 4 {
+		b.err = errBadMessage
+		return 0
+	}
+	v := binary.BigEndian.Uint32(b.data)
+	b.data = b.data[4:]
+	return v
+}
+
+func (b *buffer) uint64() uint64 {
+	if len(b.data) < 8 {
+		b.err = errBadMessage
+		return 0
+	}
+	v := binary.BigEndian.Uint64(b.data)
+	b.data = b.data[8:]
+	return v
+}
+
+func (b *buffer) string() string {
+	n := b.uint32()
+	if b.err != nil || uint32(len(b.data)) < n {
+		b.err = errBadMessage
+		return ""
+	}
+	s := string(b.data[:n])
+	b.data = b.data[n:]
+	return s
+}
+
+// packet encodes a packet.
+type packet []byte
+
+func newPacket(typ byte) packet {
+	// Leave room for the length.
+	return packet{0, 0, 0, 0, typ}
+}
+
+func (p packet) uint32(v uint32) packet {
+	return binary.BigEndian.AppendUint32(p, v)
+}
+
+func (p packet) uint64(v uint64) packet {
+	return binary.BigEndian.AppendUint64(p, v)
+}
+
+func (p packet) string(s string) packet {
+	return append(p.uint32(uint32(len(s))), s...)
+}
+
+func (p packet) attrs(fi fs.FileInfo) packet {
+	mode := uint32(fi.Mode().Perm())
+	if fi.IsDir() {
+		mode |= modeDir
+	} else {
+		mode |= modeFile
+	}
+
+	mtime := uint32(fi.ModTime().Unix())
+	if fi.ModTime().IsZero() {
+		mtime = 0
+	}
+
+	p = p.uint32(attrSize | attrPermissions | attrACModTime)
+	p = p.uint64(uint64(fi.Size()))
+	p = p.uint32(mode)
+	p = p.uint32(mtime)
+	return p.uint32(mtime)
+}
+
+func (p packet) bytes() []byte {
+	binary.BigEndian.PutUint32(p, uint32(len(p)-4))
+	return p
+}
+
+// longName formats fi like "ls -l" does, clients print it as is.
+func longName(fi fs.FileInfo) string {
+	t := fi.ModTime()
+	layout := "Jan _2 15:04"
+	if t.Before(time.Now().AddDate(0, -6, 0)) {
+		layout = "Jan _2  2006"
+	}
+
+	return fmt.Sprintf("%s    1 soft     soft     %8d %s %s", fi.Mode(), fi.Size(), t.Format(layout), fi.Name())
+}
@@ -0,0 +1,269 @@
+// Package sftp implements a read-only SFTP server.
+package sftp
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// maxHandles is the maximum number of files a client can keep open.
+const maxHandles = 256
+
+// readDirBatch is the number of directory entries returned per read.
+const readDirBatch = 128
+
+// Server serves a read-only fs.FS over version 3 of the SFTP protocol.
+// Opened files are read with io.ReaderAt or io.Seeker when they implement
+// them, directories must implement fs.ReadDirFile.
+type Server struct {
+	fsys    fs.FS
+	rw      io.ReadWriter
+	logger  *log.Logger
+	handles map[string]fs.File
+	next    uint64
+}
+
+// NewServer returns a new SFTP server of fsys talking to the client over rw.
+func NewServer(fsys fs.FS, rw io.ReadWriter, logger *log.Logger) *Server {
+	return &Server{
+		fsys:    fsys,
+		rw:      rw,
+		logger:  logger,
+		handles: make(map[string]fs.File),
+	}
+}
+
+// Serve handles requests until the client disconnects.
+func (s *Server) Serve() error {
+	defer func() {
+		for _, f := range s.handles {
+			f.Close() // nolint: errcheck
+		}
+	}()
+
+	for {
+		typ, data, err := readPacket(s.rw)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		resp := s.handle(typ, &buffer{data: data})
+		if _, err := s.rw.Write(resp.bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(typ byte, b *buffer) packet {
+	if typ == fxpInit {
+		// We only speak version 3, which is what clients ask for.
+		b.uint32()
+		return newPacket(fxpVersion).uint32(3)
+	}
+
+	id := b.uint32()
+	if b.err != nil {
+		return status(id, fxBadMessage, b.err.Error())
+	}
+
+	switch typ {
+	case fxpOpen:
+		name, flags := b.string(), b.uint32()
+		if b.err != nil {
+			return status(id, fxBadMessage, b.err.Error())
+		}
+		if flags&(fxfWrite|fxfAppend|fxfCreat|fxfTrunc) != 0 || flags&fxfRead == 0 {
+			return status(id, fxPermissionDenied, "read-only file system")
+		}
+		return s.open(id, name, false)
+	case fxpOpendir:
+		name := b.string()
+		if b.err != nil {
+			return status(id, fxBadMessage, b.err.Error())
+		}
+		return s.open(id, name, true)
+	case fxpClose:
+		handle := b.string()
+		f, ok := s.handles[handle]
+		if !ok {
+			return status(id, fxFailure, "invalid handle")
+		}
+		delete(s.handles, handle)
+		if err := f.Close(); err != nil {
+			return errorStatus(id, err)
+		}
+		return status(id, fxOK, "")
+	case fxpRead:
+		handle, offset, length := b.string(), b.uint64(), b.uint32()
+		if b.err != nil {
+			return status(id, fxBadMessage, b.err.Error())
+		}
+		f, ok := s.handles[handle]
+		if !ok {
+			return status(id, fxFailure, "invalid handle")
+		}
+		return read(id, f, int64(offset), length)
+	case fxpReaddir:
+		handle := b.string()
+		f, ok := s.handles[handle]
+		if !ok {
+			return status(id, fxFailure, "invalid handle")
+		}
+		return readDir(id, f)
+	case fxpStat, fxpLstat:
+		name := b.string()
+		if b.err != nil {
+			return status(id, fxBadMessage, b.err.Error())
+		}
+		fi, err := fs.Stat(s.fsys, fsName(name))
+		if err != nil {
+			return errorStatus(id, err)
+		}
+		return newPacket(fxpAttrs).uint32(id).attrs(fi)
+	case fxpFstat:
+		f, ok := s.handles[b.string()]
+		if !ok {
+			return status(id, fxFailure, "invalid handle")
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			return errorStatus(id, err)
+		}
+		return newPacket(fxpAttrs).uint32(id).attrs(fi)
+	case fxpRealpath:
+		name := b.string()
+		if b.err != nil {
+			return status(id, fxBadMessage, b.err.Error())
+		}
+		p := path.Clean("/" + name)
+		// Clients only use the name, attributes are empty.
+		return newPacket(fxpName).uint32(id).uint32(1).string(p).string(p).uint32(0)
+	case fxpWrite, fxpSetstat, fxpFsetstat, fxpRemove, fxpMkdir, fxpRmdir, fxpRename, fxpSymlink:
+		return status(id, fxPermissionDenied, "read-only file system")
+	default:
+		s.logger.Debug("unsupported sftp request", "type", typ)
+		return status(id, fxOpUnsupported, "operation not supported")
+	}
+}
+
+func (s *Server) open(id uint32, name string, isDir bool) packet {
+	if len(s.handles) >= maxHandles {
+		return status(id, fxFailure, "too many open files")
+	}
+
+	f, err := s.fsys.Open(fsName(name))
+	if err != nil {
+		return errorStatus(id, err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close() // nolint: errcheck
+		return errorStatus(id, err)
+	}
+
+	if fi.IsDir() != isDir {
+		f.Close() // nolint: errcheck
+		if isDir {
+			return status(id, fxFailure, "not a directory")
+		}
+		return status(id, fxFailure, "is a directory")
+	}
+
+	s.next++
+	handle := strconv.FormatUint(s.next, 10)
+	s.handles[handle] = f
+	return newPacket(fxpHandle).uint32(id).string(handle)
+}
+
+func read(id uint32, f fs.File, offset int64, length uint32) packet {
+	if length > maxReadSize {
+		length = maxReadSize
+	}
+
+	buf := make([]byte, length)
+	var n int
+	var err error
+	switch r := f.(type) {
+	case io.ReaderAt:
+		n, err = r.ReadAt(buf, offset)
+	case io.ReadSeeker:
+		if _, err = r.Seek(offset, io.SeekStart); err == nil {
+			n, err = io.ReadFull(r, buf)
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+		}
+	default:
+		return status(id, fxOpUnsupported, "file can't be read at an offset")
+	}
+
+	if n == 0 && err != nil {
+		if errors.Is(err, io.EOF) {
+			return status(id, fxEOF, "end of file")
+		}
+		return errorStatus(id, err)
+	}
+
+	return newPacket(fxpData).uint32(id).string(string(buf[:n]))
+}
+
+func readDir(id uint32, f fs.File) packet {
+	d, ok := f.(fs.ReadDirFile)
+	if !ok {
+		return status(id, fxFailure, "not a directory")
+	}
+
+	entries, err := d.ReadDir(readDirBatch)
+	if len(entries) == 0 {
+		if err == nil || errors.Is(err, io.EOF) {
+			return status(id, fxEOF, "end of directory")
+		}
+		return errorStatus(id, err)
+	}
+
+	p := newPacket(fxpName).uint32(id).uint32(uint32(len(entries)))
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			return errorStatus(id, err)
+		}
+		p = p.string(e.Name()).string(longName(fi)).attrs(fi)
+	}
+
+	return p
+}
+
+func status(id uint32, code uint32, msg string) packet {
+	return newPacket(fxpStatus).uint32(id).uint32(code).string(msg).string("en")
+}
+
+func errorStatus(id uint32, err error) packet {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return status(id, fxNoSuchFile, "no such file")
+	case errors.Is(err, fs.ErrPermission):
+		return status(id, fxPermissionDenied, "permission denied")
+	default:
+		return status(id, fxFailure, err.Error())
+	}
+}
+
+// fsName converts an SFTP path to an fs.FS name. Relative paths are relative
+// to the root.
+func fsName(p string) string {
+	name := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
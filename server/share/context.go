@@ -0,0 +1,33 @@
+package share
+
+import "context"
+
+// ContextKey is the key for the share registry in the context.
+var ContextKey = &struct{ string }{"share"}
+
+// FromContext returns the share registry from a context.
+func FromContext(ctx context.Context) *Registry {
+	if r, ok := ctx.Value(ContextKey).(*Registry); ok {
+		return r
+	}
+
+	return nil
+}
+
+// WithContext returns a new context with the share registry attached.
+func WithContext(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, ContextKey, r)
+}
+
+// SessionContextKey is the key for the current shareable session in the
+// context.
+var SessionContextKey = &struct{ string }{"share-session"}
+
+// SessionFromContext returns the shareable session from a context.
+func SessionFromContext(ctx context.Context) *Session {
+	if s, ok := ctx.Value(SessionContextKey).(*Session); ok {
+		return s
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+// Package share implements read-only sharing of TUI sessions.
+//
+// A presenter wraps the output of its TUI session with a Session. Once the
+// session is shared, a short-lived join code is generated and other users can
+// use it to attach to the session and watch the presenter's screen.
+package share
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidCode is returned when a join code doesn't exist or has
+	// expired.
+	ErrInvalidCode = errors.New("invalid or expired join code")
+	// ErrSessionClosed is returned when sharing a session that was closed.
+	ErrSessionClosed = errors.New("session closed")
+)
+
+// DefaultTTL is the default duration a join code is valid for.
+const DefaultTTL = 10 * time.Minute
+
+// codeAlphabet excludes characters that are easily confused with one another.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// codeLength is the length of generated join codes.
+const codeLength = 6
+
+// Registry keeps track of shared sessions and their join codes.
+type Registry struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	now      func() time.Time
+}
+
+// NewRegistry returns a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		sessions: make(map[string]*Session),
+		now:      time.Now,
+	}
+}
+
+// NewSession returns a new shareable Session that writes to w.
+func (r *Registry) NewSession(w io.Writer) *Session {
+	return &Session{
+		registry: r,
+		w:        w,
+		viewers:  make(map[int]io.Writer),
+		done:     make(chan struct{}),
+	}
+}
+
+// Join returns the shared session with the given join code.
+func (r *Registry) Join(code string) (*Session, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[code]
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+	if r.now().After(s.expiresAt) {
+		delete(r.sessions, code)
+		return nil, ErrInvalidCode
+	}
+	return s, nil
+}
+
+func (r *Registry) register(s *Session, ttl time.Duration) (string, time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Drop expired codes.
+	now := r.now()
+	for c, ss := range r.sessions {
+		if now.After(ss.expiresAt) {
+			delete(r.sessions, c)
+		}
+	}
+
+	for {
+		code, err := generateCode()
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if _, ok := r.sessions[code]; ok {
+			continue
+		}
+		r.sessions[code] = s
+		return code, now.Add(ttl), nil
+	}
+}
+
+func (r *Registry) unregister(code string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, code)
+}
+
+func generateCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := 0; i < codeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(codeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// Session is an io.Writer that broadcasts everything written to it to the
+// presenter and all the attached viewers.
+type Session struct {
+	registry *Registry
+
+	mu        sync.Mutex
+	w         io.Writer
+	viewers   map[int]io.Writer
+	nextID    int
+	code      string
+	expiresAt time.Time
+	closed    bool
+	done      chan struct{}
+
+	// OnJoin is called after a viewer attaches to the session. It's usually
+	// used to repaint the presenter's screen so that the viewer gets a full
+	// frame.
+	OnJoin func()
+}
+
+var _ io.Writer = (*Session)(nil)
+
+// Write implements io.Writer. Writes to viewers that fail are detached.
+func (s *Session) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, v := range s.viewers {
+		if _, err := v.Write(p); err != nil {
+			delete(s.viewers, id)
+		}
+	}
+	return s.w.Write(p)
+}
+
+// Share starts sharing the session and returns a join code valid for ttl.
+// Sharing an already shared session returns the existing code.
+func (s *Session) Share(ttl time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return "", ErrSessionClosed
+	}
+	if s.code != "" {
+		return s.code, nil
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	code, expiresAt, err := s.registry.register(s, ttl)
+	if err != nil {
+		return "", err
+	}
+	s.code = code
+	s.expiresAt = expiresAt
+	return code, nil
+}
+
+// Unshare stops sharing the session and detaches all the viewers.
+func (s *Session) Unshare() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unshare()
+}
+
+func (s *Session) unshare() {
+	if s.code != "" {
+		s.registry.unregister(s.code)
+		s.code = ""
+	}
+	for id, v := range s.viewers {
+		if c, ok := v.(*viewer); ok {
+			c.close()
+		}
+		delete(s.viewers, id)
+	}
+}
+
+// Code returns the join code of the session or an empty string if the
+// session is not shared.
+func (s *Session) Code() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.code
+}
+
+// Viewers returns the number of attached viewers.
+func (s *Session) Viewers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.viewers)
+}
+
+// Attach attaches w as a viewer of the session. The returned channel is
+// closed when the viewer is detached, either because the presenter stopped
+// sharing or because the session was closed. Call detach to stop watching.
+func (s *Session) Attach(w io.Writer) (done <-chan struct{}, detach func(), err error) {
+	s.mu.Lock()
+	if s.closed || s.code == "" {
+		s.mu.Unlock()
+		return nil, nil, ErrInvalidCode
+	}
+	v := &viewer{Writer: w, done: make(chan struct{})}
+	id := s.nextID
+	s.nextID++
+	s.viewers[id] = v
+	onJoin := s.OnJoin
+	s.mu.Unlock()
+
+	if onJoin != nil {
+		onJoin()
+	}
+
+	return v.done, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.viewers, id)
+		v.close()
+	}, nil
+}
+
+// Close stops sharing the session and releases its resources.
+func (s *Session) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.unshare()
+	s.closed = true
+	close(s.done)
+}
+
+// Done returns a channel that's closed when the session is closed.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+type viewer struct {
+	io.Writer
+	once sync.Once
+	done chan struct{}
+}
+
+func (v *viewer) close() {
+	v.once.Do(func() { close(v.done) })
+}
@@ -0,0 +1,96 @@
+package share
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestShareJoin(t *testing.T) {
+	is := is.New(t)
+	reg := NewRegistry()
+	var presenter, viewer bytes.Buffer
+	s := reg.NewSession(&presenter)
+
+	var joined int
+	s.OnJoin = func() { joined++ }
+
+	// Not shared yet.
+	_, _, err := s.Attach(&viewer)
+	is.Equal(err, ErrInvalidCode)
+
+	code, err := s.Share(time.Minute)
+	is.NoErr(err)
+	is.Equal(len(code), codeLength)
+
+	// Sharing again returns the same code.
+	again, err := s.Share(time.Minute)
+	is.NoErr(err)
+	is.Equal(again, code)
+
+	js, err := reg.Join(code)
+	is.NoErr(err)
+	is.Equal(js, s)
+
+	done, detach, err := js.Attach(&viewer)
+	is.NoErr(err)
+	is.Equal(joined, 1)
+	is.Equal(s.Viewers(), 1)
+
+	_, err = s.Write([]byte("hello"))
+	is.NoErr(err)
+	is.Equal(presenter.String(), "hello")
+	is.Equal(viewer.String(), "hello")
+
+	detach()
+	<-done
+	is.Equal(s.Viewers(), 0)
+
+	_, err = s.Write([]byte(" world"))
+	is.NoErr(err)
+	is.Equal(presenter.String(), "hello world")
+	is.Equal(viewer.String(), "hello")
+}
+
+func TestShareUnshare(t *testing.T) {
+	is := is.New(t)
+	reg := NewRegistry()
+	s := reg.NewSession(&bytes.Buffer{})
+	code, err := s.Share(0)
+	is.NoErr(err)
+
+	done, _, err := s.Attach(&bytes.Buffer{})
+	is.NoErr(err)
+
+	s.Unshare()
+	<-done
+	is.Equal(s.Code(), "")
+	_, err = reg.Join(code)
+	is.Equal(err, ErrInvalidCode)
+
+	s.Close()
+	<-s.Done()
+	_, err = s.Share(0)
+	is.Equal(err, ErrSessionClosed)
+}
+
+func TestShareExpired(t *testing.T) {
+	is := is.New(t)
+	reg := NewRegistry()
+	now := time.Now()
+	reg.now = func() time.Time { return now }
+	s := reg.NewSession(&bytes.Buffer{})
+	code, err := s.Share(time.Minute)
+	is.NoErr(err)
+
+	// Join codes are case insensitive.
+	_, err = reg.Join(strings.ToLower(code))
+	is.NoErr(err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = reg.Join(code)
+	is.Equal(err, ErrInvalidCode)
+}
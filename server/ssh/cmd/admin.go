@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/ssh"
+	"github.com/spf13/cobra"
+)
+
+// AdminCommand returns the admin subcommand. rootCommand returns the command
+// tree impersonated users run commands in.
+func AdminCommand(rootCommand func() *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Administer the server",
+	}
+
+	suCommand := &cobra.Command{
+		Use:   "su USERNAME [COMMAND...]",
+		Short: "Run a command as another user",
+		Long: `Run a command with the permissions of another user, to reproduce the permission issues they report.
+Connect with "ssh -t" and no command to open the TUI as the user instead.`,
+		Example:            "  admin su beatrice repo list",
+		DisableFlagParsing: true,
+		PersistentPreRunE:  checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+				return cmd.Help()
+			}
+
+			if len(args) == 1 {
+				return errors.New("a command is required, connect with \"ssh -t\" to open the TUI")
+			}
+
+			ctx, err := Impersonate(cmd.Context(), args[0], args[1:])
+			if err != nil {
+				return err
+			}
+
+			root := rootCommand()
+			root.SetArgs(args[1:])
+			root.SetIn(cmd.InOrStdin())
+			root.SetOut(cmd.OutOrStdout())
+			root.SetErr(cmd.ErrOrStderr())
+
+			return root.ExecuteContext(ctx)
+		},
+	}
+
+	cmd.AddCommand(
+		suCommand,
+		auditCommand(),
+		serverInfoCommand(),
+		reloadCommand(),
+		maintenanceCommand(),
+		motdCommand(),
+		wallCommand(),
+		sessionsCommand(),
+		jobsCommand(),
+		tasksCommand(),
+		quotaCommand(),
+	)
+
+	return cmd
+}
+
+// Impersonate returns a context acting as the user username. The admin
+// impersonating the user, and the command they run with args, are recorded in
+// the audit log.
+func Impersonate(ctx context.Context, username string, args []string) (context.Context, error) {
+	be := backend.FromContext(ctx)
+	user, err := be.User(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	be.Audit(ctx, proto.AuditEvent{
+		Action:  proto.AuditUserImpersonate,
+		Target:  user.Username(),
+		Details: strings.Join(args, " "),
+	})
+
+	if impersonator := proto.UserFromContext(ctx); impersonator != nil {
+		ctx = proto.WithImpersonatorContext(ctx, impersonator)
+	}
+
+	// The public key of the admin would grant their access.
+	ctx = context.WithValue(ctx, ssh.ContextKeyPublicKey, nil)
+	return proto.WithUserContext(ctx, user), nil
+}
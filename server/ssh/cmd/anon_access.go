@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+func anonAccessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "anon-access REPOSITORY [default|no-access|read-only]",
+		Short: "Set or get the anonymous access level of a repository",
+		Long: `Set or get the anonymous access level of a repository.
+
+It overrides the anonymous access level of the server for a public repository,
+over SSH, HTTP, and the Git daemon. "default" removes the override.`,
+		Args:      cobra.RangeArgs(1, 2),
+		ValidArgs: []string{"default", access.NoAccess.String(), access.ReadOnlyAccess.String()},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			switch len(args) {
+			case 1:
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				level, override, err := be.RepoAnonAccess(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				if jsonOutput(cmd) {
+					return printJSON(cmd, struct {
+						AnonAccess string `json:"anon_access"`
+						Default    bool   `json:"default"`
+					}{level.String(), !override})
+				}
+
+				if !override {
+					cmd.Printf("%s (default)\n", level)
+				} else {
+					cmd.Println(level)
+				}
+			case 2:
+				if err := checkIfRepoAdmin(cmd, args); err != nil {
+					return err
+				}
+
+				var level *access.AccessLevel
+				if args[1] != "default" {
+					al := access.ParseAccessLevel(args[1])
+					if al != access.NoAccess && al != access.ReadOnlyAccess {
+						return usageError{fmt.Errorf("invalid anonymous access level: %s, must be default, no-access, or read-only", args[1])}
+					}
+					level = &al
+				}
+
+				return be.SetRepoAnonAccess(ctx, repo, level)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
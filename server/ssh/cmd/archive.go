@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+func archiveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive REPOSITORY",
+		Short: "Archive a repository, making it read-only",
+		Long: `Archive a repository, making it read-only. Pushes, LFS uploads, and tag and
+branch changes are rejected until the repository is unarchived. Archived
+repositories are left out of the web home page unless asked for.`,
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.SetArchived(ctx, args[0], true)
+		},
+	}
+
+	return cmd
+}
+
+func unarchiveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unarchive REPOSITORY",
+		Short:             "Unarchive a repository, making it writable again",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.SetArchived(ctx, args[0], false)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/duration"
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/spf13/cobra"
+)
+
+const auditTimeLayout = "2006-01-02 15:04:05 MST"
+
+// auditCommand returns the command to query the audit log.
+func auditCommand() *cobra.Command {
+	var filter auditFilterFlags
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the audit log",
+		Long: `Show the most recent security-relevant actions recorded in the audit log.
+Actions can be filtered by group, i.e. "auth" matches "auth.success" and "auth.failure".`,
+		Example:           "  admin audit --action repo --since 7d\n  admin audit export --user beatrice > audit.jsonl",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			f, err := filter.parse()
+			if err != nil {
+				return err
+			}
+
+			events, err := be.AuditEvents(ctx, f)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				if events == nil {
+					events = []proto.AuditEvent{}
+				}
+				return printJSON(cmd, events)
+			}
+
+			if len(events) == 0 {
+				cmd.Println("No audit events found")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				events,
+				[]string{"ID", "Time", "Action", "User", "Repo", "Target", "Address", "Details"},
+				func(e proto.AuditEvent) ([]string, error) {
+					username := e.Username
+					if e.Impersonator != "" {
+						username += " (" + e.Impersonator + ")"
+					}
+
+					return []string{
+						strconv.FormatInt(e.ID, 10),
+						tf.Absolute(e.CreatedAt, auditTimeLayout),
+						string(e.Action),
+						orDash(username),
+						orDash(e.Repo),
+						orDash(e.Target),
+						orDash(e.RemoteAddr),
+						orDash(e.Details),
+					}, nil
+				},
+			)
+		},
+	}
+
+	filter.register(cmd, 50)
+
+	var exportFilter auditFilterFlags
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the audit log as JSON lines",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			f, err := exportFilter.parse()
+			if err != nil {
+				return err
+			}
+
+			events, err := be.AuditEvents(ctx, f)
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			for _, e := range events {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	exportFilter.register(exportCmd, 0)
+
+	cmd.AddCommand(exportCmd)
+
+	return cmd
+}
+
+// auditFilterFlags are the flags filtering audit events.
+type auditFilterFlags struct {
+	action   string
+	username string
+	repo     string
+	since    string
+	until    string
+	limit    int
+}
+
+func (f *auditFilterFlags) register(cmd *cobra.Command, limit int) {
+	cmd.Flags().StringVarP(&f.action, "action", "a", "", "only show actions of this kind or group")
+	cmd.Flags().StringVarP(&f.username, "user", "u", "", "only show actions performed by this user")
+	cmd.Flags().StringVarP(&f.repo, "repo", "r", "", "only show actions on this repository")
+	cmd.Flags().StringVar(&f.since, "since", "", "only show actions since a date or a duration ago, i.e. 2006-01-02 or 7d")
+	cmd.Flags().StringVar(&f.until, "until", "", "only show actions before a date or a duration ago")
+	cmd.Flags().IntVarP(&f.limit, "limit", "n", limit, "maximum number of most recent actions, 0 for all")
+}
+
+func (f *auditFilterFlags) parse() (proto.AuditFilter, error) {
+	since, err := parseAuditTime(f.since)
+	if err != nil {
+		return proto.AuditFilter{}, usageError{fmt.Errorf("invalid --since: %w", err)}
+	}
+
+	until, err := parseAuditTime(f.until)
+	if err != nil {
+		return proto.AuditFilter{}, usageError{fmt.Errorf("invalid --until: %w", err)}
+	}
+
+	var repo string
+	if f.repo != "" {
+		repo = utils.SanitizeRepo(f.repo)
+	}
+
+	return proto.AuditFilter{
+		Action:   f.action,
+		Username: strings.ToLower(f.username),
+		Repo:     repo,
+		Since:    since,
+		Until:    until,
+		Limit:    f.limit,
+	}, nil
+}
+
+// parseAuditTime parses a date, a RFC 3339 time, or a duration before now.
+func parseAuditTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+
+	d, err := duration.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date or a duration: %q", s)
+	}
+
+	return time.Now().Add(-d), nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+// blameCommand returns a command that prints the commits that last changed
+// the lines of a file.
+func blameCommand() *cobra.Command {
+	var lines string
+
+	cmd := &cobra.Command{
+		Use:   "blame REPOSITORY REFERENCE PATH",
+		Short: "Show the commits that last changed the lines of a file",
+		Long: `Show the commit, the author, and the date of the last change of each line of
+a file at a reference. With --json, the lines are printed as an array of
+objects with their number, commit, author, author email, time, commit summary,
+and content.`,
+		Example:           "  repo blame icecream main README.md -L 10,20",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			start, end, err := parseLineRange(lines)
+			if err != nil {
+				return err
+			}
+
+			rr, err := be.Repository(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			r, err := rr.Open()
+			if err != nil {
+				return err
+			}
+
+			rev, err := resolveCommit(r, args[1])
+			if err != nil {
+				return err
+			}
+
+			fp := args[2]
+			tree, err := r.LsTree(rev)
+			if err != nil {
+				return err
+			}
+			if te, err := tree.TreeEntry(fp); err != nil || te.Type() != "blob" {
+				return fmt.Errorf("%w: %s", proto.ErrFileNotFound, fp)
+			}
+
+			blame, err := r.Blame(rev, fp, start, end)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				type lineJSON struct {
+					Line        int       `json:"line"`
+					Commit      string    `json:"commit"`
+					Author      string    `json:"author"`
+					AuthorEmail string    `json:"author_email"`
+					AuthorTime  time.Time `json:"author_time"`
+					Summary     string    `json:"summary"`
+					Content     string    `json:"content"`
+				}
+				out := make([]lineJSON, 0, len(blame))
+				for _, l := range blame {
+					out = append(out, lineJSON{l.Number, l.Commit, l.Author, l.AuthorEmail, l.AuthorTime, l.Summary, l.Content})
+				}
+				return printJSON(cmd, out)
+			}
+
+			authorWidth, numberWidth := 0, 0
+			for _, l := range blame {
+				if len(l.Author) > authorWidth {
+					authorWidth = len(l.Author)
+				}
+				if n := len(strconv.Itoa(l.Number)); n > numberWidth {
+					numberWidth = n
+				}
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			for _, l := range blame {
+				cmd.Printf("%.8s (%-*s %s %*d) %s\n", l.Commit, authorWidth, l.Author,
+					tf.Absolute(l.AuthorTime, "2006-01-02"), numberWidth, l.Number, l.Content)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&lines, "lines", "L", "", "only blame the lines START,END, i.e. 10,20, 10, or ,20")
+
+	return cmd
+}
+
+// parseLineRange parses a START,END range of lines, both optional.
+func parseLineRange(s string) (int, int, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+
+	invalid := usageError{fmt.Errorf("invalid line range %q, use START,END", s)}
+	a, b, _ := strings.Cut(s, ",")
+	var start, end int
+	var err error
+	if a != "" {
+		if start, err = strconv.Atoi(a); err != nil || start < 1 {
+			return 0, 0, invalid
+		}
+	}
+	if b != "" {
+		if end, err = strconv.Atoi(b); err != nil || end < 1 || end < start {
+			return 0, 0, invalid
+		}
+	}
+
+	return start, end, nil
+}
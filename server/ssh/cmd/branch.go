@@ -1,11 +1,11 @@
 package cmd
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	gitm "github.com/gogs/git-module"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +20,7 @@ func branchCommand() *cobra.Command {
 		branchListCommand(),
 		branchDefaultCommand(),
 		branchDeleteCommand(),
+		branchProtectCommand(),
 	)
 
 	return cmd
@@ -46,11 +47,7 @@ func branchListCommand() *cobra.Command {
 			}
 
 			branches, _ := r.Branches()
-			for _, b := range branches {
-				cmd.Println(b)
-			}
-
-			return nil
+			return printNames(cmd, branches)
 		},
 	}
 
@@ -86,7 +83,7 @@ func branchDefaultCommand() *cobra.Command {
 					return err
 				}
 
-				cmd.Println(head.Name().Short())
+				return printValue(cmd, head.Name().Short())
 			case 2:
 				if err := checkIfCollab(cmd, args); err != nil {
 					return err
@@ -123,6 +120,7 @@ func branchDefaultCommand() *cobra.Command {
 				}); err != nil {
 					return err
 				}
+				be.InvalidateCache(ctx, rn)
 			}
 
 			return nil
@@ -137,45 +135,12 @@ func branchDeleteCommand() *cobra.Command {
 		Use:               "delete REPOSITORY BRANCH",
 		Aliases:           []string{"remove", "rm", "del"},
 		Short:             "Delete a branch",
+		Args:              cobra.ExactArgs(2),
 		PersistentPreRunE: checkIfCollab,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			rn := strings.TrimSuffix(args[0], ".git")
-			rr, err := be.Repository(ctx, rn)
-			if err != nil {
-				return err
-			}
-
-			r, err := rr.Open()
-			if err != nil {
-				return err
-			}
-
-			branch := args[1]
-			branches, _ := r.Branches()
-			var exists bool
-			for _, b := range branches {
-				if branch == b {
-					exists = true
-					break
-				}
-			}
-
-			if !exists {
-				return git.ErrReferenceNotExist
-			}
-
-			head, err := r.HEAD()
-			if err != nil {
-				return err
-			}
-
-			if head.Name().Short() == branch {
-				return fmt.Errorf("cannot delete the default branch")
-			}
-
-			return r.DeleteBranch(branch, gitm.DeleteBranchOptions{Force: true})
+			return be.DeleteBranch(ctx, args[0], proto.UserFromContext(ctx), args[1])
 		},
 	}
 
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func branchProtectCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "protect",
+		Aliases: []string{"protection", "protections"},
+		Short:   "Manage branch protection rules",
+	}
+
+	cmd.AddCommand(
+		branchProtectAddCommand(),
+		branchProtectRemoveCommand(),
+		branchProtectListCommand(),
+	)
+
+	return cmd
+}
+
+func branchProtectAddCommand() *cobra.Command {
+	var opts proto.BranchProtectionOptions
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY PATTERN",
+		Aliases:           []string{"set", "update"},
+		Short:             "Protect branches matching a pattern",
+		Long:              "Protect branches matching a pattern. PATTERN can be a branch name or a glob, i.e. release/*. By default, protected branches cannot be force-pushed or deleted. Running this command again on the same pattern replaces the rule.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			return be.ProtectBranch(ctx, rn, args[1], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.AllowForcePush, "allow-force-push", false, "allow force pushes")
+	cmd.Flags().BoolVar(&opts.AllowDeletion, "allow-deletion", false, "allow deleting the branch")
+	cmd.Flags().BoolVar(&opts.RequireSigned, "require-signed", false, "require commits signed with the SSH key of a user or a trusted OpenPGP key")
+	cmd.Flags().StringSliceVar(&opts.PushUsers, "push-user", nil, "restrict pushes to the given users (repeatable). Admins can always push")
+
+	return cmd
+}
+
+func branchProtectRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY PATTERN",
+		Aliases:           []string{"delete", "rm", "del"},
+		Short:             "Remove a branch protection rule",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			return be.UnprotectBranch(ctx, rn, args[1])
+		},
+	}
+
+	return cmd
+}
+
+func branchProtectListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List branch protection rules",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			if _, err := be.Repository(ctx, rn); err != nil {
+				return err
+			}
+
+			rules, err := be.BranchProtections(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			if len(rules) == 0 {
+				cmd.Println("No protected branches")
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				rules,
+				[]string{"Pattern", "Force Push", "Deletion", "Signed Commits", "Push Users"},
+				func(r proto.BranchProtection) ([]string, error) {
+					pushers := "-"
+					if r.RestrictPush {
+						pushers = strings.Join(r.PushUsers, ", ")
+					}
+					return []string{
+						r.Pattern,
+						allowedString(!r.NoForcePush),
+						allowedString(!r.NoDeletion),
+						requiredString(r.RequireSigned),
+						pushers,
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+func allowedString(b bool) string {
+	if b {
+		return "allowed"
+	}
+	return "denied"
+}
+
+func requiredString(b bool) string {
+	if b {
+		return "required"
+	}
+	return "-"
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+// BulkResult is the result of a single operation of a bulk command.
+type BulkResult struct {
+	Item  string `json:"item"`
+	OK    bool   `json:"ok"`
+	Error *Error `json:"error,omitempty"`
+}
+
+// BulkCommand returns a command that runs the same operation on many items.
+func BulkCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk",
+		Short: "Run operations on many repositories at once",
+		Long: `Run operations on many repositories at once.
+
+Each item is processed on its own, a failing item doesn't stop the others.
+Pass "-" instead of the items to read them from stdin, one per line.`,
+	}
+
+	cmd.AddCommand(
+		bulkRepoCreateCommand(),
+		bulkCollabAddCommand(),
+		bulkBranchDeleteCommand(),
+	)
+
+	return cmd
+}
+
+func bulkRepoCreateCommand() *cobra.Command {
+	var private bool
+	var hidden bool
+
+	cmd := &cobra.Command{
+		Use:               "repo-create REPOSITORY...",
+		Short:             "Create many repositories",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			return runBulk(cmd, args, func(repo string) error {
+				if err := checkIfCollab(cmd, []string{repo}); err != nil {
+					return err
+				}
+
+				_, err := be.CreateRepository(ctx, repo, user, proto.RepositoryOptions{
+					Private: private,
+					Hidden:  hidden,
+				})
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().BoolVarP(&private, "private", "p", false, "make the repositories private")
+	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repositories from the UI")
+
+	return cmd
+}
+
+func bulkCollabAddCommand() *cobra.Command {
+	var level string
+
+	cmd := &cobra.Command{
+		Use:   "collab-add USERNAME REPOSITORY...",
+		Short: "Add a collaborator to many repositories",
+		Long:  "Add a collaborator to many repositories. LEVEL can be one of: no-access, read-only, read-write, or admin-access.",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			username := args[0]
+			al := access.ParseAccessLevel(level)
+			if al < 0 {
+				return access.ErrInvalidAccessLevel
+			}
+
+			return runBulk(cmd, args[1:], func(repo string) error {
+				if err := checkIfCollab(cmd, []string{repo}); err != nil {
+					return err
+				}
+
+				return be.AddCollaborator(ctx, repo, username, al)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&level, "level", "l", access.ReadWriteAccess.String(), "the access level of the collaborator")
+
+	return cmd
+}
+
+func bulkBranchDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "branch-delete REPOSITORY BRANCH...",
+		Short:             "Delete many branches of a repository",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			repo := args[0]
+			return runBulk(cmd, args[1:], func(branch string) error {
+				return be.DeleteBranch(ctx, repo, user, branch)
+			})
+		},
+	}
+
+	return cmd
+}
+
+// runBulk runs fn for each item and prints the result of each operation. An
+// items list of "-" reads the items from stdin. It returns a partial failure
+// error if any of the operations failed.
+func runBulk(cmd *cobra.Command, items []string, fn func(item string) error) error {
+	if len(items) == 1 && items[0] == "-" {
+		items = items[:0]
+		scanner := bufio.NewScanner(cmd.InOrStdin())
+		for scanner.Scan() {
+			if item := strings.TrimSpace(scanner.Text()); item != "" {
+				items = append(items, item)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	results := make([]BulkResult, 0, len(items))
+	var failed int
+	for _, item := range items {
+		res := BulkResult{Item: item, OK: true}
+		if err := fn(item); err != nil {
+			e := NewError(err)
+			res.OK = false
+			res.Error = &e
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		if err := json.NewEncoder(cmd.OutOrStdout()).Encode(struct {
+			Results []BulkResult `json:"results"`
+		}{results}); err != nil {
+			return err
+		}
+	} else {
+		for _, res := range results {
+			if res.OK {
+				cmd.Printf("ok\t%s\n", res.Item)
+			} else {
+				cmd.Printf("failed\t%s\t%s\n", res.Item, res.Error.Message)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return Error{
+			Code:     CodePartialFailure,
+			Message:  fmt.Sprintf("%d of %d operations failed", failed, len(items)),
+			Hint:     "check the results of the failed operations",
+			ExitCode: ExitPartialFailure,
+		}
+	}
+
+	return nil
+}
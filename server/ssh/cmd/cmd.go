@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -63,22 +64,7 @@ Use "{{.SSHCommand}}{{.CommandPath}} [command] --help" for more information abou
 // UsageFunc is a function that can be used as a cobra.Command's
 // UsageFunc to render the help output.
 func UsageFunc(c *cobra.Command) error {
-	ctx := c.Context()
-	cfg := config.FromContext(ctx)
-	hostname := "localhost"
-	port := "23231"
-	url, err := url.Parse(cfg.SSH.PublicURL)
-	if err == nil {
-		hostname = url.Hostname()
-		port = url.Port()
-	}
-
-	sshCmd := "ssh"
-	if port != "" && port != "22" {
-		sshCmd += " -p " + port
-	}
-
-	sshCmd += " " + hostname
+	sshCmd := sshCommand(config.FromContext(c.Context()))
 	t := template.New("usage")
 	t.Funcs(templateFuncs)
 	template.Must(t.Parse(c.UsageTemplate()))
@@ -102,6 +88,25 @@ func rpad(s string, padding int) string {
 }
 
 // CommandName returns the name of the command from the args.
+// sshCommand returns the command to connect to the SSH server, i.e.
+// "ssh -p 23231 localhost".
+func sshCommand(cfg *config.Config) string {
+	hostname := "localhost"
+	port := "23231"
+	url, err := url.Parse(cfg.SSH.PublicURL)
+	if err == nil {
+		hostname = url.Hostname()
+		port = url.Port()
+	}
+
+	sshCmd := "ssh"
+	if port != "" && port != "22" {
+		sshCmd += " -p " + port
+	}
+
+	return sshCmd + " " + hostname
+}
+
 func CommandName(args []string) string {
 	if len(args) == 0 {
 		return ""
@@ -126,6 +131,17 @@ func checkIfReadable(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// accessLevel returns the access level of the session for a repository.
+// Sessions without a user, i.e. deploy keys, get the access of their public
+// key.
+func accessLevel(ctx context.Context, repo string) access.AccessLevel {
+	be := backend.FromContext(ctx)
+	if user := proto.UserFromContext(ctx); user != nil {
+		return be.AccessLevelForUser(ctx, repo, user)
+	}
+	return be.AccessLevelByPublicKey(ctx, repo, sshutils.PublicKeyFromContext(ctx))
+}
+
 // IsPublicKeyAdmin returns true if the given public key is an admin key from
 // the initial_admin_keys config or environment field.
 func IsPublicKeyAdmin(cfg *config.Config, pk ssh.PublicKey) bool {
@@ -137,21 +153,34 @@ func IsPublicKeyAdmin(cfg *config.Config, pk ssh.PublicKey) bool {
 	return false
 }
 
-func checkIfAdmin(cmd *cobra.Command, _ []string) error {
-	ctx := cmd.Context()
+// IsAdmin returns whether the session is an admin's.
+func IsAdmin(ctx context.Context) bool {
 	cfg := config.FromContext(ctx)
 	pk := sshutils.PublicKeyFromContext(ctx)
+	user := proto.UserFromContext(ctx)
+	if backend.KeyRestriction(user) == proto.KeyReadOnly {
+		return false
+	}
 	if IsPublicKeyAdmin(cfg, pk) {
-		return nil
+		return true
 	}
 
-	user := proto.UserFromContext(ctx)
-	if user == nil {
+	return user != nil && user.IsAdmin()
+}
+
+func checkIfAdmin(cmd *cobra.Command, _ []string) error {
+	if !IsAdmin(cmd.Context()) {
 		return proto.ErrUnauthorized
 	}
 
-	if !user.IsAdmin() {
-		return proto.ErrUnauthorized
+	return nil
+}
+
+// checkIfKeyWritable returns an error if the session authenticated with a
+// read-only public key, these can't change the account of the user.
+func checkIfKeyWritable(cmd *cobra.Command, _ []string) error {
+	if backend.KeyRestriction(proto.UserFromContext(cmd.Context())) == proto.KeyReadOnly {
+		return fmt.Errorf("%w: this key is read-only", proto.ErrKeyRestricted)
 	}
 
 	return nil
@@ -173,3 +202,42 @@ func checkIfCollab(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// checkIfOwner returns an error unless the user owns the repository, or is an
+// admin.
+func checkIfOwner(cmd *cobra.Command, args []string) error {
+	var repo string
+	if len(args) > 0 {
+		repo = args[0]
+	}
+
+	ctx := cmd.Context()
+	if IsAdmin(ctx) {
+		return nil
+	}
+
+	be := backend.FromContext(ctx)
+	user := proto.UserFromContext(ctx)
+	r, err := be.Repository(ctx, utils.SanitizeRepo(repo))
+	if err != nil || user == nil || r.UserID() != user.ID() {
+		return proto.ErrUnauthorized
+	}
+	return nil
+}
+
+func checkIfRepoAdmin(cmd *cobra.Command, args []string) error {
+	var repo string
+	if len(args) > 0 {
+		repo = args[0]
+	}
+
+	ctx := cmd.Context()
+	be := backend.FromContext(ctx)
+	rn := utils.SanitizeRepo(repo)
+	user := proto.UserFromContext(ctx)
+	auth := be.AccessLevelForUser(cmd.Context(), rn, user)
+	if auth < access.AdminAccess {
+		return proto.ErrUnauthorized
+	}
+	return nil
+}
@@ -83,11 +83,7 @@ func collabListCommand() *cobra.Command {
 				return err
 			}
 
-			for _, c := range collabs {
-				cmd.Println(c)
-			}
-
-			return nil
+			return printNames(cmd, collabs)
 		},
 	}
 
@@ -8,6 +8,7 @@ import (
 	gansi "github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
 	"github.com/charmbracelet/soft-serve/server/ui/styles"
 	"github.com/muesli/termenv"
@@ -66,7 +67,8 @@ func commitCommand() *cobra.Command {
 			s := strings.Builder{}
 			commitLine := "commit " + commitSHA
 			authorLine := "Author: " + commit.Author.Name
-			dateLine := "Date:   " + commit.Committer.When.UTC().Format(time.UnixDate)
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			dateLine := "Date:   " + tf.Absolute(commit.Committer.When.UTC(), time.UnixDate)
 			msgLine := strings.ReplaceAll(commit.Message, "\r\n", "\n")
 			statsLine := renderStats(diff, commonStyle, color)
 			diffLine := renderDiff(patch, color)
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/spf13/cobra"
+)
+
+var completionNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// completionWrappers are the shell functions running the commands on the
+// server, by shell. The arguments are quoted since ssh joins them with
+// spaces, the completion requests have empty arguments.
+var completionWrappers = map[string]string{
+	"bash": `%[1]s() {
+	if [ $# -eq 0 ]; then
+		%[2]s
+	else
+		%[2]s "$(printf '%%q ' "$@")"
+	fi
+}
+`,
+	"zsh": `%[1]s() {
+	%[2]s "${(q)@}"
+}
+`,
+	"fish": `function %[1]s --wraps ssh
+	%[2]s (string escape -- $argv)
+end
+`,
+}
+
+// CompletionCommand returns a command that prints shell completions.
+func CompletionCommand() *cobra.Command {
+	var name string
+	cmd := &cobra.Command{
+		Use:   "completion SHELL",
+		Short: "Print the shell completion script",
+		Long: `Print a shell completion script for bash, zsh, or fish.
+
+The script defines a NAME function running the commands on this server over
+SSH, and completes its commands, flags, repositories, and users, i.e.
+"soft repo info <TAB>" lists the repositories you can read.`,
+		Example: `  # bash, in ~/.bashrc
+  source <(ssh -p 23231 localhost completion bash)
+  # zsh, in ~/.zshrc
+  source <(ssh -p 23231 localhost completion zsh)
+  # fish
+  ssh -p 23231 localhost completion fish > ~/.config/fish/conf.d/soft.fish`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !completionNameRe.MatchString(name) {
+				return usageError{fmt.Errorf("invalid name %q", name)}
+			}
+
+			sshCmd := sshCommand(config.FromContext(cmd.Context()))
+			cmd.Printf(completionWrappers[args[0]], name, sshCmd)
+			cmd.Println()
+
+			// The scripts only call "NAME __complete", which is the same for
+			// any command tree.
+			prog := &cobra.Command{Use: name}
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return prog.GenBashCompletionV2(out, true)
+			case "zsh":
+				return prog.GenZshCompletion(out)
+			default:
+				return prog.GenFishCompletion(out, true)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "soft", "name of the shell function")
+
+	return cmd
+}
+
+// RegisterCompletions completes the arguments of the commands of root from
+// their usage, the REPOSITORY, USERNAME, REFERENCE, BRANCH, TAG, and TASK
+// placeholders, and lowercase choices like [true|false]. Commands with their
+// own completion are kept, the ones creating a repository or a user complete
+// nothing.
+func RegisterCompletions(root *cobra.Command) {
+	for _, c := range root.Commands() {
+		RegisterCompletions(c)
+	}
+	if root.ValidArgsFunction != nil || root.ValidArgs != nil || root.HasSubCommands() {
+		return
+	}
+
+	if params := strings.Fields(root.Use); len(params) > 1 {
+		root.ValidArgsFunction = completeParams(params[1:])
+	}
+}
+
+// completeParams returns the completion of the arguments of a command with
+// the usage parameters params. A variadic parameter completes all the
+// remaining arguments.
+func completeParams(params []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		i := len(args)
+		if i >= len(params) {
+			i = len(params) - 1
+			if !strings.HasSuffix(params[i], "...]") && !strings.HasSuffix(params[i], "...") {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+
+		param := strings.Trim(params[i], "[].")
+		var names []string
+		switch param {
+		case "REPOSITORY", "REPO":
+			names = completeRepos(cmd)
+		case "USERNAME", "USER":
+			names = completeUsers(cmd)
+		case "BRANCH", "TAG":
+			if len(args) > 0 {
+				names = completeRefs(cmd, args[0], param == "TAG")
+			}
+		case "TASK":
+			names = completeTasks(cmd)
+		case "REFERENCE":
+			if len(args) > 0 {
+				names = append(completeRefs(cmd, args[0], false), completeRefs(cmd, args[0], true)...)
+			}
+		default:
+			for _, choice := range strings.Split(param, "|") {
+				if strings.Contains(param, "|") && choice == strings.ToLower(choice) {
+					names = append(names, choice)
+				}
+			}
+		}
+
+		completions := make([]string, 0, len(names))
+		for _, n := range names {
+			if strings.HasPrefix(n, toComplete) {
+				completions = append(completions, n)
+			}
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeRepos returns the repositories the user can read.
+func completeRepos(cmd *cobra.Command) []string {
+	ctx := cmd.Context()
+	be := backend.FromContext(ctx)
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(repos))
+	for _, r := range repos {
+		if !r.IsHidden() && accessLevel(ctx, r.Name()) >= access.ReadOnlyAccess {
+			names = append(names, r.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeUsers returns the usernames, only admins can list them.
+func completeUsers(cmd *cobra.Command) []string {
+	if err := checkIfAdmin(cmd, nil); err != nil {
+		return nil
+	}
+
+	ctx := cmd.Context()
+	users, err := backend.FromContext(ctx).Users(ctx)
+	if err != nil {
+		return nil
+	}
+	sort.Strings(users)
+	return users
+}
+
+// completeTasks returns the names of the scheduled tasks, only admins can
+// list them.
+func completeTasks(cmd *cobra.Command) []string {
+	if err := checkIfAdmin(cmd, nil); err != nil {
+		return nil
+	}
+
+	tasks := backend.FromContext(cmd.Context()).ScheduledTasks()
+	names := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// completeRefs returns the branches, or the tags, of the repository if the
+// user can read it.
+func completeRefs(cmd *cobra.Command, repo string, tags bool) []string {
+	if err := checkIfReadable(cmd, []string{repo}); err != nil {
+		return nil
+	}
+
+	ctx := cmd.Context()
+	rr, err := backend.FromContext(ctx).Repository(ctx, repo)
+	if err != nil {
+		return nil
+	}
+	r, err := rr.Open()
+	if err != nil {
+		return nil
+	}
+
+	if tags {
+		names, _ := r.Tags()
+		return names
+	}
+	names, _ := r.Branches()
+	return names
+}
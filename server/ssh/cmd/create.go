@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/initfiles"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/spf13/cobra"
 )
@@ -15,11 +17,17 @@ func createCommand() *cobra.Command {
 	var description string
 	var projectName string
 	var hidden bool
+	var template string
+	var readme bool
+	var gitignore string
+	var license string
 
 	cmd := &cobra.Command{
 		Use:               "create REPOSITORY",
 		Short:             "Create a new repository",
+		Long:              "Create a new repository. The repository is empty, unless it's created from a template, or with a README, a .gitignore template, or a license.",
 		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
 		PersistentPreRunE: checkIfCollab,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -27,12 +35,26 @@ func createCommand() *cobra.Command {
 			be := backend.FromContext(ctx)
 			user := proto.UserFromContext(ctx)
 			name := args[0]
-			r, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{
+			opts := proto.RepositoryOptions{
 				Private:     private,
 				Description: description,
 				ProjectName: projectName,
 				Hidden:      hidden,
-			})
+				Readme:      readme,
+				Gitignore:   gitignore,
+				License:     license,
+			}
+
+			var r proto.Repository
+			var err error
+			if template != "" {
+				if err := checkIfReadable(cmd, []string{template}); err != nil {
+					return err
+				}
+				r, err = be.CreateRepositoryFromTemplate(ctx, name, template, user, opts)
+			} else {
+				r, err = be.CreateRepository(ctx, name, user, opts)
+			}
 			if err != nil {
 				return err
 			}
@@ -49,6 +71,10 @@ func createCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&description, "description", "d", "", "set the repository description")
 	cmd.Flags().StringVarP(&projectName, "name", "n", "", "set the project name")
 	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repository from the UI")
+	cmd.Flags().StringVarP(&template, "template", "t", "", "create the repository from a template repository")
+	cmd.Flags().BoolVarP(&readme, "readme", "r", false, "add a README")
+	cmd.Flags().StringVarP(&gitignore, "gitignore", "g", "", "add a .gitignore template, one of "+strings.Join(initfiles.Gitignores(), ", "))
+	cmd.Flags().StringVarP(&license, "license", "l", "", "add a license, one of "+strings.Join(initfiles.Licenses(), ", "))
 
-	return cmd
+	return idempotent(cmd)
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func defaultBranchCommand() *cobra.Command {
+	var createRedirect bool
+	cmd := &cobra.Command{
+		Use:   "default-branch REPOSITORY [BRANCH]",
+		Short: "Set or get the default branch, and rename it",
+		Long: `Set or get the default branch of a repository, and the branches it was renamed from.
+
+The branch is created from the current default branch if it doesn't exist. With
+--create-redirect, the current default branch is recorded as renamed to the
+branch, and fetching it over SSH prints a hint about the new name.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			if len(args) == 2 {
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+
+				return be.SetDefaultBranch(ctx, rn, proto.UserFromContext(ctx), args[1], createRedirect)
+			}
+
+			if err := checkIfReadable(cmd, args); err != nil {
+				return err
+			}
+
+			rr, err := be.Repository(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			r, err := rr.Open()
+			if err != nil {
+				return err
+			}
+
+			head, err := r.HEAD()
+			if err != nil {
+				return err
+			}
+
+			redirects, err := be.BranchRedirects(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				return printJSON(cmd, struct {
+					DefaultBranch string            `json:"default_branch"`
+					Redirects     map[string]string `json:"redirects"`
+				}{head.Name().Short(), redirects})
+			}
+
+			cmd.Println(head.Name().Short())
+			froms := make([]string, 0, len(redirects))
+			for from := range redirects {
+				froms = append(froms, from)
+			}
+			sort.Strings(froms)
+			for _, from := range froms {
+				cmd.Printf("%s -> %s\n", from, redirects[from])
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&createRedirect, "create-redirect", false, "Record the current default branch as renamed to the branch")
+
+	return cmd
+}
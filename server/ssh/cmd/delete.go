@@ -21,5 +21,5 @@ func deleteCommand() *cobra.Command {
 		},
 	}
 
-	return cmd
+	return twoFactor(cmd, nil)
 }
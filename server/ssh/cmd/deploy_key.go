@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/spf13/cobra"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func deployKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deploy-key",
+		Aliases: []string{"deploy-keys"},
+		Short:   "Manage repository deploy keys",
+		Long:    "Manage repository deploy keys. Deploy keys are SSH keys that can clone, and optionally push to, a single repository without a user account.",
+	}
+
+	cmd.AddCommand(
+		deployKeyAddCommand(),
+		deployKeyListCommand(),
+		deployKeyRemoveCommand(),
+	)
+
+	return cmd
+}
+
+func deployKeyAddCommand() *cobra.Command {
+	var title string
+	var readWrite bool
+
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY AUTHORIZED_KEY",
+		Short:             "Add a deploy key to a repository",
+		Long:              "Add a deploy key to a repository. Deploy keys are read-only unless --read-write is set. The title defaults to the key comment.",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			pk, comment, err := sshutils.ParseAuthorizedKey(strings.Join(args[1:], " "))
+			if err != nil {
+				return usageError{err}
+			}
+
+			if title == "" {
+				title = comment
+			}
+
+			level := access.ReadOnlyAccess
+			if readWrite {
+				level = access.ReadWriteAccess
+			}
+
+			return be.AddDeployKey(ctx, args[0], title, pk, level)
+		},
+	}
+
+	cmd.Flags().StringVarP(&title, "title", "t", "", "a title to identify the key")
+	cmd.Flags().BoolVarP(&readWrite, "read-write", "w", false, "allow the key to push to the repository")
+
+	return cmd
+}
+
+func deployKeyListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the deploy keys of a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			keys, err := be.DeployKeys(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				type deployKeyJSON struct {
+					ID          int64     `json:"id"`
+					Title       string    `json:"title"`
+					Access      string    `json:"access"`
+					Fingerprint string    `json:"fingerprint"`
+					CreatedAt   time.Time `json:"created_at"`
+				}
+				list := make([]deployKeyJSON, 0, len(keys))
+				for _, k := range keys {
+					list = append(list, deployKeyJSON{
+						ID:          k.ID,
+						Title:       k.Title,
+						Access:      k.AccessLevel.String(),
+						Fingerprint: gossh.FingerprintSHA256(k.PublicKey),
+						CreatedAt:   k.CreatedAt,
+					})
+				}
+				return printJSON(cmd, list)
+			}
+
+			if len(keys) == 0 {
+				cmd.Println("No deploy keys found")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				keys,
+				[]string{"ID", "Title", "Access", "Fingerprint", "Created"},
+				func(k proto.DeployKey) ([]string, error) {
+					return []string{
+						strconv.FormatInt(k.ID, 10),
+						k.Title,
+						k.AccessLevel.String(),
+						gossh.FingerprintSHA256(k.PublicKey),
+						tf.Relative(k.CreatedAt, tokenTimeLayout),
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+func deployKeyRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY ID",
+		Aliases:           []string{"rm", "delete"},
+		Short:             "Remove a deploy key from a repository",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			id, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return err
+			}
+
+			return be.RemoveDeployKey(ctx, args[0], id)
+		},
+	}
+
+	return cmd
+}
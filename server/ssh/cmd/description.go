@@ -28,7 +28,7 @@ func descriptionCommand() *cobra.Command {
 					return err
 				}
 
-				cmd.Println(desc)
+				return printValue(cmd, desc)
 			default:
 				if err := checkIfCollab(cmd, args); err != nil {
 					return err
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+// diffCommand returns a command that prints the changes between revisions.
+func diffCommand() *cobra.Command {
+	var stat, nameOnly, color bool
+
+	cmd := &cobra.Command{
+		Use:   "diff REPOSITORY BASE..HEAD",
+		Short: "Print the changes between revisions",
+		Long: `Print the changes from the base revision to the head revision. With
+BASE...HEAD, the head revision is compared to the merge base of both, i.e.
+the changes of a branch since it diverged. An empty revision is HEAD.`,
+		Example:           "  repo diff icecream v1.0.0..v1.1.0 --stat\n  repo diff icecream main...feature",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if stat && nameOnly {
+				return usageError{fmt.Errorf("--stat and --name-only can't be used together")}
+			}
+
+			opts := git.DiffRangeOptions{Stat: stat, NameOnly: nameOnly}
+			base, head, ok := strings.Cut(args[1], "...")
+			if ok {
+				opts.MergeBase = true
+			} else if base, head, ok = strings.Cut(args[1], ".."); !ok {
+				return usageError{fmt.Errorf("invalid range %q, use BASE..HEAD or BASE...HEAD", args[1])}
+			}
+
+			rr, err := be.Repository(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			r, err := rr.Open()
+			if err != nil {
+				return err
+			}
+
+			if base, err = resolveCommit(r, base); err != nil {
+				return err
+			}
+			if head, err = resolveCommit(r, head); err != nil {
+				return err
+			}
+
+			if !color || stat || nameOnly {
+				return r.DiffRange(cmd.OutOrStdout(), base, head, opts)
+			}
+
+			var patch strings.Builder
+			if err := r.DiffRange(&patch, base, head, opts); err != nil {
+				return err
+			}
+			cmd.Println(renderDiff(patch.String(), color))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&stat, "stat", false, "only print the diffstat")
+	cmd.Flags().BoolVar(&nameOnly, "name-only", false, "only print the names of the changed files")
+	cmd.Flags().BoolVarP(&color, "color", "c", false, "Colorize output")
+
+	return cmd
+}
+
+// resolveCommit returns the ID of the commit of the revision rev, HEAD if
+// empty.
+func resolveCommit(r *git.Repository, rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	if strings.HasPrefix(rev, "-") {
+		return "", usageError{fmt.Errorf("invalid revision %q", rev)}
+	}
+
+	c, err := r.CatFileCommit(rev + "^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", git.ErrRevisionNotExist, rev)
+	}
+	return c.ID.String(), nil
+}
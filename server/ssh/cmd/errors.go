@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/charmbracelet/soft-serve/server/cron"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	gitm "github.com/gogs/git-module"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the SSH commands. These are stable and can be
+// relied on by scripts.
+const (
+	// ExitError is returned for unexpected errors.
+	ExitError = 1
+	// ExitUsage is returned when the command is called with invalid arguments
+	// or flags.
+	ExitUsage = 2
+	// ExitUnauthorized is returned when the user is not allowed to perform
+	// the action.
+	ExitUnauthorized = 3
+	// ExitNotFound is returned when a resource doesn't exist.
+	ExitNotFound = 4
+	// ExitAlreadyExists is returned when a resource already exists.
+	ExitAlreadyExists = 5
+	// ExitRejected is returned when the action is rejected by a server policy
+	// such as a branch protection rule.
+	ExitRejected = 6
+	// ExitUnavailable is returned for transient failures. The command can be
+	// retried later.
+	ExitUnavailable = 7
+	// ExitPartialFailure is returned when some of the operations of a bulk
+	// command failed.
+	ExitPartialFailure = 8
+)
+
+// Error codes used in JSON error payloads.
+const (
+	CodeInternal       = "internal"
+	CodeUsage          = "usage"
+	CodeUnauthorized   = "unauthorized"
+	CodeNotFound       = "not_found"
+	CodeAlreadyExists  = "already_exists"
+	CodeRejected       = "rejected"
+	CodeUnavailable    = "unavailable"
+	CodePartialFailure = "partial_failure"
+)
+
+// Error is a structured command error.
+type Error struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// Error implements error.
+func (e Error) Error() string {
+	return e.Message
+}
+
+// Write writes the error to w, either as a JSON object or as a plain
+// "Error: message" line.
+func (e Error) Write(w io.Writer, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(e)
+	}
+	_, err := fmt.Fprintln(w, "Error:", e.Message)
+	return err
+}
+
+// NewError classifies err and returns its structured error.
+func NewError(err error) Error {
+	e := Error{
+		Code:     CodeInternal,
+		Message:  err.Error(),
+		ExitCode: ExitError,
+	}
+
+	var cmdErr Error
+	var usageErr usageError
+	var numErr *strconv.NumError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &cmdErr):
+		return cmdErr
+	case errors.As(err, &usageErr), errors.As(err, &numErr), isCobraUsageError(err),
+		errors.Is(err, git.ErrInvalidRequest),
+		errors.Is(err, proto.ErrIdempotencyKeyReused):
+		e.Code, e.ExitCode = CodeUsage, ExitUsage
+		e.Hint = "run the command with --help to see its usage"
+	case errors.Is(err, proto.ErrUnauthorized),
+		errors.Is(err, proto.ErrTokenExpired),
+		errors.Is(err, proto.ErrKeyRestricted),
+		errors.Is(err, proto.ErrTwoFactorRequired),
+		errors.Is(err, proto.ErrInvalidTwoFactorCode),
+		errors.Is(err, git.ErrNotAuthed):
+		e.Code, e.ExitCode = CodeUnauthorized, ExitUnauthorized
+		e.Hint = "check that your key or token has access to this resource"
+	case errors.Is(err, proto.ErrRepoNotFound),
+		errors.Is(err, proto.ErrUserNotFound),
+		errors.Is(err, proto.ErrFileNotFound),
+		errors.Is(err, proto.ErrTokenNotFound),
+		errors.Is(err, proto.ErrBranchProtectionNotFound),
+		errors.Is(err, proto.ErrRefPermissionNotFound),
+		errors.Is(err, proto.ErrDeployKeyNotFound),
+		errors.Is(err, proto.ErrPublicKeyNotFound),
+		errors.Is(err, proto.ErrTwoFactorNotEnrolled),
+		errors.Is(err, proto.ErrSessionNotFound),
+		errors.Is(err, proto.ErrTimestampNotFound),
+		errors.Is(err, proto.ErrTransferNotFound),
+		errors.Is(err, proto.ErrRepoHookNotFound),
+		errors.Is(err, cron.ErrTaskNotFound),
+		errors.Is(err, git.ErrInvalidRepo),
+		errors.Is(err, gitm.ErrReferenceNotExist),
+		errors.Is(err, gitm.ErrRevisionNotExist),
+		errors.Is(err, db.ErrRecordNotFound),
+		errors.Is(err, fs.ErrNotExist):
+		e.Code, e.ExitCode = CodeNotFound, ExitNotFound
+		e.Hint = "check the spelling of the name and that you have access to it"
+	case errors.Is(err, proto.ErrRepoExist),
+		errors.Is(err, proto.ErrPublicKeyInUse),
+		errors.Is(err, proto.ErrTwoFactorEnabled),
+		errors.Is(err, proto.ErrTagExist),
+		errors.Is(err, proto.ErrBranchExist),
+		errors.Is(err, db.ErrDuplicateKey),
+		errors.Is(err, fs.ErrExist):
+		e.Code, e.ExitCode = CodeAlreadyExists, ExitAlreadyExists
+		e.Hint = "choose a different name or remove the existing one first"
+	case errors.Is(err, proto.ErrBranchProtected),
+		errors.Is(err, proto.ErrDefaultBranch),
+		errors.Is(err, proto.ErrRefRestricted),
+		errors.Is(err, proto.ErrPushRejected),
+		errors.Is(err, proto.ErrArchived):
+		e.Code, e.ExitCode = CodeRejected, ExitRejected
+		e.Hint = "ask a repository admin to review the repository settings"
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, git.ErrTimeout),
+		errors.Is(err, git.ErrMaxConnections),
+		errors.Is(err, proto.ErrRateLimited),
+		errors.Is(err, proto.ErrServerBusy),
+		errors.Is(err, cron.ErrTaskRunning),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.As(err, &netErr) && netErr.Timeout():
+		e.Code, e.ExitCode = CodeUnavailable, ExitUnavailable
+		e.Hint = "this is a temporary failure, try again later"
+	}
+
+	return e
+}
+
+// usageError is returned when a command is called with invalid arguments or
+// flags.
+type usageError struct {
+	error
+}
+
+// Unwrap returns the underlying error.
+func (e usageError) Unwrap() error {
+	return e.error
+}
+
+// isCobraUsageError reports whether err is one of the errors cobra returns
+// before a command gets to validate its arguments.
+func isCobraUsageError(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "unknown command ") ||
+		strings.HasPrefix(msg, "unknown flag: ") ||
+		strings.HasPrefix(msg, "unknown shorthand flag: ")
+}
+
+// WrapUsageErrors marks argument and flag validation errors of c and all its
+// subcommands as usage errors.
+func WrapUsageErrors(c *cobra.Command) {
+	c.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return usageError{err}
+	})
+	wrapArgs(c)
+}
+
+func wrapArgs(c *cobra.Command) {
+	if args := c.Args; args != nil {
+		c.Args = func(cmd *cobra.Command, a []string) error {
+			if err := args(cmd, a); err != nil {
+				return usageError{err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range c.Commands() {
+		wrapArgs(sub)
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+)
+
+// isEmptyRepo returns true if a repository doesn't exist or doesn't have any
+// references yet, i.e. the next push is its first one.
+func isEmptyRepo(repo proto.Repository) bool {
+	if repo == nil {
+		return true
+	}
+
+	r, err := repo.Open()
+	if err != nil {
+		return false
+	}
+
+	refs, err := r.References()
+	return err != nil || len(refs) == 0
+}
+
+// pushedRefs returns true if a push created or updated references of the
+// repository, and not only deleted them. The references are read back from
+// the repository, updates rejected by the hooks don't count.
+func pushedRefs(repo proto.Repository, updates []git.RefUpdate) bool {
+	r, err := repo.Open()
+	if err != nil {
+		return false
+	}
+
+	refs, err := r.References()
+	if err != nil {
+		return false
+	}
+
+	hashes := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		hashes[ref.Refspec] = ref.Hash.String()
+	}
+
+	for _, u := range updates {
+		if !u.IsDelete() && hashes[u.RefName] == u.NewSha {
+			return true
+		}
+	}
+
+	return false
+}
+
+// firstPushBanner returns the message shown to the user after the first push
+// of a repository: where to browse it, and what to do next.
+func firstPushBanner(cfg *config.Config, repo proto.Repository) string {
+	name := repo.Name()
+	ssh := func(args ...string) string {
+		return common.SSHCmd(cfg.SSH.PublicURL, false, args...)
+	}
+
+	visibility := fmt.Sprintf("  Make it private:       %s", ssh("repo", "private", name, "true"))
+	if repo.IsPrivate() {
+		visibility = fmt.Sprintf("  Make it public:        %s", ssh("repo", "private", name, "false"))
+	}
+
+	lines := []string{
+		"",
+		fmt.Sprintf("Pushed the first commits of %s.", name),
+		"",
+		fmt.Sprintf("  Browse it in the TUI:  %s", common.SSHCmd(cfg.SSH.PublicURL, true, name)),
+	}
+	// The web UI is only reachable when the HTTP server is public.
+	if cfg.HTTP.ListenAddr != "" && cfg.HTTP.PublicURL != "" {
+		lines = append(lines, fmt.Sprintf("  Browse it on the web:  %s/%s", strings.TrimSuffix(cfg.HTTP.PublicURL, "/"), name))
+	}
+	lines = append(lines,
+		"",
+		"Next steps:",
+		fmt.Sprintf("  Describe it:           %s", ssh("repo", "description", name, `"My project"`)),
+		fmt.Sprintf("  Add a collaborator:    %s", ssh("repo", "collab", "add", name, "USERNAME")),
+		visibility,
+		"",
+	)
+
+	return strings.Join(lines, "\n")
+}
@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -11,8 +14,10 @@ import (
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/git"
 	"github.com/charmbracelet/soft-serve/server/lfs"
+	logr "github.com/charmbracelet/soft-serve/server/log"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/stats"
 	"github.com/charmbracelet/soft-serve/server/utils"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -167,7 +172,8 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	cfg := config.FromContext(ctx)
 	be := backend.FromContext(ctx)
-	logger := log.FromContext(ctx)
+	logger := logr.WithPrefix(log.FromContext(ctx), logr.SubsystemGit+".ssh")
+	ctx = log.WithContext(ctx, logger)
 	start := time.Now()
 
 	// repo should be in the form of "repo.git"
@@ -176,11 +182,15 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 	ak := sshutils.MarshalAuthorizedKey(pk)
 	user := proto.UserFromContext(ctx)
 	accessLevel := be.AccessLevelForUser(ctx, name, user)
+	if user == nil && pk != nil {
+		// The key might be a deploy key of the repository.
+		accessLevel = be.AccessLevelByPublicKey(ctx, name, pk)
+	}
+	ctx = access.WithContext(ctx, accessLevel)
 	// git bare repositories should end in ".git"
 	// https://git-scm.com/docs/gitrepository-layout
 	repoDir := name + ".git"
-	reposDir := filepath.Join(cfg.DataPath, "repos")
-	if err := git.EnsureWithin(reposDir, repoDir); err != nil {
+	if err := git.EnsureWithin(cfg.ReposPath(), repoDir); err != nil {
 		return err
 	}
 
@@ -188,32 +198,42 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 	repo, _ := be.Repository(ctx, name)
 	ctx = proto.WithRepositoryContext(ctx, repo)
 
-	// Environment variables to pass down to git hooks.
-	envs := []string{
-		"SOFT_SERVE_REPO_NAME=" + name,
-		"SOFT_SERVE_REPO_PATH=" + filepath.Join(reposDir, repoDir),
-		"SOFT_SERVE_PUBLIC_KEY=" + ak,
-		"SOFT_SERVE_LOG_PATH=" + filepath.Join(cfg.DataPath, "log", "hooks.log"),
-	}
+	// Environment variables to pass down to git hooks, on top of the ssh
+	// session environ.
+	s := sshutils.SessionFromContext(ctx)
+	envs := sessionEnviron(s.Environ())
+	envs = append(envs,
+		"SOFT_SERVE_REPO_NAME="+name,
+		"SOFT_SERVE_REPO_PATH="+be.RepoPath(name),
+		"SOFT_SERVE_PUBLIC_KEY="+ak,
+		"SOFT_SERVE_LOG_PATH="+filepath.Join(cfg.DataPath, "log", "hooks.log"),
+		"SOFT_SERVE_REMOTE_ADDR="+s.RemoteAddr().String(),
+	)
 
 	if user != nil {
 		envs = append(envs,
 			"SOFT_SERVE_USERNAME="+user.Username(),
+			"SOFT_SERVE_ACCESS_LEVEL="+accessLevel.String(),
 		)
 	}
 
-	// Add ssh session & config environ
-	s := sshutils.SessionFromContext(ctx)
-	envs = append(envs, s.Environ()...)
+	// Add config environ
 	envs = append(envs, cfg.Environ()...)
 
-	repoPath := filepath.Join(reposDir, repoDir)
+	repoPath := be.RepoPath(name)
 	service := git.Service(cmd.Name())
-	stdin := cmd.InOrStdin()
+	switch service {
+	case git.UploadPackService, git.UploadArchiveService, git.ReceivePackService:
+		if err := be.RateLimit(ctx, backend.RateGitOperation, user, s.RemoteAddr().String()); err != nil {
+			return err
+		}
+	}
+
+	req := git.NewRequestReader(cmd.InOrStdin(), service)
 	stdout := cmd.OutOrStdout()
 	stderr := cmd.ErrOrStderr()
 	scmd := git.ServiceCommand{
-		Stdin:  stdin,
+		Stdin:  req,
 		Stdout: stdout,
 		Stderr: stderr,
 		Env:    envs,
@@ -229,14 +249,39 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 		if accessLevel < access.ReadWriteAccess {
 			return git.ErrNotAuthed
 		}
+
+		if err := be.CheckMaintenance(ctx, name); err != nil {
+			return err
+		}
+		if err := be.CheckArchived(ctx, name); err != nil {
+			return err
+		}
+
+		release, err := be.AcquireWorker(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		unlock, err := be.LockPush(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		first := isEmptyRepo(repo)
 		if repo == nil {
-			if _, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{Private: false}); err != nil {
+			if !cfg.Current().Push.AutoCreate {
+				return git.ErrInvalidRepo
+			}
+			if _, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{Private: cfg.Current().Push.AutoCreatePrivate}); err != nil {
 				log.Errorf("failed to create repo: %s", err)
 				return err
 			}
 			createRepoCounter.WithLabelValues(name).Inc()
 		}
 
+		defer stats.ObserveGit(stats.TransportSSH, service, name, &scmd)()
 		if err := service.Handler(ctx, scmd); err != nil {
 			logger.Error("failed to handle git service", "service", service, "err", err, "repo", name)
 			defer func() {
@@ -255,6 +300,15 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 		}
 
 		receivePackCounter.WithLabelValues(name).Inc()
+		be.InvalidateCache(ctx, name)
+		be.RecordTraffic(ctx, name, req.Request())
+		be.NotifyPush(ctx, name, req.Request())
+
+		if first {
+			if rr, err := be.Repository(ctx, name); err == nil && pushedRefs(rr, req.Request().Updates) {
+				fmt.Fprint(stderr, firstPushBanner(cfg, rr))
+			}
+		}
 
 		return nil
 	case git.UploadPackService, git.UploadArchiveService:
@@ -279,7 +333,18 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			}()
 		}
 
-		err := service.Handler(ctx, scmd)
+		release, err := be.AcquireWorker(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		if service == git.UploadPackService {
+			printHints(stderr, be.BranchRedirectHints(ctx, name, nil))
+		}
+
+		defer stats.ObserveGit(stats.TransportSSH, service, name, &scmd)()
+		err = service.Handler(ctx, scmd)
 		if errors.Is(err, git.ErrInvalidRepo) {
 			return git.ErrInvalidRepo
 		} else if err != nil {
@@ -287,6 +352,12 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			return git.ErrSystemMalfunction
 		}
 
+		gr := req.Request()
+		if service == git.UploadPackService {
+			printHints(stderr, be.BranchRedirectHints(ctx, name, &gr))
+		}
+		be.RecordTraffic(ctx, name, gr)
+
 		return nil
 	case git.LFSTransferService, git.LFSAuthenticateService:
 		operation := args[1]
@@ -299,6 +370,12 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			if accessLevel < access.ReadWriteAccess {
 				return git.ErrNotAuthed
 			}
+			if err := be.CheckMaintenance(ctx, name); err != nil {
+				return err
+			}
+			if err := be.CheckArchived(ctx, name); err != nil {
+				return err
+			}
 		default:
 			return git.ErrInvalidRequest
 		}
@@ -314,6 +391,11 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 
 		switch service {
 		case git.LFSTransferService:
+			if operation == lfs.OperationUpload {
+				scmd.CheckQuota = func(size int64) error {
+					return be.CheckDiskQuota(ctx, name, size)
+				}
+			}
 			lfsTransferCounter.WithLabelValues(name, operation).Inc()
 			defer func() {
 				lfsTransferSeconds.WithLabelValues(name, operation).Add(time.Since(start).Seconds())
@@ -335,3 +417,25 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 
 	return errors.New("unsupported git service")
 }
+
+// printHints writes hints for a git client, which shows them to the user.
+// sessionEnviron returns the environ of an ssh session without the
+// SOFT_SERVE_ variables. Git hooks trust these to know the user and the
+// repository of a push, clients must not be able to set them.
+func sessionEnviron(environ []string) []string {
+	envs := make([]string, 0, len(environ))
+	for _, env := range environ {
+		if strings.HasPrefix(strings.ToUpper(env), "SOFT_SERVE_") {
+			continue
+		}
+		envs = append(envs, env)
+	}
+
+	return envs
+}
+
+func printHints(w io.Writer, hints []string) {
+	for _, h := range hints {
+		fmt.Fprintln(w, "hint: "+h)
+	}
+}
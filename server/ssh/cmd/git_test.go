@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSessionEnviron(t *testing.T) {
+	is := is.New(t)
+
+	// Clients can send environment variables with SetEnv, the ones git
+	// hooks trust to know who pushes must never make it to the hooks.
+	envs := sessionEnviron([]string{
+		"GIT_PROTOCOL=version=2",
+		"SOFT_SERVE_ACCESS_LEVEL=admin-access",
+		"SOFT_SERVE_USERNAME=admin",
+		"soft_serve_username=admin",
+		"TERM=xterm",
+	})
+	is.Equal(envs, []string{"GIT_PROTOCOL=version=2", "TERM=xterm"})
+}
+
+func TestSessionEnvironRepo(t *testing.T) {
+	is := is.New(t)
+
+	// The hooks check the pushes against the repository they are given,
+	// clients must not be able to point them at another one.
+	envs := sessionEnviron([]string{
+		"SOFT_SERVE_REPO_NAME=other",
+		"SOFT_SERVE_REPO_PATH=/tmp/other",
+		"GIT_PROTOCOL=version=2",
+	})
+	is.Equal(envs, []string{"GIT_PROTOCOL=version=2"})
+}
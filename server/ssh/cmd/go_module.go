@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+func goModuleCommand() *cobra.Command {
+	var unset bool
+	cmd := &cobra.Command{
+		Use:   "go-module REPOSITORY [PATH]",
+		Short: "Set or get the Go import path of a repository",
+		Long: `Set or get the Go import path of a repository.
+
+The path is relative to the Go import domain of the server, it defaults to the
+repository name.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch {
+			case unset:
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+
+				return be.SetGoModule(ctx, rn, "")
+			case len(args) == 1:
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				p, err := be.GoImportPath(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				return printValue(cmd, p)
+			default:
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+
+				return be.SetGoModule(ctx, rn, strings.Trim(args[1], "/"))
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&unset, "unset", "u", false, "use the repository name")
+
+	return cmd
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+// grepCommand returns a command that searches the files of a repository.
+func grepCommand() *cobra.Command {
+	var ref string
+	var exts []string
+	var opts git.GrepOptions
+
+	cmd := &cobra.Command{
+		Use:   "grep REPOSITORY PATTERN",
+		Short: "Search the files of a repository",
+		Long: `Search the files of a repository at a reference for the lines matching an
+extended regular expression, and print them as FILE:LINE:MATCH. Binary files
+are skipped.`,
+		Example:           "  repo grep icecream 'TODO|FIXME' --ext go --ref v1.0.0",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			name := args[0]
+			if opts.Limit < 0 {
+				return usageError{fmt.Errorf("invalid --limit %d", opts.Limit)}
+			}
+
+			rr, err := be.Repository(ctx, name)
+			if err != nil {
+				return err
+			}
+
+			r, err := rr.Open()
+			if err != nil {
+				return err
+			}
+
+			rev, err := resolveCommit(r, ref)
+			if err != nil {
+				return err
+			}
+
+			release, err := be.AcquireWorker(ctx, name)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			opts.Extensions = exts
+			matches, more, err := r.Grep(ctx, rev, args[1], opts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				type matchJSON struct {
+					Path    string `json:"path"`
+					Line    int    `json:"line"`
+					Content string `json:"content"`
+				}
+				out := make([]matchJSON, 0, len(matches))
+				for _, m := range matches {
+					out = append(out, matchJSON{m.Path, m.Line, m.Content})
+				}
+				if err := printJSON(cmd, out); err != nil {
+					return err
+				}
+			} else {
+				for _, m := range matches {
+					cmd.Printf("%s:%d:%s\n", m.Path, m.Line, m.Content)
+				}
+			}
+
+			if more {
+				cmd.PrintErrf("Only the first %d matches are shown, use --limit to show more\n", opts.Limit)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&ref, "ref", "r", "", "reference to search, the default branch if empty")
+	cmd.Flags().BoolVarP(&opts.IgnoreCase, "ignore-case", "i", false, "ignore the case of the letters")
+	cmd.Flags().StringSliceVarP(&exts, "ext", "e", nil, "only search the files with these extensions, i.e. go,md")
+	cmd.Flags().IntVarP(&opts.Limit, "limit", "n", 1000, "maximum number of matches, 0 for all")
+
+	return cmd
+}
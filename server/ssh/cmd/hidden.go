@@ -26,7 +26,7 @@ func hiddenCommand() *cobra.Command {
 					return err
 				}
 
-				cmd.Println(hidden)
+				return printValue(cmd, hidden)
 			case 2:
 				if err := checkIfCollab(cmd, args); err != nil {
 					return err
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+// hooksCommand returns the command managing the hook scripts of a
+// repository. The scripts run on the server, only admins can manage them.
+func hooksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage the hook scripts of a repository",
+		Long: `Manage the server-side hook scripts of a repository, run on pushes after the
+hooks of Soft Serve. A failing pre-receive or update script rejects the push.
+Scripts run in a temporary directory, with a minimal environment, a timeout,
+and CPU and memory limits. Supported hooks: ` + strings.Join(backend.RepoHookNames, ", ") + ".",
+		Example:           "  repo hooks set icecream pre-receive < pre-receive.sh\n  repo hooks logs icecream",
+		PersistentPreRunE: checkIfAdmin,
+	}
+
+	cmd.AddCommand(
+		hooksListCommand(),
+		hooksLogsCommand(),
+		hooksRemoveCommand(),
+		hooksSetCommand(),
+	)
+
+	return cmd
+}
+
+func hooksListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list REPOSITORY",
+		Aliases: []string{"ls"},
+		Short:   "List the hook scripts of a repository",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			hks, err := be.RepoHooks(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				type hookJSON struct {
+					Name      string    `json:"name"`
+					Size      int64     `json:"size"`
+					UpdatedAt time.Time `json:"updated_at"`
+				}
+				list := make([]hookJSON, 0, len(hks))
+				for _, h := range hks {
+					list = append(list, hookJSON{h.Name, h.Size, h.UpdatedAt})
+				}
+				return printJSON(cmd, list)
+			}
+
+			if len(hks) == 0 {
+				cmd.Println("No hooks")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				hks,
+				[]string{"Hook", "Size", "Updated"},
+				func(h proto.RepoHook) ([]string, error) {
+					return []string{
+						h.Name,
+						humanize.IBytes(uint64(h.Size)),
+						tf.Relative(h.UpdatedAt, tokenTimeLayout),
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+func hooksSetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set REPOSITORY HOOK",
+		Short: "Install the hook script of a repository, read from stdin",
+		Long: `Install the hook script of a repository, read from stdin, replacing the
+previous one. Scripts without a shebang run with sh.`,
+		Example:           "  repo hooks set icecream pre-receive < pre-receive.sh",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeParams([]string{"REPOSITORY", "HOOK"}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			script, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			return be.SetRepoHook(ctx, args[0], args[1], script)
+		},
+	}
+
+	return cmd
+}
+
+func hooksRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY HOOK",
+		Aliases:           []string{"rm"},
+		Short:             "Remove the hook script of a repository",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeParams([]string{"REPOSITORY", "HOOK"}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.RemoveRepoHook(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func hooksLogsCommand() *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "logs REPOSITORY",
+		Short: "Show the most recent runs of the hook scripts of a repository",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			runs, err := be.RepoHookRuns(ctx, args[0], limit)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				type runJSON struct {
+					Hook     string    `json:"hook"`
+					Username string    `json:"username,omitempty"`
+					ExitCode int       `json:"exit_code"`
+					Duration int64     `json:"duration_ms"`
+					Output   string    `json:"output"`
+					Created  time.Time `json:"created_at"`
+				}
+				list := make([]runJSON, 0, len(runs))
+				for _, r := range runs {
+					list = append(list, runJSON{r.Hook, r.Username, r.ExitCode, r.Duration.Milliseconds(), r.Output, r.CreatedAt})
+				}
+				return printJSON(cmd, list)
+			}
+
+			if len(runs) == 0 {
+				cmd.Println("No hook runs")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			for i, r := range runs {
+				if i > 0 {
+					cmd.Println()
+				}
+				status := "ok"
+				if r.ExitCode != 0 {
+					status = fmt.Sprintf("exit %d", r.ExitCode)
+				}
+				cmd.Printf("%s %s by %s: %s in %s\n", tf.Absolute(r.CreatedAt, auditTimeLayout), r.Hook,
+					orDash(r.Username), status, r.Duration)
+				for _, line := range strings.Split(strings.TrimRight(r.Output, "\n"), "\n") {
+					if line != "" {
+						cmd.Println("  " + line)
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "maximum number of most recent runs, 0 for all")
+
+	return cmd
+}
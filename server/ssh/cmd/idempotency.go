@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const idempotencyKeyFlag = "idempotency-key"
+
+// idempotent makes a command safe to retry. It adds an --idempotency-key flag
+// to cmd and wraps its RunE so that running the command again with the same
+// key prints the output of the first run instead of running it twice.
+func idempotent(cmd *cobra.Command) *cobra.Command {
+	var key string
+	cmd.Flags().StringVar(&key, idempotencyKeyFlag, "", "a unique key used to safely retry the command")
+
+	runE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		be := backend.FromContext(ctx)
+		user := proto.UserFromContext(ctx)
+		out := cmd.OutOrStdout()
+		resp, err := be.Idempotent(ctx, user, key, idempotencyRequest(cmd, args), func() (string, error) {
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			defer cmd.SetOut(out)
+			err := runE(cmd, args)
+			return buf.String(), err
+		})
+		if _, err := io.WriteString(out, resp); err != nil {
+			return err
+		}
+
+		return err
+	}
+
+	return cmd
+}
+
+// idempotencyRequest returns a description of the command invocation used to
+// detect idempotency keys reused for different requests.
+func idempotencyRequest(cmd *cobra.Command, args []string) string {
+	parts := []string{cmd.CommandPath()}
+	parts = append(parts, args...)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name != idempotencyKeyFlag && f.Name != twoFactorFlag {
+			parts = append(parts, fmt.Sprintf("--%s=%s", f.Name, f.Value))
+		}
+	})
+
+	return strings.Join(parts, " ")
+}
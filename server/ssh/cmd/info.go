@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/sshutils"
 	"github.com/spf13/cobra"
 )
@@ -15,10 +16,21 @@ func InfoCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			pk := sshutils.PublicKeyFromContext(ctx)
-			user, err := be.UserByPublicKey(ctx, pk)
-			if err != nil {
-				return err
+			user := proto.UserFromContext(ctx)
+			if user == nil {
+				var err error
+				user, err = be.UserByPublicKey(ctx, sshutils.PublicKeyFromContext(ctx))
+				if err != nil {
+					return err
+				}
+			}
+
+			if jsonOutput(cmd) {
+				return printJSON(cmd, struct {
+					Username   string   `json:"username"`
+					Admin      bool     `json:"admin"`
+					PublicKeys []string `json:"public_keys"`
+				}{user.Username(), user.IsAdmin(), publicKeysJSON(user.PublicKeys())})
 			}
 
 			cmd.Printf("Username: %s\n", user.Username())
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+// jobsCommand returns the command to manage the job queue.
+func jobsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Manage the job queue",
+		Long: `Manage the queue of the background jobs, the webhook deliveries, the mirror
+updates, and the LFS pruning. Failed jobs are retried until they run out of
+attempts, they're dead then and kept until they're retried.`,
+		Example:           "  admin jobs list --status dead\n  admin jobs retry 42",
+		PersistentPreRunE: checkIfAdmin,
+	}
+
+	cmd.AddCommand(
+		jobsListCommand(),
+		jobsRetryCommand(),
+	)
+
+	return cmd
+}
+
+func jobsListCommand() *cobra.Command {
+	var status string
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the queued jobs",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			var st proto.JobStatus
+			if status != "" {
+				var err error
+				st, err = proto.ParseJobStatus(status)
+				if err != nil {
+					return err
+				}
+			}
+
+			jobs, err := be.Jobs(ctx, st)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				type jobJSON struct {
+					ID          int64           `json:"id"`
+					Kind        string          `json:"kind"`
+					Status      proto.JobStatus `json:"status"`
+					Attempts    int             `json:"attempts"`
+					MaxAttempts int             `json:"max_attempts"`
+					LastError   string          `json:"last_error,omitempty"`
+					RunAt       time.Time       `json:"run_at"`
+					CreatedAt   time.Time       `json:"created_at"`
+				}
+				list := make([]jobJSON, 0, len(jobs))
+				for _, j := range jobs {
+					list = append(list, jobJSON{
+						ID:          j.ID,
+						Kind:        j.Kind,
+						Status:      j.Status,
+						Attempts:    j.Attempts,
+						MaxAttempts: j.MaxAttempts,
+						LastError:   j.LastError,
+						RunAt:       j.RunAt,
+						CreatedAt:   j.CreatedAt,
+					})
+				}
+				return printJSON(cmd, list)
+			}
+
+			if len(jobs) == 0 {
+				cmd.Println("No jobs found")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				jobs,
+				[]string{"ID", "Kind", "Status", "Attempts", "Run At", "Last Error"},
+				func(j proto.Job) ([]string, error) {
+					return []string{
+						strconv.FormatInt(j.ID, 10),
+						j.Kind,
+						string(j.Status),
+						strconv.Itoa(j.Attempts) + "/" + strconv.Itoa(j.MaxAttempts),
+						tf.Relative(j.RunAt, tokenTimeLayout),
+						orDash(strings.SplitN(j.LastError, "\n", 2)[0]),
+					}, nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().StringVarP(&status, "status", "s", "", "only list the jobs with a status, pending, running, or dead")
+
+	return cmd
+}
+
+func jobsRetryCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "retry ID",
+		Short: "Queue a dead job again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return proto.ErrJobNotFound
+			}
+
+			if err := be.RetryJob(ctx, id); err != nil {
+				return err
+			}
+
+			cmd.Printf("Queued job %d again\n", id)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/caarlos0/duration"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func lfsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lfs",
+		Short: "Manage Git LFS objects",
+	}
+
+	cmd.AddCommand(
+		lfsStatusCommand(),
+		lfsPruneCommand(),
+	)
+
+	return cmd
+}
+
+func lfsStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "status REPOSITORY",
+		Short:             "Show the Git LFS storage usage of a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rr, err := be.Repository(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			status, err := be.LFSStatus(ctx, rr)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Objects: %d\n", status.Objects)
+			cmd.Printf("Size: %s\n", humanize.IBytes(uint64(status.Size)))
+			cmd.Printf("Unreachable: %d (%s)\n", status.Unreachable, humanize.IBytes(uint64(status.UnreachableSize)))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func lfsPruneCommand() *cobra.Command {
+	var dryRun bool
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:               "prune REPOSITORY",
+		Short:             "Remove Git LFS objects that aren't referenced by any ref",
+		Long:              "Remove Git LFS objects that aren't referenced by any ref and were uploaded before the retention window. The window defaults to the lfs.prune_retention_days setting.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			be := backend.FromContext(ctx)
+			retention := time.Duration(cfg.LFS.PruneRetentionDays) * 24 * time.Hour
+			if olderThan != "" {
+				d, err := duration.Parse(olderThan)
+				if err != nil {
+					return usageError{err}
+				}
+
+				retention = d
+			}
+
+			rr, err := be.Repository(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			objs, err := be.PruneLFSObjects(ctx, rr, retention, dryRun)
+			if err != nil {
+				return err
+			}
+
+			var size int64
+			for _, obj := range objs {
+				size += obj.Size
+				cmd.Printf("%s\t%s\n", obj.Oid, humanize.IBytes(uint64(obj.Size)))
+			}
+
+			verb := "Pruned"
+			if dryRun {
+				verb = "Would prune"
+			}
+
+			cmd.PrintErrf("%s %d objects (%s)\n", verb, len(objs), humanize.IBytes(uint64(size)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "only list the objects that would be removed")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "only remove objects uploaded before this long ago (e.g. 2w, 3d, 12h)")
+
+	return cmd
+}
@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"sort"
+
+	"github.com/caarlos0/tablewriter"
 	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/backend"
-	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 )
 
 // listCommand returns a command that list file or directory at path.
 func listCommand() *cobra.Command {
-	var all bool
+	var all, usage bool
+	var list listFlags
 
 	listCmd := &cobra.Command{
 		Use:     "list",
@@ -19,23 +24,116 @@ func listCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			pk := sshutils.PublicKeyFromContext(ctx)
+			key, reverse, err := list.parse()
+			if err != nil {
+				return err
+			}
+			if usage {
+				if err := checkIfAdmin(cmd, nil); err != nil {
+					return err
+				}
+			}
+
 			repos, err := be.Repositories(ctx)
 			if err != nil {
 				return err
 			}
+
+			shown := make([]proto.Repository, 0, len(repos))
 			for _, r := range repos {
-				if be.AccessLevelByPublicKey(ctx, r.Name(), pk) >= access.ReadOnlyAccess {
-					if !r.IsHidden() || all {
-						cmd.Println(r.Name())
-					}
+				if (!r.IsHidden() || all) && list.match(r.Name()) &&
+					accessLevel(ctx, r.Name()) >= access.ReadOnlyAccess {
+					shown = append(shown, r)
 				}
 			}
+
+			sort.SliceStable(shown, func(i, j int) bool {
+				if reverse {
+					i, j = j, i
+				}
+				switch key {
+				case "name":
+					return shown[i].Name() < shown[j].Name()
+				case "updated":
+					return shown[i].UpdatedAt().Before(shown[j].UpdatedAt())
+				default:
+					return shown[i].ID() < shown[j].ID()
+				}
+			})
+			start, end := list.bounds(len(shown))
+			shown = shown[start:end]
+
+			if usage {
+				return listUsage(cmd, shown)
+			}
+			if jsonOutput(cmd) {
+				out := make([]repoJSON, 0, len(shown))
+				for _, r := range shown {
+					out = append(out, newRepoJSON(r))
+				}
+				return printJSON(cmd, out)
+			}
+			for _, r := range shown {
+				cmd.Println(r.Name())
+			}
 			return nil
 		},
 	}
 
 	listCmd.Flags().BoolVarP(&all, "all", "a", false, "List all repositories")
+	listCmd.Flags().BoolVarP(&usage, "usage", "u", false, "Show the disk usage and the quota of the repositories (admins only)")
+	list.register(listCmd, "name", "created", "updated")
 
 	return listCmd
 }
+
+// listUsage prints the repositories with their disk usage and quota.
+func listUsage(cmd *cobra.Command, shown []proto.Repository) error {
+	ctx := cmd.Context()
+	be := backend.FromContext(ctx)
+	usages, err := be.RepoDiskUsages(ctx)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput(cmd) {
+		type usageJSON struct {
+			Name  string `json:"name"`
+			Usage *int64 `json:"usage"`
+			Quota int64  `json:"quota"`
+		}
+		list := make([]usageJSON, 0, len(shown))
+		for _, r := range shown {
+			quota, err := be.RepoDiskQuota(ctx, r.Name())
+			if err != nil {
+				return err
+			}
+			u := usageJSON{Name: r.Name(), Quota: quota}
+			if du, ok := usages[r.ID()]; ok {
+				total := du.Total()
+				u.Usage = &total
+			}
+			list = append(list, u)
+		}
+		return printJSON(cmd, list)
+	}
+
+	return tablewriter.Render(
+		cmd.OutOrStdout(),
+		shown,
+		[]string{"Name", "Usage", "Quota"},
+		func(r proto.Repository) ([]string, error) {
+			quota, err := be.RepoDiskQuota(ctx, r.Name())
+			if err != nil {
+				return nil, err
+			}
+
+			used := "-"
+			if u, ok := usages[r.ID()]; ok {
+				used = humanize.IBytes(uint64(u.Total()))
+			}
+
+			return []string{r.Name(), used, sizeString(quota)}, nil
+		},
+	)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+// maintenanceCommand returns the command to manage the maintenance mode.
+func maintenanceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Manage the maintenance mode",
+		Long: `Make the server, or a single repository, read-only for backups and migrations.
+Pushes are rejected with the maintenance message, fetches keep working.`,
+		Example:           "  admin maintenance on --message \"Back at 10:00 UTC\"\n  admin maintenance on icecream\n  admin maintenance off",
+		PersistentPreRunE: checkIfAdmin,
+	}
+
+	cmd.AddCommand(
+		maintenanceStatusCommand(),
+		maintenanceOnCommand(),
+		maintenanceOffCommand(),
+	)
+
+	return cmd
+}
+
+func maintenanceStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [REPOSITORY]",
+		Short: "Show the maintenance mode of the server or a repository",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			var m *proto.Maintenance
+			var err error
+			if len(args) > 0 {
+				m, err = be.RepoMaintenance(ctx, args[0])
+			} else {
+				m, err = be.Maintenance(ctx)
+			}
+			if err != nil {
+				return err
+			}
+
+			if m == nil {
+				cmd.Println("Maintenance: off")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			cmd.Println("Maintenance: on")
+			cmd.Println("Since:", tf.Relative(m.Since, time.RFC1123))
+			if m.Message != "" {
+				cmd.Println("Message:", m.Message)
+			}
+			return nil
+		},
+	}
+}
+
+func maintenanceOnCommand() *cobra.Command {
+	var message string
+	cmd := &cobra.Command{
+		Use:   "on [REPOSITORY]",
+		Short: "Turn the maintenance mode of the server or a repository on",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			var repo string
+			if len(args) > 0 {
+				repo = args[0]
+			}
+
+			return be.SetMaintenance(ctx, repo, &proto.Maintenance{Message: message})
+		},
+	}
+
+	cmd.Flags().StringVarP(&message, "message", "m", "", "message shown to the users")
+
+	return cmd
+}
+
+func maintenanceOffCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "off [REPOSITORY]",
+		Short: "Turn the maintenance mode of the server or a repository off",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			var repo string
+			if len(args) > 0 {
+				repo = args[0]
+			}
+
+			return be.SetMaintenance(ctx, repo, nil)
+		},
+	}
+}
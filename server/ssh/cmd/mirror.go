@@ -20,9 +20,7 @@ func mirrorCommand() *cobra.Command {
 				return err
 			}
 
-			isMirror := rr.IsMirror()
-			cmd.Println(isMirror)
-			return nil
+			return printValue(cmd, rr.IsMirror())
 		},
 	}
 
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+// motdCommand returns the command to manage the message of the day.
+func motdCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "motd",
+		Short: "Manage the message of the day",
+		Long: `Manage the message of the day, markdown shown at the top of the TUI.
+Users can dismiss it until it changes.`,
+		Example:           "  admin motd set Welcome to **Soft Serve**!\n  admin motd set - < motd.md\n  admin motd clear",
+		PersistentPreRunE: checkIfAdmin,
+	}
+
+	cmd.AddCommand(
+		motdShowCommand(),
+		motdSetCommand(),
+		motdClearCommand(),
+	)
+
+	return cmd
+}
+
+func motdShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the message of the day",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			motd, err := be.MOTD(ctx)
+			if err != nil {
+				return err
+			}
+
+			if motd != "" {
+				cmd.Println(motd)
+			}
+			return nil
+		},
+	}
+}
+
+func motdSetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set MARKDOWN...",
+		Short: "Set the message of the day",
+		Long:  `Set the message of the day. A "-" reads it from the standard input.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			motd := strings.Join(args, " ")
+			if motd == "-" {
+				b, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				motd = string(b)
+			}
+
+			return be.SetMOTD(ctx, motd)
+		},
+	}
+}
+
+func motdClearCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Clear the message of the day",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.SetMOTD(ctx, "")
+		},
+	}
+}
+
+// wallCommand returns the command to broadcast a message to the connected
+// sessions.
+func wallCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "wall MESSAGE...",
+		Short:             "Send a message to the connected sessions",
+		Long:              `Send a message to the TUIs of the connected sessions, like an upcoming restart.`,
+		Example:           "  admin wall Restarting in 5 minutes",
+		Args:              cobra.MinimumNArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			be.Broadcast(ctx, strings.Join(args, " "))
+			return nil
+		},
+	}
+}
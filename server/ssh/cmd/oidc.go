@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+// userOIDCCommand returns a command that links OpenID Connect identities to
+// users.
+func userOIDCCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "oidc",
+		Short: "Manage the OpenID Connect identities of users",
+		Long: `Manage the OpenID Connect identities of users.
+
+An identity logs in as the user it's linked to. Identities are linked by their
+subject, which is shown to users whose identity isn't linked yet when they log
+in.`,
+	}
+
+	linkCmd := &cobra.Command{
+		Use:               "link USERNAME SUBJECT",
+		Short:             "Link an OpenID Connect identity to a user",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user, err := be.User(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			return be.LinkIdentity(ctx, user, args[1])
+		},
+	}
+	twoFactor(linkCmd, nil)
+
+	unlinkCmd := &cobra.Command{
+		Use:               "unlink USERNAME",
+		Short:             "Unlink the OpenID Connect identity of a user",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user, err := be.User(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			return be.UnlinkIdentity(ctx, user)
+		},
+	}
+
+	cmd.AddCommand(linkCmd, unlinkCmd)
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// jsonOutput returns true if the command was run with the global --json
+// flag. Commands print a single JSON document then, an array for lists and
+// an object for everything else, with snake_case keys.
+func jsonOutput(cmd *cobra.Command) bool {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	return asJSON
+}
+
+// printJSON prints v to the command output as JSON.
+func printJSON(cmd *cobra.Command, v interface{}) error {
+	return json.NewEncoder(cmd.OutOrStdout()).Encode(v)
+}
+
+// printValue prints a single value, as a JSON value with --json.
+func printValue(cmd *cobra.Command, v interface{}) error {
+	if jsonOutput(cmd) {
+		return printJSON(cmd, v)
+	}
+
+	cmd.Println(v)
+	return nil
+}
+
+// printNames prints names one per line, as a JSON array with --json.
+func printNames(cmd *cobra.Command, names []string) error {
+	if jsonOutput(cmd) {
+		if names == nil {
+			names = []string{}
+		}
+		return printJSON(cmd, names)
+	}
+
+	for _, n := range names {
+		cmd.Println(n)
+	}
+	return nil
+}
+
+// jsonTime returns t for an optional JSON time, nil if t is zero.
+func jsonTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
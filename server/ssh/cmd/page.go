@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// listFlags are the flags filtering, sorting, and paginating a list.
+type listFlags struct {
+	filter string
+	sort   string
+	limit  int
+	page   int
+	sorts  []string
+}
+
+// register adds the flags to cmd. The first of sorts is the default order.
+func (f *listFlags) register(cmd *cobra.Command, sorts ...string) {
+	f.sorts = sorts
+	cmd.Flags().StringVarP(&f.filter, "filter", "f", "", "only list names containing this text, or matching this pattern, i.e. team/*")
+	cmd.Flags().StringVar(&f.sort, "sort", sorts[0], fmt.Sprintf("sort by %s, prefix with - to reverse", strings.Join(sorts, ", ")))
+	cmd.Flags().IntVarP(&f.limit, "limit", "n", 0, "maximum number of entries, 0 for all")
+	cmd.Flags().IntVar(&f.page, "page", 1, "page of --limit entries to list")
+}
+
+// parse validates the flags and returns the sort key, and whether the order
+// is reversed.
+func (f *listFlags) parse() (string, bool, error) {
+	key := strings.TrimPrefix(f.sort, "-")
+	valid := false
+	for _, s := range f.sorts {
+		valid = valid || s == key
+	}
+	if !valid {
+		return "", false, usageError{fmt.Errorf("invalid --sort %q, use one of %s", f.sort, strings.Join(f.sorts, ", "))}
+	}
+	if f.limit < 0 {
+		return "", false, usageError{fmt.Errorf("invalid --limit %d", f.limit)}
+	}
+	if f.page < 1 {
+		return "", false, usageError{fmt.Errorf("invalid --page %d", f.page)}
+	}
+	if f.page > 1 && f.limit == 0 {
+		return "", false, usageError{fmt.Errorf("--page requires --limit")}
+	}
+	if _, err := path.Match(f.filter, ""); err != nil {
+		return "", false, usageError{fmt.Errorf("invalid --filter: %w", err)}
+	}
+
+	return key, strings.HasPrefix(f.sort, "-"), nil
+}
+
+// match returns true if name passes the filter. Filters without wildcards
+// match the names containing them, case insensitively.
+func (f *listFlags) match(name string) bool {
+	if f.filter == "" {
+		return true
+	}
+	if strings.ContainsAny(f.filter, "*?[") {
+		ok, _ := path.Match(f.filter, name)
+		return ok
+	}
+	return strings.Contains(strings.ToLower(name), strings.ToLower(f.filter))
+}
+
+// bounds returns the range of the page in a list of n entries.
+func (f *listFlags) bounds(n int) (int, int) {
+	if f.limit == 0 {
+		return 0, n
+	}
+
+	start := (f.page - 1) * f.limit
+	if start > n {
+		start = n
+	}
+	end := start + f.limit
+	if end > n {
+		end = n
+	}
+	return start, end
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func permsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "perms REPOSITORY",
+		Aliases: []string{"permissions"},
+		Short:   "Manage who can push to repository references",
+		Long: `Manage who can push to repository references.
+
+Once a reference matches a pattern, only admins and the users granted a
+matching pattern can push to it. Patterns are full reference names or globs,
+i.e. refs/heads/release/* or refs/tags/v*. Patterns that don't start with
+refs/ match branches.`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			perms, err := be.RefPermissions(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			if len(perms) == 0 {
+				cmd.Println("No reference permissions")
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				perms,
+				[]string{"Pattern", "Push Users"},
+				func(p proto.RefPermission) ([]string, error) {
+					return []string{
+						p.Pattern,
+						strings.Join(p.Users, ", "),
+					}, nil
+				},
+			)
+		},
+	}
+
+	cmd.AddCommand(
+		permsGrantCommand(),
+		permsRevokeCommand(),
+	)
+
+	return cmd
+}
+
+func permsGrantCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "grant REPOSITORY PATTERN USER...",
+		Aliases:           []string{"add"},
+		Short:             "Allow users to push to references matching a pattern",
+		Args:              cobra.MinimumNArgs(3),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			return be.GrantRefPermission(ctx, rn, args[1], args[2:]...)
+		},
+	}
+
+	return cmd
+}
+
+func permsRevokeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "revoke REPOSITORY PATTERN [USER...]",
+		Aliases:           []string{"remove", "rm", "del"},
+		Short:             "Disallow users to push to references matching a pattern",
+		Long:              "Disallow users to push to references matching a pattern. Without users, the pattern is removed and anyone with write access can push to matching references again.",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			return be.RevokeRefPermission(ctx, rn, args[1], args[2:]...)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+// PreferencesCommand returns a command that manages the user's preferences.
+func PreferencesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "preferences",
+		Aliases: []string{"prefs"},
+		Short:   "Manage your preferences",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "presence [true|false]",
+			Short: "Set or get whether others can see you browsing the same repository",
+			Args:  cobra.RangeArgs(0, 1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+				user := proto.UserFromContext(ctx)
+				if user == nil {
+					return proto.ErrUserNotFound
+				}
+
+				switch len(args) {
+				case 0:
+					cmd.Println(be.Presence(ctx, user))
+				case 1:
+					v, err := strconv.ParseBool(args[0])
+					if err != nil {
+						return err
+					}
+					if err := be.SetPresence(ctx, user, v); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "date-format [default|relative|absolute]",
+			Short: "Set or get how dates are displayed",
+			Args:  cobra.RangeArgs(0, 1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+				user := proto.UserFromContext(ctx)
+				if user == nil {
+					return proto.ErrUserNotFound
+				}
+
+				switch len(args) {
+				case 0:
+					cmd.Println(be.TimeFormat(ctx, user).Style)
+				case 1:
+					ds, err := proto.ParseDateStyle(args[0])
+					if err != nil {
+						return err
+					}
+					if err := be.SetDateStyle(ctx, user, ds); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "timezone [default|TIMEZONE]",
+			Short: "Set or get the time zone dates are displayed in",
+			Long: `Set or get the time zone dates are displayed in.
+
+TIMEZONE is a name from the IANA Time Zone database, such as "UTC" or
+"Europe/Paris". Use "default" to display dates in their own time zone.`,
+			Args: cobra.RangeArgs(0, 1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+				user := proto.UserFromContext(ctx)
+				if user == nil {
+					return proto.ErrUserNotFound
+				}
+
+				switch len(args) {
+				case 0:
+					tz := "default"
+					if loc := be.TimeFormat(ctx, user).Location; loc != nil {
+						tz = loc.String()
+					}
+					cmd.Println(tz)
+				case 1:
+					tz := args[0]
+					if tz == "default" {
+						tz = ""
+					}
+					if err := be.SetTimezone(ctx, user, tz); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "email [none|EMAIL]",
+			Short: "Set or get the email address your avatar is found with",
+			Long: `Set or get the email address your avatar is found with.
+
+The avatar of the address is fetched from the avatar provider of the server
+when you haven't uploaded an image. Use "none" to remove the address.`,
+			Args: cobra.RangeArgs(0, 1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+				user := proto.UserFromContext(ctx)
+				if user == nil {
+					return proto.ErrUserNotFound
+				}
+
+				switch len(args) {
+				case 0:
+					email, err := be.Email(ctx, user)
+					if err != nil {
+						return err
+					}
+					if email == "" {
+						email = "none"
+					}
+					cmd.Println(email)
+				case 1:
+					email := args[0]
+					if email == "none" {
+						email = ""
+					}
+					if err := be.SetEmail(ctx, user, email); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+	)
+
+	return cmd
+}
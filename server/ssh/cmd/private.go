@@ -29,7 +29,7 @@ func privateCommand() *cobra.Command {
 					return err
 				}
 
-				cmd.Println(isPrivate)
+				return printValue(cmd, isPrivate)
 			case 2:
 				isPrivate, err := strconv.ParseBool(args[1])
 				if err != nil {
@@ -28,7 +28,7 @@ func projectName() *cobra.Command {
 					return err
 				}
 
-				cmd.Println(pn)
+				return printValue(cmd, pn)
 			default:
 				if err := checkIfCollab(cmd, args); err != nil {
 					return err
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/caarlos0/duration"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func pruneBranchesCommand() *cobra.Command {
+	var merged, dryRun bool
+	var olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "prune-branches REPOSITORY",
+		Short: "Delete stale branches",
+		Long: `Delete the branches fully merged into the default branch with --merged, and
+the branches whose last commit is older than --older-than, or the branches that
+are both when both are set. The default branch and the protected branches are
+kept.`,
+		Example:           "  repo prune-branches icecream --merged --older-than 90d --dry-run",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			var age time.Duration
+			if olderThan != "" {
+				d, err := duration.Parse(olderThan)
+				if err != nil {
+					return usageError{err}
+				}
+
+				age = d
+			}
+			if !merged && age <= 0 {
+				return usageError{errors.New("at least one of --merged and --older-than is required")}
+			}
+
+			branches, err := be.PruneBranches(ctx, args[0], proto.UserFromContext(ctx), merged, age, dryRun)
+			if err != nil {
+				return err
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			for _, b := range branches {
+				cmd.Printf("%s\t%s\t%s\n", b.Name, b.Commit[:7], tf.Relative(b.CommittedAt, tokenTimeLayout))
+			}
+
+			verb := "Deleted"
+			if dryRun {
+				verb = "Would delete"
+			}
+
+			cmd.PrintErrf("%s %d branches\n", verb, len(branches))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&merged, "merged", false, "only delete the branches merged into the default branch")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "only delete the branches last committed to before this long ago (e.g. 90d, 2w)")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "only list the branches that would be deleted")
+
+	return cmd
+}
@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/caarlos0/duration"
+	"github.com/caarlos0/tablewriter"
 	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/sshutils"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 )
 
 // PubkeyCommand returns a command that manages user public keys.
@@ -16,10 +22,13 @@ func PubkeyCommand() *cobra.Command {
 		Short:   "Manage your public keys",
 	}
 
+	var addLabel, addExpiresIn, addRestrict string
 	pubkeyAddCommand := &cobra.Command{
-		Use:   "add AUTHORIZED_KEY",
-		Short: "Add a public key",
-		Args:  cobra.MinimumNArgs(1),
+		Use:               "add AUTHORIZED_KEY",
+		Short:             "Add a public key",
+		Long:              "Add a public key. The comment of the key is its label, unless --label is set. Expired keys can't authenticate anymore.",
+		Args:              cobra.MinimumNArgs(1),
+		PersistentPreRunE: checkIfKeyWritable,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
@@ -29,19 +38,41 @@ func PubkeyCommand() *cobra.Command {
 				return err
 			}
 
-			apk, _, err := sshutils.ParseAuthorizedKey(strings.Join(args, " "))
+			apk, comment, err := sshutils.ParseAuthorizedKey(strings.Join(args, " "))
 			if err != nil {
 				return err
 			}
 
-			return be.AddPublicKey(ctx, user.Username(), apk)
+			opts := proto.PublicKeyOptions{Label: comment}
+			if addLabel != "" {
+				opts.Label = addLabel
+			}
+			if addExpiresIn != "" {
+				d, err := duration.Parse(addExpiresIn)
+				if err != nil {
+					return usageError{err}
+				}
+
+				opts.ExpiresAt = time.Now().Add(d)
+			}
+			if opts.Restriction, err = proto.ParseKeyRestriction(addRestrict); err != nil {
+				return usageError{err}
+			}
+
+			return be.AddPublicKeyWithOptions(ctx, user.Username(), apk, opts)
 		},
 	}
 
+	pubkeyAddCommand.Flags().StringVar(&addLabel, "label", "", "Label of the key, defaults to its comment")
+	pubkeyAddCommand.Flags().StringVar(&addExpiresIn, "expires-in", "", "Key expiration time (e.g. 1y, 3mo, 2w, 5d4h, 1h30m)")
+	pubkeyAddCommand.Flags().StringVar(&addRestrict, "restrict", "", "Restrict the key to git-only or read-only")
+
 	pubkeyRemoveCommand := &cobra.Command{
-		Use:   "remove AUTHORIZED_KEY",
-		Args:  cobra.MinimumNArgs(1),
-		Short: "Remove a public key",
+		Use:               "remove AUTHORIZED_KEY|ID",
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Remove a public key",
+		Long:              "Remove a public key, by its authorized key or by its ID in \"pubkey list --long\".",
+		PersistentPreRunE: checkIfKeyWritable,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
@@ -51,6 +82,12 @@ func PubkeyCommand() *cobra.Command {
 				return err
 			}
 
+			if len(args) == 1 {
+				if id, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+					return be.RemovePublicKeyByID(ctx, user.Username(), id)
+				}
+			}
+
 			apk, _, err := sshutils.ParseAuthorizedKey(strings.Join(args, " "))
 			if err != nil {
 				return err
@@ -60,10 +97,43 @@ func PubkeyCommand() *cobra.Command {
 		},
 	}
 
+	pubkeyRestrictCommand := &cobra.Command{
+		Use:   "restrict ID git-only|read-only|none",
+		Short: "Restrict a public key",
+		Long: `Restrict a public key, by its ID in "pubkey list --long".
+
+Git-only keys can only push and fetch, they can't open the TUI or run other commands.
+Read-only keys can only read, they can't push, and they can't change your account.`,
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfKeyWritable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			pk := sshutils.PublicKeyFromContext(ctx)
+			user, err := be.UserByPublicKey(ctx, pk)
+			if err != nil {
+				return err
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return usageError{err}
+			}
+			r, err := proto.ParseKeyRestriction(args[1])
+			if err != nil {
+				return usageError{err}
+			}
+
+			return be.SetPublicKeyRestriction(ctx, user.Username(), id, r)
+		},
+	}
+
+	var listLong bool
 	pubkeyListCommand := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
 		Short:   "List public keys",
+		Long:    "List public keys. With --long, list their IDs, labels, restrictions, when they were added and last used, and when they expire.",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
@@ -74,18 +144,89 @@ func PubkeyCommand() *cobra.Command {
 				return err
 			}
 
-			pks := user.PublicKeys()
-			for _, pk := range pks {
-				cmd.Println(sshutils.MarshalAuthorizedKey(pk))
+			if !listLong {
+				pks := user.PublicKeys()
+				if jsonOutput(cmd) {
+					return printJSON(cmd, publicKeysJSON(pks))
+				}
+				for _, pk := range pks {
+					cmd.Println(sshutils.MarshalAuthorizedKey(pk))
+				}
+
+				return nil
+			}
+
+			keys, err := be.UserPublicKeys(ctx, user.Username())
+			if err != nil {
+				return err
 			}
 
-			return nil
+			if jsonOutput(cmd) {
+				type keyJSON struct {
+					ID          int64      `json:"id"`
+					Label       string     `json:"label"`
+					Restriction string     `json:"restriction"`
+					PublicKey   string     `json:"public_key"`
+					Fingerprint string     `json:"fingerprint"`
+					CreatedAt   time.Time  `json:"created_at"`
+					LastUsedAt  *time.Time `json:"last_used_at"`
+					ExpiresAt   *time.Time `json:"expires_at"`
+					Expired     bool       `json:"expired"`
+				}
+				list := make([]keyJSON, 0, len(keys))
+				for _, k := range keys {
+					list = append(list, keyJSON{
+						ID:          k.ID,
+						Label:       k.Label,
+						Restriction: k.Restriction.String(),
+						PublicKey:   sshutils.MarshalAuthorizedKey(k.PublicKey),
+						Fingerprint: ssh.FingerprintSHA256(k.PublicKey),
+						CreatedAt:   k.CreatedAt,
+						LastUsedAt:  jsonTime(k.LastUsedAt),
+						ExpiresAt:   jsonTime(k.ExpiresAt),
+						Expired:     k.IsExpired(),
+					})
+				}
+				return printJSON(cmd, list)
+			}
+
+			tf := be.TimeFormat(ctx, user)
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				keys,
+				[]string{"ID", "Label", "Restriction", "Fingerprint", "Added", "Last Used", "Expires"},
+				func(k proto.PublicKey) ([]string, error) {
+					lastUsed := "never"
+					if !k.LastUsedAt.IsZero() {
+						lastUsed = tf.Relative(k.LastUsedAt, tokenTimeLayout)
+					}
+					expiresAt := "-"
+					if k.IsExpired() {
+						expiresAt = "expired"
+					} else if !k.ExpiresAt.IsZero() {
+						expiresAt = tf.Relative(k.ExpiresAt, tokenTimeLayout)
+					}
+
+					return []string{
+						strconv.FormatInt(k.ID, 10),
+						orDash(k.Label),
+						k.Restriction.String(),
+						ssh.FingerprintSHA256(k.PublicKey),
+						tf.Relative(k.CreatedAt, tokenTimeLayout),
+						lastUsed,
+						expiresAt,
+					}, nil
+				},
+			)
 		},
 	}
 
+	pubkeyListCommand.Flags().BoolVarP(&listLong, "long", "l", false, "List the details of the keys")
+
 	cmd.AddCommand(
 		pubkeyAddCommand,
 		pubkeyRemoveCommand,
+		pubkeyRestrictCommand,
 		pubkeyListCommand,
 	)
 
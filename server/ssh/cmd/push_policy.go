@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func pushPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "push-policy",
+		Aliases: []string{"push-policies"},
+		Short:   "Manage repository push limits",
+	}
+
+	cmd.AddCommand(
+		pushPolicyShowCommand(),
+		pushPolicySetCommand(),
+		pushPolicyResetCommand(),
+	)
+
+	return cmd
+}
+
+func pushPolicyShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "show REPOSITORY",
+		Aliases:           []string{"get"},
+		Short:             "Show the push limits of a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			if _, err := be.Repository(ctx, rn); err != nil {
+				return err
+			}
+
+			policy, err := be.PushPolicy(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			banned := "-"
+			if len(policy.BannedExtensions) > 0 {
+				banned = strings.Join(policy.BannedExtensions, ", ")
+			}
+
+			cmd.Println("Max file size:", sizeString(policy.MaxBlobSize))
+			cmd.Println("Max push size:", sizeString(policy.MaxPushSize))
+			cmd.Println("Banned extensions:", banned)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func pushPolicySetCommand() *cobra.Command {
+	var maxBlobSize, maxPushSize string
+	var bannedExtensions []string
+	cmd := &cobra.Command{
+		Use:               "set REPOSITORY",
+		Short:             "Override the push limits of a repository",
+		Long:              "Override the push limits of a repository. Sizes accept units, i.e. 10MB or 1GiB, and 0 means no limit. Limits that are not set are inherited from the server configuration.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			var opts proto.PushPolicyOptions
+			if cmd.Flags().Changed("max-file-size") {
+				size, err := parseSize(maxBlobSize)
+				if err != nil {
+					return err
+				}
+				opts.MaxBlobSize = &size
+			}
+			if cmd.Flags().Changed("max-push-size") {
+				size, err := parseSize(maxPushSize)
+				if err != nil {
+					return err
+				}
+				opts.MaxPushSize = &size
+			}
+			if cmd.Flags().Changed("banned-extensions") {
+				opts.BannedExtensions = &bannedExtensions
+			}
+
+			return be.SetPushPolicy(ctx, rn, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&maxBlobSize, "max-file-size", "", "maximum size of a single file")
+	cmd.Flags().StringVar(&maxPushSize, "max-push-size", "", "maximum total size of a push")
+	cmd.Flags().StringSliceVar(&bannedExtensions, "banned-extensions", nil, "file extensions that can only be pushed using Git LFS, i.e. .zip,.iso")
+
+	return cmd
+}
+
+func pushPolicyResetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "reset REPOSITORY",
+		Short:             "Use the server push limits for a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfRepoAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			return be.ResetPushPolicy(ctx, rn)
+		},
+	}
+
+	return cmd
+}
+
+func parseSize(s string) (int64, error) {
+	size, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, err
+	}
+	return int64(size), nil
+}
+
+func sizeString(size int64) string {
+	if size <= 0 {
+		return "unlimited"
+	}
+	return humanize.IBytes(uint64(size))
+}
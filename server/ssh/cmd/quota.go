@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+// quotaCommand returns the command to manage the disk quotas.
+func quotaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "quota",
+		Aliases: []string{"quotas"},
+		Short:   "Manage the disk quotas",
+		Long: `Manage the disk quotas of the users and the repositories. The disk usage of a
+repository is the size of its git objects and of its Git LFS objects, the usage
+of a user is the usage of the repositories they own. Pushes over a quota are
+rejected.
+
+Sizes accept units, i.e. 10MB or 1GiB, and 0 means no limit. Use "reset" to
+inherit the quota from the server configuration again.`,
+		Example:           "  admin quota user beatrice 10GiB\n  admin quota repo icecream reset",
+		PersistentPreRunE: checkIfAdmin,
+	}
+
+	cmd.AddCommand(
+		quotaListCommand(),
+		quotaUserCommand(),
+		quotaRepoCommand(),
+		quotaRefreshCommand(),
+	)
+
+	return cmd
+}
+
+func quotaListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the disk usage and the quotas of the users",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			users, err := be.Users(ctx)
+			if err != nil {
+				return err
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				users,
+				[]string{"User", "Usage", "Quota"},
+				func(username string) ([]string, error) {
+					user, err := be.User(ctx, username)
+					if err != nil {
+						return nil, err
+					}
+					usage, err := be.UserDiskUsage(ctx, user)
+					if err != nil {
+						return nil, err
+					}
+					quota, err := be.UserDiskQuota(ctx, user)
+					if err != nil {
+						return nil, err
+					}
+
+					return []string{username, humanize.IBytes(uint64(usage)), sizeString(quota)}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+func quotaUserCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user USERNAME [SIZE|reset]",
+		Short: "Show or set the disk quota of a user",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user, err := be.User(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 2 {
+				if args[1] == "reset" {
+					return be.ResetUserDiskQuota(ctx, user)
+				}
+
+				size, err := parseSize(args[1])
+				if err != nil {
+					return err
+				}
+				return be.SetUserDiskQuota(ctx, user, size)
+			}
+
+			usage, err := be.UserDiskUsage(ctx, user)
+			if err != nil {
+				return err
+			}
+			quota, err := be.UserDiskQuota(ctx, user)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println("Usage:", humanize.IBytes(uint64(usage)))
+			cmd.Println("Quota:", sizeString(quota))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func quotaRepoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repo REPOSITORY [SIZE|reset]",
+		Short: "Show or set the disk quota of a repository",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			if _, err := be.Repository(ctx, rn); err != nil {
+				return err
+			}
+
+			if len(args) == 2 {
+				if args[1] == "reset" {
+					return be.ResetRepoDiskQuota(ctx, rn)
+				}
+
+				size, err := parseSize(args[1])
+				if err != nil {
+					return err
+				}
+				return be.SetRepoDiskQuota(ctx, rn, size)
+			}
+
+			usage, err := be.RepoDiskUsage(ctx, rn)
+			if err != nil {
+				return err
+			}
+			quota, err := be.RepoDiskQuota(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println("Usage:", humanize.IBytes(uint64(usage.Total())))
+			cmd.Println("Git objects:", humanize.IBytes(uint64(usage.Objects)))
+			cmd.Println("LFS objects:", humanize.IBytes(uint64(usage.LFS)))
+			cmd.Println("Quota:", sizeString(quota))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func quotaRefreshCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh [REPOSITORY...]",
+		Short: "Compute the disk usage of repositories again",
+		Long:  "Compute the disk usage of repositories again, of all of them when none is given. The usage is updated after each push.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if len(args) == 0 {
+				repos, err := be.Repositories(ctx)
+				if err != nil {
+					return err
+				}
+				for _, r := range repos {
+					args = append(args, r.Name())
+				}
+			}
+
+			for _, rn := range args {
+				rn = strings.TrimSuffix(rn, ".git")
+				usage, err := be.UpdateRepoDiskUsage(ctx, rn)
+				if err != nil {
+					return err
+				}
+				cmd.Printf("%s: %s\n", rn, humanize.IBytes(uint64(usage.Total())))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
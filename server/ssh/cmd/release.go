@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func releaseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "release",
+		Aliases: []string{"releases"},
+		Short:   "Manage repository releases",
+		Long:    "Manage repository releases. A release is a tag with markdown notes and downloadable assets.",
+	}
+
+	cmd.AddCommand(
+		releaseListCommand(),
+		releaseCreateCommand(),
+		releaseInfoCommand(),
+		releaseEditCommand(),
+		releaseDeleteCommand(),
+		releaseUploadCommand(),
+		releaseDownloadCommand(),
+		releaseDeleteAssetCommand(),
+	)
+
+	return cmd
+}
+
+// releaseNotes returns the notes of a release. A "-" reads them from the
+// standard input.
+func releaseNotes(cmd *cobra.Command, notes string) (string, error) {
+	if notes != "-" {
+		return notes, nil
+	}
+
+	b, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func releaseListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the releases of a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rels, err := be.Releases(ctx, strings.TrimSuffix(args[0], ".git"))
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				type releaseJSON struct {
+					Tag       string    `json:"tag"`
+					Name      string    `json:"name"`
+					Author    string    `json:"author,omitempty"`
+					CreatedAt time.Time `json:"created_at"`
+				}
+				list := make([]releaseJSON, 0, len(rels))
+				for _, r := range rels {
+					list = append(list, releaseJSON{r.Tag, r.Name, r.Username, r.CreatedAt})
+				}
+				return printJSON(cmd, list)
+			}
+
+			if len(rels) == 0 {
+				cmd.Println("No releases found")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				rels,
+				[]string{"Tag", "Name", "Author", "Created"},
+				func(r proto.Release) ([]string, error) {
+					return []string{
+						r.Tag,
+						r.Name,
+						r.Username,
+						tf.Relative(r.CreatedAt, tokenTimeLayout),
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+func releaseCreateCommand() *cobra.Command {
+	var name, notes string
+	cmd := &cobra.Command{
+		Use:               "create REPOSITORY TAG",
+		Short:             "Create a release of a tag",
+		Long:              "Create a release of an existing tag. The notes are markdown, use --notes - to read them from the standard input.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			notes, err := releaseNotes(cmd, notes)
+			if err != nil {
+				return err
+			}
+
+			_, err = be.CreateRelease(ctx, strings.TrimSuffix(args[0], ".git"), args[1], name, notes)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "the name of the release")
+	cmd.Flags().StringVarP(&notes, "notes", "m", "", "the markdown notes of the release, - reads them from stdin")
+
+	return cmd
+}
+
+func releaseInfoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "info REPOSITORY TAG",
+		Short:             "Show a release and its assets",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			rel, err := be.Release(ctx, rn, args[1])
+			if err != nil {
+				return err
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			cmd.Printf("Tag: %s\n", rel.Tag)
+			cmd.Printf("Name: %s\n", rel.Title())
+			if rel.Username != "" {
+				cmd.Printf("Author: %s\n", rel.Username)
+			}
+			cmd.Printf("Created: %s\n", tf.Relative(rel.CreatedAt, tokenTimeLayout))
+			if rel.Notes != "" {
+				cmd.Printf("\n%s\n", strings.TrimRight(rel.Notes, "\n"))
+			}
+
+			if len(rel.Assets) > 0 {
+				cmd.Println("\nAssets:")
+				for _, a := range rel.Assets {
+					cmd.Printf("  %s\t%s\tsha256:%s\n", a.Name, humanize.IBytes(uint64(a.Size)), a.SHA256)
+					cmd.Printf("    %s\n", be.ReleaseAssetURL(rn, rel.Tag, a.Name))
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func releaseEditCommand() *cobra.Command {
+	var name, notes string
+	cmd := &cobra.Command{
+		Use:               "edit REPOSITORY TAG",
+		Short:             "Edit the name and the notes of a release",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			rel, err := be.Release(ctx, rn, args[1])
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("name") {
+				rel.Name = name
+			}
+			if cmd.Flags().Changed("notes") {
+				rel.Notes, err = releaseNotes(cmd, notes)
+				if err != nil {
+					return err
+				}
+			}
+
+			return be.UpdateRelease(ctx, rn, rel.Tag, rel.Name, rel.Notes)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "the name of the release")
+	cmd.Flags().StringVarP(&notes, "notes", "m", "", "the markdown notes of the release, - reads them from stdin")
+
+	return cmd
+}
+
+func releaseDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete REPOSITORY TAG",
+		Aliases:           []string{"rm", "remove"},
+		Short:             "Delete a release and its assets",
+		Long:              "Delete a release and its assets. The tag is kept.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.DeleteRelease(ctx, strings.TrimSuffix(args[0], ".git"), args[1])
+		},
+	}
+
+	return cmd
+}
+
+func releaseUploadCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "upload REPOSITORY TAG NAME",
+		Short:             "Upload a release asset from the standard input",
+		Long:              "Upload a release asset from the standard input. It replaces the asset with the same name.",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			a, err := be.UploadReleaseAsset(ctx, rn, args[1], args[2], cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("%s\t%s\tsha256:%s\n", a.Name, humanize.IBytes(uint64(a.Size)), a.SHA256)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func releaseDownloadCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "download REPOSITORY TAG NAME",
+		Short:             "Write a release asset to the standard output",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			f, _, err := be.OpenReleaseAsset(ctx, strings.TrimSuffix(args[0], ".git"), args[1], args[2])
+			if err != nil {
+				return err
+			}
+			defer f.Close() // nolint: errcheck
+
+			_, err = io.Copy(cmd.OutOrStdout(), f)
+			return err
+		},
+	}
+
+	return cmd
+}
+
+func releaseDeleteAssetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete-asset REPOSITORY TAG NAME",
+		Short:             "Delete a release asset",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.DeleteReleaseAsset(ctx, strings.TrimSuffix(args[0], ".git"), args[1], args[2])
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+// reloadCommand returns the command to reload the server configuration.
+func reloadCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload",
+		Short: "Reload the server configuration",
+		Long: `Read config.yaml and the environment variables again, like sending SIGHUP to the server.
+The server name, log levels, authentication settings, IP access rules, rate limits, and avatar settings are applied without dropping active sessions. The other settings take effect on restart.`,
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if err := be.ReloadConfig(ctx); err != nil {
+				return err
+			}
+
+			cmd.Println("Configuration reloaded")
+			return nil
+		},
+	}
+}
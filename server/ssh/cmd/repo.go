@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/proto"
@@ -18,22 +19,46 @@ func RepoCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(
+		anonAccessCommand(),
+		archiveCommand(),
+		blameCommand(),
 		blobCommand(),
 		branchCommand(),
 		collabCommand(),
 		commitCommand(),
 		createCommand(),
+		defaultBranchCommand(),
 		deleteCommand(),
+		deployKeyCommand(),
 		descriptionCommand(),
+		diffCommand(),
+		goModuleCommand(),
+		grepCommand(),
 		hiddenCommand(),
+		hooksCommand(),
 		importCommand(),
+		lfsCommand(),
 		listCommand(),
 		mirrorCommand(),
+		permsCommand(),
 		privateCommand(),
 		projectName(),
+		pruneBranchesCommand(),
+		pushPolicyCommand(),
+		releaseCommand(),
 		renameCommand(),
+		setCommand(),
+		showCommand(),
+		sizeCommand(),
+		statsCommand(),
+		tabCommand(),
 		tagCommand(),
+		templateCommand(),
+		transferCommand(),
 		treeCommand(),
+		unarchiveCommand(),
+		verifyCommand(),
+		visibilityCommand(),
 	)
 
 	cmd.AddCommand(
@@ -72,6 +97,25 @@ func RepoCommand() *cobra.Command {
 				branches, _ := r.Branches()
 				tags, _ := r.Tags()
 
+				if jsonOutput(cmd) {
+					info := repoInfoJSON{
+						repoJSON:      newRepoJSON(rr),
+						DefaultBranch: head.Name().Short(),
+						Branches:      branches,
+						Tags:          tags,
+					}
+					if owner != nil {
+						info.Owner = owner.Username()
+					}
+					if info.Branches == nil {
+						info.Branches = []string{}
+					}
+					if info.Tags == nil {
+						info.Tags = []string{}
+					}
+					return printJSON(cmd, info)
+				}
+
 				// project name and description are optional, handle trailing
 				// whitespace to avoid breaking tests.
 				cmd.Println(strings.TrimSpace(fmt.Sprint("Project Name: ", rr.ProjectName())))
@@ -80,6 +124,12 @@ func RepoCommand() *cobra.Command {
 				cmd.Println("Private:", rr.IsPrivate())
 				cmd.Println("Hidden:", rr.IsHidden())
 				cmd.Println("Mirror:", rr.IsMirror())
+				if rr.IsTemplate() {
+					cmd.Println("Template:", rr.IsTemplate())
+				}
+				if rr.IsArchived() {
+					cmd.Println("Archived:", rr.IsArchived())
+				}
 				if owner != nil {
 					cmd.Println(strings.TrimSpace(fmt.Sprint("Owner: ", owner.Username())))
 				}
@@ -104,3 +154,41 @@ func RepoCommand() *cobra.Command {
 
 	return cmd
 }
+
+// repoJSON is the JSON output of a repository.
+type repoJSON struct {
+	Name        string           `json:"name"`
+	ProjectName string           `json:"project_name"`
+	Description string           `json:"description"`
+	Visibility  proto.Visibility `json:"visibility"`
+	Private     bool             `json:"private"`
+	Hidden      bool             `json:"hidden"`
+	Mirror      bool             `json:"mirror"`
+	Template    bool             `json:"template"`
+	Archived    bool             `json:"archived"`
+	UpdatedAt   *time.Time       `json:"updated_at"`
+}
+
+func newRepoJSON(r proto.Repository) repoJSON {
+	return repoJSON{
+		Name:        r.Name(),
+		ProjectName: r.ProjectName(),
+		Description: r.Description(),
+		Visibility:  proto.RepositoryVisibility(r),
+		Private:     r.IsPrivate(),
+		Hidden:      r.IsHidden(),
+		Mirror:      r.IsMirror(),
+		Template:    r.IsTemplate(),
+		Archived:    r.IsArchived(),
+		UpdatedAt:   jsonTime(r.UpdatedAt()),
+	}
+}
+
+// repoInfoJSON is the JSON output of repo info.
+type repoInfoJSON struct {
+	repoJSON
+	Owner         string   `json:"owner,omitempty"`
+	DefaultBranch string   `json:"default_branch"`
+	Branches      []string `json:"branches"`
+	Tags          []string `json:"tags"`
+}
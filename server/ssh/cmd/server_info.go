@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sessions"
+	"github.com/charmbracelet/soft-serve/server/version"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+// serverInfoCommand returns the command reporting the version, the
+// statistics, and the configuration of the server.
+func serverInfoCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Show the version, statistics, and configuration of the server",
+		Long: `Show the version and the uptime of the server, the number of repositories and
+users, the disk usage of the repositories as of their last computation, the
+active SSH sessions, the depth of the job queue, and a summary of the
+configuration.`,
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			cfg := config.FromContext(ctx)
+			stats, err := be.ServerStats(ctx)
+			if err != nil {
+				return err
+			}
+
+			var nsessions int
+			if reg := sessions.FromContext(ctx); reg != nil {
+				nsessions = len(reg.List())
+			}
+			m, err := be.Maintenance(ctx)
+			if err != nil {
+				return err
+			}
+			started := be.StartedAt()
+
+			if jsonOutput(cmd) {
+				type listenerJSON struct {
+					ListenAddr string `json:"listen_addr"`
+					PublicURL  string `json:"public_url,omitempty"`
+				}
+				type infoJSON struct {
+					Version   string    `json:"version"`
+					CommitSHA string    `json:"commit_sha,omitempty"`
+					StartedAt time.Time `json:"started_at"`
+					Uptime    int64     `json:"uptime_seconds"`
+					Repos     struct {
+						Total    int `json:"total"`
+						Mirrors  int `json:"mirrors"`
+						Private  int `json:"private"`
+						Archived int `json:"archived"`
+					} `json:"repos"`
+					Users     int `json:"users"`
+					DiskUsage struct {
+						Total   int64 `json:"total"`
+						Objects int64 `json:"objects"`
+						LFS     int64 `json:"lfs"`
+					} `json:"disk_usage"`
+					Sessions int `json:"sessions"`
+					Jobs     struct {
+						Pending int `json:"pending"`
+						Running int `json:"running"`
+						Dead    int `json:"dead"`
+					} `json:"jobs"`
+					Config struct {
+						Name         string       `json:"name"`
+						DataPath     string       `json:"data_path"`
+						SSH          listenerJSON `json:"ssh"`
+						HTTP         listenerJSON `json:"http"`
+						Git          listenerJSON `json:"git"`
+						DBDriver     string       `json:"db_driver"`
+						LFS          bool         `json:"lfs"`
+						LFSStorage   string       `json:"lfs_storage,omitempty"`
+						AnonAccess   string       `json:"anon_access"`
+						AllowKeyless bool         `json:"allow_keyless"`
+						Maintenance  bool         `json:"maintenance"`
+						HA           bool         `json:"ha"`
+						Replica      bool         `json:"replica"`
+					} `json:"config"`
+				}
+
+				var info infoJSON
+				info.Version = version.Version
+				info.CommitSHA = version.CommitSHA
+				info.StartedAt = started
+				info.Uptime = int64(time.Since(started).Seconds())
+				info.Repos.Total = stats.Repos
+				info.Repos.Mirrors = stats.Mirrors
+				info.Repos.Private = stats.Private
+				info.Repos.Archived = stats.Archived
+				info.Users = stats.Users
+				info.DiskUsage.Total = stats.DiskUsage.Total()
+				info.DiskUsage.Objects = stats.DiskUsage.Objects
+				info.DiskUsage.LFS = stats.DiskUsage.LFS
+				info.Sessions = nsessions
+				info.Jobs.Pending = stats.JobsPending
+				info.Jobs.Running = stats.JobsRunning
+				info.Jobs.Dead = stats.JobsDead
+				info.Config.Name = cfg.Current().Name
+				info.Config.DataPath = cfg.DataPath
+				info.Config.SSH = listenerJSON{cfg.SSH.ListenAddr, cfg.SSH.PublicURL}
+				info.Config.HTTP = listenerJSON{cfg.HTTP.ListenAddr, cfg.HTTP.PublicURL}
+				info.Config.Git = listenerJSON{ListenAddr: cfg.Git.ListenAddr}
+				info.Config.DBDriver = cfg.DB.Driver
+				info.Config.LFS = cfg.LFS.Enabled
+				if cfg.LFS.Enabled {
+					info.Config.LFSStorage = cfg.LFS.Storage
+				}
+				info.Config.AnonAccess = be.AnonAccess(ctx).String()
+				info.Config.AllowKeyless = be.AllowKeyless(ctx)
+				info.Config.Maintenance = m != nil
+				info.Config.HA = cfg.HA.Enabled
+				info.Config.Replica = cfg.Replica.Enabled
+				return printJSON(cmd, info)
+			}
+
+			v := version.Version
+			if sha := version.CommitSHA; sha != "" {
+				if len(sha) > 7 {
+					sha = sha[:7]
+				}
+				v += " (" + sha + ")"
+			}
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			cmd.Println("Version:", v)
+			cmd.Printf("Uptime: %s, since %s\n", time.Since(started).Round(time.Second), tf.Absolute(started, auditTimeLayout))
+			cmd.Printf("Repositories: %d (%d mirrors, %d private, %d archived)\n", stats.Repos, stats.Mirrors, stats.Private, stats.Archived)
+			cmd.Println("Users:", stats.Users)
+			cmd.Printf("Disk usage: %s (%s objects, %s LFS)\n", humanize.IBytes(uint64(stats.DiskUsage.Total())),
+				humanize.IBytes(uint64(stats.DiskUsage.Objects)), humanize.IBytes(uint64(stats.DiskUsage.LFS)))
+			cmd.Println("Sessions:", nsessions)
+			cmd.Printf("Jobs: %d pending, %d running, %d dead\n", stats.JobsPending, stats.JobsRunning, stats.JobsDead)
+
+			lfs := "off"
+			if cfg.LFS.Enabled {
+				lfs = "on, " + cfg.LFS.Storage + " storage"
+			}
+			cmd.Println()
+			cmd.Println("Configuration:")
+			cmd.Println("  Name:", cfg.Current().Name)
+			cmd.Println("  Data path:", cfg.DataPath)
+			cmd.Println("  SSH:", listener(cfg.SSH.ListenAddr, cfg.SSH.PublicURL))
+			cmd.Println("  HTTP:", listener(cfg.HTTP.ListenAddr, cfg.HTTP.PublicURL))
+			cmd.Println("  Git daemon:", listener(cfg.Git.ListenAddr, ""))
+			cmd.Println("  Database:", cfg.DB.Driver)
+			cmd.Println("  LFS:", lfs)
+			cmd.Println("  Anonymous access:", be.AnonAccess(ctx))
+			cmd.Println("  Allow keyless:", be.AllowKeyless(ctx))
+			cmd.Println("  Maintenance:", onOff(m != nil))
+			cmd.Println("  High availability:", onOff(cfg.HA.Enabled))
+			cmd.Println("  Replica:", onOff(cfg.Replica.Enabled))
+			return nil
+		},
+	}
+}
+
+// listener returns the listen address of a server, with its public URL if
+// any.
+func listener(addr string, publicURL string) string {
+	if publicURL == "" {
+		return orDash(addr)
+	}
+	return fmt.Sprintf("%s (%s)", orDash(addr), publicURL)
+}
+
+// onOff returns "on" if b is true, "off" otherwise.
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
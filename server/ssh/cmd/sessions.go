@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sessions"
+	"github.com/spf13/cobra"
+)
+
+// sessionsCommand returns the command to manage the active SSH sessions.
+func sessionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "sessions",
+		Short:             "Manage the active SSH sessions",
+		Example:           "  admin sessions list\n  admin sessions kill 1a2b3c4d5e6f7a8b",
+		PersistentPreRunE: checkIfAdmin,
+	}
+
+	cmd.AddCommand(
+		sessionsListCommand(),
+		sessionsKillCommand(),
+	)
+
+	return cmd
+}
+
+func sessionsListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the active SSH sessions",
+		Long:    `List the active SSH sessions, the sessions of a connection share their ID.`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			reg := sessions.FromContext(cmd.Context())
+			if reg == nil {
+				return proto.ErrSessionNotFound
+			}
+
+			list := reg.List()
+			if jsonOutput(cmd) {
+				if list == nil {
+					list = []sessions.Info{}
+				}
+				return printJSON(cmd, list)
+			}
+
+			now := time.Now()
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				list,
+				[]string{"ID", "User", "Address", "Type", "Location", "Age"},
+				func(i sessions.Info) ([]string, error) {
+					return []string{
+						i.ID,
+						orDash(i.Username),
+						i.RemoteAddr,
+						i.Type,
+						sessionLocation(i),
+						now.Sub(i.StartedAt).Round(time.Second).String(),
+					}, nil
+				},
+			)
+		},
+	}
+}
+
+func sessionsKillCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "kill ID",
+		Short: "Terminate the connection of a session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			reg := sessions.FromContext(ctx)
+			if reg == nil {
+				return proto.ErrSessionNotFound
+			}
+
+			username, found := "", false
+			for _, i := range reg.List() {
+				if i.ID == args[0] {
+					username, found = i.Username, true
+					break
+				}
+			}
+			if !found {
+				return proto.ErrSessionNotFound
+			}
+
+			// Admins can kill their own session, the context is canceled
+			// once it's killed.
+			be.Audit(ctx, proto.AuditEvent{Action: proto.AuditSessionKill, Target: args[0], Details: username})
+			n, err := reg.Kill(args[0])
+			if err != nil {
+				return err
+			}
+
+			if n == 1 {
+				cmd.Println("Terminated 1 session")
+			} else {
+				cmd.Printf("Terminated %d sessions\n", n)
+			}
+			return nil
+		},
+	}
+}
+
+// sessionLocation describes what a session is doing, the page of a TUI or
+// the command.
+func sessionLocation(i sessions.Info) string {
+	switch {
+	case i.Type == "tui" && i.Location.Repo == "":
+		return "repositories"
+	case i.Type == "tui" && i.Location.Tab != "":
+		return i.Location.Repo + " (" + i.Location.Tab + ")"
+	case i.Type == "tui":
+		return i.Location.Repo
+	default:
+		return orDash(i.Command)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+func setCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set REPOSITORY SETTING [VALUE]",
+		Short: "Set or get a repository setting",
+		Long: `Set or get a repository setting. "default" removes the setting of the
+repository and goes back to the server configuration.
+
+Settings:
+  daemon-export [true|false|default]  Whether the Git daemon exports the repository`,
+		Args:      cobra.RangeArgs(2, 3),
+		ValidArgs: []string{"daemon-export"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo, setting := args[0], args[1]
+			if setting != "daemon-export" {
+				return usageError{fmt.Errorf("unknown repository setting %q, must be daemon-export", setting)}
+			}
+
+			if len(args) == 2 {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				export, override, err := be.DaemonExport(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				if jsonOutput(cmd) {
+					return printJSON(cmd, struct {
+						DaemonExport bool `json:"daemon_export"`
+						Default      bool `json:"default"`
+					}{export, !override})
+				}
+
+				if !override {
+					cmd.Printf("%t (default)\n", export)
+				} else {
+					cmd.Println(export)
+				}
+				return nil
+			}
+
+			if err := checkIfRepoAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			var export *bool
+			if args[2] != "default" {
+				v, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return usageError{fmt.Errorf("invalid value %q, must be true, false, or default", args[2])}
+				}
+				export = &v
+			}
+
+			return be.SetDaemonExport(ctx, repo, export)
+		},
+	}
+
+	return cmd
+}
@@ -9,9 +9,10 @@ import (
 // SetUsernameCommand returns a command that sets the user's username.
 func SetUsernameCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "set-username USERNAME",
-		Short: "Set your username",
-		Args:  cobra.ExactArgs(1),
+		Use:               "set-username USERNAME",
+		Short:             "Set your username",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfKeyWritable,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
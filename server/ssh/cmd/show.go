@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+// showCommand returns a command that streams the raw contents of a file.
+func showCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show REPOSITORY REFERENCE:PATH",
+		Short: "Write the raw contents of a file to stdout",
+		Long: `Write the raw contents of a file at a reference to stdout, as is, binary files
+included. An empty reference is HEAD. Use it to download a file.`,
+		Example:           "  repo show icecream main:config.yaml > config.yaml\n  repo show icecream :logo.png > logo.png",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			ref, fp, ok := strings.Cut(args[1], ":")
+			fp = strings.Trim(fp, "/")
+			if !ok || fp == "" {
+				return usageError{fmt.Errorf("invalid file %q, use REFERENCE:PATH", args[1])}
+			}
+
+			rr, err := be.Repository(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			r, err := rr.Open()
+			if err != nil {
+				return err
+			}
+
+			rev, err := resolveCommit(r, ref)
+			if err != nil {
+				return err
+			}
+
+			tree, err := r.LsTree(rev)
+			if err != nil {
+				return err
+			}
+
+			te, err := tree.TreeEntry(fp)
+			if err != nil || te.Type() != "blob" {
+				return fmt.Errorf("%w: %s", proto.ErrFileNotFound, fp)
+			}
+
+			var stderr bytes.Buffer
+			if err := te.File().Pipeline(cmd.OutOrStdout(), &stderr); err != nil {
+				return fmt.Errorf("%w: %s", err, stderr.String())
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func sizeCommand() *cobra.Command {
+	var refresh bool
+
+	cmd := &cobra.Command{
+		Use:               "size REPOSITORY",
+		Short:             "Show the size of a repository",
+		Long:              "Show the disk usage of a repository, its number of git objects, the size of its packs and of its Git LFS objects, and its largest blobs. Repositories are sized once a day, collaborators can size them right away with --refresh.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			if refresh {
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+			}
+
+			usage, err := be.RepoDiskUsage(ctx, rn)
+			if err != nil {
+				return err
+			}
+			if refresh || usage.UpdatedAt.IsZero() {
+				usage, err = be.SizeRepository(ctx, rn)
+				if err != nil {
+					return err
+				}
+			}
+
+			blobs, err := be.RepoLargeBlobs(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			cmd.Println("Disk usage:", humanize.IBytes(uint64(usage.Total())))
+			cmd.Println("Objects:", usage.ObjectCount)
+			cmd.Println("Pack size:", humanize.IBytes(uint64(usage.PackSize)))
+			cmd.Println("LFS size:", humanize.IBytes(uint64(usage.LFS)))
+			cmd.Println("Sized:", tf.Relative(usage.UpdatedAt, tokenTimeLayout))
+			if len(blobs) == 0 {
+				return nil
+			}
+
+			cmd.Println()
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				blobs,
+				[]string{"Blob", "Size", "Path"},
+				func(b proto.LargeBlob) ([]string, error) {
+					return []string{
+						b.Oid[:7],
+						humanize.IBytes(uint64(b.Size)),
+						orDash(b.Path),
+					}, nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&refresh, "refresh", "r", false, "size the repository again")
+
+	return cmd
+}
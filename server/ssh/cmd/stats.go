@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func statsCommand() *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:               "stats REPOSITORY",
+		Short:             "Show the traffic of a repository",
+		Long:              "Show the number of clones, fetches, and pushes of a repository per day. Unique clients are approximate, they count users and the addresses of anonymous clients.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if days < 1 {
+				return usageError{errors.New("days must be at least 1")}
+			}
+
+			t, err := be.Traffic(ctx, args[0], days)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Clones: %d\n", t.Clones)
+			cmd.Printf("Fetches: %d\n", t.Fetches)
+			cmd.Printf("Pushes: %d\n", t.Pushes)
+			cmd.Printf("Unique clients: ~%d\n\n", t.Clients)
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				t.Days,
+				[]string{"Date", "Clones", "Fetches", "Pushes", "Clients"},
+				func(d proto.TrafficDay) ([]string, error) {
+					return []string{
+						d.Day.Format("2006-01-02"),
+						strconv.FormatInt(d.Clones, 10),
+						strconv.FormatInt(d.Fetches, 10),
+						strconv.FormatInt(d.Pushes, 10),
+						strconv.FormatInt(d.Clients, 10),
+					}, nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().IntVarP(&days, "days", "d", 14, "number of days to show, today included")
+
+	return cmd
+}
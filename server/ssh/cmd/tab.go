@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func tabCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tab",
+		Short: "Manage the tabs shown when browsing a repository",
+	}
+
+	cmd.AddCommand(
+		tabListCommand(),
+		tabDefaultCommand(),
+		tabHideCommand(),
+		tabShowCommand(),
+	)
+
+	return cmd
+}
+
+func tabListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the shown tabs",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			l, err := be.TabLayout(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			shown := l.Shown()
+			names := make([]string, len(shown))
+			for i, t := range shown {
+				names[i] = t.String()
+			}
+
+			return printNames(cmd, names)
+		},
+	}
+
+	return cmd
+}
+
+func tabDefaultCommand() *cobra.Command {
+	var unset bool
+	cmd := &cobra.Command{
+		Use:   "default REPOSITORY [TAB]",
+		Short: "Set or get the tab opened first",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch {
+			case unset:
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+
+				return be.SetDefaultTab(ctx, rn, "")
+			case len(args) == 1:
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				l, err := be.TabLayout(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				return printValue(cmd, l.DefaultTab().String())
+			default:
+				t, err := proto.ParseTab(args[1])
+				if err != nil {
+					return usageError{err}
+				}
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+
+				return be.SetDefaultTab(ctx, rn, t)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&unset, "unset", "u", false, "open the first shown tab")
+
+	return cmd
+}
+
+func tabHideCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "hide REPOSITORY TAB...",
+		Short:             "Hide tabs",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setTabsHidden(cmd, args, true)
+		},
+	}
+
+	return cmd
+}
+
+func tabShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "show REPOSITORY TAB...",
+		Short:             "Show hidden tabs",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setTabsHidden(cmd, args, false)
+		},
+	}
+
+	return cmd
+}
+
+func setTabsHidden(cmd *cobra.Command, args []string, hidden bool) error {
+	ctx := cmd.Context()
+	be := backend.FromContext(ctx)
+	rn := strings.TrimSuffix(args[0], ".git")
+	tabs := make(map[proto.Tab]bool)
+	for _, arg := range args[1:] {
+		t, err := proto.ParseTab(arg)
+		if err != nil {
+			return usageError{err}
+		}
+		tabs[t] = true
+	}
+
+	l, err := be.TabLayout(ctx, rn)
+	if err != nil {
+		return err
+	}
+
+	var hide []proto.Tab
+	for _, t := range proto.Tabs {
+		if (tabs[t] && hidden) || (!tabs[t] && l.IsHidden(t)) {
+			hide = append(hide, t)
+		}
+	}
+
+	return be.SetHiddenTabs(ctx, rn, hide)
+}
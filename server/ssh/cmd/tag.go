@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/spf13/cobra"
 )
 
@@ -14,8 +15,9 @@ func tagCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(
-		tagListCommand(),
+		tagCreateCommand(),
 		tagDeleteCommand(),
+		tagListCommand(),
 	)
 
 	return cmd
@@ -43,39 +45,76 @@ func tagListCommand() *cobra.Command {
 			}
 
 			tags, _ := r.Tags()
-			for _, t := range tags {
-				cmd.Println(t)
-			}
-
-			return nil
+			return printNames(cmd, tags)
 		},
 	}
 
 	return cmd
 }
 
-func tagDeleteCommand() *cobra.Command {
+func tagCreateCommand() *cobra.Command {
+	var opts proto.TagOptions
+
 	cmd := &cobra.Command{
-		Use:               "delete REPOSITORY TAG",
-		Aliases:           []string{"remove", "rm", "del"},
-		Short:             "Delete a tag",
-		Args:              cobra.ExactArgs(2),
+		Use:   "create REPOSITORY TAG [REFERENCE]",
+		Short: "Create a tag",
+		Long: `Create a tag pointing to a reference or a commit, HEAD if omitted. With a message, the tag
+is annotated, and tagged by you. With --sign, the tag is annotated and signed
+with the SSH key of the server, and can be verified with git verify-tag and
+the server public key as an allowed signer.`,
+		Example:           "  repo tag create icecream v1.0.0 main -m 'First release'\n  echo 'First release' | repo tag create icecream v1.0.0 -m - --sign",
+		Args:              cobra.RangeArgs(2, 3),
 		PersistentPreRunE: checkIfCollab,
+		// The new tag doesn't complete to the existing ones.
+		ValidArgsFunction: completeParams([]string{"REPOSITORY", "NAME", "[REFERENCE]"}),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			rn := strings.TrimSuffix(args[0], ".git")
-			rr, err := be.Repository(ctx, rn)
+			msg, err := releaseNotes(cmd, opts.Message)
 			if err != nil {
 				return err
 			}
 
-			r, err := rr.Open()
+			var rev string
+			if len(args) > 2 {
+				rev = args[2]
+			}
+
+			opts.Message = msg
+			oid, err := be.CreateTag(ctx, args[0], proto.UserFromContext(ctx), args[1], rev, opts)
 			if err != nil {
 				return err
 			}
 
-			return r.DeleteTag(args[1])
+			if jsonOutput(cmd) {
+				return printJSON(cmd, struct {
+					Name   string `json:"name"`
+					Object string `json:"object"`
+				}{args[1], oid})
+			}
+
+			cmd.Printf("Created tag %s (%.8s)\n", args[1], oid)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Message, "message", "m", "", "the message of an annotated tag, - reads it from stdin")
+	cmd.Flags().BoolVar(&opts.Sign, "sign", false, "sign the tag with the SSH key of the server")
+
+	return cmd
+}
+
+func tagDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete REPOSITORY TAG",
+		Aliases:           []string{"remove", "rm", "del"},
+		Short:             "Delete a tag",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.DeleteTag(ctx, args[0], proto.UserFromContext(ctx), args[1])
 		},
 	}
 
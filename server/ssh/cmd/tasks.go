@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/cron"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+// tasksCommand returns the command to inspect and run the scheduled tasks.
+func tasksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Inspect and run the scheduled tasks",
+		Long: `Inspect and run the tasks the server schedules, the mirror updates, the LFS
+pruning, the repository sizing, and the user sync. The last run of each task is
+kept until the server restarts.`,
+		Example:           "  admin tasks list\n  admin tasks run mirror-pull",
+		PersistentPreRunE: checkIfAdmin,
+	}
+
+	cmd.AddCommand(
+		tasksListCommand(),
+		tasksRunCommand(),
+	)
+
+	return cmd
+}
+
+func tasksListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the scheduled tasks and their last run",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			tasks := be.ScheduledTasks()
+
+			if jsonOutput(cmd) {
+				type taskJSON struct {
+					Name     string     `json:"name"`
+					Schedule string     `json:"schedule"`
+					Status   string     `json:"status"`
+					LastRun  *time.Time `json:"last_run"`
+					Duration float64    `json:"duration_seconds"`
+					Error    string     `json:"error,omitempty"`
+					Manual   bool       `json:"manual"`
+				}
+				list := make([]taskJSON, 0, len(tasks))
+				for _, t := range tasks {
+					tj := taskJSON{
+						Name:     t.Name,
+						Schedule: t.Spec,
+						Status:   taskStatus(t),
+						Duration: t.Duration.Seconds(),
+						Manual:   t.Manual,
+					}
+					if !t.LastRun.IsZero() {
+						lastRun := t.LastRun
+						tj.LastRun = &lastRun
+					}
+					if t.Err != nil {
+						tj.Error = t.Err.Error()
+					}
+					list = append(list, tj)
+				}
+				return printJSON(cmd, list)
+			}
+
+			if len(tasks) == 0 {
+				cmd.Println("No tasks found")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, proto.UserFromContext(ctx))
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				tasks,
+				[]string{"Name", "Schedule", "Status", "Last Run", "Duration", "Error"},
+				func(t cron.Task) ([]string, error) {
+					lastRun, duration := "-", "-"
+					if !t.LastRun.IsZero() {
+						lastRun = tf.Relative(t.LastRun, tokenTimeLayout)
+						if t.Manual {
+							lastRun += " (manual)"
+						}
+						if !t.Running {
+							duration = t.Duration.Round(time.Millisecond).String()
+						}
+					}
+					var errMsg string
+					if t.Err != nil {
+						errMsg = strings.SplitN(t.Err.Error(), "\n", 2)[0]
+					}
+					return []string{
+						t.Name,
+						t.Spec,
+						taskStatus(t),
+						lastRun,
+						duration,
+						orDash(errMsg),
+					}, nil
+				},
+			)
+		},
+	}
+}
+
+func tasksRunCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run TASK",
+		Short: "Run a scheduled task now",
+		Long: `Run a scheduled task now and wait for it to finish. The tasks queueing jobs,
+like the mirror updates, are done once the jobs are queued, follow them with
+"admin jobs list".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			start := time.Now()
+			err := be.RunScheduledTask(args[0])
+			switch {
+			case errors.Is(err, cron.ErrTaskNotFound),
+				errors.Is(err, cron.ErrTaskRunning),
+				errors.Is(err, backend.ErrTasksNotScheduled):
+				return err
+			}
+
+			result := "ok"
+			if err != nil {
+				result = err.Error()
+			}
+			be.Audit(ctx, proto.AuditEvent{Action: proto.AuditTaskRun, Target: args[0], Details: result})
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Ran task %s in %s\n", args[0], time.Since(start).Round(time.Millisecond))
+			return nil
+		},
+	}
+}
+
+// taskStatus returns the status of a task, whether it's running, and the
+// result of its last run.
+func taskStatus(t cron.Task) string {
+	switch {
+	case t.Running:
+		return "running"
+	case t.LastRun.IsZero():
+		return "never run"
+	case t.Err != nil:
+		return "failed"
+	default:
+		return "ok"
+	}
+}
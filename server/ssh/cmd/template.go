@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+func templateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template REPOSITORY [TRUE|FALSE]",
+		Short: "Mark or unmark a repository as a template",
+		Long:  "Mark or unmark a repository as a template. Repositories can be created from the default branch of a template with repo create --template.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+			switch len(args) {
+			case 1:
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				template, err := be.IsTemplate(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				return printValue(cmd, template)
+			case 2:
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+
+				template := args[1] == "true"
+				if err := be.SetTemplate(ctx, repo, template); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
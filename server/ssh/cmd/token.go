@@ -7,12 +7,16 @@ import (
 
 	"github.com/caarlos0/duration"
 	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 )
 
+// tokenTimeLayout is the layout of absolute token dates.
+const tokenTimeLayout = "2006-01-02 15:04 MST"
+
 // TokenCommand returns a command that manages user access tokens.
 func TokenCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -22,10 +26,13 @@ func TokenCommand() *cobra.Command {
 	}
 
 	var createExpiresIn string
+	var createScope string
+	var createRepo string
 	createCmd := &cobra.Command{
-		Use:   "create NAME",
-		Short: "Create a new access token",
-		Args:  cobra.MinimumNArgs(1),
+		Use:               "create NAME",
+		Short:             "Create a new access token",
+		Args:              cobra.MinimumNArgs(1),
+		PersistentPreRunE: checkIfKeyWritable,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
@@ -36,6 +43,11 @@ func TokenCommand() *cobra.Command {
 				return proto.ErrUserNotFound
 			}
 
+			level := access.ParseAccessLevel(createScope)
+			if level < access.ReadOnlyAccess {
+				return usageError{access.ErrInvalidAccessLevel}
+			}
+
 			var expiresAt time.Time
 			var expiresIn time.Duration
 			if createExpiresIn != "" {
@@ -48,14 +60,18 @@ func TokenCommand() *cobra.Command {
 				expiresAt = time.Now().Add(d)
 			}
 
-			token, err := be.CreateAccessToken(ctx, user, name, expiresAt)
+			token, err := be.CreateAccessToken(ctx, user, name, expiresAt, level, createRepo)
 			if err != nil {
 				return err
 			}
 
 			notice := "Access token created"
 			if expiresIn != 0 {
-				notice += " (expires in " + humanize.Time(expiresAt) + ")"
+				if tf := be.TimeFormat(ctx, user); tf.IsRelative(true) {
+					notice += " (expires in " + humanize.Time(expiresAt) + ")"
+				} else {
+					notice += " (expires on " + tf.Absolute(expiresAt, tokenTimeLayout) + ")"
+				}
 			}
 
 			cmd.PrintErrln(notice)
@@ -66,7 +82,14 @@ func TokenCommand() *cobra.Command {
 	}
 
 	createCmd.Flags().StringVar(&createExpiresIn, "expires-in", "", "Token expiration time (e.g. 1y, 3mo, 2w, 5d4h, 1h30m)")
+	createCmd.Flags().StringVar(&createScope, "scope", access.AdminAccess.String(), "Highest access level the token grants (read-only, read-write, admin-access)")
+	createCmd.Flags().StringVar(&createRepo, "repo", "", "Limit the token to a repository")
+	twoFactor(createCmd, func(*cobra.Command, []string) bool {
+		return access.ParseAccessLevel(createScope) == access.AdminAccess
+	})
+	idempotent(createCmd)
 
+	var listExpired bool
 	listCmd := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
@@ -86,30 +109,70 @@ func TokenCommand() *cobra.Command {
 				return err
 			}
 
+			if listExpired {
+				expired := tokens[:0]
+				for _, t := range tokens {
+					if t.IsExpired() {
+						expired = append(expired, t)
+					}
+				}
+				tokens = expired
+			}
+
+			if jsonOutput(cmd) {
+				type tokenJSON struct {
+					ID        int64      `json:"id"`
+					Name      string     `json:"name"`
+					Scope     string     `json:"scope"`
+					Repo      string     `json:"repo,omitempty"`
+					CreatedAt time.Time  `json:"created_at"`
+					ExpiresAt *time.Time `json:"expires_at"`
+					Expired   bool       `json:"expired"`
+				}
+				list := make([]tokenJSON, 0, len(tokens))
+				for _, t := range tokens {
+					list = append(list, tokenJSON{
+						ID:        t.ID,
+						Name:      t.Name,
+						Scope:     t.AccessLevel.String(),
+						Repo:      t.Repo,
+						CreatedAt: t.CreatedAt,
+						ExpiresAt: jsonTime(t.ExpiresAt),
+						Expired:   t.IsExpired(),
+					})
+				}
+				return printJSON(cmd, list)
+			}
+
 			if len(tokens) == 0 {
 				cmd.Println("No tokens found")
 				return nil
 			}
 
-			now := time.Now()
+			tf := be.TimeFormat(ctx, user)
 			return tablewriter.Render(
 				cmd.OutOrStdout(),
 				tokens,
-				[]string{"ID", "Name", "Created At", "Expires In"},
+				[]string{"ID", "Name", "Scope", "Repo", "Created", "Expires"},
 				func(t proto.AccessToken) ([]string, error) {
 					expiresAt := "-"
-					if !t.ExpiresAt.IsZero() {
-						if now.After(t.ExpiresAt) {
-							expiresAt = "expired"
-						} else {
-							expiresAt = humanize.Time(t.ExpiresAt)
-						}
+					if t.IsExpired() {
+						expiresAt = "expired"
+					} else if !t.ExpiresAt.IsZero() {
+						expiresAt = tf.Relative(t.ExpiresAt, tokenTimeLayout)
+					}
+
+					repo := "-"
+					if t.Repo != "" {
+						repo = t.Repo
 					}
 
 					return []string{
 						strconv.FormatInt(t.ID, 10),
 						t.Name,
-						humanize.Time(t.CreatedAt),
+						t.AccessLevel.String(),
+						repo,
+						tf.Relative(t.CreatedAt, tokenTimeLayout),
 						expiresAt,
 					}, nil
 				},
@@ -117,11 +180,78 @@ func TokenCommand() *cobra.Command {
 		},
 	}
 
+	listCmd.Flags().BoolVar(&listExpired, "expired", false, "Only list expired tokens")
+
+	rotateCmd := &cobra.Command{
+		Use:               "rotate ID",
+		Short:             "Replace the secret of an access token",
+		Long:              "Replace the secret of an access token. The old secret stops working right away. Tokens that expire get a new expiration date with the same lifetime as before.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfKeyWritable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			user := proto.UserFromContext(ctx)
+			if user == nil {
+				return proto.ErrUserNotFound
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return usageError{err}
+			}
+
+			token, expiresAt, err := be.RotateAccessToken(ctx, user, id)
+			if err != nil {
+				return err
+			}
+
+			notice := "Access token rotated"
+			if !expiresAt.IsZero() {
+				if tf := be.TimeFormat(ctx, user); tf.IsRelative(true) {
+					notice += " (expires in " + humanize.Time(expiresAt) + ")"
+				} else {
+					notice += " (expires on " + tf.Absolute(expiresAt, tokenTimeLayout) + ")"
+				}
+			}
+
+			cmd.PrintErrln(notice)
+			cmd.Println(token)
+
+			return nil
+		},
+	}
+
+	twoFactor(rotateCmd, func(cmd *cobra.Command, args []string) bool {
+		ctx := cmd.Context()
+		be := backend.FromContext(ctx)
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return false
+		}
+
+		tokens, err := be.ListAccessTokens(ctx, proto.UserFromContext(ctx))
+		if err != nil {
+			// Fail closed, the code is checked when the token can't be
+			// looked up.
+			return true
+		}
+		for _, t := range tokens {
+			if t.ID == id {
+				return t.AccessLevel == access.AdminAccess
+			}
+		}
+
+		return false
+	})
+
 	deleteCmd := &cobra.Command{
-		Use:     "delete ID",
-		Aliases: []string{"rm", "remove"},
-		Short:   "Delete an access token",
-		Args:    cobra.ExactArgs(1),
+		Use:               "delete ID",
+		Aliases:           []string{"rm", "remove"},
+		Short:             "Delete an access token",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfKeyWritable,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
@@ -148,6 +278,7 @@ func TokenCommand() *cobra.Command {
 	cmd.AddCommand(
 		createCmd,
 		listCmd,
+		rotateCmd,
 		deleteCmd,
 	)
 
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func transferCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "transfer REPOSITORY USERNAME",
+		Short: "Transfer a repository to a new owner",
+		Long: `Transfer the ownership of a repository to a user. The owner of the repository
+or an admin requests the transfer, which the new owner accepts with transfer
+accept. Admins can transfer a repository right away with --force.
+
+A repository in the namespace of its owner, i.e. alice/app, moves to the
+namespace of the new owner, bob/app. The new owner is removed from the
+collaborators, and the previous owner becomes a read-write collaborator.`,
+		Example:           "  repo transfer alice/icecream bob\n  repo transfer accept alice/icecream",
+		Args:              cobra.ExactArgs(2),
+		PreRunE:           checkIfOwner, // not persistent, the new owner runs the subcommands
+		ValidArgsFunction: completeParams([]string{"REPOSITORY", "USERNAME"}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if force {
+				if !IsAdmin(ctx) {
+					return proto.ErrUnauthorized
+				}
+
+				name, err := be.TransferRepository(ctx, args[0], args[1])
+				if err != nil {
+					return err
+				}
+
+				cmd.Printf("Transferred %s to %s\n", name, args[1])
+				return nil
+			}
+
+			t, err := be.RequestRepoTransfer(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Requested the transfer of %s to %s, waiting for them to accept it\n", t.Repo, t.To)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "transfer the repository without waiting for the new owner, admins only")
+
+	cmd.AddCommand(
+		transferAcceptCommand(),
+		transferCancelCommand(),
+		transferDeclineCommand(),
+		transferListCommand(),
+	)
+
+	return cmd
+}
+
+func transferAcceptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "accept REPOSITORY",
+		Short: "Accept the transfer of a repository to you",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			t, err := pendingTransfer(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			name, err := be.TransferRepository(ctx, t.Repo, t.To)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("You are now the owner of %s\n", name)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func transferDeclineCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decline REPOSITORY",
+		Short: "Decline the transfer of a repository to you",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			t, err := pendingTransfer(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			return be.DeleteRepoTransfer(ctx, t.Repo)
+		},
+	}
+
+	return cmd
+}
+
+func transferCancelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "cancel REPOSITORY",
+		Short:             "Cancel the pending transfer of a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfOwner,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.DeleteRepoTransfer(ctx, args[0])
+		},
+	}
+
+	return cmd
+}
+
+func transferListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the pending transfers from and to you, or all of them for admins",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			all, err := be.RepoTransfers(ctx)
+			if err != nil {
+				return err
+			}
+
+			ts := make([]proto.RepoTransfer, 0, len(all))
+			for _, t := range all {
+				if IsAdmin(ctx) || user != nil && (t.From == user.Username() || t.To == user.Username()) {
+					ts = append(ts, t)
+				}
+			}
+
+			if jsonOutput(cmd) {
+				type transferJSON struct {
+					Repo        string    `json:"repo"`
+					From        string    `json:"from,omitempty"`
+					To          string    `json:"to"`
+					RequestedBy string    `json:"requested_by,omitempty"`
+					CreatedAt   time.Time `json:"created_at"`
+				}
+				list := make([]transferJSON, 0, len(ts))
+				for _, t := range ts {
+					list = append(list, transferJSON{t.Repo, t.From, t.To, t.RequestedBy, t.CreatedAt})
+				}
+				return printJSON(cmd, list)
+			}
+
+			if len(ts) == 0 {
+				cmd.Println("No pending transfers")
+				return nil
+			}
+
+			tf := be.TimeFormat(ctx, user)
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				ts,
+				[]string{"Repository", "From", "To", "Requested By", "Requested"},
+				func(t proto.RepoTransfer) ([]string, error) {
+					return []string{
+						t.Repo,
+						t.From,
+						t.To,
+						t.RequestedBy,
+						tf.Relative(t.CreatedAt, tokenTimeLayout),
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+// pendingTransfer returns the pending transfer of a repository to the user
+// of the session.
+func pendingTransfer(cmd *cobra.Command, repo string) (proto.RepoTransfer, error) {
+	ctx := cmd.Context()
+	be := backend.FromContext(ctx)
+	user := proto.UserFromContext(ctx)
+	t, err := be.RepoTransfer(ctx, repo)
+	if err != nil {
+		return proto.RepoTransfer{}, err
+	}
+	if user == nil || t.To != user.Username() {
+		return proto.RepoTransfer{}, proto.ErrTransferNotFound
+	}
+
+	return t, nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+const twoFactorFlag = "otp"
+
+// twoFactor makes a destructive command require a two-factor code from users
+// who enrolled in two-factor authentication. It adds an --otp flag to cmd and
+// wraps its RunE to check the code first. If required isn't nil, the code is
+// only checked when it returns true.
+func twoFactor(cmd *cobra.Command, required func(cmd *cobra.Command, args []string) bool) *cobra.Command {
+	var code string
+	cmd.Flags().StringVar(&code, twoFactorFlag, "", "two-factor code of your authenticator app")
+
+	runE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if required == nil || required(cmd, args) {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if err := be.VerifyTwoFactor(ctx, proto.UserFromContext(ctx), code); err != nil {
+				return err
+			}
+		}
+
+		return runE(cmd, args)
+	}
+
+	return cmd
+}
+
+// userTwoFactorCommand returns a command that manages the two-factor
+// authentication of the user.
+func userTwoFactorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "2fa",
+		Short: "Manage your two-factor authentication",
+		Long: `Manage your two-factor authentication.
+
+Once enabled, destructive commands like "repo delete", "user delete", and
+"token create" or "token rotate" with the admin scope require a code of your
+authenticator app with --otp.`,
+	}
+
+	var enableCode string
+	enableCmd := &cobra.Command{
+		Use:               "enable",
+		Short:             "Enable two-factor authentication",
+		Long:              "Enable two-factor authentication. Add the printed secret to your authenticator app, then confirm it with a code of the app with --otp.",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfKeyWritable,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			if user == nil {
+				return proto.ErrUserNotFound
+			}
+
+			if enableCode != "" {
+				if err := be.ConfirmTwoFactor(ctx, user, enableCode); err != nil {
+					return err
+				}
+
+				cmd.Println("Two-factor authentication enabled")
+				return nil
+			}
+
+			secret, url, err := be.EnrollTwoFactor(ctx, user)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(cmd) {
+				return printJSON(cmd, struct {
+					Secret string `json:"secret"`
+					URL    string `json:"url"`
+				}{secret, url})
+			}
+
+			cmd.PrintErrln("Add the secret to your authenticator app, or scan a QR code of " + url)
+			cmd.PrintErrln("Then confirm it with \"user 2fa enable --otp CODE\" and a code of the app")
+			cmd.Println(secret)
+			return nil
+		},
+	}
+
+	enableCmd.Flags().StringVar(&enableCode, twoFactorFlag, "", "confirm the enrollment with a code of your authenticator app")
+
+	var disableCode string
+	disableCmd := &cobra.Command{
+		Use:               "disable",
+		Short:             "Disable two-factor authentication",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfKeyWritable,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			if user == nil {
+				return proto.ErrUserNotFound
+			}
+			if disableCode == "" {
+				return proto.ErrTwoFactorRequired
+			}
+
+			return be.DisableTwoFactor(ctx, user, disableCode)
+		},
+	}
+
+	disableCmd.Flags().StringVar(&disableCode, twoFactorFlag, "", "two-factor code of your authenticator app")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether two-factor authentication is enabled",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			if user == nil {
+				return proto.ErrUserNotFound
+			}
+
+			enabled := be.HasTwoFactor(ctx, user)
+			if jsonOutput(cmd) {
+				return printJSON(cmd, struct {
+					Enabled bool `json:"enabled"`
+				}{enabled})
+			}
+
+			if enabled {
+				cmd.Println("Two-factor authentication is enabled")
+			} else {
+				cmd.Println("Two-factor authentication is disabled")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(
+		enableCmd,
+		disableCmd,
+		statusCmd,
+	)
+
+	return cmd
+}
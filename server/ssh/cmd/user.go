@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"sort"
 	"strings"
 
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/sshutils"
@@ -25,6 +27,7 @@ func UserCommand() *cobra.Command {
 		Use:               "create USERNAME",
 		Short:             "Create a new user",
 		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
 		PersistentPreRunE: checkIfAdmin,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var pubkeys []ssh.PublicKey
@@ -52,6 +55,7 @@ func UserCommand() *cobra.Command {
 
 	userCreateCommand.Flags().BoolVarP(&admin, "admin", "a", false, "make the user an admin")
 	userCreateCommand.Flags().StringVarP(&key, "key", "k", "", "add a public key to the user")
+	idempotent(userCreateCommand)
 
 	userDeleteCommand := &cobra.Command{
 		Use:               "delete USERNAME",
@@ -66,7 +70,9 @@ func UserCommand() *cobra.Command {
 			return be.DeleteUser(ctx, username)
 		},
 	}
+	twoFactor(userDeleteCommand, nil)
 
+	var userList listFlags
 	userListCommand := &cobra.Command{
 		Use:               "list",
 		Aliases:           []string{"ls"},
@@ -76,20 +82,63 @@ func UserCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			users, err := be.Users(ctx)
+			key, reverse, err := userList.parse()
 			if err != nil {
 				return err
 			}
 
-			sort.Strings(users)
-			for _, u := range users {
-				cmd.Println(u)
+			all, err := be.Users(ctx)
+			if err != nil {
+				return err
+			}
+
+			// The users are in the order they were created.
+			users := make([]string, 0, len(all))
+			for _, u := range all {
+				if userList.match(u) {
+					users = append(users, u)
+				}
+			}
+			if key == "name" {
+				sort.Strings(users)
+			}
+			if reverse {
+				for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+					users[i], users[j] = users[j], users[i]
+				}
+			}
+			start, end := userList.bounds(len(users))
+
+			// Only the users of the page are fetched.
+			list := make([]userJSON, 0, end-start)
+			for _, u := range users[start:end] {
+				user, err := be.User(ctx, u)
+				if err != nil {
+					return err
+				}
+
+				if jsonOutput(cmd) {
+					list = append(list, userJSON{
+						Username:  u,
+						Admin:     user.IsAdmin(),
+						Suspended: user.IsSuspended(),
+					})
+				} else if user.IsSuspended() {
+					cmd.Printf("%s (suspended)\n", u)
+				} else {
+					cmd.Println(u)
+				}
+			}
+			if jsonOutput(cmd) {
+				return printJSON(cmd, list)
 			}
 
 			return nil
 		},
 	}
 
+	userList.register(userListCommand, "name", "created")
+
 	userAddPubkeyCommand := &cobra.Command{
 		Use:               "add-pubkey USERNAME AUTHORIZED_KEY",
 		Short:             "Add a public key to a user",
@@ -142,6 +191,62 @@ func UserCommand() *cobra.Command {
 		},
 	}
 
+	userSuspendCommand := &cobra.Command{
+		Use:               "suspend USERNAME",
+		Short:             "Suspend a user",
+		Long:              "Suspend a user. Suspended users can't access the server over SSH or HTTP until they're activated again, their public keys and access tokens are kept.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			username := args[0]
+			if user := proto.UserFromContext(ctx); user != nil && strings.EqualFold(user.Username(), username) {
+				return errors.New("you can't suspend yourself")
+			}
+
+			return be.SetSuspended(ctx, username, true)
+		},
+	}
+
+	userActivateCommand := &cobra.Command{
+		Use:               "activate USERNAME",
+		Short:             "Activate a suspended user",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			username := args[0]
+
+			return be.SetSuspended(ctx, username, false)
+		},
+	}
+
+	var ipAllow, ipDeny []string
+	userSetIPRulesCommand := &cobra.Command{
+		Use:               "set-ip-rules USERNAME",
+		Short:             "Restrict the addresses a user can connect from",
+		Long:              "Restrict the addresses a user can connect from, on top of the server rules. Rules are CIDRs or single addresses. Without rules, the user can connect from any address allowed by the server.",
+		Example:           "  user set-ip-rules beatrice --allow 10.0.0.0/8 --deny 10.1.0.0/16",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			username := args[0]
+			rules, err := access.ParseIPRules(ipAllow, ipDeny)
+			if err != nil {
+				return usageError{err}
+			}
+
+			return be.SetUserIPRules(ctx, username, rules)
+		},
+	}
+
+	userSetIPRulesCommand.Flags().StringSliceVar(&ipAllow, "allow", nil, "CIDRs the user can connect from")
+	userSetIPRulesCommand.Flags().StringSliceVar(&ipDeny, "deny", nil, "CIDRs the user can't connect from")
+
 	userInfoCommand := &cobra.Command{
 		Use:               "info USERNAME",
 		Short:             "Show information about a user",
@@ -158,9 +263,47 @@ func UserCommand() *cobra.Command {
 			}
 
 			isAdmin := user.IsAdmin()
+			provider, err := be.UserProvider(ctx, user)
+			if err != nil {
+				return err
+			}
+			subject, err := be.UserIdentity(ctx, user)
+			if err != nil {
+				return err
+			}
+			rules, err := be.UserIPRules(ctx, user)
+			if err != nil {
+				return err
+			}
+			allow, deny := rules.Strings()
+
+			if jsonOutput(cmd) {
+				info := userJSON{
+					Username:     user.Username(),
+					Admin:        isAdmin,
+					Suspended:    user.IsSuspended(),
+					Provider:     provider,
+					Identity:     subject,
+					AllowedAddrs: allow,
+					DeniedAddrs:  deny,
+					PublicKeys:   publicKeysJSON(user.PublicKeys()),
+				}
+				return printJSON(cmd, info)
+			}
 
 			cmd.Printf("Username: %s\n", user.Username())
 			cmd.Printf("Admin: %t\n", isAdmin)
+			cmd.Printf("Suspended: %t\n", user.IsSuspended())
+			if provider != "" {
+				cmd.Printf("Provider: %s\n", provider)
+			}
+			if subject != "" {
+				cmd.Printf("Identity: %s\n", subject)
+			}
+			if len(allow) > 0 || len(deny) > 0 {
+				cmd.Printf("Allowed addresses: %s\n", orDash(strings.Join(allow, ", ")))
+				cmd.Printf("Denied addresses: %s\n", orDash(strings.Join(deny, ", ")))
+			}
 			cmd.Printf("Public keys:\n")
 			for _, pk := range user.PublicKeys() {
 				cmd.Printf("  %s\n", sshutils.MarshalAuthorizedKey(pk))
@@ -185,6 +328,36 @@ func UserCommand() *cobra.Command {
 		},
 	}
 
+	userSyncCommand := &cobra.Command{
+		Use:               "sync",
+		Short:             "Sync users from the configured directory",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			res, err := be.SyncUsers(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, u := range res.Created {
+				cmd.Printf("created %s\n", u)
+			}
+			for _, u := range res.Updated {
+				cmd.Printf("updated %s\n", u)
+			}
+			for _, u := range res.Disabled {
+				cmd.Printf("disabled %s\n", u)
+			}
+			for _, u := range res.Skipped {
+				cmd.Printf("skipped %s: a local user has the same username\n", u)
+			}
+
+			return nil
+		},
+	}
+
 	cmd.AddCommand(
 		userCreateCommand,
 		userAddPubkeyCommand,
@@ -193,8 +366,37 @@ func UserCommand() *cobra.Command {
 		userDeleteCommand,
 		userRemovePubkeyCommand,
 		userSetAdminCommand,
+		userSetIPRulesCommand,
 		userSetUsernameCommand,
+		userSuspendCommand,
+		userActivateCommand,
+		userSyncCommand,
+		userExportCommand(),
+		userImportCommand(),
+		userTwoFactorCommand(),
+		userOIDCCommand(),
 	)
 
 	return cmd
 }
+
+// userJSON is the JSON output of a user.
+type userJSON struct {
+	Username     string   `json:"username"`
+	Admin        bool     `json:"admin"`
+	Suspended    bool     `json:"suspended"`
+	Provider     string   `json:"provider,omitempty"`
+	Identity     string   `json:"identity,omitempty"`
+	AllowedAddrs []string `json:"allowed_addresses,omitempty"`
+	DeniedAddrs  []string `json:"denied_addresses,omitempty"`
+	PublicKeys   []string `json:"public_keys,omitempty"`
+}
+
+// publicKeysJSON returns the authorized keys of pks, never nil.
+func publicKeysJSON(pks []ssh.PublicKey) []string {
+	keys := make([]string, 0, len(pks))
+	for _, pk := range pks {
+		keys = append(keys, sshutils.MarshalAuthorizedKey(pk))
+	}
+	return keys
+}
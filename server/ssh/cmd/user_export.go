@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// usersDocument is the document of the exported and imported users.
+type usersDocument struct {
+	Users []backend.ExportedUser `json:"users" yaml:"users"`
+}
+
+func userExportCommand() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the users",
+		Long: `Export the users with their public keys, address rules, and the access level
+of the repositories they collaborate on, as YAML or JSON. Passwords and access
+tokens aren't exported.`,
+		Example:           "  user export > users.yaml\n  user export --format json > users.json",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if jsonOutput(cmd) {
+				format = "json"
+			}
+			if format != "yaml" && format != "json" {
+				return usageError{fmt.Errorf("invalid format %q, must be yaml or json", format)}
+			}
+
+			users, err := be.ExportUsers(ctx)
+			if err != nil {
+				return err
+			}
+
+			doc := usersDocument{Users: users}
+			if format == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(doc)
+			}
+
+			enc := yaml.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent(2)
+			if err := enc.Encode(doc); err != nil {
+				return err
+			}
+			return enc.Close()
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "yaml", "output format, yaml or json")
+
+	return cmd
+}
+
+func userImportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import",
+		Short: "Import users from stdin",
+		Long: `Import users exported with "user export", as YAML or JSON, from stdin. Missing
+users are created. Existing users get the admin status, suspension, and address
+rules of the import, and its public keys and collaborations are added to theirs.
+Nothing changes when a user is invalid, collaborations on repositories that
+don't exist are skipped.`,
+		Example:           "  user import < users.yaml",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			// JSON documents are valid YAML.
+			var doc usersDocument
+			if err := yaml.Unmarshal(data, &doc); err != nil {
+				return usageError{fmt.Errorf("invalid users document: %w", err)}
+			}
+			if len(doc.Users) == 0 {
+				return usageError{errors.New("no users to import")}
+			}
+
+			res, err := be.ImportUsers(ctx, doc.Users)
+			for _, u := range res.Created {
+				cmd.Printf("created %s\n", u)
+			}
+			for _, u := range res.Updated {
+				cmd.Printf("updated %s\n", u)
+			}
+			for _, u := range res.Unchanged {
+				cmd.Printf("unchanged %s\n", u)
+			}
+			for _, c := range res.MissingRepos {
+				cmd.Printf("skipped %s: repository not found\n", c)
+			}
+
+			return err
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/spf13/cobra"
+)
+
+func verifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "verify REPOSITORY TAG",
+		Short:             "Verify the timestamp of a tag",
+		Long:              "Verify the RFC 3161 timestamp of a tag. Tags are timestamped when they're pushed, if the server has a timestamp authority configured.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			ts, err := be.VerifyTagTimestamp(ctx, rn, args[1])
+			if err != nil {
+				return err
+			}
+
+			cmd.Println("Tag:", ts.Tag)
+			cmd.Println("Object:", ts.ObjectID)
+			cmd.Println("Time:", ts.Time.UTC().Format(time.RFC3339))
+			cmd.Println("Authority:", ts.Authority)
+			cmd.Println("Serial Number:", ts.SerialNumber)
+			return nil
+		},
+	}
+
+	return cmd
+}
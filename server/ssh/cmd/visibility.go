@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/spf13/cobra"
+)
+
+func visibilityCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "visibility REPOSITORY [public|internal|private]",
+		Short: "Set or get a repository visibility",
+		Long: `Set or get a repository visibility.
+
+Public repositories can be read by anyone, including anonymous users.
+Internal repositories can be read by any authenticated user. Private
+repositories can only be read by their collaborators.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch len(args) {
+			case 1:
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				v, err := be.Visibility(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				return printValue(cmd, v.String())
+			case 2:
+				v, err := proto.ParseVisibility(args[1])
+				if err != nil {
+					return usageError{err}
+				}
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetVisibility(ctx, rn, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,145 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// The OpenSSH extensions clients with UpdateHostKeys learn the host keys
+// with, see PROTOCOL in the OpenSSH sources.
+const (
+	hostKeysRequest      = "hostkeys-00@openssh.com"
+	hostKeysProveRequest = "hostkeys-prove-00@openssh.com"
+)
+
+// hostKeysSentKey marks the connections the host keys were advertised on.
+var hostKeysSentKey = &struct{ string }{"hostkeys-sent"}
+
+// loadHostKeys reads the additional host keys.
+func loadHostKeys(paths []string) ([]gossh.Signer, error) {
+	signers := make([]gossh.Signer, 0, len(paths))
+	for _, p := range paths {
+		bts, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("host key: %w", err)
+		}
+		signer, err := gossh.ParsePrivateKey(bts)
+		if err != nil {
+			return nil, fmt.Errorf("host key %s: %w", p, err)
+		}
+		signers = append(signers, signer)
+	}
+
+	return signers, nil
+}
+
+// addHostKeys adds the additional host keys to the server. The server only
+// negotiates the first key of each type, the others are only advertised.
+func (s *SSHServer) addHostKeys(signers []gossh.Signer) {
+	for _, k := range s.srv.HostSigners {
+		s.hostKeys = append(s.hostKeys, k)
+	}
+	for _, signer := range signers {
+		negotiated := false
+		for _, k := range s.hostKeys {
+			if k.PublicKey().Type() == signer.PublicKey().Type() {
+				negotiated = true
+				break
+			}
+		}
+		if !negotiated {
+			s.srv.AddHostKey(signer)
+		}
+		s.hostKeys = append(s.hostKeys, signer)
+	}
+
+	if s.srv.RequestHandlers == nil {
+		s.srv.RequestHandlers = map[string]ssh.RequestHandler{}
+		for k, v := range ssh.DefaultRequestHandlers {
+			s.srv.RequestHandlers[k] = v
+		}
+	}
+	s.srv.RequestHandlers[hostKeysProveRequest] = s.proveHostKeys
+}
+
+// HostKeysMiddleware advertises the host keys once per connection, once the
+// user is authenticated. OpenSSH clients with UpdateHostKeys add the new keys
+// to their known hosts and remove the keys that aren't advertised anymore.
+func (s *SSHServer) HostKeysMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		ctx := sess.Context()
+		conn, ok := ctx.Value(ssh.ContextKeyConn).(gossh.Conn)
+		if ok && ctx.Value(hostKeysSentKey) == nil {
+			ctx.SetValue(hostKeysSentKey, true)
+			var payload []byte
+			for _, k := range s.hostKeys {
+				payload = append(payload, gossh.Marshal(struct{ Key []byte }{k.PublicKey().Marshal()})...)
+			}
+			if _, _, err := conn.SendRequest(hostKeysRequest, false, payload); err != nil {
+				s.logger.Debug("failed to advertise host keys", "err", err)
+			}
+		}
+
+		sh(sess)
+	}
+}
+
+// proveHostKeys signs the session ID with the host keys the client asks for,
+// to prove that the server has their private keys.
+func (s *SSHServer) proveHostKeys(ctx ssh.Context, _ *ssh.Server, req *gossh.Request) (bool, []byte) {
+	conn, ok := ctx.Value(ssh.ContextKeyConn).(gossh.Conn)
+	if !ok {
+		return false, nil
+	}
+
+	var sigs []byte
+	rest := req.Payload
+	for len(rest) > 0 {
+		var key struct {
+			Key  []byte
+			Rest []byte `ssh:"rest"`
+		}
+		if err := gossh.Unmarshal(rest, &key); err != nil {
+			return false, nil
+		}
+		rest = key.Rest
+
+		var signer gossh.Signer
+		for _, k := range s.hostKeys {
+			if bytes.Equal(k.PublicKey().Marshal(), key.Key) {
+				signer = k
+				break
+			}
+		}
+		if signer == nil {
+			return false, nil
+		}
+
+		data := gossh.Marshal(struct {
+			Request   string
+			SessionID []byte
+			Key       []byte
+		}{hostKeysProveRequest, conn.SessionID(), key.Key})
+		sig, err := signHostKeyProof(signer, data)
+		if err != nil {
+			s.logger.Error("failed to prove host key", "err", err)
+			return false, nil
+		}
+		sigs = append(sigs, gossh.Marshal(struct{ Sig []byte }{gossh.Marshal(sig)})...)
+	}
+
+	return true, sigs
+}
+
+// signHostKeyProof signs with SHA-512 for RSA keys, like OpenSSH.
+func signHostKeyProof(signer gossh.Signer, data []byte) (*gossh.Signature, error) {
+	if as, ok := signer.(gossh.AlgorithmSigner); ok && signer.PublicKey().Type() == gossh.KeyAlgoRSA {
+		return as.SignWithAlgorithm(rand.Reader, data, gossh.KeyAlgoRSASHA512)
+	}
+	return signer.Sign(rand.Reader, data)
+}
@@ -0,0 +1,103 @@
+package ssh
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/share"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var tuiJoinCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "ssh",
+	Name:      "tui_join_total",
+	Help:      "The total number of joined shared TUI sessions",
+}, []string{"joined"})
+
+const (
+	// Switch to the alternate screen buffer and hide the cursor.
+	enterViewerSeq = "\x1b[?1049h\x1b[?25l"
+	// Show the cursor and switch back to the main screen buffer.
+	exitViewerSeq = "\x1b[?25h\x1b[?1049l"
+)
+
+// JoinMiddleware adds the share registry to the session context and handles
+// "join <code>" sessions, which attach to a shared TUI session in read-only
+// mode.
+// This middleware must be run after the ContextMiddleware.
+func JoinMiddleware(reg *share.Registry) func(ssh.Handler) ssh.Handler {
+	return func(sh ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ctx := s.Context()
+			ctx.SetValue(share.ContextKey, reg)
+
+			cmd := s.Command()
+			if len(cmd) != 2 || cmd[0] != "join" {
+				sh(s)
+				return
+			}
+
+			logger := log.FromContext(ctx).WithPrefix("ssh.join")
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			if be.AccessLevelForUser(ctx, "", user) < access.ReadOnlyAccess {
+				tuiJoinCounter.WithLabelValues("false").Inc()
+				wish.Fatalln(s, proto.ErrUnauthorized)
+				return
+			}
+
+			sess, err := reg.Join(cmd[1])
+			if err != nil {
+				tuiJoinCounter.WithLabelValues("false").Inc()
+				wish.Fatalln(s, err)
+				return
+			}
+
+			wish.Print(s, enterViewerSeq)
+			done, detach, err := sess.Attach(s)
+			if err != nil {
+				tuiJoinCounter.WithLabelValues("false").Inc()
+				wish.Print(s, exitViewerSeq)
+				wish.Fatalln(s, err)
+				return
+			}
+
+			tuiJoinCounter.WithLabelValues("true").Inc()
+			logger.Info("viewer joined shared session", "addr", s.RemoteAddr().String())
+
+			// Viewers can leave using "q" or "ctrl+c".
+			quit := make(chan struct{})
+			go func() {
+				defer close(quit)
+				buf := make([]byte, 32)
+				for {
+					n, err := s.Read(buf)
+					if err != nil {
+						return
+					}
+					for _, b := range buf[:n] {
+						if b == 'q' || b == 0x03 {
+							return
+						}
+					}
+				}
+			}()
+
+			select {
+			case <-done:
+			case <-quit:
+			case <-ctx.Done():
+			}
+
+			detach()
+			wish.Print(s, exitViewerSeq)
+			wish.Println(s, "Left shared session.")
+			logger.Info("viewer left shared session", "addr", s.RemoteAddr().String())
+		}
+	}
+}
@@ -2,48 +2,154 @@ package ssh
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/db"
+	logr "github.com/charmbracelet/soft-serve/server/log"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/ssh/cmd"
 	"github.com/charmbracelet/soft-serve/server/sshutils"
 	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/tracing"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 	gossh "golang.org/x/crypto/ssh"
 )
 
 // ErrPermissionDenied is returned when a user is not allowed connect.
 var ErrPermissionDenied = fmt.Errorf("permission denied")
 
+// authenticationContextKey is the key for the authentication of a connection
+// in the context.
+var authenticationContextKey = &struct{ string }{"authentication"}
+
+// authentication is the public key authentication of a connection, finished
+// by the first session of the connection.
+type authentication struct {
+	once sync.Once
+	err  error
+}
+
 // AuthenticationMiddleware handles authentication.
-func AuthenticationMiddleware(sh ssh.Handler) ssh.Handler {
-	return func(s ssh.Session) {
+func (s *SSHServer) AuthenticationMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
 		// XXX: The authentication key is set in the context but gossh doesn't
 		// validate the authentication. We need to verify that the _last_ key
 		// that was approved is the one that's being used.
 
-		pk := s.PublicKey()
+		pk := sess.PublicKey()
 		if pk != nil {
 			// There is no public key stored in the context, public-key auth
 			// was never requested, skip
-			perms := s.Permissions().Permissions
+			perms := sess.Permissions().Permissions
 			if perms == nil {
-				wish.Fatalln(s, ErrPermissionDenied)
+				wish.Fatalln(sess, ErrPermissionDenied)
 				return
 			}
 
 			// Check if the key is the same as the one we have in context
 			fp := perms.Extensions["pubkey-fp"]
 			if fp != gossh.FingerprintSHA256(pk) {
-				wish.Fatalln(s, ErrPermissionDenied)
+				wish.Fatalln(sess, ErrPermissionDenied)
+				return
+			}
+
+			if err := s.authenticated(sess.Context(), pk); err != nil {
+				s.logger.Error("error authenticating", "addr", sess.RemoteAddr(), "err", err)
+				wish.Fatalln(sess, ErrPermissionDenied)
+				return
+			}
+		}
+
+		sh(sess)
+	}
+}
+
+// authenticated finishes the authentication of a connection once the public
+// key is verified. The public key handler runs before the client proves it
+// owns the key, it must not change anything.
+func (s *SSHServer) authenticated(ctx ssh.Context, pk ssh.PublicKey) error {
+	a, ok := ctx.Value(authenticationContextKey).(*authentication)
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	a.once.Do(func() {
+		// Users with a certificate signed by a trusted authority are
+		// created on first login.
+		if cert, ok := pk.(*gossh.Certificate); ok {
+			user, err := s.be.ProvisionUserByCertificate(ctx, cert)
+			if err != nil {
+				a.err = err
+				return
+			}
+			ctx.SetValue(proto.ContextKeyUser, user)
+		} else if proto.UserFromContext(ctx) != nil {
+			if err := s.be.RecordPublicKeyUse(ctx, pk); err != nil {
+				s.logger.Error("error recording public key use", "err", err)
+			}
+		}
+
+		if proto.UserFromContext(ctx) != nil {
+			s.be.Audit(ctx, proto.AuditEvent{
+				Action:  proto.AuditAuthSuccess,
+				Details: "ssh: " + gossh.FingerprintSHA256(pk),
+			})
+		}
+	})
+
+	return a.err
+}
+
+// RateLimitMiddleware rejects the sessions of clients exceeding their SSH
+// session rate limits.
+// This middleware must be run after the ContextMiddleware.
+func RateLimitMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		ctx := s.Context()
+		be := backend.FromContext(ctx)
+		if err := be.RateLimit(ctx, backend.RateSSHSession, proto.UserFromContext(ctx), s.RemoteAddr().String()); err != nil {
+			e := cmd.NewError(err)
+			e.Write(s.Stderr(), hasFlag(s.Command(), "--json")) // nolint: errcheck
+			s.Exit(e.ExitCode)                                  // nolint: errcheck
+			return
+		}
+
+		sh(s)
+	}
+}
+
+// gitOnlyCommands are the commands git-only public keys can run.
+var gitOnlyCommands = map[string]bool{
+	"git-upload-pack":      true,
+	"git-upload-archive":   true,
+	"git-receive-pack":     true,
+	"git-lfs-authenticate": true,
+	"git-lfs-transfer":     true,
+}
+
+// KeyRestrictionMiddleware rejects the sessions of git-only public keys that
+// don't run a git command, like the TUI, the CLI commands, and SFTP.
+// This middleware must be run after the ContextMiddleware.
+func KeyRestrictionMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		ctx := s.Context()
+		if backend.KeyRestriction(proto.UserFromContext(ctx)) == proto.KeyGitOnly {
+			args := s.Command()
+			_, _, isPty := s.Pty()
+			if isPty || s.Subsystem() != "" || len(args) == 0 || !gitOnlyCommands[args[0]] {
+				e := cmd.NewError(fmt.Errorf("%w: this key can only run git commands", proto.ErrKeyRestricted))
+				e.Write(s.Stderr(), hasFlag(args, "--json")) // nolint: errcheck
+				s.Exit(e.ExitCode)                           // nolint: errcheck
 				return
 			}
 		}
@@ -61,12 +167,34 @@ func ContextMiddleware(cfg *config.Config, dbx *db.DB, datastore store.Store, be
 			s.Context().SetValue(db.ContextKey, dbx)
 			s.Context().SetValue(store.ContextKey, datastore)
 			s.Context().SetValue(backend.ContextKey, be)
-			s.Context().SetValue(log.ContextKey, logger.WithPrefix("ssh"))
+			s.Context().SetValue(log.ContextKey, logr.WithPrefix(logger, logr.SubsystemSSH).With("session_id", sessionID(s)))
 			sh(s)
 		}
 	}
 }
 
+// sessionID returns the short ID of the SSH connection of a session, the
+// sessions of a connection share it.
+func sessionID(s ssh.Session) string {
+	id := s.Context().SessionID()
+	if len(id) > 16 {
+		id = id[:16]
+	}
+	return id
+}
+
+// sessionType returns the type of a session, "tui", "command", or the
+// subsystem.
+func sessionType(s ssh.Session) string {
+	if sub := s.Subsystem(); sub != "" {
+		return sub
+	}
+	if _, _, isPty := s.Pty(); isPty {
+		return "tui"
+	}
+	return "command"
+}
+
 var cliCommandCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 	Namespace: "soft_serve",
 	Subsystem: "cli",
@@ -74,6 +202,13 @@ var cliCommandCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 	Help:      "Total times each command was called",
 }, []string{"command"})
 
+var activeSessionsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "soft_serve",
+	Subsystem: "ssh",
+	Name:      "active_sessions",
+	Help:      "The number of open SSH sessions",
+}, []string{"type"})
+
 // CommandMiddleware handles git commands and CLI commands.
 // This middleware must be run after the ContextMiddleware.
 func CommandMiddleware(sh ssh.Handler) ssh.Handler {
@@ -84,44 +219,12 @@ func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 				return
 			}
 
-			ctx := s.Context()
+			ctx := tracing.Context(s.Context())
 			cfg := config.FromContext(ctx)
 
-			args := s.Command()
+			args := commandArgs(s)
 			cliCommandCounter.WithLabelValues(cmd.CommandName(args)).Inc()
-			rootCmd := &cobra.Command{
-				Short:        "Soft Serve is a self-hostable Git server for the command line.",
-				SilenceUsage: true,
-			}
-			rootCmd.CompletionOptions.DisableDefaultCmd = true
-
-			rootCmd.SetUsageTemplate(cmd.UsageTemplate)
-			rootCmd.SetUsageFunc(cmd.UsageFunc)
-			rootCmd.AddCommand(
-				cmd.GitUploadPackCommand(),
-				cmd.GitUploadArchiveCommand(),
-				cmd.GitReceivePackCommand(),
-				cmd.RepoCommand(),
-				cmd.SettingsCommand(),
-				cmd.UserCommand(),
-				cmd.InfoCommand(),
-				cmd.PubkeyCommand(),
-				cmd.SetUsernameCommand(),
-				cmd.JWTCommand(),
-				cmd.TokenCommand(),
-			)
-
-			if cfg.LFS.Enabled {
-				rootCmd.AddCommand(
-					cmd.GitLFSAuthenticateCommand(),
-				)
-
-				if cfg.LFS.SSHEnabled {
-					rootCmd.AddCommand(
-						cmd.GitLFSTransfer(),
-					)
-				}
-			}
+			rootCmd := rootCommand(cfg)
 
 			rootCmd.SetArgs(args)
 			if len(args) == 0 {
@@ -134,7 +237,14 @@ func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 			rootCmd.SetContext(ctx)
 
 			if err := rootCmd.ExecuteContext(ctx); err != nil {
-				s.Exit(1) // nolint: errcheck
+				e := cmd.NewError(err)
+				jsonErrors, _ := rootCmd.PersistentFlags().GetBool("json")
+				if e.Code == cmd.CodeUsage && !jsonErrors {
+					// Flag parsing might have failed before --json was seen.
+					jsonErrors = hasFlag(args, "--json")
+				}
+				e.Write(rootCmd.ErrOrStderr(), jsonErrors) // nolint: errcheck
+				s.Exit(e.ExitCode)                         // nolint: errcheck
 				return
 			}
 		}()
@@ -142,6 +252,35 @@ func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 	}
 }
 
+// commandArgs returns the arguments of the command of s. Parsing the
+// command drops the empty arguments, but the completion requests of the
+// shells end with one to complete a new word, so it's kept.
+func commandArgs(s ssh.Session) []string {
+	args := s.Command()
+	if len(args) == 0 || (args[0] != cobra.ShellCompRequestCmd && args[0] != cobra.ShellCompNoDescRequestCmd) {
+		return args
+	}
+
+	raw := strings.TrimSpace(s.RawCommand())
+	if strings.HasSuffix(raw, " ''") || strings.HasSuffix(raw, ` ""`) {
+		args = append(args, "")
+	}
+	return args
+}
+
+// hasFlag reports whether flag appears in args before the "--" terminator.
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == flag || arg == flag+"=true" {
+			return true
+		}
+	}
+	return false
+}
+
 // LoggingMiddleware logs the ssh connection and command.
 func LoggingMiddleware(sh ssh.Handler) ssh.Handler {
 	return func(s ssh.Session) {
@@ -181,9 +320,77 @@ func LoggingMiddleware(sh ssh.Handler) ssh.Handler {
 			)
 		}
 
+		kind := sessionType(s)
+		activeSessionsGauge.WithLabelValues(kind).Inc()
+		defer activeSessionsGauge.WithLabelValues(kind).Dec()
+
+		// SSH contexts can't be wrapped, the session span is stored in the
+		// context for the handlers to continue the trace.
+		_, span := tracing.Start(ctx, "ssh.session",
+			attribute.String("ssh.session.type", kind),
+			attribute.String("ssh.user", s.User()),
+			attribute.String("ssh.command", cmd.CommandName(s.Command())),
+			attribute.String("net.peer", addr),
+		)
+		defer span.End()
+		ctx.SetValue(tracing.ContextKey, span)
+
+		if !logr.SampleTransport() {
+			sh(s)
+			return
+		}
+
 		msg := fmt.Sprintf("user %q", s.User())
 		logger.Debug(msg+" connected", logArgs...)
 		sh(s)
 		logger.Debug(msg+" disconnected", append(logArgs, "duration", time.Since(ct))...)
 	}
 }
+
+// rootCommand returns the command tree of the CLI.
+func rootCommand(cfg *config.Config) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Short:         "Soft Serve is a self-hostable Git server for the command line.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.SetUsageTemplate(cmd.UsageTemplate)
+	rootCmd.SetUsageFunc(cmd.UsageFunc)
+	rootCmd.AddCommand(
+		cmd.GitUploadPackCommand(),
+		cmd.GitUploadArchiveCommand(),
+		cmd.GitReceivePackCommand(),
+		cmd.RepoCommand(),
+		cmd.BulkCommand(),
+		cmd.SettingsCommand(),
+		cmd.UserCommand(),
+		cmd.AdminCommand(func() *cobra.Command { return rootCommand(cfg) }),
+		cmd.InfoCommand(),
+		cmd.PubkeyCommand(),
+		cmd.SetUsernameCommand(),
+		cmd.PreferencesCommand(),
+		cmd.JWTCommand(),
+		cmd.TokenCommand(),
+		cmd.CompletionCommand(),
+	)
+
+	if cfg.LFS.Enabled {
+		rootCmd.AddCommand(
+			cmd.GitLFSAuthenticateCommand(),
+		)
+
+		if cfg.LFS.SSHEnabled {
+			rootCmd.AddCommand(
+				cmd.GitLFSTransfer(),
+			)
+		}
+	}
+
+	rootCmd.PersistentFlags().Bool("json", false, "print the output and the errors as JSON")
+	cmd.WrapUsageErrors(rootCmd)
+	cmd.RegisterCompletions(rootCmd)
+
+	return rootCmd
+}
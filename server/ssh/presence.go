@@ -0,0 +1,16 @@
+package ssh
+
+import (
+	"github.com/charmbracelet/soft-serve/server/presence"
+	"github.com/charmbracelet/ssh"
+)
+
+// PresenceMiddleware adds the presence tracker to the session context.
+func PresenceMiddleware(tr *presence.Tracker) func(ssh.Handler) ssh.Handler {
+	return func(sh ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			s.Context().SetValue(presence.ContextKey, tr)
+			sh(s)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package ssh
 
 import (
+	"context"
+	"io"
 	"strings"
 	"time"
 
@@ -8,7 +10,11 @@ import (
 	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/presence"
 	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/share"
+	sshcmd "github.com/charmbracelet/soft-serve/server/ssh/cmd"
+	"github.com/charmbracelet/soft-serve/server/tracing"
 	"github.com/charmbracelet/soft-serve/server/ui"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
 	"github.com/charmbracelet/ssh"
@@ -40,10 +46,29 @@ func SessionHandler(s ssh.Session) *tea.Program {
 		return nil
 	}
 
-	ctx := s.Context()
+	ctx := tracing.Context(s.Context())
 	be := backend.FromContext(ctx)
 	cfg := config.FromContext(ctx)
 	cmd := s.Command()
+
+	// uctx is the context of the user the TUI runs as. Admins open the TUI
+	// as another user with "admin su USERNAME".
+	var uctx context.Context = ctx
+	if len(cmd) == 3 && cmd[0] == "admin" && cmd[1] == "su" {
+		if !sshcmd.IsAdmin(ctx) {
+			wish.Fatalln(s, proto.ErrUnauthorized)
+			return nil
+		}
+
+		var err error
+		uctx, err = sshcmd.Impersonate(ctx, cmd[2], nil)
+		if err != nil {
+			wish.Fatalln(s, err)
+			return nil
+		}
+		cmd = nil
+	}
+
 	initialRepo := ""
 	if len(cmd) == 1 {
 		initialRepo = cmd[0]
@@ -56,12 +81,33 @@ func SessionHandler(s ssh.Session) *tea.Program {
 
 	envs := &sessionEnv{s}
 	output := termenv.NewOutput(s, termenv.WithColorCache(true), termenv.WithEnvironment(envs))
-	c := common.NewCommon(ctx, output, pty.Window.Width, pty.Window.Height)
+	c := common.NewCommon(uctx, output, pty.Window.Width, pty.Window.Height)
 	c.SetValue(common.ConfigKey, cfg)
+	c.SetValue(common.TimeFormatKey, be.TimeFormat(uctx, proto.UserFromContext(uctx)))
+
+	// Wrap the session output so that the TUI can be shared with other
+	// users.
+	var out io.Writer = s
+	var sess *share.Session
+	if reg := share.FromContext(ctx); reg != nil {
+		sess = reg.NewSession(s)
+		out = sess
+		c.SetValue(share.SessionContextKey, sess)
+	}
+
+	// Let other users know what we're looking at, if the user opted in.
+	var pc *presence.Client
+	if tr := presence.FromContext(ctx); tr != nil {
+		if user := proto.UserFromContext(ctx); user != nil && be.Presence(ctx, user) {
+			pc = tr.Join(user.Username())
+			c.SetValue(presence.ClientContextKey, pc)
+		}
+	}
+
 	m := ui.New(c, initialRepo)
 	p := tea.NewProgram(m,
 		tea.WithInput(s),
-		tea.WithOutput(s),
+		tea.WithOutput(out),
 		tea.WithAltScreen(),
 		tea.WithoutCatchPanics(),
 		tea.WithMouseCellMotion(),
@@ -70,9 +116,20 @@ func SessionHandler(s ssh.Session) *tea.Program {
 
 	tuiSessionCounter.WithLabelValues(initialRepo, pty.Term).Inc()
 
+	if sess != nil {
+		// Repaint the whole screen when a viewer joins.
+		sess.OnJoin = func() { p.Send(tea.ClearScreen()) }
+	}
+
 	start := time.Now()
 	go func() {
 		<-ctx.Done()
+		if sess != nil {
+			sess.Close()
+		}
+		if pc != nil {
+			pc.Leave()
+		}
 		tuiSessionDuration.WithLabelValues(initialRepo, pty.Term).Add(time.Since(start).Seconds())
 	}()
 
@@ -0,0 +1,56 @@
+package ssh
+
+import (
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sessions"
+	"github.com/charmbracelet/soft-serve/server/ssh/cmd"
+	"github.com/charmbracelet/ssh"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var activeUsersGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "soft_serve",
+	Subsystem: "ssh",
+	Name:      "active_users",
+	Help:      "The number of users with open SSH sessions",
+})
+
+// SessionsMiddleware registers the session with the session registry, and
+// adds the registry and the session to the session context.
+// This middleware must be run after the ContextMiddleware.
+func SessionsMiddleware(reg *sessions.Registry) func(ssh.Handler) ssh.Handler {
+	return func(sh ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ctx := s.Context()
+			info := sessions.Info{
+				ID:         sessionID(s),
+				RemoteAddr: s.RemoteAddr().String(),
+				Type:       sessionType(s),
+			}
+			if user := proto.UserFromContext(ctx); user != nil {
+				info.Username = user.Username()
+			}
+			if info.Type == "command" {
+				info.Command = cmd.CommandName(s.Command())
+			}
+
+			sess := reg.Add(info, func() error {
+				if conn, ok := ctx.Value(ssh.ContextKeyConn).(gossh.Conn); ok {
+					return conn.Close()
+				}
+				return s.Close()
+			})
+			activeUsersGauge.Set(float64(reg.Users()))
+			defer func() {
+				sess.Remove()
+				activeUsersGauge.Set(float64(reg.Users()))
+			}()
+
+			ctx.SetValue(sessions.ContextKey, reg)
+			ctx.SetValue(sessions.SessionContextKey, sess)
+			sh(s)
+		}
+	}
+}
@@ -0,0 +1,528 @@
+package ssh
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/repofs"
+	"github.com/charmbracelet/soft-serve/server/sftp"
+	"github.com/charmbracelet/ssh"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var sftpCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "ssh",
+	Name:      "sftp_sessions_total",
+	Help:      "The total number of SFTP sessions",
+})
+
+// SFTPHandler serves a read-only SFTP view of the repositories the user can
+// read. Each repository has a tree directory with the trees of its branches
+// and tags, an archive directory with a tarball of each branch and tag, and
+// an lfs directory with its Git LFS objects.
+// This handler must be run after the ContextMiddleware.
+func SFTPHandler(s ssh.Session) {
+	ctx := s.Context()
+	logger := log.FromContext(ctx).WithPrefix("ssh.sftp")
+	sftpCounter.Inc()
+
+	// Archives are generated on demand and only live as long as the session.
+	tmp, err := os.MkdirTemp("", "soft-serve-sftp-*")
+	if err != nil {
+		logger.Error("failed to create temporary directory", "err", err)
+		s.Exit(1) // nolint: errcheck
+		return
+	}
+
+	defer os.RemoveAll(tmp) // nolint: errcheck
+
+	fsys, err := newSFTPFS(ctx, s.PublicKey(), tmp)
+	if err != nil {
+		logger.Error("failed to list repositories", "err", err)
+		s.Exit(1) // nolint: errcheck
+		return
+	}
+
+	if err := sftp.NewServer(fsys, s, logger).Serve(); err != nil {
+		logger.Debug("sftp session ended", "err", err)
+	}
+}
+
+// sftpFS is the file system of an SFTP session. The top-level directories
+// are the repositories, names with slashes are nested directories.
+type sftpFS struct {
+	ctx context.Context
+	be  *backend.Backend
+	lfs bool
+	tmp string
+	// repos are the repositories the session can read. Hidden repositories
+	// aren't listed but can be opened by name.
+	repos map[string]proto.Repository
+	names []string
+
+	mu       sync.Mutex
+	trees    map[string]*repofs.FS
+	archives map[string]string
+}
+
+var _ fs.StatFS = (*sftpFS)(nil)
+
+func newSFTPFS(ctx ssh.Context, pk ssh.PublicKey, tmp string) (*sftpFS, error) {
+	be := backend.FromContext(ctx)
+	cfg := config.FromContext(ctx)
+	user := proto.UserFromContext(ctx)
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := &sftpFS{
+		ctx:      ctx,
+		be:       be,
+		lfs:      cfg.LFS.Enabled,
+		tmp:      tmp,
+		repos:    make(map[string]proto.Repository),
+		trees:    make(map[string]*repofs.FS),
+		archives: make(map[string]string),
+	}
+
+	for _, r := range repos {
+		level := be.AccessLevelForUser(ctx, r.Name(), user)
+		if user == nil && pk != nil {
+			// The key might be a deploy key of the repository.
+			level = be.AccessLevelByPublicKey(ctx, r.Name(), pk)
+		}
+
+		if level < access.ReadOnlyAccess {
+			continue
+		}
+
+		fsys.repos[r.Name()] = r
+		if !r.IsHidden() {
+			fsys.names = append(fsys.names, r.Name())
+		}
+	}
+
+	return fsys, nil
+}
+
+// lookup returns the repository name belongs to and the path within the
+// repository directory.
+func (f *sftpFS) lookup(name string) (proto.Repository, string, bool) {
+	segs := strings.Split(name, "/")
+	for i := len(segs); i > 0; i-- {
+		if r, ok := f.repos[strings.Join(segs[:i], "/")]; ok {
+			return r, strings.Join(segs[i:], "/"), true
+		}
+	}
+
+	return nil, "", false
+}
+
+// tree returns the tree file system of a repository.
+func (f *sftpFS) tree(r proto.Repository) (*repofs.FS, error) {
+	if t, ok := f.trees[r.Name()]; ok {
+		return t, nil
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	t := repofs.New(rr)
+	f.trees[r.Name()] = t
+	return t, nil
+}
+
+// Open implements fs.FS.
+func (f *sftpFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := f.open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return file, nil
+}
+
+// Stat implements fs.StatFS.
+func (f *sftpFS) Stat(name string) (fs.FileInfo, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close() // nolint: errcheck
+	return file.Stat()
+}
+
+func (f *sftpFS) open(name string) (fs.File, error) {
+	r, p, ok := f.lookup(name)
+	if !ok {
+		children := repofs.Children(f.names, name)
+		if name != "." && len(children) == 0 {
+			return nil, fs.ErrNotExist
+		}
+
+		entries := make([]fs.DirEntry, 0, len(children))
+		for _, child := range children {
+			var fi fs.FileInfo = &sftpInfo{name: child, dir: true}
+			if r, ok := f.repos[path.Join(name, child)]; ok {
+				fi = &sftpInfo{name: child, dir: true, modTime: r.UpdatedAt()}
+			}
+			entries = append(entries, fs.FileInfoToDirEntry(fi))
+		}
+
+		return newSFTPDir(&sftpInfo{name: path.Base(name), dir: true}, entries), nil
+	}
+
+	info := &sftpInfo{name: path.Base(r.Name()), dir: true, modTime: r.UpdatedAt()}
+	top, rest, _ := strings.Cut(p, "/")
+	switch top {
+	case "":
+		entries := []fs.DirEntry{
+			fs.FileInfoToDirEntry(&sftpInfo{name: "tree", dir: true, modTime: info.modTime}),
+			fs.FileInfoToDirEntry(&sftpInfo{name: "archive", dir: true, modTime: info.modTime}),
+		}
+		if f.lfs {
+			entries = append(entries, fs.FileInfoToDirEntry(&sftpInfo{name: "lfs", dir: true, modTime: info.modTime}))
+		}
+
+		return newSFTPDir(info, entries), nil
+	case "tree":
+		t, err := f.tree(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if rest == "" {
+			rest = "."
+		}
+
+		file, err := t.Open(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		if rest == "." {
+			return &sftpFile{File: file, info: &sftpInfo{name: "tree", dir: true, modTime: info.modTime}}, nil
+		}
+
+		return file, nil
+	case "archive":
+		return f.openArchive(r, rest)
+	case "lfs":
+		if f.lfs {
+			return f.openLFS(r, rest)
+		}
+	}
+
+	return nil, fs.ErrNotExist
+}
+
+// archiveExt is the extension of the repository archives.
+const archiveExt = ".tar.gz"
+
+func (f *sftpFS) openArchive(r proto.Repository, name string) (fs.File, error) {
+	t, err := f.tree(r)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := t.References()
+	if name != "" {
+		ref := strings.TrimSuffix(name, archiveExt)
+		if ref != name && contains(refs, ref) {
+			return f.archive(r, t, ref)
+		}
+	}
+
+	dir := name
+	if dir == "" {
+		dir = "."
+	}
+
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref + archiveExt
+	}
+
+	children := repofs.Children(names, dir)
+	if len(children) == 0 && dir != "." {
+		return nil, fs.ErrNotExist
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, child := range children {
+		ref := strings.TrimSuffix(path.Join(name, child), archiveExt)
+		fi := &sftpInfo{name: child, dir: !strings.HasSuffix(child, archiveExt) || !contains(refs, ref)}
+		if !fi.dir {
+			if rfi, err := t.Stat(ref); err == nil {
+				fi.modTime = rfi.ModTime()
+			}
+
+			// Archives are only generated when they're opened.
+			if p, ok := f.archives[r.Name()+"/"+ref]; ok {
+				if afi, err := os.Stat(p); err == nil {
+					fi.size = afi.Size()
+				}
+			}
+		}
+
+		entries = append(entries, fs.FileInfoToDirEntry(fi))
+	}
+
+	base := "archive"
+	if name != "" {
+		base = path.Base(name)
+	}
+
+	return newSFTPDir(&sftpInfo{name: base, dir: true, modTime: r.UpdatedAt()}, entries), nil
+}
+
+// archive opens the archive of a branch or tag, and generates it on first
+// use.
+func (f *sftpFS) archive(r proto.Repository, t *repofs.FS, ref string) (fs.File, error) {
+	rev, _, _ := t.Revision(ref)
+	rfi, err := t.Stat(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	key := r.Name() + "/" + ref
+	p, ok := f.archives[key]
+	if !ok {
+		rr, err := r.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		af, err := os.CreateTemp(f.tmp, "archive-*"+archiveExt)
+		if err != nil {
+			return nil, err
+		}
+
+		// Extract to a directory named after the repository and ref.
+		prefix := path.Base(r.Name()) + "-" + strings.ReplaceAll(ref, "/", "-") + "/"
+		err = rr.Archive(af, "tar.gz", rev, prefix)
+		if cerr := af.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(af.Name()) // nolint: errcheck
+			return nil, err
+		}
+
+		p = af.Name()
+		f.archives[key] = p
+	}
+
+	af, err := os.Open(filepath.Clean(p))
+	if err != nil {
+		return nil, err
+	}
+
+	afi, err := af.Stat()
+	if err != nil {
+		af.Close() // nolint: errcheck
+		return nil, err
+	}
+
+	return &sftpFile{File: af, info: &sftpInfo{
+		name:    path.Base(ref) + archiveExt,
+		size:    afi.Size(),
+		modTime: rfi.ModTime(),
+	}}, nil
+}
+
+func (f *sftpFS) openLFS(r proto.Repository, oid string) (fs.File, error) {
+	if strings.Contains(oid, "/") {
+		return nil, fs.ErrNotExist
+	}
+
+	if oid != "" {
+		obj, err := f.be.OpenLFSObject(f.ctx, r, oid)
+		if err != nil {
+			return nil, err
+		}
+
+		fi, err := obj.Stat()
+		if err != nil {
+			obj.Close() // nolint: errcheck
+			return nil, err
+		}
+
+		return &sftpFile{File: obj, info: &sftpInfo{
+			name:    oid,
+			size:    fi.Size(),
+			modTime: fi.ModTime(),
+		}}, nil
+	}
+
+	objs, err := f.be.LFSObjects(f.ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objs, func(i, j int) bool {
+		return objs[i].Oid < objs[j].Oid
+	})
+
+	entries := make([]fs.DirEntry, 0, len(objs))
+	for _, obj := range objs {
+		entries = append(entries, fs.FileInfoToDirEntry(&sftpInfo{
+			name:    obj.Oid,
+			size:    obj.Size,
+			modTime: obj.CreatedAt,
+		}))
+	}
+
+	return newSFTPDir(&sftpInfo{name: "lfs", dir: true, modTime: r.UpdatedAt()}, entries), nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// sftpInfo describes a virtual file or directory.
+type sftpInfo struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+var _ fs.FileInfo = (*sftpInfo)(nil)
+
+// Name implements fs.FileInfo.
+func (fi *sftpInfo) Name() string { return fi.name }
+
+// Size implements fs.FileInfo.
+func (fi *sftpInfo) Size() int64 { return fi.size }
+
+// Mode implements fs.FileInfo.
+func (fi *sftpInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// ModTime implements fs.FileInfo.
+func (fi *sftpInfo) ModTime() time.Time { return fi.modTime }
+
+// IsDir implements fs.FileInfo.
+func (fi *sftpInfo) IsDir() bool { return fi.dir }
+
+// Sys implements fs.FileInfo.
+func (fi *sftpInfo) Sys() any { return nil }
+
+// sftpFile is a file with virtual attributes. It hides the names of
+// temporary and storage files.
+type sftpFile struct {
+	fs.File
+	info *sftpInfo
+}
+
+// Stat implements fs.File.
+func (f *sftpFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+// Seek implements io.Seeker.
+func (f *sftpFile) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.File.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+	return 0, fs.ErrInvalid
+}
+
+// ReadAt implements io.ReaderAt.
+func (f *sftpFile) ReadAt(p []byte, off int64) (int, error) {
+	if r, ok := f.File.(io.ReaderAt); ok {
+		return r.ReadAt(p, off)
+	}
+	if _, err := f.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(f.File, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (f *sftpFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d, ok := f.File.(fs.ReadDirFile); ok {
+		return d.ReadDir(n)
+	}
+	return nil, fs.ErrInvalid
+}
+
+// sftpDir is a virtual directory.
+type sftpDir struct {
+	info    *sftpInfo
+	entries []fs.DirEntry
+}
+
+var _ fs.ReadDirFile = (*sftpDir)(nil)
+
+func newSFTPDir(info *sftpInfo, entries []fs.DirEntry) *sftpDir {
+	return &sftpDir{info: info, entries: entries}
+}
+
+// Close implements fs.File.
+func (d *sftpDir) Close() error { return nil }
+
+// Read implements fs.File.
+func (d *sftpDir) Read([]byte) (int, error) { return 0, fs.ErrInvalid }
+
+// Stat implements fs.File.
+func (d *sftpDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+// ReadDir implements fs.ReadDirFile.
+func (d *sftpDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,50 @@
+package ssh
+
+import (
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	bm "github.com/charmbracelet/wish/bubbletea"
+)
+
+// programs are the TUI programs of the active sessions.
+type programs struct {
+	mu sync.Mutex
+	m  map[*tea.Program]struct{}
+}
+
+// track wraps a program handler to keep the programs of the active sessions.
+func (ps *programs) track(h bm.ProgramHandler) bm.ProgramHandler {
+	return func(s ssh.Session) *tea.Program {
+		p := h(s)
+		if p == nil {
+			return nil
+		}
+
+		ps.mu.Lock()
+		if ps.m == nil {
+			ps.m = map[*tea.Program]struct{}{}
+		}
+		ps.m[p] = struct{}{}
+		ps.mu.Unlock()
+
+		go func() {
+			<-s.Context().Done()
+			ps.mu.Lock()
+			delete(ps.m, p)
+			ps.mu.Unlock()
+		}()
+
+		return p
+	}
+}
+
+// send sends a message to all the programs.
+func (ps *programs) send(msg tea.Msg) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for p := range ps.m {
+		go p.Send(msg)
+	}
+}
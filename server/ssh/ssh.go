@@ -2,10 +2,12 @@ package ssh
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/keygen"
@@ -13,8 +15,14 @@ import (
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/listener"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	"github.com/charmbracelet/soft-serve/server/presence"
 	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sessions"
+	"github.com/charmbracelet/soft-serve/server/share"
 	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/ui"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	bm "github.com/charmbracelet/wish/bubbletea"
@@ -48,15 +56,24 @@ type SSHServer struct { // nolint: revive
 	be     *backend.Backend
 	ctx    context.Context
 	logger *log.Logger
+	// tuis are the TUI programs of the active sessions, warned when the
+	// server shuts down.
+	tuis programs
+	// hostKeys are all the host keys, the negotiated ones first.
+	hostKeys  []gossh.Signer
+	listeners []net.Listener
 }
 
 // NewSSHServer returns a new SSHServer.
 func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 	cfg := config.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("ssh")
+	logger := logr.WithTrackedPrefix(log.FromContext(ctx), logr.SubsystemSSH)
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 	be := backend.FromContext(ctx)
+	reg := share.NewRegistry()
+	tr := presence.NewTracker()
+	sr := sessions.NewRegistry()
 
 	var err error
 	s := &SSHServer{
@@ -70,32 +87,61 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 		rm.MiddlewareWithLogger(
 			logger,
 			// BubbleTea middleware.
-			bm.MiddlewareWithProgramHandler(SessionHandler, termenv.ANSI256),
+			bm.MiddlewareWithProgramHandler(s.tuis.track(SessionHandler), termenv.ANSI256),
 			// CLI middleware.
 			CommandMiddleware,
+			// Shared sessions middleware.
+			JoinMiddleware(reg),
+			// Presence middleware.
+			PresenceMiddleware(tr),
+			// Sessions middleware.
+			SessionsMiddleware(sr),
+			// Host keys middleware.
+			s.HostKeysMiddleware,
 			// Logging middleware.
 			LoggingMiddleware,
+			// Rate limit middleware.
+			RateLimitMiddleware,
+			// Key restriction middleware.
+			KeyRestrictionMiddleware,
 			// Context middleware.
 			ContextMiddleware(cfg, dbx, datastore, be, logger),
 			// Authentication middleware.
 			// gossh.PublicKeyHandler doesn't guarantee that the public key
 			// is in fact the one used for authentication, so we need to
 			// check it again here.
-			AuthenticationMiddleware,
+			s.AuthenticationMiddleware,
 		),
 	}
 
 	s.srv, err = wish.NewServer(
 		ssh.PublicKeyAuth(s.PublicKeyHandler),
 		ssh.KeyboardInteractiveAuth(s.KeyboardInteractiveHandler),
-		wish.WithAddress(cfg.SSH.ListenAddr),
 		wish.WithHostKeyPath(cfg.SSH.KeyPath),
 		wish.WithMiddleware(mw...),
+		ssh.WrapConn(s.ConnCallback),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	keys, err := loadHostKeys(cfg.SSH.KeyPaths)
+	if err != nil {
+		return nil, err
+	}
+	s.addHostKeys(keys)
+
+	// Subsystems don't go through the session middlewares.
+	s.srv.SubsystemHandlers = map[string]ssh.SubsystemHandler{
+		"sftp": ssh.SubsystemHandler(
+			s.AuthenticationMiddleware(
+				ContextMiddleware(cfg, dbx, datastore, be, logger)(
+					KeyRestrictionMiddleware(RateLimitMiddleware(LoggingMiddleware(SessionsMiddleware(sr)(s.HostKeysMiddleware(SFTPHandler))))),
+				),
+			),
+		),
+	}
+
 	if config.IsDebug() {
 		s.srv.ServerConfigCallback = func(ctx ssh.Context) *gossh.ServerConfig {
 			return &gossh.ServerConfig{
@@ -122,12 +168,23 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 		}
 	}
 
+	s.listeners, err = listener.Listen(listener.SSH, cfg.SSH.ListenAddr)
+	if err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
+// Addr returns the addresses the SSH server listens on, its listen addresses
+// or the sockets passed by systemd.
+func (s *SSHServer) Addr() string {
+	return listener.String(s.listeners)
+}
+
 // ListenAndServe starts the SSH server.
 func (s *SSHServer) ListenAndServe() error {
-	return s.srv.ListenAndServe()
+	return listener.Serve(s.listeners, s.srv.Serve)
 }
 
 // Serve starts the SSH server on the given net.Listener.
@@ -140,8 +197,13 @@ func (s *SSHServer) Close() error {
 	return s.srv.Close()
 }
 
-// Shutdown gracefully shuts down the SSH server.
+// Shutdown gracefully shuts down the SSH server. It stops accepting
+// connections and waits for the active ones until ctx is done, active TUIs
+// show a warning until then.
 func (s *SSHServer) Shutdown(ctx context.Context) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		s.tuis.send(ui.ShutdownMsg(deadline))
+	}
 	return s.srv.Shutdown(ctx)
 }
 
@@ -158,6 +220,15 @@ func initializePermissions(ctx ssh.Context) {
 	}
 }
 
+// ConnCallback closes the connections from addresses that aren't allowed by
+// the server IP access rules.
+func (s *SSHServer) ConnCallback(_ ssh.Context, conn net.Conn) net.Conn {
+	if err := s.be.CheckAddress(s.ctx, "ssh", conn.RemoteAddr().String(), nil); err != nil {
+		return nil
+	}
+	return conn
+}
+
 // PublicKeyAuthHandler handles public key authentication.
 func (s *SSHServer) PublicKeyHandler(ctx ssh.Context, pk ssh.PublicKey) (allowed bool) {
 	if pk == nil {
@@ -168,9 +239,63 @@ func (s *SSHServer) PublicKeyHandler(ctx ssh.Context, pk ssh.PublicKey) (allowed
 		publicKeyCounter.WithLabelValues(strconv.FormatBool(*allowed)).Inc()
 	}(&allowed)
 
-	user, _ := s.be.UserByPublicKey(ctx, pk)
+	var user proto.User
+	if cert, ok := pk.(*gossh.Certificate); ok {
+		// Users with a certificate signed by a trusted authority are
+		// created on first login.
+		if err := checkSourceAddress(ctx.RemoteAddr(), cert); err != nil {
+			s.logger.Debug("certificate rejected", "addr", ctx.RemoteAddr(), "err", err)
+			s.be.Audit(ctx, proto.AuditEvent{
+				Action:  proto.AuditAuthFailure,
+				Target:  cert.KeyId,
+				Details: "ssh certificate: " + err.Error(),
+			})
+			return false
+		}
+
+		// Users are only created once the connection is authenticated, the
+		// certificate isn't verified yet.
+		var err error
+		user, err = s.be.UserByCertificate(ctx, cert)
+		if errors.Is(err, proto.ErrUserNotFound) {
+			allowed = true
+		}
+	} else {
+		user, _ = s.be.UserByPublicKey(ctx, pk)
+		if user != nil {
+			if err := s.be.CheckPublicKey(ctx, pk); err != nil {
+				s.logger.Info("public key rejected", "username", user.Username(), "addr", ctx.RemoteAddr(), "err", err)
+				s.be.Audit(ctx, proto.AuditEvent{
+					Action:   proto.AuditAuthFailure,
+					Username: user.Username(),
+					Details:  "ssh: " + err.Error() + ": " + gossh.FingerprintSHA256(pk),
+				})
+				return false
+			}
+		}
+	}
+
+	if user != nil && user.IsSuspended() {
+		s.logger.Info("suspended user rejected", "username", user.Username(), "addr", ctx.RemoteAddr())
+		s.be.Audit(ctx, proto.AuditEvent{
+			Action:   proto.AuditAuthFailure,
+			Username: user.Username(),
+			Details:  "ssh: " + proto.ErrUserSuspended.Error(),
+		})
+		return false
+	}
+
+	if user != nil && s.be.CheckAddress(ctx, "ssh", ctx.RemoteAddr().String(), user) != nil {
+		return false
+	}
+
 	if user != nil {
 		ctx.SetValue(proto.ContextKeyUser, user)
+	}
+
+	// Deploy keys don't belong to a user, they only get access to their
+	// repositories.
+	if allowed || user != nil || s.be.IsDeployKey(ctx, pk) {
 		allowed = true
 
 		// XXX: store the first "approved" public-key fingerprint in the
@@ -181,11 +306,47 @@ func (s *SSHServer) PublicKeyHandler(ctx ssh.Context, pk ssh.PublicKey) (allowed
 		// Set the public key fingerprint to be used for authentication.
 		perms.Extensions["pubkey-fp"] = gossh.FingerprintSHA256(pk)
 		ctx.SetValue(ssh.ContextKeyPermissions, perms)
+		ctx.SetValue(authenticationContextKey, &authentication{})
 	}
 
 	return
 }
 
+// checkSourceAddress checks the source-address critical option of a
+// certificate, the list of addresses and networks the certificate can be used
+// from.
+func checkSourceAddress(addr net.Addr, cert *gossh.Certificate) error {
+	allowed, ok := cert.CriticalOptions["source-address"]
+	if !ok {
+		return nil
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return fmt.Errorf("unsupported address %q", addr)
+	}
+
+	for _, src := range strings.Split(allowed, ",") {
+		if src == "" {
+			continue
+		}
+
+		if strings.Contains(src, "/") {
+			_, ipnet, err := net.ParseCIDR(src)
+			if err != nil {
+				return fmt.Errorf("invalid source-address %q: %w", src, err)
+			}
+			if ipnet.Contains(tcpAddr.IP) {
+				return nil
+			}
+		} else if ip := net.ParseIP(src); ip != nil && ip.Equal(tcpAddr.IP) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("source address %q not allowed", tcpAddr.IP)
+}
+
 // KeyboardInteractiveHandler handles keyboard interactive authentication.
 // This is used after all public key authentication has failed.
 func (s *SSHServer) KeyboardInteractiveHandler(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
@@ -0,0 +1,56 @@
+package sshutils
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const sshsigMagic = "SSHSIG"
+
+// Sign returns the armored SSH signature of msg in the namespace, i.e. "git"
+// for git objects, as created by ssh-keygen -Y sign.
+func Sign(signer gossh.Signer, namespace string, msg []byte) (string, error) {
+	h := sha512.Sum512(msg)
+	data := gossh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Hash      string
+	}{namespace, "", "sha512", string(h[:])})
+
+	var sig *gossh.Signature
+	var err error
+	if as, ok := signer.(gossh.AlgorithmSigner); ok && signer.PublicKey().Type() == gossh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, append([]byte(sshsigMagic), data...), gossh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = signer.Sign(rand.Reader, append([]byte(sshsigMagic), data...))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	blob := gossh.Marshal(struct {
+		Version   uint32
+		PublicKey string
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Signature string
+	}{1, string(signer.PublicKey().Marshal()), namespace, "", "sha512", string(gossh.Marshal(sig))})
+
+	var sb strings.Builder
+	sb.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	enc := base64.StdEncoding.EncodeToString(append([]byte(sshsigMagic), blob...))
+	for len(enc) > 70 {
+		sb.WriteString(enc[:70] + "\n")
+		enc = enc[70:]
+	}
+	sb.WriteString(enc + "\n")
+	sb.WriteString("-----END SSH SIGNATURE-----\n")
+
+	return sb.String(), nil
+}
@@ -0,0 +1,54 @@
+package sshutils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/keygen"
+)
+
+func TestSign(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	msg := []byte("object 0123456789abcdef\ntype commit\ntag v1.0.0\n\nrelease\n")
+	ed25519Key, rsaKey := generateKeys(t)
+	for name, kp := range map[string]*keygen.SSHKeyPair{"ed25519": ed25519Key, "rsa": rsaKey} {
+		kp := kp
+		t.Run(name, func(t *testing.T) {
+			sig, err := Sign(kp.Signer(), "git", msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(sig, "-----BEGIN SSH SIGNATURE-----\n") {
+				t.Fatalf("invalid armor: %q", sig)
+			}
+
+			dir := t.TempDir()
+			signers := filepath.Join(dir, "allowed_signers")
+			sigFile := filepath.Join(dir, "msg.sig")
+			if err := os.WriteFile(signers, []byte("soft@serve "+kp.AuthorizedKey()+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(sigFile, []byte(sig), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			for ns, good := range map[string]bool{"git": true, "file": false} {
+				cmd := exec.Command("ssh-keygen", "-Y", "verify", "-f", signers, "-I", "soft@serve", "-n", ns, "-s", sigFile)
+				cmd.Stdin = strings.NewReader(string(msg))
+				out, err := cmd.CombinedOutput()
+				if good && err != nil {
+					t.Errorf("verify %s: %v: %s", ns, err, out)
+				}
+				if !good && err == nil {
+					t.Errorf("verify %s: expected an error", ns)
+				}
+			}
+		})
+	}
+}
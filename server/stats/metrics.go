@@ -0,0 +1,194 @@
+package stats
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// OtherRepoLabel is the repo label of the repositories past the repo labels
+// limit.
+const OtherRepoLabel = "_other"
+
+// Transports of git operations.
+const (
+	TransportSSH    = "ssh"
+	TransportHTTP   = "http"
+	TransportDaemon = "daemon"
+)
+
+var (
+	gitOperationCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "operations_total",
+		Help:      "The total number of git operations",
+	}, []string{"transport", "service", "repo"})
+
+	gitBytesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "bytes_total",
+		Help:      "The total number of bytes transferred by git operations",
+	}, []string{"transport", "service", "repo", "direction"})
+
+	gitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "operation_duration_seconds",
+		Help:      "The duration of git operations",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"transport", "service"})
+
+	gitSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "operation_bytes",
+		Help:      "The bytes transferred by git operations, received and sent",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+	}, []string{"transport", "service"})
+
+	jobRunCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "jobs",
+		Name:      "runs_total",
+		Help:      "The total number of background job runs",
+	}, []string{"job"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "soft_serve",
+		Subsystem: "jobs",
+		Name:      "duration_seconds",
+		Help:      "The duration of background job runs",
+		Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+	}, []string{"job"})
+
+	jobLastRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "soft_serve",
+		Subsystem: "jobs",
+		Name:      "last_run_timestamp_seconds",
+		Help:      "The time of the last run of background jobs",
+	}, []string{"job"})
+
+	jobRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "soft_serve",
+		Subsystem: "jobs",
+		Name:      "running",
+		Help:      "The number of running background jobs",
+	}, []string{"job"})
+)
+
+var repoLabels = struct {
+	sync.Mutex
+	max  int
+	seen map[string]struct{}
+}{
+	max:  -1,
+	seen: map[string]struct{}{},
+}
+
+// SetMaxRepoLabels limits the number of distinct repo label values of the
+// metrics below. Zero disables the repo labels, and a negative number doesn't
+// limit them.
+func SetMaxRepoLabels(n int) {
+	repoLabels.Lock()
+	defer repoLabels.Unlock()
+	repoLabels.max = n
+}
+
+// RepoLabel returns the repo label value of a repository. The first
+// repositories get their own label, the rest are counted under
+// OtherRepoLabel, in order to keep the cardinality of the metrics bounded.
+func RepoLabel(repo string) string {
+	repoLabels.Lock()
+	defer repoLabels.Unlock()
+	if repoLabels.max < 0 {
+		return repo
+	}
+
+	if _, ok := repoLabels.seen[repo]; ok {
+		return repo
+	}
+
+	if len(repoLabels.seen) >= repoLabels.max {
+		return OtherRepoLabel
+	}
+
+	repoLabels.seen[repo] = struct{}{}
+	return repo
+}
+
+// ObserveGit counts the bytes of the standard input and output of a git
+// service command. The returned function records the operation, its duration
+// and the bytes transferred, it must be called after the command is done.
+func ObserveGit(transport string, service git.Service, repo string, scmd *git.ServiceCommand) func() {
+	start := time.Now()
+	var in, out countingReaderWriter
+	if scmd.Stdin != nil {
+		in.r = scmd.Stdin
+		scmd.Stdin = &in
+	}
+	if scmd.Stdout != nil {
+		out.w = scmd.Stdout
+		scmd.Stdout = &out
+	}
+
+	return func() {
+		svc := service.String()
+		label := RepoLabel(repo)
+		received, sent := float64(in.Count()), float64(out.Count())
+		gitOperationCounter.WithLabelValues(transport, svc, label).Inc()
+		gitBytesCounter.WithLabelValues(transport, svc, label, "received").Add(received)
+		gitBytesCounter.WithLabelValues(transport, svc, label, "sent").Add(sent)
+		gitDuration.WithLabelValues(transport, svc).Observe(time.Since(start).Seconds())
+		gitSize.WithLabelValues(transport, svc).Observe(received + sent)
+	}
+}
+
+// InstrumentJob returns a background job function that records the runs,
+// the duration and the last run time of the job.
+func InstrumentJob(name string, fn func() error) func() error {
+	return func() error {
+		start := time.Now()
+		jobRunning.WithLabelValues(name).Inc()
+		defer func() {
+			jobRunning.WithLabelValues(name).Dec()
+			jobRunCounter.WithLabelValues(name).Inc()
+			jobDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+			jobLastRun.WithLabelValues(name).Set(float64(time.Now().Unix()))
+		}()
+		return fn()
+	}
+}
+
+// countingReaderWriter counts the bytes read from its reader or written to
+// its writer.
+type countingReaderWriter struct {
+	r io.Reader
+	w io.Writer
+	n atomic.Int64
+}
+
+// Read implements io.Reader.
+func (c *countingReaderWriter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// Write implements io.Writer.
+func (c *countingReaderWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// Count returns the number of bytes read or written.
+func (c *countingReaderWriter) Count() int64 {
+	return c.n.Load()
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRepoLabel(t *testing.T) {
+	SetMaxRepoLabels(2)
+	defer SetMaxRepoLabels(-1)
+
+	cases := []struct {
+		repo string
+		want string
+	}{
+		{"repo1", "repo1"},
+		{"repo2", "repo2"},
+		{"repo3", OtherRepoLabel},
+		{"repo1", "repo1"},
+		{"repo4", OtherRepoLabel},
+	}
+	for _, c := range cases {
+		if got := RepoLabel(c.repo); got != c.want {
+			t.Errorf("RepoLabel(%q) = %q, want %q", c.repo, got, c.want)
+		}
+	}
+}
+
+func TestObserveGit(t *testing.T) {
+	var out bytes.Buffer
+	scmd := git.ServiceCommand{
+		Stdin:  strings.NewReader("want"),
+		Stdout: &out,
+	}
+
+	done := ObserveGit(TransportSSH, git.UploadPackService, "observed", &scmd)
+	if _, err := io.Copy(scmd.Stdout, scmd.Stdin); err != nil {
+		t.Fatal(err)
+	}
+	done()
+
+	if out.String() != "want" {
+		t.Errorf("output = %q, want %q", out.String(), "want")
+	}
+	if n := testutil.ToFloat64(gitOperationCounter.WithLabelValues(TransportSSH, "git-upload-pack", "observed")); n != 1 {
+		t.Errorf("operations = %v, want 1", n)
+	}
+	for _, dir := range []string{"received", "sent"} {
+		if n := testutil.ToFloat64(gitBytesCounter.WithLabelValues(TransportSSH, "git-upload-pack", "observed", dir)); n != 4 {
+			t.Errorf("%s bytes = %v, want 4", dir, n)
+		}
+	}
+}
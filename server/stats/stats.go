@@ -2,31 +2,67 @@ package stats
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/listener"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // StatsServer is a server for collecting and reporting statistics.
 type StatsServer struct { //nolint:revive
-	ctx    context.Context
-	cfg    *config.Config
-	server *http.Server
+	ctx       context.Context
+	cfg       *config.Config
+	server    *http.Server
+	listeners []net.Listener
 }
 
 // NewStatsServer returns a new StatsServer.
 func NewStatsServer(ctx context.Context) (*StatsServer, error) {
 	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	SetMaxRepoLabels(cfg.Stats.MaxRepoLabels)
+
+	// The database pool stats are collected per server, on top of the
+	// package metrics.
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer}
+	if dbx := db.FromContext(ctx); dbx != nil {
+		reg := prometheus.NewRegistry()
+		if err := reg.Register(collectors.NewDBStatsCollector(dbx.DB.DB, "soft_serve")); err != nil {
+			return nil, err
+		}
+		gatherers = append(gatherers, reg)
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}),
+	))
+	// Reject the addresses that aren't allowed by the server IP access rules.
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := be.CheckAddress(ctx, "stats", r.RemoteAddr, nil); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+	ls, err := listener.Listen(listener.Stats, cfg.Stats.ListenAddr)
+	if err != nil {
+		return nil, err
+	}
 	return &StatsServer{
-		ctx: ctx,
-		cfg: cfg,
+		ctx:       ctx,
+		cfg:       cfg,
+		listeners: ls,
 		server: &http.Server{
-			Addr:              cfg.Stats.ListenAddr,
-			Handler:           mux,
+			Handler:           h,
 			ReadHeaderTimeout: time.Second * 10,
 			ReadTimeout:       time.Second * 10,
 			WriteTimeout:      time.Second * 10,
@@ -35,9 +71,15 @@ func NewStatsServer(ctx context.Context) (*StatsServer, error) {
 	}, nil
 }
 
+// Addr returns the addresses the StatsServer listens on, its listen addresses
+// or the sockets passed by systemd.
+func (s *StatsServer) Addr() string {
+	return listener.String(s.listeners)
+}
+
 // ListenAndServe starts the StatsServer.
 func (s *StatsServer) ListenAndServe() error {
-	return s.server.ListenAndServe()
+	return listener.Serve(s.listeners, s.server.Serve)
 }
 
 // Shutdown gracefully shuts down the StatsServer.
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"path"
+	"path/filepath"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+)
+
+// NewLFSStorage returns the storage of the Git LFS objects of the repository
+// with the given ID, using the storage backend configured in cfg.
+func NewLFSStorage(cfg *config.Config, repoID string) Storage {
+	switch cfg.LFS.Storage {
+	case "s3":
+		return NewS3Storage(cfg.LFS.S3, path.Join("lfs", repoID))
+	default:
+		return NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	}
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+)
+
+// RepoStorage is an interface for storing the bare repositories. Git runs in
+// the path of a repository, so its files are read and written there. Storages
+// keep the repositories on a filesystem, local or shared between servers like
+// NFS, or keep a local copy of remote storage, like object storage, in sync.
+type RepoStorage interface {
+	// Path returns the path of a repository.
+	Path(name string) string
+	// Exists reports whether a repository exists.
+	Exists(name string) (bool, error)
+	// Remove removes a repository.
+	Remove(name string) error
+	// Rename renames a repository. The new repository must not exist.
+	Rename(oldName, newName string) error
+	// Lock locks a repository while it's created, renamed, or removed. The
+	// returned function unlocks it. Storages shared between servers lock
+	// them for all the servers.
+	Lock(ctx context.Context, name string) (func(), error)
+}
+
+// RepoStorageContextKey is the context key of the repository storage.
+var RepoStorageContextKey = &struct{ string }{"repo-storage"}
+
+// RepoStorageFromContext returns the repository storage from the given
+// context.
+func RepoStorageFromContext(ctx context.Context) RepoStorage {
+	if s, ok := ctx.Value(RepoStorageContextKey).(RepoStorage); ok {
+		return s
+	}
+
+	return nil
+}
+
+// WithRepoStorageContext returns a new context with the given repository
+// storage.
+func WithRepoStorageContext(ctx context.Context, s RepoStorage) context.Context {
+	return context.WithValue(ctx, RepoStorageContextKey, s)
+}
+
+// LocalRepoStorage is a repository storage that stores the repositories in
+// the repos directory of the data path, following the repository path
+// template.
+type LocalRepoStorage struct {
+	cfg *config.Config
+	mtx sync.Mutex
+	// locks are the locks of the repositories, an entry is kept for each
+	// repository locked once.
+	locks map[string]chan struct{}
+}
+
+var _ RepoStorage = (*LocalRepoStorage)(nil)
+
+// NewLocalRepoStorage creates a new LocalRepoStorage.
+func NewLocalRepoStorage(cfg *config.Config) *LocalRepoStorage {
+	return &LocalRepoStorage{
+		cfg:   cfg,
+		locks: map[string]chan struct{}{},
+	}
+}
+
+// Path implements RepoStorage.
+func (l *LocalRepoStorage) Path(name string) string {
+	return l.cfg.RepoPath(name)
+}
+
+// Exists implements RepoStorage.
+func (l *LocalRepoStorage) Exists(name string) (bool, error) {
+	_, err := os.Stat(l.Path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Remove implements RepoStorage.
+func (l *LocalRepoStorage) Remove(name string) error {
+	rp := l.Path(name)
+	defer l.cfg.RemoveEmptyRepoDirs(rp)
+	return os.RemoveAll(rp)
+}
+
+// Rename implements RepoStorage.
+func (l *LocalRepoStorage) Rename(oldName, newName string) error {
+	op, np := l.Path(oldName), l.Path(newName)
+	if _, err := os.Stat(np); err == nil {
+		return fs.ErrExist
+	}
+
+	// Make sure the new repository parent directory exists.
+	if err := os.MkdirAll(filepath.Dir(np), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := os.Rename(op, np); err != nil {
+		return err
+	}
+
+	l.cfg.RemoveEmptyRepoDirs(op)
+	return nil
+}
+
+// Lock implements RepoStorage. The repositories are only locked for this
+// process.
+func (l *LocalRepoStorage) Lock(ctx context.Context, name string) (func(), error) {
+	l.mtx.Lock()
+	lock, ok := l.locks[name]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		l.locks[name] = lock
+	}
+	l.mtx.Unlock()
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/matryer/is"
+)
+
+func TestLocalRepoStorage(t *testing.T) {
+	is := is.New(t)
+	cfg := config.DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.RepoPathTemplate = config.ShardedRepoPathTemplate
+	is.NoErr(cfg.Validate())
+	s := NewLocalRepoStorage(cfg)
+
+	is.NoErr(os.MkdirAll(s.Path("repo"), os.ModePerm))
+	exists, err := s.Exists("repo")
+	is.NoErr(err)
+	is.True(exists)
+
+	is.NoErr(s.Rename("repo", "group/repo"))
+	exists, err = s.Exists("repo")
+	is.NoErr(err)
+	is.True(!exists)
+	// The shards of the old path are removed.
+	_, err = os.Stat(filepath.Dir(s.Path("repo")))
+	is.True(os.IsNotExist(err))
+
+	is.NoErr(os.MkdirAll(s.Path("other"), os.ModePerm))
+	is.True(s.Rename("other", "group/repo") != nil) // new repository exists
+
+	is.NoErr(s.Remove("group/repo"))
+	exists, err = s.Exists("group/repo")
+	is.NoErr(err)
+	is.True(!exists)
+}
+
+func TestLocalRepoStorageLock(t *testing.T) {
+	is := is.New(t)
+	s := NewLocalRepoStorage(config.DefaultConfig())
+	unlock, err := s.Lock(context.Background(), "repo")
+	is.NoErr(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.Lock(ctx, "repo")
+	is.Equal(err, context.DeadlineExceeded)
+
+	// Other repositories aren't locked.
+	unlockOther, err := s.Lock(context.Background(), "other")
+	is.NoErr(err)
+	unlockOther()
+
+	unlock()
+	unlock, err = s.Lock(context.Background(), "repo")
+	is.NoErr(err)
+	unlock()
+}
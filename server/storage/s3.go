@@ -0,0 +1,528 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DefaultRegion   = "us-east-1"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+)
+
+// S3Storage is a storage implementation that stores objects in an
+// S3-compatible object storage, such as Amazon S3 or MinIO.
+type S3Storage struct {
+	cfg    config.S3Config
+	root   string
+	client *http.Client
+	now    func() time.Time
+}
+
+var (
+	_ Storage   = (*S3Storage)(nil)
+	_ Presigner = (*S3Storage)(nil)
+)
+
+// NewS3Storage creates a new S3Storage. Object names are relative to root
+// within the bucket.
+func NewS3Storage(cfg config.S3Config, root string) *S3Storage {
+	if cfg.Region == "" {
+		cfg.Region = s3DefaultRegion
+	}
+	return &S3Storage{
+		cfg:    cfg,
+		root:   strings.Trim(path.Join(cfg.Prefix, root), "/"),
+		client: http.DefaultClient,
+		now:    time.Now,
+	}
+}
+
+// Open implements Storage.
+func (s *S3Storage) Open(name string) (Object, error) {
+	fi, err := s.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Object{
+		s:    s,
+		name: name,
+		info: fi,
+	}, nil
+}
+
+// Stat implements Storage.
+func (s *S3Storage) Stat(name string) (fs.FileInfo, error) {
+	resp, err := s.do(http.MethodHead, name, nil, -1, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close() // nolint: errcheck
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &s3FileInfo{
+		name:    path.Base(name),
+		size:    resp.ContentLength,
+		modTime: modTime,
+	}, nil
+}
+
+// Put implements Storage.
+func (s *S3Storage) Put(name string, r io.Reader) (int64, error) {
+	// S3 requires the content length of uploaded objects. Buffer the object
+	// to a temporary file if the size isn't known upfront.
+	size := int64(-1)
+	switch v := r.(type) {
+	case *bytes.Reader:
+		size = int64(v.Len())
+	case *bytes.Buffer:
+		size = int64(v.Len())
+	case *strings.Reader:
+		size = int64(v.Len())
+	}
+
+	if size < 0 {
+		f, err := os.CreateTemp("", "soft-serve-s3-*")
+		if err != nil {
+			return 0, err
+		}
+		defer os.Remove(f.Name()) // nolint: errcheck
+		defer f.Close()           // nolint: errcheck
+
+		size, err = io.Copy(f, r)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+		r = f
+	}
+
+	resp, err := s.do(http.MethodPut, name, r, size, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close() // nolint: errcheck
+
+	return size, nil
+}
+
+// Delete implements Storage.
+func (s *S3Storage) Delete(name string) error {
+	resp, err := s.do(http.MethodDelete, name, nil, -1, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Exists implements Storage.
+func (s *S3Storage) Exists(name string) (bool, error) {
+	_, err := s.Stat(name)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Rename implements Storage. S3 doesn't support renaming objects, so the
+// object is copied and the original is deleted. Objects larger than 5GiB
+// can't be renamed.
+func (s *S3Storage) Rename(oldName, newName string) error {
+	src := "/" + s.cfg.Bucket + "/" + s3Escape(s.key(oldName), false)
+	resp, err := s.do(http.MethodPut, newName, nil, 0, http.Header{
+		"X-Amz-Copy-Source": {src},
+	})
+	if err != nil {
+		return err
+	}
+
+	// A copy request can fail after S3 sent a 200 OK response, in which
+	// case the error is in the response body.
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := s3ParseError(http.MethodPut, newName, body); err != nil {
+		return err
+	}
+
+	return s.Delete(oldName)
+}
+
+// PresignGet implements Presigner.
+func (s *S3Storage) PresignGet(name string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, name, expires, nil)
+}
+
+// PresignPut implements Presigner. The returned headers must be sent along
+// with the upload request. They make S3 verify that the SHA-256 checksum of
+// the uploaded content matches checksum.
+func (s *S3Storage) PresignPut(name string, checksum []byte, expires time.Duration) (string, http.Header, error) {
+	var hdr http.Header
+	if len(checksum) > 0 {
+		hdr = http.Header{
+			"X-Amz-Checksum-Sha256": {base64.StdEncoding.EncodeToString(checksum)},
+		}
+	}
+
+	u, err := s.presign(http.MethodPut, name, expires, hdr)
+	return u, hdr, err
+}
+
+// key returns the object key of name.
+func (s *S3Storage) key(name string) string {
+	return strings.TrimPrefix(path.Join(s.root, name), "/")
+}
+
+// objectURL returns the URL of the object with the given name.
+func (s *S3Storage) objectURL(name string) (*url.URL, error) {
+	u, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	key := s.key(name)
+	if s.cfg.PathStyle {
+		u.Path = "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = s3Escape(u.Path, false)
+
+	return u, nil
+}
+
+// do sends a signed request for the object with the given name. A negative
+// size means the request has no body. Responses with an error status code
+// are returned as errors.
+func (s *S3Storage) do(method, name string, body io.Reader, size int64, hdr http.Header) (*http.Response, error) {
+	u, err := s.objectURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	if size >= 0 {
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+	}
+
+	s.sign(req, s3UnsignedPayload)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close() // nolint: errcheck
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, &fs.PathError{Op: strings.ToLower(method), Path: name, Err: fs.ErrNotExist}
+		}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		if err := s3ParseError(method, name, b); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("s3: %s %s: %s", method, name, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// sign signs the request using AWS Signature Version 4. All the headers set on
+// the request are signed.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3Storage) sign(req *http.Request, payloadHash string) {
+	t := s.now().UTC()
+	req.Header.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	signedHeaders, canonicalHeaders := s3CanonicalHeaders(headers)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(t)
+	signature := s.signature(t, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// presign returns a presigned URL of the object with the given name. hdr are
+// additional headers that the request must use.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+func (s *S3Storage) presign(method, name string, expires time.Duration, hdr http.Header) (string, error) {
+	u, err := s.objectURL(name)
+	if err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{
+		"host": u.Host,
+	}
+	for k, v := range hdr {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	signedHeaders, canonicalHeaders := s3CanonicalHeaders(headers)
+
+	t := s.now().UTC()
+	scope := s.scope(t)
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", s3Algorithm)
+	q.Set("X-Amz-Credential", s.cfg.AccessKeyID+"/"+scope)
+	q.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	q.Set("X-Amz-SignedHeaders", signedHeaders)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		s3CanonicalQuery(q),
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	u.RawQuery = s3CanonicalQuery(q) + "&X-Amz-Signature=" + s.signature(t, scope, canonicalRequest)
+	return u.String(), nil
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return strings.Join([]string{t.Format(s3DateFormat), s.cfg.Region, "s3", "aws4_request"}, "/")
+}
+
+func (s *S3Storage) signature(t time.Time, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3TimeFormat),
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + s.cfg.SecretAccessKey)
+	for _, v := range []string{t.Format(s3DateFormat), s.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data)) // nolint: errcheck
+	return h.Sum(nil)
+}
+
+// s3CanonicalHeaders returns the signed headers and canonical headers of a
+// request.
+func s3CanonicalHeaders(headers map[string]string) (string, string) {
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, k := range names {
+		sb.WriteString(k + ":" + headers[k] + "\n")
+	}
+
+	return strings.Join(names, ";"), sb.String()
+}
+
+// s3CanonicalQuery returns the canonical query string of a request.
+func s3CanonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	params := make([]string, 0, len(q))
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			params = append(params, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// s3Escape URI-encodes s the way AWS expects. Slashes are only encoded if
+// encodeSlash is true.
+func s3Escape(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			sb.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+// s3ParseError returns the error contained in an S3 error response body, if
+// any.
+func s3ParseError(method, name string, body []byte) error {
+	if !bytes.Contains(body, []byte("<Error>")) {
+		return nil
+	}
+
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.Unmarshal(body, &e); err != nil {
+		return fmt.Errorf("s3: %s %s: invalid error response: %w", method, name, err)
+	}
+
+	return fmt.Errorf("s3: %s %s: %s: %s", method, name, e.Code, e.Message)
+}
+
+// s3Object is an object stored in S3. The content is streamed using range
+// requests starting at the current offset.
+type s3Object struct {
+	s      *S3Storage
+	name   string
+	info   fs.FileInfo
+	offset int64
+	body   io.ReadCloser
+}
+
+var _ Object = (*s3Object)(nil)
+
+// Name implements Object.
+func (o *s3Object) Name() string {
+	return o.name
+}
+
+// Stat implements fs.File.
+func (o *s3Object) Stat() (fs.FileInfo, error) {
+	return o.info, nil
+}
+
+// Read implements fs.File.
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.info.Size() {
+		return 0, io.EOF
+	}
+
+	if o.body == nil {
+		resp, err := o.s.do(http.MethodGet, o.name, nil, -1, http.Header{
+			"Range": {fmt.Sprintf("bytes=%d-", o.offset)},
+		})
+		if err != nil {
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.info.Size()
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != o.offset && o.body != nil {
+		o.body.Close() // nolint: errcheck
+		o.body = nil
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+// Close implements fs.File.
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+// s3FileInfo implements fs.FileInfo for S3 objects.
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+var _ fs.FileInfo = (*s3FileInfo)(nil)
+
+func (fi *s3FileInfo) Name() string       { return fi.name }
+func (fi *s3FileInfo) Size() int64        { return fi.size }
+func (fi *s3FileInfo) Mode() fs.FileMode  { return 0o644 }
+func (fi *s3FileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *s3FileInfo) IsDir() bool        { return false }
+func (fi *s3FileInfo) Sys() any           { return nil }
@@ -3,6 +3,8 @@ package storage
 import (
 	"io"
 	"io/fs"
+	"net/http"
+	"time"
 )
 
 // Object is an interface for objects that can be stored.
@@ -21,3 +23,14 @@ type Storage interface {
 	Exists(name string) (bool, error)
 	Rename(oldName, newName string) error
 }
+
+// Presigner is implemented by storages that can generate URLs to access
+// objects directly, without going through Soft Serve.
+type Presigner interface {
+	// PresignGet returns a URL to download the object with the given name.
+	PresignGet(name string, expires time.Duration) (string, error)
+	// PresignPut returns a URL to upload the object with the given name and
+	// the headers the upload request must use. If checksum is not empty, the
+	// storage verifies the SHA-256 checksum of the uploaded content.
+	PresignPut(name string, checksum []byte, expires time.Duration) (string, http.Header, error)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/db/models"
 )
@@ -13,7 +14,8 @@ type AccessTokenStore interface {
 	GetAccessToken(ctx context.Context, h db.Handler, id int64) (models.AccessToken, error)
 	GetAccessTokenByToken(ctx context.Context, h db.Handler, token string) (models.AccessToken, error)
 	GetAccessTokensByUserID(ctx context.Context, h db.Handler, userID int64) ([]models.AccessToken, error)
-	CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time) (models.AccessToken, error)
+	CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time, level access.AccessLevel, repoID int64) (models.AccessToken, error)
+	RotateAccessTokenForUser(ctx context.Context, h db.Handler, userID int64, id int64, token string, expiresAt time.Time) error
 	DeleteAccessToken(ctx context.Context, h db.Handler, id int64) error
 	DeleteAccessTokenForUser(ctx context.Context, h db.Handler, userID int64, id int64) error
 }
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// AuditEventFilter selects audit events. Zero fields match all events.
+type AuditEventFilter struct {
+	// Action matches the action, or the actions it's a prefix of, i.e. "repo"
+	// matches "repo.create".
+	Action   string
+	Username string
+	Repo     string
+	Since    time.Time
+	Until    time.Time
+	// Limit is the maximum number of most recent events returned.
+	Limit int
+}
+
+// AuditEventStore is an interface for recording and querying the audit log.
+// Audit events are never updated or deleted.
+type AuditEventStore interface {
+	CreateAuditEvent(ctx context.Context, h db.Handler, event models.AuditEvent) error
+	GetAuditEvents(ctx context.Context, h db.Handler, filter AuditEventFilter) ([]models.AuditEvent, error)
+}
@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// BranchProtectionStore is an interface for managing branch protection rules.
+type BranchProtectionStore interface {
+	GetBranchProtectionsByRepo(ctx context.Context, h db.Handler, repo string) ([]models.BranchProtection, error)
+	GetBranchProtectionByRepoAndPattern(ctx context.Context, h db.Handler, repo string, pattern string) (models.BranchProtection, error)
+	UpsertBranchProtection(ctx context.Context, h db.Handler, repo string, pattern string, noForcePush bool, noDeletion bool, requireSigned bool, restrictPush bool) error
+	DeleteBranchProtectionByRepoAndPattern(ctx context.Context, h db.Handler, repo string, pattern string) error
+
+	AddBranchProtectionUser(ctx context.Context, h db.Handler, protectionID int64, username string) error
+	RemoveBranchProtectionUsers(ctx context.Context, h db.Handler, protectionID int64) error
+	ListBranchProtectionUsers(ctx context.Context, h db.Handler, protectionID int64) ([]models.User, error)
+}
@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/db/models"
 	"github.com/charmbracelet/soft-serve/server/store"
@@ -14,27 +16,40 @@ type accessTokenStore struct{}
 var _ store.AccessTokenStore = (*accessTokenStore)(nil)
 
 // CreateAccessToken implements store.AccessTokenStore.
-func (s *accessTokenStore) CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time) (models.AccessToken, error) {
-	queryWithoutExpires := `INSERT INTO access_tokens (name, user_id, token, created_at, updated_at)
-	VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`
-	queryWithExpires := `INSERT INTO access_tokens (name, user_id, token, expires_at, created_at, updated_at)
-	VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`
-
-	query := queryWithoutExpires
-	values := []interface{}{name, userID, token}
+func (s *accessTokenStore) CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time, level access.AccessLevel, repoID int64) (models.AccessToken, error) {
+	query := `INSERT INTO access_tokens (name, user_id, token, expires_at, access_level, repo_id, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`
+
+	var expires sql.NullTime
 	if !expiresAt.IsZero() {
-		query = queryWithExpires
-		values = append(values, expiresAt.UTC())
+		expires = sql.NullTime{Time: expiresAt.UTC(), Valid: true}
+	}
+
+	var repo sql.NullInt64
+	if repoID != 0 {
+		repo = sql.NullInt64{Int64: repoID, Valid: true}
 	}
 
-	var id int64
-	if err := h.GetContext(ctx, &id, h.Rebind(query), values...); err != nil {
+	id, err := insertID(ctx, h, query, name, userID, token, expires, level, repo)
+	if err != nil {
 		return models.AccessToken{}, err
 	}
 
 	return s.GetAccessToken(ctx, h, id)
 }
 
+// RotateAccessTokenForUser implements store.AccessTokenStore.
+func (*accessTokenStore) RotateAccessTokenForUser(ctx context.Context, h db.Handler, userID int64, id int64, token string, expiresAt time.Time) error {
+	var expires sql.NullTime
+	if !expiresAt.IsZero() {
+		expires = sql.NullTime{Time: expiresAt.UTC(), Valid: true}
+	}
+
+	query := h.Rebind(`UPDATE access_tokens SET token = ?, expires_at = ?, created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE user_id = ? AND id = ?`)
+	_, err := h.ExecContext(ctx, query, token, expires, userID, id)
+	return err
+}
+
 // DeleteAccessToken implements store.AccessTokenStore.
 func (*accessTokenStore) DeleteAccessToken(ctx context.Context, h db.Handler, id int64) error {
 	query := h.Rebind(`DELETE FROM access_tokens WHERE id = ?`)
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+)
+
+type auditEventStore struct{}
+
+var _ store.AuditEventStore = (*auditEventStore)(nil)
+
+// CreateAuditEvent implements store.AuditEventStore.
+func (*auditEventStore) CreateAuditEvent(ctx context.Context, tx db.Handler, event models.AuditEvent) error {
+	query := tx.Rebind(`INSERT INTO audit_events (action, username, impersonator, repo, target, remote_addr, details, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?);`)
+	_, err := tx.ExecContext(ctx, query, event.Action, event.Username, event.Impersonator, event.Repo,
+		event.Target, event.RemoteAddr, event.Details, event.CreatedAt.UTC())
+	return err
+}
+
+// GetAuditEvents implements store.AuditEventStore.
+func (*auditEventStore) GetAuditEvents(ctx context.Context, tx db.Handler, filter store.AuditEventFilter) ([]models.AuditEvent, error) {
+	var m []models.AuditEvent
+	var where []string
+	var args []interface{}
+	if filter.Action != "" {
+		where = append(where, "(action = ? OR action LIKE ?)")
+		args = append(args, filter.Action, filter.Action+".%")
+	}
+	if filter.Username != "" {
+		where = append(where, "(username = ? OR impersonator = ?)")
+		args = append(args, filter.Username, filter.Username)
+	}
+	if filter.Repo != "" {
+		where = append(where, "repo = ?")
+		args = append(args, filter.Repo)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	query := "SELECT * FROM audit_events"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	if err := tx.SelectContext(ctx, &m, tx.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	// Return the most recent events in chronological order.
+	for i, j := 0, len(m)-1; i < j; i, j = i+1, j-1 {
+		m[i], m[j] = m[j], m[i]
+	}
+
+	return m, nil
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type branchProtectionStore struct{}
+
+var _ store.BranchProtectionStore = (*branchProtectionStore)(nil)
+
+// GetBranchProtectionsByRepo implements store.BranchProtectionStore.
+func (*branchProtectionStore) GetBranchProtectionsByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.BranchProtection, error) {
+	var m []models.BranchProtection
+
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			branch_protections.*
+		FROM
+			branch_protections
+		INNER JOIN repos ON repos.id = branch_protections.repo_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			branch_protections.pattern ASC
+	`)
+
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// GetBranchProtectionByRepoAndPattern implements store.BranchProtectionStore.
+func (*branchProtectionStore) GetBranchProtectionByRepoAndPattern(ctx context.Context, tx db.Handler, repo string, pattern string) (models.BranchProtection, error) {
+	var m models.BranchProtection
+
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			branch_protections.*
+		FROM
+			branch_protections
+		INNER JOIN repos ON repos.id = branch_protections.repo_id
+		WHERE
+			repos.name = ? AND branch_protections.pattern = ?
+	`)
+
+	err := tx.GetContext(ctx, &m, query, repo, pattern)
+	return m, err
+}
+
+// UpsertBranchProtection implements store.BranchProtectionStore.
+func (*branchProtectionStore) UpsertBranchProtection(ctx context.Context, tx db.Handler, repo string, pattern string, noForcePush bool, noDeletion bool, requireSigned bool, restrictPush bool) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO branch_protections (repo_id, pattern, no_force_push, no_deletion, require_signed, restrict_push, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, ?, ?, ?,
+				CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (repo_id, pattern) DO UPDATE SET
+				no_force_push = excluded.no_force_push,
+				no_deletion = excluded.no_deletion,
+				require_signed = excluded.require_signed,
+				restrict_push = excluded.restrict_push,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, repo, pattern, noForcePush, noDeletion, requireSigned, restrictPush)
+	return err
+}
+
+// DeleteBranchProtectionByRepoAndPattern implements store.BranchProtectionStore.
+func (*branchProtectionStore) DeleteBranchProtectionByRepoAndPattern(ctx context.Context, tx db.Handler, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM
+			branch_protections
+		WHERE
+			repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			) AND pattern = ?
+	`)
+	_, err := tx.ExecContext(ctx, query, repo, pattern)
+	return err
+}
+
+// AddBranchProtectionUser implements store.BranchProtectionStore.
+func (*branchProtectionStore) AddBranchProtectionUser(ctx context.Context, tx db.Handler, protectionID int64, username string) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`INSERT INTO branch_protection_users (branch_protection_id, user_id, updated_at)
+			VALUES (
+				?,
+				(
+					SELECT id FROM users WHERE username = ?
+				),
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, protectionID, username)
+	return err
+}
+
+// RemoveBranchProtectionUsers implements store.BranchProtectionStore.
+func (*branchProtectionStore) RemoveBranchProtectionUsers(ctx context.Context, tx db.Handler, protectionID int64) error {
+	query := tx.Rebind(`DELETE FROM branch_protection_users WHERE branch_protection_id = ?`)
+	_, err := tx.ExecContext(ctx, query, protectionID)
+	return err
+}
+
+// ListBranchProtectionUsers implements store.BranchProtectionStore.
+func (*branchProtectionStore) ListBranchProtectionUsers(ctx context.Context, tx db.Handler, protectionID int64) ([]models.User, error) {
+	var m []models.User
+
+	query := tx.Rebind(`
+		SELECT
+			users.*
+		FROM
+			users
+		INNER JOIN branch_protection_users ON branch_protection_users.user_id = users.id
+		WHERE
+			branch_protection_users.branch_protection_id = ?
+		ORDER BY
+			users.username ASC
+	`)
+
+	err := tx.SelectContext(ctx, &m, query, protectionID)
+	return m, err
+}
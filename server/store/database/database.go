@@ -21,6 +21,23 @@ type datastore struct {
 	*collabStore
 	*lfsStore
 	*accessTokenStore
+	*branchProtectionStore
+	*userSettingStore
+	*pushPolicyStore
+	*readMarkerStore
+	*idempotencyKeyStore
+	*deployKeyStore
+	*tagTimestampStore
+	*repoSettingStore
+	*refPermissionStore
+	*auditEventStore
+	*repoTrafficStore
+	*releaseStore
+	*jobStore
+	*lockStore
+	*diskQuotaStore
+	*repoTransferStore
+	*repoHookRunStore
 }
 
 // New returns a new store.Store database.
@@ -34,12 +51,29 @@ func New(ctx context.Context, db *db.DB) store.Store {
 		db:     db,
 		logger: logger,
 
-		settingsStore:    &settingsStore{},
-		repoStore:        &repoStore{},
-		userStore:        &userStore{},
-		collabStore:      &collabStore{},
-		lfsStore:         &lfsStore{},
-		accessTokenStore: &accessTokenStore{},
+		settingsStore:         &settingsStore{},
+		repoStore:             &repoStore{},
+		userStore:             &userStore{},
+		collabStore:           &collabStore{},
+		lfsStore:              &lfsStore{},
+		accessTokenStore:      &accessTokenStore{},
+		branchProtectionStore: &branchProtectionStore{},
+		userSettingStore:      &userSettingStore{},
+		pushPolicyStore:       &pushPolicyStore{},
+		readMarkerStore:       &readMarkerStore{},
+		idempotencyKeyStore:   &idempotencyKeyStore{},
+		deployKeyStore:        &deployKeyStore{},
+		tagTimestampStore:     &tagTimestampStore{},
+		repoSettingStore:      &repoSettingStore{},
+		refPermissionStore:    &refPermissionStore{},
+		auditEventStore:       &auditEventStore{},
+		repoTrafficStore:      &repoTrafficStore{},
+		releaseStore:          &releaseStore{},
+		jobStore:              &jobStore{},
+		lockStore:             &lockStore{},
+		diskQuotaStore:        &diskQuotaStore{},
+		repoTransferStore:     &repoTransferStore{},
+		repoHookRunStore:      &repoHookRunStore{},
 	}
 
 	return s
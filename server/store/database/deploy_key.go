@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"golang.org/x/crypto/ssh"
+)
+
+type deployKeyStore struct{}
+
+var _ store.DeployKeyStore = (*deployKeyStore)(nil)
+
+// GetDeployKeyByRepoAndPublicKey implements store.DeployKeyStore.
+func (*deployKeyStore) GetDeployKeyByRepoAndPublicKey(ctx context.Context, tx db.Handler, repo string, pk ssh.PublicKey) (models.DeployKey, error) {
+	var m models.DeployKey
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT deploy_keys.*
+			FROM deploy_keys
+			INNER JOIN repos ON repos.id = deploy_keys.repo_id
+			WHERE repos.name = ? AND deploy_keys.public_key = ?;`)
+	err := tx.GetContext(ctx, &m, query, repo, sshutils.MarshalAuthorizedKey(pk))
+	return m, err
+}
+
+// ListDeployKeysByRepo implements store.DeployKeyStore.
+func (*deployKeyStore) ListDeployKeysByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.DeployKey, error) {
+	var m []models.DeployKey
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT deploy_keys.*
+			FROM deploy_keys
+			INNER JOIN repos ON repos.id = deploy_keys.repo_id
+			WHERE repos.name = ?
+			ORDER BY deploy_keys.id ASC;`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// ListDeployKeysByPublicKey implements store.DeployKeyStore.
+func (*deployKeyStore) ListDeployKeysByPublicKey(ctx context.Context, tx db.Handler, pk ssh.PublicKey) ([]models.DeployKey, error) {
+	var m []models.DeployKey
+	query := tx.Rebind(`SELECT * FROM deploy_keys WHERE public_key = ?;`)
+	err := tx.SelectContext(ctx, &m, query, sshutils.MarshalAuthorizedKey(pk))
+	return m, err
+}
+
+// CreateDeployKey implements store.DeployKeyStore.
+func (*deployKeyStore) CreateDeployKey(ctx context.Context, tx db.Handler, repo string, title string, pk ssh.PublicKey, level access.AccessLevel) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`INSERT INTO deploy_keys (repo_id, title, public_key, access_level, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, ?, CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, title, sshutils.MarshalAuthorizedKey(pk), level)
+	return err
+}
+
+// DeleteDeployKeyForRepo implements store.DeployKeyStore.
+func (*deployKeyStore) DeleteDeployKeyForRepo(ctx context.Context, tx db.Handler, repo string, id int64) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`DELETE FROM deploy_keys
+			WHERE id = ? AND repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			);`)
+	_, err := tx.ExecContext(ctx, query, id, repo)
+	return err
+}
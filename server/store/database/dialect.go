@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+var (
+	onConflictUpdateRe  = regexp.MustCompile(`ON CONFLICT \([^)]*\) DO UPDATE SET`)
+	onConflictNothingRe = regexp.MustCompile(`ON CONFLICT \([^)]*\) DO NOTHING`)
+	excludedRe          = regexp.MustCompile(`\bexcluded\.(\w+)`)
+)
+
+// rebind is like Rebind for inserts with an ON CONFLICT clause. MySQL doesn't
+// support the clause, it's rewritten to ON DUPLICATE KEY UPDATE there, which
+// handles conflicts on any unique key of the table.
+func rebind(h db.Handler, query string) string {
+	if h.DriverName() == "mysql" {
+		query = onConflictUpdateRe.ReplaceAllString(query, "ON DUPLICATE KEY UPDATE")
+		query = onConflictNothingRe.ReplaceAllString(query, "ON DUPLICATE KEY UPDATE id = id")
+		query = excludedRe.ReplaceAllString(query, "VALUES($1)")
+	}
+
+	return h.Rebind(query)
+}
+
+// insertID runs an insert and returns the ID of the inserted row.
+func insertID(ctx context.Context, h db.Handler, query string, args ...interface{}) (int64, error) {
+	if h.DriverName() == "mysql" {
+		res, err := h.ExecContext(ctx, h.Rebind(query), args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+
+	var id int64
+	err := h.GetContext(ctx, &id, h.Rebind(query+" RETURNING id"), args...)
+	return id, err
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type driverHandler struct {
+	*sqlx.DB
+	driver string
+}
+
+func (h driverHandler) DriverName() string { return h.driver }
+
+func (h driverHandler) Rebind(query string) string {
+	return sqlx.Rebind(sqlx.BindType(h.driver), query)
+}
+
+func TestRebind(t *testing.T) {
+	query := `INSERT INTO t (a, b) VALUES (?, ?) ON CONFLICT (a) DO UPDATE SET b = t.b + excluded.b;`
+	cases := map[string]string{
+		"sqlite":   `INSERT INTO t (a, b) VALUES (?, ?) ON CONFLICT (a) DO UPDATE SET b = t.b + excluded.b;`,
+		"postgres": `INSERT INTO t (a, b) VALUES ($1, $2) ON CONFLICT (a) DO UPDATE SET b = t.b + excluded.b;`,
+		"mysql":    `INSERT INTO t (a, b) VALUES (?, ?) ON DUPLICATE KEY UPDATE b = t.b + VALUES(b);`,
+	}
+	for driver, want := range cases {
+		if got := rebind(driverHandler{driver: driver}, query); got != want {
+			t.Errorf("%s: expected %q, got %q", driver, want, got)
+		}
+	}
+
+	query = `INSERT INTO t (a, b) VALUES (?, ?) ON CONFLICT (a, b) DO NOTHING;`
+	want := `INSERT INTO t (a, b) VALUES (?, ?) ON DUPLICATE KEY UPDATE id = id;`
+	if got := rebind(driverHandler{driver: "mysql"}, query); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+)
+
+type diskQuotaStore struct{}
+
+var _ store.DiskQuotaStore = (*diskQuotaStore)(nil)
+
+// GetRepoDiskUsage implements store.DiskQuotaStore.
+func (*diskQuotaStore) GetRepoDiskUsage(ctx context.Context, tx db.Handler, repoID int64) (models.RepoDiskUsage, error) {
+	var m models.RepoDiskUsage
+	query := tx.Rebind(`SELECT * FROM repo_disk_usages WHERE repo_id = ?;`)
+	err := tx.GetContext(ctx, &m, query, repoID)
+	return m, err
+}
+
+// GetRepoDiskUsages implements store.DiskQuotaStore.
+func (*diskQuotaStore) GetRepoDiskUsages(ctx context.Context, tx db.Handler) ([]models.RepoDiskUsage, error) {
+	var m []models.RepoDiskUsage
+	query := tx.Rebind(`SELECT * FROM repo_disk_usages;`)
+	err := tx.SelectContext(ctx, &m, query)
+	return m, err
+}
+
+// SetRepoDiskUsage implements store.DiskQuotaStore.
+func (*diskQuotaStore) SetRepoDiskUsage(ctx context.Context, tx db.Handler, usage models.RepoDiskUsage) error {
+	query := rebind(tx, `INSERT INTO repo_disk_usages (repo_id, objects_size, lfs_size, object_count, pack_size, updated_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (repo_id) DO UPDATE SET
+				objects_size = excluded.objects_size,
+				lfs_size = excluded.lfs_size,
+				object_count = excluded.object_count,
+				pack_size = excluded.pack_size,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, usage.RepoID, usage.ObjectsSize, usage.LFSSize, usage.ObjectCount, usage.PackSize)
+	return err
+}
+
+// GetRepoLargeBlobs implements store.DiskQuotaStore.
+func (*diskQuotaStore) GetRepoLargeBlobs(ctx context.Context, tx db.Handler, repoID int64) ([]models.RepoLargeBlob, error) {
+	var m []models.RepoLargeBlob
+	query := tx.Rebind(`SELECT * FROM repo_large_blobs WHERE repo_id = ? ORDER BY size DESC, id ASC;`)
+	err := tx.SelectContext(ctx, &m, query, repoID)
+	return m, err
+}
+
+// SetRepoLargeBlobs implements store.DiskQuotaStore.
+func (*diskQuotaStore) SetRepoLargeBlobs(ctx context.Context, tx db.Handler, repoID int64, blobs []models.RepoLargeBlob) error {
+	query := tx.Rebind(`DELETE FROM repo_large_blobs WHERE repo_id = ?;`)
+	if _, err := tx.ExecContext(ctx, query, repoID); err != nil {
+		return err
+	}
+
+	query = tx.Rebind(`INSERT INTO repo_large_blobs (repo_id, oid, path, size) VALUES (?, ?, ?, ?);`)
+	for _, b := range blobs {
+		if _, err := tx.ExecContext(ctx, query, repoID, b.Oid, b.Path, b.Size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetUserDiskUsage implements store.DiskQuotaStore.
+func (*diskQuotaStore) GetUserDiskUsage(ctx context.Context, tx db.Handler, userID int64) (int64, error) {
+	var size int64
+	query := tx.Rebind(`SELECT COALESCE(SUM(repo_disk_usages.objects_size + repo_disk_usages.lfs_size), 0)
+			FROM repo_disk_usages
+			INNER JOIN repos ON repos.id = repo_disk_usages.repo_id
+			WHERE repos.user_id = ?;`)
+	err := tx.GetContext(ctx, &size, query, userID)
+	return size, err
+}
+
+// GetUserDiskQuota implements store.DiskQuotaStore.
+func (*diskQuotaStore) GetUserDiskQuota(ctx context.Context, tx db.Handler, userID int64) (models.DiskQuota, error) {
+	var m models.DiskQuota
+	query := tx.Rebind(`SELECT * FROM disk_quotas WHERE user_id = ?;`)
+	err := tx.GetContext(ctx, &m, query, userID)
+	return m, err
+}
+
+// SetUserDiskQuota implements store.DiskQuotaStore.
+func (*diskQuotaStore) SetUserDiskQuota(ctx context.Context, tx db.Handler, userID int64, size int64) error {
+	query := rebind(tx, `INSERT INTO disk_quotas (user_id, size, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (user_id) DO UPDATE SET
+				size = excluded.size,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, userID, size)
+	return err
+}
+
+// DeleteUserDiskQuota implements store.DiskQuotaStore.
+func (*diskQuotaStore) DeleteUserDiskQuota(ctx context.Context, tx db.Handler, userID int64) error {
+	query := tx.Rebind(`DELETE FROM disk_quotas WHERE user_id = ?;`)
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
+
+// GetRepoDiskQuota implements store.DiskQuotaStore.
+func (*diskQuotaStore) GetRepoDiskQuota(ctx context.Context, tx db.Handler, repoID int64) (models.DiskQuota, error) {
+	var m models.DiskQuota
+	query := tx.Rebind(`SELECT * FROM disk_quotas WHERE repo_id = ?;`)
+	err := tx.GetContext(ctx, &m, query, repoID)
+	return m, err
+}
+
+// SetRepoDiskQuota implements store.DiskQuotaStore.
+func (*diskQuotaStore) SetRepoDiskQuota(ctx context.Context, tx db.Handler, repoID int64, size int64) error {
+	query := rebind(tx, `INSERT INTO disk_quotas (repo_id, size, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (repo_id) DO UPDATE SET
+				size = excluded.size,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, repoID, size)
+	return err
+}
+
+// DeleteRepoDiskQuota implements store.DiskQuotaStore.
+func (*diskQuotaStore) DeleteRepoDiskQuota(ctx context.Context, tx db.Handler, repoID int64) error {
+	query := tx.Rebind(`DELETE FROM disk_quotas WHERE repo_id = ?;`)
+	_, err := tx.ExecContext(ctx, query, repoID)
+	return err
+}
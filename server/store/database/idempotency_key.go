@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+)
+
+type idempotencyKeyStore struct{}
+
+var _ store.IdempotencyKeyStore = (*idempotencyKeyStore)(nil)
+
+// GetIdempotencyKey implements store.IdempotencyKeyStore.
+func (*idempotencyKeyStore) GetIdempotencyKey(ctx context.Context, tx db.Handler, userID int64, key string) (models.IdempotencyKey, error) {
+	var m models.IdempotencyKey
+	query := tx.Rebind(`SELECT * FROM idempotency_keys WHERE user_id = ? AND "key" = ?`)
+	err := tx.GetContext(ctx, &m, query, userID, key)
+	return m, err
+}
+
+// CreateIdempotencyKey implements store.IdempotencyKeyStore.
+func (*idempotencyKeyStore) CreateIdempotencyKey(ctx context.Context, tx db.Handler, userID int64, key string, request string, response string) error {
+	query := tx.Rebind(`INSERT INTO idempotency_keys (user_id, "key", request, response, updated_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP);`)
+	_, err := tx.ExecContext(ctx, query, userID, key, request, response)
+	return err
+}
+
+// DeleteIdempotencyKey implements store.IdempotencyKeyStore.
+func (*idempotencyKeyStore) DeleteIdempotencyKey(ctx context.Context, tx db.Handler, userID int64, key string) error {
+	query := tx.Rebind(`DELETE FROM idempotency_keys WHERE user_id = ? AND "key" = ?`)
+	_, err := tx.ExecContext(ctx, query, userID, key)
+	return err
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+)
+
+type jobStore struct{}
+
+var _ store.JobStore = (*jobStore)(nil)
+
+// CreateJob implements store.JobStore.
+func (*jobStore) CreateJob(ctx context.Context, tx db.Handler, kind string, payload string, maxAttempts int, runAt time.Time) error {
+	var count int
+	query := tx.Rebind(`SELECT COUNT(*) FROM jobs
+			WHERE kind = ? AND payload = ? AND status IN ('pending', 'running');`)
+	if err := tx.GetContext(ctx, &count, query, kind, payload); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	query = tx.Rebind(`INSERT INTO jobs (kind, payload, status, max_attempts, last_error, run_at, updated_at)
+			VALUES (?, ?, 'pending', ?, '', ?, CURRENT_TIMESTAMP);`)
+	_, err := tx.ExecContext(ctx, query, kind, payload, maxAttempts, runAt.UTC())
+	return err
+}
+
+// ClaimJob implements store.JobStore.
+func (*jobStore) ClaimJob(ctx context.Context, tx db.Handler, now time.Time) (models.Job, error) {
+	var m models.Job
+	query := tx.Rebind(`SELECT * FROM jobs
+			WHERE status = 'pending' AND run_at <= ?
+			ORDER BY run_at ASC, id ASC
+			LIMIT 1;`)
+	if err := tx.GetContext(ctx, &m, query, now.UTC()); err != nil {
+		return m, err
+	}
+
+	query = tx.Rebind(`UPDATE jobs SET status = 'running', attempts = attempts + 1, updated_at = ?
+			WHERE id = ? AND status = 'pending';`)
+	res, err := tx.ExecContext(ctx, query, now.UTC(), m.ID)
+	if err != nil {
+		return m, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return m, err
+	} else if n == 0 {
+		// Another server claimed it first.
+		return m, sql.ErrNoRows
+	}
+
+	m.Status = "running"
+	m.Attempts++
+	return m, nil
+}
+
+// CompleteJob implements store.JobStore.
+func (*jobStore) CompleteJob(ctx context.Context, tx db.Handler, id int64) error {
+	query := tx.Rebind(`DELETE FROM jobs WHERE id = ?;`)
+	_, err := tx.ExecContext(ctx, query, id)
+	return err
+}
+
+// FailJob implements store.JobStore.
+func (*jobStore) FailJob(ctx context.Context, tx db.Handler, id int64, lastError string, retryAt time.Time) error {
+	if retryAt.IsZero() {
+		query := tx.Rebind(`UPDATE jobs SET status = 'dead', last_error = ?, updated_at = CURRENT_TIMESTAMP
+				WHERE id = ?;`)
+		_, err := tx.ExecContext(ctx, query, lastError, id)
+		return err
+	}
+
+	query := tx.Rebind(`UPDATE jobs SET status = 'pending', last_error = ?, run_at = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?;`)
+	_, err := tx.ExecContext(ctx, query, lastError, retryAt.UTC(), id)
+	return err
+}
+
+// RetryJob implements store.JobStore.
+func (*jobStore) RetryJob(ctx context.Context, tx db.Handler, id int64, runAt time.Time) error {
+	query := tx.Rebind(`UPDATE jobs SET status = 'pending', attempts = 0, run_at = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND status = 'dead';`)
+	res, err := tx.ExecContext(ctx, query, runAt.UTC(), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RequeueRunningJobs implements store.JobStore.
+func (*jobStore) RequeueRunningJobs(ctx context.Context, tx db.Handler, claimedBefore time.Time, runAt time.Time) error {
+	query := tx.Rebind(`UPDATE jobs SET status = 'pending', run_at = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE status = 'running' AND updated_at <= ?;`)
+	_, err := tx.ExecContext(ctx, query, runAt.UTC(), claimedBefore.UTC())
+	return err
+}
+
+// GetJobByID implements store.JobStore.
+func (*jobStore) GetJobByID(ctx context.Context, tx db.Handler, id int64) (models.Job, error) {
+	var m models.Job
+	query := tx.Rebind(`SELECT * FROM jobs WHERE id = ?;`)
+	err := tx.GetContext(ctx, &m, query, id)
+	return m, err
+}
+
+// GetJobs implements store.JobStore.
+func (*jobStore) GetJobs(ctx context.Context, tx db.Handler, status string) ([]models.Job, error) {
+	var m []models.Job
+	query := "SELECT * FROM jobs"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id ASC"
+	err := tx.SelectContext(ctx, &m, tx.Rebind(query), args...)
+	return m, err
+}
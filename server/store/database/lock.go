@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/store"
+)
+
+type lockStore struct{}
+
+var _ store.LockStore = (*lockStore)(nil)
+
+// AcquireLock implements store.LockStore.
+func (*lockStore) AcquireLock(ctx context.Context, tx db.Handler, name string, owner string, now time.Time, expiresAt time.Time) error {
+	query := tx.Rebind(`DELETE FROM locks WHERE name = ? AND expires_at <= ?;`)
+	if _, err := tx.ExecContext(ctx, query, name, now.UTC()); err != nil {
+		return err
+	}
+
+	query = tx.Rebind(`INSERT INTO locks (name, owner, expires_at) VALUES (?, ?, ?);`)
+	_, err := tx.ExecContext(ctx, query, name, owner, expiresAt.UTC())
+	return err
+}
+
+// RefreshLock implements store.LockStore.
+func (*lockStore) RefreshLock(ctx context.Context, tx db.Handler, name string, owner string, expiresAt time.Time) error {
+	query := tx.Rebind(`UPDATE locks SET expires_at = ? WHERE name = ? AND owner = ?;`)
+	res, err := tx.ExecContext(ctx, query, expiresAt.UTC(), name, owner)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReleaseLock implements store.LockStore.
+func (*lockStore) ReleaseLock(ctx context.Context, tx db.Handler, name string, owner string) error {
+	query := tx.Rebind(`DELETE FROM locks WHERE name = ? AND owner = ?;`)
+	_, err := tx.ExecContext(ctx, query, name, owner)
+	return err
+}
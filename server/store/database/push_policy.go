@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type pushPolicyStore struct{}
+
+var _ store.PushPolicyStore = (*pushPolicyStore)(nil)
+
+// GetPushPolicyByRepo implements store.PushPolicyStore.
+func (*pushPolicyStore) GetPushPolicyByRepo(ctx context.Context, tx db.Handler, repo string) (models.PushPolicy, error) {
+	var m models.PushPolicy
+
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			push_policies.*
+		FROM
+			push_policies
+		INNER JOIN repos ON repos.id = push_policies.repo_id
+		WHERE
+			repos.name = ?
+	`)
+
+	err := tx.GetContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// UpsertPushPolicy implements store.PushPolicyStore.
+func (*pushPolicyStore) UpsertPushPolicy(ctx context.Context, tx db.Handler, repo string, maxBlobSize sql.NullInt64, maxPushSize sql.NullInt64, bannedExtensions sql.NullString) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO push_policies (repo_id, max_blob_size, max_push_size, banned_extensions, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, ?,
+				CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (repo_id) DO UPDATE SET
+				max_blob_size = excluded.max_blob_size,
+				max_push_size = excluded.max_push_size,
+				banned_extensions = excluded.banned_extensions,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, repo, maxBlobSize, maxPushSize, bannedExtensions)
+	return err
+}
+
+// DeletePushPolicyByRepo implements store.PushPolicyStore.
+func (*pushPolicyStore) DeletePushPolicyByRepo(ctx context.Context, tx db.Handler, repo string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM
+			push_policies
+		WHERE
+			repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			)
+	`)
+	_, err := tx.ExecContext(ctx, query, repo)
+	return err
+}
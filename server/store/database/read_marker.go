@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type readMarkerStore struct{}
+
+var _ store.ReadMarkerStore = (*readMarkerStore)(nil)
+
+// GetRepoReadAt implements store.ReadMarkerStore.
+func (*readMarkerStore) GetRepoReadAt(ctx context.Context, tx db.Handler, userID int64, repo string) (time.Time, error) {
+	var readAt time.Time
+
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			repo_reads.read_at
+		FROM
+			repo_reads
+		INNER JOIN repos ON repos.id = repo_reads.repo_id
+		WHERE
+			repo_reads.user_id = ? AND repos.name = ?
+	`)
+
+	err := tx.GetContext(ctx, &readAt, query, userID, repo)
+	return readAt, err
+}
+
+// MarkRepoRead implements store.ReadMarkerStore.
+func (*readMarkerStore) MarkRepoRead(ctx context.Context, tx db.Handler, userID int64, repo string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO repo_reads (user_id, repo_id, read_at, updated_at)
+			VALUES (
+				?,
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				CURRENT_TIMESTAMP,
+				CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (user_id, repo_id) DO UPDATE SET
+				read_at = CURRENT_TIMESTAMP,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, userID, repo)
+	return err
+}
+
+// GetCommitReads implements store.ReadMarkerStore.
+func (*readMarkerStore) GetCommitReads(ctx context.Context, tx db.Handler, userID int64, repo string) ([]string, error) {
+	var hashes []string
+
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			commit_reads.commit_hash
+		FROM
+			commit_reads
+		INNER JOIN repos ON repos.id = commit_reads.repo_id
+		WHERE
+			commit_reads.user_id = ? AND repos.name = ?
+	`)
+
+	err := tx.SelectContext(ctx, &hashes, query, userID, repo)
+	return hashes, err
+}
+
+// AddCommitRead implements store.ReadMarkerStore.
+func (*readMarkerStore) AddCommitRead(ctx context.Context, tx db.Handler, userID int64, repo string, hash string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO commit_reads (user_id, repo_id, commit_hash, updated_at)
+			VALUES (
+				?,
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (user_id, repo_id, commit_hash) DO NOTHING;`)
+	_, err := tx.ExecContext(ctx, query, userID, repo, hash)
+	return err
+}
+
+// DeleteCommitReads implements store.ReadMarkerStore.
+func (*readMarkerStore) DeleteCommitReads(ctx context.Context, tx db.Handler, userID int64, repo string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM
+			commit_reads
+		WHERE
+			user_id = ? AND repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			)
+	`)
+	_, err := tx.ExecContext(ctx, query, userID, repo)
+	return err
+}
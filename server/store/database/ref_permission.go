@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type refPermissionStore struct{}
+
+var _ store.RefPermissionStore = (*refPermissionStore)(nil)
+
+// GetRefPermissionsByRepo implements store.RefPermissionStore.
+func (*refPermissionStore) GetRefPermissionsByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.RefPermission, error) {
+	var m []models.RefPermission
+
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			ref_permissions.*
+		FROM
+			ref_permissions
+		INNER JOIN repos ON repos.id = ref_permissions.repo_id
+		INNER JOIN users ON users.id = ref_permissions.user_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			ref_permissions.pattern ASC, users.username ASC
+	`)
+
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// AddRefPermission implements store.RefPermissionStore.
+func (*refPermissionStore) AddRefPermission(ctx context.Context, tx db.Handler, repo string, pattern string, userID int64) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO ref_permissions (repo_id, pattern, user_id, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (repo_id, pattern, user_id) DO NOTHING;`)
+	_, err := tx.ExecContext(ctx, query, repo, pattern, userID)
+	return err
+}
+
+// RemoveRefPermission implements store.RefPermissionStore.
+func (*refPermissionStore) RemoveRefPermission(ctx context.Context, tx db.Handler, repo string, pattern string, userID int64) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM
+			ref_permissions
+		WHERE
+			repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			) AND pattern = ? AND user_id = ?
+	`)
+	_, err := tx.ExecContext(ctx, query, repo, pattern, userID)
+	return err
+}
+
+// RemoveRefPermissionsByPattern implements store.RefPermissionStore.
+func (*refPermissionStore) RemoveRefPermissionsByPattern(ctx context.Context, tx db.Handler, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM
+			ref_permissions
+		WHERE
+			repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			) AND pattern = ?
+	`)
+	_, err := tx.ExecContext(ctx, query, repo, pattern)
+	return err
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type releaseStore struct{}
+
+var _ store.ReleaseStore = (*releaseStore)(nil)
+
+// GetReleasesByRepo implements store.ReleaseStore.
+func (*releaseStore) GetReleasesByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.Release, error) {
+	var m []models.Release
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT releases.*
+			FROM releases
+			INNER JOIN repos ON repos.id = releases.repo_id
+			WHERE repos.name = ?
+			ORDER BY releases.created_at DESC, releases.id DESC;`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// GetReleaseByRepoAndTag implements store.ReleaseStore.
+func (*releaseStore) GetReleaseByRepoAndTag(ctx context.Context, tx db.Handler, repo string, tag string) (models.Release, error) {
+	var m models.Release
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT releases.*
+			FROM releases
+			INNER JOIN repos ON repos.id = releases.repo_id
+			WHERE repos.name = ? AND releases.tag = ?;`)
+	err := tx.GetContext(ctx, &m, query, repo, tag)
+	return m, err
+}
+
+// CreateRelease implements store.ReleaseStore.
+func (*releaseStore) CreateRelease(ctx context.Context, tx db.Handler, repo string, tag string, name string, notes string, userID int64) error {
+	repo = utils.SanitizeRepo(repo)
+	var uid *int64
+	if userID > 0 {
+		uid = &userID
+	}
+	query := tx.Rebind(`INSERT INTO releases (repo_id, tag, name, notes, user_id, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, ?, ?, CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, tag, name, notes, uid)
+	return err
+}
+
+// UpdateRelease implements store.ReleaseStore.
+func (*releaseStore) UpdateRelease(ctx context.Context, tx db.Handler, id int64, name string, notes string) error {
+	query := tx.Rebind(`UPDATE releases SET name = ?, notes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;`)
+	_, err := tx.ExecContext(ctx, query, name, notes, id)
+	return err
+}
+
+// DeleteReleaseByID implements store.ReleaseStore.
+func (*releaseStore) DeleteReleaseByID(ctx context.Context, tx db.Handler, id int64) error {
+	query := tx.Rebind(`DELETE FROM releases WHERE id = ?;`)
+	_, err := tx.ExecContext(ctx, query, id)
+	return err
+}
+
+// GetReleaseAssets implements store.ReleaseStore.
+func (*releaseStore) GetReleaseAssets(ctx context.Context, tx db.Handler, releaseID int64) ([]models.ReleaseAsset, error) {
+	var m []models.ReleaseAsset
+	query := tx.Rebind(`SELECT * FROM release_assets WHERE release_id = ? ORDER BY name ASC;`)
+	err := tx.SelectContext(ctx, &m, query, releaseID)
+	return m, err
+}
+
+// GetReleaseAssetByName implements store.ReleaseStore.
+func (*releaseStore) GetReleaseAssetByName(ctx context.Context, tx db.Handler, releaseID int64, name string) (models.ReleaseAsset, error) {
+	var m models.ReleaseAsset
+	query := tx.Rebind(`SELECT * FROM release_assets WHERE release_id = ? AND name = ?;`)
+	err := tx.GetContext(ctx, &m, query, releaseID, name)
+	return m, err
+}
+
+// SetReleaseAsset implements store.ReleaseStore.
+func (*releaseStore) SetReleaseAsset(ctx context.Context, tx db.Handler, releaseID int64, name string, size int64, sha256 string) error {
+	query := rebind(tx, `INSERT INTO release_assets (release_id, name, size, sha256, updated_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (release_id, name) DO UPDATE SET
+				size = excluded.size,
+				sha256 = excluded.sha256,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, releaseID, name, size, sha256)
+	return err
+}
+
+// DeleteReleaseAsset implements store.ReleaseStore.
+func (*releaseStore) DeleteReleaseAsset(ctx context.Context, tx db.Handler, releaseID int64, name string) error {
+	query := tx.Rebind(`DELETE FROM release_assets WHERE release_id = ? AND name = ?;`)
+	_, err := tx.ExecContext(ctx, query, releaseID, name)
+	return err
+}
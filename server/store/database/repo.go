@@ -43,7 +43,7 @@ func (*repoStore) DeleteRepoByName(ctx context.Context, tx db.Handler, name stri
 // GetAllRepos implements store.RepositoryStore.
 func (*repoStore) GetAllRepos(ctx context.Context, tx db.Handler) ([]models.Repo, error) {
 	var repos []models.Repo
-	query := tx.Rebind("SELECT * FROM repos;")
+	query := tx.Rebind("SELECT * FROM repos ORDER BY id;")
 	err := tx.SelectContext(ctx, &repos, query)
 	return repos, db.WrapError(err)
 }
@@ -101,6 +101,33 @@ func (*repoStore) GetRepoIsPrivateByName(ctx context.Context, tx db.Handler, nam
 	return isPrivate, db.WrapError(err)
 }
 
+// GetRepoIsInternalByName implements store.RepositoryStore.
+func (*repoStore) GetRepoIsInternalByName(ctx context.Context, tx db.Handler, name string) (bool, error) {
+	var isInternal bool
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("SELECT internal FROM repos WHERE name = ?;")
+	err := tx.GetContext(ctx, &isInternal, query, name)
+	return isInternal, db.WrapError(err)
+}
+
+// GetRepoIsTemplateByName implements store.RepositoryStore.
+func (*repoStore) GetRepoIsTemplateByName(ctx context.Context, tx db.Handler, name string) (bool, error) {
+	var isTemplate bool
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("SELECT template FROM repos WHERE name = ?;")
+	err := tx.GetContext(ctx, &isTemplate, query, name)
+	return isTemplate, db.WrapError(err)
+}
+
+// GetRepoIsArchivedByName implements store.RepositoryStore.
+func (*repoStore) GetRepoIsArchivedByName(ctx context.Context, tx db.Handler, name string) (bool, error) {
+	var isArchived bool
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("SELECT archived FROM repos WHERE name = ?;")
+	err := tx.GetContext(ctx, &isArchived, query, name)
+	return isArchived, db.WrapError(err)
+}
+
 // GetRepoProjectNameByName implements store.RepositoryStore.
 func (*repoStore) GetRepoProjectNameByName(ctx context.Context, tx db.Handler, name string) (string, error) {
 	var pname string
@@ -134,6 +161,30 @@ func (*repoStore) SetRepoIsPrivateByName(ctx context.Context, tx db.Handler, nam
 	return db.WrapError(err)
 }
 
+// SetRepoIsInternalByName implements store.RepositoryStore.
+func (*repoStore) SetRepoIsInternalByName(ctx context.Context, tx db.Handler, name string, isInternal bool) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("UPDATE repos SET internal = ? WHERE name = ?;")
+	_, err := tx.ExecContext(ctx, query, isInternal, name)
+	return db.WrapError(err)
+}
+
+// SetRepoIsTemplateByName implements store.RepositoryStore.
+func (*repoStore) SetRepoIsTemplateByName(ctx context.Context, tx db.Handler, name string, isTemplate bool) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("UPDATE repos SET template = ? WHERE name = ?;")
+	_, err := tx.ExecContext(ctx, query, isTemplate, name)
+	return db.WrapError(err)
+}
+
+// SetRepoIsArchivedByName implements store.RepositoryStore.
+func (*repoStore) SetRepoIsArchivedByName(ctx context.Context, tx db.Handler, name string, isArchived bool) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("UPDATE repos SET archived = ? WHERE name = ?;")
+	_, err := tx.ExecContext(ctx, query, isArchived, name)
+	return db.WrapError(err)
+}
+
 // SetRepoNameByName implements store.RepositoryStore.
 func (*repoStore) SetRepoNameByName(ctx context.Context, tx db.Handler, name string, newName string) error {
 	name = utils.SanitizeRepo(name)
@@ -143,6 +194,14 @@ func (*repoStore) SetRepoNameByName(ctx context.Context, tx db.Handler, name str
 	return db.WrapError(err)
 }
 
+// SetRepoUserIDByName implements store.RepositoryStore.
+func (*repoStore) SetRepoUserIDByName(ctx context.Context, tx db.Handler, name string, userID int64) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("UPDATE repos SET user_id = ? WHERE name = ?;")
+	_, err := tx.ExecContext(ctx, query, userID, name)
+	return db.WrapError(err)
+}
+
 // SetRepoProjectNameByName implements store.RepositoryStore.
 func (*repoStore) SetRepoProjectNameByName(ctx context.Context, tx db.Handler, name string, projectName string) error {
 	name = utils.SanitizeRepo(name)
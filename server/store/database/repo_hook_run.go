@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type repoHookRunStore struct{}
+
+var _ store.RepoHookRunStore = (*repoHookRunStore)(nil)
+
+// CreateRepoHookRun implements store.RepoHookRunStore.
+func (*repoHookRunStore) CreateRepoHookRun(ctx context.Context, tx db.Handler, repo string, run models.RepoHookRun, keep int) error {
+	repo = utils.SanitizeRepo(repo)
+	var repoID int64
+	if err := tx.GetContext(ctx, &repoID, tx.Rebind("SELECT id FROM repos WHERE name = ?;"), repo); err != nil {
+		return db.WrapError(err)
+	}
+
+	query := tx.Rebind(`INSERT INTO repo_hook_runs (repo_id, hook, username, exit_code, duration, output, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?);`)
+	if _, err := tx.ExecContext(ctx, query, repoID, run.Hook, run.Username, run.ExitCode,
+		run.Duration, run.Output, run.CreatedAt.UTC()); err != nil {
+		return db.WrapError(err)
+	}
+
+	if keep <= 0 {
+		return nil
+	}
+
+	// MySQL can't select from the table it deletes from, find the oldest
+	// run to keep first.
+	var oldest int64
+	query = tx.Rebind(`SELECT id FROM repo_hook_runs WHERE repo_id = ?
+			ORDER BY id DESC LIMIT 1 OFFSET ?;`)
+	err := db.WrapError(tx.GetContext(ctx, &oldest, query, repoID, keep-1))
+	if errors.Is(err, db.ErrRecordNotFound) {
+		// There are fewer runs than the ones to keep.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	query = tx.Rebind("DELETE FROM repo_hook_runs WHERE repo_id = ? AND id < ?;")
+	_, err = tx.ExecContext(ctx, query, repoID, oldest)
+	return db.WrapError(err)
+}
+
+// GetRepoHookRunsByRepo implements store.RepoHookRunStore.
+func (*repoHookRunStore) GetRepoHookRunsByRepo(ctx context.Context, tx db.Handler, repo string, limit int) ([]models.RepoHookRun, error) {
+	var m []models.RepoHookRun
+	repo = utils.SanitizeRepo(repo)
+	query := `SELECT repo_hook_runs.* FROM repo_hook_runs
+			INNER JOIN repos ON repos.id = repo_hook_runs.repo_id
+			WHERE repos.name = ?
+			ORDER BY repo_hook_runs.id DESC`
+	args := []interface{}{repo}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	if err := tx.SelectContext(ctx, &m, tx.Rebind(query), args...); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	// Return the most recent runs in chronological order.
+	for i, j := 0, len(m)-1; i < j; i, j = i+1, j-1 {
+		m[i], m[j] = m[j], m[i]
+	}
+
+	return m, nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type repoSettingStore struct{}
+
+var _ store.RepoSettingStore = (*repoSettingStore)(nil)
+
+// GetRepoSetting implements store.RepoSettingStore.
+func (*repoSettingStore) GetRepoSetting(ctx context.Context, tx db.Handler, repo string, key string) (string, error) {
+	var value string
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT repo_settings.value
+			FROM repo_settings
+			INNER JOIN repos ON repos.id = repo_settings.repo_id
+			WHERE repos.name = ? AND repo_settings."key" = ?;`)
+	err := tx.GetContext(ctx, &value, query, repo, key)
+	return value, err
+}
+
+// GetRepoSettings implements store.RepoSettingStore.
+func (*repoSettingStore) GetRepoSettings(ctx context.Context, tx db.Handler, repo string) ([]models.RepoSetting, error) {
+	var m []models.RepoSetting
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT repo_settings.*
+			FROM repo_settings
+			INNER JOIN repos ON repos.id = repo_settings.repo_id
+			WHERE repos.name = ?
+			ORDER BY repo_settings."key" ASC;`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// GetRepoNameBySetting implements store.RepoSettingStore.
+func (*repoSettingStore) GetRepoNameBySetting(ctx context.Context, tx db.Handler, key string, value string) (string, error) {
+	var name string
+	query := tx.Rebind(`SELECT repos.name
+			FROM repo_settings
+			INNER JOIN repos ON repos.id = repo_settings.repo_id
+			WHERE repo_settings."key" = ? AND repo_settings.value = ?
+			ORDER BY repos.name ASC
+			LIMIT 1;`)
+	err := tx.GetContext(ctx, &name, query, key, value)
+	return name, err
+}
+
+// SetRepoSetting implements store.RepoSettingStore.
+func (*repoSettingStore) SetRepoSetting(ctx context.Context, tx db.Handler, repo string, key string, value string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO repo_settings (repo_id, "key", value, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (repo_id, "key") DO UPDATE SET
+				value = excluded.value,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, repo, key, value)
+	return err
+}
+
+// DeleteRepoSetting implements store.RepoSettingStore.
+func (*repoSettingStore) DeleteRepoSetting(ctx context.Context, tx db.Handler, repo string, key string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`DELETE FROM repo_settings
+			WHERE "key" = ? AND repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			);`)
+	_, err := tx.ExecContext(ctx, query, key, repo)
+	return err
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type repoTrafficStore struct{}
+
+var _ store.RepoTrafficStore = (*repoTrafficStore)(nil)
+
+// GetRepoTrafficByDay implements store.RepoTrafficStore.
+func (*repoTrafficStore) GetRepoTrafficByDay(ctx context.Context, tx db.Handler, repo string, day string) (models.RepoTraffic, error) {
+	var m models.RepoTraffic
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			repo_traffic.*
+		FROM
+			repo_traffic
+		INNER JOIN repos ON repos.id = repo_traffic.repo_id
+		WHERE
+			repos.name = ? AND repo_traffic.day = ?
+	`)
+	err := tx.GetContext(ctx, &m, query, repo, day)
+	return m, err
+}
+
+// GetRepoTrafficSince implements store.RepoTrafficStore.
+func (*repoTrafficStore) GetRepoTrafficSince(ctx context.Context, tx db.Handler, repo string, day string) ([]models.RepoTraffic, error) {
+	var m []models.RepoTraffic
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			repo_traffic.*
+		FROM
+			repo_traffic
+		INNER JOIN repos ON repos.id = repo_traffic.repo_id
+		WHERE
+			repos.name = ? AND repo_traffic.day >= ?
+		ORDER BY
+			repo_traffic.day ASC
+	`)
+	err := tx.SelectContext(ctx, &m, query, repo, day)
+	return m, err
+}
+
+// AddRepoTraffic implements store.RepoTrafficStore.
+func (*repoTrafficStore) AddRepoTraffic(ctx context.Context, tx db.Handler, repo string, day string, clones, fetches, pushes int64, clients []byte) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO repo_traffic (repo_id, day, clones, fetches, pushes, clients, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, ?, ?, ?, CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (repo_id, day) DO UPDATE SET
+				clones = repo_traffic.clones + excluded.clones,
+				fetches = repo_traffic.fetches + excluded.fetches,
+				pushes = repo_traffic.pushes + excluded.pushes,
+				clients = excluded.clients,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, repo, day, clones, fetches, pushes, clients)
+	return err
+}
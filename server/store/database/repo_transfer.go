@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type repoTransferStore struct{}
+
+var _ store.RepoTransferStore = (*repoTransferStore)(nil)
+
+// GetRepoTransferByRepo implements store.RepoTransferStore.
+func (*repoTransferStore) GetRepoTransferByRepo(ctx context.Context, tx db.Handler, repo string) (models.RepoTransfer, error) {
+	var m models.RepoTransfer
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT repo_transfers.*, repos.name AS repo
+			FROM repo_transfers
+			INNER JOIN repos ON repos.id = repo_transfers.repo_id
+			WHERE repos.name = ?;`)
+	err := tx.GetContext(ctx, &m, query, repo)
+	return m, db.WrapError(err)
+}
+
+// ListRepoTransfers implements store.RepoTransferStore.
+func (*repoTransferStore) ListRepoTransfers(ctx context.Context, tx db.Handler) ([]models.RepoTransfer, error) {
+	var m []models.RepoTransfer
+	query := tx.Rebind(`SELECT repo_transfers.*, repos.name AS repo
+			FROM repo_transfers
+			INNER JOIN repos ON repos.id = repo_transfers.repo_id
+			ORDER BY repo_transfers.id ASC;`)
+	err := tx.SelectContext(ctx, &m, query)
+	return m, db.WrapError(err)
+}
+
+// CreateRepoTransfer implements store.RepoTransferStore. It replaces the
+// pending transfer of the repository.
+func (*repoTransferStore) CreateRepoTransfer(ctx context.Context, tx db.Handler, repo string, userID int64, requestedBy int64) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`DELETE FROM repo_transfers
+			WHERE repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			);`)
+	if _, err := tx.ExecContext(ctx, query, repo); err != nil {
+		return db.WrapError(err)
+	}
+
+	var by *int64
+	if requestedBy > 0 {
+		by = &requestedBy
+	}
+	query = tx.Rebind(`INSERT INTO repo_transfers (repo_id, user_id, requested_by)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, userID, by)
+	return db.WrapError(err)
+}
+
+// DeleteRepoTransferByRepo implements store.RepoTransferStore.
+func (*repoTransferStore) DeleteRepoTransferByRepo(ctx context.Context, tx db.Handler, repo string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`DELETE FROM repo_transfers
+			WHERE repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo)
+	return db.WrapError(err)
+}
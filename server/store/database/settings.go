@@ -32,6 +32,26 @@ func (*settingsStore) GetAnonAccess(ctx context.Context, tx db.Handler) (access.
 	return access.ParseAccessLevel(level), nil
 }
 
+// GetMaintenance implements store.SettingStore.
+func (*settingsStore) GetMaintenance(ctx context.Context, tx db.Handler) (string, error) {
+	var value string
+	query := tx.Rebind(`SELECT value FROM settings WHERE "key" = 'maintenance'`)
+	if err := tx.GetContext(ctx, &value, query); err != nil {
+		return "", db.WrapError(err)
+	}
+	return value, nil
+}
+
+// GetMOTD implements store.SettingStore.
+func (*settingsStore) GetMOTD(ctx context.Context, tx db.Handler) (string, error) {
+	var motd string
+	query := tx.Rebind(`SELECT value FROM settings WHERE "key" = 'motd'`)
+	if err := tx.GetContext(ctx, &motd, query); err != nil {
+		return "", db.WrapError(err)
+	}
+	return motd, nil
+}
+
 // SetAllowKeylessAccess implements store.SettingStore.
 func (*settingsStore) SetAllowKeylessAccess(ctx context.Context, tx db.Handler, allow bool) error {
 	query := tx.Rebind(`UPDATE settings SET value = ?, updated_at = CURRENT_TIMESTAMP WHERE "key" = 'allow_keyless'`)
@@ -45,3 +65,17 @@ func (*settingsStore) SetAnonAccess(ctx context.Context, tx db.Handler, level ac
 	_, err := tx.ExecContext(ctx, query, level.String())
 	return db.WrapError(err)
 }
+
+// SetMaintenance implements store.SettingStore.
+func (*settingsStore) SetMaintenance(ctx context.Context, tx db.Handler, value string) error {
+	query := tx.Rebind(`UPDATE settings SET value = ?, updated_at = CURRENT_TIMESTAMP WHERE "key" = 'maintenance'`)
+	_, err := tx.ExecContext(ctx, query, value)
+	return db.WrapError(err)
+}
+
+// SetMOTD implements store.SettingStore.
+func (*settingsStore) SetMOTD(ctx context.Context, tx db.Handler, motd string) error {
+	query := tx.Rebind(`UPDATE settings SET value = ?, updated_at = CURRENT_TIMESTAMP WHERE "key" = 'motd'`)
+	_, err := tx.ExecContext(ctx, query, motd)
+	return db.WrapError(err)
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+	"github.com/charmbracelet/soft-serve/server/utils"
+)
+
+type tagTimestampStore struct{}
+
+var _ store.TagTimestampStore = (*tagTimestampStore)(nil)
+
+// GetTagTimestampByRepoAndTag implements store.TagTimestampStore.
+func (*tagTimestampStore) GetTagTimestampByRepoAndTag(ctx context.Context, tx db.Handler, repo string, tag string) (models.TagTimestamp, error) {
+	var m models.TagTimestamp
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`SELECT tag_timestamps.*
+			FROM tag_timestamps
+			INNER JOIN repos ON repos.id = tag_timestamps.repo_id
+			WHERE repos.name = ? AND tag_timestamps.tag = ?;`)
+	err := tx.GetContext(ctx, &m, query, repo, tag)
+	return m, err
+}
+
+// UpsertTagTimestamp implements store.TagTimestampStore.
+func (*tagTimestampStore) UpsertTagTimestamp(ctx context.Context, tx db.Handler, repo string, tag string, objectID string, token []byte) error {
+	repo = utils.SanitizeRepo(repo)
+	query := rebind(tx, `INSERT INTO tag_timestamps (repo_id, tag, object_id, token, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?, ?, ?, CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (repo_id, tag) DO UPDATE SET
+				object_id = excluded.object_id,
+				token = excluded.token,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, repo, tag, objectID, token)
+	return err
+}
+
+// DeleteTagTimestampByRepoAndTag implements store.TagTimestampStore.
+func (*tagTimestampStore) DeleteTagTimestampByRepoAndTag(ctx context.Context, tx db.Handler, repo string, tag string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`DELETE FROM tag_timestamps
+			WHERE tag = ? AND repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			);`)
+	_, err := tx.ExecContext(ctx, query, tag, repo)
+	return err
+}
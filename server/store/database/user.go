@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 
 	"github.com/charmbracelet/soft-serve/server/db"
@@ -43,11 +44,11 @@ func (*userStore) CreateUser(ctx context.Context, tx db.Handler, username string
 		return err
 	}
 
-	query := tx.Rebind(`INSERT INTO users (username, admin, updated_at)
-			VALUES (?, ?, CURRENT_TIMESTAMP) RETURNING id;`)
+	query := `INSERT INTO users (username, admin, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)`
 
-	var userID int64
-	if err := tx.GetContext(ctx, &userID, query, username, isAdmin); err != nil {
+	userID, err := insertID(ctx, tx, query, username, isAdmin)
+	if err != nil {
 		return err
 	}
 
@@ -122,7 +123,7 @@ func (*userStore) FindUserByAccessToken(ctx context.Context, tx db.Handler, toke
 // GetAllUsers implements store.UserStore.
 func (*userStore) GetAllUsers(ctx context.Context, tx db.Handler) ([]models.User, error) {
 	var ms []models.User
-	query := tx.Rebind(`SELECT * FROM users;`)
+	query := tx.Rebind(`SELECT * FROM users ORDER BY id;`)
 	err := tx.SelectContext(ctx, &ms, query)
 	return ms, err
 }
@@ -179,6 +180,51 @@ func (*userStore) ListPublicKeysByUsername(ctx context.Context, tx db.Handler, u
 	return pks, nil
 }
 
+// ListPublicKeyDetailsByUsername implements store.UserStore.
+func (*userStore) ListPublicKeyDetailsByUsername(ctx context.Context, tx db.Handler, username string) ([]models.PublicKey, error) {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return nil, err
+	}
+
+	var ms []models.PublicKey
+	query := tx.Rebind(`SELECT public_keys.* FROM public_keys
+			INNER JOIN users ON users.id = public_keys.user_id
+			WHERE users.username = ?
+			ORDER BY public_keys.id ASC;`)
+	err := tx.SelectContext(ctx, &ms, query, username)
+	return ms, err
+}
+
+// GetPublicKey implements store.UserStore.
+func (*userStore) GetPublicKey(ctx context.Context, tx db.Handler, pk ssh.PublicKey) (models.PublicKey, error) {
+	var m models.PublicKey
+	query := tx.Rebind(`SELECT * FROM public_keys WHERE public_key = ?;`)
+	err := tx.GetContext(ctx, &m, query, sshutils.MarshalAuthorizedKey(pk))
+	return m, err
+}
+
+// UpdatePublicKeyByUsername implements store.UserStore.
+func (*userStore) UpdatePublicKeyByUsername(ctx context.Context, tx db.Handler, username string, pk ssh.PublicKey, label string, expiresAt sql.NullTime, restriction string) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`UPDATE public_keys SET label = ?, expires_at = ?, restriction = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = (SELECT id FROM users WHERE username = ?)
+			AND public_key = ?;`)
+	_, err := tx.ExecContext(ctx, query, label, expiresAt, restriction, username, sshutils.MarshalAuthorizedKey(pk))
+	return err
+}
+
+// SetPublicKeyLastUsed implements store.UserStore.
+func (*userStore) SetPublicKeyLastUsed(ctx context.Context, tx db.Handler, pk ssh.PublicKey) error {
+	query := tx.Rebind(`UPDATE public_keys SET last_used_at = CURRENT_TIMESTAMP WHERE public_key = ?;`)
+	_, err := tx.ExecContext(ctx, query, sshutils.MarshalAuthorizedKey(pk))
+	return err
+}
+
 // RemovePublicKeyByUsername implements store.UserStore.
 func (*userStore) RemovePublicKeyByUsername(ctx context.Context, tx db.Handler, username string, pk ssh.PublicKey) error {
 	username = strings.ToLower(username)
@@ -205,6 +251,18 @@ func (*userStore) SetAdminByUsername(ctx context.Context, tx db.Handler, usernam
 	return err
 }
 
+// SetSuspendedByUsername implements store.UserStore.
+func (*userStore) SetSuspendedByUsername(ctx context.Context, tx db.Handler, username string, suspended bool) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`UPDATE users SET suspended = ? WHERE username = ?;`)
+	_, err := tx.ExecContext(ctx, query, suspended, username)
+	return err
+}
+
 // SetUsernameByUsername implements store.UserStore.
 func (*userStore) SetUsernameByUsername(ctx context.Context, tx db.Handler, username string, newUsername string) error {
 	username = strings.ToLower(username)
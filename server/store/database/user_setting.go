@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"github.com/charmbracelet/soft-serve/server/store"
+)
+
+type userSettingStore struct{}
+
+var _ store.UserSettingStore = (*userSettingStore)(nil)
+
+// GetUserSetting implements store.UserSettingStore.
+func (*userSettingStore) GetUserSetting(ctx context.Context, tx db.Handler, userID int64, key string) (string, error) {
+	var value string
+	query := tx.Rebind(`SELECT value FROM user_settings WHERE user_id = ? AND "key" = ?`)
+	err := tx.GetContext(ctx, &value, query, userID, key)
+	return value, err
+}
+
+// GetUserSettings implements store.UserSettingStore.
+func (*userSettingStore) GetUserSettings(ctx context.Context, tx db.Handler, userID int64) ([]models.UserSetting, error) {
+	var m []models.UserSetting
+	query := tx.Rebind(`SELECT * FROM user_settings WHERE user_id = ? ORDER BY "key" ASC`)
+	err := tx.SelectContext(ctx, &m, query, userID)
+	return m, err
+}
+
+// GetUserIDBySetting implements store.UserSettingStore.
+func (*userSettingStore) GetUserIDBySetting(ctx context.Context, tx db.Handler, key string, value string) (int64, error) {
+	var id int64
+	query := tx.Rebind(`SELECT user_id FROM user_settings WHERE "key" = ? AND value = ?`)
+	err := tx.GetContext(ctx, &id, query, key, value)
+	return id, err
+}
+
+// SetUserSetting implements store.UserSettingStore.
+func (*userSettingStore) SetUserSetting(ctx context.Context, tx db.Handler, userID int64, key string, value string) error {
+	query := rebind(tx, `INSERT INTO user_settings (user_id, "key", value, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (user_id, "key") DO UPDATE SET
+				value = excluded.value,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := tx.ExecContext(ctx, query, userID, key, value)
+	return err
+}
+
+// DeleteUserSetting implements store.UserSettingStore.
+func (*userSettingStore) DeleteUserSetting(ctx context.Context, tx db.Handler, userID int64, key string) error {
+	query := tx.Rebind(`DELETE FROM user_settings WHERE user_id = ? AND "key" = ?`)
+	_, err := tx.ExecContext(ctx, query, userID, key)
+	return err
+}
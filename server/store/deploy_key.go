@@ -0,0 +1,19 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// DeployKeyStore is an interface for managing repository deploy keys.
+type DeployKeyStore interface {
+	GetDeployKeyByRepoAndPublicKey(ctx context.Context, h db.Handler, repo string, pk ssh.PublicKey) (models.DeployKey, error)
+	ListDeployKeysByRepo(ctx context.Context, h db.Handler, repo string) ([]models.DeployKey, error)
+	ListDeployKeysByPublicKey(ctx context.Context, h db.Handler, pk ssh.PublicKey) ([]models.DeployKey, error)
+	CreateDeployKey(ctx context.Context, h db.Handler, repo string, title string, pk ssh.PublicKey, level access.AccessLevel) error
+	DeleteDeployKeyForRepo(ctx context.Context, h db.Handler, repo string, id int64) error
+}
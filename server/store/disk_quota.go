@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// DiskQuotaStore is an interface for managing the disk usage and the disk
+// quotas of users and repositories.
+type DiskQuotaStore interface {
+	GetRepoDiskUsage(ctx context.Context, h db.Handler, repoID int64) (models.RepoDiskUsage, error)
+	GetRepoDiskUsages(ctx context.Context, h db.Handler) ([]models.RepoDiskUsage, error)
+	SetRepoDiskUsage(ctx context.Context, h db.Handler, usage models.RepoDiskUsage) error
+	GetRepoLargeBlobs(ctx context.Context, h db.Handler, repoID int64) ([]models.RepoLargeBlob, error)
+	// SetRepoLargeBlobs replaces the largest blobs of a repository.
+	SetRepoLargeBlobs(ctx context.Context, h db.Handler, repoID int64, blobs []models.RepoLargeBlob) error
+	// GetUserDiskUsage returns the total disk usage of the repositories of a
+	// user.
+	GetUserDiskUsage(ctx context.Context, h db.Handler, userID int64) (int64, error)
+
+	GetUserDiskQuota(ctx context.Context, h db.Handler, userID int64) (models.DiskQuota, error)
+	SetUserDiskQuota(ctx context.Context, h db.Handler, userID int64, size int64) error
+	DeleteUserDiskQuota(ctx context.Context, h db.Handler, userID int64) error
+	GetRepoDiskQuota(ctx context.Context, h db.Handler, repoID int64) (models.DiskQuota, error)
+	SetRepoDiskQuota(ctx context.Context, h db.Handler, repoID int64, size int64) error
+	DeleteRepoDiskQuota(ctx context.Context, h db.Handler, repoID int64) error
+}
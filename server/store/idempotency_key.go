@@ -0,0 +1,15 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// IdempotencyKeyStore is an interface for managing idempotency keys.
+type IdempotencyKeyStore interface {
+	GetIdempotencyKey(ctx context.Context, h db.Handler, userID int64, key string) (models.IdempotencyKey, error)
+	CreateIdempotencyKey(ctx context.Context, h db.Handler, userID int64, key string, request string, response string) error
+	DeleteIdempotencyKey(ctx context.Context, h db.Handler, userID int64, key string) error
+}
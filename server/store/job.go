@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// JobStore is an interface for managing the persistent job queue. Jobs are
+// "pending" until a worker claims them, "running" while they run, and "dead"
+// once they failed their last attempt. Completed jobs are deleted.
+type JobStore interface {
+	// CreateJob queues a pending job, unless a job of the same kind and
+	// payload is pending or running already.
+	CreateJob(ctx context.Context, h db.Handler, kind string, payload string, maxAttempts int, runAt time.Time) error
+	// ClaimJob marks the oldest pending job due at now as running and
+	// returns it.
+	ClaimJob(ctx context.Context, h db.Handler, now time.Time) (models.Job, error)
+	CompleteJob(ctx context.Context, h db.Handler, id int64) error
+	// FailJob records the error of a running job, and queues it again at
+	// retryAt, or marks it as dead when retryAt is zero.
+	FailJob(ctx context.Context, h db.Handler, id int64, lastError string, retryAt time.Time) error
+	// RetryJob queues a dead job again at runAt with a fresh set of
+	// attempts.
+	RetryJob(ctx context.Context, h db.Handler, id int64, runAt time.Time) error
+	// RequeueRunningJobs queues the jobs running since before claimedBefore
+	// again at runAt, i.e. the jobs interrupted by a restart.
+	RequeueRunningJobs(ctx context.Context, h db.Handler, claimedBefore time.Time, runAt time.Time) error
+	GetJobByID(ctx context.Context, h db.Handler, id int64) (models.Job, error)
+	// GetJobs returns the jobs with a status, or all the jobs when status is
+	// empty.
+	GetJobs(ctx context.Context, h db.Handler, status string) ([]models.Job, error)
+}
@@ -0,0 +1,22 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+// LockStore is an interface for managing the locks shared between servers. A
+// lock is held by its owner until it's released, or until it expires.
+type LockStore interface {
+	// AcquireLock takes the lock with a name for owner until expiresAt. It
+	// fails with a constraint violation when the lock is held by someone else
+	// and doesn't expire before now.
+	AcquireLock(ctx context.Context, h db.Handler, name string, owner string, now time.Time, expiresAt time.Time) error
+	// RefreshLock extends a lock held by owner until expiresAt, it returns
+	// sql.ErrNoRows when owner doesn't hold it anymore.
+	RefreshLock(ctx context.Context, h db.Handler, name string, owner string, expiresAt time.Time) error
+	// ReleaseLock releases a lock held by owner.
+	ReleaseLock(ctx context.Context, h db.Handler, name string, owner string) error
+}
@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// PushPolicyStore is an interface for managing repository push policies.
+type PushPolicyStore interface {
+	GetPushPolicyByRepo(ctx context.Context, h db.Handler, repo string) (models.PushPolicy, error)
+	UpsertPushPolicy(ctx context.Context, h db.Handler, repo string, maxBlobSize sql.NullInt64, maxPushSize sql.NullInt64, bannedExtensions sql.NullString) error
+	DeletePushPolicyByRepo(ctx context.Context, h db.Handler, repo string) error
+}
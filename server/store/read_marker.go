@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+)
+
+// ReadMarkerStore is an interface for managing the commits users have seen.
+type ReadMarkerStore interface {
+	GetRepoReadAt(ctx context.Context, h db.Handler, userID int64, repo string) (time.Time, error)
+	MarkRepoRead(ctx context.Context, h db.Handler, userID int64, repo string) error
+	GetCommitReads(ctx context.Context, h db.Handler, userID int64, repo string) ([]string, error)
+	AddCommitRead(ctx context.Context, h db.Handler, userID int64, repo string, hash string) error
+	DeleteCommitReads(ctx context.Context, h db.Handler, userID int64, repo string) error
+}
@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// RefPermissionStore is an interface for managing who can push to the
+// references of a repository.
+type RefPermissionStore interface {
+	GetRefPermissionsByRepo(ctx context.Context, h db.Handler, repo string) ([]models.RefPermission, error)
+	AddRefPermission(ctx context.Context, h db.Handler, repo string, pattern string, userID int64) error
+	RemoveRefPermission(ctx context.Context, h db.Handler, repo string, pattern string, userID int64) error
+	RemoveRefPermissionsByPattern(ctx context.Context, h db.Handler, repo string, pattern string) error
+}
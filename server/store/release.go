@@ -0,0 +1,26 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// ReleaseStore is an interface for managing repository releases and their
+// assets.
+type ReleaseStore interface {
+	GetReleasesByRepo(ctx context.Context, h db.Handler, repo string) ([]models.Release, error)
+	GetReleaseByRepoAndTag(ctx context.Context, h db.Handler, repo string, tag string) (models.Release, error)
+	// CreateRelease creates a release. A userID of 0 means no author.
+	CreateRelease(ctx context.Context, h db.Handler, repo string, tag string, name string, notes string, userID int64) error
+	UpdateRelease(ctx context.Context, h db.Handler, id int64, name string, notes string) error
+	DeleteReleaseByID(ctx context.Context, h db.Handler, id int64) error
+
+	GetReleaseAssets(ctx context.Context, h db.Handler, releaseID int64) ([]models.ReleaseAsset, error)
+	GetReleaseAssetByName(ctx context.Context, h db.Handler, releaseID int64, name string) (models.ReleaseAsset, error)
+	// SetReleaseAsset creates an asset or replaces the one with the same
+	// name.
+	SetReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string, size int64, sha256 string) error
+	DeleteReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string) error
+}
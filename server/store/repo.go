@@ -15,6 +15,7 @@ type RepositoryStore interface {
 	CreateRepo(ctx context.Context, h db.Handler, name string, userID int64, projectName string, description string, isPrivate bool, isHidden bool, isMirror bool) error
 	DeleteRepoByName(ctx context.Context, h db.Handler, name string) error
 	SetRepoNameByName(ctx context.Context, h db.Handler, name string, newName string) error
+	SetRepoUserIDByName(ctx context.Context, h db.Handler, name string, userID int64) error
 
 	GetRepoProjectNameByName(ctx context.Context, h db.Handler, name string) (string, error)
 	SetRepoProjectNameByName(ctx context.Context, h db.Handler, name string, projectName string) error
@@ -22,7 +23,13 @@ type RepositoryStore interface {
 	SetRepoDescriptionByName(ctx context.Context, h db.Handler, name string, description string) error
 	GetRepoIsPrivateByName(ctx context.Context, h db.Handler, name string) (bool, error)
 	SetRepoIsPrivateByName(ctx context.Context, h db.Handler, name string, isPrivate bool) error
+	GetRepoIsInternalByName(ctx context.Context, h db.Handler, name string) (bool, error)
+	SetRepoIsInternalByName(ctx context.Context, h db.Handler, name string, isInternal bool) error
 	GetRepoIsHiddenByName(ctx context.Context, h db.Handler, name string) (bool, error)
 	SetRepoIsHiddenByName(ctx context.Context, h db.Handler, name string, isHidden bool) error
 	GetRepoIsMirrorByName(ctx context.Context, h db.Handler, name string) (bool, error)
+	GetRepoIsTemplateByName(ctx context.Context, h db.Handler, name string) (bool, error)
+	SetRepoIsTemplateByName(ctx context.Context, h db.Handler, name string, isTemplate bool) error
+	GetRepoIsArchivedByName(ctx context.Context, h db.Handler, name string) (bool, error)
+	SetRepoIsArchivedByName(ctx context.Context, h db.Handler, name string, isArchived bool) error
 }
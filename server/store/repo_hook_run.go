@@ -0,0 +1,19 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// RepoHookRunStore is an interface for logging the runs of the hook scripts
+// of repositories.
+type RepoHookRunStore interface {
+	// CreateRepoHookRun logs a run, and deletes the runs of the repository
+	// older than the keep most recent ones.
+	CreateRepoHookRun(ctx context.Context, h db.Handler, repo string, run models.RepoHookRun, keep int) error
+	// GetRepoHookRunsByRepo returns the limit most recent runs of a
+	// repository in chronological order, all of them if limit is 0.
+	GetRepoHookRunsByRepo(ctx context.Context, h db.Handler, repo string, limit int) ([]models.RepoHookRun, error)
+}
@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// RepoSettingStore is an interface for managing repository settings.
+type RepoSettingStore interface {
+	GetRepoSetting(ctx context.Context, h db.Handler, repo string, key string) (string, error)
+	GetRepoSettings(ctx context.Context, h db.Handler, repo string) ([]models.RepoSetting, error)
+	GetRepoNameBySetting(ctx context.Context, h db.Handler, key string, value string) (string, error)
+	SetRepoSetting(ctx context.Context, h db.Handler, repo string, key string, value string) error
+	DeleteRepoSetting(ctx context.Context, h db.Handler, repo string, key string) error
+}
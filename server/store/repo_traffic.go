@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// RepoTrafficStore is an interface for managing the daily traffic of
+// repositories. Days are formatted as "2006-01-02" in UTC.
+type RepoTrafficStore interface {
+	GetRepoTrafficByDay(ctx context.Context, h db.Handler, repo string, day string) (models.RepoTraffic, error)
+	GetRepoTrafficSince(ctx context.Context, h db.Handler, repo string, day string) ([]models.RepoTraffic, error)
+	// AddRepoTraffic adds to the counters of a day and replaces its clients.
+	AddRepoTraffic(ctx context.Context, h db.Handler, repo string, day string, clones, fetches, pushes int64, clients []byte) error
+}
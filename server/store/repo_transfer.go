@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// RepoTransferStore is an interface for managing pending repository transfers.
+type RepoTransferStore interface {
+	GetRepoTransferByRepo(ctx context.Context, h db.Handler, repo string) (models.RepoTransfer, error)
+	ListRepoTransfers(ctx context.Context, h db.Handler) ([]models.RepoTransfer, error)
+	CreateRepoTransfer(ctx context.Context, h db.Handler, repo string, userID int64, requestedBy int64) error
+	DeleteRepoTransferByRepo(ctx context.Context, h db.Handler, repo string) error
+}
@@ -13,4 +13,8 @@ type SettingStore interface {
 	SetAnonAccess(ctx context.Context, h db.Handler, level access.AccessLevel) error
 	GetAllowKeylessAccess(ctx context.Context, h db.Handler) (bool, error)
 	SetAllowKeylessAccess(ctx context.Context, h db.Handler, allow bool) error
+	GetMaintenance(ctx context.Context, h db.Handler) (string, error)
+	SetMaintenance(ctx context.Context, h db.Handler, value string) error
+	GetMOTD(ctx context.Context, h db.Handler) (string, error)
+	SetMOTD(ctx context.Context, h db.Handler, motd string) error
 }
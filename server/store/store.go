@@ -8,4 +8,21 @@ type Store interface {
 	SettingStore
 	LFSStore
 	AccessTokenStore
+	BranchProtectionStore
+	UserSettingStore
+	PushPolicyStore
+	ReadMarkerStore
+	IdempotencyKeyStore
+	DeployKeyStore
+	TagTimestampStore
+	RepoSettingStore
+	RefPermissionStore
+	AuditEventStore
+	RepoTrafficStore
+	ReleaseStore
+	JobStore
+	LockStore
+	DiskQuotaStore
+	RepoTransferStore
+	RepoHookRunStore
 }
@@ -0,0 +1,15 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// TagTimestampStore is an interface for managing the timestamps of tags.
+type TagTimestampStore interface {
+	GetTagTimestampByRepoAndTag(ctx context.Context, h db.Handler, repo string, tag string) (models.TagTimestamp, error)
+	UpsertTagTimestamp(ctx context.Context, h db.Handler, repo string, tag string, objectID string, token []byte) error
+	DeleteTagTimestampByRepoAndTag(ctx context.Context, h db.Handler, repo string, tag string) error
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/charmbracelet/soft-serve/server/db"
 	"github.com/charmbracelet/soft-serve/server/db/models"
@@ -19,10 +20,15 @@ type UserStore interface {
 	DeleteUserByUsername(ctx context.Context, h db.Handler, username string) error
 	SetUsernameByUsername(ctx context.Context, h db.Handler, username string, newUsername string) error
 	SetAdminByUsername(ctx context.Context, h db.Handler, username string, isAdmin bool) error
+	SetSuspendedByUsername(ctx context.Context, h db.Handler, username string, suspended bool) error
 	AddPublicKeyByUsername(ctx context.Context, h db.Handler, username string, pk ssh.PublicKey) error
 	RemovePublicKeyByUsername(ctx context.Context, h db.Handler, username string, pk ssh.PublicKey) error
 	ListPublicKeysByUserID(ctx context.Context, h db.Handler, id int64) ([]ssh.PublicKey, error)
 	ListPublicKeysByUsername(ctx context.Context, h db.Handler, username string) ([]ssh.PublicKey, error)
+	ListPublicKeyDetailsByUsername(ctx context.Context, h db.Handler, username string) ([]models.PublicKey, error)
+	GetPublicKey(ctx context.Context, h db.Handler, pk ssh.PublicKey) (models.PublicKey, error)
+	UpdatePublicKeyByUsername(ctx context.Context, h db.Handler, username string, pk ssh.PublicKey, label string, expiresAt sql.NullTime, restriction string) error
+	SetPublicKeyLastUsed(ctx context.Context, h db.Handler, pk ssh.PublicKey) error
 	SetUserPassword(ctx context.Context, h db.Handler, userID int64, password string) error
 	SetUserPasswordByUsername(ctx context.Context, h db.Handler, username string, password string) error
 }
@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/db/models"
+)
+
+// UserSettingStore is an interface for managing user settings.
+type UserSettingStore interface {
+	GetUserSetting(ctx context.Context, h db.Handler, userID int64, key string) (string, error)
+	GetUserSettings(ctx context.Context, h db.Handler, userID int64) ([]models.UserSetting, error)
+	GetUserIDBySetting(ctx context.Context, h db.Handler, key string, value string) (int64, error)
+	SetUserSetting(ctx context.Context, h db.Handler, userID int64, key string, value string) error
+	DeleteUserSetting(ctx context.Context, h db.Handler, userID int64, key string) error
+}
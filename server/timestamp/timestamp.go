@@ -0,0 +1,426 @@
+// Package timestamp implements an RFC 3161 trusted timestamping client.
+// https://www.rfc-editor.org/rfc/rfc3161
+package timestamp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned when a timestamp token can't be parsed.
+	ErrInvalidToken = errors.New("invalid timestamp token")
+	// ErrMismatch is returned when a timestamp is for different data.
+	ErrMismatch = errors.New("timestamp doesn't match the data")
+	// ErrInvalidSignature is returned when the signature of a timestamp
+	// doesn't verify.
+	ErrInvalidSignature = errors.New("invalid timestamp signature")
+)
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidRSA           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA256WithRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECDSA         = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidECDSAWithSHA2 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3}
+	oidEd25519       = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type request struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int `asn1:"optional"`
+	CertReq        bool     `asn1:"optional,default:false"`
+}
+
+type statusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional,utf8"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type response struct {
+	Status statusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type encapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       accuracy      `asn1:"optional"`
+	Ordering       bool          `asn1:"optional,default:false"`
+	Nonce          *big.Int      `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// Timestamp is a parsed timestamp token.
+type Timestamp struct {
+	// Time is the time the timestamp authority saw the data.
+	Time time.Time
+	// SerialNumber is the serial number of the timestamp.
+	SerialNumber *big.Int
+	// Policy is the policy of the timestamp authority the timestamp was
+	// issued under.
+	Policy asn1.ObjectIdentifier
+	// HashAlgorithm is the hash algorithm of HashedMessage.
+	HashAlgorithm crypto.Hash
+	// HashedMessage is the hash of the timestamped data.
+	HashedMessage []byte
+	// Certificates are the certificates included in the token.
+	Certificates []*x509.Certificate
+	// Raw is the DER encoded token.
+	Raw []byte
+
+	nonce  *big.Int
+	signer signerInfo
+	// content is the DER encoded TSTInfo the signature covers.
+	content []byte
+}
+
+// Request asks the timestamp authority at url for a timestamp of a SHA-256
+// digest and returns the DER encoded timestamp token.
+func Request(ctx context.Context, client *http.Client, url string, digest []byte) ([]byte, error) {
+	if len(digest) != crypto.SHA256.Size() {
+		return nil, fmt.Errorf("invalid sha256 digest length %d", len(digest))
+	}
+
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := asn1.Marshal(request{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+
+	hreq.Header.Set("Content-Type", "application/timestamp-query")
+	hreq.Header.Set("Accept", "application/timestamp-reply")
+	resp, err := client.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp authority: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var r response
+	if rest, err := asn1.Unmarshal(body, &r); err != nil || len(rest) > 0 {
+		return nil, errors.New("invalid timestamp response")
+	}
+
+	// 0 is granted, 1 is granted with modifications.
+	if r.Status.Status > 1 {
+		return nil, fmt.Errorf("timestamp authority: status %d: %s", r.Status.Status, strings.Join(r.Status.StatusString, ", "))
+	}
+
+	ts, err := Parse(r.Token.FullBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if ts.nonce == nil || ts.nonce.Cmp(nonce) != 0 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+
+	if ts.HashAlgorithm != crypto.SHA256 || !bytes.Equal(ts.HashedMessage, digest) {
+		return nil, ErrMismatch
+	}
+
+	return ts.Raw, nil
+}
+
+// Parse parses a DER encoded timestamp token. It doesn't verify the token,
+// use Verify for that.
+func Parse(token []byte) (*Timestamp, error) {
+	var ci contentInfo
+	if rest, err := asn1.Unmarshal(token, &ci); err != nil || len(rest) > 0 || !ci.ContentType.Equal(oidSignedData) {
+		return nil, ErrInvalidToken
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) || len(sd.SignerInfos) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	hash, ok := hashOf(info.MessageImprint.HashAlgorithm.Algorithm)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported hash algorithm %s", ErrInvalidToken, info.MessageImprint.HashAlgorithm.Algorithm)
+	}
+
+	ts := &Timestamp{
+		Time:          info.GenTime,
+		SerialNumber:  info.SerialNumber,
+		Policy:        info.Policy,
+		HashAlgorithm: hash,
+		HashedMessage: info.MessageImprint.HashedMessage,
+		Raw:           token,
+		nonce:         info.Nonce,
+		signer:        sd.SignerInfos[0],
+		content:       sd.EncapContentInfo.EContent,
+	}
+
+	if len(sd.Certificates.Bytes) > 0 {
+		certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		ts.Certificates = certs
+	}
+
+	return ts, nil
+}
+
+// Signer returns the certificate of the timestamp authority that signed
+// the timestamp, or nil if the token doesn't include it.
+func (t *Timestamp) Signer() *x509.Certificate {
+	sid := t.signer.SID
+	for _, c := range t.Certificates {
+		switch {
+		case sid.Class == asn1.ClassContextSpecific && sid.Tag == 0:
+			if bytes.Equal(sid.Bytes, c.SubjectKeyId) {
+				return c
+			}
+		case sid.Tag == asn1.TagSequence:
+			var is issuerAndSerial
+			if _, err := asn1.Unmarshal(sid.FullBytes, &is); err != nil {
+				return nil
+			}
+			if bytes.Equal(is.Issuer.FullBytes, c.RawIssuer) && is.Serial.Cmp(c.SerialNumber) == 0 {
+				return c
+			}
+		}
+	}
+
+	return nil
+}
+
+// Verify checks that the timestamp is for data with the given SHA-256 digest,
+// that it's signed by its timestamp authority, and that the certificate of
+// the authority chains up to roots. A nil roots uses the system roots.
+func (t *Timestamp) Verify(digest []byte, roots *x509.CertPool) error {
+	if t.HashAlgorithm != crypto.SHA256 || !bytes.Equal(t.HashedMessage, digest) {
+		return ErrMismatch
+	}
+
+	signer := t.Signer()
+	if signer == nil {
+		return fmt.Errorf("%w: signer certificate not found", ErrInvalidSignature)
+	}
+
+	hash, ok := hashOf(t.signer.DigestAlgorithm.Algorithm)
+	if !ok {
+		return fmt.Errorf("%w: unsupported digest algorithm", ErrInvalidSignature)
+	}
+
+	// The signature covers the DER encoded SET of the signed attributes.
+	// https://www.rfc-editor.org/rfc/rfc5652#section-5.4
+	if len(t.signer.SignedAttrs.FullBytes) == 0 {
+		return fmt.Errorf("%w: missing signed attributes", ErrInvalidSignature)
+	}
+
+	signed := append([]byte{0x31}, t.signer.SignedAttrs.FullBytes[1:]...)
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(signed, &attrs, "set"); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	var md []byte
+	var contentType asn1.ObjectIdentifier
+	for _, a := range attrs {
+		switch {
+		case a.Type.Equal(oidMessageDigest):
+			_, err := asn1.Unmarshal(a.Values.Bytes, &md)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+			}
+		case a.Type.Equal(oidContentType):
+			_, err := asn1.Unmarshal(a.Values.Bytes, &contentType)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+			}
+		}
+	}
+
+	if !contentType.Equal(oidTSTInfo) {
+		return fmt.Errorf("%w: content type mismatch", ErrInvalidSignature)
+	}
+
+	h := hash.New()
+	h.Write(t.content) // nolint: errcheck
+	if !bytes.Equal(md, h.Sum(nil)) {
+		return fmt.Errorf("%w: message digest mismatch", ErrInvalidSignature)
+	}
+
+	algo, ok := signatureAlgorithm(t.signer.SignatureAlgorithm.Algorithm, hash)
+	if !ok {
+		return fmt.Errorf("%w: unsupported signature algorithm %s", ErrInvalidSignature, t.signer.SignatureAlgorithm.Algorithm)
+	}
+
+	if err := signer.CheckSignature(algo, signed, t.signer.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range t.Certificates {
+		if c != signer {
+			intermediates.AddCert(c)
+		}
+	}
+
+	if _, err := signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   t.Time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	return nil
+}
+
+func hashOf(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	switch {
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, true
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, true
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, true
+	default:
+		return 0, false
+	}
+}
+
+func signatureAlgorithm(oid asn1.ObjectIdentifier, hash crypto.Hash) (x509.SignatureAlgorithm, bool) {
+	switch {
+	case oid.Equal(oidEd25519):
+		return x509.PureEd25519, true
+	case oid.Equal(oidRSA), oid.Equal(oidSHA256WithRSA), oid.Equal(oidSHA384WithRSA), oid.Equal(oidSHA512WithRSA):
+		switch hash {
+		case crypto.SHA256:
+			return x509.SHA256WithRSA, true
+		case crypto.SHA384:
+			return x509.SHA384WithRSA, true
+		case crypto.SHA512:
+			return x509.SHA512WithRSA, true
+		}
+	case oid.Equal(oidECDSA), len(oid) == 7 && oid[:6].Equal(oidECDSAWithSHA2):
+		switch hash {
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256, true
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384, true
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512, true
+		}
+	}
+
+	return x509.UnknownSignatureAlgorithm, false
+}
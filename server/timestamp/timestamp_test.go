@@ -0,0 +1,225 @@
+package timestamp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type authority struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+	now  time.Time
+	// nonce overrides the nonce of the responses when set.
+	nonce *big.Int
+}
+
+func newAuthority(t *testing.T) *authority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test TSA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &authority{key: key, cert: cert, now: time.Now().UTC().Truncate(time.Second)}
+}
+
+func (a *authority) roots() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(a.cert)
+	return pool
+}
+
+func (a *authority) sign(t *testing.T, req request) []byte {
+	t.Helper()
+	nonce := req.Nonce
+	if a.nonce != nil {
+		nonce = a.nonce
+	}
+
+	content, err := asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   big.NewInt(42),
+		GenTime:        a.now,
+		Nonce:          nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := sha256.Sum256(content)
+	ct, _ := asn1.Marshal(oidTSTInfo)
+	mdv, _ := asn1.Marshal(md[:])
+	attrs, err := asn1.MarshalWithParams([]attribute{
+		{Type: oidContentType, Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: ct}},
+		{Type: oidMessageDigest, Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: mdv}},
+	}, "set")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := sha256.Sum256(attrs)
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sid asn1.RawValue
+	if _, err := asn1.Unmarshal(mustMarshal(t, issuerAndSerial{
+		Issuer: asn1.RawValue{FullBytes: a.cert.RawIssuer},
+		Serial: a.cert.SerialNumber,
+	}), &sid); err != nil {
+		t.Fatal(err)
+	}
+
+	// Strip the SET header of the signed attributes, they're encoded as an
+	// implicit [0].
+	var attrsSet asn1.RawValue
+	if _, err := asn1.Unmarshal(attrs, &attrsSet); err != nil {
+		t.Fatal(err)
+	}
+
+	sd := mustMarshal(t, signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: encapContentInfo{EContentType: oidTSTInfo, EContent: content},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: a.cert.Raw},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                sid,
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrsSet.Bytes},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: append(asn1.ObjectIdentifier{}, 1, 2, 840, 10045, 4, 3, 2)},
+			Signature:          sig,
+		}},
+	})
+
+	return mustMarshal(t, contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+}
+
+func (a *authority) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req request
+		if _, err := asn1.Unmarshal(body, &req); err != nil {
+			w.Write(mustMarshal(t, response{Status: statusInfo{Status: 2}})) // nolint: errcheck
+			return
+		}
+
+		token := a.sign(t, req)
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		w.Write(mustMarshal(t, response{ // nolint: errcheck
+			Status: statusInfo{Status: 0},
+			Token:  asn1.RawValue{FullBytes: token},
+		}))
+	}))
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestRequestAndVerify(t *testing.T) {
+	a := newAuthority(t)
+	srv := a.server(t)
+	defer srv.Close()
+
+	digest := sha256.Sum256([]byte("hello"))
+	token, err := Request(context.Background(), srv.Client(), srv.URL, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := Parse(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ts.Time.Equal(a.now) {
+		t.Errorf("time = %s, want %s", ts.Time, a.now)
+	}
+	if ts.HashAlgorithm != crypto.SHA256 {
+		t.Errorf("hash = %s, want SHA-256", ts.HashAlgorithm)
+	}
+	if ts.SerialNumber.Int64() != 42 {
+		t.Errorf("serial = %s, want 42", ts.SerialNumber)
+	}
+	if s := ts.Signer(); s == nil || s.Subject.CommonName != "Test TSA" {
+		t.Errorf("signer = %v, want Test TSA", s)
+	}
+
+	if err := ts.Verify(digest[:], a.roots()); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	other := sha256.Sum256([]byte("bye"))
+	if err := ts.Verify(other[:], a.roots()); !errors.Is(err, ErrMismatch) {
+		t.Errorf("verify other data: %v, want %v", err, ErrMismatch)
+	}
+
+	if err := ts.Verify(digest[:], newAuthority(t).roots()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("verify untrusted authority: %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestRequestNonceMismatch(t *testing.T) {
+	a := newAuthority(t)
+	a.nonce = big.NewInt(1)
+	srv := a.server(t)
+	defer srv.Close()
+
+	digest := sha256.Sum256([]byte("hello"))
+	if _, err := Request(context.Background(), srv.Client(), srv.URL, digest[:]); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("request: %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("nope")); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("parse: %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -0,0 +1,58 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestCode(t *testing.T) {
+	// Test vectors of RFC 6238, truncated to 6 digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for ts, want := range cases {
+		got, err := Code(secret, Step(time.Unix(ts, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("code at %d: expected %s, got %s", ts, want, got)
+		}
+	}
+
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("expected an error for an invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	code, err := Code(secret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if step, ok := Validate(secret, code, now); !ok || step != Step(now) {
+		t.Errorf("expected code to be valid at step %d, got %d %v", Step(now), step, ok)
+	}
+	if _, ok := Validate(secret, code, now.Add(Period)); !ok {
+		t.Error("expected code to be valid within the skew")
+	}
+	if _, ok := Validate(secret, code, now.Add(3*Period)); ok {
+		t.Error("expected code to be invalid after the skew")
+	}
+	if _, ok := Validate(secret, "12345", now); ok {
+		t.Error("expected short codes to be invalid")
+	}
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/server/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer is the tracer of the Soft Serve spans. It doesn't record anything
+// until tracing is enabled.
+var tracer = otel.Tracer("github.com/charmbracelet/soft-serve")
+
+// ContextKey is the context key of the spans stored in SSH contexts, their
+// values can be set but they can't be wrapped.
+var ContextKey = struct{ string }{"span"}
+
+// Init sets up the OTLP exporter of the configuration. The returned function
+// flushes and stops the exporter. It does nothing when tracing is disabled.
+func Init(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	if !cfg.Tracing.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(cfg.Tracing.Endpoint),
+	}
+	if cfg.Tracing.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exp, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.Tracing.ServiceName),
+	)
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// Start starts a span and returns a context with the span.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error of a span, if any, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Context returns a context with the span stored under ContextKey as its
+// current span.
+func Context(ctx context.Context) context.Context {
+	if span, ok := ctx.Value(ContextKey).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span)
+	}
+
+	return ctx
+}
@@ -5,13 +5,19 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/tracing"
 	"github.com/charmbracelet/soft-serve/server/ui/keymap"
 	"github.com/charmbracelet/soft-serve/server/ui/styles"
 	"github.com/charmbracelet/ssh"
 	zone "github.com/lrstanley/bubblezone"
 	"github.com/muesli/termenv"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type contextKey struct {
@@ -20,8 +26,9 @@ type contextKey struct {
 
 // Keys to use for context.Context.
 var (
-	ConfigKey = &contextKey{"config"}
-	RepoKey   = &contextKey{"repo"}
+	ConfigKey     = &contextKey{"config"}
+	RepoKey       = &contextKey{"repo"}
+	TimeFormatKey = &contextKey{"time-format"}
 )
 
 // Common is a struct all components should embed.
@@ -48,7 +55,7 @@ func NewCommon(ctx context.Context, out *termenv.Output, width, height int) Comm
 		Styles: styles.DefaultStyles(),
 		KeyMap: keymap.DefaultKeyMap(),
 		Zone:   zone.New(),
-		Logger: log.FromContext(ctx).WithPrefix("ui"),
+		Logger: logr.WithPrefix(log.FromContext(ctx), logr.SubsystemUI),
 	}
 }
 
@@ -68,6 +75,12 @@ func (c *Common) Context() context.Context {
 	return c.ctx
 }
 
+// StartSpan starts the tracing span of a backend call of the TUI, the
+// returned context carries the span.
+func (c *Common) StartSpan(name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(c.ctx, "ui."+name, attrs...)
+}
+
 // Config returns the server config.
 func (c *Common) Config() *config.Config {
 	return config.FromContext(c.ctx)
@@ -78,6 +91,21 @@ func (c *Common) Backend() *backend.Backend {
 	return backend.FromContext(c.ctx)
 }
 
+// AcquireWorker waits for a worker to run an expensive git query on repo. It
+// returns a function that releases the worker once the query is done.
+func (c *Common) AcquireWorker(repo string) (func(), error) {
+	return c.Backend().AcquireWorker(c.ctx, repo)
+}
+
+// TimeFormat returns the user's date and time formatting preferences.
+func (c *Common) TimeFormat() proto.TimeFormat {
+	v := c.ctx.Value(TimeFormatKey)
+	if tf, ok := v.(proto.TimeFormat); ok {
+		return tf
+	}
+	return proto.TimeFormat{}
+}
+
 // Repo returns the repository.
 func (c *Common) Repo() *git.Repository {
 	v := c.ctx.Value(RepoKey)
@@ -87,6 +115,17 @@ func (c *Common) Repo() *git.Repository {
 	return nil
 }
 
+// AccessLevel returns the access level of the session for a repository.
+// Sessions without a user, i.e. deploy keys, get the access of their public
+// key.
+func (c *Common) AccessLevel(repo string) access.AccessLevel {
+	be := c.Backend()
+	if user := proto.UserFromContext(c.ctx); user != nil {
+		return be.AccessLevelForUser(c.ctx, repo, user)
+	}
+	return be.AccessLevelByPublicKey(c.ctx, repo, c.PublicKey())
+}
+
 // PublicKey returns the public key.
 func (c *Common) PublicKey() ssh.PublicKey {
 	v := c.ctx.Value(ssh.ContextKeyPublicKey)
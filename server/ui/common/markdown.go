@@ -0,0 +1,69 @@
+package common
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// MaxMarkdownWidth is the maximum width markdown is wrapped at.
+const MaxMarkdownWidth = 120
+
+// RenderMarkdown renders markdown to ANSI using the TUI style. The width is
+// capped at MaxMarkdownWidth.
+func RenderMarkdown(md string, width int) (string, error) {
+	if width > MaxMarkdownWidth {
+		width = MaxMarkdownWidth
+	}
+
+	tr, err := glamour.NewTermRenderer(
+		glamour.WithStyles(StyleConfig()),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return tr.Render(md)
+}
+
+// markdownHTML parses markdown with the same extensions as glamour.
+var markdownHTML = goldmark.New(
+	goldmark.WithExtensions(
+		extension.GFM,
+		extension.DefinitionList,
+	),
+	goldmark.WithParserOptions(
+		parser.WithAutoHeadingID(),
+	),
+	// Raw HTML is kept and sanitized afterwards.
+	goldmark.WithRendererOptions(
+		html.WithUnsafe(),
+	),
+)
+
+// RenderMarkdownHTML renders markdown to sanitized HTML. It's parsed the
+// same way RenderMarkdown parses it.
+func RenderMarkdownHTML(md string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownHTML.Convert([]byte(md), &buf); err != nil {
+		return "", err
+	}
+
+	return markdownPolicy.Sanitize(buf.String()), nil
+}
+
+// markdownPolicy allows user generated content and the task list
+// checkboxes of GFM.
+var markdownPolicy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}()
@@ -3,6 +3,7 @@ package common
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/charmbracelet/soft-serve/server/utils"
 	"github.com/muesli/reflow/truncate"
@@ -34,7 +35,37 @@ func RepoURL(publicURL, name string) string {
 	return fmt.Sprintf("%s/%s", publicURL, name)
 }
 
+// PatchURL returns the HTTP URL of the patch of a commit.
+func PatchURL(publicURL, name, hash string) string {
+	return fmt.Sprintf("%s/%s/commit/%s.patch", publicURL, utils.SanitizeRepo(name), hash)
+}
+
 // CloneCmd returns the URL of the repository.
 func CloneCmd(publicURL, name string) string {
 	return fmt.Sprintf("git clone %s", RepoURL(publicURL, name))
 }
+
+// JoinCmd returns the command to join a shared session.
+func JoinCmd(publicURL, code string) string {
+	return SSHCmd(publicURL, true, "join", code)
+}
+
+// SSHCmd returns the ssh command running a command on the server. With tty,
+// it allocates a terminal, which opens the TUI when there are no other
+// arguments than a repository.
+func SSHCmd(publicURL string, tty bool, args ...string) string {
+	parts := []string{"ssh"}
+	if tty {
+		parts = append(parts, "-t")
+	}
+
+	url, err := url.Parse(publicURL)
+	if err == nil && url.Hostname() != "" {
+		if port := url.Port(); port != "" && port != "22" {
+			parts = append(parts, "-p", port)
+		}
+		parts = append(parts, url.Hostname())
+	}
+
+	return strings.Join(append(parts, args...), " ")
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/alecthomas/chroma/lexers"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/glamour"
 	gansi "github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
@@ -32,7 +31,6 @@ type Code struct {
 	extension      string
 	renderContext  gansi.RenderContext
 	renderMutex    sync.Mutex
-	styleConfig    gansi.StyleConfig
 	showLineNumber bool
 
 	NoContentStyle lipgloss.Style
@@ -52,7 +50,6 @@ func New(c common.Common, content, extension string) *Code {
 		LineBarStyle:   lineBarStyle,
 	}
 	st := common.StyleConfig()
-	r.styleConfig = st
 	r.renderContext = gansi.NewRenderContext(gansi.Options{
 		ColorProfile: termenv.TrueColor,
 		Styles:       st,
@@ -164,22 +161,7 @@ func (r *Code) ScrollPercent() float64 {
 func (r *Code) glamourize(w int, md string) (string, error) {
 	r.renderMutex.Lock()
 	defer r.renderMutex.Unlock()
-	if w > 120 {
-		w = 120
-	}
-	tr, err := glamour.NewTermRenderer(
-		glamour.WithStyles(r.styleConfig),
-		glamour.WithWordWrap(w),
-	)
-
-	if err != nil {
-		return "", err
-	}
-	mdt, err := tr.Render(md)
-	if err != nil {
-		return "", err
-	}
-	return mdt, nil
+	return common.RenderMarkdown(md, w)
 }
 
 func (r *Code) renderFile(path, content string, width int) (string, error) {
@@ -0,0 +1,286 @@
+package create
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/soft-serve/server/initfiles"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+)
+
+// maxWidth is the maximum width of the dialog.
+const maxWidth = 60
+
+// CreateMsg is sent when the user submits the dialog.
+type CreateMsg struct {
+	// Name is the name of the new repository.
+	Name string
+	// Template is the template the repository is created from, if any.
+	Template string
+	// Readme, Gitignore, and License are the initial files of the
+	// repository.
+	Readme    bool
+	Gitignore string
+	License   string
+}
+
+// CloseMsg is sent when the dialog is dismissed.
+type CloseMsg struct{}
+
+type field int
+
+const (
+	nameField field = iota
+	templateField
+	readmeField
+	gitignoreField
+	licenseField
+	lastField
+)
+
+// choice is a field with a value picked from its options.
+type choice struct {
+	label   string
+	options []string
+	index   int
+}
+
+// value returns the picked option, empty for the first option, which is
+// none.
+func (c *choice) value() string {
+	if c.index == 0 {
+		return ""
+	}
+	return c.options[c.index]
+}
+
+// Create is an overlay to create a repository, optionally from one of the
+// template repositories, and with initial files.
+type Create struct {
+	common  common.Common
+	input   textinput.Model
+	choices [lastField]*choice
+	focus   field
+	err     error
+	keymap  keymap
+}
+
+type keymap struct {
+	Next   key.Binding
+	Prev   key.Binding
+	Change key.Binding
+	Create key.Binding
+	Close  key.Binding
+}
+
+// New returns a new Create dialog.
+func New(c common.Common) *Create {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "Repository name"
+	cr := &Create{
+		common: c,
+		input:  input,
+		keymap: keymap{
+			Next: key.NewBinding(
+				key.WithKeys("tab", "down"),
+				key.WithHelp("tab", "next field"),
+			),
+			Prev: key.NewBinding(
+				key.WithKeys("shift+tab", "up"),
+				key.WithHelp("shift+tab", "previous field"),
+			),
+			Change: key.NewBinding(
+				key.WithKeys("left", "right", " "),
+				key.WithHelp("←/→", "change"),
+			),
+			Create: key.NewBinding(
+				key.WithKeys("enter"),
+				key.WithHelp("enter", "create"),
+			),
+			Close: key.NewBinding(
+				key.WithKeys("esc"),
+				key.WithHelp("esc", "close"),
+			),
+		},
+	}
+	opts := func(names []string) []string {
+		return append([]string{"None"}, names...)
+	}
+	cr.choices[readmeField] = &choice{label: "README", options: []string{"No", "Yes"}}
+	cr.choices[gitignoreField] = &choice{label: ".gitignore", options: opts(initfiles.Gitignores())}
+	cr.choices[licenseField] = &choice{label: "License", options: opts(initfiles.Licenses())}
+	cr.SetSize(c.Width, c.Height)
+	return cr
+}
+
+// SetSize implements common.Component.
+func (cr *Create) SetSize(width, height int) {
+	cr.common.SetSize(width, height)
+	cr.input.Width = cr.width() - lipgloss.Width(cr.input.Prompt) - 1
+}
+
+// width returns the width of the dialog content.
+func (cr *Create) width() int {
+	w := cr.common.Width - cr.common.Styles.Switcher.Base.GetHorizontalFrameSize()
+	if w > maxWidth {
+		w = maxWidth
+	}
+	return w
+}
+
+// SetTemplates resets the dialog to create a repository from no template or
+// from one of templates. The template field is only shown if there are
+// templates.
+func (cr *Create) SetTemplates(templates []string) tea.Cmd {
+	cr.choices[templateField] = nil
+	if len(templates) > 0 {
+		cr.choices[templateField] = &choice{label: "Template", options: append([]string{"None"}, templates...)}
+	}
+	for _, c := range cr.choices {
+		if c != nil {
+			c.index = 0
+		}
+	}
+	cr.err = nil
+	cr.input.Reset()
+	return cr.setFocus(nameField)
+}
+
+// SetError shows the error of the last submission.
+func (cr *Create) SetError(err error) tea.Cmd {
+	cr.err = err
+	return cr.setFocus(cr.focus)
+}
+
+func (cr *Create) setFocus(f field) tea.Cmd {
+	cr.focus = f
+	if f == nameField {
+		return cr.input.Focus()
+	}
+	cr.input.Blur()
+	return nil
+}
+
+// move moves the focus to the next shown field in the direction dir.
+func (cr *Create) move(dir int) tea.Cmd {
+	f := cr.focus
+	for {
+		f = (f + field(dir) + lastField) % lastField
+		if f == nameField || cr.choices[f] != nil {
+			return cr.setFocus(f)
+		}
+	}
+}
+
+// ShortHelp implements help.KeyMap.
+func (cr *Create) ShortHelp() []key.Binding {
+	return []key.Binding{
+		cr.keymap.Next,
+		cr.keymap.Change,
+		cr.keymap.Create,
+		cr.keymap.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (cr *Create) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{cr.keymap.Next, cr.keymap.Prev, cr.keymap.Change},
+		{cr.keymap.Create, cr.keymap.Close},
+	}
+}
+
+// Init implements tea.Model.
+func (cr *Create) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (cr *Create) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, cr.keymap.Close):
+			cr.input.Blur()
+			return cr, func() tea.Msg { return CloseMsg{} }
+		case key.Matches(msg, cr.keymap.Create):
+			name := strings.TrimSpace(cr.input.Value())
+			if name == "" {
+				return cr, cr.setFocus(nameField)
+			}
+			cr.input.Blur()
+			m := CreateMsg{
+				Name:      name,
+				Readme:    cr.choices[readmeField].index == 1,
+				Gitignore: cr.choices[gitignoreField].value(),
+				License:   cr.choices[licenseField].value(),
+			}
+			if c := cr.choices[templateField]; c != nil {
+				m.Template = c.value()
+			}
+			return cr, func() tea.Msg { return m }
+		case key.Matches(msg, cr.keymap.Next):
+			return cr, cr.move(1)
+		case key.Matches(msg, cr.keymap.Prev):
+			return cr, cr.move(-1)
+		case cr.focus != nameField && key.Matches(msg, cr.keymap.Change):
+			c := cr.choices[cr.focus]
+			if msg.String() == "left" {
+				c.index = (c.index + len(c.options) - 1) % len(c.options)
+			} else {
+				c.index = (c.index + 1) % len(c.options)
+			}
+			return cr, nil
+		}
+	}
+
+	if cr.focus != nameField {
+		return cr, nil
+	}
+
+	var cmd tea.Cmd
+	cr.input, cmd = cr.input.Update(msg)
+	return cr, cmd
+}
+
+// View implements tea.Model.
+func (cr *Create) View() string {
+	st := cr.common.Styles.Switcher
+	width := cr.width()
+	lines := []string{
+		st.Title.Render("New repository"),
+		cr.input.View(),
+		"",
+	}
+
+	labelWidth := 0
+	for _, c := range cr.choices {
+		if c != nil && lipgloss.Width(c.label) > labelWidth {
+			labelWidth = lipgloss.Width(c.label)
+		}
+	}
+	for f, c := range cr.choices {
+		if c == nil {
+			continue
+		}
+		style := st.Item
+		if field(f) == cr.focus {
+			style = st.ActiveItem
+		}
+		label := st.Label.Copy().Width(labelWidth + 1).Render(c.label)
+		value := common.TruncateString("‹ "+c.options[c.index]+" ›",
+			width-style.GetHorizontalFrameSize()-labelWidth-1)
+		lines = append(lines, style.Render(label+value))
+	}
+
+	if cr.err != nil {
+		lines = append(lines, st.Error.Copy().Width(width).Render(cr.err.Error()))
+	}
+
+	return st.Base.Copy().
+		Width(width + st.Base.GetHorizontalPadding()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -1,6 +1,9 @@
 package statusbar
 
 import (
+	"fmt"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
@@ -22,6 +25,8 @@ type StatusBar struct {
 	value  string
 	info   string
 	extra  string
+	// viewers are the other users viewing the same repository or commit.
+	viewers []string
 }
 
 // Model is an interface that supports setting the status bar information.
@@ -44,6 +49,11 @@ func (s *StatusBar) SetSize(width, height int) {
 	s.common.Height = height
 }
 
+// SetViewers sets the other users viewing the same repository or commit.
+func (s *StatusBar) SetViewers(viewers []string) {
+	s.viewers = viewers
+}
+
 // Init implements tea.Model.
 func (s *StatusBar) Init() tea.Cmd {
 	return nil
@@ -82,8 +92,12 @@ func (s *StatusBar) View() string {
 	if s.info != "" {
 		info = st.StatusBarInfo.Render(s.info)
 	}
+	viewers := ""
+	if len(s.viewers) > 0 {
+		viewers = st.StatusBarViewers.Render(viewersString(s.viewers))
+	}
 	branch := st.StatusBarBranch.Render(s.extra)
-	maxWidth := s.common.Width - w(key) - w(info) - w(branch) - w(help)
+	maxWidth := s.common.Width - w(key) - w(info) - w(viewers) - w(branch) - w(help)
 	v := truncate.StringWithTail(s.value, uint(maxWidth-st.StatusBarValue.GetHorizontalFrameSize()), "…")
 	value := st.StatusBarValue.
 		Width(maxWidth).
@@ -95,8 +109,19 @@ func (s *StatusBar) View() string {
 				key,
 				value,
 				info,
+				viewers,
 				branch,
 				help,
 			),
 		)
 }
+
+// viewersString returns a short description of viewers. Only the first two
+// usernames are listed to keep the status bar readable.
+func viewersString(viewers []string) string {
+	const max = 2
+	if len(viewers) <= max {
+		return "◉ " + strings.Join(viewers, ", ")
+	}
+	return fmt.Sprintf("◉ %s +%d", strings.Join(viewers[:max], ", "), len(viewers)-max)
+}
@@ -0,0 +1,209 @@
+package switcher
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+)
+
+const (
+	// maxWidth is the maximum width of the switcher.
+	maxWidth = 60
+	// maxResults is the maximum number of repositories shown.
+	maxResults = 10
+)
+
+// SelectMsg is sent when a repository is picked.
+type SelectMsg string
+
+// CloseMsg is sent when the switcher is dismissed.
+type CloseMsg struct{}
+
+// Switcher is an overlay to switch between repositories. Repositories are
+// fuzzy matched against the query.
+type Switcher struct {
+	common  common.Common
+	input   textinput.Model
+	repos   []string
+	matches []list.Rank
+	index   int
+	keymap  keymap
+}
+
+type keymap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Close  key.Binding
+}
+
+// New returns a new Switcher.
+func New(c common.Common) *Switcher {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "Repository name"
+	s := &Switcher{
+		common: c,
+		input:  input,
+		keymap: keymap{
+			Up: key.NewBinding(
+				key.WithKeys("up", "ctrl+k"),
+				key.WithHelp("↑", "up"),
+			),
+			Down: key.NewBinding(
+				key.WithKeys("down", "ctrl+j", "tab"),
+				key.WithHelp("↓", "down"),
+			),
+			Select: key.NewBinding(
+				key.WithKeys("enter"),
+				key.WithHelp("enter", "switch"),
+			),
+			Close: key.NewBinding(
+				key.WithKeys("esc", "ctrl+p"),
+				key.WithHelp("esc", "close"),
+			),
+		},
+	}
+	s.SetSize(c.Width, c.Height)
+	return s
+}
+
+// SetSize implements common.Component.
+func (s *Switcher) SetSize(width, height int) {
+	s.common.SetSize(width, height)
+	s.input.Width = s.width() - lipgloss.Width(s.input.Prompt) - 1
+}
+
+// width returns the width of the switcher content.
+func (s *Switcher) width() int {
+	w := s.common.Width - s.common.Styles.Switcher.Base.GetHorizontalFrameSize()
+	if w > maxWidth {
+		w = maxWidth
+	}
+	return w
+}
+
+// SetRepos resets the switcher to pick one of repos.
+func (s *Switcher) SetRepos(repos []string) tea.Cmd {
+	s.repos = repos
+	s.input.Reset()
+	s.filter()
+	return s.input.Focus()
+}
+
+// ShortHelp implements help.KeyMap.
+func (s *Switcher) ShortHelp() []key.Binding {
+	return []key.Binding{
+		s.keymap.Up,
+		s.keymap.Down,
+		s.keymap.Select,
+		s.keymap.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (s *Switcher) FullHelp() [][]key.Binding {
+	return [][]key.Binding{s.ShortHelp()}
+}
+
+// Init implements tea.Model.
+func (s *Switcher) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (s *Switcher) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, s.keymap.Close):
+			s.input.Blur()
+			return s, func() tea.Msg { return CloseMsg{} }
+		case key.Matches(msg, s.keymap.Select):
+			if len(s.matches) == 0 {
+				return s, nil
+			}
+			s.input.Blur()
+			repo := s.repos[s.matches[s.index].Index]
+			return s, func() tea.Msg { return SelectMsg(repo) }
+		case key.Matches(msg, s.keymap.Up):
+			if s.index > 0 {
+				s.index--
+			}
+			return s, nil
+		case key.Matches(msg, s.keymap.Down):
+			if s.index < len(s.matches)-1 {
+				s.index++
+			}
+			return s, nil
+		}
+	}
+
+	query := s.input.Value()
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	if s.input.Value() != query {
+		s.filter()
+	}
+
+	return s, cmd
+}
+
+// filter matches the repositories against the query.
+func (s *Switcher) filter() {
+	s.index = 0
+	query := strings.TrimSpace(s.input.Value())
+	if query == "" {
+		s.matches = make([]list.Rank, len(s.repos))
+		for i := range s.repos {
+			s.matches[i] = list.Rank{Index: i}
+		}
+		return
+	}
+	s.matches = list.DefaultFilter(query, s.repos)
+}
+
+// View implements tea.Model.
+func (s *Switcher) View() string {
+	st := s.common.Styles.Switcher
+	width := s.width()
+	lines := []string{
+		st.Title.Render("Switch repository"),
+		s.input.View(),
+		"",
+	}
+
+	matches := s.matches
+	// Keep the active repository in view.
+	offset := 0
+	if s.index >= maxResults {
+		offset = s.index - maxResults + 1
+	}
+	if len(matches) > offset+maxResults {
+		matches = matches[offset : offset+maxResults]
+	} else {
+		matches = matches[offset:]
+	}
+
+	for i, m := range matches {
+		style := st.Item
+		if i+offset == s.index {
+			style = st.ActiveItem
+		}
+		name := common.TruncateString(s.repos[m.Index], width-style.GetHorizontalFrameSize())
+		unmatched := style.Copy().Inline(true)
+		name = lipgloss.StyleRunes(name, m.MatchedIndexes, unmatched.Copy().Underline(true), unmatched)
+		lines = append(lines, style.Render(name))
+	}
+	if len(s.matches) == 0 {
+		lines = append(lines, st.NoItems.Render("No matching repositories"))
+	}
+
+	return st.Base.Copy().
+		Width(width + st.Base.GetHorizontalPadding()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
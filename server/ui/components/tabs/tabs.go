@@ -39,6 +39,12 @@ func New(c common.Common, tabs []string) *Tabs {
 	return r
 }
 
+// SetTabs sets the tabs and selects the first one.
+func (t *Tabs) SetTabs(tabs []string) {
+	t.tabs = tabs
+	t.activeTab = 0
+}
+
 // SetSize implements common.Component.
 func (t *Tabs) SetSize(width, height int) {
 	t.common.SetSize(width, height)
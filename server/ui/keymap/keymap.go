@@ -22,7 +22,20 @@ type KeyMap struct {
 	SelectItem key.Binding
 	BackItem   key.Binding
 
-	Copy key.Binding
+	Copy      key.Binding
+	CopyPatch key.Binding
+
+	Share key.Binding
+
+	MarkRead key.Binding
+
+	Switch key.Binding
+
+	New key.Binding
+
+	Dismiss key.Binding
+
+	OpenLink key.Binding
 }
 
 // DefaultKeyMap returns the default key map.
@@ -226,5 +239,75 @@ func DefaultKeyMap() *KeyMap {
 		),
 	)
 
+	km.CopyPatch = key.NewBinding(
+		key.WithKeys(
+			"p",
+		),
+		key.WithHelp(
+			"p",
+			"copy patch url",
+		),
+	)
+
+	km.Share = key.NewBinding(
+		key.WithKeys(
+			"S",
+		),
+		key.WithHelp(
+			"S",
+			"share session",
+		),
+	)
+
+	km.MarkRead = key.NewBinding(
+		key.WithKeys(
+			"m",
+		),
+		key.WithHelp(
+			"m",
+			"mark all read",
+		),
+	)
+
+	km.Switch = key.NewBinding(
+		key.WithKeys(
+			"ctrl+p",
+		),
+		key.WithHelp(
+			"ctrl+p",
+			"switch repo",
+		),
+	)
+
+	km.New = key.NewBinding(
+		key.WithKeys(
+			"n",
+		),
+		key.WithHelp(
+			"n",
+			"new repo",
+		),
+	)
+
+	km.Dismiss = key.NewBinding(
+		key.WithKeys(
+			"x",
+		),
+		key.WithHelp(
+			"x",
+			"dismiss message",
+		),
+	)
+
+	km.OpenLink = key.NewBinding(
+		key.WithKeys(
+			"1", "2", "3", "4", "5", "6", "7", "8", "9",
+		),
+		key.WithHelp(
+			"1-9",
+			"open repo",
+		),
+	)
+
 	return km
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/charmbracelet/soft-serve/server/ui/common"
 	"github.com/charmbracelet/soft-serve/server/ui/components/code"
 	"github.com/charmbracelet/soft-serve/server/ui/components/selector"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type filesView int
@@ -333,21 +334,13 @@ func (f *Files) updateFilesCmd() tea.Msg {
 	if f.ref == nil {
 		return nil
 	}
-	r, err := f.repo.Open()
-	if err != nil {
-		return common.ErrorMsg(err)
-	}
-	t, err := r.TreePath(f.ref, f.path)
-	if err != nil {
-		log.Printf("ui: files: error getting tree %v", err)
-		return common.ErrorMsg(err)
-	}
-	ents, err := t.Entries()
+	ctx, span := f.common.StartSpan("files", attribute.String("repo", f.repo.Name()))
+	defer span.End()
+	ents, err := f.common.Backend().TreeEntries(ctx, f.repo, f.ref, f.path)
 	if err != nil {
 		log.Printf("ui: files: error listing files %v", err)
 		return common.ErrorMsg(err)
 	}
-	ents.Sort()
 	for _, e := range ents {
 		if e.IsTree() {
 			dirs = append(dirs, FileItem{entry: e})
@@ -441,6 +434,21 @@ func (f *Files) deselectItemCmd() tea.Msg {
 	return msg
 }
 
+// Position returns the index of the selected item in the root tree.
+func (f *Files) Position() int {
+	if f.path != "" && len(f.lastSelected) > 0 {
+		return f.lastSelected[0]
+	}
+	return f.selector.Index()
+}
+
+// SetPosition selects the item at the given index.
+func (f *Files) SetPosition(i int) {
+	if i >= 0 && i < len(f.selector.Items()) {
+		f.selector.Select(i)
+	}
+}
+
 func (f *Files) setItems(items []selector.IdentifiableItem) tea.Cmd {
 	return func() tea.Msg {
 		return FileItemsMsg(items)
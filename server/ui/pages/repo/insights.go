@@ -0,0 +1,199 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/charmbracelet/soft-serve/server/ui/components/code"
+	"github.com/dustin/go-humanize"
+)
+
+// insightsTrafficDays is the number of days of traffic shown.
+const insightsTrafficDays = 14
+
+// InsightsMsg is a message sent when the repository insights are loaded.
+type InsightsMsg struct {
+	Msg tea.Msg
+}
+
+// Insights is the repository insights component page.
+type Insights struct {
+	common common.Common
+	code   *code.Code
+	repo   proto.Repository
+}
+
+// NewInsights creates a new insights model.
+func NewInsights(common common.Common) *Insights {
+	c := code.New(common, "", "")
+	c.NoContentStyle = c.NoContentStyle.Copy().SetString("No insights found.")
+	return &Insights{
+		code:   c,
+		common: common,
+	}
+}
+
+// SetSize implements common.Component.
+func (s *Insights) SetSize(width, height int) {
+	s.common.SetSize(width, height)
+	s.code.SetSize(width, height)
+}
+
+// ShortHelp implements help.KeyMap.
+func (s *Insights) ShortHelp() []key.Binding {
+	b := []key.Binding{
+		s.common.KeyMap.UpDown,
+	}
+	return b
+}
+
+// FullHelp implements help.KeyMap.
+func (s *Insights) FullHelp() [][]key.Binding {
+	k := s.code.KeyMap
+	b := [][]key.Binding{
+		{
+			k.PageDown,
+			k.PageUp,
+			k.HalfPageDown,
+			k.HalfPageUp,
+		},
+		{
+			k.Down,
+			k.Up,
+			s.common.KeyMap.GotoTop,
+			s.common.KeyMap.GotoBottom,
+		},
+	}
+	return b
+}
+
+// Init implements tea.Model.
+func (s *Insights) Init() tea.Cmd {
+	return s.updateInsightsCmd
+}
+
+// Update implements tea.Model.
+func (s *Insights) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+	switch msg := msg.(type) {
+	case RepoMsg:
+		s.repo = msg
+	case RefMsg, EmptyRepoMsg:
+		cmds = append(cmds, s.Init())
+	}
+	c, cmd := s.code.Update(msg)
+	s.code = c.(*code.Code)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	return s, tea.Batch(cmds...)
+}
+
+// View implements tea.Model.
+func (s *Insights) View() string {
+	return s.code.View()
+}
+
+// StatusBarValue implements statusbar.StatusBar.
+func (s *Insights) StatusBarValue() string {
+	return ""
+}
+
+// StatusBarInfo implements statusbar.StatusBar.
+func (s *Insights) StatusBarInfo() string {
+	return fmt.Sprintf("☰ %.f%%", s.code.ScrollPercent()*100)
+}
+
+func (s *Insights) updateInsightsCmd() tea.Msg {
+	m := InsightsMsg{}
+	if s.repo == nil {
+		return common.ErrorCmd(common.ErrMissingRepo)
+	}
+
+	var traffic *proto.Traffic
+	if s.common.AccessLevel(s.repo.Name()) >= access.ReadWriteAccess {
+		t, err := s.common.Backend().Traffic(s.common.Context(), s.repo.Name(), insightsTrafficDays)
+		if err != nil {
+			s.common.Logger.Debugf("ui: failed to get traffic: %v", err)
+		} else {
+			traffic = &t
+		}
+	}
+	var usage *proto.DiskUsage
+	u, err := s.common.Backend().RepoDiskUsage(s.common.Context(), s.repo.Name())
+	if err != nil {
+		s.common.Logger.Debugf("ui: failed to get disk usage: %v", err)
+	} else if !u.UpdatedAt.IsZero() {
+		usage = &u
+	}
+	blobs, err := s.common.Backend().RepoLargeBlobs(s.common.Context(), s.repo.Name())
+	if err != nil {
+		s.common.Logger.Debugf("ui: failed to get largest blobs: %v", err)
+	}
+	s.code.GotoTop()
+	cmd := s.code.SetContent(insightsMarkdown(usage, blobs, traffic), ".md")
+	if cmd != nil {
+		m.Msg = cmd()
+	}
+	return m
+}
+
+func insightsMarkdown(u *proto.DiskUsage, blobs []proto.LargeBlob, t *proto.Traffic) string {
+	var sb strings.Builder
+	sb.WriteString("# Insights\n\n")
+
+	sb.WriteString("## Size\n\n")
+	if u == nil {
+		sb.WriteString("The repository wasn't sized yet.\n\n")
+	} else {
+		fmt.Fprintf(&sb, "- Disk usage: %s\n", humanize.IBytes(uint64(u.Total())))
+		fmt.Fprintf(&sb, "- Objects: %d\n", u.ObjectCount)
+		fmt.Fprintf(&sb, "- Pack size: %s\n", humanize.IBytes(uint64(u.PackSize)))
+		fmt.Fprintf(&sb, "- LFS size: %s\n\n", humanize.IBytes(uint64(u.LFS)))
+	}
+	if len(blobs) > 0 {
+		sb.WriteString("| Largest blobs | Size |\n")
+		sb.WriteString("| --- | --- |\n")
+		for _, b := range blobs {
+			path := b.Path
+			if path == "" {
+				path = b.Oid[:7]
+			}
+			fmt.Fprintf(&sb, "| %s | %s |\n", path, humanize.IBytes(uint64(b.Size)))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Traffic\n\n")
+	if t == nil {
+		sb.WriteString("Traffic is only visible to collaborators.\n")
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "Last %d days:\n\n", len(t.Days))
+	fmt.Fprintf(&sb, "- Clones: %d\n", t.Clones)
+	fmt.Fprintf(&sb, "- Fetches: %d\n", t.Fetches)
+	fmt.Fprintf(&sb, "- Pushes: %d\n", t.Pushes)
+	fmt.Fprintf(&sb, "- Unique clients: ~%d\n\n", t.Clients)
+
+	sb.WriteString("| Date | Clones | Fetches | Pushes | Clients |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+	for i := len(t.Days) - 1; i >= 0; i-- {
+		d := t.Days[i]
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d |\n",
+			d.Day.Format("2006-01-02"),
+			d.Clones,
+			d.Fetches,
+			d.Pushes,
+			d.Clients,
+		)
+	}
+
+	sb.WriteString("\nShow more days with `repo stats`.\n")
+	return sb.String()
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/charmbracelet/soft-serve/server/ui/components/viewport"
 	"github.com/muesli/reflow/wrap"
 	"github.com/muesli/termenv"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var waitBeforeLoading = time.Millisecond * 100
@@ -29,11 +30,16 @@ const (
 	logViewDiff
 )
 
-// LogCountMsg is a message that contains the number of commits in a repo.
-type LogCountMsg int64
+// minLogPage is the minimum number of commits loaded at once.
+const minLogPage = 20
 
-// LogItemsMsg is a message that contains a slice of LogItem.
-type LogItemsMsg []selector.IdentifiableItem
+// LogItemsMsg is a message that contains a page of LogItem, and the cursor of
+// the next page.
+type LogItemsMsg struct {
+	ref    *git.Reference
+	items  []selector.IdentifiableItem
+	cursor []string
+}
 
 // LogCommitMsg is a message that contains a git commit.
 type LogCommitMsg *git.Commit
@@ -41,6 +47,10 @@ type LogCommitMsg *git.Commit
 // LogDiffMsg is a message that contains a git diff.
 type LogDiffMsg *git.Diff
 
+// LogMarkReadMsg is a message to indicate that all the commits were marked as
+// read.
+type LogMarkReadMsg struct{}
+
 // Log is a model that displays a list of commits and their diffs.
 type Log struct {
 	common         common.Common
@@ -49,14 +59,17 @@ type Log struct {
 	activeView     logView
 	repo           proto.Repository
 	ref            *git.Reference
-	count          int64
-	nextPage       int
 	activeCommit   *git.Commit
 	selectedCommit *git.Commit
 	currentDiff    *git.Diff
 	loadingTime    time.Time
 	loading        bool
 	spinner        spinner.Model
+	// cursor is where the next page of commits starts, it's empty once all
+	// the commits are loaded.
+	cursor   []string
+	fetching bool
+	seen     map[string]bool
 }
 
 // NewLog creates a new Log model.
@@ -100,11 +113,13 @@ func (l *Log) ShortHelp() []key.Binding {
 			l.common.KeyMap.UpDown,
 			l.common.KeyMap.SelectItem,
 			copyKey,
+			l.common.KeyMap.CopyPatch,
 		}
 	case logViewDiff:
 		return []key.Binding{
 			l.common.KeyMap.UpDown,
 			l.common.KeyMap.BackItem,
+			l.common.KeyMap.CopyPatch,
 			l.common.KeyMap.GotoTop,
 			l.common.KeyMap.GotoBottom,
 		}
@@ -124,10 +139,12 @@ func (l *Log) FullHelp() [][]key.Binding {
 		b = append(b, []key.Binding{
 			l.common.KeyMap.SelectItem,
 			l.common.KeyMap.BackItem,
+			l.common.KeyMap.MarkRead,
 		})
 		b = append(b, [][]key.Binding{
 			{
 				copyKey,
+				l.common.KeyMap.CopyPatch,
 				k.CursorUp,
 				k.CursorDown,
 			},
@@ -142,6 +159,7 @@ func (l *Log) FullHelp() [][]key.Binding {
 		k := l.vp.KeyMap
 		b = append(b, []key.Binding{
 			l.common.KeyMap.BackItem,
+			l.common.KeyMap.CopyPatch,
 		})
 		b = append(b, [][]key.Binding{
 			{
@@ -175,16 +193,31 @@ func (l *Log) stopLoading() tea.Cmd {
 // Init implements tea.Model.
 func (l *Log) Init() tea.Cmd {
 	l.activeView = logViewCommits
-	l.nextPage = 0
-	l.count = 0
 	l.activeCommit = nil
 	l.selectedCommit = nil
+	l.resetCommits()
+	cmds := []tea.Cmd{l.selector.SetItems([]selector.IdentifiableItem{})}
+	if l.ref != nil {
+		hash := l.ref.Hash.String()
+		if hash == "" {
+			hash = l.ref.Name().String()
+		}
+		l.cursor = []string{hash}
+		cmds = append(cmds,
+			l.fetchCommitsCmd(),
+			// start loading on init
+			l.startLoading(),
+		)
+	}
+	return tea.Batch(cmds...)
+}
+
+// resetCommits forgets the loaded commits.
+func (l *Log) resetCommits() {
+	l.cursor = nil
+	l.fetching = false
+	l.seen = make(map[string]bool)
 	l.selector.Select(0)
-	return tea.Batch(
-		l.updateCommitsCmd,
-		// start loading on init
-		l.startLoading(),
-	)
 }
 
 // Update implements tea.Model.
@@ -196,20 +229,36 @@ func (l *Log) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case RefMsg:
 		l.ref = msg
 		cmds = append(cmds, l.Init())
-	case LogCountMsg:
-		l.count = int64(msg)
 	case LogItemsMsg:
+		// Drop the pages of a previous reference.
+		if msg.ref != nil && msg.ref != l.ref {
+			break
+		}
+		l.fetching = false
+		l.cursor = msg.cursor
+		items := make([]selector.IdentifiableItem, 0, len(l.selector.Items())+len(msg.items))
+		if msg.ref != nil {
+			items = append(items, l.logItems()...)
+		}
+		for _, item := range msg.items {
+			// Commits with an older date than their parents can be walked
+			// twice.
+			if h := item.ID(); !l.seen[h] {
+				l.seen[h] = true
+				items = append(items, item)
+			}
+		}
 		cmds = append(cmds,
-			l.selector.SetItems(msg),
+			l.selector.SetItems(items),
 			// stop loading after receiving items
 			l.stopLoading(),
 		)
-		l.selector.SetPage(l.nextPage)
 		l.SetSize(l.common.Width, l.common.Height)
 		i := l.selector.SelectedItem()
 		if i != nil {
 			l.activeCommit = i.(LogItem).Commit
 		}
+		cmds = append(cmds, l.fetchMoreCmd())
 	case tea.KeyMsg, tea.MouseMsg:
 		switch l.activeView {
 		case logViewCommits:
@@ -218,28 +267,23 @@ func (l *Log) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				switch {
 				case key.Matches(kmsg, l.common.KeyMap.SelectItem):
 					cmds = append(cmds, l.selector.SelectItem)
+				case key.Matches(kmsg, l.common.KeyMap.MarkRead):
+					cmds = append(cmds, l.markRepoReadCmd)
+				case key.Matches(kmsg, l.common.KeyMap.CopyPatch):
+					cmds = append(cmds, l.copyPatchURLCmd(l.activeCommit))
 				}
 			}
-			// This is a hack for loading commits on demand based on list.Pagination.
-			curPage := l.selector.Page()
 			s, cmd := l.selector.Update(msg)
-			m := s.(*selector.Selector)
-			l.selector = m
-			if m.Page() != curPage {
-				l.nextPage = m.Page()
-				l.selector.SetPage(curPage)
-				cmds = append(cmds,
-					l.updateCommitsCmd,
-					l.startLoading(),
-				)
-			}
-			cmds = append(cmds, cmd)
+			l.selector = s.(*selector.Selector)
+			cmds = append(cmds, cmd, l.fetchMoreCmd())
 		case logViewDiff:
 			switch kmsg := msg.(type) {
 			case tea.KeyMsg:
 				switch {
 				case key.Matches(kmsg, l.common.KeyMap.BackItem):
 					cmds = append(cmds, backCmd)
+				case key.Matches(kmsg, l.common.KeyMap.CopyPatch):
+					cmds = append(cmds, l.copyPatchURLCmd(l.selectedCommit))
 				}
 			}
 		}
@@ -266,6 +310,18 @@ func (l *Log) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case LogCommitMsg:
 		l.selectedCommit = msg
 		cmds = append(cmds, l.loadDiffCmd)
+		if cmd := l.markCommitRead(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	case LogMarkReadMsg:
+		items := l.logItems()
+		for i, item := range items {
+			if li := item.(LogItem); li.Unread {
+				li.Unread = false
+				items[i] = li
+			}
+		}
+		cmds = append(cmds, l.selector.SetItems(items))
 	case LogDiffMsg:
 		l.currentDiff = msg
 		l.vp.SetContent(
@@ -283,7 +339,7 @@ func (l *Log) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			l.stopLoading(),
 		)
 	case footer.ToggleFooterMsg:
-		cmds = append(cmds, l.updateCommitsCmd)
+		cmds = append(cmds, l.fetchMoreCmd())
 	case tea.WindowSizeMsg:
 		if l.selectedCommit != nil && l.currentDiff != nil {
 			l.vp.SetContent(
@@ -294,23 +350,16 @@ func (l *Log) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				),
 			)
 		}
-		if l.repo != nil && l.ref != nil {
-			cmds = append(cmds,
-				l.updateCommitsCmd,
-				// start loading on resize since the number of commits per page
-				// might change and we'd need to load more commits.
-				l.startLoading(),
-			)
-		}
+		// The number of commits per page might change and we'd need to load
+		// more commits.
+		cmds = append(cmds, l.fetchMoreCmd())
 	case EmptyRepoMsg:
 		l.ref = nil
 		l.loading = false
 		l.activeView = logViewCommits
-		l.nextPage = 0
-		l.count = 0
 		l.activeCommit = nil
 		l.selectedCommit = nil
-		l.selector.Select(0)
+		l.resetCommits()
 		cmds = append(cmds, l.setItems([]selector.IdentifiableItem{}))
 	}
 	if l.loading {
@@ -370,16 +419,24 @@ func (l *Log) StatusBarValue() string {
 	if who != "" {
 		value += " by " + who
 	}
-	return value
+	tf := l.common.TimeFormat()
+	date := tf.Absolute(c.Committer.When, "Jan 02 2006")
+	if !tf.IsRelative(false) {
+		date = "on " + date
+	}
+	return value + " " + date
 }
 
 // StatusBarInfo returns the status bar info.
 func (l *Log) StatusBarInfo() string {
 	switch l.activeView {
 	case logViewCommits:
-		// We're using l.nextPage instead of l.selector.Paginator.Page because
-		// of the paginator hack above.
-		return fmt.Sprintf("p. %d/%d", l.nextPage+1, l.selector.TotalPages())
+		// The total is unknown until all the commits are loaded.
+		var more string
+		if len(l.cursor) > 0 {
+			more = "+"
+		}
+		return fmt.Sprintf("p. %d/%d%s", l.selector.Page()+1, l.selector.TotalPages(), more)
 	case logViewDiff:
 		return fmt.Sprintf("☰ %.f%%", l.vp.ScrollPercent()*100)
 	default:
@@ -387,57 +444,107 @@ func (l *Log) StatusBarInfo() string {
 	}
 }
 
-func (l *Log) countCommitsCmd() tea.Msg {
-	if l.ref == nil {
+// fetchMoreCmd returns a command that loads the next page of commits when the
+// last loaded page is shown, so that there's always a page ahead.
+func (l *Log) fetchMoreCmd() tea.Cmd {
+	if l.fetching || len(l.cursor) == 0 || l.repo == nil || l.ref == nil {
 		return nil
 	}
-	r, err := l.repo.Open()
-	if err != nil {
-		return common.ErrorMsg(err)
+	if l.selector.Page() < l.selector.TotalPages()-1 {
+		return nil
 	}
-	count, err := r.CountCommits(l.ref)
-	if err != nil {
-		l.common.Logger.Debugf("ui: error counting commits: %v", err)
-		return common.ErrorMsg(err)
+	return l.fetchCommitsCmd()
+}
+
+// fetchCommitsCmd returns a command that loads the page of commits at the
+// cursor.
+func (l *Log) fetchCommitsCmd() tea.Cmd {
+	l.fetching = true
+	be := l.common.Backend()
+	repo, ref, cursor := l.repo, l.ref, l.cursor
+	size := l.selector.PerPage() * 2
+	if size < minLogPage {
+		size = minLogPage
+	}
+	return func() tea.Msg {
+		ctx, span := l.common.StartSpan("commits", attribute.String("repo", repo.Name()))
+		defer span.End()
+		release, err := l.common.AcquireWorker(repo.Name())
+		if err != nil {
+			return common.ErrorMsg(err)
+		}
+		cc, next, err := be.CommitsFrom(ctx, repo, cursor, size)
+		release()
+		if err != nil {
+			l.common.Logger.Debugf("ui: error loading commits: %v", err)
+			return common.ErrorMsg(err)
+		}
+		markers, err := be.ReadMarkers(ctx, repo.Name(), proto.UserFromContext(ctx))
+		if err != nil {
+			l.common.Logger.Debugf("ui: error loading read markers: %v", err)
+		}
+		items := make([]selector.IdentifiableItem, len(cc))
+		for i, c := range cc {
+			items[i] = LogItem{
+				Commit: c,
+				Unread: markers.IsUnread(c.ID.String(), c.Committer.When),
+			}
+		}
+		return LogItemsMsg{ref: ref, items: items, cursor: next}
 	}
-	return LogCountMsg(count)
 }
 
-func (l *Log) updateCommitsCmd() tea.Msg {
-	count := l.count
-	if l.count == 0 {
-		switch msg := l.countCommitsCmd().(type) {
-		case common.ErrorMsg:
-			return msg
-		case LogCountMsg:
-			count = int64(msg)
+// markCommitRead clears the unread marker of a commit and returns a command
+// that persists it.
+func (l *Log) markCommitRead(c *git.Commit) tea.Cmd {
+	var cmd tea.Cmd
+	var unread bool
+	for i, item := range l.selector.Items() {
+		if li, ok := item.(LogItem); ok && li.Commit != nil && li.Unread && li.Hash() == c.ID.String() {
+			li.Unread = false
+			unread = true
+			cmd = l.selector.SetItem(i, li)
+			break
 		}
 	}
-	if l.ref == nil {
+	if !unread {
 		return nil
 	}
-	items := make([]selector.IdentifiableItem, count)
-	page := l.nextPage
-	limit := l.selector.PerPage()
-	skip := page * limit
-	r, err := l.repo.Open()
-	if err != nil {
-		return common.ErrorMsg(err)
-	}
-	// CommitsByPage pages start at 1
-	cc, err := r.CommitsByPage(l.ref, page+1, limit)
-	if err != nil {
-		l.common.Logger.Debugf("ui: error loading commits: %v", err)
-		return common.ErrorMsg(err)
+
+	// Admins impersonating a user only see the markers.
+	ctx := l.common.Context()
+	if proto.ImpersonatorFromContext(ctx) != nil {
+		return cmd
 	}
-	for i, c := range cc {
-		idx := i + skip
-		if int64(idx) >= count {
-			break
+
+	repo := l.repo.Name()
+	return tea.Batch(cmd, func() tea.Msg {
+		if err := l.common.Backend().MarkCommitRead(ctx, repo, proto.UserFromContext(ctx), c.ID.String()); err != nil {
+			l.common.Logger.Debugf("ui: error marking commit as read: %v", err)
 		}
-		items[idx] = LogItem{Commit: c}
+		return nil
+	})
+}
+
+// copyPatchURLCmd copies the URL of the patch of the commit.
+func (l *Log) copyPatchURLCmd(c *git.Commit) tea.Cmd {
+	cfg := l.common.Config()
+	if c == nil || l.repo == nil || cfg == nil {
+		return nil
 	}
-	return LogItemsMsg(items)
+	return copyCmd(common.PatchURL(cfg.HTTP.PublicURL, l.repo.Name(), c.Hash.String()), "Patch URL copied to clipboard")
+}
+
+func (l *Log) markRepoReadCmd() tea.Msg {
+	if l.repo == nil {
+		return nil
+	}
+	ctx := l.common.Context()
+	if err := l.common.Backend().MarkRepoRead(ctx, l.repo.Name(), proto.UserFromContext(ctx)); err != nil {
+		l.common.Logger.Debugf("ui: error marking repository as read: %v", err)
+		return common.ErrorMsg(err)
+	}
+	return LogMarkReadMsg{}
 }
 
 func (l *Log) selectCommitCmd(commit *git.Commit) tea.Cmd {
@@ -447,11 +554,18 @@ func (l *Log) selectCommitCmd(commit *git.Commit) tea.Cmd {
 }
 
 func (l *Log) loadDiffCmd() tea.Msg {
+	_, span := l.common.StartSpan("diff", attribute.String("repo", l.repo.Name()))
+	defer span.End()
 	r, err := l.repo.Open()
 	if err != nil {
 		l.common.Logger.Debugf("ui: error loading diff repository: %v", err)
 		return common.ErrorMsg(err)
 	}
+	release, err := l.common.AcquireWorker(l.repo.Name())
+	if err != nil {
+		return common.ErrorMsg(err)
+	}
+	defer release()
 	diff, err := r.Diff(l.selectedCommit)
 	if err != nil {
 		l.common.Logger.Debugf("ui: error loading diff: %v", err)
@@ -475,7 +589,7 @@ func (l *Log) renderCommit(c *git.Commit) string {
 	s.WriteString(fmt.Sprintf("%s\n%s\n%s\n%s\n",
 		l.common.Styles.Log.CommitHash.Render("commit "+c.ID.String()),
 		l.common.Styles.Log.CommitAuthor.Render(fmt.Sprintf("Author: %s <%s>", c.Author.Name, c.Author.Email)),
-		l.common.Styles.Log.CommitDate.Render("Date:   "+c.Committer.When.Format(time.UnixDate)),
+		l.common.Styles.Log.CommitDate.Render("Date:   "+l.common.TimeFormat().Absolute(c.Committer.When, time.UnixDate)),
 		l.common.Styles.Log.CommitBody.Render(msg),
 	))
 	return wrap.String(s.String(), l.common.Width-2)
@@ -511,8 +625,19 @@ func (l *Log) renderDiff(diff *git.Diff) string {
 	return wrap.String(s.String(), l.common.Width)
 }
 
+// logItems returns the loaded commits.
+func (l *Log) logItems() []selector.IdentifiableItem {
+	items := make([]selector.IdentifiableItem, 0, len(l.selector.Items()))
+	for _, item := range l.selector.Items() {
+		if li, ok := item.(LogItem); ok {
+			items = append(items, li)
+		}
+	}
+	return items
+}
+
 func (l *Log) setItems(items []selector.IdentifiableItem) tea.Cmd {
 	return func() tea.Msg {
-		return LogItemsMsg(items)
+		return LogItemsMsg{items: items}
 	}
 }
@@ -18,6 +18,8 @@ import (
 // LogItem is a item in the log list that displays a git commit.
 type LogItem struct {
 	*git.Commit
+	// Unread is whether the user hasn't seen the commit yet.
+	Unread bool
 }
 
 // ID implements selector.IdentifiableItem.
@@ -88,11 +90,17 @@ func (d LogItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 
 	horizontalFrameSize := styles.Base.GetHorizontalFrameSize()
 
+	var unread string
+	if i.Unread {
+		unread = d.common.Styles.LogItem.Unread.Render("● ")
+	}
+
 	hash := i.Commit.ID.String()[:7]
-	title := styles.Title.Render(
+	title := unread + styles.Title.Render(
 		common.TruncateString(i.Title(),
 			m.Width()-
 				horizontalFrameSize-
+				lipgloss.Width(unread)-
 				// 9 is the length of the hash (7) + the left padding (1) + the
 				// title truncation symbol (1)
 				9),
@@ -109,9 +117,9 @@ func (d LogItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 	hash = hashStyle.Render(hash)
 	if m.Width()-horizontalFrameSize-hashStyle.GetHorizontalFrameSize()-hashStyle.GetWidth() <= 0 {
 		hash = ""
-		title = styles.Title.Render(
+		title = unread + styles.Title.Render(
 			common.TruncateString(i.Title(),
-				m.Width()-horizontalFrameSize),
+				m.Width()-horizontalFrameSize-lipgloss.Width(unread)),
 		)
 	}
 	author := i.Author.Name
@@ -124,11 +132,16 @@ func (d LogItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 		}
 		who += " "
 	}
-	date := i.Committer.When.Format("Jan 02")
+	tf := d.common.TimeFormat()
+	layout := "Jan 02"
 	if i.Committer.When.Year() != time.Now().Year() {
-		date += fmt.Sprintf(" %d", i.Committer.When.Year())
+		layout += " 2006"
+	}
+	date := tf.Absolute(i.Committer.When, layout)
+	if !tf.IsRelative(false) {
+		who += styles.Desc.Render("on ")
 	}
-	who += styles.Desc.Render("on ") + styles.Keyword.Render(date)
+	who += styles.Keyword.Render(date)
 	who = common.TruncateString(who, m.Width()-horizontalFrameSize)
 	fmt.Fprint(w,
 		d.common.Zone.Mark(
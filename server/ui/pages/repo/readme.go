@@ -6,10 +6,10 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
 	"github.com/charmbracelet/soft-serve/server/ui/components/code"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ReadmeMsg is a message sent when the readme is loaded.
@@ -120,7 +120,9 @@ func (r *Readme) updateReadmeCmd() tea.Msg {
 	if r.repo == nil {
 		return common.ErrorCmd(common.ErrMissingRepo)
 	}
-	rm, rp, _ := backend.Readme(r.repo)
+	ctx, span := r.common.StartSpan("readme", attribute.String("repo", r.repo.Name()))
+	rm, rp, _ := r.common.Backend().Readme(ctx, r.repo)
+	span.End()
 	r.readmePath = rp
 	r.code.GotoTop()
 	cmd := r.code.SetContent(rm, rp)
@@ -12,7 +12,6 @@ import (
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
 	"github.com/charmbracelet/soft-serve/server/ui/components/selector"
-	"github.com/charmbracelet/soft-serve/server/ui/components/tabs"
 )
 
 // RefMsg is a message that contains a git.Reference.
@@ -126,7 +125,7 @@ func (r *Refs) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case RefItem:
 			cmds = append(cmds,
 				switchRefCmd(i.Reference),
-				tabs.SelectTabCmd(int(filesTab)),
+				selectTabCmd(filesTab),
 			)
 		}
 	case tea.KeyMsg:
@@ -168,6 +167,22 @@ func (r *Refs) StatusBarInfo() string {
 	return ""
 }
 
+// Position returns the index of the selected reference.
+func (r *Refs) Position() int {
+	return r.selector.Index()
+}
+
+// SetPosition selects the reference at the given index.
+func (r *Refs) SetPosition(i int) {
+	if i < 0 || i >= len(r.selector.Items()) {
+		return
+	}
+	r.selector.Select(i)
+	if ri, ok := r.selector.SelectedItem().(RefItem); ok {
+		r.activeRef = ri.Reference
+	}
+}
+
 func (r *Refs) updateItemsCmd() tea.Msg {
 	its := make(RefItems, 0)
 	rr, err := r.repo.Open()
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/charmbracelet/soft-serve/server/ui/components/code"
+	"github.com/dustin/go-humanize"
+)
+
+// ReleasesMsg is a message sent when the repository releases are loaded.
+type ReleasesMsg struct {
+	Msg tea.Msg
+}
+
+// Releases is the repository releases component page.
+type Releases struct {
+	common common.Common
+	code   *code.Code
+	repo   proto.Repository
+}
+
+// NewReleases creates a new releases model.
+func NewReleases(common common.Common) *Releases {
+	c := code.New(common, "", "")
+	c.NoContentStyle = c.NoContentStyle.Copy().SetString("No releases found.")
+	return &Releases{
+		code:   c,
+		common: common,
+	}
+}
+
+// SetSize implements common.Component.
+func (s *Releases) SetSize(width, height int) {
+	s.common.SetSize(width, height)
+	s.code.SetSize(width, height)
+}
+
+// ShortHelp implements help.KeyMap.
+func (s *Releases) ShortHelp() []key.Binding {
+	b := []key.Binding{
+		s.common.KeyMap.UpDown,
+	}
+	return b
+}
+
+// FullHelp implements help.KeyMap.
+func (s *Releases) FullHelp() [][]key.Binding {
+	k := s.code.KeyMap
+	b := [][]key.Binding{
+		{
+			k.PageDown,
+			k.PageUp,
+			k.HalfPageDown,
+			k.HalfPageUp,
+		},
+		{
+			k.Down,
+			k.Up,
+			s.common.KeyMap.GotoTop,
+			s.common.KeyMap.GotoBottom,
+		},
+	}
+	return b
+}
+
+// Init implements tea.Model.
+func (s *Releases) Init() tea.Cmd {
+	return s.updateReleasesCmd
+}
+
+// Update implements tea.Model.
+func (s *Releases) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+	switch msg := msg.(type) {
+	case RepoMsg:
+		s.repo = msg
+	case RefMsg, EmptyRepoMsg:
+		cmds = append(cmds, s.Init())
+	}
+	c, cmd := s.code.Update(msg)
+	s.code = c.(*code.Code)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	return s, tea.Batch(cmds...)
+}
+
+// View implements tea.Model.
+func (s *Releases) View() string {
+	return s.code.View()
+}
+
+// StatusBarValue implements statusbar.StatusBar.
+func (s *Releases) StatusBarValue() string {
+	return ""
+}
+
+// StatusBarInfo implements statusbar.StatusBar.
+func (s *Releases) StatusBarInfo() string {
+	return fmt.Sprintf("☰ %.f%%", s.code.ScrollPercent()*100)
+}
+
+func (s *Releases) updateReleasesCmd() tea.Msg {
+	m := ReleasesMsg{}
+	if s.repo == nil {
+		return common.ErrorCmd(common.ErrMissingRepo)
+	}
+
+	ctx := s.common.Context()
+	be := s.common.Backend()
+	rels, err := be.Releases(ctx, s.repo.Name())
+	if err != nil {
+		s.common.Logger.Debugf("ui: failed to get releases: %v", err)
+	}
+
+	// Releases are listed without their assets.
+	for i := range rels {
+		if rel, err := be.Release(ctx, s.repo.Name(), rels[i].Tag); err == nil {
+			rels[i].Assets = rel.Assets
+		}
+	}
+
+	var md string
+	if len(rels) > 0 {
+		md = releasesMarkdown(rels, func(tag, name string) string {
+			return be.ReleaseAssetURL(s.repo.Name(), tag, name)
+		})
+	}
+
+	s.code.GotoTop()
+	cmd := s.code.SetContent(md, ".md")
+	if cmd != nil {
+		m.Msg = cmd()
+	}
+	return m
+}
+
+func releasesMarkdown(rels []proto.Release, assetURL func(tag, name string) string) string {
+	var sb strings.Builder
+	sb.WriteString("# Releases\n\n")
+	for _, rel := range rels {
+		fmt.Fprintf(&sb, "## %s\n\n", rel.Title())
+		fmt.Fprintf(&sb, "`%s`", rel.Tag)
+		if rel.Username != "" {
+			fmt.Fprintf(&sb, " by %s", rel.Username)
+		}
+		fmt.Fprintf(&sb, " on %s\n\n", rel.CreatedAt.Format("2006-01-02"))
+
+		if notes := strings.TrimSpace(rel.Notes); notes != "" {
+			sb.WriteString(notes + "\n\n")
+		}
+
+		if len(rel.Assets) > 0 {
+			sb.WriteString("### Assets\n\n")
+			for _, a := range rel.Assets {
+				fmt.Fprintf(&sb, "- %s (%s)\n", a.Name, humanize.IBytes(uint64(a.Size)))
+				fmt.Fprintf(&sb, "  - sha256: `%s`\n", a.SHA256)
+				fmt.Fprintf(&sb, "  - %s\n", assetURL(rel.Tag, a.Name))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("Download assets with `repo release download`.\n")
+	return sb.String()
+}
@@ -9,6 +9,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/presence"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
 	"github.com/charmbracelet/soft-serve/server/ui/components/footer"
@@ -31,9 +32,23 @@ const (
 	commitsTab
 	branchesTab
 	tagsTab
+	releasesTab
+	insightsTab
+	settingsTab
 	lastTab
 )
 
+// tabFromProto returns the tab of a repository settings tab. The order of tab
+// constants above matches the order of proto.Tabs.
+func tabFromProto(t proto.Tab) tab {
+	for i, pt := range proto.Tabs {
+		if pt == t {
+			return tab(i)
+		}
+	}
+	return readmeTab
+}
+
 func (t tab) String() string {
 	return []string{
 		"Readme",
@@ -41,6 +56,9 @@ func (t tab) String() string {
 		"Commits",
 		"Branches",
 		"Tags",
+		"Releases",
+		"Insights",
+		"Settings",
 	}[t]
 }
 
@@ -53,6 +71,27 @@ type CopyURLMsg struct{}
 // UpdateStatusBarMsg updates the status bar.
 type UpdateStatusBarMsg struct{}
 
+// PresenceMsg is a message to indicate that other users changed their
+// location.
+type PresenceMsg struct{}
+
+// ResumeMsg is a message to restore the active tab and position of a previous
+// session.
+type ResumeMsg struct {
+	Tab      string
+	Position int
+}
+
+// positioner is implemented by panes that can save and restore the selected
+// item.
+type positioner interface {
+	Position() int
+	SetPosition(int)
+}
+
+// selectTabMsg is a message to select a tab. Hidden tabs can't be selected.
+type selectTabMsg tab
+
 // RepoMsg is a message that contains a git.Repository.
 type RepoMsg proto.Repository // nolint:revive
 
@@ -70,6 +109,7 @@ type Repo struct {
 	common       common.Common
 	selectedRepo proto.Repository
 	activeTab    tab
+	shownTabs    []tab
 	tabs         *tabs.Tabs
 	statusbar    *statusbar.StatusBar
 	panes        []common.Component
@@ -77,14 +117,17 @@ type Repo struct {
 	state        state
 	spinner      spinner.Model
 	panesReady   [lastTab]bool
+	resume       *ResumeMsg
 }
 
 // New returns a new Repo.
 func New(c common.Common) *Repo {
 	sb := statusbar.New(c)
+	shown := make([]tab, lastTab)
 	ts := make([]string, lastTab)
 	// Tabs must match the order of tab constants above.
-	for i, t := range []tab{readmeTab, filesTab, commitsTab, branchesTab, tagsTab} {
+	for i, t := range []tab{readmeTab, filesTab, commitsTab, branchesTab, tagsTab, releasesTab, insightsTab, settingsTab} {
+		shown[i] = t
 		ts[i] = t.String()
 	}
 	c.Logger = c.Logger.WithPrefix("ui.repo")
@@ -94,6 +137,9 @@ func New(c common.Common) *Repo {
 	files := NewFiles(c)
 	branches := NewRefs(c, git.RefsHeads)
 	tags := NewRefs(c, git.RefsTags)
+	releases := NewReleases(c)
+	insights := NewInsights(c)
+	settings := NewSettings(c)
 	// Make sure the order matches the order of tab constants above.
 	panes := []common.Component{
 		readme,
@@ -101,11 +147,15 @@ func New(c common.Common) *Repo {
 		log,
 		branches,
 		tags,
+		releases,
+		insights,
+		settings,
 	}
 	s := spinner.New(spinner.WithSpinner(spinner.Dot),
 		spinner.WithStyle(c.Styles.Spinner))
 	r := &Repo{
 		common:    c,
+		shownTabs: shown,
 		tabs:      tb,
 		statusbar: sb,
 		panes:     panes,
@@ -171,10 +221,11 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Set the state to loading when we get a new repository.
 		r.state = loadingState
 		r.panesReady = [lastTab]bool{}
-		r.activeTab = 0
+		r.resume = nil
 		r.selectedRepo = msg
 		cmds = append(cmds,
 			r.tabs.Init(),
+			r.setTabLayout(),
 			// This will set the selected repo in each pane's model.
 			r.updateModels(msg),
 			r.spinner.Tick,
@@ -189,15 +240,25 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			r.updateStatusBarCmd,
 			r.updateModels(msg),
 		)
+	case selectTabMsg:
+		for i, t := range r.shownTabs {
+			if t == tab(msg) {
+				cmds = append(cmds, tabs.SelectTabCmd(i))
+			}
+		}
 	case tabs.SelectTabMsg:
-		r.activeTab = tab(msg)
+		if int(msg) >= 0 && int(msg) < len(r.shownTabs) {
+			r.activeTab = r.shownTabs[msg]
+		}
 		t, cmd := r.tabs.Update(msg)
 		r.tabs = t.(*tabs.Tabs)
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
 	case tabs.ActiveTabMsg:
-		r.activeTab = tab(msg)
+		if int(msg) >= 0 && int(msg) < len(r.shownTabs) {
+			r.activeTab = r.shownTabs[msg]
+		}
 		if r.selectedRepo != nil {
 			cmds = append(cmds,
 				r.updateStatusBarCmd,
@@ -242,7 +303,7 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Value: msg.Message,
 			}
 		})
-	case ReadmeMsg, FileItemsMsg, LogCountMsg, LogItemsMsg, RefItemsMsg:
+	case ReadmeMsg, FileItemsMsg, LogItemsMsg, RefItemsMsg:
 		cmds = append(cmds, r.updateRepo(msg))
 	// We have two spinners, one is used to when loading the repository and the
 	// other is used when loading the log.
@@ -260,6 +321,11 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		default:
 			cmds = append(cmds, r.updateRepo(msg))
 		}
+	case ResumeMsg:
+		r.resume = &msg
+		if r.state == readyState {
+			cmds = append(cmds, r.resumeCmd())
+		}
 	case UpdateStatusBarMsg:
 		cmds = append(cmds, r.updateStatusBarCmd)
 	case tea.WindowSizeMsg:
@@ -270,6 +336,7 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds,
 			r.updateModels(msg),
 			r.updateStatusBarCmd,
+			r.resumeCmd(),
 		)
 	case common.ErrorMsg:
 		r.state = readyState
@@ -284,6 +351,7 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if cmd != nil {
 		cmds = append(cmds, cmd)
 	}
+	r.updatePresence()
 	return r, tea.Batch(cmds...)
 }
 
@@ -333,6 +401,9 @@ func (r *Repo) headerView() string {
 		name = r.selectedRepo.Name()
 	}
 	name = r.common.Styles.Repo.HeaderName.Render(name)
+	if r.selectedRepo.IsArchived() {
+		name += " " + r.common.Styles.ArchivedBadge.Render("archived")
+	}
 	desc := r.selectedRepo.Description()
 	if desc == "" {
 		desc = name
@@ -382,6 +453,24 @@ func (r *Repo) updateStatusBarCmd() tea.Msg {
 	}
 }
 
+// updatePresence reports the current location to the presence tracker and
+// updates the list of users viewing the same repository or commit.
+func (r *Repo) updatePresence() {
+	pc := presence.ClientFromContext(r.common.Context())
+	if pc == nil {
+		return
+	}
+	var loc presence.Location
+	if r.selectedRepo != nil {
+		loc.Repo = r.selectedRepo.Name()
+		if l, ok := r.panes[commitsTab].(*Log); ok && r.activeTab == commitsTab && l.selectedCommit != nil {
+			loc.Commit = l.selectedCommit.Hash.String()
+		}
+	}
+	pc.Move(loc)
+	r.statusbar.SetViewers(pc.Viewers(loc))
+}
+
 func (r *Repo) updateModels(msg tea.Msg) tea.Cmd {
 	cmds := make([]tea.Cmd, 0)
 	for i, b := range r.panes {
@@ -397,7 +486,7 @@ func (r *Repo) updateModels(msg tea.Msg) tea.Cmd {
 func (r *Repo) updateRepo(msg tea.Msg) tea.Cmd {
 	cmds := make([]tea.Cmd, 0)
 	switch msg := msg.(type) {
-	case LogCountMsg, LogItemsMsg, spinner.TickMsg:
+	case LogItemsMsg, spinner.TickMsg:
 		switch msg.(type) {
 		case LogItemsMsg:
 			r.panesReady[commitsTab] = true
@@ -434,12 +523,82 @@ func (r *Repo) updateRepo(msg tea.Msg) tea.Cmd {
 	case ReadmeMsg:
 		r.panesReady[readmeTab] = true
 	}
-	if r.isReady() {
+	if r.isReady() && r.state != readyState {
 		r.state = readyState
+		cmds = append(cmds, r.resumeCmd())
 	}
 	return tea.Batch(cmds...)
 }
 
+// resumeCmd restores the tab and position of a resumed session.
+func (r *Repo) resumeCmd() tea.Cmd {
+	if r.resume == nil {
+		return nil
+	}
+	rs := *r.resume
+	r.resume = nil
+	for t := readmeTab; t < lastTab; t++ {
+		if t.String() != rs.Tab {
+			continue
+		}
+		if p, ok := r.panes[t].(positioner); ok {
+			p.SetPosition(rs.Position)
+		}
+		return tea.Batch(
+			selectTabCmd(t),
+			updateStatusBarCmd,
+		)
+	}
+	return nil
+}
+
+// SessionState returns the state of the repository page to resume it in a
+// later session.
+func (r *Repo) SessionState() proto.SessionState {
+	if r.selectedRepo == nil {
+		return proto.SessionState{}
+	}
+	if r.resume != nil {
+		return proto.SessionState{
+			Repo:     r.selectedRepo.Name(),
+			Tab:      r.resume.Tab,
+			Position: r.resume.Position,
+		}
+	}
+	st := proto.SessionState{
+		Repo: r.selectedRepo.Name(),
+		Tab:  r.activeTab.String(),
+	}
+	if p, ok := r.panes[r.activeTab].(positioner); ok {
+		st.Position = p.Position()
+	}
+	return st
+}
+
+// setTabLayout shows the tabs of the selected repository and selects its
+// default tab.
+func (r *Repo) setTabLayout() tea.Cmd {
+	var l proto.TabLayout
+	if r.selectedRepo != nil {
+		var err error
+		l, err = r.common.Backend().TabLayout(r.common.Context(), r.selectedRepo.Name())
+		if err != nil {
+			r.common.Logger.Debugf("ui: failed to get tab layout: %v", err)
+		}
+	}
+
+	shown := l.Shown()
+	r.shownTabs = make([]tab, len(shown))
+	names := make([]string, len(shown))
+	for i, t := range shown {
+		r.shownTabs[i] = tabFromProto(t)
+		names[i] = r.shownTabs[i].String()
+	}
+	r.tabs.SetTabs(names)
+	r.activeTab = tabFromProto(l.DefaultTab())
+	return selectTabCmd(r.activeTab)
+}
+
 func (r *Repo) isReady() bool {
 	ready := true
 	// We purposely ignore the log pane here because it has its own spinner.
@@ -464,6 +623,12 @@ func copyCmd(text, msg string) tea.Cmd {
 	}
 }
 
+func selectTabCmd(t tab) tea.Cmd {
+	return func() tea.Msg {
+		return selectTabMsg(t)
+	}
+}
+
 func updateStatusBarCmd() tea.Msg {
 	return UpdateStatusBarMsg{}
 }
@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/charmbracelet/soft-serve/server/ui/components/code"
+	"github.com/dustin/go-humanize"
+)
+
+// SettingsMsg is a message sent when the repository settings are loaded.
+type SettingsMsg struct {
+	Msg tea.Msg
+}
+
+// Settings is the repository settings component page.
+type Settings struct {
+	common common.Common
+	code   *code.Code
+	repo   proto.Repository
+}
+
+// NewSettings creates a new settings model.
+func NewSettings(common common.Common) *Settings {
+	c := code.New(common, "", "")
+	c.NoContentStyle = c.NoContentStyle.Copy().SetString("No settings found.")
+	return &Settings{
+		code:   c,
+		common: common,
+	}
+}
+
+// SetSize implements common.Component.
+func (s *Settings) SetSize(width, height int) {
+	s.common.SetSize(width, height)
+	s.code.SetSize(width, height)
+}
+
+// ShortHelp implements help.KeyMap.
+func (s *Settings) ShortHelp() []key.Binding {
+	b := []key.Binding{
+		s.common.KeyMap.UpDown,
+	}
+	return b
+}
+
+// FullHelp implements help.KeyMap.
+func (s *Settings) FullHelp() [][]key.Binding {
+	k := s.code.KeyMap
+	b := [][]key.Binding{
+		{
+			k.PageDown,
+			k.PageUp,
+			k.HalfPageDown,
+			k.HalfPageUp,
+		},
+		{
+			k.Down,
+			k.Up,
+			s.common.KeyMap.GotoTop,
+			s.common.KeyMap.GotoBottom,
+		},
+	}
+	return b
+}
+
+// Init implements tea.Model.
+func (s *Settings) Init() tea.Cmd {
+	return s.updateSettingsCmd
+}
+
+// Update implements tea.Model.
+func (s *Settings) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+	switch msg := msg.(type) {
+	case RepoMsg:
+		s.repo = msg
+	case RefMsg, EmptyRepoMsg:
+		cmds = append(cmds, s.Init())
+	}
+	c, cmd := s.code.Update(msg)
+	s.code = c.(*code.Code)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	return s, tea.Batch(cmds...)
+}
+
+// View implements tea.Model.
+func (s *Settings) View() string {
+	return s.code.View()
+}
+
+// StatusBarValue implements statusbar.StatusBar.
+func (s *Settings) StatusBarValue() string {
+	return ""
+}
+
+// StatusBarInfo implements statusbar.StatusBar.
+func (s *Settings) StatusBarInfo() string {
+	return fmt.Sprintf("☰ %.f%%", s.code.ScrollPercent()*100)
+}
+
+func (s *Settings) updateSettingsCmd() tea.Msg {
+	m := SettingsMsg{}
+	if s.repo == nil {
+		return common.ErrorCmd(common.ErrMissingRepo)
+	}
+	be := s.common.Backend()
+	rules, err := be.BranchProtections(s.common.Context(), s.repo.Name())
+	if err != nil {
+		s.common.Logger.Debugf("ui: failed to get branch protections: %v", err)
+	}
+	policy, err := be.PushPolicy(s.common.Context(), s.repo.Name())
+	if err != nil {
+		s.common.Logger.Debugf("ui: failed to get push policy: %v", err)
+	}
+	s.code.GotoTop()
+	cmd := s.code.SetContent(settingsMarkdown(s.repo, rules, policy), ".md")
+	if cmd != nil {
+		m.Msg = cmd()
+	}
+	return m
+}
+
+func settingsMarkdown(r proto.Repository, rules []proto.BranchProtection, policy proto.PushPolicy) string {
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Settings\n\n")
+	fmt.Fprintf(&sb, "- Private: %s\n", yesNo(r.IsPrivate()))
+	fmt.Fprintf(&sb, "- Visibility: %s\n", proto.RepositoryVisibility(r))
+	fmt.Fprintf(&sb, "- Hidden: %s\n", yesNo(r.IsHidden()))
+	fmt.Fprintf(&sb, "- Mirror: %s\n", yesNo(r.IsMirror()))
+	fmt.Fprintf(&sb, "- Archived: %s\n", yesNo(r.IsArchived()))
+
+	size := func(n int64) string {
+		if n <= 0 {
+			return "unlimited"
+		}
+		return humanize.IBytes(uint64(n))
+	}
+	banned := "none"
+	if len(policy.BannedExtensions) > 0 {
+		banned = "`" + strings.Join(policy.BannedExtensions, "`, `") + "`"
+	}
+	sb.WriteString("\n## Push Limits\n\n")
+	fmt.Fprintf(&sb, "- Max file size: %s\n", size(policy.MaxBlobSize))
+	fmt.Fprintf(&sb, "- Max push size: %s\n", size(policy.MaxPushSize))
+	fmt.Fprintf(&sb, "- Banned extensions: %s\n", banned)
+
+	sb.WriteString("\n## Protected Branches\n\n")
+	if len(rules) == 0 {
+		sb.WriteString("No protected branches.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Pattern | Force Push | Deletion | Signed Commits | Push Users |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, rule := range rules {
+		pushers := "anyone"
+		if rule.RestrictPush {
+			pushers = strings.Join(append([]string{"admins"}, rule.PushUsers...), ", ")
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %s | %s |\n",
+			rule.Pattern,
+			yesNo(!rule.NoForcePush),
+			yesNo(!rule.NoDeletion),
+			yesNo(rule.RequireSigned),
+			pushers,
+		)
+	}
+
+	sb.WriteString("\nManage protected branches with `repo branch protect`.\n")
+	return sb.String()
+}
@@ -0,0 +1,314 @@
+package selection
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sessions"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/charmbracelet/soft-serve/server/ui/components/code"
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// dashboardPushes is the number of recent pushes on the dashboard.
+	dashboardPushes = 10
+	// dashboardRepos is the number of largest repositories on the
+	// dashboard.
+	dashboardRepos = 10
+	// dashboardLinks is the number of repositories needing attention that
+	// can be opened with a number key.
+	dashboardLinks = 9
+	// dashboardQuotaPercent is the percentage of its disk quota a repository
+	// needs attention at.
+	dashboardQuotaPercent = 90
+)
+
+// OpenRepoMsg is sent to open a repository from the dashboard.
+type OpenRepoMsg string
+
+// dashboardMsg is sent when the dashboard is loaded.
+type dashboardMsg struct {
+	Msg   tea.Msg
+	links []string
+}
+
+// attention is a repository needing attention, and why.
+type attention struct {
+	repo   string
+	reason string
+}
+
+// dashboard is the admin pane showing the health of the server.
+type dashboard struct {
+	common common.Common
+	code   *code.Code
+	// links are the repositories needing attention, in their order on the
+	// dashboard.
+	links []string
+}
+
+func newDashboard(c common.Common) *dashboard {
+	cd := code.New(c, "", "")
+	cd.NoContentStyle = c.Styles.NoContent.Copy().SetString("Loading…")
+	return &dashboard{
+		common: c,
+		code:   cd,
+	}
+}
+
+// SetSize implements common.Component.
+func (d *dashboard) SetSize(width, height int) {
+	d.common.SetSize(width, height)
+	d.code.SetSize(width, height)
+}
+
+// Update implements tea.Model.
+func (d *dashboard) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case dashboardMsg:
+		d.links = msg.links
+	case tea.KeyMsg:
+		if k := msg.String(); key.Matches(msg, d.common.KeyMap.OpenLink) && int(k[0]-'1') < len(d.links) {
+			repo := d.links[k[0]-'1']
+			return func() tea.Msg {
+				return OpenRepoMsg(repo)
+			}
+		}
+	}
+	c, cmd := d.code.Update(msg)
+	d.code = c.(*code.Code)
+	return cmd
+}
+
+// View implements tea.Model.
+func (d *dashboard) View() string {
+	return d.code.View()
+}
+
+func (d *dashboard) loadCmd() tea.Msg {
+	ctx, span := d.common.StartSpan("dashboard")
+	defer span.End()
+	be := d.common.Backend()
+	m := dashboardMsg{}
+
+	var infos []sessions.Info
+	if reg := sessions.FromContext(ctx); reg != nil {
+		infos = reg.List()
+	}
+
+	pushes := make([]events.Event, 0, dashboardPushes)
+	recent := be.RecentEvents()
+	for i := len(recent) - 1; i >= 0 && len(pushes) < dashboardPushes; i-- {
+		switch e := recent[i]; e.Type {
+		case events.Push, events.BranchCreate, events.TagCreate:
+			pushes = append(pushes, e)
+		}
+	}
+
+	// Pending jobs with an error are being retried.
+	var failing []proto.Job
+	jobs, err := be.Jobs(ctx, "")
+	if err != nil {
+		d.common.Logger.Debugf("ui: failed to list jobs: %v", err)
+	}
+	for _, j := range jobs {
+		if j.Status == proto.JobDead || j.LastError != "" {
+			failing = append(failing, j)
+		}
+	}
+
+	var usages []repoUsage
+	var attentions []attention
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		d.common.Logger.Debugf("ui: failed to list repos: %v", err)
+	}
+	du, err := be.RepoDiskUsages(ctx)
+	if err != nil {
+		d.common.Logger.Debugf("ui: failed to get disk usages: %v", err)
+	}
+	for _, r := range repos {
+		u, ok := du[r.ID()]
+		if !ok {
+			continue
+		}
+		usages = append(usages, repoUsage{repo: r.Name(), usage: u})
+		quota, err := be.RepoDiskQuota(ctx, r.Name())
+		if err != nil {
+			d.common.Logger.Debugf("ui: failed to get disk quota of %s: %v", r.Name(), err)
+			continue
+		}
+		if quota > 0 && u.Total()*100 >= quota*dashboardQuotaPercent {
+			attentions = append(attentions, attention{
+				repo:   r.Name(),
+				reason: fmt.Sprintf("disk usage at %d%% of its quota", u.Total()*100/quota),
+			})
+		}
+	}
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].usage.Total() > usages[j].usage.Total()
+	})
+	if len(usages) > dashboardRepos {
+		usages = usages[:dashboardRepos]
+	}
+
+	nfailing := map[string]int{}
+	for _, j := range failing {
+		if j.Repo != "" {
+			nfailing[j.Repo]++
+		}
+	}
+	for _, r := range repos {
+		if n := nfailing[r.Name()]; n > 0 {
+			attentions = append(attentions, attention{
+				repo:   r.Name(),
+				reason: fmt.Sprintf("%d failing %s", n, plural(n, "job", "jobs")),
+			})
+		}
+	}
+	for i, a := range attentions {
+		if i == dashboardLinks {
+			break
+		}
+		m.links = append(m.links, a.repo)
+	}
+
+	d.code.GotoTop()
+	md := dashboardMarkdown(d.common.TimeFormat(), be.StartedAt(), infos, pushes, failing, usages, attentions)
+	if cmd := d.code.SetContent(md, ".md"); cmd != nil {
+		m.Msg = cmd()
+	}
+	return m
+}
+
+// repoUsage is the disk usage of a repository.
+type repoUsage struct {
+	repo  string
+	usage proto.DiskUsage
+}
+
+func dashboardMarkdown(
+	tf proto.TimeFormat,
+	started time.Time,
+	infos []sessions.Info,
+	pushes []events.Event,
+	failing []proto.Job,
+	usages []repoUsage,
+	attentions []attention,
+) string {
+	var sb strings.Builder
+	sb.WriteString("# Server Health\n\n")
+	fmt.Fprintf(&sb, "Up for %s, since %s.\n\n", time.Since(started).Round(time.Second), tf.Absolute(started, time.DateTime))
+
+	sb.WriteString("## Needs Attention\n\n")
+	if len(attentions) == 0 {
+		sb.WriteString("Nothing needs attention.\n\n")
+	} else {
+		for i, a := range attentions {
+			if i < dashboardLinks {
+				fmt.Fprintf(&sb, "%d. `%s`: %s\n", i+1, a.repo, a.reason)
+			} else {
+				fmt.Fprintf(&sb, "- `%s`: %s\n", a.repo, a.reason)
+			}
+		}
+		sb.WriteString("\nPress a number to open a repository.\n\n")
+	}
+
+	fmt.Fprintf(&sb, "## Sessions (%d)\n\n", len(infos))
+	if len(infos) == 0 {
+		sb.WriteString("No active sessions.\n\n")
+	} else {
+		sb.WriteString("| User | Address | Type | Location | Since |\n")
+		sb.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, i := range infos {
+			user := i.Username
+			if user == "" {
+				user = "anonymous"
+			}
+			loc := i.Command
+			if i.Type == "tui" {
+				loc = i.Location.Repo
+				if loc == "" {
+					loc = "repositories"
+				}
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n",
+				user,
+				i.RemoteAddr,
+				i.Type,
+				loc,
+				tf.Relative(i.StartedAt, time.DateTime),
+			)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Recent Pushes\n\n")
+	if len(pushes) == 0 {
+		sb.WriteString("No recent pushes.\n\n")
+	} else {
+		sb.WriteString("| Repository | User | Ref | When |\n")
+		sb.WriteString("| --- | --- | --- | --- |\n")
+		for _, e := range pushes {
+			fmt.Fprintf(&sb, "| %s | %s | `%s` | %s |\n",
+				e.Repo,
+				e.Username,
+				e.Ref,
+				tf.Relative(e.CreatedAt, time.DateTime),
+			)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Failing Jobs\n\n")
+	if len(failing) == 0 {
+		sb.WriteString("No failing jobs or webhooks.\n\n")
+	} else {
+		sb.WriteString("| ID | Kind | Repository | Status | Attempts | Error |\n")
+		sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		for _, j := range failing {
+			fmt.Fprintf(&sb, "| %d | %s | %s | %s | %d/%d | %s |\n",
+				j.ID,
+				j.Kind,
+				j.Repo,
+				j.Status,
+				j.Attempts,
+				j.MaxAttempts,
+				strings.ReplaceAll(j.LastError, "|", "\\|"),
+			)
+		}
+		sb.WriteString("\nRetry dead jobs with `admin jobs retry`.\n\n")
+	}
+
+	sb.WriteString("## Disk Usage\n\n")
+	if len(usages) == 0 {
+		sb.WriteString("No repository was sized yet.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Repository | Total | Objects | LFS |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for _, u := range usages {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n",
+			u.repo,
+			humanize.IBytes(uint64(u.usage.Total())),
+			humanize.IBytes(uint64(u.usage.Objects)),
+			humanize.IBytes(uint64(u.usage.LFS)),
+		)
+	}
+	return sb.String()
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
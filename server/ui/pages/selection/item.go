@@ -14,7 +14,6 @@ import (
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
-	"github.com/dustin/go-humanize"
 )
 
 var _ sort.Interface = Items{}
@@ -54,9 +53,9 @@ type Item struct {
 }
 
 // New creates a new Item.
-func NewItem(repo proto.Repository, cfg *config.Config) (Item, error) {
+func NewItem(repo proto.Repository, meta proto.RepoMetadata, cfg *config.Config) (Item, error) {
 	var lastUpdate *time.Time
-	lu := repo.UpdatedAt()
+	lu := meta.UpdatedAt
 	if !lu.IsZero() {
 		lastUpdate = &lu
 	}
@@ -168,16 +167,23 @@ func (d *ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 	if isSelected {
 		title += " "
 	}
+	var badge string
+	if i.repo.IsArchived() {
+		badge = d.common.Styles.ArchivedBadge.Render("archived")
+		if !isSelected {
+			badge = " " + badge
+		}
+	}
 	var updatedStr string
 	if i.lastUpdate != nil {
-		updatedStr = fmt.Sprintf(" Updated %s", humanize.Time(*i.lastUpdate))
+		updatedStr = fmt.Sprintf(" Updated %s", d.common.TimeFormat().Relative(*i.lastUpdate, "Jan 02 2006"))
 	}
-	if m.Width()-styles.Base.GetHorizontalFrameSize()-lipgloss.Width(updatedStr)-lipgloss.Width(title) <= 0 {
+	if m.Width()-styles.Base.GetHorizontalFrameSize()-lipgloss.Width(updatedStr)-lipgloss.Width(title)-lipgloss.Width(badge) <= 0 {
 		updatedStr = ""
 	}
 	updatedStyle := styles.Updated.Copy().
 		Align(lipgloss.Right).
-		Width(m.Width() - styles.Base.GetHorizontalFrameSize() - lipgloss.Width(title))
+		Width(m.Width() - styles.Base.GetHorizontalFrameSize() - lipgloss.Width(title) - lipgloss.Width(badge))
 	updated := updatedStyle.Render(updatedStr)
 
 	if isFiltered && index < len(m.VisibleItems()) {
@@ -195,7 +201,7 @@ func (d *ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 	desc = common.TruncateString(desc, m.Width()-styles.Base.GetHorizontalFrameSize())
 	desc = styles.Desc.Render(desc)
 
-	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Bottom, title, updated))
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Bottom, title, badge, updated))
 	s.WriteRune('\n')
 	s.WriteString(desc)
 	s.WriteRune('\n')
@@ -0,0 +1,117 @@
+package selection
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/charmbracelet/soft-serve/server/ui/components/code"
+	"golang.org/x/crypto/ssh"
+)
+
+// keysMsg is sent when the public keys are loaded.
+type keysMsg struct {
+	Msg tea.Msg
+}
+
+// keys is the pane listing the public keys of the user.
+type keys struct {
+	common common.Common
+	code   *code.Code
+}
+
+func newKeys(c common.Common) *keys {
+	cd := code.New(c, "", "")
+	cd.NoContentStyle = c.Styles.NoContent.Copy().SetString("Loading…")
+	return &keys{
+		common: c,
+		code:   cd,
+	}
+}
+
+// SetSize implements common.Component.
+func (k *keys) SetSize(width, height int) {
+	k.common.SetSize(width, height)
+	k.code.SetSize(width, height)
+}
+
+// Update implements tea.Model.
+func (k *keys) Update(msg tea.Msg) tea.Cmd {
+	c, cmd := k.code.Update(msg)
+	k.code = c.(*code.Code)
+	return cmd
+}
+
+// View implements tea.Model.
+func (k *keys) View() string {
+	return k.code.View()
+}
+
+func (k *keys) loadCmd() tea.Msg {
+	ctx, span := k.common.StartSpan("keys")
+	defer span.End()
+	m := keysMsg{}
+	user := proto.UserFromContext(ctx)
+	if user == nil {
+		return m
+	}
+
+	pks, err := k.common.Backend().UserPublicKeys(ctx, user.Username())
+	if err != nil {
+		k.common.Logger.Debugf("ui: failed to list public keys: %v", err)
+	}
+
+	k.code.GotoTop()
+	md := keysMarkdown(k.common.TimeFormat(), pks, k.common.PublicKey())
+	if cmd := k.code.SetContent(md, ".md"); cmd != nil {
+		m.Msg = cmd()
+	}
+	return m
+}
+
+func keysMarkdown(tf proto.TimeFormat, pks []proto.PublicKey, current ssh.PublicKey) string {
+	var sb strings.Builder
+	sb.WriteString("# Public Keys\n\n")
+	if len(pks) == 0 {
+		sb.WriteString("No public keys.\n\n")
+	} else {
+		sb.WriteString("| ID | Label | Restriction | Fingerprint | Added | Last Used | Expires |\n")
+		sb.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+		for _, k := range pks {
+			label := k.Label
+			if label == "" {
+				label = "-"
+			}
+			if current != nil && bytes.Equal(current.Marshal(), k.PublicKey.Marshal()) {
+				label += " (this key)"
+			}
+			lastUsed := "never"
+			if !k.LastUsedAt.IsZero() {
+				lastUsed = tf.Relative(k.LastUsedAt, time.DateTime)
+			}
+			expires := "-"
+			if k.IsExpired() {
+				expires = "expired"
+			} else if !k.ExpiresAt.IsZero() {
+				expires = tf.Relative(k.ExpiresAt, time.DateTime)
+			}
+			fmt.Fprintf(&sb, "| %d | %s | %s | `%s` | %s | %s | %s |\n",
+				k.ID,
+				strings.ReplaceAll(label, "|", "\\|"),
+				k.Restriction,
+				ssh.FingerprintSHA256(k.PublicKey),
+				tf.Relative(k.CreatedAt, time.DateTime),
+				lastUsed,
+				expires,
+			)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Add a key with `pubkey add`, and remove one with `pubkey remove ID`, and restrict one with `pubkey restrict ID`.\n")
+	return sb.String()
+}
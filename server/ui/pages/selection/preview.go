@@ -0,0 +1,284 @@
+package selection
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/lexers"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// previewMinWidth is the minimum width of the page the preview pane is
+	// shown at.
+	previewMinWidth = 120
+	// previewCommits is the number of commits in the preview.
+	previewCommits = 3
+	// previewReadmeLines is the maximum number of lines of the README
+	// excerpt.
+	previewReadmeLines = 20
+)
+
+// previewMsg is sent when the preview of a repository is loaded.
+type previewMsg struct {
+	repo    string
+	preview *repoPreview
+}
+
+// language is the total size of the files of a language.
+type language struct {
+	name string
+	size int64
+}
+
+// repoPreview is the preview of a repository.
+type repoPreview struct {
+	readme    string
+	commits   git.Commits
+	languages []language
+
+	// rendered is the README rendered at renderedWidth.
+	rendered      string
+	renderedWidth int
+}
+
+// preview is the pane previewing the active repository of the selector.
+type preview struct {
+	common   common.Common
+	repo     string
+	previews map[string]*repoPreview
+}
+
+func newPreview(c common.Common) *preview {
+	return &preview{
+		common:   c,
+		previews: make(map[string]*repoPreview),
+	}
+}
+
+// SetSize implements common.Component.
+func (p *preview) SetSize(width, height int) {
+	p.common.SetSize(width, height)
+}
+
+// SetRepo sets the previewed repository. It returns a command loading the
+// preview unless it's already loaded.
+func (p *preview) SetRepo(repo string) tea.Cmd {
+	p.repo = repo
+	return p.loadCmd()
+}
+
+// Update handles loaded previews.
+func (p *preview) Update(msg previewMsg) {
+	p.previews[msg.repo] = msg.preview
+}
+
+func (p *preview) loadCmd() tea.Cmd {
+	repo := p.repo
+	if _, ok := p.previews[repo]; ok || repo == "" {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx, span := p.common.StartSpan("preview", attribute.String("repo", repo))
+		defer span.End()
+		be := p.common.Backend()
+		pv := &repoPreview{}
+		r, err := be.Repository(ctx, repo)
+		if err != nil {
+			p.common.Logger.Debugf("ui: failed to get repository %s: %v", repo, err)
+			return previewMsg{repo: repo, preview: pv}
+		}
+
+		pv.readme, _, _ = be.Readme(ctx, r)
+
+		// Empty repositories have neither commits nor files.
+		rr, err := r.Open()
+		if err != nil {
+			return previewMsg{repo: repo, preview: pv}
+		}
+		head, err := rr.HEAD()
+		if err != nil {
+			return previewMsg{repo: repo, preview: pv}
+		}
+
+		pv.commits, err = rr.CommitsByPage(head, 1, previewCommits)
+		if err != nil {
+			p.common.Logger.Debugf("ui: failed to get commits for %s: %v", repo, err)
+		}
+
+		files, err := rr.Files(head.Hash.String())
+		if err != nil {
+			p.common.Logger.Debugf("ui: failed to list files for %s: %v", repo, err)
+		}
+		pv.languages = languages(files)
+
+		return previewMsg{repo: repo, preview: pv}
+	}
+}
+
+// languages returns the total size of the files of each language, largest
+// first. Files without a known language are ignored.
+func languages(files []git.Object) []language {
+	sizes := make(map[string]int64)
+	for _, f := range files {
+		lexer := lexers.Match(filepath.Base(f.Path))
+		if lexer == nil || f.Size == 0 {
+			continue
+		}
+		sizes[lexer.Config().Name] += f.Size
+	}
+
+	langs := make([]language, 0, len(sizes))
+	for name, size := range sizes {
+		langs = append(langs, language{name: name, size: size})
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if langs[i].size == langs[j].size {
+			return langs[i].name < langs[j].name
+		}
+		return langs[i].size > langs[j].size
+	})
+
+	return langs
+}
+
+// View implements tea.Model.
+func (p *preview) View() string {
+	st := p.common.Styles.Preview
+	width := p.common.Width - st.Base.GetHorizontalFrameSize()
+	height := p.common.Height - st.Base.GetVerticalFrameSize()
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+
+	title := st.Title.Render(common.TruncateString(p.repo, width))
+	pv, ok := p.previews[p.repo]
+	if !ok {
+		return p.render(title, st.Empty.Render("Loading…"))
+	}
+
+	var bottom []string
+	if len(pv.commits) > 0 {
+		bottom = append(bottom, st.Section.Render("Latest commits"))
+		for _, c := range pv.commits {
+			hash := st.CommitHash.Render(c.ID.String()[:7])
+			date := st.CommitDate.Render(p.common.TimeFormat().Relative(c.Author.When, "Jan 02 2006"))
+			msg := common.TruncateString(c.Summary(), width-lipgloss.Width(hash)-lipgloss.Width(date)-2)
+			bottom = append(bottom, fmt.Sprintf("%s %s %s", hash, st.CommitTitle.Render(msg), date))
+		}
+	} else {
+		bottom = append(bottom, st.Section.Render("No commits yet"))
+	}
+	if len(pv.languages) > 0 {
+		bottom = append(bottom,
+			st.Section.Render("Languages"),
+			p.languagesView(pv.languages, width),
+		)
+	}
+
+	// The README gets the lines the other sections don't use.
+	lines := height - lipgloss.Height(title) - lipgloss.Height(strings.Join(bottom, "\n")) - 1
+	if lines > previewReadmeLines {
+		lines = previewReadmeLines
+	}
+
+	readme := st.Empty.Render("No readme found.")
+	if pv.readme != "" {
+		if pv.renderedWidth != width {
+			md, err := common.RenderMarkdown(pv.readme, width)
+			if err != nil {
+				p.common.Logger.Debugf("ui: failed to render readme of %s: %v", p.repo, err)
+			}
+			pv.rendered = strings.Trim(md, "\n")
+			pv.renderedWidth = width
+		}
+		readme = pv.rendered
+	}
+	readme = excerpt(readme, lines)
+
+	parts := []string{title}
+	if readme != "" {
+		parts = append(parts, "", readme)
+	}
+
+	return p.render(append(parts, bottom...)...)
+}
+
+func (p *preview) render(parts ...string) string {
+	st := p.common.Styles.Preview.Base
+	// Widths and heights of styles include their padding.
+	return st.Copy().
+		Width(p.common.Width - st.GetHorizontalBorderSize() - st.GetHorizontalMargins()).
+		Height(p.common.Height - st.GetVerticalBorderSize() - st.GetVerticalMargins()).
+		MaxHeight(p.common.Height).
+		Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+// languagesView renders the language bar and its legend.
+func (p *preview) languagesView(langs []language, width int) string {
+	st := p.common.Styles.Preview
+	colors := st.LanguageColors
+	var total int64
+	for _, l := range langs {
+		total += l.size
+	}
+
+	// Languages beyond the colors are grouped as "Other".
+	if len(langs) > len(colors) {
+		other := language{name: "Other"}
+		for _, l := range langs[len(colors)-1:] {
+			other.size += l.size
+		}
+		langs = append(langs[:len(colors)-1:len(colors)-1], other)
+	}
+
+	var bar, legend strings.Builder
+	used := 0
+	for i, l := range langs {
+		w := int(math.Round(float64(l.size) / float64(total) * float64(width)))
+		if i == len(langs)-1 {
+			w = width - used
+		}
+		if w > width-used {
+			w = width - used
+		}
+		used += w
+
+		color := lipgloss.NewStyle().Foreground(colors[i])
+		bar.WriteString(color.Render(strings.Repeat("█", w)))
+
+		entry := fmt.Sprintf("%s %s %.1f%%", color.Render("●"),
+			st.Language.Render(l.name), float64(l.size)/float64(total)*100)
+		if lipgloss.Width(legend.String())+lipgloss.Width(entry)+2 > width {
+			continue
+		}
+		if legend.Len() > 0 {
+			legend.WriteString("  ")
+		}
+		legend.WriteString(entry)
+	}
+
+	return bar.String() + "\n" + legend.String()
+}
+
+// excerpt returns the first lines of s.
+func excerpt(s string, lines int) string {
+	if lines <= 0 {
+		return ""
+	}
+
+	ls := strings.Split(s, "\n")
+	if len(ls) > lines {
+		ls = ls[:lines]
+	}
+
+	return strings.Join(ls, "\n")
+}
@@ -1,6 +1,7 @@
 package selection
 
 import (
+	"context"
 	"fmt"
 	"sort"
 
@@ -9,22 +10,41 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/soft-serve/server/access"
-	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/tracing"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
 	"github.com/charmbracelet/soft-serve/server/ui/components/code"
 	"github.com/charmbracelet/soft-serve/server/ui/components/selector"
 	"github.com/charmbracelet/soft-serve/server/ui/components/tabs"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
 	defaultNoContent = "No readme found.\n\nCreate a `.soft-serve` repository and add a `README.md` file to display readme."
+
+	// refreshWorkers is the number of repositories opened at once on refresh.
+	refreshWorkers = 8
 )
 
+// refreshMsg is a message that contains the refreshed repositories.
+type refreshMsg struct {
+	seq    int
+	items  []selector.IdentifiableItem
+	readme *readmeContent
+}
+
+type readmeContent struct {
+	content string
+	path    string
+}
+
 type pane int
 
 const (
 	selectorPane pane = iota
 	readmePane
+	keysPane
+	dashboardPane
 	lastPane
 )
 
@@ -32,6 +52,8 @@ func (p pane) String() string {
 	return []string{
 		"Repositories",
 		"About",
+		"Keys",
+		"Admin",
 	}[p]
 }
 
@@ -42,12 +64,30 @@ type Selection struct {
 	selector   *selector.Selector
 	activePane pane
 	tabs       *tabs.Tabs
+	preview    *preview
+	// keys is the pane of the user public keys, nil for anonymous users.
+	keys *keys
+	// dashboard is the admin pane, nil for other users.
+	dashboard *dashboard
+	// refreshSeq identifies the latest refresh, and cancel stops it.
+	refreshSeq int
+	cancel     context.CancelFunc
+	loaded     bool
 }
 
 // New creates a new selection model.
 func New(c common.Common) *Selection {
-	ts := make([]string, lastPane)
-	for i, b := range []pane{selectorPane, readmePane} {
+	panes := []pane{selectorPane, readmePane}
+	user := proto.UserFromContext(c.Context())
+	if user != nil {
+		panes = append(panes, keysPane)
+	}
+	isAdmin := user != nil && user.IsAdmin()
+	if isAdmin {
+		panes = append(panes, dashboardPane)
+	}
+	ts := make([]string, len(panes))
+	for i, b := range panes {
 		ts[i] = b.String()
 	}
 	t := tabs.New(c, ts)
@@ -73,6 +113,13 @@ func New(c common.Common) *Selection {
 	selector.DisableQuitKeybindings()
 	sel.selector = selector
 	sel.readme = readme
+	sel.preview = newPreview(c)
+	if user != nil {
+		sel.keys = newKeys(c)
+	}
+	if isAdmin {
+		sel.dashboard = newDashboard(c)
+	}
 	return sel
 }
 
@@ -97,8 +144,26 @@ func (s *Selection) SetSize(width, height int) {
 	s.common.SetSize(width, height)
 	wm, hm := s.getMargins()
 	s.tabs.SetSize(width, height-hm)
-	s.selector.SetSize(width-wm, height-hm)
 	s.readme.SetSize(width-wm, height-hm-1) // -1 for readme status line
+	if s.keys != nil {
+		s.keys.SetSize(width-wm, height-hm)
+	}
+	if s.dashboard != nil {
+		s.dashboard.SetSize(width-wm, height-hm)
+	}
+	if s.showPreview() {
+		sw := (width - wm) / 2
+		s.selector.SetSize(sw, height-hm)
+		s.preview.SetSize(width-wm-sw, height-hm)
+	} else {
+		s.selector.SetSize(width-wm, height-hm)
+	}
+}
+
+// showPreview returns whether the preview pane is shown next to the
+// selector. It's only shown on wide terminals.
+func (s *Selection) showPreview() bool {
+	return s.common.Width >= previewMinWidth
 }
 
 // IsFiltering returns true if the selector is currently filtering.
@@ -124,6 +189,9 @@ func (s *Selection) ShortHelp() []key.Binding {
 			copyKey,
 		)
 	}
+	if s.activePane == dashboardPane && len(s.dashboard.links) > 0 {
+		kb = append(kb, s.common.KeyMap.OpenLink)
+	}
 	return kb
 }
 
@@ -135,6 +203,27 @@ func (s *Selection) FullHelp() [][]key.Binding {
 		},
 	}
 	switch s.activePane {
+	case dashboardPane:
+		b[0] = append(b[0], s.common.KeyMap.OpenLink)
+		k := s.dashboard.code.KeyMap
+		b = append(b, []key.Binding{
+			k.PageDown,
+			k.PageUp,
+		})
+		b = append(b, []key.Binding{
+			k.Down,
+			k.Up,
+		})
+	case keysPane:
+		k := s.keys.code.KeyMap
+		b = append(b, []key.Binding{
+			k.PageDown,
+			k.PageUp,
+		})
+		b = append(b, []key.Binding{
+			k.Down,
+			k.Up,
+		})
 	case readmePane:
 		k := s.readme.KeyMap
 		b = append(b, []key.Binding{
@@ -181,57 +270,105 @@ func (s *Selection) FullHelp() [][]key.Binding {
 
 // Init implements tea.Model.
 func (s *Selection) Init() tea.Cmd {
-	var readmeCmd tea.Cmd
+	return tea.Batch(
+		s.selector.Init(),
+		s.Refresh(),
+	)
+}
+
+// Loaded returns whether the repositories were refreshed.
+func (s *Selection) Loaded() bool {
+	return s.loaded
+}
+
+// Cancel stops the refresh in progress, if any.
+func (s *Selection) Cancel() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// Refresh returns a command that reloads the repositories, opening up to
+// refreshWorkers repositories at once. It cancels the previous refresh.
+func (s *Selection) Refresh() tea.Cmd {
+	s.Cancel()
 	cfg := s.common.Config()
 	if cfg == nil {
 		return nil
 	}
 
-	ctx := s.common.Context()
 	be := s.common.Backend()
-	pk := s.common.PublicKey()
-	if pk == nil && !be.AllowKeyless(ctx) {
+	if s.common.PublicKey() == nil && proto.UserFromContext(s.common.Context()) == nil && !be.AllowKeyless(s.common.Context()) {
 		return nil
 	}
 
-	repos, err := be.Repositories(ctx)
-	if err != nil {
-		return common.ErrorCmd(err)
-	}
-	sortedItems := make(Items, 0)
-	for _, r := range repos {
-		if r.Name() == ".soft-serve" {
-			readme, path, err := backend.Readme(r)
-			if err != nil {
-				continue
+	ctx, cancel := context.WithCancel(s.common.Context())
+	s.cancel = cancel
+	s.loaded = false
+	s.refreshSeq++
+	seq := s.refreshSeq
+	return func() tea.Msg {
+		defer cancel()
+		ctx, span := tracing.Start(ctx, "ui.repos")
+		defer span.End()
+		repos, err := be.Repositories(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
 			}
-
-			readmeCmd = s.readme.SetContent(readme, path)
+			return common.ErrorMsg(err)
 		}
 
-		if r.IsHidden() {
-			continue
+		var readme *readmeContent
+		items := make([]*Item, len(repos))
+		g, ctx := errgroup.WithContext(ctx)
+		g.SetLimit(refreshWorkers)
+		for i, r := range repos {
+			i, r := i, r
+			g.Go(func() error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if r.Name() == ".soft-serve" {
+					if content, path, err := be.Readme(ctx, r); err == nil {
+						readme = &readmeContent{content: content, path: path}
+					}
+				}
+				if r.IsHidden() || s.common.AccessLevel(r.Name()) < access.ReadOnlyAccess {
+					return nil
+				}
+				item, err := NewItem(r, be.RepoMetadata(ctx, r), cfg)
+				if err != nil {
+					s.common.Logger.Debugf("ui: failed to create item for %s: %v", r.Name(), err)
+					return nil
+				}
+				items[i] = &item
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			// The user navigated away.
+			return nil
 		}
-		al := be.AccessLevelByPublicKey(ctx, r.Name(), pk)
-		if al >= access.ReadOnlyAccess {
-			item, err := NewItem(r, cfg)
-			if err != nil {
-				s.common.Logger.Debugf("ui: failed to create item for %s: %v", r.Name(), err)
-				continue
+
+		sortedItems := make(Items, 0, len(items))
+		for _, it := range items {
+			if it != nil {
+				sortedItems = append(sortedItems, *it)
 			}
-			sortedItems = append(sortedItems, item)
 		}
+		sort.Sort(sortedItems)
+		msg := refreshMsg{
+			seq:    seq,
+			items:  make([]selector.IdentifiableItem, len(sortedItems)),
+			readme: readme,
+		}
+		for i, it := range sortedItems {
+			msg.items[i] = it
+		}
+		return msg
 	}
-	sort.Sort(sortedItems)
-	items := make([]selector.IdentifiableItem, len(sortedItems))
-	for i, it := range sortedItems {
-		items[i] = it
-	}
-	return tea.Batch(
-		s.selector.Init(),
-		s.selector.SetItems(items),
-		readmeCmd,
-	)
 }
 
 // Update implements tea.Model.
@@ -249,6 +386,40 @@ func (s *Selection) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		if s.showPreview() {
+			cmds = append(cmds, s.preview.loadCmd())
+		}
+	case selector.ActiveMsg:
+		if item, ok := msg.IdentifiableItem.(Item); ok {
+			cmd := s.preview.SetRepo(item.ID())
+			if s.showPreview() {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case refreshMsg:
+		if msg.seq != s.refreshSeq {
+			break
+		}
+		s.cancel = nil
+		s.loaded = true
+		cmds = append(cmds,
+			s.selector.SetItems(msg.items),
+			// Preview the selected repository.
+			s.selector.Init(),
+		)
+		if msg.readme != nil {
+			cmds = append(cmds, s.readme.SetContent(msg.readme.content, msg.readme.path))
+		}
+	case previewMsg:
+		s.preview.Update(msg)
+	case keysMsg:
+		if s.keys != nil {
+			s.keys.Update(msg)
+		}
+	case dashboardMsg:
+		if s.dashboard != nil {
+			s.dashboard.Update(msg)
+		}
 	case tea.KeyMsg, tea.MouseMsg:
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
@@ -264,8 +435,27 @@ func (s *Selection) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case tabs.ActiveTabMsg:
 		s.activePane = pane(msg)
+		// The keys and the dashboard are reloaded each time they're shown.
+		switch s.activePane {
+		case keysPane:
+			cmds = append(cmds, s.keys.loadCmd)
+		case dashboardPane:
+			cmds = append(cmds, s.dashboard.loadCmd)
+		}
 	}
 	switch s.activePane {
+	case keysPane:
+		if _, ok := msg.(keysMsg); !ok {
+			if cmd := s.keys.Update(msg); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case dashboardPane:
+		if _, ok := msg.(dashboardMsg); !ok {
+			if cmd := s.dashboard.Update(msg); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
 	case readmePane:
 		r, cmd := s.readme.Update(msg)
 		s.readme = r.(*code.Code)
@@ -291,7 +481,22 @@ func (s *Selection) View() string {
 		ss := lipgloss.NewStyle().
 			Width(s.common.Width - wm).
 			Height(s.common.Height - hm)
-		view = ss.Render(s.selector.View())
+		view = s.selector.View()
+		if s.showPreview() {
+			view = lipgloss.JoinHorizontal(lipgloss.Top,
+				ss.Copy().Width(s.selector.Width()).Render(view),
+				s.preview.View(),
+			)
+		}
+		view = ss.Render(view)
+	case keysPane:
+		view = lipgloss.NewStyle().
+			Height(s.common.Height - hm).
+			Render(s.keys.View())
+	case dashboardPane:
+		view = lipgloss.NewStyle().
+			Height(s.common.Height - hm).
+			Render(s.dashboard.View())
 	case readmePane:
 		rs := lipgloss.NewStyle().
 			Height(s.common.Height - hm)
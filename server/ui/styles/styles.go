@@ -14,6 +14,11 @@ type Styles struct {
 
 	App                  lipgloss.Style
 	ServerName           lipgloss.Style
+	ShareBanner          lipgloss.Style
+	ShutdownBanner       lipgloss.Style
+	MaintenanceBanner    lipgloss.Style
+	BroadcastBanner      lipgloss.Style
+	MOTD                 lipgloss.Style
 	TopLevelNormalTab    lipgloss.Style
 	TopLevelActiveTab    lipgloss.Style
 	TopLevelActiveTabDot lipgloss.Style
@@ -38,6 +43,30 @@ type Styles struct {
 		}
 	}
 
+	Preview struct {
+		Base        lipgloss.Style
+		Title       lipgloss.Style
+		Section     lipgloss.Style
+		CommitHash  lipgloss.Style
+		CommitTitle lipgloss.Style
+		CommitDate  lipgloss.Style
+		Language    lipgloss.Style
+		Empty       lipgloss.Style
+		// LanguageColors are the colors of the languages in the language
+		// bar, the last one is used for all other languages.
+		LanguageColors []lipgloss.Color
+	}
+
+	Switcher struct {
+		Base       lipgloss.Style
+		Title      lipgloss.Style
+		Item       lipgloss.Style
+		ActiveItem lipgloss.Style
+		NoItems    lipgloss.Style
+		Label      lipgloss.Style
+		Error      lipgloss.Style
+	}
+
 	Repo struct {
 		Base       lipgloss.Style
 		Title      lipgloss.Style
@@ -55,6 +84,10 @@ type Styles struct {
 	HelpDivider lipgloss.Style
 	URLStyle    lipgloss.Style
 
+	// ArchivedBadge marks archived repositories in the repository list and
+	// header.
+	ArchivedBadge lipgloss.Style
+
 	Error      lipgloss.Style
 	ErrorTitle lipgloss.Style
 	ErrorBody  lipgloss.Style
@@ -74,6 +107,7 @@ type Styles struct {
 			Desc    lipgloss.Style
 			Keyword lipgloss.Style
 		}
+		Unread lipgloss.Style
 	}
 
 	Log struct {
@@ -126,12 +160,13 @@ type Styles struct {
 
 	NoItems lipgloss.Style
 
-	StatusBar       lipgloss.Style
-	StatusBarKey    lipgloss.Style
-	StatusBarValue  lipgloss.Style
-	StatusBarInfo   lipgloss.Style
-	StatusBarBranch lipgloss.Style
-	StatusBarHelp   lipgloss.Style
+	StatusBar        lipgloss.Style
+	StatusBarKey     lipgloss.Style
+	StatusBarValue   lipgloss.Style
+	StatusBarInfo    lipgloss.Style
+	StatusBarViewers lipgloss.Style
+	StatusBarBranch  lipgloss.Style
+	StatusBarHelp    lipgloss.Style
 
 	Tabs         lipgloss.Style
 	TabInactive  lipgloss.Style
@@ -163,6 +198,32 @@ func DefaultStyles() *Styles {
 		Foreground(lipgloss.Color("229")).
 		Bold(true)
 
+	s.ShareBanner = lipgloss.NewStyle().
+		Height(1).
+		MarginBottom(1).
+		Padding(0, 1).
+		Background(lipgloss.Color("203")).
+		Foreground(lipgloss.Color("230")).
+		Bold(true)
+
+	s.ShutdownBanner = lipgloss.NewStyle().
+		Height(1).
+		MarginBottom(1).
+		Padding(0, 1).
+		Background(lipgloss.Color("214")).
+		Foreground(lipgloss.Color("232")).
+		Bold(true)
+
+	s.MaintenanceBanner = s.ShutdownBanner.Copy()
+
+	s.BroadcastBanner = s.ShareBanner.Copy().
+		Background(lipgloss.Color("63"))
+
+	s.MOTD = lipgloss.NewStyle().
+		MarginBottom(1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63"))
+
 	s.TopLevelNormalTab = lipgloss.NewStyle().
 		MarginRight(2)
 
@@ -204,6 +265,72 @@ func DefaultStyles() *Styles {
 	s.RepoSelector.Active.Command = s.RepoSelector.Normal.Command.Copy().
 		Foreground(lipgloss.Color("204"))
 
+	s.Preview.Base = lipgloss.NewStyle().
+		PaddingLeft(2).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(s.InactiveBorderColor)
+
+	s.Preview.Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("212"))
+
+	s.Preview.Section = lipgloss.NewStyle().
+		MarginTop(1).
+		Foreground(lipgloss.Color("246")).
+		Bold(true)
+
+	s.Preview.CommitHash = lipgloss.NewStyle().
+		Foreground(hashColor)
+
+	s.Preview.CommitTitle = lipgloss.NewStyle()
+
+	s.Preview.CommitDate = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	s.Preview.Language = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("246"))
+
+	s.Preview.Empty = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	s.Preview.LanguageColors = []lipgloss.Color{
+		lipgloss.Color("39"),
+		lipgloss.Color("212"),
+		lipgloss.Color("185"),
+		lipgloss.Color("42"),
+		lipgloss.Color("208"),
+		lipgloss.Color("241"),
+	}
+
+	s.Switcher.Base = lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(s.ActiveBorderColor)
+
+	s.Switcher.Title = lipgloss.NewStyle().
+		Bold(true).
+		MarginBottom(1)
+
+	s.Switcher.Item = lipgloss.NewStyle().
+		PaddingLeft(2)
+
+	s.Switcher.ActiveItem = lipgloss.NewStyle().
+		PaddingLeft(1).
+		Border(lipgloss.Border{Left: "┃"}, false, false, false, true).
+		BorderForeground(lipgloss.Color("176")).
+		Foreground(lipgloss.Color("212"))
+
+	s.Switcher.NoItems = lipgloss.NewStyle().
+		PaddingLeft(2).
+		Foreground(lipgloss.Color("243"))
+
+	s.Switcher.Label = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	s.Switcher.Error = lipgloss.NewStyle().
+		MarginTop(1).
+		Foreground(lipgloss.Color("203"))
+
 	s.MenuItem = lipgloss.NewStyle().
 		PaddingLeft(1).
 		Border(lipgloss.Border{
@@ -262,6 +389,11 @@ func DefaultStyles() *Styles {
 		MarginLeft(1).
 		Foreground(lipgloss.Color("168"))
 
+	s.ArchivedBadge = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("130")).
+		Padding(0, 1)
+
 	s.Error = lipgloss.NewStyle().
 		MarginTop(2)
 
@@ -316,6 +448,10 @@ func DefaultStyles() *Styles {
 	s.LogItem.Active.Hash = lipgloss.NewStyle().
 		Foreground(highlightColor)
 
+	s.LogItem.Unread = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("203")).
+		Bold(true)
+
 	s.Log.Commit = lipgloss.NewStyle().
 		Margin(0, 2)
 
@@ -433,6 +569,11 @@ func DefaultStyles() *Styles {
 		Background(lipgloss.Color("212")).
 		Foreground(lipgloss.Color("230"))
 
+	s.StatusBarViewers = lipgloss.NewStyle().
+		Padding(0, 1).
+		Background(lipgloss.Color("35")).
+		Foreground(lipgloss.Color("230"))
+
 	s.StatusBarBranch = lipgloss.NewStyle().
 		Padding(0, 1).
 		Background(lipgloss.Color("62")).
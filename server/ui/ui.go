@@ -2,16 +2,27 @@ package ui
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/presence"
 	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sessions"
+	"github.com/charmbracelet/soft-serve/server/share"
 	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/charmbracelet/soft-serve/server/ui/components/create"
 	"github.com/charmbracelet/soft-serve/server/ui/components/footer"
 	"github.com/charmbracelet/soft-serve/server/ui/components/header"
 	"github.com/charmbracelet/soft-serve/server/ui/components/selector"
+	"github.com/charmbracelet/soft-serve/server/ui/components/switcher"
 	"github.com/charmbracelet/soft-serve/server/ui/pages/repo"
 	"github.com/charmbracelet/soft-serve/server/ui/pages/selection"
 )
@@ -23,6 +34,13 @@ const (
 	repoPage
 )
 
+// maxMOTDHeight is the maximum number of lines of the message of the day.
+const maxMOTDHeight = 5
+
+// sessionSaveDelay is how long to wait for the UI to settle before saving the
+// session state.
+const sessionSaveDelay = 500 * time.Millisecond
+
 type sessionState int
 
 const (
@@ -43,11 +61,63 @@ type UI struct {
 	footer      *footer.Footer
 	showFooter  bool
 	error       error
+	shareCode   string
+	// maintenance is the maintenance notice of the server or of the
+	// repository, if any.
+	maintenance string
+	// shutdownAt is when the server closes the session, once it's shutting
+	// down.
+	shutdownAt time.Time
+	// motd is the message of the day, motdView caches it rendered at
+	// motdWidth. It's hidden once dismissed, until it changes.
+	motd          string
+	motdView      string
+	motdWidth     int
+	motdDismissed bool
+	// broadcast is the last message sent by an admin, until it's dismissed.
+	broadcast     string
+	announcements *events.Subscription
+	// switcher is the repository quick-switcher, shown on the repository
+	// page.
+	switcher     *switcher.Switcher
+	showSwitcher bool
+	// creator is the dialog to create a repository, shown on the selection
+	// page.
+	creator    *create.Create
+	showCreate bool
+	// session is the last known state of the session, resume holds the
+	// state to restore once its repository is opened.
+	session       proto.SessionState
+	resume        *proto.SessionState
+	sessionLoaded bool
+	sessionSeq    int
 }
 
+// sessionStateMsg is a message that contains the state of the user's
+// previous session.
+type sessionStateMsg proto.SessionState
+
+// switcherReposMsg is a message that contains the repositories the user can
+// switch to.
+type switcherReposMsg []string
+
+// createTemplatesMsg is a message that contains the templates the user can
+// create a repository from.
+type createTemplatesMsg []string
+
+// createErrorMsg is a message that contains the error creating a repository.
+type createErrorMsg struct{ error }
+
+// createdMsg is a message sent once a repository is created.
+type createdMsg string
+
+// saveSessionMsg is a message to save the session state. It's dropped if the
+// state changed again since it was sent.
+type saveSessionMsg int
+
 // New returns a new UI model.
 func New(c common.Common, initialRepo string) *UI {
-	serverName := c.Config().Name
+	serverName := c.Config().Current().Name
 	h := header.New(c, serverName)
 	ui := &UI{
 		serverName:  serverName,
@@ -60,6 +130,8 @@ func New(c common.Common, initialRepo string) *UI {
 		showFooter:  true,
 	}
 	ui.footer = footer.New(c, ui)
+	ui.switcher = switcher.New(c)
+	ui.creator = create.New(c)
 	return ui
 }
 
@@ -71,6 +143,25 @@ func (ui *UI) getMargins() (wm, hm int) {
 			ui.common.Styles.ServerName.GetVerticalFrameSize()
 	case repoPage:
 	}
+	if ui.shareCode != "" {
+		hm += ui.common.Styles.ShareBanner.GetHeight() +
+			ui.common.Styles.ShareBanner.GetVerticalFrameSize()
+	}
+	if ui.maintenance != "" {
+		hm += ui.common.Styles.MaintenanceBanner.GetHeight() +
+			ui.common.Styles.MaintenanceBanner.GetVerticalFrameSize()
+	}
+	if ui.broadcast != "" {
+		hm += ui.common.Styles.BroadcastBanner.GetHeight() +
+			ui.common.Styles.BroadcastBanner.GetVerticalFrameSize()
+	}
+	if ui.showMOTD() {
+		hm += lipgloss.Height(ui.motdView)
+	}
+	if !ui.shutdownAt.IsZero() {
+		hm += ui.common.Styles.ShutdownBanner.GetHeight() +
+			ui.common.Styles.ShutdownBanner.GetVerticalFrameSize()
+	}
 	wm += style.GetHorizontalFrameSize()
 	hm += style.GetVerticalFrameSize()
 	if ui.showFooter {
@@ -89,6 +180,12 @@ func (ui *UI) ShortHelp() []key.Binding {
 	case errorState:
 		b = append(b, ui.common.KeyMap.Back)
 	case readyState:
+		if ui.showSwitcher {
+			return ui.switcher.ShortHelp()
+		}
+		if ui.showCreate {
+			return ui.creator.ShortHelp()
+		}
 		b = append(b, ui.pages[ui.activePage].ShortHelp()...)
 	}
 	if !ui.IsFiltering() {
@@ -105,11 +202,29 @@ func (ui *UI) FullHelp() [][]key.Binding {
 	case errorState:
 		b = append(b, []key.Binding{ui.common.KeyMap.Back})
 	case readyState:
+		if ui.showSwitcher {
+			return ui.switcher.FullHelp()
+		}
+		if ui.showCreate {
+			return ui.creator.FullHelp()
+		}
 		b = append(b, ui.pages[ui.activePage].FullHelp()...)
 	}
 	h := []key.Binding{
 		ui.common.KeyMap.Help,
 	}
+	if ui.activePage == repoPage {
+		h = append(h, ui.common.KeyMap.Switch)
+	}
+	if ui.canCreate() {
+		h = append(h, ui.common.KeyMap.New)
+	}
+	if share.SessionFromContext(ui.common.Context()) != nil {
+		h = append(h, ui.common.KeyMap.Share)
+	}
+	if ui.broadcast != "" || ui.showMOTD() {
+		h = append(h, ui.common.KeyMap.Dismiss)
+	}
 	if !ui.IsFiltering() {
 		h = append(h, ui.common.KeyMap.Quit)
 	}
@@ -120,9 +235,12 @@ func (ui *UI) FullHelp() [][]key.Binding {
 // SetSize implements common.Component.
 func (ui *UI) SetSize(width, height int) {
 	ui.common.SetSize(width, height)
+	ui.renderMOTD()
 	wm, hm := ui.getMargins()
 	ui.header.SetSize(width-wm, height-hm)
 	ui.footer.SetSize(width-wm, height-hm)
+	ui.switcher.SetSize(width-wm, height-hm)
+	ui.creator.SetSize(width-wm, height-hm)
 	for _, p := range ui.pages {
 		if p != nil {
 			p.SetSize(width-wm, height-hm)
@@ -140,8 +258,15 @@ func (ui *UI) Init() tea.Cmd {
 		ui.pages[selectionPage].Init(),
 		ui.pages[repoPage].Init(),
 	)
-	if ui.initialRepo != "" {
-		cmds = append(cmds, ui.initialRepoCmd(ui.initialRepo))
+	// The initial repository is opened once the previous session state is
+	// loaded.
+	cmds = append(cmds, ui.loadSessionCmd, ui.maintenanceCmd(""), ui.motdCmd)
+	if ui.announcements == nil {
+		ui.announcements = ui.common.Backend().SubscribeAnnouncements(ui.common.Context())
+		cmds = append(cmds, ui.listenAnnouncementsCmd)
+	}
+	if presence.ClientFromContext(ui.common.Context()) != nil {
+		cmds = append(cmds, ui.listenPresenceCmd)
 	}
 	ui.state = readyState
 	ui.SetSize(ui.common.Width, ui.common.Height)
@@ -162,6 +287,26 @@ func (ui *UI) IsFiltering() bool {
 func (ui *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	ui.common.Logger.Debugf("msg received: %T", msg)
 	cmds := make([]tea.Cmd, 0)
+	// The switcher gets all the input while it's shown.
+	if ui.showSwitcher {
+		switch msg.(type) {
+		case tea.KeyMsg:
+			_, cmd := ui.switcher.Update(msg)
+			return ui, cmd
+		case tea.MouseMsg:
+			return ui, nil
+		}
+	}
+	// So does the create dialog.
+	if ui.showCreate {
+		switch msg.(type) {
+		case tea.KeyMsg:
+			_, cmd := ui.creator.Update(msg)
+			return ui, cmd
+		case tea.MouseMsg:
+			return ui, nil
+		}
+	}
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		ui.SetSize(msg.Width, msg.Height)
@@ -183,6 +328,22 @@ func (ui *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				ui.showFooter = ui.footer.ShowAll()
 			case key.Matches(msg, ui.common.KeyMap.Help):
 				cmds = append(cmds, footer.ToggleFooterCmd)
+			case key.Matches(msg, ui.common.KeyMap.Share) && !ui.IsFiltering():
+				cmds = append(cmds, ui.toggleShareCmd)
+			case key.Matches(msg, ui.common.KeyMap.Dismiss) && !ui.IsFiltering() &&
+				(ui.broadcast != "" || ui.showMOTD()):
+				// The broadcast is on top of the message of the day.
+				if ui.broadcast != "" {
+					ui.broadcast = ""
+				} else {
+					ui.motdDismissed = true
+				}
+				ui.SetSize(ui.common.Width, ui.common.Height)
+				return ui, nil
+			case ui.activePage == repoPage && ui.error == nil && key.Matches(msg, ui.common.KeyMap.Switch):
+				cmds = append(cmds, ui.switcherReposCmd(ui.SessionState().Repo))
+			case ui.canCreate() && !ui.IsFiltering() && ui.error == nil && key.Matches(msg, ui.common.KeyMap.New):
+				cmds = append(cmds, ui.createTemplatesCmd)
 			case key.Matches(msg, ui.common.KeyMap.Quit):
 				if !ui.IsFiltering() {
 					// Stop bubblezone background workers.
@@ -191,8 +352,17 @@ func (ui *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case ui.activePage == repoPage && key.Matches(msg, ui.common.KeyMap.Back):
 				ui.activePage = selectionPage
+				// The refresh is canceled when opening a repository before
+				// it's done.
+				if s, ok := ui.pages[selectionPage].(*selection.Selection); ok && !s.Loaded() {
+					cmds = append(cmds, s.Refresh())
+				}
+				if pc := presence.ClientFromContext(ui.common.Context()); pc != nil {
+					pc.Move(presence.Location{})
+				}
 				// Always show the footer on selection page.
 				ui.showFooter = true
+				cmds = append(cmds, ui.maintenanceCmd(""))
 			}
 		case tea.MouseMsg:
 			switch msg.Type {
@@ -212,13 +382,105 @@ func (ui *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case repo.RepoMsg:
 		ui.common.SetValue(common.RepoKey, msg)
 		ui.activePage = repoPage
+		// The selection page doesn't get messages anymore.
+		if s, ok := ui.pages[selectionPage].(*selection.Selection); ok {
+			s.Cancel()
+		}
 		// Show the footer on repo page if show all is set.
 		ui.showFooter = ui.footer.ShowAll()
-		cmds = append(cmds, repo.UpdateRefCmd(msg))
+		cmds = append(cmds, repo.UpdateRefCmd(msg), ui.maintenanceCmd(msg.Name()))
+		if rs := ui.resume; rs != nil && rs.Repo == msg.Name() {
+			cmds = append(cmds, func() tea.Msg {
+				return repo.ResumeMsg{Tab: rs.Tab, Position: rs.Position}
+			})
+		}
+		ui.resume = nil
+	case sessionStateMsg:
+		ui.sessionLoaded = true
+		rn := ui.initialRepo
+		if msg.Repo != "" && (rn == "" || rn == msg.Repo) {
+			rs := proto.SessionState(msg)
+			ui.resume = &rs
+			rn = msg.Repo
+		}
+		if rn != "" {
+			cmds = append(cmds, ui.initialRepoCmd(rn))
+		}
+	case saveSessionMsg:
+		if int(msg) == ui.sessionSeq {
+			cmds = append(cmds, ui.saveSessionCmd(ui.session))
+		}
+	case switcherReposMsg:
+		if ui.activePage == repoPage {
+			ui.showSwitcher = true
+			cmds = append(cmds, ui.switcher.SetRepos(msg))
+		}
+	case createTemplatesMsg:
+		if ui.activePage == selectionPage {
+			ui.showCreate = true
+			cmds = append(cmds, ui.creator.SetTemplates(msg))
+		}
+	case create.CloseMsg:
+		ui.showCreate = false
+	case create.CreateMsg:
+		cmds = append(cmds, ui.createRepoCmd(msg))
+	case createErrorMsg:
+		cmds = append(cmds, ui.creator.SetError(msg.error))
+	case createdMsg:
+		ui.showCreate = false
+		// The new repository is listed once back on the selection page.
+		if s, ok := ui.pages[selectionPage].(*selection.Selection); ok {
+			cmds = append(cmds, s.Refresh())
+		}
+		cmds = append(cmds, ui.setRepoCmd(string(msg)))
+	case switcher.CloseMsg:
+		ui.showSwitcher = false
+	case switcher.SelectMsg:
+		ui.showSwitcher = false
+		// Keep the active tab of the current repository.
+		st := ui.SessionState()
+		ui.resume = &proto.SessionState{Repo: string(msg), Tab: st.Tab}
+		cmds = append(cmds, ui.setRepoCmd(string(msg)))
+	case repo.PresenceMsg:
+		cmds = append(cmds, ui.listenPresenceCmd)
+	case ShareMsg:
+		ui.shareCode = string(msg)
+	case maintenanceMsg:
+		if ui.maintenance != string(msg) {
+			ui.maintenance = string(msg)
+			ui.SetSize(ui.common.Width, ui.common.Height)
+		}
+	case motdMsg:
+		ui.setMOTD(string(msg))
+	case announcementMsg:
+		switch msg.Type {
+		case events.MOTD:
+			ui.setMOTD(msg.Message)
+		case events.Broadcast:
+			ui.broadcast = "Message from the admins: " + msg.Message
+			if msg.Username != "" {
+				ui.broadcast = fmt.Sprintf("Message from %s: %s", msg.Username, msg.Message)
+			}
+			ui.SetSize(ui.common.Width, ui.common.Height)
+		}
+		cmds = append(cmds, ui.listenAnnouncementsCmd)
+	case ShutdownMsg:
+		ui.shutdownAt = time.Time(msg)
+		ui.SetSize(ui.common.Width, ui.common.Height)
+		cmds = append(cmds, shutdownTickCmd())
+	case shutdownTickMsg:
+		// Keep the countdown of the banner up to date.
+		if time.Until(ui.shutdownAt) > 0 {
+			cmds = append(cmds, shutdownTickCmd())
+		}
 	case common.ErrorMsg:
 		ui.error = msg
 		ui.state = errorState
 		ui.showFooter = true
+	case selection.OpenRepoMsg:
+		if ui.activePage == selectionPage {
+			cmds = append(cmds, ui.setRepoCmd(string(msg)))
+		}
 	case selector.SelectMsg:
 		switch msg.IdentifiableItem.(type) {
 		case selection.Item:
@@ -246,6 +508,13 @@ func (ui *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	// This fixes determining the height margin of the footer.
 	ui.SetSize(ui.common.Width, ui.common.Height)
+	if cmd := ui.trackSession(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	// Let admins listing the sessions see where the user is.
+	if sess := sessions.SessionFromContext(ui.common.Context()); sess != nil {
+		sess.SetLocation(ui.SessionState())
+	}
 	return ui, tea.Batch(cmds...)
 }
 
@@ -269,12 +538,39 @@ func (ui *UI) View() string {
 			Render(err)
 	case readyState:
 		view = ui.pages[ui.activePage].View()
+		if ui.showSwitcher {
+			view = lipgloss.Place(ui.common.Width-wm, ui.common.Height-hm,
+				lipgloss.Center, lipgloss.Center,
+				ui.switcher.View(),
+			)
+		}
+		if ui.showCreate {
+			view = lipgloss.Place(ui.common.Width-wm, ui.common.Height-hm,
+				lipgloss.Center, lipgloss.Center,
+				ui.creator.View(),
+			)
+		}
 	default:
 		view = "Unknown state :/ this is a bug!"
 	}
 	if ui.activePage == selectionPage {
 		view = lipgloss.JoinVertical(lipgloss.Left, ui.header.View(), view)
 	}
+	if ui.showMOTD() {
+		view = lipgloss.JoinVertical(lipgloss.Left, ui.motdView, view)
+	}
+	if ui.broadcast != "" {
+		view = lipgloss.JoinVertical(lipgloss.Left, ui.broadcastView(), view)
+	}
+	if ui.shareCode != "" {
+		view = lipgloss.JoinVertical(lipgloss.Left, ui.shareView(), view)
+	}
+	if ui.maintenance != "" {
+		view = lipgloss.JoinVertical(lipgloss.Left, ui.maintenanceView(), view)
+	}
+	if !ui.shutdownAt.IsZero() {
+		view = lipgloss.JoinVertical(lipgloss.Left, ui.shutdownView(), view)
+	}
 	if ui.showFooter {
 		view = lipgloss.JoinVertical(lipgloss.Left, view, ui.footer.View())
 	}
@@ -283,6 +579,255 @@ func (ui *UI) View() string {
 	)
 }
 
+// ShareMsg is sent when the session sharing state changes. It contains the
+// join code, or an empty string when the session is no longer shared.
+type ShareMsg string
+
+// maintenanceMsg is the maintenance notice of the server or of the
+// repository, empty when neither is in maintenance mode. Read-only replicas
+// show where to push instead.
+type maintenanceMsg string
+
+// maintenanceCmd checks the maintenance mode of the server and of repo, if
+// it isn't empty.
+func (ui *UI) maintenanceCmd(repo string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := ui.common.Context()
+		err := ui.common.Backend().CheckMaintenance(ctx, repo)
+		var me *proto.MaintenanceError
+		var re *proto.ReplicaError
+		if !errors.As(err, &me) && !errors.As(err, &re) {
+			if err != nil {
+				ui.common.Logger.Error("failed to check maintenance mode", "repo", repo, "err", err)
+			}
+			return maintenanceMsg("")
+		}
+
+		msg := err.Error()
+		return maintenanceMsg(strings.ToUpper(msg[:1]) + msg[1:])
+	}
+}
+
+// motdMsg is the message of the day loaded when the UI starts.
+type motdMsg string
+
+func (ui *UI) motdCmd() tea.Msg {
+	motd, err := ui.common.Backend().MOTD(ui.common.Context())
+	if err != nil {
+		ui.common.Logger.Error("failed to get the message of the day", "err", err)
+	}
+	return motdMsg(motd)
+}
+
+// announcementMsg is a change of the message of the day, or a broadcast.
+type announcementMsg events.Event
+
+// listenAnnouncementsCmd waits for the next announcement.
+func (ui *UI) listenAnnouncementsCmd() tea.Msg {
+	e, ok := <-ui.announcements.Events()
+	if !ok {
+		return nil
+	}
+
+	return announcementMsg(e)
+}
+
+// ShutdownMsg is sent when the server starts shutting down. It contains the
+// time the session is closed at.
+type ShutdownMsg time.Time
+
+// shutdownTickMsg refreshes the countdown of the shutdown banner.
+type shutdownTickMsg struct{}
+
+func shutdownTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return shutdownTickMsg{}
+	})
+}
+
+func (ui *UI) toggleShareCmd() tea.Msg {
+	sess := share.SessionFromContext(ui.common.Context())
+	if sess == nil {
+		return nil
+	}
+
+	if sess.Code() != "" {
+		sess.Unshare()
+		return ShareMsg("")
+	}
+
+	code, err := sess.Share(share.DefaultTTL)
+	if err != nil {
+		return common.ErrorMsg(err)
+	}
+
+	return ShareMsg(code)
+}
+
+// listenPresenceCmd waits for other users to change their location.
+func (ui *UI) listenPresenceCmd() tea.Msg {
+	pc := presence.ClientFromContext(ui.common.Context())
+	if pc == nil {
+		return nil
+	}
+
+	if _, ok := <-pc.Events(); !ok {
+		return nil
+	}
+
+	return repo.PresenceMsg{}
+}
+
+func (ui *UI) shareView() string {
+	var cmd string
+	if cfg := ui.common.Config(); cfg != nil {
+		cmd = common.JoinCmd(cfg.SSH.PublicURL, ui.shareCode)
+	}
+	msg := fmt.Sprintf("Sharing read-only, join with: %s", cmd)
+	return ui.common.Styles.ShareBanner.Render(
+		common.TruncateString(msg, ui.common.Width-
+			ui.common.Styles.App.GetHorizontalFrameSize()-
+			ui.common.Styles.ShareBanner.GetHorizontalFrameSize()),
+	)
+}
+
+func (ui *UI) maintenanceView() string {
+	return ui.common.Styles.MaintenanceBanner.Render(
+		common.TruncateString(ui.maintenance, ui.common.Width-
+			ui.common.Styles.App.GetHorizontalFrameSize()-
+			ui.common.Styles.MaintenanceBanner.GetHorizontalFrameSize()),
+	)
+}
+
+func (ui *UI) broadcastView() string {
+	return ui.common.Styles.BroadcastBanner.Render(
+		common.TruncateString(ui.broadcast, ui.common.Width-
+			ui.common.Styles.App.GetHorizontalFrameSize()-
+			ui.common.Styles.BroadcastBanner.GetHorizontalFrameSize()),
+	)
+}
+
+// setMOTD shows a new message of the day, even if the previous one was
+// dismissed.
+func (ui *UI) setMOTD(motd string) {
+	if motd == ui.motd {
+		return
+	}
+	ui.motd = motd
+	ui.motdDismissed = false
+	ui.motdWidth = 0
+	ui.SetSize(ui.common.Width, ui.common.Height)
+}
+
+func (ui *UI) showMOTD() bool {
+	return ui.motdView != "" && !ui.motdDismissed
+}
+
+// renderMOTD renders the message of the day when it or the width changes.
+// It's cut at maxMOTDHeight lines.
+func (ui *UI) renderMOTD() {
+	width := ui.common.Width -
+		ui.common.Styles.App.GetHorizontalFrameSize() -
+		ui.common.Styles.MOTD.GetHorizontalFrameSize()
+	if width == ui.motdWidth {
+		return
+	}
+	ui.motdWidth = width
+	ui.motdView = ""
+	if ui.motd == "" || width <= 0 {
+		return
+	}
+
+	md, err := common.RenderMarkdown(ui.motd, width)
+	if err != nil {
+		ui.common.Logger.Debugf("ui: failed to render the message of the day: %v", err)
+		md = ui.motd
+	}
+	lines := strings.Split(strings.Trim(md, "\n"), "\n")
+	if len(lines) > maxMOTDHeight {
+		lines = append(lines[:maxMOTDHeight-1], "…")
+	}
+	ui.motdView = ui.common.Styles.MOTD.Render(strings.Join(lines, "\n"))
+}
+
+func (ui *UI) shutdownView() string {
+	left := time.Until(ui.shutdownAt).Round(time.Second)
+	if left < 0 {
+		left = 0
+	}
+	msg := fmt.Sprintf("The server is shutting down, this session closes in %s", left)
+	return ui.common.Styles.ShutdownBanner.Render(
+		common.TruncateString(msg, ui.common.Width-
+			ui.common.Styles.App.GetHorizontalFrameSize()-
+			ui.common.Styles.ShutdownBanner.GetHorizontalFrameSize()),
+	)
+}
+
+// SessionState returns the current state of the session.
+func (ui *UI) SessionState() proto.SessionState {
+	if ui.resume != nil {
+		return *ui.resume
+	}
+	if ui.activePage != repoPage {
+		return proto.SessionState{}
+	}
+	r, ok := ui.pages[repoPage].(*repo.Repo)
+	if !ok {
+		return proto.SessionState{}
+	}
+	return r.SessionState()
+}
+
+// trackSession schedules saving the session state when it changes. Admins
+// impersonating a user don't change their session state.
+func (ui *UI) trackSession() tea.Cmd {
+	ctx := ui.common.Context()
+	if !ui.sessionLoaded || proto.UserFromContext(ctx) == nil || proto.ImpersonatorFromContext(ctx) != nil {
+		return nil
+	}
+	st := ui.SessionState()
+	if st == ui.session {
+		return nil
+	}
+	ui.session = st
+	ui.sessionSeq++
+	seq := ui.sessionSeq
+	return tea.Tick(sessionSaveDelay, func(time.Time) tea.Msg {
+		return saveSessionMsg(seq)
+	})
+}
+
+func (ui *UI) loadSessionCmd() tea.Msg {
+	ctx := ui.common.Context()
+	user := proto.UserFromContext(ctx)
+	if user == nil {
+		return sessionStateMsg{}
+	}
+	be := ui.common.Backend()
+	st, err := be.SessionState(ctx, user)
+	if err != nil {
+		ui.common.Logger.Debugf("ui: failed to load session state: %v", err)
+	}
+	// Don't resume repositories that were removed or that the user can no
+	// longer access.
+	if st.Repo != "" {
+		if _, err := ui.openRepo(st.Repo); err != nil || be.AccessLevelForUser(ctx, st.Repo, user) < access.ReadOnlyAccess {
+			st = proto.SessionState{}
+		}
+	}
+	return sessionStateMsg(st)
+}
+
+func (ui *UI) saveSessionCmd(st proto.SessionState) tea.Cmd {
+	return func() tea.Msg {
+		ctx := ui.common.Context()
+		if err := ui.common.Backend().SetSessionState(ctx, proto.UserFromContext(ctx), st); err != nil {
+			ui.common.Logger.Debugf("ui: failed to save session state: %v", err)
+		}
+		return nil
+	}
+}
+
 func (ui *UI) openRepo(rn string) (proto.Repository, error) {
 	cfg := ui.common.Config()
 	if cfg == nil {
@@ -304,6 +849,95 @@ func (ui *UI) openRepo(rn string) (proto.Repository, error) {
 	return nil, common.ErrMissingRepo
 }
 
+// switcherReposCmd lists the repositories the user can switch to, except the
+// current one.
+func (ui *UI) switcherReposCmd(current string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := ui.common.Context()
+		be := ui.common.Backend()
+		repos, err := be.Repositories(ctx)
+		if err != nil {
+			return common.ErrorMsg(err)
+		}
+
+		names := make([]string, 0, len(repos))
+		for _, r := range repos {
+			if r.IsHidden() || r.Name() == current {
+				continue
+			}
+			if ui.common.AccessLevel(r.Name()) >= access.ReadOnlyAccess {
+				names = append(names, r.Name())
+			}
+		}
+		sort.Strings(names)
+
+		return switcherReposMsg(names)
+	}
+}
+
+// canCreate returns true if the user can create repositories from the
+// selection page.
+func (ui *UI) canCreate() bool {
+	return ui.activePage == selectionPage && proto.UserFromContext(ui.common.Context()) != nil
+}
+
+// createTemplatesCmd lists the template repositories the user can read.
+func (ui *UI) createTemplatesCmd() tea.Msg {
+	ctx := ui.common.Context()
+	repos, err := ui.common.Backend().Repositories(ctx)
+	if err != nil {
+		return common.ErrorMsg(err)
+	}
+
+	names := make([]string, 0)
+	for _, r := range repos {
+		if !r.IsTemplate() || r.IsHidden() {
+			continue
+		}
+		if ui.common.AccessLevel(r.Name()) >= access.ReadOnlyAccess {
+			names = append(names, r.Name())
+		}
+	}
+	sort.Strings(names)
+
+	return createTemplatesMsg(names)
+}
+
+// createRepoCmd creates a repository owned by the user, from a template if
+// one is picked, and with the picked initial files.
+func (ui *UI) createRepoCmd(msg create.CreateMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx := ui.common.Context()
+		be := ui.common.Backend()
+		user := proto.UserFromContext(ctx)
+		if ui.common.AccessLevel(msg.Name) < access.ReadWriteAccess {
+			return createErrorMsg{proto.ErrUnauthorized}
+		}
+
+		opts := proto.RepositoryOptions{
+			Readme:    msg.Readme,
+			Gitignore: msg.Gitignore,
+			License:   msg.License,
+		}
+
+		var r proto.Repository
+		var err error
+		if msg.Template != "" {
+			if ui.common.AccessLevel(msg.Template) < access.ReadOnlyAccess {
+				return createErrorMsg{proto.ErrUnauthorized}
+			}
+			r, err = be.CreateRepositoryFromTemplate(ctx, msg.Name, msg.Template, user, opts)
+		} else {
+			r, err = be.CreateRepository(ctx, msg.Name, user, opts)
+		}
+		if err != nil {
+			return createErrorMsg{err}
+		}
+
+		return createdMsg(r.Name())
+	}
+}
+
 func (ui *UI) setRepoCmd(rn string) tea.Cmd {
 	return func() tea.Msg {
 		r, err := ui.openRepo(rn)
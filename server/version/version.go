@@ -0,0 +1,12 @@
+// Package version holds the version of the running Soft Serve binary, for the
+// server to report it.
+package version
+
+var (
+	// Version is the version of Soft Serve. It's set by the soft command.
+	Version = "unknown (built from source)"
+
+	// CommitSHA is the SHA of the commit Soft Serve was built against, empty
+	// if unknown.
+	CommitSHA = ""
+)
@@ -0,0 +1,292 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// maxMarkdownSize is the maximum size of the markdown rendered by the API.
+const maxMarkdownSize = 1 << 20 // 1 MiB
+
+// maxAPIRequestSize is the maximum size of the JSON body of API requests.
+const maxAPIRequestSize = 1 << 20 // 1 MiB
+
+// defaultMarkdownWidth is the width ANSI markdown is wrapped at by default.
+const defaultMarkdownWidth = 80
+
+var apiMarkdownCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "api_markdown_total",
+	Help:      "The total number of markdown API requests",
+}, []string{"format"})
+
+// APIError is the body of API error responses.
+type APIError struct {
+	Message string `json:"message"`
+}
+
+// MarkdownRequest is the body of a markdown API request.
+type MarkdownRequest struct {
+	// Text is the markdown to render.
+	Text string `json:"text"`
+	// Format is either "html" (the default) or "ansi".
+	Format string `json:"format"`
+	// Width is the width ANSI markdown is wrapped at.
+	Width int `json:"width"`
+}
+
+// APIController registers the routes of the HTTP API. It must be registered
+// before GitController, repository routes match any path.
+func APIController(_ context.Context, r *mux.Router) {
+	r.Handle("/.well-known/webfinger", withAPIAccess(http.HandlerFunc(serviceWebFinger))).
+		Methods(http.MethodGet)
+
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Handle("/markdown", withAPIAccess(http.HandlerFunc(serviceMarkdown))).
+		Methods(http.MethodPost)
+	api.Handle("/repos/{repo:.+?}/markdown/{path:.+}", withAPIAccess(http.HandlerFunc(serviceRepoMarkdown))).
+		Methods(http.MethodGet)
+	registerUserAPI(api)
+	registerAvatarAPI(api)
+	registerEventsAPI(api)
+	registerGraphQLAPI(api)
+	registerReleaseAPI(api)
+	// Repository routes go last, repository names can contain slashes.
+	registerRepoAPI(api)
+}
+
+// withAPIAccess authenticates API requests. Anonymous requests are only
+// allowed when keyless access is allowed and anonymous users have read
+// access.
+func withAPIAccess(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := log.FromContext(ctx)
+		be := backend.FromContext(ctx)
+
+		user, err := authenticate(r)
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidPassword):
+			renderAPIError(w, http.StatusForbidden, "bad credentials")
+			return
+		case errors.Is(err, proto.ErrUserSuspended):
+			renderAPIError(w, http.StatusForbidden, "user is suspended")
+			return
+		case errors.Is(err, proto.ErrAddressDenied):
+			renderAPIError(w, http.StatusForbidden, "address not allowed")
+			return
+		case errors.Is(err, proto.ErrRateLimited):
+			setRetryAfter(w, err)
+			renderAPIError(w, http.StatusTooManyRequests, err.Error())
+			return
+		case err != nil && !errors.Is(err, proto.ErrUserNotFound):
+			logger.Error("failed to authenticate", "err", err)
+		}
+
+		if user == nil && (!be.AllowKeyless(ctx) || be.AnonAccess(ctx) < access.ReadOnlyAccess) {
+			askCredentials(w, r)
+			renderAPIError(w, http.StatusUnauthorized, "credentials needed")
+			return
+		}
+
+		ctx = proto.WithUserContext(ctx, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// serviceMarkdown renders the markdown of the request body.
+func serviceMarkdown(w http.ResponseWriter, r *http.Request) {
+	var req MarkdownRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxMarkdownSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	renderMarkdown(w, r, req)
+}
+
+// serviceRepoMarkdown renders a markdown file of a repository. The ref query
+// parameter selects the revision, HEAD by default.
+func serviceRepoMarkdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+	user := proto.UserFromContext(ctx)
+
+	// Don't hint that the repo exists if the user doesn't have access.
+	repo, err := be.Repository(ctx, name)
+	if err != nil || be.AccessLevelForUser(ctx, name, user) < access.ReadOnlyAccess {
+		renderAPIError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	tree, err := rr.LsTree(ref)
+	if err != nil {
+		renderAPIError(w, http.StatusNotFound, "reference not found")
+		return
+	}
+
+	te, err := tree.TreeEntry(vars["path"])
+	if err != nil || te.Type() != "blob" {
+		renderAPIError(w, http.StatusNotFound, git.ErrFileNotFound.Error())
+		return
+	}
+
+	if te.Size() > maxMarkdownSize {
+		renderAPIError(w, http.StatusRequestEntityTooLarge, "file is too large to render")
+		return
+	}
+
+	content, err := te.Contents()
+	if err != nil {
+		logger.Error("failed to read file", "repo", name, "path", vars["path"], "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	req := MarkdownRequest{
+		Text:   string(content),
+		Format: r.URL.Query().Get("format"),
+	}
+	if width := r.URL.Query().Get("width"); width != "" {
+		req.Width, err = strconv.Atoi(width)
+		if err != nil {
+			renderAPIError(w, http.StatusBadRequest, "invalid width")
+			return
+		}
+	}
+
+	renderMarkdown(w, r, req)
+}
+
+func renderMarkdown(w http.ResponseWriter, r *http.Request, req MarkdownRequest) {
+	logger := log.FromContext(r.Context())
+	if req.Width < 0 {
+		renderAPIError(w, http.StatusBadRequest, "invalid width")
+		return
+	} else if req.Width == 0 {
+		req.Width = defaultMarkdownWidth
+	}
+
+	var out string
+	var err error
+	switch req.Format {
+	case "", "html":
+		req.Format = "html"
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		out, err = common.RenderMarkdownHTML(req.Text)
+	case "ansi":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		out, err = common.RenderMarkdown(req.Text, req.Width)
+	default:
+		renderAPIError(w, http.StatusBadRequest, "invalid format: must be html or ansi")
+		return
+	}
+
+	if err != nil {
+		logger.Error("failed to render markdown", "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	apiMarkdownCounter.WithLabelValues(req.Format).Inc()
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(out)) // nolint: errcheck
+}
+
+// renderAPIJSON writes v as the JSON body of the response.
+func renderAPIJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("error encoding json", "err", err)
+	}
+}
+
+// decodeJSON decodes the JSON body of the request into v. It writes an error
+// response and returns false when the body is invalid.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAPIRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		renderAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// renderAPIErr writes the error response of err, unexpected errors are
+// logged and hidden from the client.
+func renderAPIErr(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	switch {
+	case errors.Is(err, proto.ErrRepoNotFound),
+		errors.Is(err, proto.ErrUserNotFound),
+		errors.Is(err, proto.ErrFileNotFound),
+		errors.Is(err, proto.ErrTokenNotFound),
+		errors.Is(err, proto.ErrReleaseNotFound),
+		errors.Is(err, git.ErrFileNotFound),
+		errors.Is(err, git.ErrReferenceNotExist),
+		errors.Is(err, db.ErrRecordNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, proto.ErrRepoExist),
+		errors.Is(err, proto.ErrReleaseExist),
+		errors.Is(err, proto.ErrPublicKeyInUse),
+		errors.Is(err, db.ErrDuplicateKey):
+		status = http.StatusConflict
+	case errors.Is(err, proto.ErrUnauthorized),
+		errors.Is(err, proto.ErrTwoFactorRequired),
+		errors.Is(err, proto.ErrInvalidTwoFactorCode),
+		errors.Is(err, proto.ErrBranchProtected),
+		errors.Is(err, proto.ErrDefaultBranch),
+		errors.Is(err, proto.ErrRefRestricted):
+		status = http.StatusForbidden
+	case errors.Is(err, access.ErrInvalidAccessLevel):
+		status = http.StatusBadRequest
+	case errors.Is(err, proto.ErrRateLimited):
+		setRetryAfter(w, err)
+		status = http.StatusTooManyRequests
+	case errors.Is(err, proto.ErrServerBusy):
+		status = http.StatusServiceUnavailable
+	default:
+		log.FromContext(r.Context()).Error("api request failed", "path", r.URL.Path, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	renderAPIError(w, status, err.Error())
+}
+
+func renderAPIError(w http.ResponseWriter, statusCode int, msg string) {
+	renderAPIJSON(w, statusCode, APIError{Message: msg})
+}
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/events"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var eventStreamsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "event_streams",
+	Help:      "The number of open event streams",
+})
+
+// eventsKeepAlive is how often a comment is sent to idle event streams, so
+// proxies don't close them.
+const eventsKeepAlive = 30 * time.Second
+
+func registerEventsAPI(api *mux.Router) {
+	api.Handle("/events", withAPIAccess(http.HandlerFunc(serviceEvents))).
+		Methods(http.MethodGet)
+}
+
+// isEventStream returns true if the request asks for a stream of server-sent
+// events.
+func isEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// serviceEvents streams the repository events as server-sent events. The
+// repo query parameter only streams the events of a repository. Clients
+// resuming a stream with the Last-Event-ID header get the recent events they
+// missed first.
+// https://html.spec.whatwg.org/multipage/server-sent-events.html
+func serviceEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	var repo string
+	if q := r.URL.Query().Get("repo"); q != "" {
+		repo = utils.SanitizeRepo(q)
+	}
+
+	var lastID int64
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		var err error
+		lastID, err = strconv.ParseInt(id, 10, 64)
+		if err != nil || lastID < 0 {
+			renderAPIError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		logger.Errorf("expected http.ResponseWriter to be an http.Flusher, got %T", w)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	sub := be.SubscribeEvents(lastID)
+	defer sub.Close()
+	eventStreamsGauge.Inc()
+	defer eventStreamsGauge.Dec()
+
+	// Streams outlive the server write timeout, the deadline is pushed back
+	// before every write.
+	rc := http.NewResponseController(w)
+	write := func(s string) bool {
+		rc.SetWriteDeadline(time.Now().Add(eventsKeepAlive)) // nolint: errcheck
+		if _, err := fmt.Fprint(w, s); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if !write(": connected\n\n") {
+		return
+	}
+
+	ticker := time.NewTicker(eventsKeepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !write(": ping\n\n") {
+				return
+			}
+		case e, ok := <-sub.Events():
+			// The subscription is closed when the client falls behind, it
+			// reconnects and resumes from the last event it got.
+			if !ok {
+				return
+			}
+			if repo != "" && e.Repo != repo && e.From != repo {
+				continue
+			}
+			if !canSeeEvent(r, user, e) {
+				continue
+			}
+
+			data, err := json.Marshal(e)
+			if err != nil {
+				logger.Error("failed to marshal event", "err", err)
+				continue
+			}
+			if !write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)) {
+				return
+			}
+		}
+	}
+}
+
+// canSeeEvent returns true if user can read the repository of e. Events of
+// repositories that no longer exist are shown to admins, and to everyone if
+// the repository was public.
+func canSeeEvent(r *http.Request, user proto.User, e events.Event) bool {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	if _, err := be.Repository(ctx, e.Repo); err != nil {
+		return e.Public || be.AccessLevelForUser(ctx, e.Repo, user) >= access.AdminAccess
+	}
+
+	return be.AccessLevelForUser(ctx, e.Repo, user) >= access.ReadOnlyAccess
+}
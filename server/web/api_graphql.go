@@ -0,0 +1,474 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/graphql"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var apiGraphQLCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "api_graphql_total",
+	Help:      "The total number of GraphQL API requests",
+})
+
+const (
+	// gqlDefaultFirst is the number of items of lists by default.
+	gqlDefaultFirst = 30
+	// gqlMaxFirst is the maximum number of items of lists.
+	gqlMaxFirst = 100
+)
+
+// gqlSchema is the schema of the GraphQL API.
+var gqlSchema = newGQLSchema()
+
+func registerGraphQLAPI(api *mux.Router) {
+	api.Handle("/graphql", withAPIAccess(http.HandlerFunc(serviceGraphQL))).
+		Methods(http.MethodGet, http.MethodPost)
+}
+
+// serviceGraphQL executes a GraphQL query. Queries are read from the JSON
+// body of POST requests, or from the query, variables, and operationName
+// query parameters of GET requests.
+// https://graphql.org/learn/serving-over-http/
+func serviceGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				renderAPIError(w, http.StatusBadRequest, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	} else if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Query == "" {
+		renderAPIError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	apiGraphQLCounter.Inc()
+	res := gqlSchema.Execute(r.Context(), req)
+	status := http.StatusOK
+	if res.Data == nil {
+		status = http.StatusBadRequest
+	}
+	renderAPIJSON(w, status, res)
+}
+
+// gqlRepo is a repository of the GraphQL API.
+type gqlRepo struct {
+	repo proto.Repository
+	rr   *git.Repository
+}
+
+// open returns the git repository, it's opened once.
+func (g *gqlRepo) open() (*git.Repository, error) {
+	if g.rr == nil {
+		rr, err := g.repo.Open()
+		if err != nil {
+			return nil, err
+		}
+		g.rr = rr
+	}
+	return g.rr, nil
+}
+
+// gqlRef is a branch or a tag of the GraphQL API.
+type gqlRef struct {
+	repo *gqlRepo
+	name string
+}
+
+// gqlCommit is a commit of the GraphQL API.
+type gqlCommit struct {
+	repo   *gqlRepo
+	commit *git.Commit
+}
+
+// gqlEntry is a tree entry of the GraphQL API.
+type gqlEntry struct {
+	path  string
+	entry *git.TreeEntry
+}
+
+// gqlFirst returns the number of items of a list.
+func gqlFirst(args graphql.Args) int {
+	n := args.Int("first", gqlDefaultFirst)
+	switch {
+	case n < 0:
+		return 0
+	case n > gqlMaxFirst:
+		return gqlMaxFirst
+	}
+	return n
+}
+
+// gqlErr hides unexpected errors from the client.
+func gqlErr(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, proto.ErrServerBusy):
+		return err
+	default:
+		log.FromContext(ctx).Error("failed to resolve graphql field", "err", err)
+		return errors.New("internal server error")
+	}
+}
+
+// gqlRepository returns the repository name, nil when the user can't read
+// it.
+func gqlRepository(ctx context.Context, name string) *gqlRepo {
+	be := backend.FromContext(ctx)
+	name = utils.SanitizeRepo(name)
+	repo, err := be.Repository(ctx, name)
+	if err != nil || be.AccessLevelForUser(ctx, name, proto.UserFromContext(ctx)) < access.ReadOnlyAccess {
+		return nil
+	}
+	return &gqlRepo{repo: repo}
+}
+
+// gqlCatCommit returns the commit at rev.
+func gqlCatCommit(repo *gqlRepo, rev string) (*gqlCommit, error) {
+	rr, err := repo.open()
+	if err != nil {
+		return nil, err
+	}
+	c, err := rr.CatFileCommit(rev + "^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	return &gqlCommit{repo: repo, commit: &git.Commit{Commit: c, Hash: git.Hash(c.ID.String())}}, nil
+}
+
+// gqlResolve resolves a field of the GraphQL API.
+type gqlResolve = func(ctx context.Context, src interface{}, args graphql.Args) (interface{}, error)
+
+// gqlSignatureField returns the resolver of a field read from a signature.
+func gqlSignatureField(fn func(*git.Signature) interface{}) gqlResolve {
+	return func(_ context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+		return fn(src.(*git.Signature)), nil
+	}
+}
+
+// gqlCommitField returns the resolver of a field read from a commit.
+func gqlCommitField(fn func(*gqlCommit) interface{}) gqlResolve {
+	return func(_ context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+		return fn(src.(*gqlCommit)), nil
+	}
+}
+
+// gqlRefField returns the resolver of a field read from a reference.
+func gqlRefField(fn func(*gqlRef) interface{}) gqlResolve {
+	return func(_ context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+		return fn(src.(*gqlRef)), nil
+	}
+}
+
+// gqlEntryField returns the resolver of a field read from a tree entry.
+func gqlEntryField(fn func(*gqlEntry) interface{}) gqlResolve {
+	return func(_ context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+		return fn(src.(*gqlEntry)), nil
+	}
+}
+
+// gqlRepoField returns the resolver of a field read from a repository.
+func gqlRepoField(fn func(*gqlRepo) interface{}) gqlResolve {
+	return func(_ context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+		return fn(src.(*gqlRepo)), nil
+	}
+}
+
+// gqlUserField returns the resolver of a field read from a user.
+func gqlUserField(fn func(proto.User) interface{}) gqlResolve {
+	return func(_ context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+		return fn(src.(proto.User)), nil
+	}
+}
+
+func newGQLSchema() *graphql.Schema {
+	signature := &graphql.Object{Name: "Signature", Fields: map[string]*graphql.Field{
+		"name":  {Resolve: gqlSignatureField(func(s *git.Signature) interface{} { return s.Name })},
+		"email": {Resolve: gqlSignatureField(func(s *git.Signature) interface{} { return s.Email })},
+		"date":  {Resolve: gqlSignatureField(func(s *git.Signature) interface{} { return s.When })},
+	}}
+
+	commit := &graphql.Object{Name: "Commit", Fields: map[string]*graphql.Field{
+		"hash":      {Resolve: gqlCommitField(func(c *gqlCommit) interface{} { return c.commit.Hash.String() })},
+		"shortHash": {Resolve: gqlCommitField(func(c *gqlCommit) interface{} { return shortHash(c.commit.Hash.String()) })},
+		"message":   {Resolve: gqlCommitField(func(c *gqlCommit) interface{} { return c.commit.Message })},
+		"title": {Resolve: gqlCommitField(func(c *gqlCommit) interface{} {
+			title, _, _ := strings.Cut(strings.TrimSpace(c.commit.Message), "\n")
+			return strings.TrimSpace(title)
+		})},
+		"author":    {Type: signature, Resolve: gqlCommitField(func(c *gqlCommit) interface{} { return c.commit.Author })},
+		"committer": {Type: signature, Resolve: gqlCommitField(func(c *gqlCommit) interface{} { return c.commit.Committer })},
+	}}
+	commit.Fields["parents"] = &graphql.Field{
+		Type: commit,
+		Resolve: func(ctx context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+			c := src.(*gqlCommit)
+			parents := make([]*gqlCommit, 0, c.commit.ParentsCount())
+			for i := 0; i < c.commit.ParentsCount(); i++ {
+				id, err := c.commit.ParentID(i)
+				if err != nil {
+					return nil, gqlErr(ctx, err)
+				}
+				p, err := gqlCatCommit(c.repo, id.String())
+				if err != nil {
+					return nil, gqlErr(ctx, err)
+				}
+				parents = append(parents, p)
+			}
+			return parents, nil
+		},
+	}
+
+	ref := &graphql.Object{Name: "Ref", Fields: map[string]*graphql.Field{
+		"name": {Resolve: gqlRefField(func(r *gqlRef) interface{} {
+			return strings.TrimPrefix(strings.TrimPrefix(r.name, git.RefsHeads), git.RefsTags)
+		})},
+		"fullName": {Resolve: gqlRefField(func(r *gqlRef) interface{} { return r.name })},
+		"target": {
+			Type: commit,
+			Resolve: func(ctx context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+				r := src.(*gqlRef)
+				c, err := gqlCatCommit(r.repo, r.name)
+				if err != nil {
+					return nil, gqlErr(ctx, err)
+				}
+				return c, nil
+			},
+		},
+	}}
+
+	entry := &graphql.Object{Name: "TreeEntry", Fields: map[string]*graphql.Field{
+		"name": {Resolve: gqlEntryField(func(e *gqlEntry) interface{} { return e.entry.Name() })},
+		"path": {Resolve: gqlEntryField(func(e *gqlEntry) interface{} { return e.path })},
+		"type": {Resolve: gqlEntryField(func(e *gqlEntry) interface{} { return string(e.entry.Type()) })},
+		"hash": {Resolve: gqlEntryField(func(e *gqlEntry) interface{} { return e.entry.ID().String() })},
+		"size": {Resolve: gqlEntryField(func(e *gqlEntry) interface{} { return e.entry.Size() })},
+	}}
+
+	// refs returns the references of a repository with prefix.
+	refs := func(prefix string) gqlResolve {
+		return func(_ context.Context, src interface{}, args graphql.Args) (interface{}, error) {
+			repo := src.(*gqlRepo)
+			list := make([]*gqlRef, 0)
+			rr, err := repo.open()
+			if err != nil {
+				return list, nil
+			}
+			// Empty repositories don't have any references.
+			rs, _ := rr.References()
+			for _, r := range rs {
+				if len(list) == gqlFirst(args) {
+					break
+				}
+				if strings.HasPrefix(r.Name().String(), prefix) {
+					list = append(list, &gqlRef{repo: repo, name: r.Name().String()})
+				}
+			}
+			return list, nil
+		}
+	}
+
+	repository := &graphql.Object{Name: "Repository", Fields: map[string]*graphql.Field{
+		"name":        {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return r.repo.Name() })},
+		"projectName": {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return r.repo.ProjectName() })},
+		"description": {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return r.repo.Description() })},
+		"visibility":  {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return string(proto.RepositoryVisibility(r.repo)) })},
+		"hidden":      {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return r.repo.IsHidden() })},
+		"mirror":      {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return r.repo.IsMirror() })},
+		"archived":    {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return r.repo.IsArchived() })},
+		"updatedAt":   {Resolve: gqlRepoField(func(r *gqlRepo) interface{} { return r.repo.UpdatedAt() })},
+		"defaultBranch": {
+			Type: ref,
+			Resolve: func(_ context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+				repo := src.(*gqlRepo)
+				rr, err := repo.open()
+				if err != nil {
+					return (*gqlRef)(nil), nil
+				}
+				// Empty repositories don't have a default branch yet.
+				head, err := rr.HEAD()
+				if err != nil {
+					return (*gqlRef)(nil), nil
+				}
+				return &gqlRef{repo: repo, name: head.Name().String()}, nil
+			},
+		},
+		"branches": {Type: ref, Args: map[string]graphql.Kind{"first": graphql.Int}, Resolve: refs(git.RefsHeads)},
+		"tags":     {Type: ref, Args: map[string]graphql.Kind{"first": graphql.Int}, Resolve: refs(git.RefsTags)},
+		"ref": {
+			Type: ref,
+			Args: map[string]graphql.Kind{"name": graphql.String},
+			Resolve: func(_ context.Context, src interface{}, args graphql.Args) (interface{}, error) {
+				repo := src.(*gqlRepo)
+				rr, err := repo.open()
+				if err != nil {
+					return (*gqlRef)(nil), nil
+				}
+				name := args.String("name", "")
+				rs, _ := rr.References()
+				for _, candidate := range []string{name, git.RefsHeads + name, git.RefsTags + name} {
+					for _, r := range rs {
+						if r.Name().String() == candidate && strings.HasPrefix(candidate, "refs/") {
+							return &gqlRef{repo: repo, name: candidate}, nil
+						}
+					}
+				}
+				return (*gqlRef)(nil), nil
+			},
+		},
+		"commit": {
+			Type: commit,
+			Args: map[string]graphql.Kind{"rev": graphql.String},
+			Resolve: func(ctx context.Context, src interface{}, args graphql.Args) (interface{}, error) {
+				repo := src.(*gqlRepo)
+				rr, err := repo.open()
+				if err != nil {
+					return (*gqlCommit)(nil), nil
+				}
+				_, gref, p, ok := webRevision(rr, args.String("rev", "HEAD"))
+				if !ok || p != "" {
+					return (*gqlCommit)(nil), nil
+				}
+				c, err := gqlCatCommit(repo, gref.Hash.String())
+				if err != nil {
+					return nil, gqlErr(ctx, err)
+				}
+				return c, nil
+			},
+		},
+		"commits": {
+			Type: commit,
+			Args: map[string]graphql.Kind{"ref": graphql.String, "first": graphql.Int},
+			Resolve: func(ctx context.Context, src interface{}, args graphql.Args) (interface{}, error) {
+				be := backend.FromContext(ctx)
+				repo := src.(*gqlRepo)
+				list := make([]*gqlCommit, 0)
+				rr, err := repo.open()
+				if err != nil {
+					return list, nil
+				}
+				_, gref, p, ok := webRevision(rr, args.String("ref", "HEAD"))
+				if !ok || p != "" || gqlFirst(args) == 0 {
+					return list, nil
+				}
+
+				release, err := be.AcquireWorker(ctx, repo.repo.Name())
+				if err != nil {
+					return nil, gqlErr(ctx, err)
+				}
+				commits, _, err := be.CommitsFrom(ctx, repo.repo, []string{gref.Hash.String()}, gqlFirst(args))
+				release()
+				if err != nil {
+					return nil, gqlErr(ctx, err)
+				}
+				for _, c := range commits {
+					list = append(list, &gqlCommit{repo: repo, commit: c})
+				}
+				return list, nil
+			},
+		},
+		"tree": {
+			Type: entry,
+			Args: map[string]graphql.Kind{"ref": graphql.String, "path": graphql.String},
+			Resolve: func(ctx context.Context, src interface{}, args graphql.Args) (interface{}, error) {
+				be := backend.FromContext(ctx)
+				repo := src.(*gqlRepo)
+				list := make([]*gqlEntry, 0)
+				rr, err := repo.open()
+				if err != nil {
+					return list, nil
+				}
+				_, gref, p, ok := webRevision(rr, args.String("ref", "HEAD"))
+				if !ok || p != "" {
+					return list, nil
+				}
+				dir := strings.Trim(path.Clean("/"+args.String("path", "")), "/")
+				ents, err := be.TreeEntries(ctx, repo.repo, gref, dir)
+				if err != nil {
+					return list, nil
+				}
+				for _, e := range ents {
+					list = append(list, &gqlEntry{path: path.Join(dir, e.Name()), entry: e})
+				}
+				return list, nil
+			},
+		},
+	}}
+
+	user := &graphql.Object{Name: "User", Fields: map[string]*graphql.Field{
+		"username": {Resolve: gqlUserField(func(u proto.User) interface{} { return u.Username() })},
+		"admin":    {Resolve: gqlUserField(func(u proto.User) interface{} { return u.IsAdmin() })},
+		"initials": {Resolve: gqlUserField(func(u proto.User) interface{} { return initials(u.Username()) })},
+		"avatarUrl": {Resolve: func(ctx context.Context, src interface{}, _ graphql.Args) (interface{}, error) {
+			return avatarURL(config.FromContext(ctx), src.(proto.User)), nil
+		}},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"viewer": {
+			Type: user,
+			Resolve: func(ctx context.Context, _ interface{}, _ graphql.Args) (interface{}, error) {
+				return proto.UserFromContext(ctx), nil
+			},
+		},
+		"repository": {
+			Type: repository,
+			Args: map[string]graphql.Kind{"name": graphql.String},
+			Resolve: func(ctx context.Context, _ interface{}, args graphql.Args) (interface{}, error) {
+				return gqlRepository(ctx, args.String("name", "")), nil
+			},
+		},
+		"repositories": {
+			Type: repository,
+			Args: map[string]graphql.Kind{"first": graphql.Int, "hidden": graphql.Boolean},
+			Resolve: func(ctx context.Context, _ interface{}, args graphql.Args) (interface{}, error) {
+				be := backend.FromContext(ctx)
+				user := proto.UserFromContext(ctx)
+				repos, err := be.Repositories(ctx)
+				if err != nil {
+					return nil, gqlErr(ctx, err)
+				}
+				list := make([]*gqlRepo, 0)
+				for _, repo := range repos {
+					if len(list) == gqlFirst(args) {
+						break
+					}
+					if repo.IsHidden() && !args.Bool("hidden", false) {
+						continue
+					}
+					if be.AccessLevelForUser(ctx, repo.Name(), user) >= access.ReadOnlyAccess {
+						list = append(list, &gqlRepo{repo: repo})
+					}
+				}
+				return list, nil
+			},
+		},
+	}}
+
+	return &graphql.Schema{Query: query, MaxDepth: 10, MaxFields: 10000}
+}
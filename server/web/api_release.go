@@ -0,0 +1,265 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/gorilla/mux"
+)
+
+// APIRelease is a release of a repository in API responses.
+type APIRelease struct {
+	Tag       string            `json:"tag"`
+	Name      string            `json:"name"`
+	Notes     string            `json:"notes"`
+	Author    string            `json:"author,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Assets    []APIReleaseAsset `json:"assets,omitempty"`
+}
+
+// APIReleaseAsset is a release asset in API responses.
+type APIReleaseAsset struct {
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	DownloadURL string    `json:"download_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// APIReleaseRequest is the body of release create and update requests. The
+// tag is only used on create, fields that aren't set are left unchanged on
+// update.
+type APIReleaseRequest struct {
+	Tag   string  `json:"tag"`
+	Name  *string `json:"name"`
+	Notes *string `json:"notes"`
+}
+
+func registerReleaseAPI(api *mux.Router) {
+	api.Handle("/repos/{repo:.+?}/releases", withAPIAccess(http.HandlerFunc(serviceListReleases))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+?}/releases", withAPIAccess(http.HandlerFunc(serviceCreateRelease))).
+		Methods(http.MethodPost)
+	// Asset routes go first, tags can contain slashes.
+	api.Handle("/repos/{repo:.+?}/releases/{tag:.+}/assets/{name}", withAPIAccess(http.HandlerFunc(serviceUploadReleaseAsset))).
+		Methods(http.MethodPut)
+	api.Handle("/repos/{repo:.+?}/releases/{tag:.+}/assets/{name}", withAPIAccess(http.HandlerFunc(serviceDeleteReleaseAsset))).
+		Methods(http.MethodDelete)
+	api.Handle("/repos/{repo:.+?}/releases/{tag:.+}", withAPIAccess(http.HandlerFunc(serviceGetRelease))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+?}/releases/{tag:.+}", withAPIAccess(http.HandlerFunc(serviceUpdateRelease))).
+		Methods(http.MethodPatch)
+	api.Handle("/repos/{repo:.+?}/releases/{tag:.+}", withAPIAccess(http.HandlerFunc(serviceDeleteRelease))).
+		Methods(http.MethodDelete)
+}
+
+func newAPIRelease(be *backend.Backend, repo string, rel proto.Release) APIRelease {
+	ar := APIRelease{
+		Tag:       rel.Tag,
+		Name:      rel.Name,
+		Notes:     rel.Notes,
+		Author:    rel.Username,
+		CreatedAt: rel.CreatedAt,
+		UpdatedAt: rel.UpdatedAt,
+	}
+	for _, a := range rel.Assets {
+		ar.Assets = append(ar.Assets, newAPIReleaseAsset(be, repo, rel.Tag, a))
+	}
+
+	return ar
+}
+
+func newAPIReleaseAsset(be *backend.Backend, repo string, tag string, a proto.ReleaseAsset) APIReleaseAsset {
+	return APIReleaseAsset{
+		Name:        a.Name,
+		Size:        a.Size,
+		SHA256:      a.SHA256,
+		DownloadURL: be.ReleaseAssetURL(repo, tag, a.Name),
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// serviceListReleases lists the releases of a repository, newest first.
+func serviceListReleases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadOnlyAccess)
+	if !ok {
+		return
+	}
+
+	rels, err := be.Releases(ctx, repo.Name())
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	ars := make([]APIRelease, 0, len(rels))
+	for _, rel := range rels {
+		ars = append(ars, newAPIRelease(be, repo.Name(), rel))
+	}
+
+	renderAPIJSON(w, http.StatusOK, ars)
+}
+
+// serviceGetRelease returns a release and its assets.
+func serviceGetRelease(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadOnlyAccess)
+	if !ok {
+		return
+	}
+
+	rel, err := be.Release(ctx, repo.Name(), mux.Vars(r)["tag"])
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIRelease(be, repo.Name(), rel))
+}
+
+// serviceCreateRelease creates a release of an existing tag.
+func serviceCreateRelease(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	var req APIReleaseRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Tag == "" {
+		renderAPIError(w, http.StatusBadRequest, "tag is required")
+		return
+	}
+
+	var name, notes string
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Notes != nil {
+		notes = *req.Notes
+	}
+
+	rel, err := be.CreateRelease(ctx, repo.Name(), req.Tag, name, notes)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusCreated, newAPIRelease(be, repo.Name(), rel))
+}
+
+// serviceUpdateRelease updates the name and the notes of a release.
+func serviceUpdateRelease(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	var req APIReleaseRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	tag := mux.Vars(r)["tag"]
+	rel, err := be.Release(ctx, repo.Name(), tag)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	if req.Name != nil {
+		rel.Name = *req.Name
+	}
+	if req.Notes != nil {
+		rel.Notes = *req.Notes
+	}
+
+	if err := be.UpdateRelease(ctx, repo.Name(), tag, rel.Name, rel.Notes); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	rel, err = be.Release(ctx, repo.Name(), tag)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIRelease(be, repo.Name(), rel))
+}
+
+// serviceDeleteRelease deletes a release and its assets.
+func serviceDeleteRelease(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	if err := be.DeleteRelease(ctx, repo.Name(), mux.Vars(r)["tag"]); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceUploadReleaseAsset uploads a release asset, the body of the request.
+func serviceUploadReleaseAsset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	tag := mux.Vars(r)["tag"]
+	a, err := be.UploadReleaseAsset(ctx, repo.Name(), tag, mux.Vars(r)["name"], r.Body)
+	if err != nil {
+		if errors.Is(err, backend.ErrReleaseAssetTooLarge) {
+			renderAPIError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		if errors.Is(err, proto.ErrReleaseNotFound) {
+			renderAPIErr(w, r, err)
+			return
+		}
+		renderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIReleaseAsset(be, repo.Name(), tag, a))
+}
+
+// serviceDeleteReleaseAsset deletes a release asset.
+func serviceDeleteReleaseAsset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	if err := be.DeleteReleaseAsset(ctx, repo.Name(), mux.Vars(r)["tag"], mux.Vars(r)["name"]); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,663 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/db"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/gorilla/mux"
+)
+
+// maxAPIContentSize is the maximum size of the files returned by the
+// contents API.
+const maxAPIContentSize = 10 << 20 // 10 MiB
+
+// APIRepository is a repository in API responses.
+type APIRepository struct {
+	Name          string           `json:"name"`
+	ProjectName   string           `json:"project_name"`
+	Description   string           `json:"description"`
+	Visibility    proto.Visibility `json:"visibility"`
+	Hidden        bool             `json:"hidden"`
+	Mirror        bool             `json:"mirror"`
+	Archived      bool             `json:"archived"`
+	DefaultBranch string           `json:"default_branch,omitempty"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// APIRepositoryRequest is the body of repository create and update requests.
+// Fields that aren't set are left unchanged on update.
+type APIRepositoryRequest struct {
+	Name        *string `json:"name"`
+	ProjectName *string `json:"project_name"`
+	Description *string `json:"description"`
+	Visibility  *string `json:"visibility"`
+	Hidden      *bool   `json:"hidden"`
+}
+
+// APICollaborator is a repository collaborator in API responses.
+type APICollaborator struct {
+	Username    string `json:"username"`
+	AccessLevel string `json:"access_level"`
+}
+
+// APICollaboratorRequest is the body of collaborator requests.
+type APICollaboratorRequest struct {
+	// AccessLevel is the access level of the collaborator, read-write by
+	// default.
+	AccessLevel string `json:"access_level"`
+}
+
+// APIReference is a branch or a tag in API responses.
+type APIReference struct {
+	Name string `json:"name"`
+	// Hash is the hash of the commit the reference points to.
+	Hash string `json:"hash"`
+}
+
+// APIContent is a file or a directory of a repository in API responses.
+type APIContent struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Type is the type of the git object, "blob", "tree", or "commit" for
+	// submodules.
+	Type string `json:"type"`
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+	// Content is the base64 encoded content of blobs.
+	Content string `json:"content,omitempty"`
+	// Entries are the entries of trees.
+	Entries []APIContent `json:"entries,omitempty"`
+}
+
+func registerRepoAPI(api *mux.Router) {
+	api.Handle("/repos", withAPIAccess(http.HandlerFunc(serviceListRepos))).
+		Methods(http.MethodGet)
+	api.Handle("/repos", withAPIAccess(http.HandlerFunc(serviceCreateRepo))).
+		Methods(http.MethodPost)
+	api.Handle("/repos/{repo:.+?}/collaborators", withAPIAccess(http.HandlerFunc(serviceListCollaborators))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+?}/collaborators/{username}", withAPIAccess(http.HandlerFunc(serviceSetCollaborator))).
+		Methods(http.MethodPut)
+	api.Handle("/repos/{repo:.+?}/collaborators/{username}", withAPIAccess(http.HandlerFunc(serviceRemoveCollaborator))).
+		Methods(http.MethodDelete)
+	api.Handle("/repos/{repo:.+?}/branches", withAPIAccess(http.HandlerFunc(serviceListBranches))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+?}/branches/{ref:.+}", withAPIAccess(http.HandlerFunc(serviceDeleteBranch))).
+		Methods(http.MethodDelete)
+	api.Handle("/repos/{repo:.+?}/tags", withAPIAccess(http.HandlerFunc(serviceListTags))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+?}/tags/{ref:.+}", withAPIAccess(http.HandlerFunc(serviceDeleteTag))).
+		Methods(http.MethodDelete)
+	api.Handle("/repos/{repo:.+?}/contents", withAPIAccess(http.HandlerFunc(serviceContents))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+?}/contents/{path:.*}", withAPIAccess(http.HandlerFunc(serviceContents))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}", withAPIAccess(http.HandlerFunc(serviceGetRepo))).
+		Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}", withAPIAccess(http.HandlerFunc(serviceUpdateRepo))).
+		Methods(http.MethodPatch)
+	api.Handle("/repos/{repo:.+}", withAPIAccess(http.HandlerFunc(serviceDeleteRepo))).
+		Methods(http.MethodDelete)
+}
+
+// apiRepository returns the repository of the request if the user has at
+// least level access to it. Users without read access get a not found error,
+// so that private repositories aren't revealed.
+func apiRepository(w http.ResponseWriter, r *http.Request, level access.AccessLevel) (proto.Repository, bool) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+	user := proto.UserFromContext(ctx)
+
+	repo, err := be.Repository(ctx, name)
+	al := be.AccessLevelForUser(ctx, name, user)
+	if err != nil || al < access.ReadOnlyAccess {
+		renderAPIError(w, http.StatusNotFound, proto.ErrRepoNotFound.Error())
+		return nil, false
+	}
+
+	if al < level {
+		renderAPIError(w, http.StatusForbidden, proto.ErrUnauthorized.Error())
+		return nil, false
+	}
+
+	return repo, true
+}
+
+// apiUser returns the user of the request. Anonymous requests get an
+// unauthorized error.
+func apiUser(w http.ResponseWriter, r *http.Request) (proto.User, bool) {
+	user := proto.UserFromContext(r.Context())
+	if user == nil {
+		askCredentials(w, r)
+		renderAPIError(w, http.StatusUnauthorized, "credentials needed")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// twoFactorHeader is the header of the two-factor code of destructive
+// requests.
+const twoFactorHeader = "X-OTP"
+
+// apiTwoFactor checks the two-factor code of a destructive request, like the
+// --otp flag of the SSH commands. Users who didn't enroll in two-factor
+// authentication don't need one.
+func apiTwoFactor(w http.ResponseWriter, r *http.Request) bool {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	if err := be.VerifyTwoFactor(ctx, proto.UserFromContext(ctx), r.Header.Get(twoFactorHeader)); err != nil {
+		renderAPIErr(w, r, err)
+		return false
+	}
+
+	return true
+}
+
+func newAPIRepository(ctx context.Context, repo proto.Repository) APIRepository {
+	meta := backend.FromContext(ctx).RepoMetadata(ctx, repo)
+	return APIRepository{
+		Name:          repo.Name(),
+		ProjectName:   repo.ProjectName(),
+		Description:   repo.Description(),
+		Visibility:    proto.RepositoryVisibility(repo),
+		Hidden:        repo.IsHidden(),
+		Mirror:        repo.IsMirror(),
+		Archived:      repo.IsArchived(),
+		UpdatedAt:     meta.UpdatedAt,
+		DefaultBranch: meta.DefaultBranch,
+	}
+}
+
+// serviceListRepos lists the repositories the user can read. Hidden
+// repositories are only listed with the all query parameter.
+func serviceListRepos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user := proto.UserFromContext(ctx)
+	all := r.URL.Query().Get("all") == "true"
+
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	list := make([]APIRepository, 0, len(repos))
+	for _, repo := range repos {
+		if repo.IsHidden() && !all {
+			continue
+		}
+		if be.AccessLevelForUser(ctx, repo.Name(), user) >= access.ReadOnlyAccess {
+			list = append(list, newAPIRepository(ctx, repo))
+		}
+	}
+
+	renderAPIJSON(w, http.StatusOK, list)
+}
+
+// serviceCreateRepo creates a repository owned by the user.
+func serviceCreateRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req APIRepositoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Name == nil {
+		renderAPIError(w, http.StatusBadRequest, "repository name is required")
+		return
+	}
+
+	name := utils.SanitizeRepo(*req.Name)
+	if err := utils.ValidateRepo(name); err != nil {
+		renderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	vis := proto.VisibilityPublic
+	if req.Visibility != nil {
+		v, err := proto.ParseVisibility(*req.Visibility)
+		if err != nil {
+			renderAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		vis = v
+	}
+
+	if be.AccessLevelForUser(ctx, name, user) < access.ReadWriteAccess {
+		renderAPIError(w, http.StatusForbidden, proto.ErrUnauthorized.Error())
+		return
+	}
+
+	opts := proto.RepositoryOptions{Private: vis == proto.VisibilityPrivate}
+	if req.ProjectName != nil {
+		opts.ProjectName = *req.ProjectName
+	}
+	if req.Description != nil {
+		opts.Description = *req.Description
+	}
+	if req.Hidden != nil {
+		opts.Hidden = *req.Hidden
+	}
+
+	repo, err := be.CreateRepository(ctx, name, user, opts)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	if vis == proto.VisibilityInternal {
+		if err := be.SetVisibility(ctx, name, vis); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+		if repo, err = be.Repository(ctx, name); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+
+	renderAPIJSON(w, http.StatusCreated, newAPIRepository(ctx, repo))
+}
+
+// serviceGetRepo returns a repository.
+func serviceGetRepo(w http.ResponseWriter, r *http.Request) {
+	repo, ok := apiRepository(w, r, access.ReadOnlyAccess)
+	if !ok {
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIRepository(r.Context(), repo))
+}
+
+// serviceUpdateRepo updates the settings of a repository, and renames it
+// when the name is set.
+func serviceUpdateRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	var req APIRepositoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// Validate everything before changing anything.
+	var vis proto.Visibility
+	if req.Visibility != nil {
+		v, err := proto.ParseVisibility(*req.Visibility)
+		if err != nil {
+			renderAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		vis = v
+	}
+
+	name := repo.Name()
+	var newName string
+	if req.Name != nil {
+		newName = utils.SanitizeRepo(*req.Name)
+		if err := utils.ValidateRepo(newName); err != nil {
+			renderAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if req.ProjectName != nil {
+		if err := be.SetProjectName(ctx, name, *req.ProjectName); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+	if req.Description != nil {
+		if err := be.SetDescription(ctx, name, *req.Description); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+	if req.Hidden != nil {
+		if err := be.SetHidden(ctx, name, *req.Hidden); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+	if vis != "" {
+		if err := be.SetVisibility(ctx, name, vis); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+
+	// Rename last, the other settings are set by the current name.
+	if newName != "" && newName != name {
+		if err := be.RenameRepository(ctx, name, newName); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+		name = newName
+	}
+
+	repo, err := be.Repository(ctx, name)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIRepository(ctx, repo))
+}
+
+// serviceDeleteRepo deletes a repository.
+func serviceDeleteRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok || !apiTwoFactor(w, r) {
+		return
+	}
+
+	if err := be.DeleteRepository(ctx, repo.Name()); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceListCollaborators lists the collaborators of a repository.
+func serviceListCollaborators(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	collabs, err := be.Collaborators(ctx, repo.Name())
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	list := make([]APICollaborator, 0, len(collabs))
+	for _, username := range collabs {
+		level, _, err := be.IsCollaborator(ctx, repo.Name(), username)
+		if err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+		list = append(list, APICollaborator{Username: username, AccessLevel: level.String()})
+	}
+
+	renderAPIJSON(w, http.StatusOK, list)
+}
+
+// serviceSetCollaborator adds a collaborator to a repository, or changes its
+// access level.
+func serviceSetCollaborator(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	var req APICollaboratorRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	level := access.ReadWriteAccess
+	if req.AccessLevel != "" {
+		level = access.ParseAccessLevel(req.AccessLevel)
+		if level < 0 {
+			renderAPIError(w, http.StatusBadRequest, access.ErrInvalidAccessLevel.Error())
+			return
+		}
+	}
+
+	username := mux.Vars(r)["username"]
+	if _, err := be.User(ctx, username); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	// Collaborators can't be added twice, replace the existing one.
+	if _, isCollab, err := be.IsCollaborator(ctx, repo.Name(), username); err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		renderAPIErr(w, r, err)
+		return
+	} else if isCollab {
+		if err := be.RemoveCollaborator(ctx, repo.Name(), username); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+
+	if err := be.AddCollaborator(ctx, repo.Name(), username, level); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, APICollaborator{Username: strings.ToLower(username), AccessLevel: level.String()})
+}
+
+// serviceRemoveCollaborator removes a collaborator from a repository.
+func serviceRemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	if err := be.RemoveCollaborator(ctx, repo.Name(), mux.Vars(r)["username"]); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceListBranches lists the branches of a repository.
+func serviceListBranches(w http.ResponseWriter, r *http.Request) {
+	renderReferences(w, r, (*git.Reference).IsBranch)
+}
+
+// serviceListTags lists the tags of a repository.
+func serviceListTags(w http.ResponseWriter, r *http.Request) {
+	renderReferences(w, r, (*git.Reference).IsTag)
+}
+
+func renderReferences(w http.ResponseWriter, r *http.Request, filter func(*git.Reference) bool) {
+	repo, ok := apiRepository(w, r, access.ReadOnlyAccess)
+	if !ok {
+		return
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	list := make([]APIReference, 0)
+	// Empty repositories don't have any references.
+	refs, _ := rr.References()
+	for _, ref := range refs {
+		if !filter(ref) {
+			continue
+		}
+
+		// Annotated tags point to a tag object.
+		hash := ref.Hash.String()
+		if ref.IsTag() {
+			if h := ref.TargetHash(); h != "" {
+				hash = h.String()
+			}
+		}
+		name := strings.TrimPrefix(ref.Name().String(), git.RefsHeads)
+		name = strings.TrimPrefix(name, git.RefsTags)
+		list = append(list, APIReference{Name: name, Hash: hash})
+	}
+
+	renderAPIJSON(w, http.StatusOK, list)
+}
+
+// serviceDeleteBranch deletes a branch of a repository.
+func serviceDeleteBranch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	if err := be.DeleteBranch(ctx, repo.Name(), proto.UserFromContext(ctx), mux.Vars(r)["ref"]); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceDeleteTag deletes a tag of a repository.
+func serviceDeleteTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	repo, ok := apiRepository(w, r, access.ReadWriteAccess)
+	if !ok {
+		return
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	tag := mux.Vars(r)["ref"]
+	if !rr.HasTag(tag) {
+		renderAPIErr(w, r, git.ErrReferenceNotExist)
+		return
+	}
+
+	if err := be.DeleteTag(ctx, repo.Name(), proto.UserFromContext(ctx), tag); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceContents returns a file or a directory of a repository. The ref
+// query parameter selects the revision, HEAD by default.
+func serviceContents(w http.ResponseWriter, r *http.Request) {
+	logger := log.FromContext(r.Context())
+	repo, ok := apiRepository(w, r, access.ReadOnlyAccess)
+	if !ok {
+		return
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	tree, err := rr.LsTree(ref)
+	if err != nil {
+		renderAPIError(w, http.StatusNotFound, "reference not found")
+		return
+	}
+
+	fp := strings.Trim(mux.Vars(r)["path"], "/")
+	content := APIContent{Path: fp, Type: "tree"}
+	if fp != "" {
+		te, err := tree.TreeEntry(fp)
+		if err != nil {
+			renderAPIError(w, http.StatusNotFound, git.ErrFileNotFound.Error())
+			return
+		}
+
+		content = APIContent{
+			Name: te.Name(),
+			Path: fp,
+			Type: string(te.Type()),
+			Hash: te.ID().String(),
+			Size: te.Size(),
+		}
+		switch te.Type() {
+		case "blob":
+			if te.Size() > maxAPIContentSize {
+				renderAPIError(w, http.StatusRequestEntityTooLarge, "file is too large")
+				return
+			}
+
+			bts, err := te.Contents()
+			if err != nil {
+				logger.Error("failed to read file", "repo", repo.Name(), "path", fp, "err", err)
+				renderAPIErr(w, r, err)
+				return
+			}
+
+			content.Content = base64.StdEncoding.EncodeToString(bts)
+			renderAPIJSON(w, http.StatusOK, content)
+			return
+		case "tree":
+			if tree, err = tree.SubTree(fp); err != nil {
+				renderAPIErr(w, r, err)
+				return
+			}
+		default:
+			renderAPIJSON(w, http.StatusOK, content)
+			return
+		}
+	}
+
+	ents, err := tree.Entries()
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	ents.Sort()
+	content.Entries = make([]APIContent, 0, len(ents))
+	for _, e := range ents {
+		content.Entries = append(content.Entries, APIContent{
+			Name: e.Name(),
+			Path: path.Join(fp, e.Name()),
+			Type: string(e.Type()),
+			Hash: e.ID().String(),
+			Size: e.Size(),
+		})
+	}
+
+	renderAPIJSON(w, http.StatusOK, content)
+}
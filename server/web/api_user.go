@@ -0,0 +1,442 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/duration"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/sshutils"
+	"github.com/charmbracelet/soft-serve/server/utils"
+	"github.com/gorilla/mux"
+)
+
+// APIUser is a user in API responses.
+type APIUser struct {
+	Username   string   `json:"username"`
+	Admin      bool     `json:"admin"`
+	Suspended  bool     `json:"suspended"`
+	PublicKeys []string `json:"public_keys"`
+	// AvatarURL is the image of the user, their Initials are shown until it
+	// loads.
+	AvatarURL string `json:"avatar_url"`
+	Initials  string `json:"initials"`
+}
+
+// APIUserRequest is the body of user create and update requests. Fields that
+// aren't set are left unchanged on update.
+type APIUserRequest struct {
+	Username *string `json:"username"`
+	Admin    *bool   `json:"admin"`
+	// Suspended can only be set on update.
+	Suspended *bool `json:"suspended"`
+	// PublicKeys can only be set on create, in the authorized_keys format.
+	PublicKeys []string `json:"public_keys"`
+}
+
+// APIToken is an access token in API responses.
+type APIToken struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	AccessLevel string     `json:"access_level"`
+	Repo        string     `json:"repo,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	// Token is the secret of the token, it's only returned on create.
+	Token string `json:"token,omitempty"`
+}
+
+// APITokenRequest is the body of token create requests.
+type APITokenRequest struct {
+	Name string `json:"name"`
+	// ExpiresIn is the lifetime of the token, i.e. "1y" or "5d4h". Tokens
+	// don't expire by default.
+	ExpiresIn string `json:"expires_in"`
+	// AccessLevel is the highest access level the token grants, admin-access
+	// by default.
+	AccessLevel string `json:"access_level"`
+	// Repo limits the token to a repository.
+	Repo string `json:"repo"`
+}
+
+func registerUserAPI(api *mux.Router) {
+	api.Handle("/user", withAPIAccess(http.HandlerFunc(serviceCurrentUser))).
+		Methods(http.MethodGet)
+	api.Handle("/user/tokens", withAPIAccess(http.HandlerFunc(serviceListTokens))).
+		Methods(http.MethodGet)
+	api.Handle("/user/tokens", withAPIAccess(http.HandlerFunc(serviceCreateToken))).
+		Methods(http.MethodPost)
+	api.Handle("/user/tokens/{id}", withAPIAccess(http.HandlerFunc(serviceDeleteToken))).
+		Methods(http.MethodDelete)
+	api.Handle("/users", withAPIAccess(withAPIAdmin(serviceListUsers))).
+		Methods(http.MethodGet)
+	api.Handle("/users", withAPIAccess(withAPIAdmin(serviceCreateUser))).
+		Methods(http.MethodPost)
+	api.Handle("/users/{username}", withAPIAccess(withAPIAdmin(serviceGetUser))).
+		Methods(http.MethodGet)
+	api.Handle("/users/{username}", withAPIAccess(withAPIAdmin(serviceUpdateUser))).
+		Methods(http.MethodPatch)
+	api.Handle("/users/{username}", withAPIAccess(withAPIAdmin(serviceDeleteUser))).
+		Methods(http.MethodDelete)
+}
+
+// withAPIAdmin only lets admins through. Admins authenticated with an access
+// token need an unrestricted admin-access token.
+func withAPIAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		be := backend.FromContext(ctx)
+		user, ok := apiUser(w, r)
+		if !ok {
+			return
+		}
+
+		if !user.IsAdmin() || be.AccessLevelForUser(ctx, "", user) < access.AdminAccess {
+			renderAPIError(w, http.StatusForbidden, proto.ErrUnauthorized.Error())
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func newAPIUser(cfg *config.Config, user proto.User) APIUser {
+	au := APIUser{
+		Username:   user.Username(),
+		Admin:      user.IsAdmin(),
+		Suspended:  user.IsSuspended(),
+		PublicKeys: make([]string, 0, len(user.PublicKeys())),
+		AvatarURL:  avatarURL(cfg, user),
+		Initials:   initials(user.Username()),
+	}
+	for _, pk := range user.PublicKeys() {
+		au.PublicKeys = append(au.PublicKeys, sshutils.MarshalAuthorizedKey(pk))
+	}
+
+	return au
+}
+
+func newAPIToken(t proto.AccessToken) APIToken {
+	at := APIToken{
+		ID:          t.ID,
+		Name:        t.Name,
+		AccessLevel: t.AccessLevel.String(),
+		Repo:        t.Repo,
+		CreatedAt:   t.CreatedAt,
+	}
+	if !t.ExpiresAt.IsZero() {
+		at.ExpiresAt = &t.ExpiresAt
+	}
+
+	return at
+}
+
+// serviceCurrentUser returns the authenticated user.
+func serviceCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIUser(config.FromContext(r.Context()), user))
+}
+
+// serviceListUsers lists all the users.
+func serviceListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	usernames, err := be.Users(ctx)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	list := make([]APIUser, 0, len(usernames))
+	for _, username := range usernames {
+		user, err := be.User(ctx, username)
+		if err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+		list = append(list, newAPIUser(config.FromContext(r.Context()), user))
+	}
+
+	renderAPIJSON(w, http.StatusOK, list)
+}
+
+// serviceCreateUser creates a user.
+func serviceCreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	var req APIUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Username == nil {
+		renderAPIError(w, http.StatusBadRequest, "username is required")
+		return
+	}
+
+	username := strings.ToLower(*req.Username)
+	if err := utils.ValidateUsername(username); err != nil {
+		renderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var opts proto.UserOptions
+	if req.Admin != nil {
+		opts.Admin = *req.Admin
+	}
+	for _, k := range req.PublicKeys {
+		pk, _, err := sshutils.ParseAuthorizedKey(k)
+		if err != nil {
+			renderAPIError(w, http.StatusBadRequest, "invalid public key: "+err.Error())
+			return
+		}
+		opts.PublicKeys = append(opts.PublicKeys, pk)
+	}
+
+	if _, err := be.User(ctx, username); err == nil {
+		renderAPIError(w, http.StatusConflict, "user already exists")
+		return
+	}
+
+	user, err := be.CreateUser(ctx, username, opts)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusCreated, newAPIUser(config.FromContext(r.Context()), user))
+}
+
+// serviceGetUser returns a user.
+func serviceGetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, err := be.User(ctx, mux.Vars(r)["username"])
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIUser(config.FromContext(r.Context()), user))
+}
+
+// serviceUpdateUser updates a user, and renames it when the username is set.
+func serviceUpdateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, err := be.User(ctx, mux.Vars(r)["username"])
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	var req APIUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if len(req.PublicKeys) > 0 {
+		renderAPIError(w, http.StatusBadRequest, "public keys can't be updated")
+		return
+	}
+
+	username := user.Username()
+	var newUsername string
+	if req.Username != nil {
+		newUsername = strings.ToLower(*req.Username)
+		if err := utils.ValidateUsername(newUsername); err != nil {
+			renderAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if req.Admin != nil {
+		if err := be.SetAdmin(ctx, username, *req.Admin); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+	if req.Suspended != nil {
+		if err := be.SetSuspended(ctx, username, *req.Suspended); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+	}
+
+	// Rename last, the other settings are set by the current username.
+	if newUsername != "" && newUsername != username {
+		if err := be.SetUsername(ctx, username, newUsername); err != nil {
+			renderAPIErr(w, r, err)
+			return
+		}
+		username = newUsername
+	}
+
+	user, err = be.User(ctx, username)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIUser(config.FromContext(r.Context()), user))
+}
+
+// serviceDeleteUser deletes a user.
+func serviceDeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, err := be.User(ctx, mux.Vars(r)["username"])
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+	if !apiTwoFactor(w, r) {
+		return
+	}
+
+	if err := be.DeleteUser(ctx, user.Username()); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceListTokens lists the access tokens of the user.
+func serviceListTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	tokens, err := be.ListAccessTokens(ctx, user)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	list := make([]APIToken, 0, len(tokens))
+	for _, t := range tokens {
+		list = append(list, newAPIToken(t))
+	}
+
+	renderAPIJSON(w, http.StatusOK, list)
+}
+
+// serviceCreateToken creates an access token for the user. Requests
+// authenticated with an access token can only create tokens with the same
+// or a narrower scope.
+func serviceCreateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req APITokenRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		renderAPIError(w, http.StatusBadRequest, "token name is required")
+		return
+	}
+
+	level := access.AdminAccess
+	if req.AccessLevel != "" {
+		level = access.ParseAccessLevel(req.AccessLevel)
+		if level < access.ReadOnlyAccess {
+			renderAPIError(w, http.StatusBadRequest, access.ErrInvalidAccessLevel.Error())
+			return
+		}
+	}
+
+	var expiresAt time.Time
+	if req.ExpiresIn != "" {
+		d, err := duration.Parse(req.ExpiresIn)
+		if err != nil {
+			renderAPIError(w, http.StatusBadRequest, "invalid expiration: "+err.Error())
+			return
+		}
+		expiresAt = time.Now().Add(d)
+	}
+
+	if scope, repoID, ok := be.AccessTokenScope(user); ok {
+		if level > scope {
+			level = scope
+		}
+		if repoID != 0 {
+			// Tokens limited to a repository can only create tokens for it.
+			repo, err := be.Repository(ctx, req.Repo)
+			if req.Repo == "" || err != nil || repo.ID() != repoID {
+				renderAPIError(w, http.StatusForbidden, proto.ErrUnauthorized.Error())
+				return
+			}
+		}
+	}
+
+	if level == access.AdminAccess && !apiTwoFactor(w, r) {
+		return
+	}
+
+	token, err := be.CreateAccessToken(ctx, user, req.Name, expiresAt, level, req.Repo)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	// Return the token as listed.
+	tokens, err := be.ListAccessTokens(ctx, user)
+	if err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	hash := backend.HashToken(token)
+	for _, t := range tokens {
+		if t.TokenHash == hash {
+			at := newAPIToken(t)
+			at.Token = token
+			renderAPIJSON(w, http.StatusCreated, at)
+			return
+		}
+	}
+
+	renderAPIErr(w, r, proto.ErrTokenNotFound)
+}
+
+// serviceDeleteToken deletes an access token of the user.
+func serviceDeleteToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		renderAPIError(w, http.StatusBadRequest, "invalid token id")
+		return
+	}
+
+	if err := be.DeleteAccessToken(ctx, user, id); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
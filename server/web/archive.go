@@ -0,0 +1,141 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/repofs"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var archiveCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "archive_total",
+	Help:      "The total number of archive downloads",
+}, []string{"repo", "format"})
+
+// archiveMethods are the methods allowed on archives.
+var archiveMethods = []string{http.MethodGet, http.MethodHead}
+
+// archivePath is the path of archive requests.
+const archivePath = "/{repo:.+?}/{_:archive/.+\\.(?:tar\\.gz|zip)$}"
+
+// archiveFormat is an archive format of git archive.
+type archiveFormat struct {
+	ext         string
+	format      string
+	contentType string
+}
+
+var archiveFormats = []archiveFormat{
+	{ext: ".tar.gz", format: "tar.gz", contentType: "application/gzip"},
+	{ext: ".zip", format: "zip", contentType: "application/zip"},
+}
+
+// serviceArchive streams an archive of the tree of a ref at
+// /<repo>/archive/<ref>.{tar.gz,zip}. The ref is a branch, a tag, a commit
+// hash, or HEAD. Archives are tagged with the commit hash, so clients can
+// revalidate them without downloading them again.
+func serviceArchive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	name := mux.Vars(r)["repo"]
+	file := strings.TrimPrefix(mux.Vars(r)["file"], "archive/")
+
+	var af archiveFormat
+	for _, f := range archiveFormats {
+		if strings.HasSuffix(file, f.ext) {
+			af = f
+			break
+		}
+	}
+
+	ref := strings.TrimSuffix(file, af.ext)
+	archiveCounter.WithLabelValues(name, af.format).Inc()
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", name, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	rev, p, ok := repofs.New(rr).Revision(ref)
+	if !ok || p != "" {
+		renderNotFound(w, r)
+		return
+	}
+
+	commit, err := rr.CatFileCommit(rev + "^{commit}")
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	// Commit hashes always point to the same tree, branches and tags can
+	// move.
+	cache := "no-cache"
+	if !strings.HasPrefix(rev, "refs/") && strings.HasPrefix(commit.ID.String(), ref) {
+		cache = "max-age=31536000, immutable"
+	}
+	if repo.IsPrivate() {
+		cache = "private, " + cache
+	}
+
+	etag := `"` + commit.ID.String() + af.ext + `"`
+	w.Header().Set("Cache-Control", cache)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", commit.Committer.When.UTC().Format(http.TimeFormat))
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// Extract to a directory named after the repository and ref.
+	prefix := path.Base(repo.Name()) + "-" + strings.ReplaceAll(ref, "/", "-")
+	w.Header().Set("Content-Type", af.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", prefix+af.ext))
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	release, err := be.AcquireWorker(ctx, name)
+	if err != nil {
+		if errors.Is(err, proto.ErrServerBusy) {
+			renderStatus(http.StatusServiceUnavailable)(w, r)
+			return
+		}
+		renderInternalServerError(w, r)
+		return
+	}
+	defer release()
+
+	// The status is sent with the first bytes of the archive, errors can
+	// only be logged past that point.
+	if err := rr.Archive(w, af.format, commit.ID.String(), prefix+"/"); err != nil {
+		logger.Error("failed to write archive", "repo", name, "ref", ref, "err", err)
+	}
+}
+
+// etagMatch reports whether the If-None-Match header matches etag.
+func etagMatch(header string, etag string) bool {
+	for _, m := range strings.Split(header, ",") {
+		m = strings.TrimPrefix(strings.TrimSpace(m), "W/")
+		if m == "*" || m == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -8,23 +8,57 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/auth"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// authenticate authenticates the user from the request.
+// authenticate authenticates the user from the request. Failed
+// authentications are recorded in the audit log, successful ones once in a
+// while, requests without credentials aren't.
 func authenticate(r *http.Request) (proto.User, error) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+
 	// Prefer the Authorization header
 	user, err := parseAuthHdr(r)
 	if err != nil || user == nil {
+		username, password, isBasic := r.BasicAuth()
+		if err != nil && !errors.Is(err, ErrInvalidHeader) && (!isBasic || username != "" || password != "") {
+			event := proto.AuditEvent{Action: proto.AuditAuthFailure, Details: "http: " + err.Error()}
+			// Without a password, the username is an access token.
+			if isBasic && password != "" {
+				event.Target = username
+			}
+			be.Audit(ctx, event)
+		}
 		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrInvalidPassword) {
 			return nil, err
 		}
 		return nil, proto.ErrUserNotFound
 	}
 
+	if user.IsSuspended() {
+		be.Audit(ctx, proto.AuditEvent{
+			Action:   proto.AuditAuthFailure,
+			Username: user.Username(),
+			Details:  "http: " + proto.ErrUserSuspended.Error(),
+		})
+		return nil, proto.ErrUserSuspended
+	}
+
+	if err := be.CheckAddress(ctx, "http", r.RemoteAddr, user); err != nil {
+		return nil, err
+	}
+
+	if err := be.RateLimit(ctx, backend.RateHTTPRequest, user, ""); err != nil {
+		return nil, err
+	}
+
+	be.AuditAuthentication(ctx, proto.AuditEvent{Action: proto.AuditAuthSuccess, Username: user.Username(), Details: "http"})
+
 	return user, nil
 }
 
@@ -41,6 +75,15 @@ func parseUsernamePassword(ctx context.Context, username, password string) (prot
 			return user, nil
 		}
 
+		// Users synced from a directory use their directory password.
+		if err == nil && user != nil && user.Password() == "" {
+			if err := be.AuthenticateUser(ctx, user, password); err == nil {
+				return user, nil
+			} else if !errors.Is(err, auth.ErrInvalidCredentials) {
+				logger.Error("error authenticating user", "username", username, "err", err)
+			}
+		}
+
 		// Try to authenticate using access token as the password
 		user, err = be.UserByAccessToken(ctx, password)
 		if err == nil {
@@ -0,0 +1,244 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Avatar sizes, in pixels.
+const (
+	defaultAvatarSize = 80
+	maxAvatarSize     = 512
+)
+
+var avatarCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "avatar_total",
+	Help:      "The total number of avatar requests",
+}, []string{"source"})
+
+// avatarSVGTpl is the image of users shown with their initials. The initials
+// are letters and digits, they don't need escaping.
+var avatarSVGTpl = template.Must(template.New("avatar").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Size }}" height="{{ .Size }}" viewBox="0 0 100 100">
+<rect width="100" height="100" fill="hsl({{ .Hue }}, 45%, 45%)"/>
+<text x="50" y="50" dy="0.35em" fill="#fff" font-family="sans-serif" font-size="42" text-anchor="middle">{{ .Initials }}</text>
+</svg>
+`))
+
+// WebFinger is the JSON resource descriptor of a WebFinger response.
+//
+// https://www.rfc-editor.org/rfc/rfc7033
+type WebFinger struct {
+	Subject string          `json:"subject"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+// WebFingerLink is a link of a WebFinger resource.
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+func registerAvatarAPI(api *mux.Router) {
+	api.Handle("/users/{username}/avatar", withAPIAccess(http.HandlerFunc(serviceAvatar))).
+		Methods(http.MethodGet, http.MethodHead)
+	api.Handle("/user/avatar", withAPIAccess(http.HandlerFunc(serviceSetAvatar))).
+		Methods(http.MethodPut)
+	api.Handle("/user/avatar", withAPIAccess(http.HandlerFunc(serviceDeleteAvatar))).
+		Methods(http.MethodDelete)
+}
+
+// initials returns the initials of a username, the first letter of its first
+// two words.
+func initials(username string) string {
+	var b strings.Builder
+	for _, w := range strings.FieldsFunc(username, func(r rune) bool { return r == '-' }) {
+		r, _ := utf8.DecodeRuneInString(w)
+		b.WriteRune(unicode.ToUpper(r))
+		if b.Len() > 1 {
+			break
+		}
+	}
+	return b.String()
+}
+
+// avatarURL returns the URL of the avatar of the user.
+func avatarURL(cfg *config.Config, user proto.User) string {
+	return cfg.HTTP.PublicURL + "/api/v1/users/" + url.PathEscape(user.Username()) + "/avatar"
+}
+
+// serviceAvatar serves the avatar of a user: the uploaded image, a redirect
+// to the avatar provider, or the initials of the user. The s query parameter
+// is the size of the image, and default=initials skips the other sources.
+func serviceAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	user, err := be.User(ctx, mux.Vars(r)["username"])
+	if err != nil {
+		renderAPIError(w, http.StatusNotFound, proto.ErrUserNotFound.Error())
+		return
+	}
+
+	size := defaultAvatarSize
+	if s := r.URL.Query().Get("s"); s != "" {
+		size, err = strconv.Atoi(s)
+		if err != nil || size < 1 || size > maxAvatarSize {
+			renderAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid size: must be between 1 and %d", maxAvatarSize))
+			return
+		}
+	}
+
+	if r.URL.Query().Get("default") != "initials" {
+		a, err := be.Avatar(ctx, user, size)
+		if err != nil {
+			logger.Error("failed to get avatar", "username", user.Username(), "err", err)
+			renderAPIError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		switch {
+		case a.Uploaded():
+			f, err := os.Open(a.Path)
+			if err != nil {
+				logger.Error("failed to open avatar", "username", user.Username(), "err", err)
+				renderAPIError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			defer f.Close() // nolint: errcheck
+
+			fi, err := f.Stat()
+			if err != nil {
+				logger.Error("failed to stat avatar", "username", user.Username(), "err", err)
+				renderAPIError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			avatarCounter.WithLabelValues("upload").Inc()
+			w.Header().Set("Content-Type", a.ContentType)
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			http.ServeContent(w, r, "", fi.ModTime(), f)
+			return
+		case a.URL != "":
+			avatarCounter.WithLabelValues("provider").Inc()
+			http.Redirect(w, r, a.URL, http.StatusFound)
+			return
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(user.Username())) // nolint: errcheck
+
+	avatarCounter.WithLabelValues("initials").Inc()
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	if err := avatarSVGTpl.Execute(w, struct {
+		Size     int
+		Hue      uint32
+		Initials string
+	}{
+		Size:     size,
+		Hue:      h.Sum32() % 360,
+		Initials: initials(user.Username()),
+	}); err != nil {
+		logger.Error("failed to render avatar", "err", err)
+	}
+}
+
+// serviceSetAvatar uploads the image of the current user, the body of the
+// request.
+func serviceSetAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := be.SetAvatar(ctx, user, r.Body); err != nil {
+		if errors.Is(err, backend.ErrAvatarTooLarge) {
+			renderAPIError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		renderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newAPIUser(cfg, user))
+}
+
+// serviceDeleteAvatar removes the uploaded image of the current user.
+func serviceDeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	be := backend.FromContext(ctx)
+	user, ok := apiUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := be.DeleteAvatar(ctx, user); err != nil {
+		renderAPIErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serviceWebFinger describes the users of the server, "acct:<user>@<host>"
+// resources where the host is the one of the HTTP public URL. Avatar
+// services and clients find the avatars of users with it.
+func serviceWebFinger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	resource := r.URL.Query().Get("resource")
+	acct := strings.TrimPrefix(resource, "acct:")
+	i := strings.LastIndex(acct, "@")
+	if resource == "" || acct == resource || i < 0 {
+		renderAPIError(w, http.StatusBadRequest, "invalid resource: must be acct:<user>@<host>")
+		return
+	}
+
+	u, err := url.Parse(cfg.HTTP.PublicURL)
+	host := acct[i+1:]
+	if err != nil || (host != u.Host && host != u.Hostname()) {
+		renderAPIError(w, http.StatusNotFound, proto.ErrUserNotFound.Error())
+		return
+	}
+
+	user, err := be.User(ctx, acct[:i])
+	if err != nil {
+		renderAPIError(w, http.StatusNotFound, proto.ErrUserNotFound.Error())
+		return
+	}
+
+	// WebFinger resources are read by clients of other origins.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	renderAPIJSON(w, http.StatusOK, WebFinger{
+		Subject: "acct:" + user.Username() + "@" + host,
+		Links: []WebFingerLink{
+			{Rel: "http://webfinger.net/rel/avatar", Href: avatarURL(cfg, user)},
+		},
+	})
+}
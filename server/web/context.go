@@ -8,6 +8,8 @@ import (
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/db"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/store"
 )
 
@@ -16,7 +18,7 @@ import (
 func NewContextHandler(ctx context.Context) func(http.Handler) http.Handler {
 	cfg := config.FromContext(ctx)
 	be := backend.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("http")
+	logger := logr.WithTrackedPrefix(log.FromContext(ctx), logr.SubsystemHTTP)
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 	return func(next http.Handler) http.Handler {
@@ -24,9 +26,14 @@ func NewContextHandler(ctx context.Context) func(http.Handler) http.Handler {
 			ctx := r.Context()
 			ctx = config.WithContext(ctx, cfg)
 			ctx = backend.WithContext(ctx, be)
-			ctx = log.WithContext(ctx, logger)
+			if id := requestIDFromContext(ctx); id != "" {
+				ctx = log.WithContext(ctx, logger.With("request_id", id))
+			} else {
+				ctx = log.WithContext(ctx, logger)
+			}
 			ctx = db.WithContext(ctx, dbx)
 			ctx = store.WithContext(ctx, datastore)
+			ctx = proto.WithRemoteAddrContext(ctx, r.RemoteAddr)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		})
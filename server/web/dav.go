@@ -0,0 +1,215 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/repofs"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/net/webdav"
+)
+
+var davCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "webdav_total",
+	Help:      "The total number of WebDAV requests",
+}, []string{"repo", "method"})
+
+// davMethods are the WebDAV methods allowed on repository trees. Anything
+// that would modify the tree is rejected.
+var davMethods = []string{
+	http.MethodOptions,
+	http.MethodGet,
+	http.MethodHead,
+	"PROPFIND",
+}
+
+// serviceDav serves a read-only WebDAV view of the repository trees. The
+// top-level directories are the repository branches and tags, a commit hash
+// can be used to browse any other revision.
+func serviceDav(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	name := mux.Vars(r)["repo"]
+	file := strings.TrimPrefix(strings.TrimPrefix(mux.Vars(r)["file"], "dav"), "/")
+	davCounter.WithLabelValues(name, r.Method).Inc()
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Allow", strings.Join(davMethods, ", "))
+		w.Header().Set("DAV", "1")
+		w.WriteHeader(http.StatusOK)
+		return
+	case "PROPFIND":
+		// Walking a whole repository would run git for every file in it.
+		// https://www.rfc-editor.org/rfc/rfc4918#section-9.1
+		if d := r.Header.Get("Depth"); d != "0" && d != "1" {
+			renderForbidden(w, r)
+			return
+		}
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", name, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	prefix := "/" + name + "/dav"
+	r.URL.Path = prefix + "/" + file
+	h := &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: &davFS{repofs.New(rr)},
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				logger.Debug("webdav error", "repo", name, "path", r.URL.Path, "err", err)
+			}
+		},
+	}
+
+	h.ServeHTTP(w, r)
+}
+
+// davFS is a read-only webdav.FileSystem of the trees of a repository.
+type davFS struct {
+	fsys *repofs.FS
+}
+
+var _ webdav.FileSystem = (*davFS)(nil)
+
+// davName converts a WebDAV path to an fs.FS name.
+func davName(name string) string {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// Mkdir implements webdav.FileSystem.
+func (d *davFS) Mkdir(context.Context, string, os.FileMode) error {
+	return fs.ErrPermission
+}
+
+// OpenFile implements webdav.FileSystem.
+func (d *davFS) OpenFile(_ context.Context, name string, flag int, _ os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, fs.ErrPermission
+	}
+
+	f, err := d.fsys.Open(davName(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &davFile{File: f}, nil
+}
+
+// RemoveAll implements webdav.FileSystem.
+func (d *davFS) RemoveAll(context.Context, string) error {
+	return fs.ErrPermission
+}
+
+// Rename implements webdav.FileSystem.
+func (d *davFS) Rename(context.Context, string, string) error {
+	return fs.ErrPermission
+}
+
+// Stat implements webdav.FileSystem.
+func (d *davFS) Stat(_ context.Context, name string) (os.FileInfo, error) {
+	fi, err := d.fsys.Stat(davName(name))
+	if err != nil {
+		return nil, err
+	}
+	return davFileInfo{fi}, nil
+}
+
+// davFileInfo adds WebDAV properties to a repofs file.
+type davFileInfo struct {
+	fs.FileInfo
+}
+
+var (
+	_ webdav.ETager       = davFileInfo{}
+	_ webdav.ContentTyper = davFileInfo{}
+)
+
+// ETag implements webdav.ETager. Files use their blob hash.
+func (fi davFileInfo) ETag(context.Context) (string, error) {
+	e, ok := fi.Sys().(*git.TreeEntry)
+	if !ok || fi.IsDir() {
+		return "", webdav.ErrNotImplemented
+	}
+	return `"` + e.ID().String() + `"`, nil
+}
+
+// ContentType implements webdav.ContentTyper.
+func (fi davFileInfo) ContentType(context.Context) (string, error) {
+	if ct := mime.TypeByExtension(path.Ext(fi.Name())); ct != "" {
+		return ct, nil
+	}
+	return "", webdav.ErrNotImplemented
+}
+
+// davFile is a read-only webdav.File of a repofs file or directory.
+type davFile struct {
+	fs.File
+}
+
+var _ webdav.File = (*davFile)(nil)
+
+// Seek implements webdav.File.
+func (f *davFile) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.File.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+	return 0, nil
+}
+
+// Readdir implements webdav.File.
+func (f *davFile) Readdir(count int) ([]fs.FileInfo, error) {
+	d, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, fs.ErrInvalid
+	}
+
+	entries, err := d.ReadDir(count)
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, davFileInfo{fi})
+	}
+
+	return infos, err
+}
+
+// Stat implements webdav.File.
+func (f *davFile) Stat() (fs.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return davFileInfo{fi}, nil
+}
+
+// Write implements webdav.File.
+func (f *davFile) Write([]byte) (int, error) { return 0, fs.ErrPermission }
@@ -0,0 +1,255 @@
+package web
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var feedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "feed_total",
+	Help:      "The total number of feed requests",
+}, []string{"repo", "feed"})
+
+// feedMethods are the methods allowed on feeds.
+var feedMethods = []string{http.MethodGet, http.MethodHead}
+
+// feedPath is the path of feed requests.
+const feedPath = "/{repo:.+?}/{_:feeds/.+\\.atom$}"
+
+// feedSize is the number of entries of a feed.
+const feedSize = 20
+
+// atomFeed is an Atom feed.
+// https://www.rfc-editor.org/rfc/rfc4287
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomEntry is an entry of an Atom feed.
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  atomPerson `xml:"author"`
+	Links   []atomLink `xml:"link"`
+	Content *atomText  `xml:"content,omitempty"`
+}
+
+// atomLink is a link of an Atom feed or entry.
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// atomPerson is the author of an Atom entry.
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+// atomText is the text content of an Atom entry.
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func newAtomPerson(s *git.Signature) atomPerson {
+	if s == nil {
+		return atomPerson{Name: "unknown"}
+	}
+	return atomPerson{Name: s.Name, Email: s.Email}
+}
+
+// serviceFeed serves the Atom feeds of a repository:
+//
+//   - /<repo>/feeds/commits.atom, the commits of the default branch.
+//   - /<repo>/feeds/commits/<ref>.atom, the commits of a branch or tag.
+//   - /<repo>/feeds/tags.atom, the latest tags.
+func serviceFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	wr := newWebRepo(ctx, cfg, repo)
+	file := strings.TrimSuffix(strings.TrimPrefix(mux.Vars(r)["file"], "feeds/"), ".atom")
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	var feed *atomFeed
+	switch {
+	case file == "tags":
+		feedCounter.WithLabelValues(repo.Name(), "tags").Inc()
+		feed, err = tagsFeed(cfg, wr, rr)
+	case file == "commits", strings.HasPrefix(file, "commits/"):
+		ref := strings.TrimPrefix(strings.TrimPrefix(file, "commits"), "/")
+		if ref == "" {
+			ref = wr.DefaultBranch
+		}
+		feedCounter.WithLabelValues(repo.Name(), "commits").Inc()
+		feed, err = commitsFeed(r, wr, rr, ref)
+	default:
+		renderNotFound(w, r)
+		return
+	}
+
+	switch {
+	case errors.Is(err, proto.ErrServerBusy):
+		renderStatus(http.StatusServiceUnavailable)(w, r)
+		return
+	case errors.Is(err, git.ErrReferenceNotExist):
+		renderNotFound(w, r)
+		return
+	case err != nil:
+		logger.Error("failed to build feed", "repo", repo.Name(), "feed", file, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	feed.ID = cfg.HTTP.PublicURL + wr.FeedURL(file)
+	feed.Links = append(feed.Links, atomLink{Href: feed.ID, Rel: "self", Type: "application/atom+xml"})
+	if feed.Updated == "" {
+		feed.Updated = atomTime(repo.UpdatedAt())
+	}
+
+	out, err := xml.Marshal(feed)
+	if err != nil {
+		logger.Error("failed to marshal feed", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write([]byte(xml.Header)) // nolint: errcheck
+		w.Write(out)                // nolint: errcheck
+	}
+}
+
+// commitsFeed returns the feed of the latest commits of ref.
+func commitsFeed(r *http.Request, wr *webRepo, rr *git.Repository, ref string) (*atomFeed, error) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	refName, gref, p, ok := webRevision(rr, ref)
+	if !ok || p != "" {
+		return nil, git.ErrReferenceNotExist
+	}
+
+	release, err := be.AcquireWorker(ctx, repo.Name())
+	if err != nil {
+		return nil, err
+	}
+	commits, _, err := be.CommitsFrom(ctx, repo, []string{gref.Hash.String()}, feedSize)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &atomFeed{
+		Title: repo.Name() + " commits on " + refName,
+		Links: []atomLink{{Href: cfg.HTTP.PublicURL + wr.CommitsURL(refName)}},
+	}
+	for _, c := range commits {
+		wc := newWebCommit(wr, c)
+		url := cfg.HTTP.PublicURL + wc.URL
+		e := atomEntry{
+			ID:      url,
+			Title:   wc.Title,
+			Author:  newAtomPerson(c.Author),
+			Links:   []atomLink{{Href: url}},
+			Content: &atomText{Type: "text", Body: strings.TrimSpace(c.Message)},
+		}
+		if c.Committer != nil {
+			e.Updated = atomTime(c.Committer.When)
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+	if len(feed.Entries) > 0 {
+		feed.Updated = feed.Entries[0].Updated
+	}
+
+	return feed, nil
+}
+
+// tagsFeed returns the feed of the latest tags. Entries link to the tag
+// tarballs, so feed readers can download releases.
+func tagsFeed(cfg *config.Config, wr *webRepo, rr *git.Repository) (*atomFeed, error) {
+	names, err := rr.LatestTags(feedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &atomFeed{
+		Title: wr.Name + " tags",
+		Links: []atomLink{{Href: cfg.HTTP.PublicURL + wr.URL()}},
+	}
+	for _, name := range names {
+		tag, err := rr.Tag(name)
+		if err != nil {
+			return nil, err
+		}
+		c, err := tag.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		// Lightweight tags don't have a tagger or a message of their own.
+		signer, msg := tag.Tagger(), tag.Message()
+		if signer == nil {
+			signer, msg = c.Committer, c.Message
+		}
+
+		url := cfg.HTTP.PublicURL + wr.TreeURL(name, "")
+		e := atomEntry{
+			ID:     url,
+			Title:  name,
+			Author: newAtomPerson(signer),
+			Links: []atomLink{
+				{Href: url},
+				{Href: cfg.HTTP.PublicURL + webURL(wr.Name, "archive", name+".tar.gz"), Rel: "enclosure", Type: "application/gzip"},
+			},
+			Content: &atomText{Type: "text", Body: strings.TrimSpace(msg)},
+		}
+		if signer != nil {
+			e.Updated = atomTime(signer.When)
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+	if len(feed.Entries) > 0 {
+		feed.Updated = feed.Entries[0].Updated
+	}
+
+	return feed, nil
+}
@@ -20,7 +20,9 @@ import (
 	"github.com/charmbracelet/soft-serve/server/config"
 	"github.com/charmbracelet/soft-serve/server/git"
 	"github.com/charmbracelet/soft-serve/server/lfs"
+	logr "github.com/charmbracelet/soft-serve/server/log"
 	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/stats"
 	"github.com/charmbracelet/soft-serve/server/utils"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -75,7 +77,7 @@ var (
 func withParams(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		cfg := config.FromContext(ctx)
+		be := backend.FromContext(ctx)
 		vars := mux.Vars(r)
 		repo := vars["repo"]
 
@@ -92,7 +94,7 @@ func withParams(h http.Handler) http.Handler {
 
 		repo = utils.SanitizeRepo(repo)
 		vars["repo"] = repo
-		vars["dir"] = filepath.Join(cfg.DataPath, "repos", repo+".git")
+		vars["dir"] = be.RepoPath(repo)
 
 		// Add repo suffix (.git)
 		r.URL.Path = fmt.Sprintf("%s.git/%s", repo, vars["file"])
@@ -104,16 +106,56 @@ func withParams(h http.Handler) http.Handler {
 // GitController is a router for git services.
 func GitController(_ context.Context, r *mux.Router) {
 	basePrefix := "/{repo:.*}"
+
+	// Raw files, archives, feeds, and releases go first, their repository
+	// name isn't the longest match.
+	raw := GitRoute{method: rawMethods, handler: serviceRaw}
+	r.Handle(rawPath, withParams(withAccess(raw))).MatcherFunc(repoMatcher("raw"))
+	archive := GitRoute{method: archiveMethods, handler: serviceArchive}
+	r.Handle(archivePath, withParams(withAccess(archive))).MatcherFunc(repoMatcher("archive"))
+	feed := GitRoute{method: feedMethods, handler: serviceFeed}
+	r.Handle(feedPath, withParams(withAccess(feed))).MatcherFunc(repoMatcher("feeds"))
+	release := GitRoute{method: releaseMethods, handler: serviceReleaseAsset}
+	r.Handle(releasePath, withParams(withAccess(release))).MatcherFunc(repoMatcher("releases"))
+
 	for _, route := range gitRoutes {
 		// NOTE: withParam must always be the outermost wrapper, otherwise the
 		// request vars will not be set.
-		r.Handle(basePrefix+route.path, withParams(withAccess(route)))
+		r.Handle(basePrefix+route.path, withParams(withGitLogger(withReplicaProxy(withAccess(route)))))
 	}
 
 	// Handle go-get
 	r.Handle(basePrefix, withParams(withAccess(GoGetHandler{}))).Methods(http.MethodGet)
 }
 
+// withGitLogger logs the requests of the git routes with the logger of the git
+// subsystem.
+func withGitLogger(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := logr.WithPrefix(log.FromContext(ctx), logr.SubsystemGit+".http")
+		h.ServeHTTP(w, r.WithContext(log.WithContext(ctx, logger)))
+	})
+}
+
+// repoMatcher matches requests of paths under a segment of an existing
+// repository, "/<repo>/<segment>/...". The repository name is the shortest
+// match, file and ref names are likelier to have the segment than repository
+// names. Requests of repositories named after the segment fall through to
+// the git routes.
+func repoMatcher(segment string) mux.MatcherFunc {
+	return func(r *http.Request, _ *mux.RouteMatch) bool {
+		repo, _, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/"+segment+"/")
+		if !ok || repo == "" {
+			return false
+		}
+
+		be := backend.FromContext(r.Context())
+		_, err := be.Repository(r.Context(), utils.SanitizeRepo(repo))
+		return err == nil
+	}
+}
+
 var gitRoutes = []GitRoute{
 	// Git services
 	// These routes don't handle authentication/authorization.
@@ -187,6 +229,12 @@ var gitRoutes = []GitRoute{
 		handler: serviceLfsLocksDelete,
 		path:    "/info/lfs/locks/{lock_id:[0-9]+}/unlock",
 	},
+	// Read-only WebDAV export of the repository trees
+	{
+		method:  davMethods,
+		handler: serviceDav,
+		path:    "/{_:dav(?:/.*)?$}",
+	},
 }
 
 func askCredentials(w http.ResponseWriter, _ *http.Request) {
@@ -215,6 +263,13 @@ func withAccess(next http.Handler) http.HandlerFunc {
 			switch {
 			case errors.Is(err, ErrInvalidToken):
 			case errors.Is(err, proto.ErrUserNotFound):
+			case errors.Is(err, proto.ErrUserSuspended), errors.Is(err, proto.ErrAddressDenied):
+				// Suspended users don't fall back to anonymous access.
+				renderForbidden(w, r)
+				return
+			case errors.Is(err, proto.ErrRateLimited):
+				renderRateLimited(w, r, err)
+				return
 			default:
 				logger.Error("failed to authenticate", "err", err)
 			}
@@ -258,9 +313,23 @@ func withAccess(next http.Handler) http.HandlerFunc {
 				return
 			}
 
+			if err := be.CheckMaintenance(ctx, repoName); err != nil {
+				renderMaintenance(w, r, err)
+				return
+			}
+			if err := be.CheckArchived(ctx, repoName); err != nil {
+				renderMaintenance(w, r, err)
+				return
+			}
+
 			// Create the repo if it doesn't exist.
 			if repo == nil {
-				repo, err = be.CreateRepository(ctx, repoName, user, proto.RepositoryOptions{})
+				if !cfg.Current().Push.AutoCreate {
+					renderNotFound(w, r)
+					return
+				}
+
+				repo, err = be.CreateRepository(ctx, repoName, user, proto.RepositoryOptions{Private: cfg.Current().Push.AutoCreatePrivate})
 				if err != nil {
 					logger.Error("failed to create repository", "repo", repoName, "err", err)
 					renderInternalServerError(w, r)
@@ -287,6 +356,16 @@ func withAccess(next http.Handler) http.HandlerFunc {
 				return
 			}
 
+		case file == "dav" || strings.HasPrefix(file, "dav/"), strings.HasPrefix(file, "raw/"),
+			strings.HasPrefix(file, "archive/"), strings.HasPrefix(file, "feeds/"),
+			strings.HasPrefix(file, "releases/"), strings.HasPrefix(file, "compare/"), isWebPage(file):
+			// WebDAV clients and browsers only send credentials when asked to.
+			if repo != nil && user == nil && accessLevel < access.ReadOnlyAccess {
+				askCredentials(w, r)
+				renderUnauthorized(w, r)
+				return
+			}
+
 		case strings.HasPrefix(file, "info/lfs"):
 			if !cfg.LFS.Enabled {
 				logger.Debug("LFS is not enabled, skipping")
@@ -320,6 +399,18 @@ func withAccess(next http.Handler) http.HandlerFunc {
 						})
 						return
 					}
+					if err := be.CheckMaintenance(ctx, repoName); err != nil {
+						renderJSON(w, http.StatusServiceUnavailable, lfs.ErrorResponse{
+							Message: err.Error(),
+						})
+						return
+					}
+					if err := be.CheckArchived(ctx, repoName); err != nil {
+						renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
+							Message: err.Error(),
+						})
+						return
+					}
 				case http.MethodGet:
 					// Basic download
 				case http.MethodPost:
@@ -364,6 +455,11 @@ func withAccess(next http.Handler) http.HandlerFunc {
 	}
 }
 
+// maxCachedRequestSize is the size of the largest upload-pack request with a
+// cached response. Larger requests list many commits the client already has,
+// they're unlikely to be repeated.
+const maxCachedRequestSize = 64 << 10 // 64 KiB
+
 //nolint:revive
 func serviceRpc(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -380,14 +476,79 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 		gitHttpReceiveCounter.WithLabelValues(repoName)
 	}
 
-	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-result", service))
-	w.Header().Set("Connection", "Keep-Alive")
-	w.Header().Set("Transfer-Encoding", "chunked")
-	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.WriteHeader(http.StatusOK)
+	// Handle gzip encoding
+	reader := r.Body
+	defer reader.Close() // nolint: errcheck
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			logger.Errorf("failed to create gzip reader: %v", err)
+			renderInternalServerError(w, r)
+			return
+		}
+		defer gz.Close() // nolint: errcheck
+		reader = gz
+	}
 
+	be := backend.FromContext(ctx)
 	version := r.Header.Get("Git-Protocol")
 
+	// Fetches of the same references get the same response, serve it from
+	// the cache when possible.
+	var body io.Reader = reader
+	var cacheKey string
+	if service == git.UploadPackService {
+		buf, err := io.ReadAll(io.LimitReader(reader, maxCachedRequestSize+1))
+		if err != nil {
+			logger.Errorf("failed to read request: %v", err)
+			renderInternalServerError(w, r)
+			return
+		}
+		if len(buf) <= maxCachedRequestSize {
+			key, pack, ok := be.CachedPack(ctx, repoName, version, buf)
+			if ok {
+				writeServiceHeaders(w, service)
+				if _, err := w.Write(pack); err != nil {
+					logger.Errorf("failed to write data: %v", err)
+					return
+				}
+				req := git.NewRequestReader(bytes.NewReader(buf), service)
+				io.Copy(io.Discard, req) // nolint: errcheck
+				be.RecordTraffic(ctx, repoName, req.Request())
+				return
+			}
+			cacheKey = key
+		}
+		body = io.MultiReader(bytes.NewReader(buf), reader)
+	}
+
+	release, err := be.AcquireWorker(ctx, repoName)
+	if err != nil {
+		if errors.Is(err, proto.ErrServerBusy) {
+			renderStatus(http.StatusServiceUnavailable)(w, r)
+			return
+		}
+		renderInternalServerError(w, r)
+		return
+	}
+	defer release()
+
+	if service == git.ReceivePackService {
+		unlock, err := be.LockPush(ctx, repoName)
+		if err != nil {
+			if errors.Is(err, proto.ErrRepoLocked) {
+				renderStatus(http.StatusServiceUnavailable)(w, r)
+				return
+			}
+			renderInternalServerError(w, r)
+			return
+		}
+		defer unlock()
+	}
+
+	writeServiceHeaders(w, service)
+
 	var stdout bytes.Buffer
 	cmd := git.ServiceCommand{
 		Stdout: &stdout,
@@ -401,10 +562,12 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 		"SOFT_SERVE_REPO_NAME=" + repoName,
 		"SOFT_SERVE_REPO_PATH=" + dir,
 		"SOFT_SERVE_LOG_PATH=" + filepath.Join(cfg.DataPath, "log", "hooks.log"),
+		"SOFT_SERVE_REMOTE_ADDR=" + r.RemoteAddr,
 	}...)
 	if user != nil {
 		cmd.Env = append(cmd.Env, []string{
 			"SOFT_SERVE_USERNAME=" + user.Username(),
+			"SOFT_SERVE_ACCESS_LEVEL=" + access.FromContext(ctx).String(),
 		}...)
 	}
 	if len(version) != 0 {
@@ -413,23 +576,13 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 		}...)
 	}
 
-	// Handle gzip encoding
-	reader := r.Body
-	defer reader.Close() // nolint: errcheck
-	switch r.Header.Get("Content-Encoding") {
-	case "gzip":
-		reader, err := gzip.NewReader(reader)
-		if err != nil {
-			logger.Errorf("failed to create gzip reader: %v", err)
-			renderInternalServerError(w, r)
-			return
-		}
-		defer reader.Close() // nolint: errcheck
-	}
-
-	cmd.Stdin = reader
+	req := git.NewRequestReader(body, service)
+	cmd.Stdin = req
 
-	if err := service.Handler(ctx, cmd); err != nil {
+	observe := stats.ObserveGit(stats.TransportHTTP, service, repoName, &cmd)
+	err = service.Handler(ctx, cmd)
+	observe()
+	if err != nil {
 		if errors.Is(err, git.ErrInvalidRepo) {
 			renderNotFound(w, r)
 			return
@@ -438,6 +591,8 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	be.CachePack(ctx, cacheKey, stdout.Bytes())
+
 	// Handle buffered output
 	// Useful when using proxies
 
@@ -470,7 +625,20 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 		if err := git.EnsureDefaultBranch(ctx, cmd); err != nil {
 			logger.Errorf("failed to ensure default branch: %s", err)
 		}
+		be.InvalidateCache(ctx, repoName)
+		be.NotifyPush(ctx, repoName, req.Request())
 	}
+
+	be.RecordTraffic(ctx, repoName, req.Request())
+}
+
+// writeServiceHeaders writes the headers of the response of a git service.
+func writeServiceHeaders(w http.ResponseWriter, service git.Service) {
+	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-result", service))
+	w.Header().Set("Connection", "Keep-Alive")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
 }
 
 func getInfoRefs(w http.ResponseWriter, r *http.Request) {
@@ -483,6 +651,13 @@ func getInfoRefs(w http.ResponseWriter, r *http.Request) {
 	gitHttpUploadCounter.WithLabelValues(repoName, file).Inc()
 
 	if service != "" && (service == git.UploadPackService || service == git.ReceivePackService) {
+		// Every clone, fetch, or push starts by advertising the references.
+		be := backend.FromContext(ctx)
+		if err := be.RateLimit(ctx, backend.RateGitOperation, proto.UserFromContext(ctx), r.RemoteAddr); err != nil {
+			renderRateLimited(w, r, err)
+			return
+		}
+
 		// Smart HTTP
 		var refs bytes.Buffer
 		cmd := git.ServiceCommand{
@@ -613,6 +788,25 @@ func renderForbidden(w http.ResponseWriter, r *http.Request) {
 	renderStatus(http.StatusForbidden)(w, r)
 }
 
+// renderMaintenance renders the error of a write rejected by the maintenance
+// mode, by a read-only replica, or by an archived repository. Git shows the
+// plain text message to the user.
+func renderMaintenance(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusServiceUnavailable
+	switch {
+	case errors.Is(err, proto.ErrMaintenance):
+	case errors.Is(err, proto.ErrReadOnly), errors.Is(err, proto.ErrArchived):
+		status = http.StatusForbidden
+	default:
+		renderInternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	io.WriteString(w, err.Error()+"\n") // nolint: errcheck
+}
+
 func renderInternalServerError(w http.ResponseWriter, r *http.Request) {
 	renderStatus(http.StatusInternalServerError)(w, r)
 }
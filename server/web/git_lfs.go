@@ -1,6 +1,7 @@
 package web
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,9 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"path"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/server/access"
@@ -26,6 +27,9 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// lfsLinkExpiry is how long presigned object links are valid for.
+const lfsLinkExpiry = 15 * time.Minute
+
 // serviceLfsBatch handles a Git LFS batch requests.
 // https://github.com/git-lfs/git-lfs/blob/main/docs/api/batch.md
 // TODO: support refname
@@ -88,9 +92,8 @@ func serviceLfsBatch(w http.ResponseWriter, r *http.Request) {
 	cfg := config.FromContext(ctx)
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
-	// TODO: support S3 storage
 	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg := storage.NewLFSStorage(cfg, repoID)
 
 	baseHref := fmt.Sprintf("%s/%s/info/lfs/objects/basic", cfg.HTTP.PublicURL, name+".git")
 
@@ -142,7 +145,21 @@ func serviceLfsBatch(w http.ResponseWriter, r *http.Request) {
 				download := &lfs.Link{
 					Href: fmt.Sprintf("%s/%s", baseHref, o.Oid),
 				}
-				if auth := r.Header.Get("Authorization"); auth != "" {
+				if ps, ok := strg.(storage.Presigner); ok {
+					// Let the client download the object directly from the
+					// storage.
+					href, err := ps.PresignGet(path.Join("objects", o.RelativePath()), lfsLinkExpiry)
+					if err != nil {
+						logger.Error("error presigning object download", "oid", o.Oid, "repo", name, "err", err)
+						renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+							Message: "internal server error",
+						})
+						return
+					}
+					expiresAt := time.Now().Add(lfsLinkExpiry)
+					download.Href = href
+					download.ExpiresAt = &expiresAt
+				} else if auth := r.Header.Get("Authorization"); auth != "" {
 					download.Header = map[string]string{
 						"Authorization": auth,
 					}
@@ -187,6 +204,39 @@ func serviceLfsBatch(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if err := backend.FromContext(ctx).CheckMaintenance(ctx, name); err != nil {
+			renderJSON(w, http.StatusServiceUnavailable, lfs.ErrorResponse{
+				Message: err.Error(),
+			})
+			return
+		}
+		if err := backend.FromContext(ctx).CheckArchived(ctx, name); err != nil {
+			renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
+				Message: err.Error(),
+			})
+			return
+		}
+
+		var size int64
+		for _, o := range batchRequest.Objects {
+			if o.IsValid() {
+				size += o.Size
+			}
+		}
+		if err := backend.FromContext(ctx).CheckDiskQuota(ctx, name, size); err != nil {
+			if errors.Is(err, proto.ErrQuotaExceeded) {
+				renderJSON(w, http.StatusInsufficientStorage, lfs.ErrorResponse{
+					Message: err.Error(),
+				})
+				return
+			}
+			logger.Error("error checking disk quota", "repo", name, "err", err)
+			renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+				Message: "internal server error",
+			})
+			return
+		}
+
 		// Object upload logic happens in the "basic" API route
 		for _, o := range batchRequest.Objects {
 			if !o.IsValid() {
@@ -209,8 +259,31 @@ func serviceLfsBatch(w http.ResponseWriter, r *http.Request) {
 				verify := &lfs.Link{
 					Href: fmt.Sprintf("%s/verify", baseHref),
 				}
-				if auth := r.Header.Get("Authorization"); auth != "" {
+				auth := r.Header.Get("Authorization")
+				if ps, ok := strg.(storage.Presigner); ok {
+					// Let the client upload the object directly to the
+					// storage. The storage checks the content against the
+					// object ID, and the object is added to the database
+					// once it's verified.
+					checksum, _ := hex.DecodeString(o.Oid)
+					href, hdr, err := ps.PresignPut(path.Join("objects", o.RelativePath()), checksum, lfsLinkExpiry)
+					if err != nil {
+						logger.Error("error presigning object upload", "oid", o.Oid, "repo", name, "err", err)
+						renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+							Message: "internal server error",
+						})
+						return
+					}
+					expiresAt := time.Now().Add(lfsLinkExpiry)
+					upload.Href = href
+					upload.ExpiresAt = &expiresAt
+					for k := range hdr {
+						upload.Header[k] = hdr.Get(k)
+					}
+				} else if auth != "" {
 					upload.Header["Authorization"] = auth
+				}
+				if auth != "" {
 					verify.Header = map[string]string{
 						"Authorization": auth,
 					}
@@ -259,7 +332,7 @@ func serviceLfsBasicDownload(w http.ResponseWriter, r *http.Request) {
 	datastore := store.FromContext(ctx)
 	dbx := db.FromContext(ctx)
 	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg := storage.NewLFSStorage(cfg, repoID)
 
 	obj, err := datastore.GetLFSObjectByOid(ctx, dbx, repo.ID(), oid)
 	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
@@ -310,7 +383,7 @@ func serviceLfsBasicUpload(w http.ResponseWriter, r *http.Request) {
 	logger := log.FromContext(ctx).WithPrefix("http.lfs-basic")
 	repo := proto.RepositoryFromContext(ctx)
 	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg := storage.NewLFSStorage(cfg, repoID)
 	name := mux.Vars(r)["repo"]
 
 	defer r.Body.Close() // nolint: errcheck
@@ -398,10 +471,17 @@ func serviceLfsBasicVerify(w http.ResponseWriter, r *http.Request) {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg := storage.NewLFSStorage(cfg, repoID)
 	if stat, err := strg.Stat(path.Join("objects", pointer.RelativePath())); err == nil {
 		// Verify object is in the database.
 		obj, err := datastore.GetLFSObjectByOid(ctx, dbx, repo.ID(), pointer.Oid)
+		if _, ok := strg.(storage.Presigner); ok && errors.Is(err, db.ErrRecordNotFound) &&
+			pointer.IsValid() && stat.Size() == pointer.Size {
+			// Objects uploaded directly to the storage are added to the
+			// database once they're verified.
+			err = datastore.CreateLFSObject(ctx, dbx, repo.ID(), pointer.Oid, pointer.Size)
+			obj.Size = pointer.Size
+		}
 		if err != nil {
 			if errors.Is(err, db.ErrRecordNotFound) {
 				logger.Error("object not found", "oid", pointer.Oid)
@@ -491,6 +571,13 @@ func serviceLfsLocksCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Path == "" {
+		renderJSON(w, http.StatusBadRequest, lfs.ErrorResponse{
+			Message: "invalid request: missing path",
+		})
+		return
+	}
+
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 	if err := datastore.CreateLFSLockForUser(ctx, dbx, repo.ID(), user.ID(), req.Path, req.Ref.Name); err != nil {
@@ -501,7 +588,7 @@ func serviceLfsLocksCreate(w http.ResponseWriter, r *http.Request) {
 					Message: "lock already exists",
 				},
 			}
-			lock, err := datastore.GetLFSLockForUserPath(ctx, dbx, repo.ID(), user.ID(), req.Path)
+			lock, err := datastore.GetLFSLockForPath(ctx, dbx, repo.ID(), req.Path)
 			if err == nil {
 				errResp.Lock = lfs.Lock{
 					ID:       strconv.FormatInt(lock.ID, 10),
@@ -609,6 +696,9 @@ func serviceLfsLocksGet(w http.ResponseWriter, r *http.Request) {
 
 	if id > 0 {
 		lock, err := datastore.GetLFSLockByID(ctx, dbx, id)
+		if err == nil && lock.RepoID != repo.ID() {
+			err = db.ErrRecordNotFound
+		}
 		if err != nil {
 			if errors.Is(err, db.ErrRecordNotFound) {
 				renderJSON(w, http.StatusNotFound, lfs.ErrorResponse{
@@ -877,6 +967,9 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 
 	// The lock being deleted
 	lock, err := datastore.GetLFSLockByID(ctx, dbx, lockID)
+	if err == nil && lock.RepoID != repo.ID() {
+		err = db.ErrRecordNotFound
+	}
 	if err != nil {
 		logger.Error("error getting lock", "err", err)
 		renderJSON(w, http.StatusNotFound, lfs.ErrorResponse{
@@ -894,7 +987,6 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete another user's lock
 	l := lfs.Lock{
 		ID:       strconv.FormatInt(lock.ID, 10),
 		Path:     lock.Path,
@@ -903,20 +995,7 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 			Name: owner.Username,
 		},
 	}
-	if req.Force {
-		if err := datastore.DeleteLFSLock(ctx, dbx, repo.ID(), lockID); err != nil {
-			logger.Error("error deleting lock", "err", err)
-			renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
-				Message: "internal server error",
-			})
-			return
-		}
-
-		renderJSON(w, http.StatusOK, l)
-		return
-	}
 
-	// Delete our own lock
 	user := proto.UserFromContext(ctx)
 	if user == nil {
 		logger.Error("error getting user from context")
@@ -927,11 +1006,22 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if owner.ID != user.ID() {
-		logger.Error("error deleting another user's lock")
-		renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
-			Message: "lock belongs to another user",
-		})
-		return
+		// Only repository admins can force delete another user's lock.
+		if !req.Force {
+			logger.Error("error deleting another user's lock")
+			renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
+				Message: "lock belongs to another user",
+			})
+			return
+		}
+
+		if access.FromContext(ctx) < access.AdminAccess {
+			logger.Error("error force deleting lock without admin access")
+			renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
+				Message: "admin access required to delete another user's lock",
+			})
+			return
+		}
 	}
 
 	if err := datastore.DeleteLFSLock(ctx, dbx, repo.ID(), lockID); err != nil {
@@ -2,13 +2,14 @@ package web
 
 import (
 	"net/http"
-	"net/url"
 	"path"
 	"text/template"
 
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/access"
 	"github.com/charmbracelet/soft-serve/server/backend"
 	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
 	"github.com/charmbracelet/soft-serve/server/utils"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -26,11 +27,12 @@ var repoIndexHTMLTpl = template.Must(template.New("index").Parse(`<!DOCTYPE html
 <html lang="en">
 <head>
     <meta http-equiv="Content-Type" content="text/html; charset=utf-8"/>
-    <meta http-equiv="refresh" content="0; url=https://godoc.org/{{ .ImportRoot }}/{{.Repo}}">
-    <meta name="go-import" content="{{ .ImportRoot }}/{{ .Repo }} git {{ .Config.HTTP.PublicURL }}/{{ .Repo }}.git">
+    <meta http-equiv="refresh" content="0; url=https://godoc.org/{{ .ImportPath }}">
+    <meta name="go-import" content="{{ .ImportPath }} git {{ .RepoURL }}.git">
+    <meta name="go-source" content="{{ .ImportPath }} {{ .RepoURL }} {{ .RepoURL }}/tree/HEAD{/dir} {{ .RepoURL }}/tree/HEAD{/dir}/{file}#L{line}">
 </head>
 <body>
-Redirecting to docs at <a href="https://godoc.org/{{ .ImportRoot }}/{{ .Repo }}">godoc.org/{{ .ImportRoot }}/{{ .Repo }}</a>...
+Redirecting to docs at <a href="https://godoc.org/{{ .ImportPath }}">godoc.org/{{ .ImportPath }}</a>...
 </body>
 </html>
 `))
@@ -45,54 +47,63 @@ func (g GoGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	cfg := config.FromContext(ctx)
 	be := backend.FromContext(ctx)
 	logger := log.FromContext(ctx)
-	repo := mux.Vars(r)["repo"]
+	name := mux.Vars(r)["repo"]
 
 	// Handle go get requests.
 	//
 	// Always return a 200 status code, even if the repo path doesn't exist.
-	// It will try to find the repo by walking up the path until it finds one.
+	// It will try to find the repo by walking up the path until it finds one
+	// named after the path, or with the path as its Go module path.
 	// If it can't find one, it will return a 404.
 	//
 	// https://golang.org/cmd/go/#hdr-Remote_import_paths
 	// https://go.dev/ref/mod#vcs-branch
 	if r.URL.Query().Get("go-get") == "1" {
-		repo := repo
-		importRoot, err := url.Parse(cfg.HTTP.PublicURL)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+		// find the repo, either by its name or by its module path
+		var repo proto.Repository
+		for p := utils.SanitizeRepo(name); ; p = path.Dir(p) {
+			if p == "" || p == "." || p == "/" {
+				return
+			}
 
-		// find the repo
-		for {
-			if _, err := be.Repository(ctx, repo); err == nil {
+			var err error
+			if repo, err = be.Repository(ctx, p); err == nil {
 				break
 			}
-
-			if repo == "" || repo == "." || repo == "/" {
-				return
+			if repo, err = be.RepositoryByGoModule(ctx, p); err == nil {
+				break
 			}
+		}
+
+		// The requested path may not be the one the access was checked against.
+		if be.AccessLevelForUser(ctx, repo.Name(), proto.UserFromContext(ctx)) < access.ReadOnlyAccess {
+			renderNotFound(w, r)
+			return
+		}
 
-			repo = path.Dir(repo)
+		importPath, err := be.GoImportPath(ctx, repo.Name())
+		if err != nil {
+			logger.Error("failed to get go import path", "repo", repo.Name(), "err", err)
+			renderInternalServerError(w, r)
+			return
 		}
 
 		if err := repoIndexHTMLTpl.Execute(w, struct {
-			Repo       string
-			Config     *config.Config
-			ImportRoot string
+			ImportPath string
+			RepoURL    string
 		}{
-			Repo:       utils.SanitizeRepo(repo),
-			Config:     cfg,
-			ImportRoot: importRoot.Host,
+			ImportPath: importPath,
+			RepoURL:    cfg.HTTP.PublicURL + "/" + repo.Name(),
 		}); err != nil {
 			logger.Error("failed to render go get template", "err", err)
 			renderInternalServerError(w, r)
 			return
 		}
 
-		goGetCounter.WithLabelValues(repo).Inc()
+		goGetCounter.WithLabelValues(repo.Name()).Inc()
 		return
 	}
 
-	renderNotFound(w, r)
+	// Browsers landing on the repository get its summary.
+	serviceWebRepo(w, r)
 }
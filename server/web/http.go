@@ -2,55 +2,128 @@ package web
 
 import (
 	"context"
+	"errors"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/listener"
+	logr "github.com/charmbracelet/soft-serve/server/log"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 // HTTPServer is an http server.
 type HTTPServer struct {
-	ctx    context.Context
-	cfg    *config.Config
-	server *http.Server
+	ctx       context.Context
+	cfg       *config.Config
+	logger    *log.Logger
+	server    *http.Server
+	listeners []net.Listener
+	// challenge answers the ACME HTTP-01 challenges and redirects to HTTPS,
+	// if it's enabled.
+	challenge          *http.Server
+	challengeListeners []net.Listener
 }
 
 // NewHTTPServer creates a new HTTP server.
 func NewHTTPServer(ctx context.Context) (*HTTPServer, error) {
 	cfg := config.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("http")
+	logger := logr.WithTrackedPrefix(log.FromContext(ctx), logr.SubsystemHTTP)
+	errorLog := logger.StandardLog(log.StandardLogOptions{ForceLevel: log.ErrorLevel})
 	s := &HTTPServer{
-		ctx: ctx,
-		cfg: cfg,
+		ctx:    ctx,
+		cfg:    cfg,
+		logger: logger,
 		server: &http.Server{
-			Addr:              cfg.HTTP.ListenAddr,
 			Handler:           NewRouter(ctx),
 			ReadHeaderTimeout: time.Second * 10,
 			ReadTimeout:       time.Second * 10,
 			WriteTimeout:      time.Second * 10,
 			MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
-			ErrorLog:          logger.StandardLog(log.StandardLogOptions{ForceLevel: log.ErrorLevel}),
+			ErrorLog:          errorLog,
 		},
 	}
 
+	if cfg.HTTP.ACME.Enabled {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(cfg.HTTP.ACME.CacheDir),
+			HostPolicy: autocert.HostWhitelist(cfg.HTTP.ACME.Domains...),
+			Email:      cfg.HTTP.ACME.Email,
+			Client:     &acme.Client{DirectoryURL: cfg.HTTP.ACME.DirectoryURL},
+		}
+		// The TLS configuration of the manager answers the TLS-ALPN-01
+		// challenges.
+		s.server.TLSConfig = m.TLSConfig()
+		if addr := cfg.HTTP.ACME.ChallengeListenAddr; addr != "" {
+			ls, err := listener.Listen(listener.ACME, addr)
+			if err != nil {
+				return nil, err
+			}
+			s.challengeListeners = ls
+			s.challenge = &http.Server{
+				Handler:           m.HTTPHandler(nil),
+				ReadHeaderTimeout: time.Second * 10,
+				ReadTimeout:       time.Second * 10,
+				WriteTimeout:      time.Second * 10,
+				MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+				ErrorLog:          errorLog,
+			}
+		}
+	}
+
+	ls, err := listener.Listen(listener.HTTP, cfg.HTTP.ListenAddr)
+	if err != nil {
+		return nil, err
+	}
+	s.listeners = ls
+
 	return s, nil
 }
 
+// Addr returns the addresses the HTTP server listens on, its listen addresses
+// or the sockets passed by systemd.
+func (s *HTTPServer) Addr() string {
+	return listener.String(s.listeners)
+}
+
 // Close closes the HTTP server.
 func (s *HTTPServer) Close() error {
+	if s.challenge != nil {
+		s.challenge.Close() // nolint: errcheck
+	}
 	return s.server.Close()
 }
 
 // ListenAndServe starts the HTTP server.
 func (s *HTTPServer) ListenAndServe() error {
+	if s.cfg.HTTP.ACME.Enabled {
+		if s.challenge != nil {
+			go func() {
+				if err := listener.Serve(s.challengeListeners, s.challenge.Serve); !errors.Is(err, http.ErrServerClosed) {
+					s.logger.Error("acme challenge server error", "err", err)
+				}
+			}()
+		}
+		return listener.Serve(s.listeners, func(l net.Listener) error {
+			return s.server.ServeTLS(l, "", "")
+		})
+	}
 	if s.cfg.HTTP.TLSKeyPath != "" && s.cfg.HTTP.TLSCertPath != "" {
-		return s.server.ListenAndServeTLS(s.cfg.HTTP.TLSCertPath, s.cfg.HTTP.TLSKeyPath)
+		return listener.Serve(s.listeners, func(l net.Listener) error {
+			return s.server.ServeTLS(l, s.cfg.HTTP.TLSCertPath, s.cfg.HTTP.TLSKeyPath)
+		})
 	}
-	return s.server.ListenAndServe()
+	return listener.Serve(s.listeners, s.server.Serve)
 }
 
 // Shutdown gracefully shuts down the HTTP server.
 func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	if s.challenge != nil {
+		s.challenge.Shutdown(ctx) // nolint: errcheck
+	}
 	return s.server.Shutdown(ctx)
 }
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+)
+
+// NewIPAccessHandler returns a middleware rejecting the requests from
+// addresses that aren't allowed by the server IP access rules.
+func NewIPAccessHandler(ctx context.Context) func(http.Handler) http.Handler {
+	be := backend.FromContext(ctx)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := be.CheckAddress(ctx, "http", r.RemoteAddr, nil); err != nil {
+				renderForbidden(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
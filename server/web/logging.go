@@ -2,15 +2,64 @@ package web
 
 import (
 	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
 	"time"
 
 	"github.com/charmbracelet/log"
+	logr "github.com/charmbracelet/soft-serve/server/log"
 	"github.com/dustin/go-humanize"
 )
 
+// requestIDHeader is the header of request IDs. Clients can set it to follow
+// their requests in the logs.
+const requestIDHeader = "X-Request-Id"
+
+type requestIDKey struct{}
+
+// requestIDFromContext returns the ID of the request of a context.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestID returns the ID of a request, the ID set by the client if it's
+// valid, or a random one.
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); isRequestID(id) {
+		return id
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// isRequestID reports whether a request ID can be logged as is.
+func isRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
 // logWriter is a wrapper around http.ResponseWriter that allows us to capture
 // the HTTP status code and bytes written to the response.
 type logWriter struct {
@@ -47,6 +96,12 @@ func (r *logWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying http.ResponseWriter, it's used by
+// http.ResponseController.
+func (r *logWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // CloseNotify implements http.CloseNotifier.
 func (r *logWriter) CloseNotify() <-chan bool {
 	if cn, ok := r.ResponseWriter.(http.CloseNotifier); ok { // nolint: staticcheck
@@ -66,7 +121,18 @@ func (r *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 // NewLoggingMiddleware returns a new logging middleware.
 func NewLoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger := log.FromContext(r.Context())
+		id := requestID(r)
+		if id != "" {
+			w.Header().Set(requestIDHeader, id)
+			r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+		}
+
+		if !logr.SampleTransport() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logger := log.FromContext(r.Context()).With("request_id", id)
 		start := time.Now()
 		writer := &logWriter{code: http.StatusOK, ResponseWriter: w}
 		logger.Debug("request",
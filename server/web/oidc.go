@@ -0,0 +1,188 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/auth"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// oidcCookie is the cookie holding the state and nonce of a login.
+	oidcCookie = "soft_serve_oidc"
+	// oidcLoginTimeout is the time users have to log in with the provider.
+	oidcLoginTimeout = 10 * time.Minute
+)
+
+// OIDCController registers the OpenID Connect login routes. It must be
+// registered before GitController, repository routes match any path.
+func OIDCController(_ context.Context, r *mux.Router) {
+	r.HandleFunc("/login", serviceLogin).Methods(http.MethodGet)
+	r.HandleFunc("/login/callback", serviceLoginCallback).Methods(http.MethodGet)
+}
+
+// serviceLogin redirects users to the provider to log in.
+func serviceLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx).WithPrefix("http.oidc")
+	be := backend.FromContext(ctx)
+	provider := be.OIDC()
+	if provider == nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	state, nonce := randomString(), randomString()
+	if state == "" || nonce == "" {
+		renderInternalServerError(w, r)
+		return
+	}
+
+	u, err := provider.AuthCodeURL(ctx, callbackURL(ctx), state, nonce)
+	if err != nil {
+		logger.Error("failed to discover provider", "err", err)
+		renderStatus(http.StatusBadGateway)(w, r)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookie,
+		Value:    state + "." + nonce,
+		Path:     "/login",
+		MaxAge:   int(oidcLoginTimeout.Seconds()),
+		Secure:   strings.HasPrefix(config.FromContext(ctx).HTTP.PublicURL, "https://"),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	hdrNocache(w)
+	http.Redirect(w, r, u, http.StatusFound)
+}
+
+// serviceLoginCallback completes the login of a user redirected back by the
+// provider, and issues them an access token.
+func serviceLoginCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx).WithPrefix("http.oidc")
+	be := backend.FromContext(ctx)
+	provider := be.OIDC()
+	if provider == nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	cookie, err := r.Cookie(oidcCookie)
+	if err != nil {
+		renderLoginError(w, http.StatusBadRequest, "login expired, try again")
+		return
+	}
+
+	// The cookie is single use.
+	http.SetCookie(w, &http.Cookie{Name: oidcCookie, Path: "/login", MaxAge: -1})
+
+	state, nonce, _ := strings.Cut(cookie.Value, ".")
+	query := r.URL.Query()
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		renderLoginError(w, http.StatusBadRequest, "invalid state, try again")
+		return
+	}
+
+	if e := query.Get("error"); e != "" {
+		logger.Info("login denied by provider", "error", e, "description", query.Get("error_description"))
+		renderLoginError(w, http.StatusForbidden, "login denied by the provider")
+		return
+	}
+
+	id, err := provider.Exchange(ctx, callbackURL(ctx), query.Get("code"), nonce)
+	if err != nil {
+		logger.Error("failed to verify identity", "err", err)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			renderLoginError(w, http.StatusForbidden, "invalid identity")
+		} else {
+			renderLoginError(w, http.StatusBadGateway, "failed to verify identity with the provider")
+		}
+		return
+	}
+
+	user, err := be.UserByIdentity(ctx, id)
+	if err != nil {
+		logger.Info("login rejected", "subject", id.Subject, "username", id.Username, "err", err)
+		be.Audit(ctx, proto.AuditEvent{
+			Action:   proto.AuditAuthFailure,
+			Username: id.Username,
+			Details:  "oidc: " + err.Error(),
+		})
+		switch {
+		case errors.Is(err, backend.ErrIdentityNotLinked):
+			renderLoginError(w, http.StatusForbidden,
+				fmt.Sprintf("identity %q is not linked to a user, ask an admin to link it", id.Subject))
+		case errors.Is(err, proto.ErrUserSuspended):
+			renderLoginError(w, http.StatusForbidden, "user is suspended")
+		default:
+			renderInternalServerError(w, r)
+		}
+		return
+	}
+
+	if err := be.CheckAddress(ctx, "http", r.RemoteAddr, user); err != nil {
+		renderLoginError(w, http.StatusForbidden, "address not allowed")
+		return
+	}
+
+	token, expiresAt, err := be.CreateLoginToken(ctx, user)
+	if err != nil {
+		logger.Error("failed to create login token", "username", user.Username(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	logger.Info("user logged in", "username", user.Username(), "subject", id.Subject)
+	be.Audit(ctx, proto.AuditEvent{Action: proto.AuditAuthSuccess, Username: user.Username(), Details: "oidc: " + id.Subject})
+	hdrNocache(w)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Logged in as %s.\n\nToken: %s\nExpires: %s\n\n"+ // nolint: errcheck
+		"Use the token as your password with Git over HTTP, for example:\n  git clone %s\n",
+		user.Username(), token, expiresAt.UTC().Format(time.RFC3339), cloneURL(ctx, user.Username()))
+}
+
+func renderLoginError(w http.ResponseWriter, code int, msg string) {
+	hdrNocache(w)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, "%d %s: %s\n", code, http.StatusText(code), msg) // nolint: errcheck
+}
+
+// callbackURL returns the URL the provider redirects users back to.
+func callbackURL(ctx context.Context) string {
+	return config.FromContext(ctx).HTTP.PublicURL + "/login/callback"
+}
+
+// cloneURL returns an example clone URL with the username of a user.
+func cloneURL(ctx context.Context, username string) string {
+	u := config.FromContext(ctx).HTTP.PublicURL
+	if scheme, rest, ok := strings.Cut(u, "://"); ok {
+		u = scheme + "://" + username + "@" + rest
+	}
+	return u + "/<repo>.git"
+}
+
+// randomString returns a random hex string, or an empty string on failure.
+func randomString() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/repofs"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var patchCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "patch_total",
+	Help:      "The total number of patch downloads",
+}, []string{"repo", "format"})
+
+// patchMethods are the methods allowed on patches.
+var patchMethods = []string{http.MethodGet, http.MethodHead}
+
+// Paths of patch requests, of a commit and of a range of commits.
+const (
+	commitPatchPath  = "/{repo:.+?}/{_:commit/[0-9a-f]{4,40}\\.(?:patch|diff)$}"
+	comparePatchPath = "/{repo:.+?}/{_:compare/.+\\.\\.\\..+\\.(?:patch|diff)$}"
+)
+
+// servicePatch streams the changes of a commit at /<repo>/commit/<hash>.patch,
+// or of the commits from base to head at
+// /<repo>/compare/<base>...<head>.patch, in the format of git format-patch.
+// The .diff extension streams a plain diff instead, against the merge base
+// of ranges. Patches are tagged with their commit hashes.
+func servicePatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	name := mux.Vars(r)["repo"]
+	file := mux.Vars(r)["file"]
+	ext := path.Ext(file)
+	spec := strings.TrimSuffix(file, ext)
+	patchCounter.WithLabelValues(name, strings.TrimPrefix(ext, ".")).Inc()
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", name, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	// Commit hashes always point to the same changes, branches and tags can
+	// move.
+	immutable := true
+	resolve := func(ref string) (string, bool) {
+		rev, p, ok := repofs.New(rr).Revision(ref)
+		if !ok || p != "" {
+			return "", false
+		}
+
+		c, err := rr.CatFileCommit(rev + "^{commit}")
+		if err != nil {
+			return "", false
+		}
+
+		if strings.HasPrefix(rev, "refs/") || !strings.HasPrefix(c.ID.String(), ref) {
+			immutable = false
+		}
+
+		return c.ID.String(), true
+	}
+
+	var base, head string
+	var ok bool
+	if h, isCommit := strings.CutPrefix(spec, "commit/"); isCommit {
+		head, ok = resolve(h)
+	} else {
+		b, h, _ := strings.Cut(strings.TrimPrefix(spec, "compare/"), "...")
+		if base, ok = resolve(b); ok {
+			head, ok = resolve(h)
+		}
+	}
+	if !ok {
+		renderNotFound(w, r)
+		return
+	}
+
+	cache := "no-cache"
+	if immutable {
+		cache = "max-age=31536000, immutable"
+	}
+	if repo.IsPrivate() {
+		cache = "private, " + cache
+	}
+
+	etag := `"` + head + ext + `"`
+	if base != "" {
+		etag = `"` + base + ".." + head + ext + `"`
+	}
+	w.Header().Set("Cache-Control", cache)
+	w.Header().Set("ETag", etag)
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	release, err := be.AcquireWorker(ctx, name)
+	if err != nil {
+		if errors.Is(err, proto.ErrServerBusy) {
+			renderStatus(http.StatusServiceUnavailable)(w, r)
+			return
+		}
+		renderInternalServerError(w, r)
+		return
+	}
+	defer release()
+
+	write := (*git.Repository).FormatPatch
+	if ext == ".diff" {
+		write = (*git.Repository).DiffPatch
+	}
+
+	// The status is sent with the first bytes of the patch, errors can only
+	// be logged past that point.
+	if err := write(rr, w, base, head); err != nil {
+		logger.Error("failed to write patch", "repo", name, "base", base, "head", head, "err", err)
+	}
+}
@@ -0,0 +1,42 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+)
+
+// NewRateLimitHandler returns a middleware rejecting the requests from
+// addresses exceeding their HTTP request rate limit. The limits of users are
+// checked when they're authenticated.
+func NewRateLimitHandler(ctx context.Context) func(http.Handler) http.Handler {
+	be := backend.FromContext(ctx)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := be.RateLimit(ctx, backend.RateHTTPRequest, nil, r.RemoteAddr); err != nil {
+				renderRateLimited(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// renderRateLimited renders a rate limit error with the time to wait before
+// retrying.
+func renderRateLimited(w http.ResponseWriter, r *http.Request, err error) {
+	setRetryAfter(w, err)
+	renderStatus(http.StatusTooManyRequests)(w, r)
+}
+
+// setRetryAfter sets the Retry-After header of a rate limit error.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var rle *proto.RateLimitError
+	if errors.As(err, &rle) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(rle.RetryAfter.Seconds())))
+	}
+}
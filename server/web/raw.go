@@ -0,0 +1,124 @@
+package web
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/repofs"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var rawCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "raw_total",
+	Help:      "The total number of raw file requests",
+}, []string{"repo"})
+
+// rawMethods are the methods allowed on raw files.
+var rawMethods = []string{http.MethodGet, http.MethodHead}
+
+// rawPath is the path of raw file requests.
+const rawPath = "/{repo:.+?}/{_:raw/.*$}"
+
+// serviceRaw serves the raw content of a file at /<repo>/raw/<ref>/<path>.
+// The ref is a branch, a tag, a commit hash, or HEAD, the longest matching
+// branch or tag name wins.
+func serviceRaw(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	name := mux.Vars(r)["repo"]
+	file := strings.Trim(path.Clean("/"+strings.TrimPrefix(mux.Vars(r)["file"], "raw/")), "/")
+	rawCounter.WithLabelValues(name).Inc()
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", name, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	if file == "" {
+		renderNotFound(w, r)
+		return
+	}
+
+	f, err := repofs.New(rr).Open(file)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Error("failed to open file", "repo", name, "path", file, "err", err)
+		}
+		renderNotFound(w, r)
+		return
+	}
+	defer f.Close() // nolint: errcheck
+
+	fi, err := f.Stat()
+	if err != nil {
+		logger.Error("failed to stat file", "repo", name, "path", file, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	// Only blobs have raw content.
+	e, ok := fi.Sys().(*git.TreeEntry)
+	content, isSeeker := f.(io.ReadSeeker)
+	if !ok || fi.IsDir() || !isSeeker {
+		renderNotFound(w, r)
+		return
+	}
+
+	ct, err := rawContentType(fi.Name(), content)
+	if err != nil {
+		logger.Error("failed to read file", "repo", name, "path", file, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	// Files are served from the same origin as the web interface, don't let
+	// them run scripts there.
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+e.ID().String()+`"`)
+
+	// ServeContent handles range and conditional requests.
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), content)
+}
+
+// rawContentType returns the content type of a raw file, from its extension
+// or its content. Documents and scripts are served as plain text.
+func rawContentType(name string, content io.ReadSeeker) (string, error) {
+	ct := mime.TypeByExtension(path.Ext(name))
+	if ct == "" {
+		var buf [512]byte
+		n, err := io.ReadFull(content, buf[:])
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", err
+		}
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		ct = http.DetectContentType(buf[:n])
+	}
+
+	mt, _, _ := mime.ParseMediaType(ct)
+	switch {
+	case mt == "text/html", mt == "application/xhtml+xml",
+		strings.HasSuffix(mt, "javascript"), mt == "text/ecmascript":
+		return "text/plain; charset=utf-8", nil
+	}
+
+	return ct, nil
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var releaseDownloadCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "release_download_total",
+	Help:      "The total number of release asset downloads",
+}, []string{"repo"})
+
+// releaseMethods are the methods allowed on release assets.
+var releaseMethods = []string{http.MethodGet, http.MethodHead}
+
+// releasePath is the path of release asset downloads.
+const releasePath = "/{repo:.+?}/{_:releases/download/.+/[^/]+$}"
+
+// serviceReleaseAsset serves a release asset at
+// /<repo>/releases/download/<tag>/<name>. Tags can have slashes, the name is
+// the last segment of the path. Assets are tagged with their checksum.
+func serviceReleaseAsset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	file := strings.TrimPrefix(mux.Vars(r)["file"], "releases/download/")
+	i := strings.LastIndex(file, "/")
+	tag, name := file[:i], file[i+1:]
+
+	f, a, err := be.OpenReleaseAsset(ctx, repo.Name(), tag, name)
+	if err != nil {
+		if errors.Is(err, proto.ErrReleaseNotFound) {
+			renderNotFound(w, r)
+			return
+		}
+		logger.Error("failed to open release asset", "repo", repo.Name(), "tag", tag, "name", name, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+	defer f.Close() // nolint: errcheck
+
+	releaseDownloadCounter.WithLabelValues(repo.Name()).Inc()
+	cache := "no-cache"
+	if repo.IsPrivate() {
+		cache = "private, " + cache
+	}
+
+	w.Header().Set("Cache-Control", cache)
+	w.Header().Set("ETag", `"`+a.SHA256+`"`)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", a.Name))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", a.UpdatedAt, f)
+}
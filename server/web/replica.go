@@ -0,0 +1,67 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/git"
+	"github.com/gorilla/mux"
+)
+
+// withReplicaProxy proxies the pushes and the LFS requests of a read-only
+// replica to the primary, with the credentials of the client. The other
+// requests are served by the replica.
+func withReplicaProxy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		cfg := config.FromContext(ctx)
+		if !cfg.Replica.Enabled || !isPrimaryRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		primary, err := url.Parse(cfg.Replica.Primary)
+		if err != nil {
+			log.FromContext(ctx).Error("invalid replica primary url", "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+
+		proxy := &httputil.ReverseProxy{
+			Director: func(req *http.Request) {
+				req.URL.Scheme = primary.Scheme
+				req.URL.Host = primary.Host
+				req.URL.Path = path.Join("/", primary.Path, req.URL.Path)
+				req.URL.RawPath = ""
+				req.Host = primary.Host
+			},
+			// Stream the responses of the primary.
+			FlushInterval: -1,
+		}
+		proxy.ServeHTTP(w, r)
+
+		if r.Method == http.MethodPost && mux.Vars(r)["service"] == git.ReceivePackService.String() {
+			be := backend.FromContext(ctx)
+			be.ReplicaPushed(mux.Vars(r)["repo"])
+		}
+	})
+}
+
+// isPrimaryRequest returns true if the request of a git route writes to the
+// repository and has to go to the primary, i.e. it's a push or a Git LFS
+// request.
+func isPrimaryRequest(r *http.Request) bool {
+	vars := mux.Vars(r)
+	if vars["service"] == git.ReceivePackService.String() ||
+		r.URL.Query().Get("service") == git.ReceivePackService.String() {
+		return true
+	}
+
+	return strings.HasPrefix(vars["file"], "info/lfs")
+}
@@ -6,12 +6,22 @@ import (
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // NewRouter returns a new HTTP router.
 func NewRouter(ctx context.Context) http.Handler {
 	router := mux.NewRouter()
 
+	// API routes
+	APIController(ctx, router)
+
+	// OpenID Connect login routes
+	OIDCController(ctx, router)
+
+	// Web interface routes
+	WebUIController(ctx, router)
+
 	// Git routes
 	GitController(ctx, router)
 
@@ -20,9 +30,29 @@ func NewRouter(ctx context.Context) http.Handler {
 	// Context handler
 	// Adds context to the request
 	h := NewContextHandler(ctx)(router)
-	h = handlers.CompressHandler(h)
+	h = NewRateLimitHandler(ctx)(h)
+	h = NewIPAccessHandler(ctx)(h)
+	h = compressHandler(h)
 	h = handlers.RecoveryHandler()(h)
 	h = NewLoggingMiddleware(h)
+	// Traces continue the traces of the clients. Span names don't include the
+	// paths, they're unbounded.
+	h = otelhttp.NewHandler(h, "http", otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return "HTTP " + r.Method
+	}))
 
 	return h
 }
+
+// compressHandler compresses responses. Event streams aren't compressed, they
+// need the connection to extend its write deadline.
+func compressHandler(next http.Handler) http.Handler {
+	compress := handlers.CompressHandler(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isEventStream(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		compress.ServeHTTP(w, r)
+	})
+}
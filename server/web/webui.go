@@ -0,0 +1,733 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/server/access"
+	"github.com/charmbracelet/soft-serve/server/backend"
+	"github.com/charmbracelet/soft-serve/server/config"
+	"github.com/charmbracelet/soft-serve/server/proto"
+	"github.com/charmbracelet/soft-serve/server/repofs"
+	"github.com/charmbracelet/soft-serve/server/ui/common"
+	"github.com/dustin/go-humanize"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var webCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "web_total",
+	Help:      "The total number of web interface page views",
+}, []string{"page"})
+
+// maxWebFileSize is the size of the largest file shown in the web interface,
+// larger files are only linked to.
+const maxWebFileSize = 1 << 20 // 1 MiB
+
+// webCommitsPage is the number of commits per page of the commit log.
+const webCommitsPage = 30
+
+// commitHashRe matches full and abbreviated commit hashes.
+var commitHashRe = regexp.MustCompile(`^[0-9a-f]{4,40}$`)
+
+//go:embed templates/*.html
+var webTemplatesFS embed.FS
+
+var webFuncs = template.FuncMap{
+	"ago": humanize.Time,
+	"size": func(n int64) string {
+		return humanize.Bytes(uint64(n))
+	},
+}
+
+// webTemplates are the pages of the web interface by name. Each page is
+// rendered in the layout, with the shared templates.
+var webTemplates = func() map[string]*template.Template {
+	pages := make(map[string]*template.Template)
+	for _, name := range []string{"repos", "repo", "tree", "commits", "commit"} {
+		pages[name] = template.Must(template.New(name).Funcs(webFuncs).ParseFS(webTemplatesFS,
+			"templates/layout.html", "templates/entries.html", "templates/"+name+".html"))
+	}
+	return pages
+}()
+
+// WebUIController registers the routes of the read-only web interface. The
+// repository pages that don't collide with the git routes are served by
+// GoGetHandler, so WebUIController must be registered before GitController.
+func WebUIController(_ context.Context, r *mux.Router) {
+	r.Handle("/", withWebAccess(http.HandlerFunc(serviceWebIndex))).
+		Methods(http.MethodGet, http.MethodHead)
+
+	// Patches go first, commit patches are under the commit pages.
+	patch := GitRoute{method: patchMethods, handler: servicePatch}
+	r.Handle(commitPatchPath, withParams(withAccess(patch))).MatcherFunc(repoMatcher("commit"))
+	r.Handle(comparePatchPath, withParams(withAccess(patch))).MatcherFunc(repoMatcher("compare"))
+
+	for _, route := range []struct {
+		segment string
+		handler http.HandlerFunc
+	}{
+		{segment: "tree", handler: serviceWebTree},
+		{segment: "commits", handler: serviceWebCommits},
+		{segment: "commit", handler: serviceWebCommit},
+	} {
+		page := GitRoute{method: []string{http.MethodGet, http.MethodHead}, handler: route.handler}
+		r.Handle("/{repo:.+?}/{_:"+route.segment+"/.*$}", withParams(withAccess(page))).
+			MatcherFunc(repoMatcher(route.segment))
+	}
+}
+
+// isWebPage reports whether a repository request file is a web interface page.
+func isWebPage(file string) bool {
+	for _, segment := range []string{"tree/", "commits/", "commit/"} {
+		if strings.HasPrefix(file, segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// withWebAccess authenticates web interface requests that aren't about a
+// single repository. Anonymous users are allowed when keyless access is.
+func withWebAccess(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := log.FromContext(ctx)
+		be := backend.FromContext(ctx)
+
+		user, err := authenticate(r)
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidPassword),
+			errors.Is(err, proto.ErrUserSuspended), errors.Is(err, proto.ErrAddressDenied):
+			renderForbidden(w, r)
+			return
+		case errors.Is(err, proto.ErrRateLimited):
+			renderRateLimited(w, r, err)
+			return
+		case err != nil && !errors.Is(err, proto.ErrUserNotFound):
+			logger.Error("failed to authenticate", "err", err)
+		}
+
+		if user == nil && !be.AllowKeyless(ctx) {
+			askCredentials(w, r)
+			renderUnauthorized(w, r)
+			return
+		}
+
+		ctx = proto.WithUserContext(ctx, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// webPage is the data shared by the pages of the web interface.
+type webPage struct {
+	Title      string
+	ServerName string
+	Repo       *webRepo
+	// Tab is the active tab of repository pages.
+	Tab string
+}
+
+// webRepo is a repository of the web interface.
+type webRepo struct {
+	Name          string
+	Description   string
+	UpdatedAt     time.Time
+	HTTPURL       string
+	SSHURL        string
+	DefaultBranch string
+	Archived      bool
+}
+
+func newWebRepo(ctx context.Context, cfg *config.Config, repo proto.Repository) *webRepo {
+	meta := backend.FromContext(ctx).RepoMetadata(ctx, repo)
+	return &webRepo{
+		Name:          repo.Name(),
+		Description:   repo.Description(),
+		UpdatedAt:     meta.UpdatedAt,
+		HTTPURL:       common.RepoURL(cfg.HTTP.PublicURL, repo.Name()),
+		SSHURL:        common.RepoURL(cfg.SSH.PublicURL, repo.Name()),
+		DefaultBranch: meta.DefaultBranch,
+		Archived:      repo.IsArchived(),
+	}
+}
+
+// URL returns the URL of the repository summary.
+func (wr *webRepo) URL() string {
+	return webURL(wr.Name)
+}
+
+// TreeURL returns the URL of a tree or file at ref.
+func (wr *webRepo) TreeURL(ref, p string) string {
+	return webURL(wr.Name, "tree", ref, p)
+}
+
+// RawURL returns the URL of the raw content of a file at ref.
+func (wr *webRepo) RawURL(ref, p string) string {
+	return webURL(wr.Name, "raw", ref, p)
+}
+
+// CommitsURL returns the URL of the commit log of ref.
+func (wr *webRepo) CommitsURL(ref string) string {
+	return webURL(wr.Name, "commits", ref)
+}
+
+// FeedURL returns the URL of a feed of the repository, see serviceFeed.
+func (wr *webRepo) FeedURL(feed string) string {
+	return webURL(wr.Name, "feeds", feed+".atom")
+}
+
+// CommitURL returns the URL of a commit.
+func (wr *webRepo) CommitURL(hash string) string {
+	return webURL(wr.Name, "commit", hash)
+}
+
+// PatchURL returns the URL of the patch of a commit, see servicePatch.
+func (wr *webRepo) PatchURL(hash string) string {
+	return webURL(wr.Name, "commit", hash+".patch")
+}
+
+// webURL returns the path of the joined parts, with their segments escaped.
+func webURL(parts ...string) string {
+	var segs []string
+	for _, p := range parts {
+		for _, s := range strings.Split(p, "/") {
+			if s != "" {
+				segs = append(segs, url.PathEscape(s))
+			}
+		}
+	}
+	return "/" + strings.Join(segs, "/")
+}
+
+// webEntry is an entry of a tree listing.
+type webEntry struct {
+	Name  string
+	URL   string
+	IsDir bool
+	Size  int64
+}
+
+func newWebEntries(wr *webRepo, ref string, dir string, ents git.Entries) []webEntry {
+	entries := make([]webEntry, 0, len(ents))
+	for _, e := range ents {
+		we := webEntry{Name: e.Name(), IsDir: e.IsTree() || e.IsCommit()}
+		// Submodules point to other repositories.
+		if !e.IsCommit() {
+			we.URL = wr.TreeURL(ref, path.Join(dir, e.Name()))
+		}
+		if !we.IsDir {
+			we.Size = e.Size()
+		}
+		entries = append(entries, we)
+	}
+	return entries
+}
+
+// renderWebPage renders the page template name with data.
+func renderWebPage(w http.ResponseWriter, r *http.Request, name string, data any) {
+	var buf bytes.Buffer
+	if err := webTemplates[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		log.FromContext(r.Context()).Error("failed to render page", "page", name, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	webCounter.WithLabelValues(name).Inc()
+	// Rendered markdown can link to images, but nothing can run scripts.
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src * data:")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(buf.Bytes()) // nolint: errcheck
+	}
+}
+
+// serviceWebIndex lists the repositories the user can read. Hidden
+// repositories aren't listed, and archived repositories are only listed with
+// ?archived=true.
+func serviceWebIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	user := proto.UserFromContext(ctx)
+	showArchived := r.URL.Query().Get("archived") == "true"
+
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error("failed to list repositories", "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	list := make([]*webRepo, 0, len(repos))
+	var archived int
+	for _, repo := range repos {
+		if repo.IsHidden() || be.AccessLevelForUser(ctx, repo.Name(), user) < access.ReadOnlyAccess {
+			continue
+		}
+		if repo.IsArchived() {
+			archived++
+			if !showArchived {
+				continue
+			}
+		}
+		list = append(list, newWebRepo(ctx, cfg, repo))
+	}
+
+	// Recently updated repositories first.
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].UpdatedAt.After(list[j].UpdatedAt)
+	})
+
+	renderWebPage(w, r, "repos", struct {
+		webPage
+		Repos        []*webRepo
+		Archived     int
+		ShowArchived bool
+	}{
+		webPage:      webPage{ServerName: cfg.Current().Name},
+		Repos:        list,
+		Archived:     archived,
+		ShowArchived: showArchived,
+	})
+}
+
+// webReadme is a rendered README.
+type webReadme struct {
+	Path string
+	HTML template.HTML
+}
+
+// serviceWebRepo shows the summary of a repository, the files of its default
+// branch and its README.
+func serviceWebRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	wr := newWebRepo(ctx, cfg, repo)
+
+	data := struct {
+		webPage
+		Ref     string
+		Entries []webEntry
+		Readme  *webReadme
+	}{
+		webPage: webPage{Title: repo.Name(), ServerName: cfg.Current().Name, Repo: wr, Tab: "summary"},
+		Ref:     wr.DefaultBranch,
+	}
+
+	if wr.DefaultBranch != "" {
+		rr, err := repo.Open()
+		if err != nil {
+			logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+
+		head, err := rr.HEAD()
+		if err != nil {
+			logger.Error("failed to get HEAD", "repo", repo.Name(), "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+
+		ents, err := be.TreeEntries(ctx, repo, head, "")
+		if err != nil {
+			logger.Error("failed to list tree", "repo", repo.Name(), "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+		data.Entries = newWebEntries(wr, wr.DefaultBranch, "", ents)
+
+		if readme, p, err := be.Readme(ctx, repo); err == nil && p != "" {
+			data.Readme = &webReadme{Path: p, HTML: renderWebFile(p, []byte(readme))}
+		}
+	}
+
+	renderWebPage(w, r, "repo", data)
+}
+
+// webRevision resolves a ref name followed by a path, like repofs does. It
+// returns the short name of the ref, the reference of its commit, and the
+// path.
+func webRevision(rr *git.Repository, name string) (string, *git.Reference, string, bool) {
+	rev, p, ok := repofs.New(rr).Revision(strings.Trim(path.Clean("/"+name), "/"))
+	if !ok {
+		return "", nil, "", false
+	}
+
+	c, err := rr.CatFileCommit(rev + "^{commit}")
+	if err != nil {
+		return "", nil, "", false
+	}
+
+	ref := git.NewReference(rr.Path, rev)
+	ref.Hash = git.Hash(c.ID.String())
+	short := strings.TrimSuffix(strings.TrimSuffix(strings.Trim(name, "/"), p), "/")
+	return short, ref, p, true
+}
+
+// webCrumb is a path segment of a tree.
+type webCrumb struct {
+	Name string
+	URL  string
+}
+
+// webFile is a file of a tree.
+type webFile struct {
+	Size   int64
+	RawURL string
+	HTML   template.HTML
+	// Note explains why a file isn't shown.
+	Note string
+}
+
+// serviceWebTree shows a tree or a file at /<repo>/tree/<ref>/<path>.
+func serviceWebTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	wr := newWebRepo(ctx, cfg, repo)
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	refName, ref, p, ok := webRevision(rr, strings.TrimPrefix(mux.Vars(r)["file"], "tree/"))
+	if !ok {
+		renderNotFound(w, r)
+		return
+	}
+
+	data := struct {
+		webPage
+		Ref     string
+		Crumbs  []webCrumb
+		Entries []webEntry
+		File    *webFile
+	}{
+		webPage: webPage{Title: path.Join(repo.Name(), p), ServerName: cfg.Current().Name, Repo: wr, Tab: "tree"},
+		Ref:     refName,
+		Crumbs:  []webCrumb{{Name: path.Base(repo.Name()), URL: wr.TreeURL(refName, "")}},
+	}
+
+	if p != "" {
+		segs := strings.Split(p, "/")
+		for i, s := range segs {
+			data.Crumbs = append(data.Crumbs, webCrumb{Name: s, URL: wr.TreeURL(refName, strings.Join(segs[:i+1], "/"))})
+		}
+	}
+
+	// Trees are listed from their parent, so that files and missing paths
+	// are told apart.
+	var entry *git.TreeEntry
+	if p != "" {
+		dir := path.Dir(p)
+		if dir == "." {
+			dir = ""
+		}
+		ents, err := be.TreeEntries(ctx, repo, ref, dir)
+		if err != nil {
+			renderNotFound(w, r)
+			return
+		}
+		for _, e := range ents {
+			if e.Name() == path.Base(p) {
+				entry = e
+				break
+			}
+		}
+		if entry == nil || entry.IsCommit() {
+			renderNotFound(w, r)
+			return
+		}
+	}
+
+	if entry == nil || entry.IsTree() {
+		ents, err := be.TreeEntries(ctx, repo, ref, p)
+		if err != nil {
+			logger.Error("failed to list tree", "repo", repo.Name(), "path", p, "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+		data.Entries = newWebEntries(wr, refName, p, ents)
+		renderWebPage(w, r, "tree", data)
+		return
+	}
+
+	f := &webFile{Size: entry.Size(), RawURL: wr.RawURL(refName, p)}
+	data.File = f
+	if f.Size > maxWebFileSize {
+		f.Note = "This file is too large to show."
+		renderWebPage(w, r, "tree", data)
+		return
+	}
+
+	content, err := entry.Contents()
+	if err != nil {
+		logger.Error("failed to read file", "repo", repo.Name(), "path", p, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	if bin, _ := git.IsBinary(bytes.NewReader(content)); bin {
+		f.Note = "This file is binary."
+	} else {
+		f.HTML = renderWebFile(p, content)
+	}
+
+	renderWebPage(w, r, "tree", data)
+}
+
+// renderWebFile renders markdown files as sanitized HTML, and highlights the
+// others like the TUI does.
+func renderWebFile(name string, content []byte) template.HTML {
+	lexer := lexers.Match(path.Base(name))
+	if lexer != nil && lexer.Config() != nil && lexer.Config().Name == "markdown" {
+		if out, err := common.RenderMarkdownHTML(string(content)); err == nil {
+			return template.HTML(out) // nolint: gosec
+		}
+	}
+
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	var buf bytes.Buffer
+	it, err := lexer.Tokenise(nil, string(content))
+	if err == nil {
+		err = html.New(html.WithLineNumbers(true), html.LinkableLineNumbers(true, "L")).Format(&buf, styles.Get("github"), it)
+	}
+	if err != nil {
+		return template.HTML("<pre>" + template.HTMLEscapeString(string(content)) + "</pre>") // nolint: gosec
+	}
+
+	return template.HTML(buf.String()) // nolint: gosec
+}
+
+// webCommit is a commit of the commit log.
+type webCommit struct {
+	Hash      string
+	ShortHash string
+	Title     string
+	Body      string
+	Author    string
+	When      time.Time
+	URL       string
+	PatchURL  string
+	Parents   []webCommit
+}
+
+func newWebCommit(wr *webRepo, c *git.Commit) webCommit {
+	title, body, _ := strings.Cut(strings.TrimSpace(strings.ReplaceAll(c.Message, "\r\n", "\n")), "\n")
+	wc := webCommit{
+		Hash:      c.Hash.String(),
+		ShortHash: shortHash(c.Hash.String()),
+		Title:     title,
+		Body:      strings.TrimSpace(body),
+		URL:       wr.CommitURL(c.Hash.String()),
+		PatchURL:  wr.PatchURL(c.Hash.String()),
+	}
+	if c.Author != nil {
+		wc.Author = c.Author.Name
+		wc.When = c.Author.When
+	}
+	return wc
+}
+
+func shortHash(h string) string {
+	if len(h) > 7 {
+		return h[:7]
+	}
+	return h
+}
+
+// serviceWebCommits shows the commit log of a ref at /<repo>/commits/<ref>.
+// Older pages are read from the after query parameter, the comma separated
+// commits the log continues from.
+func serviceWebCommits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	wr := newWebRepo(ctx, cfg, repo)
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	refName, ref, p, ok := webRevision(rr, strings.TrimPrefix(mux.Vars(r)["file"], "commits/"))
+	if !ok || p != "" {
+		renderNotFound(w, r)
+		return
+	}
+
+	cursor := []string{ref.Hash.String()}
+	if after := r.URL.Query().Get("after"); after != "" {
+		cursor = strings.Split(after, ",")
+		for _, h := range cursor {
+			// The cursor is passed to git log, only allow hashes.
+			if len(h) != 40 || !commitHashRe.MatchString(h) {
+				renderBadRequest(w, r)
+				return
+			}
+		}
+	}
+
+	release, err := be.AcquireWorker(ctx, repo.Name())
+	if err != nil {
+		if errors.Is(err, proto.ErrServerBusy) {
+			renderStatus(http.StatusServiceUnavailable)(w, r)
+			return
+		}
+		renderInternalServerError(w, r)
+		return
+	}
+	commits, next, err := be.CommitsFrom(ctx, repo, cursor, webCommitsPage)
+	release()
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	data := struct {
+		webPage
+		Ref     string
+		Commits []webCommit
+		NextURL string
+	}{
+		webPage: webPage{Title: repo.Name() + " commits", ServerName: cfg.Current().Name, Repo: wr, Tab: "commits"},
+		Ref:     refName,
+	}
+
+	for _, c := range commits {
+		data.Commits = append(data.Commits, newWebCommit(wr, c))
+	}
+	if len(next) > 0 {
+		data.NextURL = wr.CommitsURL(refName) + "?after=" + strings.Join(next, ",")
+	}
+
+	renderWebPage(w, r, "commits", data)
+}
+
+// webDiffLine is a line of a patch.
+type webDiffLine struct {
+	Class string
+	Text  string
+}
+
+// serviceWebCommit shows a commit and its diff at /<repo>/commit/<hash>.
+func serviceWebCommit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	wr := newWebRepo(ctx, cfg, repo)
+
+	hash := strings.TrimPrefix(mux.Vars(r)["file"], "commit/")
+	if !commitHashRe.MatchString(hash) {
+		renderNotFound(w, r)
+		return
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	c, err := rr.CatFileCommit(hash)
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	commit := &git.Commit{Commit: c, Hash: git.Hash(c.ID.String())}
+	release, err := be.AcquireWorker(ctx, repo.Name())
+	if err != nil {
+		if errors.Is(err, proto.ErrServerBusy) {
+			renderStatus(http.StatusServiceUnavailable)(w, r)
+			return
+		}
+		renderInternalServerError(w, r)
+		return
+	}
+	diff, err := rr.Diff(commit)
+	release()
+	if err != nil {
+		logger.Error("failed to get diff", "repo", repo.Name(), "commit", commit.Hash, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	wc := newWebCommit(wr, commit)
+	for i := 0; i < c.ParentsCount(); i++ {
+		if id, err := c.ParentID(i); err == nil {
+			wc.Parents = append(wc.Parents, webCommit{
+				Hash:      id.String(),
+				ShortHash: shortHash(id.String()),
+				URL:       wr.CommitURL(id.String()),
+			})
+		}
+	}
+
+	data := struct {
+		webPage
+		Commit webCommit
+		Stats  string
+		Lines  []webDiffLine
+	}{
+		webPage: webPage{Title: wc.Title, ServerName: cfg.Current().Name, Repo: wr},
+		Commit:  wc,
+		Stats:   strings.TrimSpace(diff.Stats().String()),
+	}
+
+	for _, l := range strings.Split(strings.TrimSuffix(diff.Patch(), "\n"), "\n") {
+		dl := webDiffLine{Text: l}
+		switch {
+		case strings.HasPrefix(l, "+++"), strings.HasPrefix(l, "---"), strings.HasPrefix(l, "diff --git"):
+			dl.Class = "meta"
+		case strings.HasPrefix(l, "+"):
+			dl.Class = "add"
+		case strings.HasPrefix(l, "-"):
+			dl.Class = "del"
+		case strings.HasPrefix(l, "@@"):
+			dl.Class = "hunk"
+		}
+		data.Lines = append(data.Lines, dl)
+	}
+
+	renderWebPage(w, r, "commit", data)
+}
@@ -3,6 +3,7 @@ package testscript
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"flag"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -28,6 +30,8 @@ import (
 	"github.com/charmbracelet/soft-serve/server/store"
 	"github.com/charmbracelet/soft-serve/server/store/database"
 	"github.com/charmbracelet/soft-serve/server/test"
+	"github.com/charmbracelet/soft-serve/server/totp"
+	"github.com/go-sql-driver/mysql"
 	"github.com/rogpeppe/go-internal/testscript"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
@@ -51,6 +55,11 @@ func TestScript(t *testing.T) {
 	key, admin1 := mkkey("admin1")
 	_, admin2 := mkkey("admin2")
 	_, user1 := mkkey("user1")
+	deployKey, deploy1 := mkkey("deploy1")
+	_, ca1 := mkkey("ca1")
+	_, cert1 := mkkey("cert1")
+	cert1Signer := mkcert(t, ca1.Signer(), cert1.Signer(), "cert1")
+	untrustedSigner := mkcert(t, admin2.Signer(), cert1.Signer(), "cert1")
 
 	testscript.Run(t, testscript.Params{
 		Dir:           "./testdata/",
@@ -58,12 +67,18 @@ func TestScript(t *testing.T) {
 		Cmds: map[string]func(ts *testscript.TestScript, neg bool, args []string){
 			"soft":     cmdSoft(admin1.Signer()),
 			"usoft":    cmdSoft(user1.Signer()),
+			"csoft":    cmdSoft(cert1Signer),
+			"xsoft":    cmdSoft(untrustedSigner),
+			"dsoft":    cmdSoft(deploy1.Signer()),
 			"git":      cmdGit(key),
+			"dgit":     cmdGit(deployKey),
+			"sftp":     cmdSftp(key),
 			"curl":     cmdCurl,
 			"mkfile":   cmdMkfile,
 			"envfile":  cmdEnvfile,
 			"readfile": cmdReadfile,
 			"dos2unix": cmdDos2Unix,
+			"totp":     cmdTotp,
 		},
 		Setup: func(e *testscript.Env) error {
 			data := t.TempDir()
@@ -81,9 +96,11 @@ func TestScript(t *testing.T) {
 			e.Setenv("DATA_PATH", data)
 			e.Setenv("SSH_PORT", fmt.Sprintf("%d", sshPort))
 			e.Setenv("HTTP_PORT", fmt.Sprintf("%d", httpPort))
+			e.Setenv("GIT_PORT", fmt.Sprintf("%d", gitPort))
 			e.Setenv("ADMIN1_AUTHORIZED_KEY", admin1.AuthorizedKey())
 			e.Setenv("ADMIN2_AUTHORIZED_KEY", admin2.AuthorizedKey())
 			e.Setenv("USER1_AUTHORIZED_KEY", user1.AuthorizedKey())
+			e.Setenv("DEPLOY1_AUTHORIZED_KEY", deploy1.AuthorizedKey())
 			e.Setenv("SSH_KNOWN_HOSTS_FILE", filepath.Join(t.TempDir(), "known_hosts"))
 			e.Setenv("SSH_KNOWN_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
 
@@ -91,6 +108,7 @@ func TestScript(t *testing.T) {
 			cfg.DataPath = data
 			cfg.Name = serverName
 			cfg.InitialAdminKeys = []string{admin1.AuthorizedKey()}
+			cfg.SSH.TrustedUserCAKeys = []string{ca1.AuthorizedKey()}
 			cfg.SSH.ListenAddr = sshListen
 			cfg.SSH.PublicURL = "ssh://" + sshListen
 			cfg.Git.ListenAddr = gitListen
@@ -121,6 +139,16 @@ func TestScript(t *testing.T) {
 				}
 			}
 
+			if cfg.DB.Driver == "mysql" {
+				err, cleanup := setupMySQL(e.T(), cfg)
+				if err != nil {
+					return err
+				}
+				if cleanup != nil {
+					e.Defer(cleanup)
+				}
+			}
+
 			if err := cfg.Validate(); err != nil {
 				return err
 			}
@@ -195,6 +223,27 @@ func TestScript(t *testing.T) {
 	})
 }
 
+// mkcert returns a signer of a user certificate of key signed by ca.
+func mkcert(t *testing.T, ca ssh.Signer, key ssh.Signer, principals ...string) ssh.Signer {
+	cert := &ssh.Certificate{
+		Key:             key.PublicKey(),
+		CertType:        ssh.UserCert,
+		KeyId:           "test",
+		ValidPrincipals: principals,
+		ValidBefore:     ssh.CertTimeInfinity,
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ssh.NewCertSigner(cert, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer
+}
+
 func cmdSoft(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []string) {
 	return func(ts *testscript.TestScript, neg bool, args []string) {
 		cli, err := ssh.Dial(
@@ -206,7 +255,11 @@ func cmdSoft(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []st
 				HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 			},
 		)
-		ts.Check(err)
+		if err != nil {
+			// Rejected keys fail the handshake.
+			check(ts, err, neg)
+			return
+		}
 		defer cli.Close()
 
 		sess, err := cli.NewSession()
@@ -278,6 +331,26 @@ func cmdGit(key string) func(ts *testscript.TestScript, neg bool, args []string)
 	}
 }
 
+func cmdSftp(key string) func(ts *testscript.TestScript, neg bool, args []string) {
+	return func(ts *testscript.TestScript, neg bool, args []string) {
+		if len(args) != 1 {
+			ts.Fatalf("usage: sftp batchfile")
+		}
+		ts.Check(os.WriteFile(
+			ts.Getenv("SSH_KNOWN_CONFIG_FILE"),
+			[]byte(fmt.Sprintf(sshConfig, ts.Getenv("SSH_KNOWN_HOSTS_FILE"))),
+			0o600,
+		))
+		check(ts, ts.Exec("sftp",
+			"-F", filepath.ToSlash(ts.Getenv("SSH_KNOWN_CONFIG_FILE")),
+			"-i", filepath.ToSlash(key),
+			"-P", ts.Getenv("SSH_PORT"),
+			"-b", ts.MkAbs(args[0]),
+			"localhost",
+		), neg)
+	}
+}
+
 func cmdMkfile(ts *testscript.TestScript, neg bool, args []string) {
 	if len(args) < 2 {
 		ts.Fatalf("usage: mkfile path content")
@@ -318,6 +391,23 @@ func cmdEnvfile(ts *testscript.TestScript, neg bool, args []string) {
 	}
 }
 
+func cmdTotp(ts *testscript.TestScript, neg bool, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		ts.Fatalf("usage: totp secret [offset]")
+	}
+
+	var offset int64
+	if len(args) == 2 {
+		n, err := strconv.ParseInt(args[1], 10, 64)
+		ts.Check(err)
+		offset = n
+	}
+
+	code, err := totp.Code(args[0], totp.Step(time.Now())+offset)
+	ts.Check(err)
+	ts.Stdout().Write([]byte(code + "\n")) // nolint: errcheck
+}
+
 func cmdCurl(ts *testscript.TestScript, neg bool, args []string) {
 	var verbose bool
 	var headers []string
@@ -340,6 +430,7 @@ func cmdCurl(ts *testscript.TestScript, neg bool, args []string) {
 
 			if data != "" {
 				req.Body = io.NopCloser(strings.NewReader(data))
+				req.ContentLength = int64(len(data))
 			}
 
 			if verbose {
@@ -469,3 +560,44 @@ func setupPostgres(t testscript.T, cfg *config.Config) (error, func()) {
 		}
 	}
 }
+
+func setupMySQL(t testscript.T, cfg *config.Config) (error, func()) {
+	// Create a disposable database
+	dbName := fmt.Sprintf("softserve_test_%d", time.Now().UnixNano())
+	dbDsn := os.Getenv("DB_DATA_SOURCE")
+	if dbDsn == "" {
+		cfg.DB.DataSource = "root@tcp(localhost:3306)/"
+	}
+
+	dsn, err := mysql.ParseDSN(cfg.DB.DataSource)
+	if err != nil {
+		return err, nil
+	}
+
+	dsn.DBName = ""
+	connInfo := dsn.FormatDSN()
+	dsn.DBName = dbName
+	cfg.DB.DataSource = dsn.FormatDSN()
+
+	// Create the database
+	db, err := sql.Open(cfg.DB.Driver, connInfo)
+	if err != nil {
+		return err, nil
+	}
+
+	if _, err := db.Exec("CREATE DATABASE " + dbName); err != nil {
+		return err, nil
+	}
+
+	return nil, func() {
+		db, err := sql.Open(cfg.DB.Driver, connInfo)
+		if err != nil {
+			t.Log("failed to open database", dbName, err)
+			return
+		}
+
+		if _, err := db.Exec("DROP DATABASE " + dbName); err != nil {
+			t.Log("failed to drop database", dbName, err)
+		}
+	}
+}